@@ -0,0 +1,226 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/storage"
+	"github.com/urfave/cli/v2"
+)
+
+type benchResult struct {
+	Name     string
+	Count    int
+	Elapsed  time.Duration
+	Operator string
+}
+
+func (r *benchResult) String() string {
+	rate := float64(r.Count) / r.Elapsed.Seconds()
+	return fmt.Sprintf("%-24s %12.2f %s/s\t(%d in %s)", r.Name, rate, r.Operator, r.Count, r.Elapsed.Round(time.Millisecond))
+}
+
+func benchCmd(c *cli.Context) error {
+	custom, err := config.Initialize(c.String("dir") + "/config.toml")
+	if err != nil {
+		return err
+	}
+	duration := c.Duration("duration")
+	if duration <= 0 {
+		return fmt.Errorf("invalid duration %s", duration)
+	}
+	nodes := c.Int("nodes")
+	if nodes < 4 || nodes > 1024 {
+		return fmt.Errorf("invalid nodes count %d", nodes)
+	}
+
+	fmt.Printf("host:\t\t%s/%s %d CPUs %s\n", runtime.GOOS, runtime.GOARCH, runtime.NumCPU(), runtime.Version())
+	fmt.Printf("mixin:\t\t%s\n", config.BuildVersion)
+	fmt.Printf("storage:\tvalue log gc %t, disk bandwidth %dMB/s, background share %d%%\n",
+		custom.Storage.ValueLogGC, custom.Storage.DiskBandwidth, custom.Storage.BackgroundShare)
+
+	results := make([]*benchResult, 0)
+	res, err := benchSignatureVerify(duration)
+	if err != nil {
+		return err
+	}
+	results = append(results, res)
+
+	res, err = benchSnapshotValidation(duration, nodes)
+	if err != nil {
+		return err
+	}
+	results = append(results, res)
+
+	dir, err := os.MkdirTemp("", "mixin-bench-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	store, err := storage.NewBadgerStore(custom, dir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	res, err = benchWrite("badger sync write", duration, store.WriteTransaction)
+	if err != nil {
+		return err
+	}
+	results = append(results, res)
+	res, err = benchWrite("badger cache write", duration, store.CachePutTransaction)
+	if err != nil {
+		return err
+	}
+	results = append(results, res)
+
+	fmt.Println()
+	for _, r := range results {
+		fmt.Println(r.String())
+	}
+	return nil
+}
+
+func benchRun(name, operator string, duration time.Duration, fn func(i int) error) (*benchResult, error) {
+	start := time.Now()
+	i := 0
+	for ; time.Since(start) < duration; i++ {
+		err := fn(i)
+		if err != nil {
+			return nil, fmt.Errorf("%s %d %v", name, i, err)
+		}
+	}
+	return &benchResult{
+		Name:     name,
+		Count:    i,
+		Elapsed:  time.Since(start),
+		Operator: operator,
+	}, nil
+}
+
+func benchSignatureVerify(duration time.Duration) (*benchResult, error) {
+	key := benchKey()
+	pub := key.Public()
+	msg := crypto.NewHash([]byte("mixin bench signature"))
+	sig := key.Sign(msg[:])
+	return benchRun("signature verify", "ops", duration, func(int) error {
+		if !pub.Verify(msg[:], sig) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	})
+}
+
+// benchSnapshotValidation measures the CPU bound part of accepting a final
+// snapshot: decode the transaction, verify its input signature and verify the
+// aggregated signature of the snapshot against all the consensus nodes.
+func benchSnapshotValidation(duration time.Duration, nodes int) (*benchResult, error) {
+	key := benchKey()
+	pub := key.Public()
+	ver, err := benchTransaction(key, []byte("mixin bench snapshot"))
+	if err != nil {
+		return nil, err
+	}
+	raw := ver.Marshal()
+
+	keys := make([]*crypto.Key, nodes)
+	publics := make([]*crypto.Key, nodes)
+	for i := range keys {
+		k := benchKey()
+		p := k.Public()
+		keys[i], publics[i] = &k, &p
+	}
+	threshold := nodes*2/3 + 1
+
+	s := &common.Snapshot{
+		Version:     common.SnapshotVersion,
+		NodeId:      crypto.NewHash(publics[0][:]),
+		Transaction: ver.PayloadHash(),
+		References:  &common.RoundLink{},
+		RoundNumber: 1,
+		Timestamp:   uint64(time.Now().UnixNano()),
+	}
+	s.Hash = s.PayloadHash()
+	randoms := make(map[int]*crypto.Key)
+	commits := make(map[int]*crypto.Key)
+	for i := 0; i < threshold; i++ {
+		r := crypto.CosiCommit(rand.Reader)
+		R := r.Public()
+		commits[i], randoms[i] = r, &R
+	}
+	cosi, err := crypto.CosiAggregateCommitment(randoms)
+	if err != nil {
+		return nil, err
+	}
+	responses := make(map[int]*[32]byte)
+	for i := 0; i < threshold; i++ {
+		sig, err := cosi.Response(keys[i], commits[i], publics, s.Hash[:])
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = sig
+	}
+	err = cosi.AggregateResponse(publics, responses, s.Hash[:], true)
+	if err != nil {
+		return nil, err
+	}
+	s.Signature = cosi
+
+	return benchRun("snapshot validation", "snapshots", duration, func(int) error {
+		tx, err := common.UnmarshalVersionedTransaction(raw)
+		if err != nil {
+			return err
+		}
+		msg := tx.PayloadMarshal()
+		if !pub.Verify(msg, *tx.SignaturesMap[0][0]) {
+			return fmt.Errorf("invalid transaction signature")
+		}
+		if tx.PayloadHash() != s.Transaction || s.PayloadHash() != s.Hash {
+			return fmt.Errorf("malformed snapshot %s", s.Hash)
+		}
+		return s.Signature.FullVerify(publics, threshold, s.Hash[:])
+	})
+}
+
+func benchWrite(name string, duration time.Duration, write func(*common.VersionedTransaction) error) (*benchResult, error) {
+	key := benchKey()
+	return benchRun(name, "IOPS", duration, func(i int) error {
+		ver, err := benchTransaction(key, []byte(fmt.Sprintf("%s %d", name, i)))
+		if err != nil {
+			return err
+		}
+		return write(ver)
+	})
+}
+
+func benchTransaction(key crypto.Key, extra []byte) (*common.VersionedTransaction, error) {
+	seed := make([]byte, 64)
+	_, err := rand.Read(seed)
+	if err != nil {
+		return nil, err
+	}
+	addr := common.NewAddressFromSeed(seed)
+	tx := common.NewTransaction(common.XINAssetId)
+	tx.AddInput(crypto.NewHash(extra), 0)
+	tx.AddScriptOutput([]*common.Address{&addr}, common.NewThresholdScript(1), common.NewIntegerFromString("1"), seed)
+	tx.Extra = extra
+	ver := tx.AsLatestVersion()
+	sig := key.Sign(ver.PayloadMarshal())
+	ver.SignaturesMap = []map[uint16]*crypto.Signature{{0: &sig}}
+	return ver, nil
+}
+
+func benchKey() crypto.Key {
+	seed := make([]byte, 64)
+	_, err := rand.Read(seed)
+	if err != nil {
+		panic(err)
+	}
+	return crypto.NewKeyFromSeed(seed)
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
@@ -184,22 +185,45 @@ func validateGraphEntries(c *cli.Context) error {
 	return nil
 }
 
-func decodeTransactionCmd(c *cli.Context) error {
-	raw, err := hex.DecodeString(c.String("raw"))
+func exportSnapshotsCmd(c *cli.Context) error {
+	custom, err := config.Initialize(c.String("dir") + "/config.toml")
 	if err != nil {
 		return err
 	}
-	ver, err := common.UnmarshalVersionedTransaction(raw)
+
+	f, err := os.ReadFile(c.String("dir") + "/genesis.json")
 	if err != nil {
 		return err
 	}
-	m := transactionToMap(ver)
-	data, err := json.MarshalIndent(m, "", "  ")
+	var gns kernel.Genesis
+	err = json.Unmarshal(f, &gns)
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(data))
-	return nil
+	data, err := json.Marshal(gns)
+	if err != nil {
+		return err
+	}
+	networkId := crypto.NewHash(data)
+
+	store, err := storage.NewBadgerStore(custom, c.String("dir"))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	file, err := os.OpenFile(c.String("file"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	count, err := kernel.ExportSnapshots(store, networkId, custom.Node.Signer, file, c.Uint64("from"), c.Uint64("to"))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("exported snapshots: %d\n", count)
+	return file.Sync()
 }
 
 func buildRawTransactionCmd(c *cli.Context) error {
@@ -307,21 +331,57 @@ func buildRawTransactionCmd(c *cli.Context) error {
 
 func signTransactionCmd(c *cli.Context) error {
 	var raw signerInput
-	err := json.Unmarshal([]byte(c.String("raw")), &raw)
+	if r := c.String("raw"); r != "" {
+		err := json.Unmarshal([]byte(r), &raw)
+		if err != nil {
+			return err
+		}
+	}
+	raw.Node = c.String("node")
+	raw.Offline = c.Bool("offline")
+
+	accounts, err := parseSignerAccounts(c)
 	if err != nil {
 		return err
 	}
-	raw.Node = c.String("node")
 
-	seed, err := hex.DecodeString(c.String("seed"))
+	signed, err := aggregateSignedTransactions(c.StringSlice("signed"))
+	if err != nil {
+		return err
+	}
+	if signed == nil {
+		signed, err = buildSignerTransaction(&raw, c.String("seed"))
+		if err != nil {
+			return err
+		}
+	}
+
+	sigs := signed.SignaturesMap
+	signed.SignaturesMap = nil
+	for i := range signed.Inputs {
+		err := signInputWithAccounts(signed, raw, i, accounts, c.Bool("partial"))
+		if err != nil {
+			return err
+		}
+	}
+	signed.SignaturesMap, err = mergeSignaturesMap(signed.SignaturesMap, sigs)
 	if err != nil {
 		return err
 	}
+	fmt.Println(hex.EncodeToString(signed.Marshal()))
+	return nil
+}
+
+func buildSignerTransaction(raw *signerInput, seedStr string) (*common.VersionedTransaction, error) {
+	seed, err := hex.DecodeString(seedStr)
+	if err != nil {
+		return nil, err
+	}
 	if len(seed) != 64 {
 		seed = make([]byte, 64)
 		_, err := rand.Read(seed)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -358,19 +418,24 @@ func signTransactionCmd(c *cli.Context) error {
 
 	extra, err := hex.DecodeString(raw.Extra)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	tx.Extra = extra
+	return tx.AsLatestVersion(), nil
+}
 
-	keys := c.StringSlice("key")
+// parseSignerAccounts reads the private keys from the key flags, the view
+// and spend flags, and the BIP39 mnemonic, whose seed derives the account
+// as common.NewAddressFromSeed.
+func parseSignerAccounts(c *cli.Context) ([]*common.Address, error) {
 	var accounts []*common.Address
-	for _, s := range keys {
+	for _, s := range c.StringSlice("key") {
 		key, err := hex.DecodeString(s)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(key) != 64 {
-			return fmt.Errorf("invalid key length %d", len(key))
+			return nil, fmt.Errorf("invalid key length %d", len(key))
 		}
 		var account common.Address
 		copy(account.PrivateViewKey[:], key[:32])
@@ -378,21 +443,146 @@ func signTransactionCmd(c *cli.Context) error {
 		accounts = append(accounts, &account)
 	}
 
-	signed := tx.AsLatestVersion()
-	for i := range signed.Inputs {
-		err := signed.SignInput(raw, i, accounts)
+	if c.String("view") != "" || c.String("spend") != "" {
+		view, err := crypto.KeyFromString(c.String("view"))
 		if err != nil {
-			return err
+			return nil, err
+		}
+		spend, err := crypto.KeyFromString(c.String("spend"))
+		if err != nil {
+			return nil, err
 		}
+		accounts = append(accounts, &common.Address{
+			PrivateViewKey:  view,
+			PrivateSpendKey: spend,
+			PublicViewKey:   view.Public(),
+			PublicSpendKey:  spend.Public(),
+		})
 	}
-	fmt.Println(hex.EncodeToString(signed.Marshal()))
-	return nil
+
+	if m := c.String("mnemonic"); m != "" {
+		seed, err := mnemonicSeed(m, c.String("passphrase"))
+		if err != nil {
+			return nil, err
+		}
+		account := common.NewAddressFromSeed(seed)
+		accounts = append(accounts, &account)
+	}
+	return accounts, nil
+}
+
+// signInputWithAccounts signs the input with the accounts owning its keys,
+// which must be all of them unless partial, so that each multisig signer
+// signs its own part offline, to be aggregated with the signed flags.
+func signInputWithAccounts(signed *common.VersionedTransaction, raw signerInput, index int, accounts []*common.Address, partial bool) error {
+	in := signed.Inputs[index]
+	if in.Deposit != nil || in.Mint != nil || !partial {
+		if len(accounts) == 0 {
+			signed.SignaturesMap = append(signed.SignaturesMap, map[uint16]*crypto.Signature{})
+			return nil
+		}
+		return signed.SignInput(raw, index, accounts)
+	}
+
+	utxo, err := raw.ReadUTXOKeys(in.Hash, in.Index)
+	if err != nil {
+		return err
+	}
+	keys := make(map[crypto.Key]bool)
+	for _, k := range utxo.Keys {
+		keys[*k] = true
+	}
+	var owners []*common.Address
+	for _, acc := range accounts {
+		priv := crypto.DeriveGhostPrivateKey(&utxo.Mask, &acc.PrivateViewKey, &acc.PrivateSpendKey, uint64(in.Index))
+		if keys[priv.Public()] {
+			owners = append(owners, acc)
+		}
+	}
+	if len(owners) == 0 {
+		signed.SignaturesMap = append(signed.SignaturesMap, map[uint16]*crypto.Signature{})
+		return nil
+	}
+	return signed.SignInput(raw, index, owners)
+}
+
+// aggregateSignedTransactions merges the signatures of the partially signed
+// raw transactions, which must have the same payload.
+func aggregateSignedTransactions(raws []string) (*common.VersionedTransaction, error) {
+	var signed *common.VersionedTransaction
+	for _, r := range raws {
+		b, err := hex.DecodeString(r)
+		if err != nil {
+			return nil, err
+		}
+		ver, err := common.UnmarshalVersionedTransaction(b)
+		if err != nil {
+			return nil, err
+		}
+		if ver.Version != common.TxVersion {
+			return nil, fmt.Errorf("invalid signed transaction version %d", ver.Version)
+		}
+		if ver.AggregatedSignature != nil {
+			return nil, fmt.Errorf("invalid aggregated signature transaction %s", ver.PayloadHash())
+		}
+		if signed == nil {
+			signed = ver
+			continue
+		}
+		if ver.PayloadHash() != signed.PayloadHash() {
+			return nil, fmt.Errorf("invalid signed transaction payload %s %s", ver.PayloadHash(), signed.PayloadHash())
+		}
+		signed.SignaturesMap, err = mergeSignaturesMap(signed.SignaturesMap, ver.SignaturesMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return signed, nil
+}
+
+func mergeSignaturesMap(dst, src []map[uint16]*crypto.Signature) ([]map[uint16]*crypto.Signature, error) {
+	if len(src) == 0 {
+		return dst, nil
+	}
+	if len(dst) == 0 {
+		return src, nil
+	}
+	if len(dst) != len(src) {
+		return nil, fmt.Errorf("invalid signatures count %d %d", len(dst), len(src))
+	}
+	for i, sigs := range src {
+		if dst[i] == nil {
+			dst[i] = make(map[uint16]*crypto.Signature)
+		}
+		for k, sig := range sigs {
+			dst[i][k] = sig
+		}
+	}
+	return dst, nil
 }
 
 func sendTransactionCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "sendrawtransaction", []interface{}{
-		c.String("raw"),
-	}, c.Bool("time"))
+	params := []interface{}{c.String("raw")}
+	if c.Bool("trace") {
+		params = append(params, true)
+	}
+	data, err := callRPC(c.String("node"), "sendrawtransaction", params, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func validateTransactionCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "validaterawtransaction", []interface{}{c.String("raw")}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func listDomainChainsCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listdomainchains", []interface{}{c.String("chain")}, c.Bool("time"))
 	if err == nil {
 		fmt.Println(string(data))
 	}
@@ -544,6 +734,172 @@ func cancelNodeCmd(c *cli.Context) error {
 	return nil
 }
 
+func signNodeRemovalCmd(c *cli.Context) error {
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	accept, err := crypto.HashFromString(c.String("accept"))
+	if err != nil {
+		return err
+	}
+	e := common.SignNodeRemoval(key, accept, uint64(time.Now().UnixNano()))
+	fmt.Println(hex.EncodeToString(common.EncodeNodeRemovalEndorsements([]*common.NodeRemovalEndorsement{e})))
+	return nil
+}
+
+func signGovernanceSignalCmd(c *cli.Context) error {
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	proposal, err := crypto.HashFromString(c.String("proposal"))
+	if err != nil {
+		return err
+	}
+	weight := c.Uint("weight")
+	if weight > common.GovernanceSignalWeightMaximum {
+		return fmt.Errorf("invalid weight %d", weight)
+	}
+	inputs, err := parseExtraInputs(c.String("inputs"))
+	if err != nil {
+		return err
+	}
+	s := common.SignGovernanceSignal(key, proposal, uint16(weight), inputs)
+	fmt.Println(hex.EncodeToString(s.Encode()))
+	return nil
+}
+
+// parseExtraInputs parses the inputs of the transaction to carry a signed
+// extra, which must be the same inputs in the same order.
+func parseExtraInputs(s string) ([]*common.Input, error) {
+	var inputs []*common.Input
+	for _, in := range strings.Split(s, ",") {
+		parts := strings.Split(in, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid input %s", in)
+		}
+		hash, err := crypto.HashFromString(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		index, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, &common.Input{Hash: hash, Index: int(index)})
+	}
+	return inputs, nil
+}
+
+func signNodeModifyCmd(c *cli.Context) error {
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	payee, err := common.NewAddressFromString(c.String("payee"))
+	if err != nil {
+		return err
+	}
+	if payee.PublicSpendKey.DeterministicHashDerive().Public() != payee.PublicViewKey {
+		return fmt.Errorf("invalid payee view key %s", payee.String())
+	}
+	inputs, err := parseExtraInputs(c.String("inputs"))
+	if err != nil {
+		return err
+	}
+	m := common.SignNodeModify(key, payee.PublicSpendKey, inputs)
+	fmt.Println(hex.EncodeToString(m.Encode()))
+	return nil
+}
+
+func parseCustodianKeys(c *cli.Context) ([]crypto.Key, error) {
+	var keys []crypto.Key
+	for _, s := range strings.Split(c.String("keys"), ",") {
+		key, err := crypto.KeyFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// parseCustodianPrevious parses the transaction of the current custodian set,
+// which is empty to register the first set.
+func parseCustodianPrevious(c *cli.Context) (crypto.Hash, error) {
+	if c.String("previous") == "" {
+		return crypto.Hash{}, nil
+	}
+	return crypto.HashFromString(c.String("previous"))
+}
+
+func signCustodianUpdateCmd(c *cli.Context) error {
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	keys, err := parseCustodianKeys(c)
+	if err != nil {
+		return err
+	}
+	previous, err := parseCustodianPrevious(c)
+	if err != nil {
+		return err
+	}
+	s := common.SignCustodianUpdate(key, previous, uint8(c.Uint("threshold")), keys)
+	fmt.Println(hex.EncodeToString(append(s.Signer[:], s.Signature[:]...)))
+	return nil
+}
+
+func buildCustodianUpdateCmd(c *cli.Context) error {
+	keys, err := parseCustodianKeys(c)
+	if err != nil {
+		return err
+	}
+	previous, err := parseCustodianPrevious(c)
+	if err != nil {
+		return err
+	}
+	update := &common.CustodianUpdate{
+		Previous:  previous,
+		Threshold: uint8(c.Uint("threshold")),
+		Keys:      keys,
+	}
+	for _, s := range strings.Split(c.String("signatures"), ",") {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		if len(b) != len(crypto.Key{})+len(crypto.Signature{}) {
+			return fmt.Errorf("invalid custodian signature %s", s)
+		}
+		var cs common.CustodianSignature
+		copy(cs.Signer[:], b)
+		copy(cs.Signature[:], b[len(cs.Signer):])
+		update.Signatures = append(update.Signatures, &cs)
+	}
+	extra := update.Encode()
+	_, err = common.DecodeCustodianUpdate(extra)
+	if err != nil {
+		return err
+	}
+	fmt.Println(hex.EncodeToString(extra))
+	return nil
+}
+
+func buildNodeRemovalProposalCmd(c *cli.Context) error {
+	endorsements := strings.Split(c.String("endorsements"), ",")
+	data, err := callRPC(c.String("node"), "buildnoderemovalproposal", []interface{}{
+		c.String("accept"),
+		strings.Join(endorsements, ""),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
 func decodePledgeNodeCmd(c *cli.Context) error {
 	b, err := hex.DecodeString(c.String("raw"))
 	if err != nil {
@@ -599,6 +955,22 @@ func getRoundByNumberCmd(c *cli.Context) error {
 	return err
 }
 
+func graphDumpCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "graphdump", []interface{}{
+		c.Uint64("rounds"),
+		c.String("format"),
+	}, c.Bool("time"))
+	if err != nil {
+		return err
+	}
+	var graph string
+	err = json.Unmarshal(data, &graph)
+	if err == nil {
+		fmt.Print(graph)
+	}
+	return err
+}
+
 func getRoundByHashCmd(c *cli.Context) error {
 	data, err := callRPC(c.String("node"), "getroundbyhash", []interface{}{
 		c.String("hash"),
@@ -610,21 +982,43 @@ func getRoundByHashCmd(c *cli.Context) error {
 }
 
 func listSnapshotsCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "listsnapshots", []interface{}{
+	params := []interface{}{
 		c.Uint64("since"),
 		c.Uint64("count"),
 		c.Bool("sig"),
 		c.Bool("tx"),
-	}, c.Bool("time"))
+	}
+	filter := make(map[string]interface{})
+	for _, name := range []string{"round-start", "round-end", "timestamp-start", "timestamp-end"} {
+		if c.IsSet(name) {
+			filter[strings.ReplaceAll(name, "-", "_")] = c.Uint64(name)
+		}
+	}
+	if c.IsSet("node-id") {
+		filter["node"] = c.String("node-id")
+	}
+	if c.Bool("mint") {
+		filter["mint"] = true
+	}
+	if c.Bool("node-state") {
+		filter["node_state"] = true
+	}
+	if len(filter) > 0 {
+		params = append(params, filter)
+	}
+	data, err := callRPC(c.String("node"), "listsnapshots", params, c.Bool("time"))
 	if err == nil {
 		fmt.Println(string(data))
 	}
 	return err
 }
 
-func getSnapshotCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "getsnapshot", []interface{}{
-		c.String("hash"),
+func readCursorCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "readcursor", []interface{}{
+		c.String("name"),
+		c.Uint64("count"),
+		c.Bool("sig"),
+		c.Bool("tx"),
 	}, c.Bool("time"))
 	if err == nil {
 		fmt.Println(string(data))
@@ -632,9 +1026,9 @@ func getSnapshotCmd(c *cli.Context) error {
 	return err
 }
 
-func getTransactionCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "gettransaction", []interface{}{
-		c.String("hash"),
+func getCursorCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getcursor", []interface{}{
+		c.String("name"),
 	}, c.Bool("time"))
 	if err == nil {
 		fmt.Println(string(data))
@@ -642,9 +1036,10 @@ func getTransactionCmd(c *cli.Context) error {
 	return err
 }
 
-func getCacheTransactionCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "getcachetransaction", []interface{}{
-		c.String("hash"),
+func setCursorCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "setcursor", []interface{}{
+		c.String("name"),
+		c.Uint64("offset"),
 	}, c.Bool("time"))
 	if err == nil {
 		fmt.Println(string(data))
@@ -652,10 +1047,9 @@ func getCacheTransactionCmd(c *cli.Context) error {
 	return err
 }
 
-func getUTXOCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "getutxo", []interface{}{
-		c.String("hash"),
-		c.Uint64("index"),
+func removeCursorCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "removecursor", []interface{}{
+		c.String("name"),
 	}, c.Bool("time"))
 	if err == nil {
 		fmt.Println(string(data))
@@ -663,18 +1057,227 @@ func getUTXOCmd(c *cli.Context) error {
 	return err
 }
 
-func getKeyCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "getkey", []interface{}{
-		c.String("key"),
-	}, c.Bool("time"))
+func listCursorsCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listcursors", []interface{}{}, c.Bool("time"))
 	if err == nil {
 		fmt.Println(string(data))
 	}
 	return err
 }
 
-func listMintWorksCmd(c *cli.Context) error {
-	data, err := callRPC(c.String("node"), "listmintworks", []interface{}{
+func getSnapshotCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getsnapshot", []interface{}{
+		c.String("hash"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getTransactionCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "gettransaction", []interface{}{
+		c.String("hash"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getCacheTransactionCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getcachetransaction", []interface{}{
+		c.String("hash"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getUTXOCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getutxo", []interface{}{
+		c.String("hash"),
+		c.Uint64("index"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getFinalizationBundleCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getfinalizationbundle", []interface{}{
+		c.String("hash"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getAtomicSwapCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getatomicswap", []interface{}{
+		c.String("hash"),
+		c.Uint64("index"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func buildAtomicSwapScriptCmd(c *cli.Context) error {
+	var secret []byte
+	var hashlock crypto.Hash
+	switch {
+	case c.String("secret") != "" && c.String("hashlock") != "":
+		return fmt.Errorf("both secret and hashlock provided")
+	case c.String("hashlock") != "":
+		h, err := crypto.HashFromString(c.String("hashlock"))
+		if err != nil {
+			return err
+		}
+		hashlock = h
+	case c.String("secret") != "":
+		s, err := hex.DecodeString(c.String("secret"))
+		if err != nil {
+			return err
+		}
+		secret = s
+	default:
+		secret = make([]byte, 32)
+		_, err := rand.Read(secret)
+		if err != nil {
+			return err
+		}
+	}
+	if len(secret) > common.ExtraSizeLimit {
+		return fmt.Errorf("invalid secret size %d", len(secret))
+	}
+	if secret != nil {
+		hashlock = crypto.NewHash(secret)
+	}
+
+	threshold, refundThreshold, refundKeys := c.Uint("threshold"), c.Uint("refund-threshold"), c.Uint("refund-keys")
+	if threshold > common.Operator64 || refundThreshold > common.Operator64 || refundKeys > common.Operator64 {
+		return fmt.Errorf("invalid thresholds %d %d %d", threshold, refundThreshold, refundKeys)
+	}
+	s := common.NewHashlockScript(uint8(threshold), hashlock, c.Uint64("expire"), uint8(refundThreshold), uint8(refundKeys))
+	err := s.VerifyFormat()
+	if err != nil {
+		return err
+	}
+	swap := map[string]interface{}{
+		"hashlock": hashlock,
+		"script":   s,
+	}
+	if secret != nil {
+		swap["secret"] = hex.EncodeToString(secret)
+	}
+	data, err := json.MarshalIndent(swap, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func signBootstrapListCmd(c *cli.Context) error {
+	networkId, err := crypto.HashFromString(c.String("network"))
+	if err != nil {
+		return err
+	}
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	var peers []string
+	for _, p := range strings.Split(c.String("peers"), ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(p); err != nil {
+			return fmt.Errorf("invalid peer %s %v", p, err)
+		}
+		peers = append(peers, p)
+	}
+	if len(peers) == 0 {
+		return fmt.Errorf("invalid peers %s", c.String("peers"))
+	}
+	l := kernel.SignBootstrapList(networkId, peers, key)
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func getKeyCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getkey", []interface{}{
+		c.String("key"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func listOutputsForKeyCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listoutputsforkey", []interface{}{
+		c.String("view"),
+		c.String("spend"),
+		c.Uint64("since"),
+		c.Uint64("count"),
+		c.String("filter"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func diffOutputsCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "diffoutputs", []interface{}{
+		c.Uint64("from"),
+		c.Uint64("to"),
+		c.String("asset"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getAttestationCmd(c *cli.Context) error {
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	args := []string{c.String("fact"), c.String("hash")}
+	if c.String("fact") == "output" {
+		args = append(args, fmt.Sprint(c.Uint64("index")))
+	}
+	args = append(args, fmt.Sprint(c.Uint64("topology")), fmt.Sprint(time.Now().UnixNano()))
+	msg := crypto.NewHash([]byte(strings.Join(args, ":")))
+	sig := key.Sign(msg[:])
+
+	params := make([]interface{}, 0)
+	for _, a := range args {
+		params = append(params, a)
+	}
+	params = append(params, key.Public().String(), sig.String())
+	data, err := callRPC(c.String("node"), "getattestation", params, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func listMintWorksCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listmintworks", []interface{}{
 		c.Uint64("since"),
 	}, c.Bool("time"))
 	if err == nil {
@@ -683,6 +1286,17 @@ func listMintWorksCmd(c *cli.Context) error {
 	return err
 }
 
+func mintSimulateCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "mintsimulate", []interface{}{
+		c.Uint64("start"),
+		c.Uint64("end"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
 func listMintDistributionsCmd(c *cli.Context) error {
 	data, err := callRPC(c.String("node"), "listmintdistributions", []interface{}{
 		c.Uint64("since"),
@@ -695,6 +1309,17 @@ func listMintDistributionsCmd(c *cli.Context) error {
 	return err
 }
 
+func listFeeStatsCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listfeestats", []interface{}{
+		c.Uint64("since"),
+		c.Uint64("count"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
 func listAllNodesCmd(c *cli.Context) error {
 	data, err := callRPC(c.String("node"), "listallnodes", []interface{}{
 		c.Uint64("threshold"),
@@ -706,6 +1331,205 @@ func listAllNodesCmd(c *cli.Context) error {
 	return err
 }
 
+func listConsensusNodesCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listconsensusnodes", []interface{}{
+		c.Uint64("timestamp"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getCustodianCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getcustodian", []interface{}{
+		c.Uint64("timestamp"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getGovernanceTallyCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getgovernancetally", []interface{}{
+		c.String("proposal"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func listNodeModificationsCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listnodemodifications", []interface{}{
+		c.Bool("pending"),
+	}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func listStalePeersCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "liststalepeers", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func exportPeersCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "exportpeers", []interface{}{}, c.Bool("time"))
+	if err != nil {
+		return err
+	}
+	if c.String("file") == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	var result struct {
+		Book  string        `json:"book"`
+		Peers []interface{} `json:"peers"`
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(c.String("file"), []byte(result.Book+"\n"), 0644)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d peers exported to %s\n", len(result.Peers), c.String("file"))
+	return nil
+}
+
+func importPeersCmd(c *cli.Context) error {
+	book, err := os.ReadFile(c.String("file"))
+	if err != nil {
+		return err
+	}
+	params := []interface{}{strings.TrimSpace(string(book))}
+	data, err := callRPC(c.String("node"), "importpeers", params, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getPartitionCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getpartition", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func listSignerStatsCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listsignerstats", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func listCheckpointsCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "listcheckpoints", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getPeerGraphCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getpeergraph", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+// collectPeerGraphCmd fetches the signed peer graphs from the nodes willing
+// to share them, and aggregates the verified ones into a topology view.
+func collectPeerGraphCmd(c *cli.Context) error {
+	var endpoints []string
+	for _, n := range strings.Split(c.String("nodes"), ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			endpoints = append(endpoints, n)
+		}
+	}
+	if len(endpoints) == 0 {
+		return fmt.Errorf("no nodes to collect")
+	}
+
+	var network crypto.Hash
+	failures := make(map[string]string)
+	reported := make(map[crypto.Hash]bool)
+	seen := make(map[crypto.Hash]bool)
+	edges := make([]map[string]interface{}, 0)
+	for _, endpoint := range endpoints {
+		data, err := callRPC(endpoint, "getpeergraph", []interface{}{}, false)
+		if err != nil {
+			failures[endpoint] = err.Error()
+			continue
+		}
+		var g kernel.PeerGraph
+		err = json.Unmarshal(data, &g)
+		if err != nil {
+			failures[endpoint] = err.Error()
+			continue
+		}
+		err = kernel.VerifyPeerGraph(&g)
+		if err != nil {
+			failures[endpoint] = err.Error()
+			continue
+		}
+		if network.HasValue() && g.Network != network {
+			failures[endpoint] = fmt.Sprintf("invalid network %s", g.Network)
+			continue
+		}
+		if reported[g.Node] {
+			continue
+		}
+		network = g.Network
+		reported[g.Node] = true
+		for _, l := range g.Links {
+			seen[l.Peer] = true
+			edges = append(edges, map[string]interface{}{
+				"from":      g.Node,
+				"to":        l.Peer,
+				"address":   l.Address,
+				"latency":   l.Latency,
+				"connected": l.Connected,
+				"received":  l.Received,
+			})
+		}
+	}
+
+	unreported := make([]crypto.Hash, 0)
+	for id := range seen {
+		if !reported[id] {
+			unreported = append(unreported, id)
+		}
+	}
+	nodes := make([]crypto.Hash, 0)
+	for id := range reported {
+		nodes = append(nodes, id)
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"network":    network,
+		"nodes":      nodes,
+		"unreported": unreported,
+		"edges":      edges,
+		"failures":   failures,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
 func getInfoCmd(c *cli.Context) error {
 	data, err := callRPC(c.String("node"), "getinfo", []interface{}{}, c.Bool("time"))
 	if err == nil {
@@ -714,6 +1538,38 @@ func getInfoCmd(c *cli.Context) error {
 	return err
 }
 
+func getHealthCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "gethealth", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getNodeStatusCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getnodestatus", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getStorageInfoCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "storageinfo", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func getUpgradeReadinessCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "getupgradereadiness", []interface{}{}, c.Bool("time"))
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
 func dumpGraphHeadCmd(c *cli.Context) error {
 	data, err := callRPC(c.String("node"), "dumpgraphhead", []interface{}{}, c.Bool("time"))
 	if err == nil {
@@ -722,6 +1578,45 @@ func dumpGraphHeadCmd(c *cli.Context) error {
 	return err
 }
 
+func describeSchemaCmd(c *cli.Context) error {
+	data, err := callRPC(c.String("node"), "describeschema", []interface{}{}, c.Bool("time"))
+	if err != nil {
+		return err
+	}
+	dir := c.String("output")
+	if dir == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	var schema struct {
+		Version int                        `json:"version"`
+		Hash    string                     `json:"hash"`
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	}
+	err = json.Unmarshal(data, &schema)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	for name, s := range schema.Schemas {
+		var out bytes.Buffer
+		err = json.Indent(&out, s, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(dir+"/"+name+".json", out.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Printf("schema v%d %s\n", schema.Version, schema.Hash)
+	return nil
+}
+
 func setupTestNetCmd(c *cli.Context) error {
 	var signers, payees []common.Address
 
@@ -759,6 +1654,13 @@ func setupTestNetCmd(c *cli.Context) error {
 			},
 		},
 	}
+	round, err := parseGenesisRound(c)
+	if err != nil {
+		return err
+	}
+	if round != nil {
+		genesis["round"] = round
+	}
 	genesisData, err := json.MarshalIndent(genesis, "", "  ")
 	if err != nil {
 		return err
@@ -879,9 +1781,10 @@ type signerInput struct {
 		Script   common.Script     `json:"script"`
 		Accounts []*common.Address `json:"accounts"`
 	}
-	Asset crypto.Hash `json:"asset"`
-	Extra string      `json:"extra"`
-	Node  string      `json:"-"`
+	Asset   crypto.Hash `json:"asset"`
+	Extra   string      `json:"extra"`
+	Node    string      `json:"-"`
+	Offline bool        `json:"-"`
 }
 
 func (raw signerInput) ReadUTXOKeys(hash crypto.Hash, index int) (*common.UTXOKeys, error) {
@@ -894,6 +1797,9 @@ func (raw signerInput) ReadUTXOKeys(hash crypto.Hash, index int) (*common.UTXOKe
 			return utxo, nil
 		}
 	}
+	if raw.Offline {
+		return nil, fmt.Errorf("input keys missing %s#%d", hash.String(), index)
+	}
 
 	data, err := callRPC(raw.Node, "getutxo", []interface{}{hash.String(), index}, false)
 	if err != nil {
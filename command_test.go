@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestMnemonicSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	seed, err := mnemonicSeed(strings.Repeat("abandon ", 11)+"about", "TREZOR")
+	assert.Nil(err)
+	assert.Equal("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04", hex.EncodeToString(seed))
+
+	for _, m := range []string{
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		strings.Repeat("zoo ", 11) + "wrong",
+		strings.Repeat("abandon ", 23) + "art",
+	} {
+		_, err := mnemonicSeed(m, "")
+		assert.Nil(err, m)
+	}
+
+	_, err = mnemonicSeed(strings.Repeat("abandon ", 12), "")
+	assert.Contains(err.Error(), "checksum")
+	_, err = mnemonicSeed(strings.Repeat("abandon ", 11)+"abouts", "")
+	assert.Contains(err.Error(), "word")
+	_, err = mnemonicSeed(strings.Repeat("abandon ", 10)+"about", "")
+	assert.Contains(err.Error(), "count")
+}
+
+func TestSignMultisigInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	accounts := make([]*common.Address, 3)
+	for i := range accounts {
+		seed := make([]byte, 64)
+		seed[0] = byte(i)
+		account := common.NewAddressFromSeed(seed)
+		accounts[i] = &account
+	}
+	seed := make([]byte, 64)
+	amount := common.NewIntegerFromString("1")
+	source := common.NewTransaction(common.XINAssetId)
+	source.AddScriptOutput(accounts[:2], common.NewThresholdScript(2), amount, seed)
+	out := source.Outputs[0]
+
+	var raw signerInput
+	rb, _ := json.Marshal(map[string]interface{}{
+		"inputs": []map[string]interface{}{{
+			"hash":  crypto.NewHash([]byte("source")),
+			"index": 0,
+			"keys":  out.Keys,
+			"mask":  out.Mask,
+		}},
+	})
+	assert.Nil(json.Unmarshal(rb, &raw))
+	raw.Offline = true
+	build := func() *common.VersionedTransaction {
+		tx := common.NewTransaction(common.XINAssetId)
+		tx.AddInput(raw.Inputs[0].Hash, 0)
+		tx.AddScriptOutput(accounts[2:], common.NewThresholdScript(1), amount, seed)
+		return tx.AsLatestVersion()
+	}
+
+	signed := build()
+	err := signInputWithAccounts(signed, raw, 0, accounts[2:], false)
+	assert.NotNil(err)
+	signed = build()
+	assert.Nil(signInputWithAccounts(signed, raw, 0, accounts[2:], true))
+	assert.Len(signed.SignaturesMap, 1)
+	assert.Len(signed.SignaturesMap[0], 0)
+
+	var partials []string
+	for i := 0; i < 2; i++ {
+		signed := build()
+		err := signInputWithAccounts(signed, raw, 0, accounts, true)
+		assert.Nil(err)
+		assert.Len(signed.SignaturesMap[0], 2)
+		signed = build()
+		err = signInputWithAccounts(signed, raw, 0, accounts[i:i+1], true)
+		assert.Nil(err)
+		assert.Len(signed.SignaturesMap[0], 1)
+		assert.NotNil(signed.SignaturesMap[0][uint16(i)])
+		partials = append(partials, hex.EncodeToString(signed.Marshal()))
+	}
+
+	signed, err = aggregateSignedTransactions(partials)
+	assert.Nil(err)
+	assert.Len(signed.SignaturesMap, 1)
+	assert.Len(signed.SignaturesMap[0], 2)
+	full := build()
+	assert.Nil(signInputWithAccounts(full, raw, 0, accounts[:2], false))
+	assert.Equal(full.SignaturesMap, signed.SignaturesMap)
+
+	other := build()
+	other.Extra = []byte("other")
+	assert.Nil(signInputWithAccounts(other, raw, 0, accounts[:1], true))
+	_, err = aggregateSignedTransactions(append(partials, hex.EncodeToString(other.Marshal())))
+	assert.Contains(err.Error(), "payload")
+	signed, err = aggregateSignedTransactions(nil)
+	assert.Nil(err)
+	assert.Nil(signed)
+}
+
+func TestMergeSignaturesMap(t *testing.T) {
+	assert := assert.New(t)
+
+	var a, b crypto.Signature
+	a[0], b[0] = 1, 2
+	dst := []map[uint16]*crypto.Signature{{0: &a}, nil}
+	src := []map[uint16]*crypto.Signature{{1: &b}, {0: &b}}
+
+	merged, err := mergeSignaturesMap(dst, nil)
+	assert.Nil(err)
+	assert.Equal(dst, merged)
+	merged, err = mergeSignaturesMap(nil, src)
+	assert.Nil(err)
+	assert.Equal(src, merged)
+	_, err = mergeSignaturesMap(dst, src[:1])
+	assert.NotNil(err)
+
+	merged, err = mergeSignaturesMap(dst, src)
+	assert.Nil(err)
+	assert.Len(merged, 2)
+	assert.Equal(&a, merged[0][0])
+	assert.Equal(&b, merged[0][1])
+	assert.Equal(&b, merged[1][0])
+}
+
+func TestParseGenesisRound(t *testing.T) {
+	assert := assert.New(t)
+
+	context := func(args ...string) *cli.Context {
+		set := flag.NewFlagSet("setuptestnet", flag.ContinueOnError)
+		set.Uint64("round-gap", 0, "")
+		set.Uint64("reference-threshold", 0, "")
+		err := set.Parse(args)
+		assert.Nil(err)
+		return cli.NewContext(nil, set, nil)
+	}
+
+	round, err := parseGenesisRound(context())
+	assert.Nil(err)
+	assert.Nil(round)
+	round, err = parseGenesisRound(context("--round-gap", "500"))
+	assert.Nil(err)
+	assert.Equal(uint64(500), round.Gap)
+	assert.Equal(uint64(10), round.Threshold)
+	round, err = parseGenesisRound(context("--reference-threshold", "4"))
+	assert.Nil(err)
+	assert.Equal(uint64(3000), round.Gap)
+	assert.Equal(uint64(4), round.Threshold)
+	round, err = parseGenesisRound(context("--round-gap", "500", "--reference-threshold", "4"))
+	assert.Nil(err)
+	assert.Equal(uint64(500), round.Gap)
+	assert.Equal(uint64(4), round.Threshold)
+
+	_, err = parseGenesisRound(context("--round-gap", "50"))
+	assert.Contains(err.Error(), "invalid snapshot round gap")
+	_, err = parseGenesisRound(context("--reference-threshold", "1"))
+	assert.Contains(err.Error(), "invalid snapshot reference threshold")
+}
@@ -2,16 +2,19 @@ package common
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/domains/akash"
 	"github.com/MixinNetwork/mixin/domains/algorand"
+	"github.com/MixinNetwork/mixin/domains/aptos"
 	"github.com/MixinNetwork/mixin/domains/arweave"
 	"github.com/MixinNetwork/mixin/domains/avalanche"
 	"github.com/MixinNetwork/mixin/domains/bch"
 	"github.com/MixinNetwork/mixin/domains/binance"
 	"github.com/MixinNetwork/mixin/domains/bitcoin"
 	"github.com/MixinNetwork/mixin/domains/bsv"
+	"github.com/MixinNetwork/mixin/domains/cardano"
 	"github.com/MixinNetwork/mixin/domains/cosmos"
 	"github.com/MixinNetwork/mixin/domains/dash"
 	"github.com/MixinNetwork/mixin/domains/decred"
@@ -20,23 +23,28 @@ import (
 	"github.com/MixinNetwork/mixin/domains/eos"
 	"github.com/MixinNetwork/mixin/domains/etc"
 	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/MixinNetwork/mixin/domains/evm"
 	"github.com/MixinNetwork/mixin/domains/filecoin"
 	"github.com/MixinNetwork/mixin/domains/handshake"
+	"github.com/MixinNetwork/mixin/domains/hedera"
 	"github.com/MixinNetwork/mixin/domains/horizen"
 	"github.com/MixinNetwork/mixin/domains/kusama"
 	"github.com/MixinNetwork/mixin/domains/litecoin"
 	"github.com/MixinNetwork/mixin/domains/mobilecoin"
+	"github.com/MixinNetwork/mixin/domains/monacoin"
 	"github.com/MixinNetwork/mixin/domains/monero"
 	"github.com/MixinNetwork/mixin/domains/namecoin"
 	"github.com/MixinNetwork/mixin/domains/near"
 	"github.com/MixinNetwork/mixin/domains/nervos"
+	"github.com/MixinNetwork/mixin/domains/osmosis"
+	"github.com/MixinNetwork/mixin/domains/peercoin"
 	"github.com/MixinNetwork/mixin/domains/polkadot"
-	"github.com/MixinNetwork/mixin/domains/polygon"
 	"github.com/MixinNetwork/mixin/domains/ravencoin"
 	"github.com/MixinNetwork/mixin/domains/ripple"
 	"github.com/MixinNetwork/mixin/domains/siacoin"
 	"github.com/MixinNetwork/mixin/domains/solana"
 	"github.com/MixinNetwork/mixin/domains/stellar"
+	"github.com/MixinNetwork/mixin/domains/sui"
 	"github.com/MixinNetwork/mixin/domains/tezos"
 	"github.com/MixinNetwork/mixin/domains/tron"
 	"github.com/MixinNetwork/mixin/domains/zcash"
@@ -44,6 +52,10 @@ import (
 
 var (
 	XINAssetId crypto.Hash
+
+	// NonFungibleSupply is the only valid amount of a non-fungible asset,
+	// each token of the object or NFT chains has its own asset id.
+	NonFungibleSupply Integer
 )
 
 type Asset struct {
@@ -53,6 +65,7 @@ type Asset struct {
 
 func init() {
 	XINAssetId = crypto.NewHash([]byte("c94ac88f-4671-3976-b60a-09064f1811e8"))
+	NonFungibleSupply = NewInteger(1)
 }
 
 func (a *Asset) Verify() error {
@@ -77,6 +90,10 @@ func (a *Asset) Verify() error {
 		return ravencoin.VerifyAssetKey(a.AssetKey)
 	case namecoin.NamecoinChainId:
 		return namecoin.VerifyAssetKey(a.AssetKey)
+	case monacoin.MonacoinChainId:
+		return monacoin.VerifyAssetKey(a.AssetKey)
+	case peercoin.PeercoinChainId:
+		return peercoin.VerifyAssetKey(a.AssetKey)
 	case dash.DashChainId:
 		return dash.VerifyAssetKey(a.AssetKey)
 	case decred.DecredChainId:
@@ -127,11 +144,46 @@ func (a *Asset) Verify() error {
 		return dfinity.VerifyAssetKey(a.AssetKey)
 	case algorand.AlgorandChainId:
 		return algorand.VerifyAssetKey(a.AssetKey)
-	case polygon.PolygonChainId:
-		return polygon.VerifyAssetKey(a.AssetKey)
-	default:
-		return fmt.Errorf("invalid chain id %s", a.ChainId)
+	case sui.SuiChainId:
+		return sui.VerifyAssetKey(a.AssetKey)
+	case aptos.AptosChainId:
+		return aptos.VerifyAssetKey(a.AssetKey)
+	case cardano.CardanoChainId:
+		return cardano.VerifyAssetKey(a.AssetKey)
+	case hedera.HederaChainId:
+		return hedera.VerifyAssetKey(a.AssetKey)
+	case osmosis.OsmosisChainId:
+		return osmosis.VerifyAssetKey(a.AssetKey)
+	}
+	if c := evm.Lookup(a.ChainId); c != nil {
+		return c.VerifyAssetKey(a.AssetKey)
+	}
+	return fmt.Errorf("invalid chain id %s", a.ChainId)
+}
+
+func (a *Asset) NonFungible() bool {
+	switch a.ChainId {
+	case sui.SuiChainId:
+		return sui.IsNonFungibleKey(a.AssetKey)
+	case hedera.HederaChainId:
+		return hedera.IsNonFungibleKey(a.AssetKey)
 	}
+	return false
+}
+
+// verifyFork checks the asset key by the stricter rules of the domain fork.
+func (a *Asset) verifyFork() error {
+	switch a.ChainId {
+	case eos.EOSChainId:
+		err := eos.VerifyAccountName(strings.Split(a.AssetKey, ":")[0])
+		if err != nil {
+			return err
+		}
+		return eos.VerifySymbol(a.AssetKey)
+	case tron.TronChainId:
+		return tron.VerifyTokenId(a.AssetKey)
+	}
+	return nil
 }
 
 func (a *Asset) AssetId() crypto.Hash {
@@ -156,6 +208,10 @@ func (a *Asset) AssetId() crypto.Hash {
 		return ravencoin.GenerateAssetId(a.AssetKey)
 	case namecoin.NamecoinChainId:
 		return namecoin.GenerateAssetId(a.AssetKey)
+	case monacoin.MonacoinChainId:
+		return monacoin.GenerateAssetId(a.AssetKey)
+	case peercoin.PeercoinChainId:
+		return peercoin.GenerateAssetId(a.AssetKey)
 	case dash.DashChainId:
 		return dash.GenerateAssetId(a.AssetKey)
 	case decred.DecredChainId:
@@ -206,11 +262,21 @@ func (a *Asset) AssetId() crypto.Hash {
 		return dfinity.GenerateAssetId(a.AssetKey)
 	case algorand.AlgorandChainId:
 		return algorand.GenerateAssetId(a.AssetKey)
-	case polygon.PolygonChainId:
-		return polygon.GenerateAssetId(a.AssetKey)
-	default:
-		return crypto.Hash{}
+	case sui.SuiChainId:
+		return sui.GenerateAssetId(a.AssetKey)
+	case aptos.AptosChainId:
+		return aptos.GenerateAssetId(a.AssetKey)
+	case cardano.CardanoChainId:
+		return cardano.GenerateAssetId(a.AssetKey)
+	case hedera.HederaChainId:
+		return hedera.GenerateAssetId(a.AssetKey)
+	case osmosis.OsmosisChainId:
+		return osmosis.GenerateAssetId(a.AssetKey)
 	}
+	if c := evm.Lookup(a.ChainId); c != nil {
+		return c.GenerateAssetId(a.AssetKey)
+	}
+	return crypto.Hash{}
 }
 
 func (a *Asset) FeeAssetId() crypto.Hash {
@@ -235,6 +301,10 @@ func (a *Asset) FeeAssetId() crypto.Hash {
 		return ravencoin.RavencoinChainId
 	case namecoin.NamecoinChainId:
 		return namecoin.NamecoinChainId
+	case monacoin.MonacoinChainId:
+		return monacoin.MonacoinChainId
+	case peercoin.PeercoinChainId:
+		return peercoin.PeercoinChainId
 	case dash.DashChainId:
 		return dash.DashChainId
 	case decred.DecredChainId:
@@ -285,8 +355,19 @@ func (a *Asset) FeeAssetId() crypto.Hash {
 		return dfinity.DfinityChainId
 	case algorand.AlgorandChainId:
 		return algorand.AlgorandChainId
-	case polygon.PolygonChainId:
-		return polygon.PolygonChainId
+	case sui.SuiChainId:
+		return sui.SuiChainId
+	case aptos.AptosChainId:
+		return aptos.AptosChainId
+	case cardano.CardanoChainId:
+		return cardano.CardanoChainId
+	case hedera.HederaChainId:
+		return hedera.HederaChainId
+	case osmosis.OsmosisChainId:
+		return osmosis.OsmosisChainId
+	}
+	if c := evm.Lookup(a.ChainId); c != nil {
+		return c.ChainId
 	}
 	return crypto.Hash{}
 }
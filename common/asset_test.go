@@ -0,0 +1,62 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/MixinNetwork/mixin/domains/hedera"
+	"github.com/MixinNetwork/mixin/domains/sui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonFungibleAsset(t *testing.T) {
+	assert := assert.New(t)
+
+	nft := &Asset{ChainId: sui.SuiChainId, AssetKey: "0x7d20dcdb2bca4f508ea9613994683eb4e76e9c4ed371169677c1be02aaf0b58e"}
+	coin := &Asset{ChainId: sui.SuiChainId, AssetKey: sui.NativeCoinType}
+	eth := &Asset{ChainId: ethereum.EthereumChainId, AssetKey: "0x0000000000000000000000000000000000000000"}
+	assert.True(nft.NonFungible())
+	assert.False(coin.NonFungible())
+	assert.False(eth.NonFungible())
+	assert.Nil(nft.Verify())
+	assert.Equal(sui.SuiChainId, nft.FeeAssetId())
+
+	tx := NewTransaction(nft.AssetId())
+	tx.AddDepositInput(&DepositData{
+		Chain:           nft.ChainId,
+		AssetKey:        nft.AssetKey,
+		TransactionHash: "3SivNwfPYsaSgWj8jLNvd567sVZJCDoagsobDEMkQsHT",
+		Amount:          NewIntegerFromString("0.5"),
+	})
+	assert.NotNil(tx.verifyDepositFormat())
+	tx.Inputs[0].Deposit.Amount = NewInteger(2)
+	assert.NotNil(tx.verifyDepositFormat())
+	tx.Inputs[0].Deposit.Amount = NewInteger(1)
+	assert.Nil(tx.verifyDepositFormat())
+
+	tx = NewTransaction(coin.AssetId())
+	tx.AddDepositInput(&DepositData{
+		Chain:           coin.ChainId,
+		AssetKey:        coin.AssetKey,
+		TransactionHash: "3SivNwfPYsaSgWj8jLNvd567sVZJCDoagsobDEMkQsHT",
+		Amount:          NewInteger(2),
+	})
+	assert.Nil(tx.verifyDepositFormat())
+
+	hts := &Asset{ChainId: hedera.HederaChainId, AssetKey: "0.0.1234567/42"}
+	token := &Asset{ChainId: hedera.HederaChainId, AssetKey: "0.0.456858"}
+	assert.True(hts.NonFungible())
+	assert.False(token.NonFungible())
+	assert.Nil(hts.Verify())
+
+	tx = NewTransaction(hts.AssetId())
+	tx.AddDepositInput(&DepositData{
+		Chain:           hts.ChainId,
+		AssetKey:        hts.AssetKey,
+		TransactionHash: "0.0.1234567@1615422161.073238162",
+		Amount:          NewInteger(2),
+	})
+	assert.NotNil(tx.verifyDepositFormat())
+	tx.Inputs[0].Deposit.Amount = NewInteger(1)
+	assert.Nil(tx.verifyDepositFormat())
+}
@@ -0,0 +1,152 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/akash"
+	"github.com/MixinNetwork/mixin/domains/algorand"
+	"github.com/MixinNetwork/mixin/domains/aptos"
+	"github.com/MixinNetwork/mixin/domains/arweave"
+	"github.com/MixinNetwork/mixin/domains/avalanche"
+	"github.com/MixinNetwork/mixin/domains/bch"
+	"github.com/MixinNetwork/mixin/domains/binance"
+	"github.com/MixinNetwork/mixin/domains/bitcoin"
+	"github.com/MixinNetwork/mixin/domains/bsv"
+	"github.com/MixinNetwork/mixin/domains/cardano"
+	"github.com/MixinNetwork/mixin/domains/cosmos"
+	"github.com/MixinNetwork/mixin/domains/dash"
+	"github.com/MixinNetwork/mixin/domains/decred"
+	"github.com/MixinNetwork/mixin/domains/dfinity"
+	"github.com/MixinNetwork/mixin/domains/dogecoin"
+	"github.com/MixinNetwork/mixin/domains/eos"
+	"github.com/MixinNetwork/mixin/domains/etc"
+	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/MixinNetwork/mixin/domains/evm"
+	"github.com/MixinNetwork/mixin/domains/filecoin"
+	"github.com/MixinNetwork/mixin/domains/handshake"
+	"github.com/MixinNetwork/mixin/domains/hedera"
+	"github.com/MixinNetwork/mixin/domains/horizen"
+	"github.com/MixinNetwork/mixin/domains/kusama"
+	"github.com/MixinNetwork/mixin/domains/litecoin"
+	"github.com/MixinNetwork/mixin/domains/mobilecoin"
+	"github.com/MixinNetwork/mixin/domains/monacoin"
+	"github.com/MixinNetwork/mixin/domains/monero"
+	"github.com/MixinNetwork/mixin/domains/namecoin"
+	"github.com/MixinNetwork/mixin/domains/near"
+	"github.com/MixinNetwork/mixin/domains/nervos"
+	"github.com/MixinNetwork/mixin/domains/osmosis"
+	"github.com/MixinNetwork/mixin/domains/peercoin"
+	"github.com/MixinNetwork/mixin/domains/polkadot"
+	"github.com/MixinNetwork/mixin/domains/ravencoin"
+	"github.com/MixinNetwork/mixin/domains/ripple"
+	"github.com/MixinNetwork/mixin/domains/siacoin"
+	"github.com/MixinNetwork/mixin/domains/solana"
+	"github.com/MixinNetwork/mixin/domains/stellar"
+	"github.com/MixinNetwork/mixin/domains/sui"
+	"github.com/MixinNetwork/mixin/domains/tezos"
+	"github.com/MixinNetwork/mixin/domains/tron"
+	"github.com/MixinNetwork/mixin/domains/zcash"
+)
+
+// DomainChain is the metadata of a domain chain for the services handling
+// the deposits and withdrawals, which is never used by the kernel. The
+// confirmations are recommended before a deposit is final, and a chain with
+// instant send, e.g. the dash InstantSend, could finalize a deposit locked
+// by its quorum before the confirmations.
+type DomainChain struct {
+	Name          string      `json:"name"`
+	ChainId       crypto.Hash `json:"chain"`
+	Confirmations uint64      `json:"confirmations"`
+	InstantSend   bool        `json:"instant_send"`
+}
+
+var domainChains = []*DomainChain{
+	{Name: "akash", ChainId: akash.AkashChainId, Confirmations: 1},
+	{Name: "algorand", ChainId: algorand.AlgorandChainId, Confirmations: 1},
+	{Name: "aptos", ChainId: aptos.AptosChainId, Confirmations: 1},
+	{Name: "arweave", ChainId: arweave.ArweaveChainId, Confirmations: 20},
+	{Name: "avalanche", ChainId: avalanche.AvalancheChainId, Confirmations: 1},
+	{Name: "bch", ChainId: bch.BitcoinCashChainId, Confirmations: 6},
+	{Name: "binance", ChainId: binance.BinanceChainId, Confirmations: 1},
+	{Name: "bitcoin", ChainId: bitcoin.BitcoinChainId, Confirmations: 3},
+	{Name: "bsv", ChainId: bsv.BitcoinSVChainId, Confirmations: 6},
+	{Name: "cardano", ChainId: cardano.CardanoChainId, Confirmations: 30},
+	{Name: "cosmos", ChainId: cosmos.CosmosChainId, Confirmations: 1},
+	{Name: "dash", ChainId: dash.DashChainId, Confirmations: 6, InstantSend: true},
+	{Name: "decred", ChainId: decred.DecredChainId, Confirmations: 6},
+	{Name: "dfinity", ChainId: dfinity.DfinityChainId, Confirmations: 1},
+	{Name: "dogecoin", ChainId: dogecoin.DogecoinChainId, Confirmations: 12},
+	{Name: "eos", ChainId: eos.EOSChainId, Confirmations: 330},
+	{Name: "etc", ChainId: etc.EthereumClassicChainId, Confirmations: 500},
+	{Name: "ethereum", ChainId: ethereum.EthereumChainId, Confirmations: 64},
+	{Name: "filecoin", ChainId: filecoin.FilecoinChainId, Confirmations: 900},
+	{Name: "handshake", ChainId: handshake.HandshakenChainId, Confirmations: 6},
+	{Name: "hedera", ChainId: hedera.HederaChainId, Confirmations: 1},
+	{Name: "horizen", ChainId: horizen.HorizenChainId, Confirmations: 6},
+	{Name: "kusama", ChainId: kusama.KusamaChainId, Confirmations: 1},
+	{Name: "litecoin", ChainId: litecoin.LitecoinChainId, Confirmations: 6},
+	{Name: "mobilecoin", ChainId: mobilecoin.MobileCoinChainId, Confirmations: 1},
+	{Name: "monacoin", ChainId: monacoin.MonacoinChainId, Confirmations: 6},
+	{Name: "monero", ChainId: monero.MoneroChainId, Confirmations: 10},
+	{Name: "namecoin", ChainId: namecoin.NamecoinChainId, Confirmations: 6},
+	{Name: "near", ChainId: near.NearChainId, Confirmations: 1},
+	{Name: "nervos", ChainId: nervos.NervosChainId, Confirmations: 24},
+	{Name: "osmosis", ChainId: osmosis.OsmosisChainId, Confirmations: 1},
+	{Name: "peercoin", ChainId: peercoin.PeercoinChainId, Confirmations: 6},
+	{Name: "polkadot", ChainId: polkadot.PolkadotChainId, Confirmations: 1},
+	{Name: "ravencoin", ChainId: ravencoin.RavencoinChainId, Confirmations: 60},
+	{Name: "ripple", ChainId: ripple.RippleChainId, Confirmations: 1},
+	{Name: "siacoin", ChainId: siacoin.SiacoinChainId, Confirmations: 6},
+	{Name: "solana", ChainId: solana.SolanaChainId, Confirmations: 32},
+	{Name: "stellar", ChainId: stellar.StellarChainId, Confirmations: 1},
+	{Name: "sui", ChainId: sui.SuiChainId, Confirmations: 1},
+	{Name: "tezos", ChainId: tezos.TezosChainId, Confirmations: 2},
+	{Name: "tron", ChainId: tron.TronChainId, Confirmations: 19},
+	{Name: "zcash", ChainId: zcash.ZcashChainId, Confirmations: 10},
+}
+
+var domainChainsById map[crypto.Hash]*DomainChain
+
+// init registers the EVM networks of the evm package table, which have
+// their confirmations there, so a new EVM network is never added here.
+func init() {
+	for _, c := range evm.Chains() {
+		domainChains = append(domainChains, &DomainChain{
+			Name:          c.Name,
+			ChainId:       c.ChainId,
+			Confirmations: c.Confirmations,
+		})
+	}
+	sort.Slice(domainChains, func(i, j int) bool {
+		return domainChains[i].Name < domainChains[j].Name
+	})
+	domainChainsById = make(map[crypto.Hash]*DomainChain)
+	for _, c := range domainChains {
+		if domainChainsById[c.ChainId] != nil {
+			panic(fmt.Errorf("duplicated domain chain %s %s", c.Name, c.ChainId))
+		}
+		domainChainsById[c.ChainId] = c
+	}
+}
+
+// ReadDomainChain returns the metadata of the domain chain, or nil.
+func ReadDomainChain(chainId crypto.Hash) *DomainChain {
+	c := domainChainsById[chainId]
+	if c == nil {
+		return nil
+	}
+	cc := *c
+	return &cc
+}
+
+// ListDomainChains returns the metadata of all domain chains by name.
+func ListDomainChains() []*DomainChain {
+	chains := make([]*DomainChain, len(domainChains))
+	for i, c := range domainChains {
+		cc := *c
+		chains[i] = &cc
+	}
+	return chains
+}
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/dash"
+	"github.com/MixinNetwork/mixin/domains/polygon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainChains(t *testing.T) {
+	assert := assert.New(t)
+
+	chains := ListDomainChains()
+	assert.Len(chains, 44)
+	for i, c := range chains {
+		assert.Equal(c, ReadDomainChain(c.ChainId))
+		assert.True(c.Confirmations > 0)
+		if i > 0 {
+			assert.True(chains[i-1].Name < c.Name)
+		}
+	}
+
+	c := ReadDomainChain(dash.DashChainId)
+	assert.Equal("dash", c.Name)
+	assert.Equal(uint64(6), c.Confirmations)
+	assert.True(c.InstantSend)
+	c.Confirmations = 1
+	assert.Equal(uint64(6), ReadDomainChain(dash.DashChainId).Confirmations)
+
+	c = ReadDomainChain(polygon.PolygonChainId)
+	assert.Equal("polygon", c.Name)
+	assert.Equal(uint64(128), c.Confirmations)
+	assert.False(c.InstantSend)
+
+	assert.Nil(ReadDomainChain(crypto.NewHash([]byte("00000000-0000-0000-0000-000000000000"))))
+}
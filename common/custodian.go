@@ -0,0 +1,216 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	CustodianUpdateMessagePrefix = "CUSTODIANUPDATE"
+	CustodianKeysLimit           = 16
+	CustodianSignaturesLimit     = 64
+
+	custodianSignatureSize   = len(crypto.Key{}) + len(crypto.Signature{})
+	custodianUpdateSizeLimit = len(CustodianUpdateMessagePrefix) + len(crypto.Hash{}) +
+		1 + 1 + CustodianKeysLimit*len(crypto.Key{}) +
+		1 + CustodianSignaturesLimit*custodianSignatureSize
+)
+
+// CustodianSet is the multi-signature keys of the custodians, the first set
+// is registered with the endorsements of the consensus threshold of the
+// accepted nodes, and each rotation is signed by the threshold of the
+// current set, all in the extra of script transactions. The signatures cover
+// the transaction of the current set, so an old rotation can't be replayed
+// once the set is rotated again.
+type CustodianSet struct {
+	Threshold   uint8
+	Keys        []crypto.Key
+	Transaction crypto.Hash
+	Timestamp   uint64
+}
+
+type CustodianSignature struct {
+	Signer    crypto.Key
+	Signature crypto.Signature
+}
+
+type CustodianUpdate struct {
+	Previous   crypto.Hash
+	Threshold  uint8
+	Keys       []crypto.Key
+	Signatures []*CustodianSignature
+}
+
+type CustodianReader interface {
+	ReadCustodianSet(timestamp uint64) (*CustodianSet, error)
+}
+
+func (s *CustodianSet) Contains(key crypto.Key) bool {
+	for _, k := range s.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// CustodianUpdateMessage is signed by the custodian keys of the previous set,
+// or by the accepted nodes with a zero previous hash to register the first set.
+func CustodianUpdateMessage(previous crypto.Hash, threshold uint8, keys []crypto.Key) []byte {
+	data := append([]byte(CustodianUpdateMessagePrefix), previous[:]...)
+	data = append(data, threshold, uint8(len(keys)))
+	for _, k := range keys {
+		data = append(data, k[:]...)
+	}
+	msg := crypto.NewHash(data)
+	return msg[:]
+}
+
+func SignCustodianUpdate(signer crypto.Key, previous crypto.Hash, threshold uint8, keys []crypto.Key) *CustodianSignature {
+	return &CustodianSignature{
+		Signer:    signer.Public(),
+		Signature: signer.Sign(CustodianUpdateMessage(previous, threshold, keys)),
+	}
+}
+
+func (u *CustodianUpdate) Encode() []byte {
+	data := append([]byte(CustodianUpdateMessagePrefix), u.Previous[:]...)
+	data = append(data, u.Threshold, uint8(len(u.Keys)))
+	for _, k := range u.Keys {
+		data = append(data, k[:]...)
+	}
+	data = append(data, uint8(len(u.Signatures)))
+	for _, s := range u.Signatures {
+		data = append(data, s.Signer[:]...)
+		data = append(data, s.Signature[:]...)
+	}
+	return data
+}
+
+func DecodeCustodianUpdate(data []byte) (*CustodianUpdate, error) {
+	if !bytes.HasPrefix(data, []byte(CustodianUpdateMessagePrefix)) {
+		return nil, fmt.Errorf("invalid custodian update prefix")
+	}
+	data = data[len(CustodianUpdateMessagePrefix):]
+	if len(data) < len(crypto.Hash{})+2 {
+		return nil, fmt.Errorf("invalid custodian update size %d", len(data))
+	}
+	u := &CustodianUpdate{}
+	copy(u.Previous[:], data)
+	data = data[len(u.Previous):]
+	u.Threshold = data[0]
+	count := int(data[1])
+	if count < 1 || count > CustodianKeysLimit {
+		return nil, fmt.Errorf("invalid custodian keys count %d", count)
+	}
+	if u.Threshold < 1 || int(u.Threshold) > count {
+		return nil, fmt.Errorf("invalid custodian threshold %d/%d", u.Threshold, count)
+	}
+	data = data[2:]
+	if len(data) < count*len(crypto.Key{})+1 {
+		return nil, fmt.Errorf("invalid custodian update size %d", len(data))
+	}
+	filter := make(map[crypto.Key]bool)
+	for i := 0; i < count; i++ {
+		var k crypto.Key
+		copy(k[:], data[i*len(k):])
+		if filter[k] {
+			return nil, fmt.Errorf("duplicated custodian key %s", k)
+		}
+		filter[k] = true
+		u.Keys = append(u.Keys, k)
+	}
+	data = data[count*len(crypto.Key{}):]
+	count = int(data[0])
+	if count > CustodianSignaturesLimit || len(data) != 1+count*custodianSignatureSize {
+		return nil, fmt.Errorf("invalid custodian signatures %d %d", count, len(data))
+	}
+
+	msg := CustodianUpdateMessage(u.Previous, u.Threshold, u.Keys)
+	filter = make(map[crypto.Key]bool)
+	for i := 0; i < count; i++ {
+		var s CustodianSignature
+		b := data[1+i*custodianSignatureSize:]
+		copy(s.Signer[:], b)
+		copy(s.Signature[:], b[len(s.Signer):])
+		if filter[s.Signer] {
+			return nil, fmt.Errorf("duplicated custodian signature %s", s.Signer)
+		}
+		filter[s.Signer] = true
+		if !s.Signer.Verify(msg, s.Signature) {
+			return nil, fmt.Errorf("invalid custodian signature %s", s.Signer)
+		}
+		u.Signatures = append(u.Signatures, &s)
+	}
+	return u, nil
+}
+
+func (tx *Transaction) IsCustodianUpdate() bool {
+	return bytes.HasPrefix(tx.Extra, []byte(CustodianUpdateMessagePrefix))
+}
+
+func (tx *Transaction) CustodianUpdate() (*CustodianUpdate, error) {
+	if !tx.IsCustodianUpdate() {
+		return nil, nil
+	}
+	return DecodeCustodianUpdate(tx.Extra)
+}
+
+// validateCustodianUpdate checks the update against the custodian set and the
+// accepted nodes at the snapshot timestamp, and before the custodian fork the
+// extra is only a memo limited by ExtraSizeLimit.
+func (tx *Transaction) validateCustodianUpdate(store DataStore, timestamp uint64) error {
+	if !ForkActivated(CustodianForkTimestamp, timestamp) {
+		if len(tx.Extra) > ExtraSizeLimit {
+			return fmt.Errorf("invalid extra size %d", len(tx.Extra))
+		}
+		return nil
+	}
+	update, err := tx.CustodianUpdate()
+	if err != nil || update == nil {
+		return err
+	}
+
+	current, err := store.ReadCustodianSet(timestamp)
+	if err != nil {
+		return err
+	}
+	var previous crypto.Hash
+	if current != nil {
+		previous = current.Transaction
+	}
+	if update.Previous != previous {
+		return fmt.Errorf("invalid custodian update previous %s %s", update.Previous, previous)
+	}
+	if current != nil {
+		signers := 0
+		for _, s := range update.Signatures {
+			if !current.Contains(s.Signer) {
+				return fmt.Errorf("invalid custodian signer %s", s.Signer)
+			}
+			signers += 1
+		}
+		if signers < int(current.Threshold) {
+			return fmt.Errorf("insufficient custodian signatures %d %d", signers, current.Threshold)
+		}
+		return nil
+	}
+
+	accepted := make(map[crypto.Key]bool)
+	for _, n := range store.ReadAllNodes(timestamp, false) {
+		if n.State == NodeStateAccepted {
+			accepted[n.Signer.PublicSpendKey] = true
+		}
+	}
+	for _, s := range update.Signatures {
+		if !accepted[s.Signer] {
+			return fmt.Errorf("invalid custodian registration signer %s", s.Signer)
+		}
+	}
+	if threshold := len(accepted)*2/3 + 1; len(update.Signatures) < threshold {
+		return fmt.Errorf("insufficient custodian registration signatures %d %d", len(update.Signatures), threshold)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustodianUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	signers := make([]crypto.Key, 3)
+	keys := make([]crypto.Key, 3)
+	for i := range signers {
+		seed := crypto.NewHash([]byte{byte(i)})
+		signers[i] = crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+		keys[i] = signers[i].Public()
+	}
+
+	previous := crypto.NewHash([]byte("previous"))
+	update := &CustodianUpdate{Previous: previous, Threshold: 2, Keys: keys}
+	for _, s := range signers[:2] {
+		update.Signatures = append(update.Signatures, SignCustodianUpdate(s, previous, 2, keys))
+	}
+	data := update.Encode()
+	assert.Len(data, len(CustodianUpdateMessagePrefix)+32+2+3*32+1+2*96)
+	assert.LessOrEqual(len(data), custodianUpdateSizeLimit)
+
+	decoded, err := DecodeCustodianUpdate(data)
+	assert.Nil(err)
+	assert.Equal(update, decoded)
+
+	_, err = DecodeCustodianUpdate(data[:len(data)-1])
+	assert.Contains(err.Error(), "invalid custodian signatures")
+	_, err = DecodeCustodianUpdate(data[1:])
+	assert.Contains(err.Error(), "invalid custodian update prefix")
+	invalid := &CustodianUpdate{Previous: previous, Threshold: 3, Keys: keys, Signatures: update.Signatures}
+	_, err = DecodeCustodianUpdate(invalid.Encode())
+	assert.Contains(err.Error(), "invalid custodian signature")
+	invalid = &CustodianUpdate{Threshold: 2, Keys: keys, Signatures: update.Signatures}
+	_, err = DecodeCustodianUpdate(invalid.Encode())
+	assert.Contains(err.Error(), "invalid custodian signature")
+	invalid = &CustodianUpdate{Threshold: 4, Keys: keys}
+	_, err = DecodeCustodianUpdate(invalid.Encode())
+	assert.Contains(err.Error(), "invalid custodian threshold 4/3")
+	invalid = &CustodianUpdate{Threshold: 1, Keys: append(keys, keys[0])}
+	_, err = DecodeCustodianUpdate(invalid.Encode())
+	assert.Contains(err.Error(), "duplicated custodian key")
+	invalid = &CustodianUpdate{Previous: update.Previous, Threshold: 2, Keys: keys, Signatures: append(update.Signatures, update.Signatures[0])}
+	_, err = DecodeCustodianUpdate(invalid.Encode())
+	assert.Contains(err.Error(), "duplicated custodian signature")
+
+	set := &CustodianSet{Threshold: 2, Keys: keys}
+	assert.True(set.Contains(keys[1]))
+	assert.False(set.Contains(crypto.Key{}))
+
+	tx := NewTransaction(XINAssetId)
+	assert.False(tx.IsCustodianUpdate())
+	tx.Extra = data
+	assert.True(tx.IsCustodianUpdate())
+	decoded, err = tx.CustodianUpdate()
+	assert.Nil(err)
+	assert.Len(decoded.Signatures, 2)
+}
@@ -6,12 +6,14 @@ import (
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/domains/akash"
 	"github.com/MixinNetwork/mixin/domains/algorand"
+	"github.com/MixinNetwork/mixin/domains/aptos"
 	"github.com/MixinNetwork/mixin/domains/arweave"
 	"github.com/MixinNetwork/mixin/domains/avalanche"
 	"github.com/MixinNetwork/mixin/domains/bch"
 	"github.com/MixinNetwork/mixin/domains/binance"
 	"github.com/MixinNetwork/mixin/domains/bitcoin"
 	"github.com/MixinNetwork/mixin/domains/bsv"
+	"github.com/MixinNetwork/mixin/domains/cardano"
 	"github.com/MixinNetwork/mixin/domains/cosmos"
 	"github.com/MixinNetwork/mixin/domains/dash"
 	"github.com/MixinNetwork/mixin/domains/decred"
@@ -20,23 +22,28 @@ import (
 	"github.com/MixinNetwork/mixin/domains/eos"
 	"github.com/MixinNetwork/mixin/domains/etc"
 	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/MixinNetwork/mixin/domains/evm"
 	"github.com/MixinNetwork/mixin/domains/filecoin"
 	"github.com/MixinNetwork/mixin/domains/handshake"
+	"github.com/MixinNetwork/mixin/domains/hedera"
 	"github.com/MixinNetwork/mixin/domains/horizen"
 	"github.com/MixinNetwork/mixin/domains/kusama"
 	"github.com/MixinNetwork/mixin/domains/litecoin"
 	"github.com/MixinNetwork/mixin/domains/mobilecoin"
+	"github.com/MixinNetwork/mixin/domains/monacoin"
 	"github.com/MixinNetwork/mixin/domains/monero"
 	"github.com/MixinNetwork/mixin/domains/namecoin"
 	"github.com/MixinNetwork/mixin/domains/near"
 	"github.com/MixinNetwork/mixin/domains/nervos"
+	"github.com/MixinNetwork/mixin/domains/osmosis"
+	"github.com/MixinNetwork/mixin/domains/peercoin"
 	"github.com/MixinNetwork/mixin/domains/polkadot"
-	"github.com/MixinNetwork/mixin/domains/polygon"
 	"github.com/MixinNetwork/mixin/domains/ravencoin"
 	"github.com/MixinNetwork/mixin/domains/ripple"
 	"github.com/MixinNetwork/mixin/domains/siacoin"
 	"github.com/MixinNetwork/mixin/domains/solana"
 	"github.com/MixinNetwork/mixin/domains/stellar"
+	"github.com/MixinNetwork/mixin/domains/sui"
 	"github.com/MixinNetwork/mixin/domains/tezos"
 	"github.com/MixinNetwork/mixin/domains/tron"
 	"github.com/MixinNetwork/mixin/domains/zcash"
@@ -80,81 +87,112 @@ func (tx *Transaction) verifyDepositFormat() error {
 	if deposit.Amount.Sign() <= 0 {
 		return fmt.Errorf("invalid amount %s", deposit.Amount.String())
 	}
+	if deposit.Asset().NonFungible() && deposit.Amount.Cmp(NonFungibleSupply) != 0 {
+		return fmt.Errorf("invalid non-fungible amount %s", deposit.Amount.String())
+	}
+
+	return VerifyDepositTransactionHash(deposit.Asset().ChainId, deposit.TransactionHash)
+}
 
-	chainId := deposit.Asset().ChainId
+// verifyDepositTransactionHashFork checks the deposit transaction hash by
+// the stricter rules of the domain fork.
+func verifyDepositTransactionHashFork(chainId crypto.Hash, hash string) error {
+	switch chainId {
+	case filecoin.FilecoinChainId:
+		return filecoin.VerifyMessageCid(hash)
+	}
+	return nil
+}
+
+func VerifyDepositTransactionHash(chainId crypto.Hash, hash string) error {
 	switch chainId {
 	case ethereum.EthereumChainId:
-		return ethereum.VerifyTransactionHash(deposit.TransactionHash)
+		return ethereum.VerifyTransactionHash(hash)
 	case etc.EthereumClassicChainId:
-		return etc.VerifyTransactionHash(deposit.TransactionHash)
+		return etc.VerifyTransactionHash(hash)
 	case bitcoin.BitcoinChainId:
-		return bitcoin.VerifyTransactionHash(deposit.TransactionHash)
+		return bitcoin.VerifyTransactionHash(hash)
 	case monero.MoneroChainId:
-		return monero.VerifyTransactionHash(deposit.TransactionHash)
+		return monero.VerifyTransactionHash(hash)
 	case zcash.ZcashChainId:
-		return zcash.VerifyTransactionHash(deposit.TransactionHash)
+		return zcash.VerifyTransactionHash(hash)
 	case horizen.HorizenChainId:
-		return horizen.VerifyTransactionHash(deposit.TransactionHash)
+		return horizen.VerifyTransactionHash(hash)
 	case litecoin.LitecoinChainId:
-		return litecoin.VerifyTransactionHash(deposit.TransactionHash)
+		return litecoin.VerifyTransactionHash(hash)
 	case dogecoin.DogecoinChainId:
-		return dogecoin.VerifyTransactionHash(deposit.TransactionHash)
+		return dogecoin.VerifyTransactionHash(hash)
 	case ravencoin.RavencoinChainId:
-		return ravencoin.VerifyTransactionHash(deposit.TransactionHash)
+		return ravencoin.VerifyTransactionHash(hash)
 	case namecoin.NamecoinChainId:
-		return namecoin.VerifyTransactionHash(deposit.TransactionHash)
+		return namecoin.VerifyTransactionHash(hash)
+	case monacoin.MonacoinChainId:
+		return monacoin.VerifyTransactionHash(hash)
+	case peercoin.PeercoinChainId:
+		return peercoin.VerifyTransactionHash(hash)
 	case dash.DashChainId:
-		return dash.VerifyTransactionHash(deposit.TransactionHash)
+		return dash.VerifyTransactionHash(hash)
 	case decred.DecredChainId:
-		return decred.VerifyTransactionHash(deposit.TransactionHash)
+		return decred.VerifyTransactionHash(hash)
 	case bch.BitcoinCashChainId:
-		return bch.VerifyTransactionHash(deposit.TransactionHash)
+		return bch.VerifyTransactionHash(hash)
 	case bsv.BitcoinSVChainId:
-		return bsv.VerifyTransactionHash(deposit.TransactionHash)
+		return bsv.VerifyTransactionHash(hash)
 	case handshake.HandshakenChainId:
-		return handshake.VerifyTransactionHash(deposit.TransactionHash)
+		return handshake.VerifyTransactionHash(hash)
 	case nervos.NervosChainId:
-		return nervos.VerifyTransactionHash(deposit.TransactionHash)
+		return nervos.VerifyTransactionHash(hash)
 	case siacoin.SiacoinChainId:
-		return siacoin.VerifyTransactionHash(deposit.TransactionHash)
+		return siacoin.VerifyTransactionHash(hash)
 	case filecoin.FilecoinChainId:
-		return filecoin.VerifyTransactionHash(deposit.TransactionHash)
+		return filecoin.VerifyTransactionHash(hash)
 	case solana.SolanaChainId:
-		return solana.VerifyTransactionHash(deposit.TransactionHash)
+		return solana.VerifyTransactionHash(hash)
 	case near.NearChainId:
-		return near.VerifyTransactionHash(deposit.TransactionHash)
+		return near.VerifyTransactionHash(hash)
 	case polkadot.PolkadotChainId:
-		return polkadot.VerifyTransactionHash(deposit.TransactionHash)
+		return polkadot.VerifyTransactionHash(hash)
 	case kusama.KusamaChainId:
-		return kusama.VerifyTransactionHash(deposit.TransactionHash)
+		return kusama.VerifyTransactionHash(hash)
 	case ripple.RippleChainId:
-		return ripple.VerifyTransactionHash(deposit.TransactionHash)
+		return ripple.VerifyTransactionHash(hash)
 	case stellar.StellarChainId:
-		return stellar.VerifyTransactionHash(deposit.TransactionHash)
+		return stellar.VerifyTransactionHash(hash)
 	case tezos.TezosChainId:
-		return tezos.VerifyTransactionHash(deposit.TransactionHash)
+		return tezos.VerifyTransactionHash(hash)
 	case eos.EOSChainId:
-		return eos.VerifyTransactionHash(deposit.TransactionHash)
+		return eos.VerifyTransactionHash(hash)
 	case tron.TronChainId:
-		return tron.VerifyTransactionHash(deposit.TransactionHash)
+		return tron.VerifyTransactionHash(hash)
 	case mobilecoin.MobileCoinChainId:
-		return mobilecoin.VerifyTransactionHash(deposit.TransactionHash)
+		return mobilecoin.VerifyTransactionHash(hash)
 	case cosmos.CosmosChainId:
-		return cosmos.VerifyTransactionHash(deposit.TransactionHash)
+		return cosmos.VerifyTransactionHash(hash)
 	case avalanche.AvalancheChainId:
-		return avalanche.VerifyTransactionHash(deposit.TransactionHash)
+		return avalanche.VerifyTransactionHash(hash)
 	case binance.BinanceChainId:
-		return binance.VerifyTransactionHash(deposit.TransactionHash)
+		return binance.VerifyTransactionHash(hash)
 	case akash.AkashChainId:
-		return akash.VerifyTransactionHash(deposit.TransactionHash)
+		return akash.VerifyTransactionHash(hash)
 	case arweave.ArweaveChainId:
-		return arweave.VerifyTransactionHash(deposit.TransactionHash)
+		return arweave.VerifyTransactionHash(hash)
 	case dfinity.DfinityChainId:
-		return dfinity.VerifyTransactionHash(deposit.TransactionHash)
+		return dfinity.VerifyTransactionHash(hash)
 	case algorand.AlgorandChainId:
-		return algorand.VerifyTransactionHash(deposit.TransactionHash)
-	case polygon.PolygonChainId:
-		return polygon.VerifyTransactionHash(deposit.TransactionHash)
+		return algorand.VerifyTransactionHash(hash)
+	case sui.SuiChainId:
+		return sui.VerifyTransactionHash(hash)
+	case aptos.AptosChainId:
+		return aptos.VerifyTransactionHash(hash)
+	case cardano.CardanoChainId:
+		return cardano.VerifyTransactionHash(hash)
+	case hedera.HederaChainId:
+		return hedera.VerifyTransactionHash(hash)
+	case osmosis.OsmosisChainId:
+		return osmosis.VerifyTransactionHash(hash)
+	}
+	if c := evm.Lookup(chainId); c != nil {
+		return c.VerifyTransactionHash(hash)
 	}
 	return fmt.Errorf("invalid deposit chain id %s", chainId)
 }
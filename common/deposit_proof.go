@@ -0,0 +1,82 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/bitcoin"
+)
+
+const (
+	DepositProofMagic     = "DEPOSITPROOF"
+	DepositProofSizeLimit = 64 * 1024
+)
+
+// DepositProofVerifier verifies that the deposit is included in its chain,
+// it is optional and only implemented by the domains able to verify their
+// chain proofs without any external data.
+type DepositProofVerifier interface {
+	VerifyDepositProof(deposit *DepositData, proof []byte) error
+}
+
+var depositProofVerifiers = map[crypto.Hash]DepositProofVerifier{
+	bitcoin.BitcoinChainId: bitcoinDepositProofVerifier{},
+}
+
+type bitcoinDepositProofVerifier struct{}
+
+func (bitcoinDepositProofVerifier) VerifyDepositProof(deposit *DepositData, proof []byte) error {
+	value, err := bitcoin.VerifyDepositProof(deposit.TransactionHash, deposit.OutputIndex, proof)
+	if err != nil {
+		return err
+	}
+	if deposit.AssetKey != bitcoin.BitcoinChainAssetKey {
+		return nil
+	}
+	if deposit.Amount.i.Cmp(big.NewInt(value)) != 0 {
+		return fmt.Errorf("invalid bitcoin deposit proof amount %s %d", deposit.Amount, value)
+	}
+	return nil
+}
+
+// DepositProof is the inclusion proof carried by the deposit transaction
+// extra, after the DepositProofMagic prefix.
+func (tx *Transaction) DepositProof() []byte {
+	if tx.DepositData() == nil {
+		return nil
+	}
+	if !bytes.HasPrefix(tx.Extra, []byte(DepositProofMagic)) {
+		return nil
+	}
+	return tx.Extra[len(DepositProofMagic):]
+}
+
+func (tx *Transaction) VerifyDepositProof() error {
+	deposit, proof := tx.DepositData(), tx.DepositProof()
+	if deposit == nil || proof == nil {
+		return nil
+	}
+	verifier := depositProofVerifiers[deposit.Chain]
+	if verifier == nil {
+		return fmt.Errorf("deposit proof not supported for chain %s", deposit.Chain)
+	}
+	return verifier.VerifyDepositProof(deposit, proof)
+}
+
+// validateDepositProof verifies the proof carried by the deposit extra since
+// the deposit proof fork, and before it the extra is only a memo limited by
+// ExtraSizeLimit.
+func (tx *Transaction) validateDepositProof(timestamp uint64) error {
+	if tx.DepositProof() == nil {
+		return nil
+	}
+	if !ForkActivated(DepositProofForkTimestamp, timestamp) {
+		if len(tx.Extra) > ExtraSizeLimit {
+			return fmt.Errorf("invalid extra size %d", len(tx.Extra))
+		}
+		return nil
+	}
+	return tx.VerifyDepositProof()
+}
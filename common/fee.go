@@ -0,0 +1,85 @@
+package common
+
+import (
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// FeeAmount is the fee amount of an asset.
+type FeeAmount struct {
+	Asset  crypto.Hash
+	Amount Integer
+}
+
+// FeeLedgerEntry is the fees of the transactions finalized in a round of
+// the node. The withdrawal claim outputs are burned as they can never be
+// spent, and the withdrawal fuel outputs are paid to the domains.
+type FeeLedgerEntry struct {
+	NodeId       crypto.Hash
+	Round        uint64
+	Timestamp    uint64
+	Transactions uint64
+	Burned       Integer
+	Fuels        []*FeeAmount
+}
+
+// Fees returns the burned XIN amount and the withdrawal fuel amount of the
+// transaction asset, both zero for the transactions without fees.
+func (tx *Transaction) Fees() (Integer, Integer) {
+	burned, fuel := Zero, Zero
+	for _, out := range tx.Outputs {
+		switch out.Type {
+		case OutputTypeWithdrawalClaim:
+			burned = addFee(burned, out.Amount)
+		case OutputTypeWithdrawalFuel:
+			fuel = addFee(fuel, out.Amount)
+		}
+	}
+	return burned, fuel
+}
+
+func addFee(x, y Integer) Integer {
+	if y.Sign() <= 0 {
+		return x
+	}
+	return x.Add(y)
+}
+
+// AddFees adds the fees of the transaction to the entry, and returns false
+// if the transaction has no fees at all.
+func (e *FeeLedgerEntry) AddFees(tx *Transaction) bool {
+	burned, fuel := tx.Fees()
+	if burned.Sign() <= 0 && fuel.Sign() <= 0 {
+		return false
+	}
+	e.Transactions += 1
+	e.Burned = addFee(e.Burned, burned)
+	if fuel.Sign() <= 0 {
+		return true
+	}
+	for _, f := range e.Fuels {
+		if f.Asset == tx.Asset {
+			f.Amount = addFee(f.Amount, fuel)
+			return true
+		}
+	}
+	e.Fuels = append(e.Fuels, &FeeAmount{Asset: tx.Asset, Amount: fuel})
+	return true
+}
+
+// Merge adds the fees of the other entry, regardless of the node and round.
+func (e *FeeLedgerEntry) Merge(o *FeeLedgerEntry) {
+	e.Transactions += o.Transactions
+	e.Burned = addFee(e.Burned, o.Burned)
+	for _, of := range o.Fuels {
+		var found bool
+		for _, f := range e.Fuels {
+			if f.Asset == of.Asset {
+				f.Amount = addFee(f.Amount, of.Amount)
+				found = true
+			}
+		}
+		if !found {
+			e.Fuels = append(e.Fuels, &FeeAmount{Asset: of.Asset, Amount: of.Amount})
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// The consensus rules added after the transaction version 2 are activated by
+// the snapshot timestamps, so all the nodes switch to a rule at the same
+// point of the graph, and the old nodes never see a transaction using it
+// before the fork. They are checked by ValidateForks, because Validate doesn't
+// know the snapshot timestamp.
+var (
+	ScriptForkTimestamp, _       = time.Parse(time.RFC3339, "2027-01-04T00:00:00Z")
+	NodeRemovalForkTimestamp, _  = time.Parse(time.RFC3339, "2027-01-04T00:00:00Z")
+	GovernanceForkTimestamp, _   = time.Parse(time.RFC3339, "2027-01-04T00:00:00Z")
+	CustodianForkTimestamp, _    = time.Parse(time.RFC3339, "2027-01-04T00:00:00Z")
+	NodeModifyForkTimestamp, _   = time.Parse(time.RFC3339, "2027-01-04T00:00:00Z")
+	DomainForkTimestamp, _       = time.Parse(time.RFC3339, "2027-01-04T00:00:00Z")
+	DepositProofForkTimestamp, _ = time.Parse(time.RFC3339, "2027-01-04T00:00:00Z")
+)
+
+func ForkActivated(fork time.Time, timestamp uint64) bool {
+	return timestamp >= uint64(fork.UnixNano())
+}
+
+// ValidateForks checks the transaction against the rules activated at the
+// snapshot timestamp.
+func (ver *VersionedTransaction) ValidateForks(store DataStore, timestamp uint64) error {
+	if ver.Version < TxVersion {
+		return nil
+	}
+	for _, o := range ver.Outputs {
+		err := o.Script.validateFork(timestamp)
+		if err != nil {
+			return err
+		}
+	}
+	switch ver.TransactionType() {
+	case TransactionTypeScript:
+		err := ver.validateGovernanceSignal(store, timestamp)
+		if err != nil {
+			return err
+		}
+		err = ver.validateCustodianUpdate(store, timestamp)
+		if err != nil {
+			return err
+		}
+		return ver.validateNodeModify(store, timestamp)
+	case TransactionTypeNodeRemove:
+		return ver.validateNodeRemovalEndorsements(timestamp)
+	case TransactionTypeDeposit:
+		err := ver.validateDepositProof(timestamp)
+		if err != nil {
+			return err
+		}
+		return ver.validateDomainFork(timestamp)
+	case TransactionTypeWithdrawalSubmit:
+		return ver.validateDomainFork(timestamp)
+	}
+	return nil
+}
+
+// validateDomainFork checks the deposit and withdrawal against the stricter
+// rules of the domains after the domain fork, and the snapshots before it
+// are still valid by the rules of Validate when synced again.
+func (ver *VersionedTransaction) validateDomainFork(timestamp uint64) error {
+	if !ForkActivated(DomainForkTimestamp, timestamp) {
+		return nil
+	}
+	if d := ver.DepositData(); d != nil {
+		err := d.Asset().verifyFork()
+		if err != nil {
+			return err
+		}
+		return verifyDepositTransactionHashFork(d.Chain, d.TransactionHash)
+	}
+	for _, o := range ver.Outputs {
+		if o.Type != OutputTypeWithdrawalSubmit || o.Withdrawal == nil {
+			continue
+		}
+		err := o.Withdrawal.Asset().verifyFork()
+		if err != nil {
+			return err
+		}
+		return verifyWithdrawalFork(o.Withdrawal.Asset().ChainId, o.Withdrawal.Address, o.Withdrawal.Tag)
+	}
+	return nil
+}
+
+// validateFork rejects the expiration and hashlock scripts before the script
+// fork, which share the same activation.
+func (s Script) validateFork(timestamp uint64) error {
+	if ForkActivated(ScriptForkTimestamp, timestamp) {
+		return nil
+	}
+	switch len(s) {
+	case expirationScriptLength:
+		return fmt.Errorf("expiration script not activated %d", timestamp)
+	case hashlockScriptLength:
+		return fmt.Errorf("hashlock script not activated %d", timestamp)
+	}
+	return nil
+}
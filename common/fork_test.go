@@ -0,0 +1,327 @@
+package common
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/bitcoin"
+	"github.com/MixinNetwork/mixin/domains/eos"
+	"github.com/MixinNetwork/mixin/domains/filecoin"
+	"github.com/MixinNetwork/mixin/domains/kusama"
+	"github.com/MixinNetwork/mixin/domains/litecoin"
+	"github.com/MixinNetwork/mixin/domains/polkadot"
+	"github.com/MixinNetwork/mixin/domains/tron"
+	"github.com/MixinNetwork/mixin/domains/zcash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptFork(t *testing.T) {
+	assert := assert.New(t)
+
+	fork := uint64(ScriptForkTimestamp.UnixNano())
+	ver := NewTransaction(XINAssetId).AsLatestVersion()
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeScript, Script: NewThresholdScript(1)})
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeScript, Script: NewExpirationScript(1, fork+1, 1, 1)})
+	err := ver.ValidateForks(nil, fork-1)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "expiration script not activated")
+	assert.Nil(ver.ValidateForks(nil, fork))
+
+	hashlock := NewHashlockScript(1, ver.PayloadHash(), fork+1, 1, 1)
+	ver.Outputs[1] = &Output{Type: OutputTypeScript, Script: hashlock}
+	err = ver.ValidateForks(nil, fork-1)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "hashlock script not activated")
+	assert.Nil(ver.ValidateForks(nil, fork))
+
+	ver.Version = 1
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+}
+
+func TestNodeRemovalFork(t *testing.T) {
+	assert := assert.New(t)
+
+	fork := uint64(NodeRemovalForkTimestamp.UnixNano())
+	accept := crypto.NewHash([]byte("accept"))
+	seed := crypto.NewHash([]byte("removal"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+
+	ver := NewTransaction(XINAssetId).AsLatestVersion()
+	ver.AddInput(accept, 0)
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeNodeRemove})
+	ver.Extra = make([]byte, 64)
+	assert.Equal(uint8(TransactionTypeNodeRemove), ver.TransactionType())
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+
+	e := SignNodeRemoval(key, accept, fork-1)
+	ver.Extra = append(make([]byte, 64), EncodeNodeRemovalEndorsements([]*NodeRemovalEndorsement{e})...)
+	err := ver.ValidateForks(nil, fork-1)
+	assert.Contains(err.Error(), "node removal endorsements not activated")
+	assert.Nil(ver.ValidateForks(nil, fork))
+	expire := e.Timestamp + uint64(NodeRemovalEndorsementExpiration)
+	assert.Nil(ver.ValidateForks(nil, expire-1))
+	err = ver.ValidateForks(nil, expire)
+	assert.Contains(err.Error(), "node removal endorsement expired")
+
+	e = SignNodeRemoval(key, accept, fork+uint64(time.Hour))
+	ver.Extra = append(make([]byte, 64), EncodeNodeRemovalEndorsements([]*NodeRemovalEndorsement{e})...)
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "node removal endorsement future timestamp")
+}
+
+func TestGovernanceFork(t *testing.T) {
+	assert := assert.New(t)
+
+	fork := uint64(GovernanceForkTimestamp.UnixNano())
+	seed := crypto.NewHash([]byte("governance"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	proposal := crypto.NewHash([]byte("proposal"))
+
+	ver := NewTransaction(XINAssetId).AsLatestVersion()
+	ver.AddInput(crypto.NewHash([]byte("input")), 0)
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeScript, Script: NewThresholdScript(1)})
+	ver.Extra = []byte(GovernanceSignalMessagePrefix)
+	assert.Equal(uint8(TransactionTypeScript), ver.TransactionType())
+	assert.Nil(ver.ValidateForks(storeImpl{}, fork-1))
+	err := ver.ValidateForks(storeImpl{}, fork)
+	assert.Contains(err.Error(), "invalid governance signal size")
+
+	ver.Extra = SignGovernanceSignal(key, proposal, 5000, ver.Inputs).Encode()
+	err = ver.ValidateForks(storeImpl{}, fork)
+	assert.Contains(err.Error(), "invalid governance signal signer")
+	node := &Node{State: NodeStateAccepted}
+	node.Signer.PublicSpendKey = key.Public()
+	assert.Nil(ver.ValidateForks(storeImpl{nodes: []*Node{node}}, fork))
+
+	ver.AddInput(crypto.NewHash([]byte("other")), 0)
+	err = ver.ValidateForks(storeImpl{nodes: []*Node{node}}, fork)
+	assert.Contains(err.Error(), "invalid governance signal signature")
+}
+
+func TestCustodianFork(t *testing.T) {
+	assert := assert.New(t)
+
+	fork := uint64(CustodianForkTimestamp.UnixNano())
+	signers := make([]crypto.Key, 3)
+	keys := make([]crypto.Key, 3)
+	for i := range signers {
+		seed := crypto.NewHash([]byte{byte(i)})
+		signers[i] = crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+		keys[i] = signers[i].Public()
+	}
+	current := &CustodianSet{Threshold: 2, Keys: keys, Transaction: crypto.NewHash([]byte("current"))}
+	store := storeImpl{custodian: current}
+
+	ver := NewTransaction(XINAssetId).AsLatestVersion()
+	ver.AddInput(crypto.NewHash([]byte("input")), 0)
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeScript, Script: NewThresholdScript(1)})
+	update := &CustodianUpdate{Previous: current.Transaction, Threshold: 1, Keys: keys[:1]}
+	for _, s := range signers[:2] {
+		update.Signatures = append(update.Signatures, SignCustodianUpdate(s, update.Previous, 1, keys[:1]))
+	}
+	ver.Extra = update.Encode()
+	assert.Greater(len(ver.Extra), ExtraSizeLimit)
+	err := ver.ValidateForks(store, fork-1)
+	assert.Contains(err.Error(), "invalid extra size")
+	assert.Nil(ver.ValidateForks(store, fork))
+	ver.Extra = []byte(CustodianUpdateMessagePrefix)
+	assert.Nil(ver.ValidateForks(store, fork-1))
+	err = ver.ValidateForks(store, fork)
+	assert.Contains(err.Error(), "invalid custodian update size")
+
+	update.Signatures = update.Signatures[:1]
+	ver.Extra = update.Encode()
+	err = ver.ValidateForks(store, fork)
+	assert.Contains(err.Error(), "insufficient custodian signatures")
+
+	update.Previous = crypto.Hash{}
+	update.Signatures = []*CustodianSignature{SignCustodianUpdate(signers[0], update.Previous, 1, keys[:1])}
+	ver.Extra = update.Encode()
+	err = ver.ValidateForks(store, fork)
+	assert.Contains(err.Error(), "invalid custodian update previous")
+	node := &Node{State: NodeStateAccepted}
+	node.Signer.PublicSpendKey = keys[0]
+	assert.Nil(ver.ValidateForks(storeImpl{nodes: []*Node{node}}, fork))
+}
+
+func TestNodeModifyFork(t *testing.T) {
+	assert := assert.New(t)
+
+	fork := uint64(NodeModifyForkTimestamp.UnixNano())
+	seed := crypto.NewHash([]byte("modify"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	seed = crypto.NewHash([]byte("payee"))
+	payee := crypto.NewKeyFromSeed(append(seed[:], seed[:]...)).Public()
+
+	ver := NewTransaction(XINAssetId).AsLatestVersion()
+	ver.AddInput(crypto.NewHash([]byte("input")), 0)
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeScript, Script: NewThresholdScript(1)})
+	ver.Extra = SignNodeModify(key, payee, ver.Inputs).Encode()
+	assert.Nil(ver.ValidateForks(storeImpl{}, fork-1))
+	err := ver.ValidateForks(storeImpl{}, fork)
+	assert.Contains(err.Error(), "invalid node modify signer")
+
+	node := &Node{State: NodeStateAccepted}
+	node.Signer.PublicSpendKey = key.Public()
+	store := storeImpl{nodes: []*Node{node}}
+	assert.Nil(ver.ValidateForks(store, fork))
+	node.State = NodeStatePledging
+	err = ver.ValidateForks(store, fork)
+	assert.Contains(err.Error(), "invalid node modify signer")
+
+	node.State = NodeStateAccepted
+	node.Payee.PublicSpendKey = payee
+	err = ver.ValidateForks(store, fork)
+	assert.Contains(err.Error(), "invalid node modify payee")
+
+	node.Payee.PublicSpendKey = key.Public()
+	ver.Inputs[0].Index = 1
+	err = ver.ValidateForks(store, fork)
+	assert.Contains(err.Error(), "invalid node modify signature")
+}
+
+func TestDomainFork(t *testing.T) {
+	assert := assert.New(t)
+
+	fork := uint64(DomainForkTimestamp.UnixNano())
+	withdrawal := &WithdrawalData{
+		Chain:    eos.EOSChainId,
+		AssetKey: "eosio.token:EOS",
+		Address:  "eosio.token",
+		Tag:      strings.Repeat("m", eos.MemoSizeLimit+1),
+	}
+	ver := NewTransaction(withdrawal.Asset().AssetId()).AsLatestVersion()
+	ver.AddInput(crypto.NewHash([]byte("input")), 0)
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeWithdrawalSubmit, Withdrawal: withdrawal})
+	assert.Equal(uint8(TransactionTypeWithdrawalSubmit), ver.TransactionType())
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err := ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid eos memo size")
+	withdrawal.Tag = "memo"
+	assert.Nil(ver.ValidateForks(nil, fork))
+	withdrawal.Address = "eosio.tokenss"
+	assert.Nil(VerifyWithdrawalAddress(withdrawal.Chain, withdrawal.Address))
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid eos account name")
+
+	withdrawal = &WithdrawalData{
+		Chain:    zcash.ZcashChainId,
+		AssetKey: zcash.ZcashChainBase,
+		Address:  "t1NsuW4Xpz3GQUzt3BTZAxN6k4svKfWXgni",
+	}
+	ver.Outputs[0].Withdrawal = withdrawal
+	assert.Nil(ver.ValidateForks(nil, fork))
+	withdrawal.Address = "zs1z7rejlpsa98s2rrrfkwmaxu53e4ue0ulcrw0h4x5g8jl04tak0d3mm47vdtahatqrlkngh9sly"
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "shielded zcash address")
+
+	withdrawal = &WithdrawalData{
+		Chain:    litecoin.LitecoinChainId,
+		AssetKey: litecoin.LitecoinChainBase,
+		Address:  "LcDrhX7NCmoRj58abHjAzfNCvk7jHxARsm",
+	}
+	ver.Outputs[0].Withdrawal = withdrawal
+	assert.Nil(ver.ValidateForks(nil, fork))
+	withdrawal.Address = "ltcmweb1qq0hav4lqwkfxquzmatjkp3c6lmzm7m8cz2afwvnqzyk8tnkwq5qswq6evkmsd56ph34c5f6l78z0pvdst5dpns7rnaa6ea4w2cdcdhjfryecqfy5"
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "litecoin mweb address")
+
+	withdrawal = &WithdrawalData{
+		Chain:    polkadot.PolkadotChainId,
+		AssetKey: polkadot.PolkadotChainBase,
+		Address:  "13eM4Bgw55j93P7tiozfSjCkr55imbbiyso9MTG6YiQLaZSt",
+	}
+	ver.Outputs[0].Withdrawal = withdrawal
+	assert.Nil(ver.ValidateForks(nil, fork))
+	withdrawal.Address = "193RYwvY62s"
+	assert.Nil(VerifyWithdrawalAddress(withdrawal.Chain, withdrawal.Address))
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid polkadot address size")
+
+	withdrawal = &WithdrawalData{
+		Chain:    kusama.KusamaChainId,
+		AssetKey: kusama.KusamaChainBase,
+		Address:  "HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F",
+	}
+	ver.Outputs[0].Withdrawal = withdrawal
+	assert.Nil(ver.ValidateForks(nil, fork))
+	withdrawal.Address = "USmaZZXQHw"
+	assert.Nil(VerifyWithdrawalAddress(withdrawal.Chain, withdrawal.Address))
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid kusama address")
+
+	deposit := &DepositData{
+		Chain:           eos.EOSChainId,
+		AssetKey:        "eosio.token:eos",
+		TransactionHash: "197be13b8d572ae4c83fe2bc60e87ac8993896242bb486790fd4378f88d8d961",
+		Amount:          NewInteger(1),
+	}
+	assert.Nil(deposit.Asset().Verify())
+	ver = NewTransaction(deposit.Asset().AssetId()).AsLatestVersion()
+	ver.AddDepositInput(deposit)
+	assert.Equal(uint8(TransactionTypeDeposit), ver.TransactionType())
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid eos asset symbol")
+	deposit.AssetKey = "eosio.token:EOS"
+	assert.Nil(ver.ValidateForks(nil, fork))
+
+	deposit.Chain = tron.TronChainId
+	deposit.AssetKey = "1000000"
+	deposit.TransactionHash = "c5a8a5e3b4b0bb3f5d0a4b4e5fcd2d2d0e9dba3bfa6f8e7c5b1b0e4e0a2d5c1b"
+	assert.Nil(deposit.Asset().Verify())
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid tron asset key")
+	deposit.AssetKey = "1000001"
+	assert.Nil(ver.ValidateForks(nil, fork))
+
+	deposit.Chain = filecoin.FilecoinChainId
+	deposit.AssetKey = filecoin.FilecoinChainBase
+	deposit.TransactionHash = "bafk2bzaceclkp3mimhnqvpaan5dt7htenb4hl46z36hheow25h2tuavsv3bxq"
+	assert.Nil(deposit.Asset().Verify())
+	assert.Nil(VerifyDepositTransactionHash(deposit.Chain, deposit.TransactionHash))
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid filecoin transaction hash")
+	deposit.TransactionHash = "bafy2bzaceclkp3mimhnqvpaan5dt7htenb4hl46z36hheow25h2tuavsv3bxq"
+	assert.Nil(ver.ValidateForks(nil, fork))
+}
+
+func TestDepositProofFork(t *testing.T) {
+	assert := assert.New(t)
+
+	fork := uint64(DepositProofForkTimestamp.UnixNano())
+	ver := NewTransaction(XINAssetId).AsLatestVersion()
+	ver.AddDepositInput(&DepositData{
+		Chain:           bitcoin.BitcoinChainId,
+		AssetKey:        bitcoin.BitcoinChainAssetKey,
+		TransactionHash: "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+		Amount:          NewInteger(50),
+	})
+	ver.Outputs = append(ver.Outputs, &Output{Type: OutputTypeScript, Script: NewThresholdScript(1)})
+	assert.Equal(uint8(TransactionTypeDeposit), ver.TransactionType())
+	ver.Extra = []byte(DepositProofMagic + "memo")
+	assert.Nil(ver.ValidateForks(nil, fork-1))
+	err := ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof")
+
+	ver.Extra = append([]byte(DepositProofMagic), make([]byte, ExtraSizeLimit)...)
+	err = ver.ValidateForks(nil, fork-1)
+	assert.Contains(err.Error(), "invalid extra size")
+	err = ver.ValidateForks(nil, fork)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof")
+
+	ver.Extra = make([]byte, ExtraSizeLimit)
+	assert.Nil(ver.DepositProof())
+	assert.Nil(ver.ValidateForks(nil, fork))
+}
@@ -0,0 +1,136 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	GovernanceSignalMessagePrefix = "GOVERNANCESIGNAL"
+	GovernanceSignalWeightMaximum = 10000
+
+	governanceSignalSize = len(GovernanceSignalMessagePrefix) + len(crypto.Hash{}) + 2 + len(crypto.Key{}) + len(crypto.Signature{})
+)
+
+// GovernanceSignal is a consensus node signer voting on a proposal hash with
+// a weight in basis points, zero to oppose or withdraw a former support. The
+// signal is the extra of a normal script transaction, and the latest signal
+// of a signer on a proposal replaces the former ones. The signature covers
+// the inputs of the transaction, which could be spent only once, so an old
+// signal can't be replayed to revert a later one.
+type GovernanceSignal struct {
+	Proposal  crypto.Hash
+	Weight    uint16
+	Signer    crypto.Key
+	Signature crypto.Signature
+}
+
+// GovernanceVote is a finalized governance signal recorded by the kernel.
+type GovernanceVote struct {
+	Proposal    crypto.Hash
+	Signer      crypto.Key
+	Weight      uint16
+	Transaction crypto.Hash
+	Timestamp   uint64
+}
+
+func GovernanceSignalMessage(proposal crypto.Hash, weight uint16, inputs []*Input) []byte {
+	buf := make([]byte, 2)
+	binary.BigEndian.PutUint16(buf, weight)
+	data := append([]byte(GovernanceSignalMessagePrefix), proposal[:]...)
+	data = append(data, buf...)
+	msg := crypto.NewHash(append(data, inputsMessageData(inputs)...))
+	return msg[:]
+}
+
+func SignGovernanceSignal(signer crypto.Key, proposal crypto.Hash, weight uint16, inputs []*Input) *GovernanceSignal {
+	return &GovernanceSignal{
+		Proposal:  proposal,
+		Weight:    weight,
+		Signer:    signer.Public(),
+		Signature: signer.Sign(GovernanceSignalMessage(proposal, weight, inputs)),
+	}
+}
+
+// inputsMessageData is the hash and index of each input, which binds a signed
+// extra to the only transaction able to spend the inputs.
+func inputsMessageData(inputs []*Input) []byte {
+	data := make([]byte, 0, len(inputs)*(len(crypto.Hash{})+8))
+	for _, in := range inputs {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(in.Index))
+		data = append(data, in.Hash[:]...)
+		data = append(data, buf...)
+	}
+	return data
+}
+
+func (s *GovernanceSignal) Encode() []byte {
+	buf := make([]byte, 2)
+	binary.BigEndian.PutUint16(buf, s.Weight)
+	data := make([]byte, 0, governanceSignalSize)
+	data = append(data, GovernanceSignalMessagePrefix...)
+	data = append(data, s.Proposal[:]...)
+	data = append(data, buf...)
+	data = append(data, s.Signer[:]...)
+	return append(data, s.Signature[:]...)
+}
+
+func DecodeGovernanceSignal(data []byte, inputs []*Input) (*GovernanceSignal, error) {
+	if len(data) != governanceSignalSize {
+		return nil, fmt.Errorf("invalid governance signal size %d", len(data))
+	}
+	if !bytes.HasPrefix(data, []byte(GovernanceSignalMessagePrefix)) {
+		return nil, fmt.Errorf("invalid governance signal prefix %x", data[:len(GovernanceSignalMessagePrefix)])
+	}
+	var s GovernanceSignal
+	b := data[len(GovernanceSignalMessagePrefix):]
+	copy(s.Proposal[:], b)
+	b = b[len(s.Proposal):]
+	s.Weight = binary.BigEndian.Uint16(b)
+	b = b[2:]
+	copy(s.Signer[:], b)
+	copy(s.Signature[:], b[len(s.Signer):])
+	if s.Weight > GovernanceSignalWeightMaximum {
+		return nil, fmt.Errorf("invalid governance signal weight %d", s.Weight)
+	}
+	if !s.Signer.Verify(GovernanceSignalMessage(s.Proposal, s.Weight, inputs), s.Signature) {
+		return nil, fmt.Errorf("invalid governance signal signature %s", s.Signer)
+	}
+	return &s, nil
+}
+
+// IsGovernanceSignal tells whether the transaction extra is meant to be a
+// governance signal, which must be valid then.
+func (tx *Transaction) IsGovernanceSignal() bool {
+	return bytes.HasPrefix(tx.Extra, []byte(GovernanceSignalMessagePrefix))
+}
+
+func (tx *Transaction) GovernanceSignal() (*GovernanceSignal, error) {
+	if !tx.IsGovernanceSignal() {
+		return nil, nil
+	}
+	return DecodeGovernanceSignal(tx.Extra, tx.Inputs)
+}
+
+// validateGovernanceSignal requires the signer accepted at the snapshot
+// timestamp, and before the governance fork the extra is only a memo.
+func (tx *Transaction) validateGovernanceSignal(store DataStore, timestamp uint64) error {
+	if !ForkActivated(GovernanceForkTimestamp, timestamp) {
+		return nil
+	}
+	signal, err := tx.GovernanceSignal()
+	if err != nil || signal == nil {
+		return err
+	}
+	nodes := store.ReadAllNodes(timestamp, false)
+	for _, n := range nodes {
+		if n.State == NodeStateAccepted && n.Signer.PublicSpendKey == signal.Signer {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid governance signal signer %s", signal.Signer)
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGovernanceSignal(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := crypto.NewHash([]byte("governance"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	proposal := crypto.NewHash([]byte("proposal"))
+	inputs := []*Input{{Hash: crypto.NewHash([]byte("input")), Index: 1}}
+	signal := SignGovernanceSignal(key, proposal, 7500, inputs)
+	data := signal.Encode()
+	assert.Len(data, governanceSignalSize)
+
+	decoded, err := DecodeGovernanceSignal(data, inputs)
+	assert.Nil(err)
+	assert.Equal(signal, decoded)
+
+	_, err = DecodeGovernanceSignal(data[:len(data)-1], inputs)
+	assert.Contains(err.Error(), "invalid governance signal size")
+	other := SignGovernanceSignal(key, proposal, 2500, inputs).Encode()
+	copy(other[len(GovernanceSignalMessagePrefix)+32:], data[len(GovernanceSignalMessagePrefix)+32:len(GovernanceSignalMessagePrefix)+34])
+	_, err = DecodeGovernanceSignal(other, inputs)
+	assert.Contains(err.Error(), "invalid governance signal signature")
+	_, err = DecodeGovernanceSignal(SignGovernanceSignal(key, proposal, GovernanceSignalWeightMaximum+1, inputs).Encode(), inputs)
+	assert.Contains(err.Error(), "invalid governance signal weight")
+	_, err = DecodeGovernanceSignal(data, []*Input{{Hash: inputs[0].Hash, Index: 0}})
+	assert.Contains(err.Error(), "invalid governance signal signature")
+	_, err = DecodeGovernanceSignal(data, nil)
+	assert.Contains(err.Error(), "invalid governance signal signature")
+
+	tx := NewTransaction(XINAssetId)
+	tx.AddInput(inputs[0].Hash, inputs[0].Index)
+	s, err := tx.GovernanceSignal()
+	assert.Nil(err)
+	assert.Nil(s)
+	tx.Extra = []byte("memo")
+	assert.False(tx.IsGovernanceSignal())
+	tx.Extra = data
+	assert.True(tx.IsGovernanceSignal())
+	s, err = tx.GovernanceSignal()
+	assert.Nil(err)
+	assert.Equal(uint16(7500), s.Weight)
+	assert.Equal(key.Public(), s.Signer)
+	tx.Extra = data[:len(data)-1]
+	assert.True(tx.IsGovernanceSignal())
+	_, err = tx.GovernanceSignal()
+	assert.NotNil(err)
+}
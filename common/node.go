@@ -245,8 +245,9 @@ func (tx *Transaction) validateNodeRemove(store DataStore) error {
 	if ao.Type != OutputTypeNodeAccept {
 		return fmt.Errorf("invalid accept utxo type %d", ao.Type)
 	}
-	if !bytes.Equal(accept.Extra, tx.Extra) {
+	if len(tx.Extra) < len(accept.Extra) || !bytes.Equal(accept.Extra, tx.Extra[:len(accept.Extra)]) {
 		return fmt.Errorf("invalid accept and remove key %s %s", hex.EncodeToString(accept.Extra), hex.EncodeToString(tx.Extra))
 	}
-	return nil
+	_, err = tx.NodeRemovalEndorsements()
+	return err
 }
@@ -0,0 +1,133 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	NodeModifyMessagePrefix = "NODEMODIFY"
+
+	nodeModifySize = len(NodeModifyMessagePrefix) + len(crypto.Key{})*2 + len(crypto.Signature{})
+)
+
+// NodeModify is an accepted node signer rotating the payee to receive the
+// kernel mint rewards. The modify is the extra of a normal script transaction,
+// and the new payee takes effect only after the modify delay, so a stolen
+// signer key can't take the rewards before the operator notices it. The
+// signature covers the inputs of the transaction, so an old modify can't be
+// replayed to revert the payee.
+type NodeModify struct {
+	Signer    crypto.Key
+	Payee     crypto.Key
+	Signature crypto.Signature
+}
+
+// NodeModification is a finalized node modify recorded by the kernel, which
+// is effective since the Effective timestamp.
+type NodeModification struct {
+	Signer      crypto.Key
+	Payee       crypto.Key
+	Transaction crypto.Hash
+	Timestamp   uint64
+	Effective   uint64
+}
+
+func NodeModifyMessage(signer, payee crypto.Key, inputs []*Input) []byte {
+	data := append([]byte(NodeModifyMessagePrefix), signer[:]...)
+	data = append(data, payee[:]...)
+	msg := crypto.NewHash(append(data, inputsMessageData(inputs)...))
+	return msg[:]
+}
+
+func SignNodeModify(signer, payee crypto.Key, inputs []*Input) *NodeModify {
+	pub := signer.Public()
+	return &NodeModify{
+		Signer:    pub,
+		Payee:     payee,
+		Signature: signer.Sign(NodeModifyMessage(pub, payee, inputs)),
+	}
+}
+
+func (m *NodeModify) Encode() []byte {
+	data := make([]byte, 0, nodeModifySize)
+	data = append(data, NodeModifyMessagePrefix...)
+	data = append(data, m.Signer[:]...)
+	data = append(data, m.Payee[:]...)
+	return append(data, m.Signature[:]...)
+}
+
+func DecodeNodeModify(data []byte, inputs []*Input) (*NodeModify, error) {
+	if len(data) != nodeModifySize {
+		return nil, fmt.Errorf("invalid node modify size %d", len(data))
+	}
+	if !bytes.HasPrefix(data, []byte(NodeModifyMessagePrefix)) {
+		return nil, fmt.Errorf("invalid node modify prefix %x", data[:len(NodeModifyMessagePrefix)])
+	}
+	var m NodeModify
+	b := data[len(NodeModifyMessagePrefix):]
+	copy(m.Signer[:], b)
+	b = b[len(m.Signer):]
+	copy(m.Payee[:], b)
+	copy(m.Signature[:], b[len(m.Payee):])
+	if !m.Payee.CheckKey() {
+		return nil, fmt.Errorf("invalid node modify payee %s", m.Payee)
+	}
+	if !m.Signer.Verify(NodeModifyMessage(m.Signer, m.Payee, inputs), m.Signature) {
+		return nil, fmt.Errorf("invalid node modify signature %s", m.Signer)
+	}
+	return &m, nil
+}
+
+// PayeeAddress is the payee address with the view key derived from the
+// spend key, the same as the payee of the node pledge.
+func (m *NodeModification) PayeeAddress() Address {
+	privateView := m.Payee.DeterministicHashDerive()
+	return Address{
+		PrivateViewKey: privateView,
+		PublicViewKey:  privateView.Public(),
+		PublicSpendKey: m.Payee,
+	}
+}
+
+// IsNodeModify tells whether the transaction extra is meant to be a node
+// modify, which must be valid then.
+func (tx *Transaction) IsNodeModify() bool {
+	return bytes.HasPrefix(tx.Extra, []byte(NodeModifyMessagePrefix))
+}
+
+func (tx *Transaction) NodeModify() (*NodeModify, error) {
+	if !tx.IsNodeModify() {
+		return nil, nil
+	}
+	return DecodeNodeModify(tx.Extra, tx.Inputs)
+}
+
+// validateNodeModify requires the signer accepted at the snapshot timestamp,
+// so the modify is always recorded at finalization, and before the node
+// modify fork the extra is only a memo.
+func (tx *Transaction) validateNodeModify(store DataStore, timestamp uint64) error {
+	if !ForkActivated(NodeModifyForkTimestamp, timestamp) {
+		return nil
+	}
+	modify, err := tx.NodeModify()
+	if err != nil || modify == nil {
+		return err
+	}
+	var node *Node
+	nodes := store.ReadAllNodes(timestamp, false)
+	for _, n := range nodes {
+		if n.Signer.PublicSpendKey == modify.Payee || n.Payee.PublicSpendKey == modify.Payee {
+			return fmt.Errorf("invalid node modify payee %s %s", modify.Payee, n.Signer)
+		}
+		if n.State == NodeStateAccepted && n.Signer.PublicSpendKey == modify.Signer {
+			node = n
+		}
+	}
+	if node == nil {
+		return fmt.Errorf("invalid node modify signer %s", modify.Signer)
+	}
+	return nil
+}
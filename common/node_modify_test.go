@@ -0,0 +1,57 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeModify(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := crypto.NewHash([]byte("modify"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	seed = crypto.NewHash([]byte("payee"))
+	payee := crypto.NewKeyFromSeed(append(seed[:], seed[:]...)).Public()
+	inputs := []*Input{{Hash: crypto.NewHash([]byte("input")), Index: 0}}
+	modify := SignNodeModify(key, payee, inputs)
+	data := modify.Encode()
+	assert.Len(data, nodeModifySize)
+
+	decoded, err := DecodeNodeModify(data, inputs)
+	assert.Nil(err)
+	assert.Equal(modify, decoded)
+	assert.Equal(key.Public(), decoded.Signer)
+
+	_, err = DecodeNodeModify(data[:len(data)-1], inputs)
+	assert.Contains(err.Error(), "invalid node modify size")
+	other := SignNodeModify(key, key.Public(), inputs).Encode()
+	copy(other[len(NodeModifyMessagePrefix)+32:], payee[:])
+	_, err = DecodeNodeModify(other, inputs)
+	assert.Contains(err.Error(), "invalid node modify signature")
+	_, err = DecodeNodeModify(data, []*Input{{Hash: inputs[0].Hash, Index: 1}})
+	assert.Contains(err.Error(), "invalid node modify signature")
+
+	m := &NodeModification{Signer: key.Public(), Payee: payee}
+	addr := m.PayeeAddress()
+	assert.Equal(payee, addr.PublicSpendKey)
+	assert.Equal(payee.DeterministicHashDerive().Public(), addr.PublicViewKey)
+
+	tx := NewTransaction(XINAssetId)
+	tx.AddInput(inputs[0].Hash, inputs[0].Index)
+	mod, err := tx.NodeModify()
+	assert.Nil(err)
+	assert.Nil(mod)
+	tx.Extra = []byte("memo")
+	assert.False(tx.IsNodeModify())
+	tx.Extra = data
+	assert.True(tx.IsNodeModify())
+	mod, err = tx.NodeModify()
+	assert.Nil(err)
+	assert.Equal(payee, mod.Payee)
+	tx.Extra = data[:len(data)-1]
+	assert.True(tx.IsNodeModify())
+	_, err = tx.NodeModify()
+	assert.NotNil(err)
+}
@@ -0,0 +1,121 @@
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	NodeRemovalMessagePrefix         = "NODEREMOVAL"
+	NodeRemovalEndorsementsLimit     = 64
+	NodeRemovalEndorsementExpiration = 24 * time.Hour
+
+	nodeRemovalEndorsementSize = len(crypto.Key{}) + 8 + len(crypto.Signature{})
+)
+
+// NodeRemovalEndorsement is a consensus node signer endorsing the removal
+// proposal of an offline node, the proposal is a node remove transaction
+// with the endorsements appended to the accept extra. The endorsement is
+// only valid in the snapshots within the expiration since its timestamp.
+type NodeRemovalEndorsement struct {
+	Signer    crypto.Key
+	Timestamp uint64
+	Signature crypto.Signature
+}
+
+func NodeRemovalMessage(accept crypto.Hash, timestamp uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, timestamp)
+	data := append([]byte(NodeRemovalMessagePrefix), accept[:]...)
+	msg := crypto.NewHash(append(data, buf...))
+	return msg[:]
+}
+
+func SignNodeRemoval(signer crypto.Key, accept crypto.Hash, timestamp uint64) *NodeRemovalEndorsement {
+	return &NodeRemovalEndorsement{
+		Signer:    signer.Public(),
+		Timestamp: timestamp,
+		Signature: signer.Sign(NodeRemovalMessage(accept, timestamp)),
+	}
+}
+
+func EncodeNodeRemovalEndorsements(endorsements []*NodeRemovalEndorsement) []byte {
+	data := make([]byte, 0, len(endorsements)*nodeRemovalEndorsementSize)
+	for _, e := range endorsements {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, e.Timestamp)
+		data = append(data, e.Signer[:]...)
+		data = append(data, buf...)
+		data = append(data, e.Signature[:]...)
+	}
+	return data
+}
+
+func DecodeNodeRemovalEndorsements(accept crypto.Hash, data []byte) ([]*NodeRemovalEndorsement, error) {
+	if len(data)%nodeRemovalEndorsementSize != 0 {
+		return nil, fmt.Errorf("invalid node removal endorsements size %d", len(data))
+	}
+	count := len(data) / nodeRemovalEndorsementSize
+	if count > NodeRemovalEndorsementsLimit {
+		return nil, fmt.Errorf("invalid node removal endorsements count %d", count)
+	}
+
+	filter := make(map[crypto.Key]bool)
+	endorsements := make([]*NodeRemovalEndorsement, count)
+	for i := range endorsements {
+		var e NodeRemovalEndorsement
+		b := data[i*nodeRemovalEndorsementSize:]
+		copy(e.Signer[:], b)
+		b = b[len(e.Signer):]
+		e.Timestamp = binary.BigEndian.Uint64(b)
+		copy(e.Signature[:], b[8:])
+		if filter[e.Signer] {
+			return nil, fmt.Errorf("duplicated node removal endorsement %s", e.Signer)
+		}
+		filter[e.Signer] = true
+		if !e.Signer.Verify(NodeRemovalMessage(accept, e.Timestamp), e.Signature) {
+			return nil, fmt.Errorf("invalid node removal endorsement %s", e.Signer)
+		}
+		endorsements[i] = &e
+	}
+	return endorsements, nil
+}
+
+// NodeRemovalEndorsements returns the endorsements of a node remove proposal,
+// and nothing for the rolling removal whose extra is the accept extra only.
+func (tx *Transaction) NodeRemovalEndorsements() ([]*NodeRemovalEndorsement, error) {
+	if len(tx.Inputs) != 1 {
+		return nil, fmt.Errorf("invalid inputs count %d for remove transaction", len(tx.Inputs))
+	}
+	size := len(crypto.Key{}) * 2
+	if len(tx.Extra) < size {
+		return nil, fmt.Errorf("invalid remove transaction extra size %d", len(tx.Extra))
+	}
+	return DecodeNodeRemovalEndorsements(tx.Inputs[0].Hash, tx.Extra[size:])
+}
+
+// validateNodeRemovalEndorsements checks the endorsements of a node remove
+// proposal against the snapshot timestamp, they are only activated since the
+// node removal fork, and each of them expires after a day.
+func (tx *Transaction) validateNodeRemovalEndorsements(timestamp uint64) error {
+	endorsements, err := tx.NodeRemovalEndorsements()
+	if err != nil || len(endorsements) == 0 {
+		return err
+	}
+	if !ForkActivated(NodeRemovalForkTimestamp, timestamp) {
+		return fmt.Errorf("node removal endorsements not activated %d", timestamp)
+	}
+	for _, e := range endorsements {
+		if e.Timestamp > timestamp+config.SnapshotRoundGap*config.SnapshotReferenceThreshold {
+			return fmt.Errorf("node removal endorsement future timestamp %s %d %d", e.Signer, e.Timestamp, timestamp)
+		}
+		if e.Timestamp+uint64(NodeRemovalEndorsementExpiration) <= timestamp {
+			return fmt.Errorf("node removal endorsement expired %s %d %d", e.Signer, e.Timestamp, timestamp)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeRemovalEndorsements(t *testing.T) {
+	assert := assert.New(t)
+
+	accept := crypto.NewHash([]byte("accept"))
+	endorsements := make([]*NodeRemovalEndorsement, 3)
+	for i := range endorsements {
+		seed := crypto.NewHash([]byte{byte(i)})
+		key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+		endorsements[i] = SignNodeRemoval(key, accept, 1600000000000000000+uint64(i))
+	}
+	data := EncodeNodeRemovalEndorsements(endorsements)
+	assert.Len(data, 3*104)
+
+	decoded, err := DecodeNodeRemovalEndorsements(accept, data)
+	assert.Nil(err)
+	assert.Equal(endorsements, decoded)
+	decoded, err = DecodeNodeRemovalEndorsements(accept, nil)
+	assert.Nil(err)
+	assert.Len(decoded, 0)
+
+	_, err = DecodeNodeRemovalEndorsements(crypto.NewHash([]byte("other")), data)
+	assert.Contains(err.Error(), "invalid node removal endorsement")
+	_, err = DecodeNodeRemovalEndorsements(accept, data[:len(data)-1])
+	assert.Contains(err.Error(), "invalid node removal endorsements size")
+	_, err = DecodeNodeRemovalEndorsements(accept, append(data, data[:104]...))
+	assert.Contains(err.Error(), "duplicated node removal endorsement")
+	other := EncodeNodeRemovalEndorsements(endorsements[:1])
+	other[len(crypto.Key{})+7] += 1
+	_, err = DecodeNodeRemovalEndorsements(accept, other)
+	assert.Contains(err.Error(), "invalid node removal endorsement")
+
+	tx := NewTransaction(XINAssetId)
+	tx.AddInput(accept, 0)
+	tx.Extra = make([]byte, 64)
+	decoded, err = tx.NodeRemovalEndorsements()
+	assert.Nil(err)
+	assert.Len(decoded, 0)
+	tx.Extra = append(tx.Extra, data...)
+	decoded, err = tx.NodeRemovalEndorsements()
+	assert.Nil(err)
+	assert.Len(decoded, 3)
+	tx.Extra = tx.Extra[:63]
+	_, err = tx.NodeRemovalEndorsements()
+	assert.NotNil(err)
+}
@@ -1,16 +1,24 @@
 package common
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"strconv"
+
+	"github.com/MixinNetwork/mixin/crypto"
 )
 
 const (
-	Operator0   = 0x00
-	Operator64  = 0x40
-	OperatorSum = 0xfe
-	OperatorCmp = 0xff
+	Operator0        = 0x00
+	Operator64       = 0x40
+	OperatorHashlock = 0xfc
+	OperatorExpire   = 0xfd
+	OperatorSum      = 0xfe
+	OperatorCmp      = 0xff
+
+	expirationScriptLength = 14
+	hashlockScriptLength   = expirationScriptLength + 1 + 32
 )
 
 type Script []uint8
@@ -19,8 +27,29 @@ func NewThresholdScript(threshold uint8) Script {
 	return Script{OperatorCmp, OperatorSum, threshold}
 }
 
+// NewExpirationScript makes an output spendable by the threshold of its
+// leading keys before the expire timestamp, and only by the refund threshold
+// of its last refund keys, i.e. the sender, since then.
+func NewExpirationScript(threshold uint8, expire uint64, refundThreshold, refundKeys uint8) Script {
+	s := make(Script, expirationScriptLength)
+	s[0], s[1], s[2], s[3] = OperatorCmp, OperatorSum, threshold, OperatorExpire
+	binary.BigEndian.PutUint64(s[4:12], expire)
+	s[12], s[13] = refundThreshold, refundKeys
+	return s
+}
+
+// NewHashlockScript makes an expiration script whose leading keys can spend
+// the output only with the preimage of the hashlock as the transaction extra,
+// so the same secret settles both sides of an atomic swap across two assets.
+func NewHashlockScript(threshold uint8, hashlock crypto.Hash, expire uint64, refundThreshold, refundKeys uint8) Script {
+	s := make(Script, 0, hashlockScriptLength)
+	s = append(s, NewExpirationScript(threshold, expire, refundThreshold, refundKeys)...)
+	s = append(s, OperatorHashlock)
+	return append(s, hashlock[:]...)
+}
+
 func (s Script) VerifyFormat() error {
-	if len(s) != 3 {
+	if len(s) != 3 && len(s) != expirationScriptLength && len(s) != hashlockScriptLength {
 		return fmt.Errorf("invalid script length %d", len(s))
 	}
 	if s[0] != OperatorCmp || s[1] != OperatorSum {
@@ -29,20 +58,144 @@ func (s Script) VerifyFormat() error {
 	if s[2] > Operator64 {
 		return fmt.Errorf("invalid script threshold %d", s[2])
 	}
+	if len(s) == 3 {
+		return nil
+	}
+	if s[3] != OperatorExpire {
+		return fmt.Errorf("invalid script operator %d", s[3])
+	}
+	if s.Expiration() == 0 {
+		return fmt.Errorf("invalid script expiration %d", s.Expiration())
+	}
+	if s[12] > Operator64 {
+		return fmt.Errorf("invalid script refund threshold %d", s[12])
+	}
+	if s[13] == 0 || s[13] > Operator64 || s[12] > s[13] {
+		return fmt.Errorf("invalid script refund keys %d %d", s[12], s[13])
+	}
+	if len(s) == expirationScriptLength {
+		return nil
+	}
+	if s[14] != OperatorHashlock {
+		return fmt.Errorf("invalid script operator %d", s[14])
+	}
+	if !s.Hashlock().HasValue() {
+		return fmt.Errorf("invalid script hashlock %s", s.Hashlock())
+	}
+	return nil
+}
+
+// VerifyKeys checks the keys count of an output with this script.
+func (s Script) VerifyKeys(keys int) error {
+	if len(s) < expirationScriptLength {
+		return nil
+	}
+	if keys <= int(s[13]) {
+		return fmt.Errorf("invalid script keys count %d %d", keys, s[13])
+	}
 	return nil
 }
 
+// Expiration returns the expire timestamp, or 0 for the scripts that never expire.
+func (s Script) Expiration() uint64 {
+	if len(s) < expirationScriptLength {
+		return 0
+	}
+	return binary.BigEndian.Uint64(s[4:12])
+}
+
+// Hashlock returns the hash of the secret to spend the output before its
+// expiration, or the zero hash for the scripts without hashlock.
+func (s Script) Hashlock() crypto.Hash {
+	var hash crypto.Hash
+	if len(s) != hashlockScriptLength {
+		return hash
+	}
+	copy(hash[:], s[expirationScriptLength+1:])
+	return hash
+}
+
 func (s Script) Validate(sum int) error {
 	err := s.VerifyFormat()
 	if err != nil {
 		return err
 	}
+	if s.Expiration() > 0 {
+		return fmt.Errorf("invalid script expiration without signers")
+	}
 	if sum < int(s[2]) {
 		return fmt.Errorf("invalid signature keys %d %d", sum, s[2])
 	}
 	return nil
 }
 
+// ValidateSigners checks the signer indexes of all the keys, and returns
+// whether the signers are the refund keys of an expiration script.
+func (s Script) ValidateSigners(signers []int, keys int) (bool, error) {
+	if s.Expiration() == 0 {
+		return false, s.Validate(len(signers))
+	}
+	err := s.VerifyFormat()
+	if err != nil {
+		return false, err
+	}
+	err = s.VerifyKeys(keys)
+	if err != nil {
+		return false, err
+	}
+
+	boundary, refunds := keys-int(s[13]), 0
+	for _, i := range signers {
+		if i >= boundary {
+			refunds += 1
+		}
+	}
+	switch refunds {
+	case 0:
+		if len(signers) < int(s[2]) {
+			return false, fmt.Errorf("invalid signature keys %d %d", len(signers), s[2])
+		}
+		return false, nil
+	case len(signers):
+		if refunds < int(s[12]) {
+			return true, fmt.Errorf("invalid refund signature keys %d %d", refunds, s[12])
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid mixed signature keys %d %d", len(signers), refunds)
+	}
+}
+
+// ValidateTimestamp checks the spending timestamp against the expiration,
+// the refund keys can spend only when the output has expired, and the others
+// only before that.
+func (s Script) ValidateTimestamp(refund bool, timestamp uint64) error {
+	expire := s.Expiration()
+	if expire == 0 {
+		return nil
+	}
+	if refund && timestamp < expire {
+		return fmt.Errorf("output not expired %d %d", timestamp, expire)
+	}
+	if !refund && timestamp >= expire {
+		return fmt.Errorf("output expired %d %d", timestamp, expire)
+	}
+	return nil
+}
+
+// ValidatePreimage checks the transaction extra against the hashlock, which
+// is not required for the refund keys.
+func (s Script) ValidatePreimage(refund bool, extra []byte) error {
+	hashlock := s.Hashlock()
+	if refund || !hashlock.HasValue() {
+		return nil
+	}
+	if crypto.NewHash(extra) != hashlock {
+		return fmt.Errorf("invalid hashlock preimage %s", hashlock)
+	}
+	return nil
+}
+
 func (s Script) String() string {
 	return hex.EncodeToString(s[:])
 }
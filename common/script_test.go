@@ -3,6 +3,7 @@ package common
 import (
 	"testing"
 
+	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,3 +47,88 @@ func TestScript(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal("fffe01", s.String())
 }
+
+func TestExpirationScript(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewExpirationScript(2, 1600000000000000000, 1, 1)
+	assert.Equal("fffe02fd16345785d8a000000101", s.String())
+	assert.Nil(s.VerifyFormat())
+	assert.Equal(uint64(1600000000000000000), s.Expiration())
+	assert.Equal(uint64(0), NewThresholdScript(1).Expiration())
+	assert.NotNil(s.Validate(2))
+	assert.NotNil(s.VerifyKeys(1))
+	assert.Nil(s.VerifyKeys(3))
+
+	assert.NotNil(NewExpirationScript(2, 0, 1, 1).VerifyFormat())
+	assert.NotNil(NewExpirationScript(2, 1, 1, 0).VerifyFormat())
+	assert.NotNil(NewExpirationScript(2, 1, 2, 1).VerifyFormat())
+	assert.NotNil(NewExpirationScript(65, 1, 1, 1).VerifyFormat())
+	invalid := NewExpirationScript(2, 1, 1, 1)
+	invalid[3] = OperatorSum
+	assert.NotNil(invalid.VerifyFormat())
+
+	refund, err := s.ValidateSigners([]int{0, 1}, 3)
+	assert.Nil(err)
+	assert.False(refund)
+	_, err = s.ValidateSigners([]int{0}, 3)
+	assert.NotNil(err)
+	refund, err = s.ValidateSigners([]int{2}, 3)
+	assert.Nil(err)
+	assert.True(refund)
+	_, err = s.ValidateSigners([]int{0, 2}, 3)
+	assert.NotNil(err)
+	_, err = s.ValidateSigners([]int{0, 1}, 1)
+	assert.NotNil(err)
+
+	assert.Nil(s.ValidateTimestamp(false, s.Expiration()-1))
+	assert.NotNil(s.ValidateTimestamp(false, s.Expiration()))
+	assert.NotNil(s.ValidateTimestamp(true, s.Expiration()-1))
+	assert.Nil(s.ValidateTimestamp(true, s.Expiration()))
+
+	plain := NewThresholdScript(1)
+	refund, err = plain.ValidateSigners([]int{3}, 4)
+	assert.Nil(err)
+	assert.False(refund)
+	assert.Nil(plain.ValidateTimestamp(false, 0))
+}
+
+func TestHashlockScript(t *testing.T) {
+	assert := assert.New(t)
+
+	secret := []byte("mixin atomic swap secret")
+	hashlock := crypto.NewHash(secret)
+	s := NewHashlockScript(1, hashlock, 1600000000000000000, 1, 1)
+	assert.Len(s, 47)
+	assert.Equal("fffe01fd16345785d8a000000101fc"+hashlock.String(), s.String())
+	assert.Nil(s.VerifyFormat())
+	assert.Equal(uint64(1600000000000000000), s.Expiration())
+	assert.Equal(hashlock, s.Hashlock())
+	assert.False(NewExpirationScript(1, 1, 1, 1).Hashlock().HasValue())
+	assert.NotNil(s.VerifyKeys(1))
+	assert.Nil(s.VerifyKeys(2))
+
+	assert.NotNil(NewHashlockScript(1, crypto.Hash{}, 1, 1, 1).VerifyFormat())
+	invalid := NewHashlockScript(1, hashlock, 1, 1, 1)
+	invalid[14] = OperatorExpire
+	assert.NotNil(invalid.VerifyFormat())
+	assert.NotNil(Script(s[:20]).VerifyFormat())
+
+	refund, err := s.ValidateSigners([]int{0}, 2)
+	assert.Nil(err)
+	assert.False(refund)
+	assert.Nil(s.ValidatePreimage(false, secret))
+	assert.NotNil(s.ValidatePreimage(false, nil))
+	assert.NotNil(s.ValidatePreimage(false, hashlock[:]))
+	refund, err = s.ValidateSigners([]int{1}, 2)
+	assert.Nil(err)
+	assert.True(refund)
+	assert.Nil(s.ValidatePreimage(true, nil))
+	assert.Nil(NewThresholdScript(1).ValidatePreimage(false, nil))
+
+	j, err := s.MarshalJSON()
+	assert.Nil(err)
+	var decoded Script
+	assert.Nil(decoded.UnmarshalJSON(j))
+	assert.Equal(s, decoded)
+}
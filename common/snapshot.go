@@ -18,6 +18,25 @@ type Round struct {
 	References *RoundLink
 }
 
+// GraphHead is the compact summary of the head rounds of a node, updated
+// with the snapshots and rounds in the same transaction, so the node loads
+// the chain state on boot without reading the rounds history. The finals
+// are the latest final rounds, at most the reference threshold, oldest first.
+type GraphHead struct {
+	NodeId crypto.Hash
+	Number uint64
+	Start  uint64
+	End    uint64
+	Finals []*GraphHeadRound
+}
+
+type GraphHeadRound struct {
+	Number uint64
+	Start  uint64
+	End    uint64
+	Hash   crypto.Hash
+}
+
 type RoundLink struct {
 	Self     crypto.Hash
 	External crypto.Hash
@@ -42,6 +61,16 @@ type Snapshot struct {
 	Signatures  []*crypto.Signature   `msgpack:",omitempty"`
 	Signature   *crypto.CosiSignature `msgpack:",omitempty"`
 	Hash        crypto.Hash           `msgpack:"-"`
+
+	decoded *decodedSnapshot
+}
+
+// decodedSnapshot is the payload hash computed by UnmarshalSnapshot before
+// the snapshot is shared, it is never written again so it is safe to read
+// from any goroutine, and it is ignored by a copy of the snapshot.
+type decodedSnapshot struct {
+	snapshot    *Snapshot
+	payloadHash crypto.Hash
 }
 
 type SnapshotWithTopologicalOrder struct {
@@ -89,10 +118,35 @@ func (s *Snapshot) VersionedPayload() []byte {
 	}
 }
 
+// PayloadHash returns the hash computed when the snapshot is decoded by
+// UnmarshalSnapshot, otherwise it is hashed on each call, because the self
+// snapshots are changed before signed.
 func (s *Snapshot) PayloadHash() crypto.Hash {
+	if d := s.decoded; d != nil && d.snapshot == s {
+		return d.payloadHash
+	}
 	return crypto.NewHash(s.VersionedPayload())
 }
 
+// UnmarshalSnapshot decodes the snapshot of the network messages and hashes
+// its payload only once, so the snapshot must not be changed after decoded,
+// and any change should be made to a copy.
+func UnmarshalSnapshot(data []byte) (*Snapshot, error) {
+	var s *Snapshot
+	err := MsgpackUnmarshal(data, &s)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("invalid snapshot data %d", len(data))
+	}
+	if s.Version != 0 && s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("invalid snapshot version %d", s.Version)
+	}
+	s.decoded = &decodedSnapshot{snapshot: s, payloadHash: s.PayloadHash()}
+	return s, nil
+}
+
 func (tx *VersionedTransaction) LockInputs(locker UTXOLocker, fork bool) error {
 	switch tx.TransactionType() {
 	case TransactionTypeMint:
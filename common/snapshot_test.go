@@ -49,6 +49,69 @@ func TestSnapshot(t *testing.T) {
 	assert.True(checkSignature(s, key.Public()))
 }
 
+func TestSnapshotPayloadHash(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &Snapshot{
+		Version:     SnapshotVersion,
+		NodeId:      crypto.NewHash([]byte("node")),
+		Transaction: crypto.NewHash([]byte("transaction")),
+		RoundNumber: 7,
+		Timestamp:   1551312000000000000,
+	}
+	hash := s.PayloadHash()
+	assert.Equal(crypto.NewHash(s.VersionedPayload()), hash)
+	assert.Equal(hash, s.PayloadHash())
+
+	s.References = &RoundLink{}
+	assert.NotEqual(hash, s.PayloadHash())
+	assert.Equal(crypto.NewHash(s.VersionedPayload()), s.PayloadHash())
+	s.References.External = crypto.NewHash([]byte("external"))
+	assert.Equal(crypto.NewHash(s.VersionedPayload()), s.PayloadHash())
+	s.Timestamp += 1
+	assert.Equal(crypto.NewHash(s.VersionedPayload()), s.PayloadHash())
+	s.Version = 0
+	assert.Equal(crypto.NewHash(s.VersionedPayload()), s.PayloadHash())
+
+	data := MsgpackMarshalPanic(s)
+	decoded, err := UnmarshalSnapshot(data)
+	assert.Nil(err)
+	assert.Equal(s.PayloadHash(), decoded.PayloadHash())
+	assert.Equal(data, MsgpackMarshalPanic(decoded))
+	_, err = UnmarshalSnapshot(MsgpackMarshalPanic(nil))
+	assert.NotNil(err)
+	s.Version = 2
+	_, err = UnmarshalSnapshot(MsgpackMarshalPanic(s))
+	assert.Contains(err.Error(), "invalid snapshot version 2")
+
+	copied := *decoded
+	copied.Timestamp += 1
+	assert.Equal(crypto.NewHash(copied.VersionedPayload()), copied.PayloadHash())
+	assert.NotEqual(decoded.PayloadHash(), copied.PayloadHash())
+	assert.Equal(crypto.NewHash(decoded.VersionedPayload()), decoded.PayloadHash())
+}
+
+func BenchmarkSnapshotPayloadHash(b *testing.B) {
+	s := &Snapshot{Version: SnapshotVersion, References: &RoundLink{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.PayloadHash()
+	}
+}
+
+func BenchmarkDecodedSnapshotPayloadHash(b *testing.B) {
+	s, err := UnmarshalSnapshot(MsgpackMarshalPanic(&Snapshot{Version: SnapshotVersion, References: &RoundLink{}}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.PayloadHash()
+	}
+}
+
 func checkSignature(s *Snapshot, pub crypto.Key) bool {
 	msg := s.PayloadHash()
 	for _, sig := range s.Signatures {
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ValidationCodeMalformed     = "malformed"
+	ValidationCodeSignature     = "signature"
+	ValidationCodeInputNotFound = "input_not_found"
+	ValidationCodeInputSpent    = "input_spent"
+	ValidationCodeInputAsset    = "input_asset"
+	ValidationCodeScript        = "script"
+	ValidationCodeInput         = "input"
+	ValidationCodeOutput        = "output"
+	ValidationCodeAmount        = "amount"
+	ValidationCodeTransaction   = "transaction"
+	ValidationCodeExpiration    = "expiration"
+	ValidationCodeFork          = "fork"
+	ValidationCodeInvalid       = "invalid"
+)
+
+// ValidationError tags the validation error with a code, for the clients
+// to tell the reason of a rejected transaction without parsing the message.
+type ValidationError struct {
+	Code string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func validationErrorf(code, format string, args ...interface{}) error {
+	return &ValidationError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// ValidationErrorCode returns the code of the error tagged, otherwise the
+// code of the failed check.
+func ValidationErrorCode(check string, err error) string {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve.Code
+	}
+	switch check {
+	case "version", "type", "count", "extra", "size":
+		return ValidationCodeMalformed
+	case "signatures":
+		return ValidationCodeSignature
+	case "inputs":
+		return ValidationCodeInput
+	case "outputs":
+		return ValidationCodeOutput
+	case "amount":
+		return ValidationCodeAmount
+	case "transaction":
+		return ValidationCodeTransaction
+	case "expiration":
+		return ValidationCodeExpiration
+	case "forks":
+		return ValidationCodeFork
+	}
+	return ValidationCodeInvalid
+}
+
+// ValidationStep is one check performed by the transaction validation,
+// with the values compared and the time spent on it.
+type ValidationStep struct {
+	Check   string        `json:"check"`
+	Values  []interface{} `json:"values"`
+	Error   string        `json:"error,omitempty"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// ValidationTrace records the validation steps of a single transaction, it
+// is only used to debug a rejected transaction, a nil trace records nothing.
+type ValidationTrace struct {
+	Steps []*ValidationStep `json:"steps"`
+}
+
+func (t *ValidationTrace) Run(check string, fn func() error, values ...interface{}) error {
+	if t == nil {
+		return fn()
+	}
+	start := time.Now()
+	err := fn()
+	step := &ValidationStep{
+		Check:   check,
+		Values:  values,
+		Elapsed: time.Since(start),
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	t.Steps = append(t.Steps, step)
+	return err
+}
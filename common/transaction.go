@@ -104,6 +104,25 @@ func (tx *Transaction) ViewGhostKey(a *crypto.Key) []*Output {
 	return outputs
 }
 
+func (tx *Transaction) OwnedOutputs(a, B *crypto.Key) []int {
+	indexes := make([]int, 0)
+
+	for i, o := range tx.Outputs {
+		if o.Type != OutputTypeScript {
+			continue
+		}
+		for _, k := range o.Keys {
+			key := crypto.ViewGhostOutputKey(k, a, &o.Mask, uint64(i))
+			if *key == *B {
+				indexes = append(indexes, i)
+				break
+			}
+		}
+	}
+
+	return indexes
+}
+
 func (tx *SignedTransaction) TransactionType() uint8 {
 	for _, in := range tx.Inputs {
 		if in.Mint != nil {
@@ -376,3 +395,11 @@ func (tx *Transaction) AddRandomScriptOutput(accounts []*Address, s Script, amou
 	tx.AddScriptOutput(accounts, s, amount, seed)
 	return nil
 }
+
+// AddAtomicSwapOutput locks the amount to the receivers with the hashlock
+// before the expire timestamp, and refunds it to the senders since then.
+func (tx *Transaction) AddAtomicSwapOutput(receivers []*Address, threshold uint8, senders []*Address, refundThreshold uint8, hashlock crypto.Hash, expire uint64, amount Integer, seed []byte) {
+	accounts := append(append([]*Address{}, receivers...), senders...)
+	s := NewHashlockScript(threshold, hashlock, expire, refundThreshold, uint8(len(senders)))
+	tx.AddScriptOutput(accounts, s, amount, seed)
+}
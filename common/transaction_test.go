@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"testing"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/bitcoin"
+	"github.com/MixinNetwork/mixin/domains/ethereum"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,6 +88,7 @@ func TestTransaction(t *testing.T) {
 		err = ver.Validate(store, false)
 		if i < len(ver.Inputs)-1 {
 			assert.NotNil(err)
+			assert.Equal(ValidationCodeSignature, ValidationErrorCode("signatures", err))
 		} else {
 			assert.Nil(err)
 		}
@@ -168,6 +172,10 @@ func TestTransaction(t *testing.T) {
 	assert.Len(outputs, 2)
 	assert.NotEqual(outputs[1].Keys[1].String(), accounts[1].PublicSpendKey.String())
 	assert.NotEqual(outputs[1].Keys[1].String(), accounts[1].PublicViewKey.String())
+	owned := ver.OwnedOutputs(&accounts[1].PrivateViewKey, &accounts[1].PublicSpendKey)
+	assert.Equal([]int{1}, owned)
+	owned = ver.OwnedOutputs(&accounts[1].PrivateSpendKey, &accounts[1].PublicSpendKey)
+	assert.Len(owned, 0)
 
 	ver.AggregatedSignature = &AggregatedSignature{}
 	err = ver.Validate(store, false)
@@ -226,8 +234,10 @@ func TestTransaction(t *testing.T) {
 }
 
 type storeImpl struct {
-	seed     []byte
-	accounts []*Address
+	seed      []byte
+	accounts  []*Address
+	nodes     []*Node
+	custodian *CustodianSet
 }
 
 func (store storeImpl) ReadUTXOKeys(hash crypto.Hash, index int) (*UTXOKeys, error) {
@@ -282,8 +292,12 @@ func (store storeImpl) ReadDomains() []Domain {
 	return nil
 }
 
+func (store storeImpl) ReadCustodianSet(_ uint64) (*CustodianSet, error) {
+	return store.custodian, nil
+}
+
 func (store storeImpl) ReadAllNodes(_ uint64, _ bool) []*Node {
-	return nil
+	return store.nodes
 }
 
 func (store storeImpl) ReadTransaction(hash crypto.Hash) (*VersionedTransaction, string, error) {
@@ -406,3 +420,58 @@ func (ver *VersionedTransaction) resetCache() {
 	ver.hash = crypto.Hash{}
 	ver.pmbytes = nil
 }
+
+func TestValidationTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	var none *ValidationTrace
+	err := none.Run("none", func() error { return nil })
+	assert.Nil(err)
+
+	ver := NewTransaction(XINAssetId).AsLatestVersion()
+	trace, err := ver.ValidateWithTrace(nil, false)
+	assert.NotNil(err)
+	assert.Len(trace.Steps, 3)
+	assert.Equal("version", trace.Steps[0].Check)
+	assert.Equal([]interface{}{uint8(TxVersion), TxVersion}, trace.Steps[0].Values)
+	assert.Equal("", trace.Steps[0].Error)
+	assert.Equal("type", trace.Steps[1].Check)
+	assert.Equal("count", trace.Steps[2].Check)
+	assert.Equal([]interface{}{0, 0, SliceCountLimit}, trace.Steps[2].Values)
+	assert.Equal(err.Error(), trace.Steps[2].Error)
+	assert.Equal(err.Error(), ver.Validate(nil, false).Error())
+	assert.Equal(ValidationCodeMalformed, ValidationErrorCode(trace.Steps[2].Check, err))
+
+	err = validationErrorf(ValidationCodeInputSpent, "input locked for transaction %s", crypto.Hash{})
+	assert.Equal(ValidationCodeInputSpent, ValidationErrorCode("inputs", err))
+	assert.Equal(ValidationCodeInput, ValidationErrorCode("inputs", fmt.Errorf("invalid input")))
+	assert.Equal(ValidationCodeInvalid, ValidationErrorCode("v1", fmt.Errorf("invalid input")))
+}
+
+func TestDepositProof(t *testing.T) {
+	assert := assert.New(t)
+
+	tx := NewTransaction(XINAssetId)
+	tx.AddDepositInput(&DepositData{
+		Chain:           ethereum.EthereumChainId,
+		AssetKey:        "0x0000000000000000000000000000000000000000",
+		TransactionHash: "0x07f073bd2c056be7833c270215c162bff6774673a318d0e842dc27aac686a3ec",
+		Amount:          NewInteger(1),
+	})
+	assert.Nil(tx.DepositProof())
+	assert.Nil(tx.VerifyDepositProof())
+	tx.Extra = []byte("DEPOSIT")
+	assert.Nil(tx.DepositProof())
+	tx.Extra = []byte(DepositProofMagic + "proof")
+	assert.Equal([]byte("proof"), tx.DepositProof())
+	assert.NotNil(tx.VerifyDepositProof())
+
+	tx.Inputs[0].Deposit.Chain = bitcoin.BitcoinChainId
+	assert.NotNil(tx.VerifyDepositProof())
+
+	tx = NewTransaction(XINAssetId)
+	tx.AddInput(crypto.NewHash([]byte("input")), 0)
+	tx.Extra = []byte(DepositProofMagic + "proof")
+	assert.Nil(tx.DepositProof())
+	assert.Nil(tx.VerifyDepositProof())
+}
@@ -0,0 +1,142 @@
+package txbuilder
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// Builder assembles a script transaction from owned UTXOs, the errors of
+// the chained calls are deferred and returned by Build or Sign.
+type Builder struct {
+	tx    *common.Transaction
+	utxos []*common.UTXO
+	err   error
+}
+
+func New(asset crypto.Hash) *Builder {
+	return &Builder{tx: common.NewTransaction(asset)}
+}
+
+func (b *Builder) AddInput(utxo *common.UTXO) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if utxo == nil {
+		b.err = fmt.Errorf("invalid input utxo")
+		return b
+	}
+	if utxo.Asset != b.tx.Asset {
+		b.err = fmt.Errorf("invalid input asset %s %s", utxo.Asset, b.tx.Asset)
+		return b
+	}
+	for _, u := range b.utxos {
+		if u.Hash == utxo.Hash && u.Index == utxo.Index {
+			b.err = fmt.Errorf("duplicated input %s:%d", utxo.Hash, utxo.Index)
+			return b
+		}
+	}
+	b.tx.AddInput(utxo.Hash, utxo.Index)
+	b.utxos = append(b.utxos, utxo)
+	return b
+}
+
+func (b *Builder) AddOutput(address string, amount common.Integer) *Builder {
+	return b.AddMultisigOutput([]string{address}, 1, amount)
+}
+
+// AddMultisigOutput locks the amount to the ghost keys of all addresses,
+// spendable by any threshold of them.
+func (b *Builder) AddMultisigOutput(addresses []string, threshold uint8, amount common.Integer) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if amount.Sign() <= 0 {
+		b.err = fmt.Errorf("invalid output amount %s", amount)
+		return b
+	}
+	if threshold == 0 || int(threshold) > len(addresses) {
+		b.err = fmt.Errorf("invalid output threshold %d/%d", threshold, len(addresses))
+		return b
+	}
+	accounts := make([]*common.Address, len(addresses))
+	for i, s := range addresses {
+		addr, err := common.NewAddressFromString(s)
+		if err != nil {
+			b.err = err
+			return b
+		}
+		accounts[i] = &addr
+	}
+	seed := make([]byte, 64)
+	_, err := rand.Read(seed)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.tx.AddScriptOutput(accounts, common.NewThresholdScript(threshold), amount, seed)
+	return b
+}
+
+func (b *Builder) SetExtra(extra []byte) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(extra) > common.ExtraSizeLimit {
+		b.err = fmt.Errorf("invalid extra size %d", len(extra))
+		return b
+	}
+	b.tx.Extra = extra
+	return b
+}
+
+// UTXOs returns the inputs in order, which all multisig participants need
+// to sign the transaction returned by Build.
+func (b *Builder) UTXOs() []*common.UTXO {
+	return b.utxos
+}
+
+// Build checks the inputs and outputs balance and returns the unsigned
+// transaction.
+func (b *Builder) Build() (*common.VersionedTransaction, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.utxos) == 0 {
+		return nil, fmt.Errorf("invalid inputs count 0")
+	}
+	if len(b.tx.Outputs) == 0 {
+		return nil, fmt.Errorf("invalid outputs count 0")
+	}
+	inputs, outputs := common.NewInteger(0), common.NewInteger(0)
+	for _, u := range b.utxos {
+		inputs = inputs.Add(u.Amount)
+	}
+	for _, o := range b.tx.Outputs {
+		outputs = outputs.Add(o.Amount)
+	}
+	if inputs.Cmp(outputs) != 0 {
+		return nil, fmt.Errorf("invalid amount %s %s", inputs, outputs)
+	}
+	return b.tx.AsLatestVersion(), nil
+}
+
+// Sign builds the transaction and signs all inputs with the account keys,
+// which should meet the threshold of every input on its own.
+func (b *Builder) Sign(viewKey, spendKey crypto.Key) (*common.VersionedTransaction, error) {
+	ver, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	err = SignMultisig(ver, b.utxos, viewKey, spendKey)
+	if err != nil {
+		return nil, err
+	}
+	err = VerifyThreshold(ver, b.utxos)
+	if err != nil {
+		return nil, err
+	}
+	return ver, nil
+}
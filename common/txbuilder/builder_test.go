@@ -0,0 +1,143 @@
+package txbuilder
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, bob := testAccount(1), testAccount(2)
+	utxos := testUTXOs(common.XINAssetId, []*common.Address{alice}, 1, "100", "23.5")
+
+	b := New(common.XINAssetId)
+	b.AddInput(utxos[0]).AddInput(utxos[1])
+	b.AddOutput(bob.String(), common.NewIntegerFromString("120"))
+	b.AddOutput(alice.String(), common.NewIntegerFromString("3.5"))
+	b.SetExtra([]byte("txbuilder"))
+	assert.Len(b.UTXOs(), 2)
+
+	ver, err := b.Sign(bob.PrivateViewKey, bob.PrivateSpendKey)
+	assert.NotNil(err)
+	assert.Nil(ver)
+	ver, err = b.Sign(alice.PrivateViewKey, alice.PrivateSpendKey)
+	assert.Nil(err)
+	assert.Len(ver.Inputs, 2)
+	assert.Len(ver.Outputs, 2)
+	assert.Len(ver.SignaturesMap, 2)
+	assert.Equal([]byte("txbuilder"), ver.Extra)
+	assert.Nil(VerifyThreshold(ver, utxos))
+
+	ver, err = common.UnmarshalVersionedTransaction(ver.Marshal())
+	assert.Nil(err)
+	assert.Nil(VerifyThreshold(ver, utxos))
+	assert.Equal([]int{0}, ver.OwnedOutputs(&bob.PrivateViewKey, &bob.PublicSpendKey))
+	assert.Equal([]int{1}, ver.OwnedOutputs(&alice.PrivateViewKey, &alice.PublicSpendKey))
+
+	outputs := ver.UnspentOutputs()
+	assert.Equal(0, GhostKeyIndex(outputs[0], bob.PrivateViewKey, bob.PublicSpendKey))
+	assert.Equal(-1, GhostKeyIndex(outputs[0], alice.PrivateViewKey, alice.PublicSpendKey))
+
+	ver.SignaturesMap[1][0] = ver.SignaturesMap[0][0]
+	assert.NotNil(VerifyThreshold(ver, utxos))
+}
+
+func TestBuilderErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	alice := testAccount(1)
+	utxos := testUTXOs(common.XINAssetId, []*common.Address{alice}, 1, "100")
+	others := testUTXOs(crypto.NewHash([]byte("other")), []*common.Address{alice}, 1, "100")
+
+	_, err := New(common.XINAssetId).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(nil).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(others[0]).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(utxos[0]).AddInput(utxos[0]).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(utxos[0]).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(utxos[0]).AddOutput("XIN", common.NewInteger(100)).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(utxos[0]).AddOutput(alice.String(), common.NewInteger(0)).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(utxos[0]).AddOutput(alice.String(), common.NewInteger(99)).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(utxos[0]).AddMultisigOutput([]string{alice.String()}, 2, common.NewInteger(100)).Build()
+	assert.NotNil(err)
+	_, err = New(common.XINAssetId).AddInput(utxos[0]).AddOutput(alice.String(), common.NewInteger(100)).SetExtra(make([]byte, common.ExtraSizeLimit+1)).Build()
+	assert.NotNil(err)
+
+	ver, err := New(common.XINAssetId).AddInput(utxos[0]).AddOutput(alice.String(), common.NewInteger(100)).Build()
+	assert.Nil(err)
+	assert.Len(ver.SignaturesMap, 0)
+	assert.NotNil(SignMultisig(ver, nil, alice.PrivateViewKey, alice.PrivateSpendKey))
+	assert.NotNil(SignMultisig(ver, others, alice.PrivateViewKey, alice.PrivateSpendKey))
+	assert.NotNil(VerifyThreshold(ver, utxos))
+}
+
+func TestMultisig(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, bob, carol := testAccount(1), testAccount(2), testAccount(3)
+	members := []*common.Address{alice, bob, carol}
+	utxos := testUTXOs(common.XINAssetId, members, 2, "100")
+
+	b := New(common.XINAssetId).AddInput(utxos[0])
+	b.AddMultisigOutput([]string{alice.String(), bob.String(), carol.String()}, 2, common.NewInteger(60))
+	b.AddOutput(carol.String(), common.NewInteger(40))
+	_, err := b.Sign(alice.PrivateViewKey, alice.PrivateSpendKey)
+	assert.NotNil(err)
+
+	ver, err := b.Build()
+	assert.Nil(err)
+	raw := ver.Marshal()
+
+	av, _ := common.UnmarshalVersionedTransaction(raw)
+	cv, _ := common.UnmarshalVersionedTransaction(raw)
+	assert.Nil(SignMultisig(av, b.UTXOs(), alice.PrivateViewKey, alice.PrivateSpendKey))
+	assert.NotNil(VerifyThreshold(av, b.UTXOs()))
+	assert.Nil(SignMultisig(cv, b.UTXOs(), carol.PrivateViewKey, carol.PrivateSpendKey))
+	assert.NotNil(VerifyThreshold(cv, b.UTXOs()))
+
+	assert.Nil(MergeSignatures(ver, av, cv))
+	assert.Len(ver.SignaturesMap[0], 2)
+	assert.NotNil(ver.SignaturesMap[0][0])
+	assert.Nil(ver.SignaturesMap[0][1])
+	assert.NotNil(ver.SignaturesMap[0][2])
+	assert.Nil(VerifyThreshold(ver, b.UTXOs()))
+
+	outputs := ver.UnspentOutputs()
+	assert.Equal(0, GhostKeyIndex(outputs[0], alice.PrivateViewKey, alice.PublicSpendKey))
+	assert.Equal(1, GhostKeyIndex(outputs[0], bob.PrivateViewKey, bob.PublicSpendKey))
+	assert.Equal(2, GhostKeyIndex(outputs[0], carol.PrivateViewKey, carol.PublicSpendKey))
+	assert.Equal(0, GhostKeyIndex(outputs[1], carol.PrivateViewKey, carol.PublicSpendKey))
+
+	other, err := New(common.XINAssetId).AddInput(utxos[0]).AddOutput(bob.String(), common.NewInteger(100)).Build()
+	assert.Nil(err)
+	assert.NotNil(MergeSignatures(ver, other))
+}
+
+func testAccount(i byte) *common.Address {
+	a := common.NewAddressFromSeed(bytes.Repeat([]byte{i}, 64))
+	return &a
+}
+
+func testUTXOs(asset crypto.Hash, accounts []*common.Address, threshold uint8, amounts ...string) []*common.UTXO {
+	tx := common.NewTransaction(asset)
+	tx.AddInput(crypto.NewHash([]byte("genesis")), 0)
+	for _, a := range amounts {
+		seed := make([]byte, 64)
+		rand.Read(seed)
+		tx.AddScriptOutput(accounts, common.NewThresholdScript(threshold), common.NewIntegerFromString(a), seed)
+	}
+	return tx.AsLatestVersion().UnspentOutputs()
+}
@@ -0,0 +1,109 @@
+package txbuilder
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// GhostKeyIndex returns the index of the utxo ghost key owned by the
+// account, or -1 if the account is not a receiver of the utxo.
+func GhostKeyIndex(utxo *common.UTXO, viewKey crypto.Key, spendPublic crypto.Key) int {
+	for i, k := range utxo.Keys {
+		spend := crypto.ViewGhostOutputKey(k, &viewKey, &utxo.Mask, uint64(utxo.Index))
+		if *spend == spendPublic {
+			return i
+		}
+	}
+	return -1
+}
+
+// SignMultisig adds the signatures of a participant to all inputs it
+// owns, the utxos must be the inputs of the transaction in order.
+func SignMultisig(ver *common.VersionedTransaction, utxos []*common.UTXO, viewKey, spendKey crypto.Key) error {
+	if len(utxos) != len(ver.Inputs) {
+		return fmt.Errorf("invalid utxos count %d %d", len(utxos), len(ver.Inputs))
+	}
+	if len(ver.SignaturesMap) == 0 {
+		ver.SignaturesMap = make([]map[uint16]*crypto.Signature, len(ver.Inputs))
+	}
+	if len(ver.SignaturesMap) != len(ver.Inputs) {
+		return fmt.Errorf("invalid signatures count %d %d", len(ver.SignaturesMap), len(ver.Inputs))
+	}
+
+	msg := ver.PayloadMarshal()
+	var signed int
+	for i, in := range ver.Inputs {
+		utxo := utxos[i]
+		if utxo.Hash != in.Hash || utxo.Index != in.Index {
+			return fmt.Errorf("invalid utxo %s:%d for input %d", utxo.Hash, utxo.Index, i)
+		}
+		k := GhostKeyIndex(utxo, viewKey, spendKey.Public())
+		if k < 0 {
+			continue
+		}
+		priv := crypto.DeriveGhostPrivateKey(&utxo.Mask, &viewKey, &spendKey, uint64(utxo.Index))
+		sig := priv.Sign(msg)
+		if ver.SignaturesMap[i] == nil {
+			ver.SignaturesMap[i] = make(map[uint16]*crypto.Signature)
+		}
+		ver.SignaturesMap[i][uint16(k)] = &sig
+		signed++
+	}
+	if signed == 0 {
+		return fmt.Errorf("no input owned by %s", spendKey.Public())
+	}
+	return nil
+}
+
+// MergeSignatures collects the signatures of the same transaction signed
+// by other participants.
+func MergeSignatures(ver *common.VersionedTransaction, others ...*common.VersionedTransaction) error {
+	if len(ver.SignaturesMap) == 0 {
+		ver.SignaturesMap = make([]map[uint16]*crypto.Signature, len(ver.Inputs))
+	}
+	for _, o := range others {
+		if o.PayloadHash() != ver.PayloadHash() {
+			return fmt.Errorf("invalid transaction %s %s", o.PayloadHash(), ver.PayloadHash())
+		}
+		if len(o.SignaturesMap) != len(ver.SignaturesMap) {
+			return fmt.Errorf("invalid signatures count %d %d", len(o.SignaturesMap), len(ver.SignaturesMap))
+		}
+		for i, sigs := range o.SignaturesMap {
+			if len(sigs) > 0 && ver.SignaturesMap[i] == nil {
+				ver.SignaturesMap[i] = make(map[uint16]*crypto.Signature)
+			}
+			for k, sig := range sigs {
+				ver.SignaturesMap[i][k] = sig
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyThreshold checks the signatures of every input against its ghost
+// keys and whether they meet the input script threshold.
+func VerifyThreshold(ver *common.VersionedTransaction, utxos []*common.UTXO) error {
+	if len(utxos) != len(ver.Inputs) || len(ver.SignaturesMap) != len(ver.Inputs) {
+		return fmt.Errorf("invalid signatures count %d %d", len(ver.SignaturesMap), len(ver.Inputs))
+	}
+	msg := ver.PayloadMarshal()
+	for i, utxo := range utxos {
+		signers := make([]int, 0, len(ver.SignaturesMap[i]))
+		for k, sig := range ver.SignaturesMap[i] {
+			if int(k) >= len(utxo.Keys) {
+				return fmt.Errorf("invalid signature key index %d/%d", k, len(utxo.Keys))
+			}
+			if !utxo.Keys[k].Verify(msg, *sig) {
+				return fmt.Errorf("invalid signature %d for input %d", k, i)
+			}
+			signers = append(signers, int(k))
+		}
+		_, err := utxo.Script.ValidateSigners(signers, len(utxo.Keys))
+		if err != nil {
+			return fmt.Errorf("input %d %s", i, err.Error())
+		}
+	}
+	return nil
+}
@@ -55,6 +55,7 @@ type DataStore interface {
 	GhostChecker
 	NodeReader
 	DomainReader
+	CustodianReader
 }
 
 func (tx *VersionedTransaction) UnspentOutputs() []*UTXO {
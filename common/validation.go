@@ -8,56 +8,141 @@ import (
 )
 
 func (ver *VersionedTransaction) Validate(store DataStore, fork bool) error {
+	return ver.validate(store, fork, nil)
+}
+
+// ValidateWithTrace runs the same checks as Validate, and records each of
+// them to the trace, which stops at the first failed check.
+func (ver *VersionedTransaction) ValidateWithTrace(store DataStore, fork bool) (*ValidationTrace, error) {
+	trace := &ValidationTrace{}
+	err := ver.validate(store, fork, trace)
+	return trace, err
+}
+
+func (ver *VersionedTransaction) validate(store DataStore, fork bool, trace *ValidationTrace) error {
 	tx := &ver.SignedTransaction
 	msg := ver.PayloadMarshal()
 	txType := tx.TransactionType()
 
 	if ver.Version < TxVersion {
-		return ver.validateV1(store, fork)
+		return trace.Run("v1", func() error {
+			return ver.validateV1(store, fork)
+		}, ver.Version)
 	}
 
-	if ver.Version != TxVersion {
-		return fmt.Errorf("invalid tx version %d %d", ver.Version, tx.Version)
+	err := trace.Run("version", func() error {
+		if ver.Version != TxVersion {
+			return fmt.Errorf("invalid tx version %d %d", ver.Version, tx.Version)
+		}
+		return nil
+	}, ver.Version, TxVersion)
+	if err != nil {
+		return err
 	}
-	if txType == TransactionTypeUnknown {
-		return fmt.Errorf("invalid tx type %d", txType)
+	err = trace.Run("type", func() error {
+		if txType == TransactionTypeUnknown {
+			return fmt.Errorf("invalid tx type %d", txType)
+		}
+		return nil
+	}, txType)
+	if err != nil {
+		return err
 	}
-	if len(tx.Inputs) < 1 || len(tx.Outputs) < 1 {
-		return fmt.Errorf("invalid tx inputs or outputs %d %d", len(tx.Inputs), len(tx.Outputs))
+	err = trace.Run("count", func() error {
+		if len(tx.Inputs) < 1 || len(tx.Outputs) < 1 {
+			return fmt.Errorf("invalid tx inputs or outputs %d %d", len(tx.Inputs), len(tx.Outputs))
+		}
+		if len(tx.Inputs) > SliceCountLimit || len(tx.Outputs) > SliceCountLimit {
+			return fmt.Errorf("invalid tx inputs or outputs %d %d", len(tx.Inputs), len(tx.Outputs))
+		}
+		return nil
+	}, len(tx.Inputs), len(tx.Outputs), SliceCountLimit)
+	if err != nil {
+		return err
 	}
-	if len(tx.Inputs) > SliceCountLimit || len(tx.Outputs) > SliceCountLimit {
-		return fmt.Errorf("invalid tx inputs or outputs %d %d", len(tx.Inputs), len(tx.Outputs))
+	extraSizeLimit := ExtraSizeLimit
+	// The deposit proof extra only exceeds ExtraSizeLimit after the deposit
+	// proof fork, which is checked by ValidateForks.
+	if txType == TransactionTypeDeposit && tx.DepositProof() != nil {
+		extraSizeLimit = DepositProofSizeLimit
 	}
-	if len(tx.Extra) > ExtraSizeLimit {
-		return fmt.Errorf("invalid extra size %d", len(tx.Extra))
+	if txType == TransactionTypeNodeRemove {
+		extraSizeLimit = len(crypto.Key{})*2 + NodeRemovalEndorsementsLimit*nodeRemovalEndorsementSize
 	}
-	if len(msg) > config.TransactionMaximumSize {
-		return fmt.Errorf("invalid transaction size %d", len(msg))
+	// The custodian update extra only exceeds ExtraSizeLimit after the
+	// custodian fork, which is checked by ValidateForks.
+	if txType == TransactionTypeScript && tx.IsCustodianUpdate() {
+		extraSizeLimit = custodianUpdateSizeLimit
 	}
-
-	if tx.AggregatedSignature != nil {
-		if tx.SignaturesMap != nil {
-			return fmt.Errorf("invalid signatures map %d", len(tx.SignaturesMap))
+	err = trace.Run("extra", func() error {
+		if len(tx.Extra) > extraSizeLimit {
+			return fmt.Errorf("invalid extra size %d", len(tx.Extra))
 		}
-	} else {
-		if len(tx.Inputs) != len(tx.SignaturesMap) && txType != TransactionTypeNodeAccept && txType != TransactionTypeNodeRemove {
-			return fmt.Errorf("invalid tx signature number %d %d %d", len(tx.Inputs), len(tx.SignaturesMap), txType)
+		return nil
+	}, len(tx.Extra), extraSizeLimit)
+	if err != nil {
+		return err
+	}
+	err = trace.Run("size", func() error {
+		if len(msg) > config.TransactionMaximumSize {
+			return fmt.Errorf("invalid transaction size %d", len(msg))
 		}
+		return nil
+	}, len(msg), config.TransactionMaximumSize)
+	if err != nil {
+		return err
+	}
+
+	err = trace.Run("signatures", func() error {
+		if tx.AggregatedSignature != nil {
+			if tx.SignaturesMap != nil {
+				return fmt.Errorf("invalid signatures map %d", len(tx.SignaturesMap))
+			}
+		} else {
+			if len(tx.Inputs) != len(tx.SignaturesMap) && txType != TransactionTypeNodeAccept && txType != TransactionTypeNodeRemove {
+				return fmt.Errorf("invalid tx signature number %d %d %d", len(tx.Inputs), len(tx.SignaturesMap), txType)
+			}
+		}
+		return nil
+	}, tx.AggregatedSignature != nil, len(tx.Inputs), len(tx.SignaturesMap))
+	if err != nil {
+		return err
 	}
 
-	inputsFilter, inputAmount, err := validateInputs(store, tx, msg, ver.PayloadHash(), txType, fork)
+	var inputsFilter map[string]*UTXO
+	var inputAmount, outputAmount Integer
+	err = trace.Run("inputs", func() error {
+		inputsFilter, inputAmount, err = validateInputs(store, tx, msg, ver.PayloadHash(), txType, fork)
+		return err
+	}, len(tx.Inputs), fork)
 	if err != nil {
 		return err
 	}
-	outputAmount, err := tx.validateOutputs(store)
+	err = trace.Run("outputs", func() error {
+		outputAmount, err = tx.validateOutputs(store)
+		return err
+	}, len(tx.Outputs))
 	if err != nil {
 		return err
 	}
 
-	if inputAmount.Sign() <= 0 || inputAmount.Cmp(outputAmount) != 0 {
-		return fmt.Errorf("invalid input output amount %s %s", inputAmount.String(), outputAmount.String())
+	err = trace.Run("amount", func() error {
+		if inputAmount.Sign() <= 0 || inputAmount.Cmp(outputAmount) != 0 {
+			return fmt.Errorf("invalid input output amount %s %s", inputAmount.String(), outputAmount.String())
+		}
+		return nil
+	}, inputAmount, outputAmount)
+	if err != nil {
+		return err
 	}
 
+	return trace.Run("transaction", func() error {
+		return ver.validateType(store, txType, msg, inputsFilter)
+	}, txType)
+}
+
+func (ver *VersionedTransaction) validateType(store DataStore, txType uint8, msg []byte, inputsFilter map[string]*UTXO) error {
+	tx := &ver.SignedTransaction
 	switch txType {
 	case TransactionTypeScript:
 		return validateScriptTransaction(inputsFilter)
@@ -87,6 +172,46 @@ func (ver *VersionedTransaction) Validate(store DataStore, fork bool) error {
 	return fmt.Errorf("invalid transaction type %d", txType)
 }
 
+// ValidateExpiration checks the inputs with expiration script against the
+// snapshot timestamp, which is not known by Validate.
+func (ver *VersionedTransaction) ValidateExpiration(store UTXOLockReader, timestamp uint64) error {
+	tx := &ver.SignedTransaction
+	if ver.Version < TxVersion {
+		return nil
+	}
+
+	offset := 0
+	for i, in := range tx.Inputs {
+		if in.Mint != nil || in.Deposit != nil {
+			return nil
+		}
+		utxo, err := store.ReadUTXOLock(in.Hash, in.Index)
+		if err != nil {
+			return err
+		}
+		if utxo == nil {
+			return fmt.Errorf("input not found %s:%d", in.Hash.String(), in.Index)
+		}
+		keys := len(utxo.Keys)
+		if utxo.Type == OutputTypeScript && utxo.Script.Expiration() > 0 {
+			if tx.AggregatedSignature == nil && i >= len(tx.SignaturesMap) {
+				return fmt.Errorf("invalid tx signature number %d %d", len(tx.Inputs), len(tx.SignaturesMap))
+			}
+			signers := inputSigners(i, keys, tx.SignaturesMap, tx.AggregatedSignature, offset)
+			refund, err := utxo.Script.ValidateSigners(signers, keys)
+			if err != nil {
+				return err
+			}
+			err = utxo.Script.ValidateTimestamp(refund, timestamp)
+			if err != nil {
+				return err
+			}
+		}
+		offset += keys
+	}
+	return nil
+}
+
 func validateScriptTransaction(inputs map[string]*UTXO) error {
 	for _, in := range inputs {
 		if in.Type != OutputTypeScript && in.Type != OutputTypeNodeRemove {
@@ -113,7 +238,7 @@ func validateInputs(store UTXOLockReader, tx *SignedTransaction, msg []byte, has
 
 		fk := fmt.Sprintf("%s:%d", in.Hash.String(), in.Index)
 		if inputsFilter[fk] != nil {
-			return inputsFilter, inputAmount, fmt.Errorf("invalid input %s", fk)
+			return inputsFilter, inputAmount, validationErrorf(ValidationCodeInputSpent, "invalid input %s", fk)
 		}
 
 		utxo, err := store.ReadUTXOLock(in.Hash, in.Index)
@@ -121,18 +246,18 @@ func validateInputs(store UTXOLockReader, tx *SignedTransaction, msg []byte, has
 			return inputsFilter, inputAmount, err
 		}
 		if utxo == nil {
-			return inputsFilter, inputAmount, fmt.Errorf("input not found %s:%d", in.Hash.String(), in.Index)
+			return inputsFilter, inputAmount, validationErrorf(ValidationCodeInputNotFound, "input not found %s:%d", in.Hash.String(), in.Index)
 		}
 		if utxo.Asset != tx.Asset {
-			return inputsFilter, inputAmount, fmt.Errorf("invalid input asset %s %s", utxo.Asset.String(), tx.Asset.String())
+			return inputsFilter, inputAmount, validationErrorf(ValidationCodeInputAsset, "invalid input asset %s %s", utxo.Asset.String(), tx.Asset.String())
 		}
 		if utxo.LockHash.HasValue() && utxo.LockHash != hash {
 			if !fork {
-				return inputsFilter, inputAmount, fmt.Errorf("input locked for transaction %s", utxo.LockHash)
+				return inputsFilter, inputAmount, validationErrorf(ValidationCodeInputSpent, "input locked for transaction %s", utxo.LockHash)
 			}
 		}
 
-		err = validateUTXO(i, &utxo.UTXO, tx.SignaturesMap, tx.AggregatedSignature, msg, txType, keySigs, len(allKeys))
+		err = validateUTXO(i, &utxo.UTXO, tx.SignaturesMap, tx.AggregatedSignature, msg, tx.Extra, txType, keySigs, len(allKeys))
 		if err != nil {
 			return inputsFilter, inputAmount, err
 		}
@@ -145,12 +270,12 @@ func validateInputs(store UTXOLockReader, tx *SignedTransaction, msg []byte, has
 		return inputsFilter, inputAmount, nil
 	}
 	if len(keySigs) < len(tx.Inputs) {
-		return inputsFilter, inputAmount, fmt.Errorf("batch verification not ready %d %d", len(tx.Inputs), len(keySigs))
+		return inputsFilter, inputAmount, validationErrorf(ValidationCodeSignature, "batch verification not ready %d %d", len(tx.Inputs), len(keySigs))
 	}
 	if as := tx.AggregatedSignature; as != nil {
 		err := crypto.AggregateVerify(&as.Signature, allKeys, as.Signers, msg)
 		if err != nil {
-			return inputsFilter, inputAmount, fmt.Errorf("aggregate verification failure %s", err)
+			return inputsFilter, inputAmount, validationErrorf(ValidationCodeSignature, "aggregate verification failure %s", err)
 		}
 	} else {
 		var keys []*crypto.Key
@@ -160,7 +285,7 @@ func validateInputs(store UTXOLockReader, tx *SignedTransaction, msg []byte, has
 			sigs = append(sigs, s)
 		}
 		if !crypto.BatchVerify(msg, keys, sigs) {
-			return inputsFilter, inputAmount, fmt.Errorf("batch verification failure %d %d", len(keys), len(sigs))
+			return inputsFilter, inputAmount, validationErrorf(ValidationCodeSignature, "batch verification failure %d %d", len(keys), len(sigs))
 		}
 	}
 	return inputsFilter, inputAmount, nil
@@ -219,6 +344,10 @@ func (tx *Transaction) validateOutputs(store GhostChecker) (Integer, error) {
 			if err != nil {
 				return outputAmount, err
 			}
+			err = o.Script.VerifyKeys(len(o.Keys))
+			if err != nil {
+				return outputAmount, err
+			}
 			if !o.Mask.HasValue() {
 				return outputAmount, fmt.Errorf("invalid script output empty mask %s", o.Mask)
 			}
@@ -231,30 +360,31 @@ func (tx *Transaction) validateOutputs(store GhostChecker) (Integer, error) {
 	return outputAmount, nil
 }
 
-func validateUTXO(index int, utxo *UTXO, sigs []map[uint16]*crypto.Signature, as *AggregatedSignature, msg []byte, txType uint8, keySigs map[*crypto.Key]*crypto.Signature, offset int) error {
+func validateUTXO(index int, utxo *UTXO, sigs []map[uint16]*crypto.Signature, as *AggregatedSignature, msg, extra []byte, txType uint8, keySigs map[*crypto.Key]*crypto.Signature, offset int) error {
 	switch utxo.Type {
 	case OutputTypeScript, OutputTypeNodeRemove:
+		signers := inputSigners(index, len(utxo.Keys), sigs, as, offset)
 		if as != nil {
-			signers, limit := 0, offset+len(utxo.Keys)
-			for _, m := range as.Signers {
-				if m >= limit {
-					break
-				} else if m < offset {
-					continue
-				}
-				keySigs[utxo.Keys[m-offset]] = nil
-				signers += 1
+			for _, i := range signers {
+				keySigs[utxo.Keys[i]] = nil
 			}
-			return utxo.Script.Validate(signers)
 		} else {
 			for i, sig := range sigs[index] {
 				if int(i) >= len(utxo.Keys) {
-					return fmt.Errorf("invalid signature map index %d %d", i, len(utxo.Keys))
+					return validationErrorf(ValidationCodeSignature, "invalid signature map index %d %d", i, len(utxo.Keys))
 				}
 				keySigs[utxo.Keys[i]] = sig
 			}
-			return utxo.Script.Validate(len(sigs[index]))
 		}
+		refund, err := utxo.Script.ValidateSigners(signers, len(utxo.Keys))
+		if err != nil {
+			return &ValidationError{Code: ValidationCodeScript, Err: err}
+		}
+		err = utxo.Script.ValidatePreimage(refund, extra)
+		if err != nil {
+			return &ValidationError{Code: ValidationCodeScript, Err: err}
+		}
+		return nil
 	case OutputTypeNodePledge:
 		if txType == TransactionTypeNodeAccept || txType == TransactionTypeNodeCancel {
 			return nil
@@ -271,3 +401,22 @@ func validateUTXO(index int, utxo *UTXO, sigs []map[uint16]*crypto.Signature, as
 		return fmt.Errorf("invalid input type %d", utxo.Type)
 	}
 }
+
+func inputSigners(index, keys int, sigs []map[uint16]*crypto.Signature, as *AggregatedSignature, offset int) []int {
+	var signers []int
+	if as != nil {
+		for _, m := range as.Signers {
+			if m >= offset+keys {
+				break
+			} else if m < offset {
+				continue
+			}
+			signers = append(signers, m-offset)
+		}
+		return signers
+	}
+	for i := range sigs[index] {
+		signers = append(signers, int(i))
+	}
+	return signers
+}
@@ -7,12 +7,14 @@ import (
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/domains/akash"
 	"github.com/MixinNetwork/mixin/domains/algorand"
+	"github.com/MixinNetwork/mixin/domains/aptos"
 	"github.com/MixinNetwork/mixin/domains/arweave"
 	"github.com/MixinNetwork/mixin/domains/avalanche"
 	"github.com/MixinNetwork/mixin/domains/bch"
 	"github.com/MixinNetwork/mixin/domains/binance"
 	"github.com/MixinNetwork/mixin/domains/bitcoin"
 	"github.com/MixinNetwork/mixin/domains/bsv"
+	"github.com/MixinNetwork/mixin/domains/cardano"
 	"github.com/MixinNetwork/mixin/domains/cosmos"
 	"github.com/MixinNetwork/mixin/domains/dash"
 	"github.com/MixinNetwork/mixin/domains/decred"
@@ -21,23 +23,28 @@ import (
 	"github.com/MixinNetwork/mixin/domains/eos"
 	"github.com/MixinNetwork/mixin/domains/etc"
 	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/MixinNetwork/mixin/domains/evm"
 	"github.com/MixinNetwork/mixin/domains/filecoin"
 	"github.com/MixinNetwork/mixin/domains/handshake"
+	"github.com/MixinNetwork/mixin/domains/hedera"
 	"github.com/MixinNetwork/mixin/domains/horizen"
 	"github.com/MixinNetwork/mixin/domains/kusama"
 	"github.com/MixinNetwork/mixin/domains/litecoin"
 	"github.com/MixinNetwork/mixin/domains/mobilecoin"
+	"github.com/MixinNetwork/mixin/domains/monacoin"
 	"github.com/MixinNetwork/mixin/domains/monero"
 	"github.com/MixinNetwork/mixin/domains/namecoin"
 	"github.com/MixinNetwork/mixin/domains/near"
 	"github.com/MixinNetwork/mixin/domains/nervos"
+	"github.com/MixinNetwork/mixin/domains/osmosis"
+	"github.com/MixinNetwork/mixin/domains/peercoin"
 	"github.com/MixinNetwork/mixin/domains/polkadot"
-	"github.com/MixinNetwork/mixin/domains/polygon"
 	"github.com/MixinNetwork/mixin/domains/ravencoin"
 	"github.com/MixinNetwork/mixin/domains/ripple"
 	"github.com/MixinNetwork/mixin/domains/siacoin"
 	"github.com/MixinNetwork/mixin/domains/solana"
 	"github.com/MixinNetwork/mixin/domains/stellar"
+	"github.com/MixinNetwork/mixin/domains/sui"
 	"github.com/MixinNetwork/mixin/domains/tezos"
 	"github.com/MixinNetwork/mixin/domains/tron"
 	"github.com/MixinNetwork/mixin/domains/zcash"
@@ -85,6 +92,11 @@ func (tx *Transaction) validateWithdrawalSubmit(inputs map[string]*UTXO) error {
 	if id := submit.Withdrawal.Asset().AssetId(); id != tx.Asset {
 		return fmt.Errorf("invalid asset %s %s", tx.Asset, id)
 	}
+	if submit.Withdrawal.Asset().NonFungible() {
+		if submit.Amount.Cmp(NonFungibleSupply) != 0 || len(tx.Outputs) != 1 {
+			return fmt.Errorf("invalid non-fungible withdrawal %s %d", submit.Amount, len(tx.Outputs))
+		}
+	}
 
 	if len(submit.Keys) != 0 {
 		return fmt.Errorf("invalid withdrawal submit keys %d", len(submit.Keys))
@@ -96,80 +108,129 @@ func (tx *Transaction) validateWithdrawalSubmit(inputs map[string]*UTXO) error {
 		return fmt.Errorf("invalid withdrawal submit mask %s", submit.Mask)
 	}
 
-	chainId := submit.Withdrawal.Asset().ChainId
+	return VerifyWithdrawalAddress(submit.Withdrawal.Asset().ChainId, submit.Withdrawal.Address)
+}
+
+// VerifyWithdrawalTag checks the tag for the chains with the memo based
+// transfers, the tag is not checked for the other chains.
+func VerifyWithdrawalTag(chainId crypto.Hash, tag string) error {
+	switch chainId {
+	case eos.EOSChainId:
+		return eos.VerifyMemo(tag)
+	}
+	return nil
+}
+
+// verifyWithdrawalFork checks the address and tag by the stricter rules of
+// the domain fork.
+func verifyWithdrawalFork(chainId crypto.Hash, address, tag string) error {
+	switch chainId {
+	case eos.EOSChainId:
+		err := eos.VerifyAccountName(address)
+		if err != nil {
+			return err
+		}
+	case zcash.ZcashChainId:
+		return zcash.VerifyTransparentAddress(address)
+	case litecoin.LitecoinChainId:
+		return litecoin.VerifyObservableAddress(address)
+	case polkadot.PolkadotChainId:
+		return polkadot.VerifyAccountAddress(address)
+	case kusama.KusamaChainId:
+		return kusama.VerifyAccountAddress(address)
+	}
+	return VerifyWithdrawalTag(chainId, tag)
+}
+
+func VerifyWithdrawalAddress(chainId crypto.Hash, address string) error {
 	switch chainId {
 	case ethereum.EthereumChainId:
-		return ethereum.VerifyAddress(submit.Withdrawal.Address)
+		return ethereum.VerifyAddress(address)
 	case etc.EthereumClassicChainId:
-		return etc.VerifyAddress(submit.Withdrawal.Address)
+		return etc.VerifyAddress(address)
 	case bitcoin.BitcoinChainId:
-		return bitcoin.VerifyAddress(submit.Withdrawal.Address)
+		return bitcoin.VerifyAddress(address)
 	case monero.MoneroChainId:
-		return monero.VerifyAddress(submit.Withdrawal.Address)
+		return monero.VerifyAddress(address)
 	case zcash.ZcashChainId:
-		return zcash.VerifyAddress(submit.Withdrawal.Address)
+		return zcash.VerifyAddress(address)
 	case horizen.HorizenChainId:
-		return horizen.VerifyAddress(submit.Withdrawal.Address)
+		return horizen.VerifyAddress(address)
 	case litecoin.LitecoinChainId:
-		return litecoin.VerifyAddress(submit.Withdrawal.Address)
+		return litecoin.VerifyAddress(address)
 	case dogecoin.DogecoinChainId:
-		return dogecoin.VerifyAddress(submit.Withdrawal.Address)
+		return dogecoin.VerifyAddress(address)
 	case ravencoin.RavencoinChainId:
-		return ravencoin.VerifyAddress(submit.Withdrawal.Address)
+		return ravencoin.VerifyAddress(address)
 	case namecoin.NamecoinChainId:
-		return namecoin.VerifyAddress(submit.Withdrawal.Address)
+		return namecoin.VerifyAddress(address)
+	case monacoin.MonacoinChainId:
+		return monacoin.VerifyAddress(address)
+	case peercoin.PeercoinChainId:
+		return peercoin.VerifyAddress(address)
 	case dash.DashChainId:
-		return dash.VerifyAddress(submit.Withdrawal.Address)
+		return dash.VerifyAddress(address)
 	case decred.DecredChainId:
-		return decred.VerifyAddress(submit.Withdrawal.Address)
+		return decred.VerifyAddress(address)
 	case bch.BitcoinCashChainId:
-		return bch.VerifyAddress(submit.Withdrawal.Address)
+		return bch.VerifyAddress(address)
 	case bsv.BitcoinSVChainId:
-		return bsv.VerifyAddress(submit.Withdrawal.Address)
+		return bsv.VerifyAddress(address)
 	case handshake.HandshakenChainId:
-		return handshake.VerifyAddress(submit.Withdrawal.Address)
+		return handshake.VerifyAddress(address)
 	case nervos.NervosChainId:
-		return nervos.VerifyAddress(submit.Withdrawal.Address)
+		return nervos.VerifyAddress(address)
 	case siacoin.SiacoinChainId:
-		return siacoin.VerifyAddress(submit.Withdrawal.Address)
+		return siacoin.VerifyAddress(address)
 	case filecoin.FilecoinChainId:
-		return filecoin.VerifyAddress(submit.Withdrawal.Address)
+		return filecoin.VerifyAddress(address)
 	case solana.SolanaChainId:
-		return solana.VerifyAddress(submit.Withdrawal.Address)
+		return solana.VerifyAddress(address)
 	case near.NearChainId:
-		return near.VerifyAddress(submit.Withdrawal.Address)
+		return near.VerifyAddress(address)
 	case polkadot.PolkadotChainId:
-		return polkadot.VerifyAddress(submit.Withdrawal.Address)
+		return polkadot.VerifyAddress(address)
 	case kusama.KusamaChainId:
-		return kusama.VerifyAddress(submit.Withdrawal.Address)
+		return kusama.VerifyAddress(address)
 	case ripple.RippleChainId:
-		return ripple.VerifyAddress(submit.Withdrawal.Address)
+		return ripple.VerifyAddress(address)
 	case stellar.StellarChainId:
-		return stellar.VerifyAddress(submit.Withdrawal.Address)
+		return stellar.VerifyAddress(address)
 	case tezos.TezosChainId:
-		return tezos.VerifyAddress(submit.Withdrawal.Address)
+		return tezos.VerifyAddress(address)
 	case eos.EOSChainId:
-		return eos.VerifyAddress(submit.Withdrawal.Address)
+		return eos.VerifyAddress(address)
 	case tron.TronChainId:
-		return tron.VerifyAddress(submit.Withdrawal.Address)
+		return tron.VerifyAddress(address)
 	case mobilecoin.MobileCoinChainId:
-		return mobilecoin.VerifyAddress(submit.Withdrawal.Address)
+		return mobilecoin.VerifyAddress(address)
 	case cosmos.CosmosChainId:
-		return cosmos.VerifyAddress(submit.Withdrawal.Address)
+		return cosmos.VerifyAddress(address)
 	case avalanche.AvalancheChainId:
-		return avalanche.VerifyAddress(submit.Withdrawal.Address)
+		return avalanche.VerifyAddress(address)
 	case binance.BinanceChainId:
-		return binance.VerifyAddress(submit.Withdrawal.Address)
+		return binance.VerifyAddress(address)
 	case akash.AkashChainId:
-		return akash.VerifyAddress(submit.Withdrawal.Address)
+		return akash.VerifyAddress(address)
 	case arweave.ArweaveChainId:
-		return arweave.VerifyAddress(submit.Withdrawal.Address)
+		return arweave.VerifyAddress(address)
 	case dfinity.DfinityChainId:
-		return dfinity.VerifyAddress(submit.Withdrawal.Address)
+		return dfinity.VerifyAddress(address)
 	case algorand.AlgorandChainId:
-		return algorand.VerifyAddress(submit.Withdrawal.Address)
-	case polygon.PolygonChainId:
-		return polygon.VerifyAddress(submit.Withdrawal.Address)
+		return algorand.VerifyAddress(address)
+	case sui.SuiChainId:
+		return sui.VerifyAddress(address)
+	case aptos.AptosChainId:
+		return aptos.VerifyAddress(address)
+	case cardano.CardanoChainId:
+		return cardano.VerifyAddress(address)
+	case hedera.HederaChainId:
+		return hedera.VerifyAddress(address)
+	case osmosis.OsmosisChainId:
+		return osmosis.VerifyAddress(address)
+	}
+	if c := evm.Lookup(chainId); c != nil {
+		return c.VerifyAddress(address)
 	}
 	return fmt.Errorf("invalid withdrawal chain id %s", chainId)
 }
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"encoding/hex"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/MixinNetwork/mixin/crypto"
@@ -14,7 +18,7 @@ const (
 
 	MainnetId                  = "6430225c42bb015b4da03102fa962e4f4ef3969e03e04345db229f8377ef7997"
 	SnapshotRoundGap           = uint64(3 * time.Second)
-	SnapshotReferenceThreshold = 10
+	SnapshotReferenceThreshold = uint64(10)
 	SnapshotSyncRoundThreshold = 100
 	SnapshotRoundSize          = 200
 	TransactionMaximumSize     = 1024 * 1024
@@ -26,36 +30,195 @@ const (
 	KernelMintTimeBegin = 7
 	KernelMintTimeEnd   = 9
 
-	KernelNodeAcceptTimeBegin     = 13
-	KernelNodeAcceptTimeEnd       = 19
-	KernelNodePledgePeriodMinimum = 12 * time.Hour
-	KernelNodeAcceptPeriodMinimum = 12 * time.Hour
-	KernelNodeAcceptPeriodMaximum = 7 * 24 * time.Hour
+	KernelNodeAcceptTimeBegin      = 13
+	KernelNodeAcceptTimeEnd        = 19
+	KernelNodePledgePeriodMinimum  = 12 * time.Hour
+	KernelNodeAcceptPeriodMinimum  = 12 * time.Hour
+	KernelNodeAcceptPeriodMaximum  = 7 * 24 * time.Hour
+	KernelNodeOfflinePeriodMinimum = 3 * 24 * time.Hour
+	KernelNodeModifyDelay          = 3 * 24 * time.Hour
+
+	OverflowBlock      = "block"
+	OverflowDropOldest = "drop-oldest"
+	OverflowDropNew    = "drop-new"
+
+	AnnouncementOverflowQueue   = "queue"
+	AnnouncementOverflowDiscard = "discard"
+
+	FanoutSerial   = "serial"
+	FanoutParallel = "parallel"
+	FanoutTree     = "tree"
+	FanoutAuto     = "auto"
+
+	StorageProfileSSD       = "ssd"
+	StorageProfileHDD       = "hdd"
+	StorageProfileLowMemory = "low-memory"
+
+	StorageCompressionNone   = "none"
+	StorageCompressionSnappy = "snappy"
+	StorageCompressionZSTD   = "zstd"
+
+	SignerBackendLocal  = "local"
+	SignerBackendRemote = "remote"
+	SignerBackendPKCS11 = "pkcs11"
+
+	SnapshotRoundGapMinimum           = uint64(100 * time.Millisecond)
+	SnapshotReferenceThresholdMinimum = 2
 )
 
+func VerifySnapshotRound(gap, threshold uint64) error {
+	if gap < SnapshotRoundGapMinimum || gap > SnapshotRoundGap {
+		return fmt.Errorf("invalid snapshot round gap %d", gap)
+	}
+	if threshold < SnapshotReferenceThresholdMinimum || threshold > SnapshotReferenceThreshold {
+		return fmt.Errorf("invalid snapshot reference threshold %d", threshold)
+	}
+	return nil
+}
+
+// Checkpoint pins the snapshot that must be in the round of the node, the
+// node id is the one with network.
+type Checkpoint struct {
+	Node     crypto.Hash
+	Round    uint64
+	Snapshot crypto.Hash
+}
+
 type Custom struct {
 	Node struct {
 		Signer               crypto.Key `toml:"-"`
 		SignerStr            string     `toml:"signer-key"`
+		SignerBackend        string     `toml:"signer-backend"`
+		SignerRemote         string     `toml:"signer-remote"`
+		SignerToken          string     `toml:"signer-token"`
+		SignerPublic         crypto.Key `toml:"-"`
+		SignerPublicStr      string     `toml:"signer-public"`
+		SignerPKCS11Module   string     `toml:"signer-pkcs11-module"`
+		SignerPKCS11Slot     uint       `toml:"signer-pkcs11-slot"`
+		SignerPKCS11Pin      string     `toml:"signer-pkcs11-pin"`
+		SignerPKCS11Label    string     `toml:"signer-pkcs11-label"`
+		SignerPKCS11Wrapped  []byte     `toml:"-"`
+		SignerPKCS11WrapStr  string     `toml:"signer-pkcs11-wrapped"`
 		ConsensusOnly        bool       `toml:"consensus-only"`
 		KernelOprationPeriod int        `toml:"kernel-operation-period"`
 		MemoryCacheSize      int        `toml:"memory-cache-size"`
 		CacheTTL             int        `toml:"cache-ttl"`
+		TransactionCacheSize int        `toml:"transaction-cache-size"`
+		SignatureCacheSize   int        `toml:"signature-cache-size"`
+		SignerAlertThreshold float64    `toml:"signer-alert-threshold"`
+		SignerAlertWebhook   string     `toml:"signer-alert-webhook"`
+		CosiActionsSize      int        `toml:"cosi-actions-size"`
+		CosiActionsOverflow  string     `toml:"cosi-actions-overflow"`
+		AnnouncementInFlight int        `toml:"announcement-inflight"`
+		AnnouncementOverflow string     `toml:"announcement-overflow"`
+		RequeueSize          int        `toml:"requeue-size"`
+		ClockSkewThreshold   int        `toml:"clock-skew-threshold"`
+
+		Checkpoints    []*Checkpoint `toml:"-"`
+		CheckpointsStr []string      `toml:"checkpoints"`
 	} `toml:"node"`
 	Storage struct {
-		ValueLogGC bool `toml:"value-log-gc"`
+		ValueLogGC      bool `toml:"value-log-gc"`
+		DiskBandwidth   int  `toml:"disk-bandwidth"`
+		BackgroundShare int  `toml:"background-share"`
+
+		SnapshotBatchSize   int `toml:"snapshot-batch-size"`
+		SnapshotBatchWindow int `toml:"snapshot-batch-window"`
+
+		Profile          string `toml:"profile"`
+		ValueLogFileSize int    `toml:"value-log-file-size"`
+		MemTableSize     int    `toml:"memtable-size"`
+		NumMemTables     int    `toml:"memtables"`
+		BlockCacheSize   int    `toml:"block-cache-size"`
+		IndexCacheSize   int    `toml:"index-cache-size"`
+		Compression      string `toml:"compression"`
 	} `toml:"storage"`
 	Network struct {
-		Listener        string   `toml:"listener"`
-		GossipNeighbors bool     `toml:"gossip-neighbors"`
-		Peers           []string `toml:"peers"`
+		Listener         string   `toml:"listener"`
+		GossipNeighbors  bool     `toml:"gossip-neighbors"`
+		StaticOnly       bool     `toml:"static-only"`
+		Peers            []string `toml:"peers"`
+		Bootstrap        []string `toml:"bootstrap"`
+		BootstrapKeys    []string `toml:"bootstrap-keys"`
+		AnnouncementRate int      `toml:"announcement-rate"`
+		FinalizationRate int      `toml:"finalization-rate"`
+
+		TransportKeyRotation int  `toml:"transport-key-rotation"`
+		TransportKeyOverlap  int  `toml:"transport-key-overlap"`
+		MutualTLS            bool `toml:"mutual-tls"`
+		StalePeerTimeout     int  `toml:"stale-peer-timeout"`
+		PartitionTimeout     int  `toml:"partition-timeout"`
+
+		Relay          bool          `toml:"relay"`
+		RelayAllow     []crypto.Hash `toml:"-"`
+		RelayAllowStr  []string      `toml:"relay-allow"`
+		Relays         []crypto.Hash `toml:"-"`
+		RelaysStr      []string      `toml:"relays"`
+		RelayBandwidth int           `toml:"relay-bandwidth"`
+
+		PeerQueueSize     int    `toml:"peer-queue-size"`
+		PeerQueueOverflow string `toml:"peer-queue-overflow"`
+
+		FinalizationFanout string `toml:"finalization-fanout"`
+		FanoutConcurrency  int    `toml:"fanout-concurrency"`
+		FanoutTreeSize     int    `toml:"fanout-tree-size"`
 	} `toml:"network"`
 	RPC struct {
-		Runtime bool `toml:"runtime"`
+		Runtime   bool     `toml:"runtime"`
+		Observers []string `toml:"observers"`
+		PeerGraph bool     `toml:"peer-graph"`
+		GRPC      bool     `toml:"grpc"`
 	} `toml:"rpc"`
+	Upgrade struct {
+		Capabilities []string `toml:"capabilities"`
+		Activation   int64    `toml:"activation"`
+	} `toml:"upgrade"`
 	Dev struct {
-		Profile bool `toml:"profile"`
+		Profile        bool  `toml:"profile"`
+		Simulation     bool  `toml:"simulation"`
+		SimulationSeed int64 `toml:"simulation-seed"`
+
+		ChaosMessageDelay   int      `toml:"chaos-message-delay"`
+		ChaosMessageDrop    int      `toml:"chaos-message-drop"`
+		ChaosStorageLatency int      `toml:"chaos-storage-latency"`
+		ChaosCrashPoints    []string `toml:"chaos-crash-points"`
+		ChaosCrashRate      int      `toml:"chaos-crash-rate"`
 	} `toml:"dev"`
+
+	round struct {
+		gap       uint64
+		threshold uint64
+	}
+}
+
+// SetSnapshotRound shortens the round gap and reference threshold for the
+// test network from its genesis, which should be set before the node starts.
+func (c *Custom) SetSnapshotRound(gap, threshold uint64) error {
+	err := VerifySnapshotRound(gap, threshold)
+	if err != nil {
+		return err
+	}
+	c.round.gap = gap
+	c.round.threshold = threshold
+	return nil
+}
+
+// RoundGap returns the round gap of the network, and the mainnet one
+// if the genesis doesn't shorten it.
+func (c *Custom) RoundGap() uint64 {
+	if c == nil || c.round.gap == 0 {
+		return SnapshotRoundGap
+	}
+	return c.round.gap
+}
+
+// ReferenceThreshold returns the reference threshold of the network, and
+// the mainnet one if the genesis doesn't shorten it.
+func (c *Custom) ReferenceThreshold() uint64 {
+	if c == nil || c.round.threshold == 0 {
+		return SnapshotReferenceThreshold
+	}
+	return c.round.threshold
 }
 
 func Initialize(file string) (*Custom, error) {
@@ -68,11 +231,48 @@ func Initialize(file string) (*Custom, error) {
 	if err != nil {
 		return nil, err
 	}
-	key, err := crypto.KeyFromString(config.Node.SignerStr)
-	if err != nil {
-		return nil, err
+	if config.Node.SignerBackend == "" {
+		config.Node.SignerBackend = SignerBackendLocal
+	}
+	switch config.Node.SignerBackend {
+	case SignerBackendLocal:
+		key, err := crypto.KeyFromString(config.Node.SignerStr)
+		if err != nil {
+			return nil, err
+		}
+		config.Node.Signer = key
+	case SignerBackendRemote:
+		if config.Node.SignerStr != "" {
+			return nil, fmt.Errorf("signer-key with the remote signer-backend")
+		}
+		if config.Node.SignerRemote == "" || config.Node.SignerToken == "" {
+			return nil, fmt.Errorf("invalid signer-remote %s", config.Node.SignerRemote)
+		}
+		key, err := crypto.KeyFromString(config.Node.SignerPublicStr)
+		if err != nil {
+			return nil, err
+		}
+		config.Node.SignerPublic = key
+	case SignerBackendPKCS11:
+		if config.Node.SignerStr != "" {
+			return nil, fmt.Errorf("signer-key with the pkcs11 signer-backend")
+		}
+		if config.Node.SignerPKCS11Module == "" || config.Node.SignerPKCS11Label == "" {
+			return nil, fmt.Errorf("invalid signer-pkcs11-module %s", config.Node.SignerPKCS11Module)
+		}
+		wrapped, err := hex.DecodeString(config.Node.SignerPKCS11WrapStr)
+		if err != nil || len(wrapped) == 0 {
+			return nil, fmt.Errorf("invalid signer-pkcs11-wrapped %s", config.Node.SignerPKCS11WrapStr)
+		}
+		config.Node.SignerPKCS11Wrapped = wrapped
+		key, err := crypto.KeyFromString(config.Node.SignerPublicStr)
+		if err != nil {
+			return nil, err
+		}
+		config.Node.SignerPublic = key
+	default:
+		return nil, fmt.Errorf("invalid signer-backend %s", config.Node.SignerBackend)
 	}
-	config.Node.Signer = key
 	if config.Node.KernelOprationPeriod == 0 {
 		config.Node.KernelOprationPeriod = 700
 	}
@@ -82,5 +282,262 @@ func Initialize(file string) (*Custom, error) {
 	if config.Node.CacheTTL == 0 {
 		config.Node.CacheTTL = 3600 * 2
 	}
+	if config.Node.TransactionCacheSize == 0 {
+		config.Node.TransactionCacheSize = 100000
+	}
+	if config.Node.SignatureCacheSize == 0 {
+		config.Node.SignatureCacheSize = 100000
+	}
+	if config.Node.SignerAlertThreshold == 0 {
+		config.Node.SignerAlertThreshold = 0.5
+	}
+	if config.Node.SignerAlertThreshold < 0 || config.Node.SignerAlertThreshold > 1 {
+		return nil, fmt.Errorf("invalid signer-alert-threshold %f", config.Node.SignerAlertThreshold)
+	}
+	if config.Node.CosiActionsSize == 0 {
+		config.Node.CosiActionsSize = 256
+	}
+	if config.Node.CosiActionsSize < 1 {
+		return nil, fmt.Errorf("invalid cosi-actions-size %d", config.Node.CosiActionsSize)
+	}
+	if config.Node.CosiActionsOverflow == "" {
+		config.Node.CosiActionsOverflow = OverflowDropNew
+	}
+	if !validOverflow(config.Node.CosiActionsOverflow) {
+		return nil, fmt.Errorf("invalid cosi-actions-overflow %s", config.Node.CosiActionsOverflow)
+	}
+	if config.Node.AnnouncementInFlight == 0 {
+		config.Node.AnnouncementInFlight = 16
+	}
+	if config.Node.AnnouncementInFlight < 0 {
+		return nil, fmt.Errorf("invalid announcement-inflight %d", config.Node.AnnouncementInFlight)
+	}
+	if config.Node.AnnouncementOverflow == "" {
+		config.Node.AnnouncementOverflow = AnnouncementOverflowQueue
+	}
+	switch config.Node.AnnouncementOverflow {
+	case AnnouncementOverflowQueue, AnnouncementOverflowDiscard:
+	default:
+		return nil, fmt.Errorf("invalid announcement-overflow %s", config.Node.AnnouncementOverflow)
+	}
+	if config.Node.RequeueSize == 0 {
+		config.Node.RequeueSize = 4096
+	}
+	if config.Node.ClockSkewThreshold == 0 {
+		config.Node.ClockSkewThreshold = 3000
+	}
+	if config.Node.ClockSkewThreshold < 0 {
+		return nil, fmt.Errorf("invalid clock-skew-threshold %d", config.Node.ClockSkewThreshold)
+	}
+	checkpoints, err := parseCheckpoints(config.Node.CheckpointsStr)
+	if err != nil {
+		return nil, err
+	}
+	config.Node.Checkpoints = checkpoints
+	if config.Storage.BackgroundShare == 0 {
+		config.Storage.BackgroundShare = 20
+	}
+	if config.Storage.SnapshotBatchSize == 0 {
+		config.Storage.SnapshotBatchSize = 64
+	}
+	if config.Storage.SnapshotBatchWindow == 0 {
+		config.Storage.SnapshotBatchWindow = 2
+	}
+	if config.Storage.SnapshotBatchSize < 0 || config.Storage.SnapshotBatchWindow < 0 {
+		return nil, fmt.Errorf("invalid snapshot batch %d %d", config.Storage.SnapshotBatchSize, config.Storage.SnapshotBatchWindow)
+	}
+	err = applyStorageProfile(&config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Network.AnnouncementRate == 0 {
+		config.Network.AnnouncementRate = 100
+	}
+	if config.Network.FinalizationRate == 0 {
+		config.Network.FinalizationRate = 1000
+	}
+	if config.Network.TransportKeyOverlap == 0 {
+		config.Network.TransportKeyOverlap = 600
+	}
+	if config.Network.RelayBandwidth == 0 {
+		config.Network.RelayBandwidth = 1024 * 1024
+	}
+	config.Network.RelayAllow, err = parseHashes("relay-allow", config.Network.RelayAllowStr)
+	if err != nil {
+		return nil, err
+	}
+	config.Network.Relays, err = parseHashes("relays", config.Network.RelaysStr)
+	if err != nil {
+		return nil, err
+	}
+	if config.Network.PeerQueueSize == 0 {
+		config.Network.PeerQueueSize = 1024
+	}
+	if config.Network.PeerQueueSize < 1 {
+		return nil, fmt.Errorf("invalid peer-queue-size %d", config.Network.PeerQueueSize)
+	}
+	if config.Network.PeerQueueOverflow == "" {
+		config.Network.PeerQueueOverflow = OverflowDropNew
+	}
+	if !validOverflow(config.Network.PeerQueueOverflow) {
+		return nil, fmt.Errorf("invalid peer-queue-overflow %s", config.Network.PeerQueueOverflow)
+	}
+	if config.Network.FinalizationFanout == "" {
+		config.Network.FinalizationFanout = FanoutAuto
+	}
+	switch config.Network.FinalizationFanout {
+	case FanoutSerial, FanoutParallel, FanoutTree, FanoutAuto:
+	default:
+		return nil, fmt.Errorf("invalid finalization-fanout %s", config.Network.FinalizationFanout)
+	}
+	if config.Network.FanoutConcurrency == 0 {
+		config.Network.FanoutConcurrency = 8
+	}
+	if config.Network.FanoutConcurrency < 0 {
+		return nil, fmt.Errorf("invalid fanout-concurrency %d", config.Network.FanoutConcurrency)
+	}
+	if config.Network.FanoutTreeSize == 0 {
+		config.Network.FanoutTreeSize = 64
+	}
+	if config.Dev.Simulation && !InTestBuild() {
+		return nil, fmt.Errorf("simulation not allowed in build version %s", BuildVersion)
+	}
+	if config.Dev.ChaosMessageDelay < 0 || config.Dev.ChaosStorageLatency < 0 {
+		return nil, fmt.Errorf("invalid chaos delay %d %d", config.Dev.ChaosMessageDelay, config.Dev.ChaosStorageLatency)
+	}
+	if config.Dev.ChaosMessageDrop < 0 || config.Dev.ChaosMessageDrop > 100 {
+		return nil, fmt.Errorf("invalid chaos-message-drop %d", config.Dev.ChaosMessageDrop)
+	}
+	if config.Dev.ChaosCrashRate < 0 || config.Dev.ChaosCrashRate > 100 {
+		return nil, fmt.Errorf("invalid chaos-crash-rate %d", config.Dev.ChaosCrashRate)
+	}
 	return &config, nil
 }
+
+// InTestBuild tells whether the binary is not built by build.sh, which
+// replaces the placeholder in the BuildVersion by the commit. The virtual
+// clock of the simulation is only allowed in test builds.
+func InTestBuild() bool {
+	return strings.Contains(BuildVersion, "BUILD_VERSION")
+}
+
+// storageProfile is the badger tuning in MB of a kind of host. The ssd one
+// relies on the page cache for the fast random reads, the hdd one caches
+// more blocks to avoid the seeks, and the low-memory one bounds the tables
+// and indices kept in memory.
+type storageProfile struct {
+	valueLogFileSize int
+	memTableSize     int
+	numMemTables     int
+	blockCacheSize   int
+	indexCacheSize   int
+	compression      string
+}
+
+var storageProfiles = map[string]storageProfile{
+	StorageProfileSSD:       {1024, 64, 5, 0, 0, StorageCompressionNone},
+	StorageProfileHDD:       {1024, 64, 5, 256, 128, StorageCompressionSnappy},
+	StorageProfileLowMemory: {256, 16, 2, 0, 32, StorageCompressionNone},
+}
+
+// applyStorageProfile fills the storage tuning options not set with the
+// values of the profile, so any of them could be overridden alone.
+func applyStorageProfile(config *Custom) error {
+	if config.Storage.Profile == "" {
+		config.Storage.Profile = StorageProfileSSD
+	}
+	p, found := storageProfiles[config.Storage.Profile]
+	if !found {
+		return fmt.Errorf("invalid storage profile %s", config.Storage.Profile)
+	}
+	if config.Storage.ValueLogFileSize == 0 {
+		config.Storage.ValueLogFileSize = p.valueLogFileSize
+	}
+	if config.Storage.MemTableSize == 0 {
+		config.Storage.MemTableSize = p.memTableSize
+	}
+	if config.Storage.NumMemTables == 0 {
+		config.Storage.NumMemTables = p.numMemTables
+	}
+	if config.Storage.BlockCacheSize == 0 {
+		config.Storage.BlockCacheSize = p.blockCacheSize
+	}
+	if config.Storage.IndexCacheSize == 0 {
+		config.Storage.IndexCacheSize = p.indexCacheSize
+	}
+	if config.Storage.Compression == "" {
+		config.Storage.Compression = p.compression
+	}
+
+	// badger requires the value log file size in [1MB, 2GB)
+	if config.Storage.ValueLogFileSize < 1 || config.Storage.ValueLogFileSize >= 2048 {
+		return fmt.Errorf("invalid storage value-log-file-size %d", config.Storage.ValueLogFileSize)
+	}
+	if config.Storage.MemTableSize < 1 || config.Storage.NumMemTables < 1 {
+		return fmt.Errorf("invalid storage memtable %d %d", config.Storage.MemTableSize, config.Storage.NumMemTables)
+	}
+	if config.Storage.BlockCacheSize < 0 || config.Storage.IndexCacheSize < 0 {
+		return fmt.Errorf("invalid storage cache %d %d", config.Storage.BlockCacheSize, config.Storage.IndexCacheSize)
+	}
+	switch config.Storage.Compression {
+	case StorageCompressionNone, StorageCompressionSnappy, StorageCompressionZSTD:
+	default:
+		return fmt.Errorf("invalid storage compression %s", config.Storage.Compression)
+	}
+	if config.Storage.Compression != StorageCompressionNone && config.Storage.BlockCacheSize == 0 {
+		return fmt.Errorf("invalid storage compression %s without block cache", config.Storage.Compression)
+	}
+	return nil
+}
+
+func validOverflow(policy string) bool {
+	switch policy {
+	case OverflowBlock, OverflowDropOldest, OverflowDropNew:
+		return true
+	}
+	return false
+}
+
+// parseCheckpoints parses the checkpoints in the node:round:snapshot form,
+// and at most one checkpoint for each round of a node.
+func parseCheckpoints(list []string) ([]*Checkpoint, error) {
+	filter := make(map[string]bool)
+	checkpoints := make([]*Checkpoint, len(list))
+	for i, str := range list {
+		parts := strings.Split(str, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid checkpoint %s", str)
+		}
+		node, err := crypto.HashFromString(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint node %s", str)
+		}
+		round, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint round %s", str)
+		}
+		snap, err := crypto.HashFromString(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint snapshot %s", str)
+		}
+		key := parts[0] + ":" + parts[1]
+		if filter[key] {
+			return nil, fmt.Errorf("duplicated checkpoint %s", str)
+		}
+		filter[key] = true
+		checkpoints[i] = &Checkpoint{Node: node, Round: round, Snapshot: snap}
+	}
+	return checkpoints, nil
+}
+
+func parseHashes(name string, list []string) ([]crypto.Hash, error) {
+	hashes := make([]crypto.Hash, len(list))
+	for i, str := range list {
+		h, err := crypto.HashFromString(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s", name, str)
+		}
+		hashes[i] = h
+	}
+	return hashes, nil
+}
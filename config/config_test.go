@@ -1,7 +1,11 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -13,13 +17,254 @@ func TestConfig(t *testing.T) {
 	assert.Nil(err)
 
 	assert.Equal("56a7904a2dfd71c397bb48584033d8cb6ddcde9b46b7d91f07d2ede061723a0b", custom.Node.Signer.String())
+	assert.Equal(SignerBackendLocal, custom.Node.SignerBackend)
 	assert.Equal(false, custom.Node.ConsensusOnly)
 	assert.Equal(700, custom.Node.KernelOprationPeriod)
 	assert.Equal(4096, custom.Node.MemoryCacheSize)
 	assert.Equal(7200, custom.Node.CacheTTL)
+	assert.Equal(100000, custom.Node.TransactionCacheSize)
+	assert.Equal(100000, custom.Node.SignatureCacheSize)
+	assert.Equal(0.5, custom.Node.SignerAlertThreshold)
+	assert.Equal("", custom.Node.SignerAlertWebhook)
+	assert.Equal(256, custom.Node.CosiActionsSize)
+	assert.Equal(OverflowDropNew, custom.Node.CosiActionsOverflow)
+	assert.Equal(16, custom.Node.AnnouncementInFlight)
+	assert.Equal(AnnouncementOverflowQueue, custom.Node.AnnouncementOverflow)
+	assert.Equal(4096, custom.Node.RequeueSize)
+	assert.Equal(3000, custom.Node.ClockSkewThreshold)
+	assert.Len(custom.Node.Checkpoints, 0)
+
+	assert.Equal(true, custom.Storage.ValueLogGC)
+	assert.Equal(200, custom.Storage.DiskBandwidth)
+	assert.Equal(20, custom.Storage.BackgroundShare)
+	assert.Equal(64, custom.Storage.SnapshotBatchSize)
+	assert.Equal(2, custom.Storage.SnapshotBatchWindow)
+	assert.Equal(StorageProfileSSD, custom.Storage.Profile)
+	assert.Equal(1024, custom.Storage.ValueLogFileSize)
+	assert.Equal(64, custom.Storage.MemTableSize)
+	assert.Equal(5, custom.Storage.NumMemTables)
+	assert.Equal(0, custom.Storage.BlockCacheSize)
+	assert.Equal(0, custom.Storage.IndexCacheSize)
+	assert.Equal(StorageCompressionNone, custom.Storage.Compression)
 
 	assert.Equal("mixin-node.example.com:7239", custom.Network.Listener)
+	assert.Equal(false, custom.Network.StaticOnly)
+	assert.Equal(100, custom.Network.AnnouncementRate)
+	assert.Equal(1000, custom.Network.FinalizationRate)
+	assert.Equal(24, custom.Network.TransportKeyRotation)
+	assert.Equal(600, custom.Network.TransportKeyOverlap)
+	assert.Equal(false, custom.Network.MutualTLS)
+	assert.Equal(300, custom.Network.StalePeerTimeout)
+	assert.Equal(120, custom.Network.PartitionTimeout)
+	assert.Equal(false, custom.Network.Relay)
+	assert.Len(custom.Network.RelayAllow, 0)
+	assert.Len(custom.Network.Relays, 0)
+	assert.Equal(1048576, custom.Network.RelayBandwidth)
+	assert.Equal(1024, custom.Network.PeerQueueSize)
+	assert.Equal(OverflowDropNew, custom.Network.PeerQueueOverflow)
+	assert.Equal(FanoutAuto, custom.Network.FinalizationFanout)
+	assert.Equal(8, custom.Network.FanoutConcurrency)
+	assert.Equal(64, custom.Network.FanoutTreeSize)
 	assert.Len(custom.Network.Peers, 37)
 	assert.Equal("lehigh.hotot.org:7239", custom.Network.Peers[35])
+	assert.Len(custom.Network.Bootstrap, 0)
+	assert.Len(custom.Network.BootstrapKeys, 0)
 	assert.Equal(false, custom.RPC.Runtime)
+	assert.Len(custom.RPC.Observers, 0)
+	assert.False(custom.RPC.PeerGraph)
+	assert.False(custom.RPC.GRPC)
+	assert.Len(custom.Upgrade.Capabilities, 0)
+	assert.Equal(int64(0), custom.Upgrade.Activation)
+	assert.False(custom.Dev.Simulation)
+	assert.Equal(int64(0), custom.Dev.SimulationSeed)
+	assert.Equal(0, custom.Dev.ChaosMessageDelay)
+	assert.Equal(0, custom.Dev.ChaosMessageDrop)
+	assert.Equal(0, custom.Dev.ChaosStorageLatency)
+	assert.Len(custom.Dev.ChaosCrashPoints, 0)
+	assert.Equal(0, custom.Dev.ChaosCrashRate)
+}
+
+func TestQueueSizes(t *testing.T) {
+	assert := assert.New(t)
+
+	example, err := os.ReadFile("config.example.toml")
+	assert.Nil(err)
+	root, err := os.MkdirTemp("", "mixin-config-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	for _, c := range []string{"cosi-actions-size = 256", "peer-queue-size = 1024"} {
+		for _, size := range []string{"-1", "0"} {
+			key := strings.Split(c, " = ")[0]
+			data := strings.Replace(string(example), c, key+" = "+size, 1)
+			path := filepath.Join(root, "config.toml")
+			assert.Nil(os.WriteFile(path, []byte(data), 0644))
+			custom, err := Initialize(path)
+			if size == "0" {
+				assert.Nil(err)
+				assert.Greater(custom.Node.CosiActionsSize, 0)
+				assert.Greater(custom.Network.PeerQueueSize, 0)
+			} else {
+				assert.Contains(err.Error(), key)
+			}
+		}
+	}
+}
+
+func TestSignerBackend(t *testing.T) {
+	assert := assert.New(t)
+
+	example, err := os.ReadFile("config.example.toml")
+	assert.Nil(err)
+	root, err := os.MkdirTemp("", "mixin-config-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "config.toml")
+	data := strings.Replace(string(example), `signer-backend = "local"`, `signer-backend = "hsm"`, 1)
+	assert.Nil(os.WriteFile(path, []byte(data), 0644))
+	_, err = Initialize(path)
+	assert.Contains(err.Error(), "invalid signer-backend hsm")
+
+	data = strings.Replace(string(example), `signer-backend = "local"`, `signer-backend = "pkcs11"`, 1)
+	assert.Nil(os.WriteFile(path, []byte(data), 0644))
+	_, err = Initialize(path)
+	assert.Contains(err.Error(), "signer-key with the pkcs11 signer-backend")
+
+	data = strings.Replace(data, `signer-key = "56a7904a2dfd71c397bb48584033d8cb6ddcde9b46b7d91f07d2ede061723a0b"`, `signer-key = ""`, 1)
+	assert.Nil(os.WriteFile(path, []byte(data), 0644))
+	_, err = Initialize(path)
+	assert.Contains(err.Error(), "invalid signer-pkcs11-module")
+
+	data = strings.Replace(data, `signer-pkcs11-module = ""`, `signer-pkcs11-module = "/usr/lib/softhsm/libsofthsm2.so"`, 1)
+	data = strings.Replace(data, `signer-pkcs11-label = ""`, `signer-pkcs11-label = "mixin"`, 1)
+	assert.Nil(os.WriteFile(path, []byte(data), 0644))
+	_, err = Initialize(path)
+	assert.Contains(err.Error(), "invalid signer-pkcs11-wrapped")
+
+	data = strings.Replace(data, `signer-pkcs11-wrapped = ""`, `signer-pkcs11-wrapped = "00112233"`, 1)
+	data = strings.Replace(data, `signer-public = ""`, `signer-public = "b9f49cf777dc4d03bc54cd1367eebca319f8603ea1ce18910d09e2c540c630d8"`, 1)
+	assert.Nil(os.WriteFile(path, []byte(data), 0644))
+	custom, err := Initialize(path)
+	assert.Nil(err)
+	assert.Equal(SignerBackendPKCS11, custom.Node.SignerBackend)
+	assert.Equal([]byte{0x00, 0x11, 0x22, 0x33}, custom.Node.SignerPKCS11Wrapped)
+	assert.Equal("b9f49cf777dc4d03bc54cd1367eebca319f8603ea1ce18910d09e2c540c630d8", custom.Node.SignerPublic.String())
+}
+
+func TestSimulation(t *testing.T) {
+	assert := assert.New(t)
+
+	example, err := os.ReadFile("config.example.toml")
+	assert.Nil(err)
+	root, err := os.MkdirTemp("", "mixin-config-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "config.toml")
+	data := strings.Replace(string(example), "simulation = false", "simulation = true", 1)
+	assert.Nil(os.WriteFile(path, []byte(data), 0644))
+	custom, err := Initialize(path)
+	assert.True(InTestBuild())
+	assert.Nil(err)
+	assert.True(custom.Dev.Simulation)
+}
+
+func TestCheckpoints(t *testing.T) {
+	assert := assert.New(t)
+
+	node := "f3fcf842446bcf00f3787fd809a02fb4528c57121481904c41d8c025c861a477"
+	snap := "b3ea56de6124ad2f3ad1d48f2aff8338b761e62bcde6f2f0acba63a32dd8eecc"
+	checkpoints, err := parseCheckpoints([]string{node + ":1024:" + snap, node + ":1025:" + snap})
+	assert.Nil(err)
+	assert.Len(checkpoints, 2)
+	assert.Equal(node, checkpoints[0].Node.String())
+	assert.Equal(uint64(1024), checkpoints[0].Round)
+	assert.Equal(snap, checkpoints[0].Snapshot.String())
+	assert.Equal(uint64(1025), checkpoints[1].Round)
+
+	_, err = parseCheckpoints([]string{node + ":1024:" + snap, node + ":1024:" + snap})
+	assert.NotNil(err)
+	_, err = parseCheckpoints([]string{node + ":1024"})
+	assert.NotNil(err)
+	_, err = parseCheckpoints([]string{node + ":-1:" + snap})
+	assert.NotNil(err)
+	_, err = parseCheckpoints([]string{node[2:] + ":1024:" + snap})
+	assert.NotNil(err)
+	_, err = parseCheckpoints([]string{node + ":1024:" + snap[2:]})
+	assert.NotNil(err)
+}
+
+func TestHashes(t *testing.T) {
+	assert := assert.New(t)
+
+	node := "f3fcf842446bcf00f3787fd809a02fb4528c57121481904c41d8c025c861a477"
+	hashes, err := parseHashes("relays", []string{node})
+	assert.Nil(err)
+	assert.Len(hashes, 1)
+	assert.Equal(node, hashes[0].String())
+	_, err = parseHashes("relays", []string{node[2:]})
+	assert.NotNil(err)
+}
+
+func TestSnapshotRound(t *testing.T) {
+	assert := assert.New(t)
+
+	var custom *Custom
+	assert.Equal(uint64(3*time.Second), custom.RoundGap())
+	assert.Equal(uint64(10), custom.ReferenceThreshold())
+	custom = &Custom{}
+	assert.Equal(uint64(3*time.Second), custom.RoundGap())
+	assert.Equal(uint64(10), custom.ReferenceThreshold())
+
+	assert.NotNil(VerifySnapshotRound(uint64(50*time.Millisecond), 4))
+	assert.Nil(VerifySnapshotRound(uint64(500*time.Millisecond), 4))
+	assert.NotNil(custom.SetSnapshotRound(uint64(50*time.Millisecond), 4))
+	assert.NotNil(custom.SetSnapshotRound(uint64(4*time.Second), 4))
+	assert.NotNil(custom.SetSnapshotRound(uint64(500*time.Millisecond), 1))
+	assert.NotNil(custom.SetSnapshotRound(uint64(500*time.Millisecond), 11))
+	assert.Equal(uint64(3*time.Second), custom.RoundGap())
+	assert.Equal(uint64(10), custom.ReferenceThreshold())
+
+	assert.Nil(custom.SetSnapshotRound(uint64(500*time.Millisecond), 4))
+	assert.Equal(uint64(500*time.Millisecond), custom.RoundGap())
+	assert.Equal(uint64(4), custom.ReferenceThreshold())
+	assert.Equal(uint64(3*time.Second), SnapshotRoundGap)
+	assert.Equal(uint64(10), SnapshotReferenceThreshold)
+}
+
+func TestStorageProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	var custom Custom
+	custom.Storage.Profile = StorageProfileHDD
+	custom.Storage.IndexCacheSize = 64
+	assert.Nil(applyStorageProfile(&custom))
+	assert.Equal(256, custom.Storage.BlockCacheSize)
+	assert.Equal(64, custom.Storage.IndexCacheSize)
+	assert.Equal(StorageCompressionSnappy, custom.Storage.Compression)
+
+	custom = Custom{}
+	custom.Storage.Profile = StorageProfileLowMemory
+	assert.Nil(applyStorageProfile(&custom))
+	assert.Equal(256, custom.Storage.ValueLogFileSize)
+	assert.Equal(2, custom.Storage.NumMemTables)
+
+	custom = Custom{}
+	custom.Storage.Profile = "nvme"
+	assert.NotNil(applyStorageProfile(&custom))
+	custom = Custom{}
+	custom.Storage.ValueLogFileSize = 2048
+	assert.NotNil(applyStorageProfile(&custom))
+	custom = Custom{}
+	custom.Storage.BlockCacheSize = -1
+	assert.NotNil(applyStorageProfile(&custom))
+	custom = Custom{}
+	custom.Storage.Compression = "lz4"
+	assert.NotNil(applyStorageProfile(&custom))
+	custom = Custom{}
+	custom.Storage.Compression = StorageCompressionZSTD
+	assert.NotNil(applyStorageProfile(&custom))
+	custom.Storage.BlockCacheSize = 64
+	assert.Nil(applyStorageProfile(&custom))
 }
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/network"
+	"github.com/urfave/cli/v2"
+)
+
+var transactionTypeNames = map[uint8]string{
+	common.TransactionTypeScript:           "script",
+	common.TransactionTypeMint:             "mint",
+	common.TransactionTypeDeposit:          "deposit",
+	common.TransactionTypeWithdrawalSubmit: "withdrawal_submit",
+	common.TransactionTypeWithdrawalFuel:   "withdrawal_fuel",
+	common.TransactionTypeWithdrawalClaim:  "withdrawal_claim",
+	common.TransactionTypeNodePledge:       "node_pledge",
+	common.TransactionTypeNodeAccept:       "node_accept",
+	common.TransactionTypeNodeRemove:       "node_remove",
+	common.TransactionTypeDomainAccept:     "domain_accept",
+	common.TransactionTypeDomainRemove:     "domain_remove",
+	common.TransactionTypeNodeCancel:       "node_cancel",
+	common.TransactionTypeUnknown:          "unknown",
+}
+
+var outputTypeNames = map[uint8]string{
+	common.OutputTypeScript:              "script",
+	common.OutputTypeWithdrawalSubmit:    "withdrawal_submit",
+	common.OutputTypeWithdrawalFuel:      "withdrawal_fuel",
+	common.OutputTypeNodePledge:          "node_pledge",
+	common.OutputTypeNodeAccept:          "node_accept",
+	common.OutputTypeNodeRemove:          "node_remove",
+	common.OutputTypeDomainAccept:        "domain_accept",
+	common.OutputTypeDomainRemove:        "domain_remove",
+	common.OutputTypeWithdrawalClaim:     "withdrawal_claim",
+	common.OutputTypeNodeCancel:          "node_cancel",
+	common.OutputTypeCustodianDeposit:    "custodian_deposit",
+	common.OutputTypeCustodianWithdrawal: "custodian_withdrawal",
+	common.OutputTypeCustodianMigration:  "custodian_migration",
+}
+
+// decodeRawPayload accepts the hex with or without 0x, or the standard or
+// URL base64, since the captures from the wire come in both forms.
+func decodeRawPayload(raw string) ([]byte, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("empty raw payload")
+	}
+	if data, err := hex.DecodeString(strings.TrimPrefix(raw, "0x")); err == nil {
+		return data, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(raw); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid raw payload, neither hex nor base64")
+}
+
+func decodeTransactionCmd(c *cli.Context) error {
+	raw, err := decodeRawPayload(c.String("raw"))
+	if err != nil {
+		return err
+	}
+	ver, err := common.UnmarshalVersionedTransaction(raw)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(annotateTransaction(ver), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func decodeSnapshotCmd(c *cli.Context) error {
+	raw, err := decodeRawPayload(c.String("raw"))
+	if err != nil {
+		return err
+	}
+	s, err := decodeSnapshotPayload(raw)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(annotateSnapshot(s), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// decodeSnapshotPayload decodes the msgpack snapshot, or the snapshot in
+// the announcement or finalization peer messages with the type prefix.
+func decodeSnapshotPayload(raw []byte) (*common.Snapshot, error) {
+	s, err := common.UnmarshalSnapshot(raw)
+	if err == nil {
+		return s, nil
+	}
+	switch {
+	case len(raw) > 1 && raw[0] == network.PeerMessageTypeSnapshotFinalization:
+		return common.UnmarshalSnapshot(raw[1:])
+	case len(raw) > 33 && raw[0] == network.PeerMessageTypeSnapshotAnnoucement:
+		return common.UnmarshalSnapshot(raw[33:])
+	}
+	return nil, err
+}
+
+func annotateTransaction(ver *common.VersionedTransaction) map[string]interface{} {
+	tm := transactionToMap(ver)
+	tm["type"] = transactionTypeNames[ver.TransactionType()]
+
+	inputs := tm["inputs"].([]map[string]interface{})
+	for i, in := range ver.Inputs {
+		switch {
+		case in.Hash.HasValue():
+			inputs[i]["kind"] = "utxo"
+		case len(in.Genesis) > 0:
+			inputs[i]["kind"] = "genesis"
+		case in.Deposit != nil:
+			inputs[i]["kind"] = "deposit"
+			inputs[i]["asset_id"] = in.Deposit.Asset().AssetId()
+		case in.Mint != nil:
+			inputs[i]["kind"] = "mint"
+		}
+	}
+
+	outputs := tm["outputs"].([]map[string]interface{})
+	for i, out := range ver.Outputs {
+		o := outputs[i]
+		name := outputTypeNames[out.Type]
+		if name == "" {
+			name = fmt.Sprintf("unknown(%d)", out.Type)
+		}
+		o["type_name"] = name
+		if len(out.Keys) > 0 {
+			o["ghost_keys"] = len(out.Keys)
+		}
+		if len(out.Script) > 0 {
+			o["script_meaning"] = describeScript(out.Script, len(out.Keys))
+		}
+	}
+
+	switch {
+	case ver.IsGovernanceSignal():
+		tm["extra_kind"] = "governance_signal"
+	case ver.IsCustodianUpdate():
+		tm["extra_kind"] = "custodian_update"
+	case ver.IsNodeModify():
+		tm["extra_kind"] = "node_modify"
+	}
+	if text := printableText(ver.Extra); text != "" {
+		tm["extra_text"] = text
+	}
+
+	if as := ver.AggregatedSignature; as != nil {
+		tm["aggregated"].(map[string]interface{})["signers_bitmap"] = signersBitmap(as.Signers)
+	}
+	return tm
+}
+
+func annotateSnapshot(s *common.Snapshot) map[string]interface{} {
+	sm := map[string]interface{}{
+		"version":     s.Version,
+		"node":        s.NodeId,
+		"transaction": s.Transaction,
+		"round":       s.RoundNumber,
+		"timestamp":   s.Timestamp,
+		"time":        time.Unix(0, int64(s.Timestamp)).UTC().Format(time.RFC3339Nano),
+		"hash":        s.PayloadHash(),
+	}
+	if r := s.References; r != nil {
+		sm["references"] = map[string]interface{}{
+			"self":     r.Self,
+			"external": r.External,
+		}
+	}
+	if len(s.Signatures) > 0 {
+		sm["signatures"] = s.Signatures
+	}
+	if cs := s.Signature; cs != nil {
+		signers := cs.Keys()
+		sm["signature"] = map[string]interface{}{
+			"signature":      cs.Signature,
+			"mask":           fmt.Sprintf("%016x", cs.Mask),
+			"signers":        signers,
+			"signers_count":  len(signers),
+			"signers_bitmap": signersBitmap(signers),
+		}
+	}
+	return sm
+}
+
+// describeScript explains the script in words, or the format error.
+func describeScript(s common.Script, keys int) string {
+	err := s.VerifyFormat()
+	if err != nil {
+		return "invalid: " + err.Error()
+	}
+	meaning := fmt.Sprintf("%d of %d keys", s[2], keys)
+	if s[2] == 0 {
+		meaning = "anyone can spend"
+	}
+	expire := s.Expiration()
+	if expire == 0 {
+		return meaning
+	}
+	meaning = fmt.Sprintf("%d of the first %d keys", s[2], keys-int(s[13]))
+	if h := s.Hashlock(); h.HasValue() {
+		meaning = meaning + " with the preimage of " + h.String()
+	}
+	at := time.Unix(0, int64(expire)).UTC().Format(time.RFC3339)
+	return fmt.Sprintf("%s before %s, then %d of the last %d keys", meaning, at, s[12], s[13])
+}
+
+// signersBitmap renders the signer indexes as the 64 bits mask, from the
+// lowest index on the right, e.g. 0000...1011 for the signers 0, 1 and 3.
+func signersBitmap(signers []int) string {
+	var mask uint64
+	for _, i := range signers {
+		if i >= 0 && i < 64 {
+			mask |= uint64(1) << uint(i)
+		}
+	}
+	return fmt.Sprintf("%064b", mask)
+}
+
+func printableText(b []byte) string {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return ""
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return ""
+		}
+	}
+	return string(b)
+}
@@ -2,12 +2,16 @@ package algorand
 
 import (
 	"bytes"
+	"crypto/md5"
 	"crypto/sha512"
 	"encoding/base32"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/gofrs/uuid"
 )
 
 var (
@@ -20,11 +24,20 @@ func init() {
 	AlgorandChainId = crypto.NewHash([]byte(AlgorandChainBase))
 }
 
+// VerifyAssetKey accepts the ALGO chain base, or the decimal id of an
+// Algorand Standard Asset without leading zeros.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == AlgorandChainBase {
 		return nil
 	}
-	return fmt.Errorf("invalid algorand asset key %s", assetKey)
+	id, err := strconv.ParseUint(assetKey, 10, 64)
+	if err != nil || id == 0 {
+		return fmt.Errorf("invalid algorand asset key %s", assetKey)
+	}
+	if strconv.FormatUint(id, 10) != assetKey {
+		return fmt.Errorf("invalid algorand asset key %s", assetKey)
+	}
+	return nil
 }
 
 func VerifyAddress(address string) error {
@@ -59,12 +72,23 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case AlgorandChainBase:
-		return AlgorandChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == AlgorandChainBase {
+		return AlgorandChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, AlgorandChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
 }
 
 const (
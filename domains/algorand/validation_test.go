@@ -20,6 +20,16 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAssetKey(addrMain))
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(algo)))
 
+	usdc := "31566704"
+	assert.Nil(VerifyAssetKey(usdc))
+	assert.Nil(VerifyAssetKey("1"))
+	assert.NotNil(VerifyAssetKey("0"))
+	assert.NotNil(VerifyAssetKey("0" + usdc))
+	assert.NotNil(VerifyAssetKey("+" + usdc))
+	assert.NotNil(VerifyAssetKey("-" + usdc))
+	assert.NotNil(VerifyAssetKey(usdc + " "))
+	assert.NotNil(VerifyAssetKey("18446744073709551616"))
+
 	assert.Nil(VerifyAddress(addrMain))
 	assert.NotNil(VerifyAddress(algo))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
@@ -32,4 +42,5 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("706b6f84-3333-4e55-8e89-275e71ce9803")), GenerateAssetId(algo))
 	assert.Equal(crypto.NewHash([]byte("706b6f84-3333-4e55-8e89-275e71ce9803")), AlgorandChainId)
 	assert.Equal(crypto.NewHash([]byte(AlgorandChainBase)), AlgorandChainId)
+	assert.Equal(crypto.NewHash([]byte("a9afeec5-5c79-3a2a-b1af-38717995efd9")), GenerateAssetId(usdc))
 }
@@ -0,0 +1,93 @@
+package aptos
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/gofrs/uuid"
+)
+
+var (
+	AptosChainBase string
+	AptosChainId   crypto.Hash
+)
+
+const NativeCoinType = "0x1::aptos_coin::AptosCoin"
+
+var moveIdentifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+func init() {
+	AptosChainBase = "d2c1c7e1-a1a9-4f88-b282-d93b0a08b42b"
+	AptosChainId = crypto.NewHash([]byte(AptosChainBase))
+}
+
+// VerifyAssetKey accepts the coin type tag "<address>::<module>::<struct>",
+// only the native coin could use the short address, all the other coins
+// must use the full 32 bytes address to have a unique asset id.
+func VerifyAssetKey(assetKey string) error {
+	if assetKey == NativeCoinType {
+		return nil
+	}
+	parts := strings.Split(assetKey, "::")
+	if len(parts) != 3 || verifyAccountAddress(parts[0]) != nil {
+		return fmt.Errorf("invalid aptos asset key %s", assetKey)
+	}
+	if !moveIdentifier.MatchString(parts[1]) || !moveIdentifier.MatchString(parts[2]) {
+		return fmt.Errorf("invalid aptos asset key %s", assetKey)
+	}
+	return nil
+}
+
+func VerifyAddress(address string) error {
+	err := verifyAccountAddress(address)
+	if err != nil {
+		return fmt.Errorf("invalid aptos address %s", address)
+	}
+	return nil
+}
+
+func VerifyTransactionHash(hash string) error {
+	err := verifyAccountAddress(hash)
+	if err != nil {
+		return fmt.Errorf("invalid aptos transaction hash %s", hash)
+	}
+	return nil
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
+		panic(assetKey)
+	}
+
+	if assetKey == NativeCoinType {
+		return AptosChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, AptosChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
+
+// verifyAccountAddress checks the lowercase 0x prefixed 32 bytes hex, the
+// same format as the transaction hash.
+func verifyAccountAddress(address string) error {
+	if !strings.HasPrefix(address, "0x") || len(address) != 66 {
+		return fmt.Errorf("invalid aptos account address %s", address)
+	}
+	if strings.ToLower(address) != address {
+		return fmt.Errorf("invalid aptos account address %s", address)
+	}
+	_, err := hex.DecodeString(address[2:])
+	return err
+}
@@ -0,0 +1,46 @@
+package aptos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	apt := "0x1::aptos_coin::AptosCoin"
+	usdc := "0xf22bede237a07e121b56d91a491eb7bcdfd1f5907926a9e58338f964a01b17fa::asset::USDC"
+	address := "0x8f396e4246b2ba87b51c0739ef5ea4f26515a98375308c31ac2ec1e42142a57f"
+	tx := "0x6b4bad4a0b32e5e1a9d4d6d7a0ab7cf5a1a0fa2ebd0c5a6b3f0e0ad4b8c2f9e1"
+
+	assert.Nil(VerifyAssetKey(apt))
+	assert.Nil(VerifyAssetKey(usdc))
+	assert.NotNil(VerifyAssetKey(address))
+	assert.NotNil(VerifyAssetKey("0x1::aptos_coin::AptosCoin::"))
+	assert.NotNil(VerifyAssetKey("0x01::aptos_coin::AptosCoin"))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(usdc)))
+	assert.NotNil(VerifyAssetKey(usdc[2:]))
+	assert.NotNil(VerifyAssetKey(address + "::1asset::USDC"))
+	assert.NotNil(VerifyAssetKey(address + "::asset::USDC<T>"))
+
+	assert.Nil(VerifyAddress(address))
+	assert.NotNil(VerifyAddress(address[2:]))
+	assert.NotNil(VerifyAddress(address[:65]))
+	assert.NotNil(VerifyAddress("0x1"))
+	assert.NotNil(VerifyAddress(strings.ToUpper(address)))
+	assert.NotNil(VerifyAddress(address[:65] + "g"))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash(tx[2:]))
+	assert.NotNil(VerifyTransactionHash(tx + "0"))
+	assert.NotNil(VerifyTransactionHash(" " + tx))
+	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
+
+	assert.Equal(crypto.NewHash([]byte("d2c1c7e1-a1a9-4f88-b282-d93b0a08b42b")), GenerateAssetId(apt))
+	assert.Equal(crypto.NewHash([]byte("962f624f-42b2-3dbd-b9ed-27329c0756c5")), GenerateAssetId(usdc))
+	assert.Equal(crypto.NewHash([]byte("d2c1c7e1-a1a9-4f88-b282-d93b0a08b42b")), AptosChainId)
+	assert.Equal(crypto.NewHash([]byte(AptosChainBase)), AptosChainId)
+}
@@ -0,0 +1,83 @@
+package avalanche
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const NativeContractAssetKey = "0x0000000000000000000000000000000000000000"
+
+func verifyContractAssetKey(assetKey string) error {
+	if len(assetKey) != 42 {
+		return fmt.Errorf("invalid avalanche asset key %s", assetKey)
+	}
+	if assetKey != strings.ToLower(assetKey) {
+		return fmt.Errorf("invalid avalanche asset key %s", assetKey)
+	}
+	k, err := hex.DecodeString(assetKey[2:])
+	if err != nil {
+		return fmt.Errorf("invalid avalanche asset key %s %s", assetKey, err.Error())
+	}
+	if len(k) != 20 {
+		return fmt.Errorf("invalid avalanche asset key %s", assetKey)
+	}
+	return nil
+}
+
+func verifyEVMAddress(address string) error {
+	if len(address) != 42 {
+		return fmt.Errorf("invalid avalanche address %s", address)
+	}
+	a, err := hex.DecodeString(address[2:])
+	if err != nil {
+		return fmt.Errorf("invalid avalanche address %s %s", address, err.Error())
+	}
+	if len(a) != 20 {
+		return fmt.Errorf("invalid avalanche address %s", address)
+	}
+	if checksumAddress(a) != address {
+		return fmt.Errorf("invalid avalanche address %s", address)
+	}
+	return nil
+}
+
+func verifyEVMTransactionHash(hash string) error {
+	if len(hash) != 66 {
+		return fmt.Errorf("invalid avalanche transaction hash %s", hash)
+	}
+	if strings.ToLower(hash) != hash {
+		return fmt.Errorf("invalid avalanche transaction hash %s", hash)
+	}
+	h, err := hex.DecodeString(hash[2:])
+	if err != nil {
+		return fmt.Errorf("invalid avalanche transaction hash %s %s", hash, err.Error())
+	}
+	if len(h) != 32 {
+		return fmt.Errorf("invalid avalanche transaction hash %s", hash)
+	}
+	return nil
+}
+
+// checksumAddress formats the C-Chain address with the EIP-55 checksum.
+func checksumAddress(a []byte) string {
+	buf := []byte("0x" + hex.EncodeToString(a))
+
+	sha := sha3.NewLegacyKeccak256()
+	sha.Write(buf[2:])
+	hash := sha.Sum(nil)
+	for i := 2; i < len(buf); i++ {
+		hashByte := hash[(i-2)/2]
+		if i%2 == 0 {
+			hashByte = hashByte >> 4
+		} else {
+			hashByte &= 0xf
+		}
+		if buf[i] > '9' && hashByte > 7 {
+			buf[i] -= 32
+		}
+	}
+	return string(buf)
+}
@@ -1,12 +1,15 @@
 package avalanche
 
 import (
+	"crypto/md5"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/btcsuite/btcutil/bech32"
+	"github.com/gofrs/uuid"
 )
 
 var (
@@ -21,17 +24,28 @@ func init() {
 	AvalancheChainId = crypto.NewHash([]byte(AvalancheChainBase))
 }
 
+// VerifyAssetKey accepts the AVAX asset id of the X-Chain, or the ERC-20
+// contract address of the C-Chain, and the zero address for the native AVAX
+// of the C-Chain, which is the same asset as the X-Chain AVAX.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == AvalancheAssetKey {
 		return nil
 	}
+	if strings.HasPrefix(assetKey, "0x") {
+		return verifyContractAssetKey(assetKey)
+	}
 	return fmt.Errorf("invalid avalanche asset key %s", assetKey)
 }
 
+// VerifyAddress accepts the X-Chain bech32 addresses and the C-Chain EVM
+// addresses with the EIP-55 checksum.
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid avalanche address %s", address)
 	}
+	if strings.HasPrefix(address, "0x") {
+		return verifyEVMAddress(address)
+	}
 	chainId, hrp, addr, err := parseAddress(address)
 	if err != nil {
 		return err
@@ -56,6 +70,9 @@ func VerifyAddress(address string) error {
 }
 
 func VerifyTransactionHash(hash string) error {
+	if strings.HasPrefix(hash, "0x") {
+		return verifyEVMTransactionHash(hash)
+	}
 	decodedBytes := base58.Decode(hash)
 	if len(decodedBytes) != 36 {
 		return fmt.Errorf("invalid avalanche transaction hash %s", hash)
@@ -64,12 +81,23 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case AvalancheAssetKey:
-		return AvalancheChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == AvalancheAssetKey || assetKey == NativeContractAssetKey {
+		return AvalancheChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, AvalancheChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
 }
 
 const (
@@ -14,22 +14,40 @@ func TestValidation(t *testing.T) {
 	assetKey := "FvwEAhmxKfeiG8SnEvq42hc6whRyY3EFYAvebMqDNDGCgxN5Z"
 	tx := "Sv3wdQnUfh7A9zGzppHxn7ehjzkFR79MMnQdx2CUWdRc3eSNN"
 	addrMain := "X-avax1emj30lmw3mcdgnmzl2plrmmvahln9mnmfzw2d5"
+	usdc := "0xb97ef9ef8734c71904d8002f8b6bc66dd9c48a6e"
+	evmTx := "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"
+	evmAddr := "0xA974c709cFb4566686553a20790685A47acEAA33"
 
 	assert.Nil(VerifyAssetKey(assetKey))
 	assert.NotNil(VerifyAssetKey(tx))
 	assert.NotNil(VerifyAssetKey(addrMain))
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(assetKey)))
+	assert.Nil(VerifyAssetKey(usdc))
+	assert.Nil(VerifyAssetKey(NativeContractAssetKey))
+	assert.NotNil(VerifyAssetKey(evmAddr))
+	assert.NotNil(VerifyAssetKey(usdc[:40]))
+	assert.NotNil(VerifyAssetKey(usdc[2:]))
 
 	assert.Nil(VerifyAddress(addrMain))
 	assert.NotNil(VerifyAddress(assetKey))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.Nil(VerifyAddress(evmAddr))
+	assert.NotNil(VerifyAddress(strings.ToLower(evmAddr)))
+	assert.NotNil(VerifyAddress(evmAddr[:41]))
+	assert.NotNil(VerifyAddress("C-avax1emj30lmw3mcdgnmzl2plrmmvahln9mnmfzw2d5"))
 
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(addrMain))
 	assert.NotNil(VerifyTransactionHash("0x" + tx))
+	assert.Nil(VerifyTransactionHash(evmTx))
+	assert.NotNil(VerifyTransactionHash(strings.ToUpper(evmTx)))
+	assert.NotNil(VerifyTransactionHash(evmTx[:64]))
 
 	assert.Equal(crypto.NewHash([]byte("cbc77539-0a20-4666-8c8a-4ded62b36f0a")), GenerateAssetId(assetKey))
 	assert.Equal(crypto.NewHash([]byte("cbc77539-0a20-4666-8c8a-4ded62b36f0a")), AvalancheChainId)
 	assert.Equal(crypto.NewHash([]byte(AvalancheChainBase)), AvalancheChainId)
+	assert.Equal(AvalancheChainId, GenerateAssetId(NativeContractAssetKey))
+	assert.Equal(crypto.NewHash([]byte("e332c8c1-a4e2-3db9-90f9-850118163afa")), GenerateAssetId(usdc))
+	assert.Panics(func() { GenerateAssetId(evmAddr) })
 }
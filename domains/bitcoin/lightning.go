@@ -0,0 +1,215 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+const (
+	lightningInvoicePrefix  = "lnbc"
+	lightningOfferPrefix    = "lno"
+	lightningRequestPrefix  = "lnr"
+	lightningBolt12Prefix   = "lni"
+	lightningInvoiceMaximum = 7089
+
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	bolt11TimestampWords = 7
+	bolt11SignatureWords = 104
+	bolt11HashWords      = 52
+	bolt11PubKeyWords    = 53
+
+	bolt11FieldPaymentHash     = 1
+	bolt11FieldDescription     = 13
+	bolt11FieldPayee           = 19
+	bolt11FieldDescriptionHash = 23
+
+	bolt12SignatureType = 240
+)
+
+var (
+	bolt11AmountMatcher = regexp.MustCompile(`^([1-9][0-9]*)([munp]?)$`)
+	bolt12Continuation  = regexp.MustCompile(`\+\s*`)
+)
+
+// VerifyLightningInvoice checks the format of a mainnet Lightning payment
+// request carried in the withdrawal tag to a Lightning gateway, either a
+// BOLT11 invoice, or a BOLT12 offer, invoice request or invoice. It's only
+// a sanity check, the expiry and the route are up to the gateway.
+func VerifyLightningInvoice(invoice string) error {
+	if strings.TrimSpace(invoice) != invoice || len(invoice) > lightningInvoiceMaximum {
+		return fmt.Errorf("invalid lightning invoice %s", invoice)
+	}
+	lower := strings.ToLower(invoice)
+	switch {
+	case strings.HasPrefix(lower, lightningInvoicePrefix):
+		return verifyBolt11Invoice(invoice)
+	case strings.HasPrefix(lower, lightningOfferPrefix+"1"),
+		strings.HasPrefix(lower, lightningRequestPrefix+"1"),
+		strings.HasPrefix(lower, lightningBolt12Prefix+"1"):
+		return verifyBolt12Invoice(invoice)
+	}
+	return fmt.Errorf("invalid lightning invoice prefix %s", invoice)
+}
+
+// verifyBolt11Invoice decodes the invoice, checks the amount, the required
+// tagged fields, and recovers the signer from the signature, which must be
+// the payee if the invoice has one.
+func verifyBolt11Invoice(invoice string) error {
+	hrp, data, err := bech32.DecodeNoLimit(invoice)
+	if err != nil {
+		return fmt.Errorf("invalid lightning invoice %s %s", invoice, err.Error())
+	}
+	amount := strings.TrimPrefix(hrp, lightningInvoicePrefix)
+	if amount != "" {
+		m := bolt11AmountMatcher.FindStringSubmatch(amount)
+		if m == nil || (m[2] == "p" && !strings.HasSuffix(m[1], "0")) {
+			return fmt.Errorf("invalid lightning invoice amount %s", hrp)
+		}
+	}
+	if len(data) < bolt11TimestampWords+bolt11SignatureWords {
+		return fmt.Errorf("invalid lightning invoice length %d", len(data))
+	}
+
+	signed, words := data[:len(data)-bolt11SignatureWords], data[len(data)-bolt11SignatureWords:]
+	var payee []byte
+	var paymentHash, description bool
+	for fields := signed[bolt11TimestampWords:]; len(fields) > 0; {
+		if len(fields) < 3 {
+			return fmt.Errorf("invalid lightning invoice field %v", fields)
+		}
+		typ, size := fields[0], int(fields[1])<<5|int(fields[2])
+		if len(fields) < 3+size {
+			return fmt.Errorf("invalid lightning invoice field %d %d", typ, size)
+		}
+		switch value := fields[3 : 3+size]; typ {
+		case bolt11FieldPaymentHash:
+			paymentHash = paymentHash || size == bolt11HashWords
+		case bolt11FieldDescription:
+			description = true
+		case bolt11FieldDescriptionHash:
+			description = description || size == bolt11HashWords
+		case bolt11FieldPayee:
+			if size != bolt11PubKeyWords {
+				return fmt.Errorf("invalid lightning invoice payee %d", size)
+			}
+			payee, _ = bech32.ConvertBits(value, 5, 8, false)
+		}
+		fields = fields[3+size:]
+	}
+	if !paymentHash || !description {
+		return fmt.Errorf("invalid lightning invoice fields %t %t", paymentHash, description)
+	}
+
+	sig, err := bech32.ConvertBits(words, 5, 8, false)
+	if err != nil || sig[64] > 3 {
+		return fmt.Errorf("invalid lightning invoice signature %v", sig)
+	}
+	msg, err := bech32.ConvertBits(signed, 5, 8, true)
+	if err != nil {
+		return fmt.Errorf("invalid lightning invoice data %s", err.Error())
+	}
+	hash := sha256.Sum256(append([]byte(hrp), msg...))
+	compact := append([]byte{27 + 4 + sig[64]}, sig[:64]...)
+	pub, _, err := btcec.RecoverCompact(btcec.S256(), compact, hash[:])
+	if err != nil {
+		return fmt.Errorf("invalid lightning invoice signature %s", err.Error())
+	}
+	if payee != nil && !bytes.Equal(payee, pub.SerializeCompressed()) {
+		return fmt.Errorf("invalid lightning invoice payee %x %x", payee, pub.SerializeCompressed())
+	}
+	return nil
+}
+
+// verifyBolt12Invoice decodes the bech32 string without checksum, which may
+// be split by '+' and whitespaces, and checks the TLV stream is minimally
+// encoded in strictly ascending types. The invoices and invoice requests
+// must have a signature, but it's not verified here.
+func verifyBolt12Invoice(invoice string) error {
+	parts := bolt12Continuation.Split(invoice, -1)
+	for _, p := range parts {
+		if p == "" {
+			return fmt.Errorf("invalid lightning offer continuation %s", invoice)
+		}
+	}
+	joined := strings.Join(parts, "")
+	if strings.ToLower(joined) != joined && strings.ToUpper(joined) != joined {
+		return fmt.Errorf("invalid lightning offer case %s", invoice)
+	}
+	joined = strings.ToLower(joined)
+	hrp, chars := joined[:3], joined[4:]
+
+	words := make([]byte, len(chars))
+	for i, c := range []byte(chars) {
+		v := strings.IndexByte(bech32Charset, c)
+		if v < 0 {
+			return fmt.Errorf("invalid lightning offer character %c", c)
+		}
+		words[i] = byte(v)
+	}
+	stream, err := bech32.ConvertBits(words, 5, 8, false)
+	if err != nil || len(stream) == 0 {
+		return fmt.Errorf("invalid lightning offer data %s", invoice)
+	}
+
+	var signature bool
+	var last uint64
+	for r, first := bytes.NewReader(stream), true; r.Len() > 0; first = false {
+		typ, err := readBigSize(r)
+		if err != nil {
+			return fmt.Errorf("invalid lightning offer type %s", err.Error())
+		}
+		if !first && typ <= last {
+			return fmt.Errorf("invalid lightning offer type order %d %d", last, typ)
+		}
+		size, err := readBigSize(r)
+		if err != nil || size > uint64(r.Len()) {
+			return fmt.Errorf("invalid lightning offer length %d %d", typ, size)
+		}
+		r.Seek(int64(size), io.SeekCurrent)
+		signature = signature || (typ == bolt12SignatureType && size == 64)
+		last = typ
+	}
+	if hrp != lightningOfferPrefix && !signature {
+		return fmt.Errorf("invalid lightning offer signature %s", hrp)
+	}
+	return nil
+}
+
+// readBigSize reads the BOLT bigsize integer, which must be minimally encoded.
+func readBigSize(r *bytes.Reader) (uint64, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var size int
+	var minimum uint64
+	switch b {
+	case 0xfd:
+		size, minimum = 2, 0xfd
+	case 0xfe:
+		size, minimum = 4, 0x10000
+	case 0xff:
+		size, minimum = 8, 0x100000000
+	default:
+		return uint64(b), nil
+	}
+	buf := make([]byte, 8)
+	_, err = io.ReadFull(r, buf[8-size:])
+	if err != nil {
+		return 0, err
+	}
+	n := binary.BigEndian.Uint64(buf)
+	if n < minimum {
+		return 0, fmt.Errorf("non-minimal bigsize %d", n)
+	}
+	return n, nil
+}
@@ -0,0 +1,111 @@
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLightningBolt11(t *testing.T) {
+	assert := assert.New(t)
+
+	donation := "lnbc1pvjluezpp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqdpl2pkx2ctnv5sxxmmwwd5kgetjypeh2ursdae8g6twvus8g6rfwvs8qun0dfjkxaq8rkx3yf5tcsyz3d73gafnh3cax9rn449d9p5uxz9ezhhypd0elx87sjle52x86fux2ypatgddc6k63n7erqz25le42c4u4ecky03ylcqca784w"
+	assert.Nil(VerifyLightningInvoice(donation))
+
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), sha256Bytes("lightning"))
+	other, _ := btcec.PrivKeyFromBytes(btcec.S256(), sha256Bytes("other"))
+	payee := priv.PubKey().SerializeCompressed()
+	hash := sha256Bytes("payment")
+
+	invoice := testBolt11Invoice(assert, priv, "lnbc2500u", bolt11Field(1, hash), bolt11Field(13, []byte("coffee")))
+	assert.Nil(VerifyLightningInvoice(invoice))
+	assert.Nil(VerifyLightningInvoice(strings.ToUpper(invoice)))
+	assert.NotNil(VerifyLightningInvoice(" " + invoice))
+	assert.NotNil(VerifyLightningInvoice(invoice[:len(invoice)-1] + "q"))
+
+	invoice = testBolt11Invoice(assert, priv, "lnbc", bolt11Field(1, hash), bolt11Field(23, hash), bolt11Field(19, payee))
+	assert.Nil(VerifyLightningInvoice(invoice))
+	invoice = testBolt11Invoice(assert, other, "lnbc", bolt11Field(1, hash), bolt11Field(23, hash), bolt11Field(19, payee))
+	assert.NotNil(VerifyLightningInvoice(invoice))
+	invoice = testBolt11Invoice(assert, priv, "lnbc10p", bolt11Field(1, hash), bolt11Field(13, []byte("coffee")))
+	assert.Nil(VerifyLightningInvoice(invoice))
+
+	for _, hrp := range []string{"lnbc25p", "lnbc025m", "lnbc1x", "lntb2500u", "lnbcrt2500u"} {
+		invoice = testBolt11Invoice(assert, priv, hrp, bolt11Field(1, hash), bolt11Field(13, []byte("coffee")))
+		assert.NotNil(VerifyLightningInvoice(invoice), hrp)
+	}
+	invoice = testBolt11Invoice(assert, priv, "lnbc", bolt11Field(13, []byte("coffee")))
+	assert.NotNil(VerifyLightningInvoice(invoice))
+	invoice = testBolt11Invoice(assert, priv, "lnbc", bolt11Field(1, hash[:31]), bolt11Field(13, []byte("coffee")))
+	assert.NotNil(VerifyLightningInvoice(invoice))
+	invoice = testBolt11Invoice(assert, priv, "lnbc", bolt11Field(1, hash))
+	assert.NotNil(VerifyLightningInvoice(invoice))
+	invoice = testBolt11Invoice(assert, priv, "lnbc", bolt11Field(1, hash), bolt11Field(23, hash), bolt11Field(19, payee[1:]))
+	assert.NotNil(VerifyLightningInvoice(invoice))
+}
+
+func TestLightningBolt12(t *testing.T) {
+	assert := assert.New(t)
+
+	offer := testBolt12(assert, "lno", []byte{0x0a, 0x03, 'a', 'b', 'c', 0x16, 0x02, 0x01, 0x02})
+	assert.Nil(VerifyLightningInvoice(offer))
+	assert.Nil(VerifyLightningInvoice(strings.ToUpper(offer)))
+	assert.Nil(VerifyLightningInvoice(offer[:10] + "+\n  " + offer[10:]))
+	assert.NotNil(VerifyLightningInvoice(offer[:10] + "++" + offer[10:]))
+	assert.NotNil(VerifyLightningInvoice(offer + "+"))
+	assert.NotNil(VerifyLightningInvoice(offer[:10] + strings.ToUpper(offer[10:])))
+	assert.NotNil(VerifyLightningInvoice(offer + "b"))
+
+	assert.NotNil(VerifyLightningInvoice(testBolt12(assert, "lno", []byte{0x16, 0x00, 0x0a, 0x00})))
+	assert.NotNil(VerifyLightningInvoice(testBolt12(assert, "lno", []byte{0x0a, 0x04, 'a', 'b', 'c'})))
+	assert.NotNil(VerifyLightningInvoice(testBolt12(assert, "lno", []byte{0xfd, 0x00, 0x0a, 0x00})))
+
+	signed := append([]byte{0x0a, 0x00, 0xf0, 0x40}, make([]byte, 64)...)
+	assert.Nil(VerifyLightningInvoice(testBolt12(assert, "lni", signed)))
+	assert.Nil(VerifyLightningInvoice(testBolt12(assert, "lnr", signed)))
+	assert.NotNil(VerifyLightningInvoice(testBolt12(assert, "lni", []byte{0x0a, 0x00})))
+	assert.NotNil(VerifyLightningInvoice(testBolt12(assert, "lnx", signed)))
+}
+
+func sha256Bytes(s string) []byte {
+	h := sha256.Sum256([]byte(s))
+	return h[:]
+}
+
+func bolt11Field(typ byte, value []byte) []byte {
+	words, _ := bech32.ConvertBits(value, 8, 5, true)
+	return append([]byte{typ, byte(len(words) >> 5), byte(len(words) & 31)}, words...)
+}
+
+func testBolt11Invoice(assert *assert.Assertions, priv *btcec.PrivateKey, hrp string, fields ...[]byte) string {
+	data := []byte{0, 1, 2, 3, 4, 5, 6}
+	for _, f := range fields {
+		data = append(data, f...)
+	}
+	msg, err := bech32.ConvertBits(data, 5, 8, true)
+	assert.Nil(err)
+	hash := sha256.Sum256(append([]byte(hrp), msg...))
+	compact, err := btcec.SignCompact(btcec.S256(), priv, hash[:], true)
+	assert.Nil(err)
+	sig := append(compact[1:], compact[0]-27-4)
+	words, err := bech32.ConvertBits(sig, 8, 5, true)
+	assert.Nil(err)
+	invoice, err := bech32.Encode(hrp, append(data, words...))
+	assert.Nil(err)
+	return invoice
+}
+
+func testBolt12(assert *assert.Assertions, hrp string, stream []byte) string {
+	words, err := bech32.ConvertBits(stream, 8, 5, true)
+	assert.Nil(err)
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for _, w := range words {
+		b.WriteByte(bech32Charset[w])
+	}
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	depositProofMaximumBranch  = 32
+	depositProofMaximumHeaders = 512
+	depositProofConfirmations  = 6
+)
+
+// the headers of a deposit proof must have a target not easier than this,
+// which is a difficulty above 4 billion
+var depositProofMaximumBits uint32 = 0x1900ffff
+
+// the headers of a deposit proof must extend one of these trusted blocks,
+// they are the btcd main net checkpoints, and more recent ones should be
+// added by the releases to keep the proofs within the headers limit
+var depositProofCheckpoints = make(map[chainhash.Hash]bool)
+
+func init() {
+	for _, c := range chaincfg.MainNetParams.Checkpoints {
+		depositProofCheckpoints[*c.Hash] = true
+	}
+}
+
+// VerifyDepositProof verifies the SPV proof that a transaction is included
+// in a block of a header chain extending a trusted checkpoint, with at least
+// depositProofConfirmations blocks since the transaction block, and returns
+// the value of the output in satoshi. So forging a proof costs the work of
+// mining all these blocks after a checkpoint, each at least at the maximum
+// target. The proof is the little endian uint16 headers count and the 80
+// bytes block headers, the little endian uint16 index of the transaction
+// block in the headers, the little endian uint32 transaction index in the
+// block, the merkle branch hashes count byte and the hashes, then the raw
+// transaction.
+func VerifyDepositProof(hash string, index uint64, proof []byte) (int64, error) {
+	r := bytes.NewReader(proof)
+	var count uint16
+	err := binary.Read(r, binary.LittleEndian, &count)
+	if err != nil || count < 1 || count > depositProofMaximumHeaders {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof headers %d", count)
+	}
+	headers := make([]wire.BlockHeader, count)
+	for i := range headers {
+		err = headers[i].Deserialize(r)
+		if err != nil {
+			return 0, fmt.Errorf("invalid bitcoin deposit proof header %s", err.Error())
+		}
+	}
+	err = verifyDepositProofHeaders(headers)
+	if err != nil {
+		return 0, err
+	}
+	var block uint16
+	err = binary.Read(r, binary.LittleEndian, &block)
+	if err != nil || block >= count {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof block %d %d", block, count)
+	}
+	if int(count-block) < depositProofConfirmations {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof confirmations %d", count-block)
+	}
+	header := headers[block]
+	var position uint32
+	err = binary.Read(r, binary.LittleEndian, &position)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof position %s", err.Error())
+	}
+	depth, err := r.ReadByte()
+	if err != nil || depth > depositProofMaximumBranch {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof branch %d", depth)
+	}
+	branch := make([]chainhash.Hash, depth)
+	for i := range branch {
+		_, err = io.ReadFull(r, branch[i][:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid bitcoin deposit proof branch %s", err.Error())
+		}
+	}
+	var tx wire.MsgTx
+	err = tx.Deserialize(r)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof transaction %s", err.Error())
+	}
+	if r.Len() != 0 {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof size %d", len(proof))
+	}
+	// a 64 bytes transaction could be an inner merkle node
+	if tx.SerializeSize() == 64 {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof transaction size %d", tx.SerializeSize())
+	}
+	if id := tx.TxHash(); id.String() != hash {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof transaction %s %s", hash, id)
+	}
+	if index >= uint64(len(tx.TxOut)) {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof output %s %d", hash, index)
+	}
+
+	root := tx.TxHash()
+	for _, h := range branch {
+		if position&1 == 0 {
+			root = chainhash.DoubleHashH(append(root[:], h[:]...))
+		} else {
+			root = chainhash.DoubleHashH(append(h[:], root[:]...))
+		}
+		position = position >> 1
+	}
+	if position != 0 || root != header.MerkleRoot {
+		return 0, fmt.Errorf("invalid bitcoin deposit proof merkle root %s %s", root, header.MerkleRoot)
+	}
+	return tx.TxOut[index].Value, nil
+}
+
+// verifyDepositProofHeaders checks that the first header extends a trusted
+// checkpoint, each of the others extends its previous one, and all of them
+// have enough work.
+func verifyDepositProofHeaders(headers []wire.BlockHeader) error {
+	if !depositProofCheckpoints[headers[0].PrevBlock] {
+		return fmt.Errorf("invalid bitcoin deposit proof checkpoint %s", headers[0].PrevBlock)
+	}
+	maximum := compactToBig(depositProofMaximumBits)
+	for i, header := range headers {
+		block := header.BlockHash()
+		if i > 0 && header.PrevBlock != headers[i-1].BlockHash() {
+			return fmt.Errorf("invalid bitcoin deposit proof chain %s %s", block, header.PrevBlock)
+		}
+		target := compactToBig(header.Bits)
+		if target.Sign() <= 0 || target.Cmp(maximum) > 0 {
+			return fmt.Errorf("invalid bitcoin deposit proof target %x", header.Bits)
+		}
+		if hashToBig(block).Cmp(target) > 0 {
+			return fmt.Errorf("invalid bitcoin deposit proof work %s", block)
+		}
+	}
+	return nil
+}
+
+func compactToBig(compact uint32) *big.Int {
+	mantissa := int64(compact & 0x007fffff)
+	exponent := uint(compact >> 24)
+	if compact&0x00800000 != 0 {
+		return big.NewInt(0)
+	}
+	if exponent <= 3 {
+		return big.NewInt(mantissa >> (8 * (3 - exponent)))
+	}
+	n := big.NewInt(mantissa)
+	return n.Lsh(n, 8*(exponent-3))
+}
+
+func hashToBig(hash chainhash.Hash) *big.Int {
+	buf := make([]byte, len(hash))
+	for i := range hash {
+		buf[len(hash)-1-i] = hash[i]
+	}
+	return new(big.Int).SetBytes(buf)
+}
@@ -1,10 +1,16 @@
 package bitcoin
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,3 +54,106 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("c6d0c728-2624-429b-8e0d-d9d19b6592fa")), BitcoinChainId)
 	assert.Equal(crypto.NewHash([]byte("815b0b1a-2764-3736-8faa-42d694fa620a")), BitcoinOmniUSDTId)
 }
+
+func TestDepositProof(t *testing.T) {
+	assert := assert.New(t)
+
+	coinbase := "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	genesis := "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+	hash := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	proof, _ := hex.DecodeString("0100" + genesis + "0000" + "00000000" + "00" + coinbase)
+
+	_, err := VerifyDepositProof(hash, 0, proof)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof checkpoint")
+	depositProofMaximumBits = 0x1d00ffff
+	depositProofCheckpoints[chainhash.Hash{}] = true
+	_, err = VerifyDepositProof(hash, 0, proof)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof confirmations 1")
+	delete(depositProofCheckpoints, chainhash.Hash{})
+
+	spend, _ := hex.DecodeString("01000000013ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a0000000000ffffffff02a0860100000000000151606b042a01000000015100000000")
+	hash = "8d4e77c394946fe6f8209225d896579a1399e52853f26dc2436c72a988848565"
+	branch, _ := chainhash.NewHashFromStr("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b")
+	id, _ := chainhash.NewHashFromStr(hash)
+	root := chainhash.DoubleHashH(append(branch[:], id[:]...))
+	checkpoint := chainhash.DoubleHashH([]byte("checkpoint"))
+	headers := mineDepositProofHeaders(checkpoint, root, depositProofConfirmations)
+
+	depositProofMaximumBits = 0x207fffff
+	proof = encodeDepositProof(headers, 0, 1, branch, spend)
+	_, err = VerifyDepositProof(hash, 0, proof)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof checkpoint")
+	depositProofCheckpoints[checkpoint] = true
+	value, err := VerifyDepositProof(hash, 0, proof)
+	assert.Nil(err)
+	assert.Equal(int64(100000), value)
+	value, err = VerifyDepositProof(hash, 1, proof)
+	assert.Nil(err)
+	assert.Equal(int64(4999900000), value)
+	_, err = VerifyDepositProof(hash, 2, proof)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof output")
+	_, err = VerifyDepositProof(strings.Repeat("0", 64), 0, proof)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof transaction")
+	_, err = VerifyDepositProof(hash, 0, proof[:len(proof)-1])
+	assert.NotNil(err)
+	_, err = VerifyDepositProof(hash, 0, append(proof, 0))
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof size")
+
+	_, err = VerifyDepositProof(hash, 0, encodeDepositProof(headers, 0, 0, branch, spend))
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof merkle root")
+	_, err = VerifyDepositProof(hash, 0, encodeDepositProof(headers, 0, 3, branch, spend))
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof merkle root")
+	_, err = VerifyDepositProof(hash, 0, encodeDepositProof(headers[:len(headers)-1], 0, 1, branch, spend))
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof confirmations")
+	_, err = VerifyDepositProof(hash, 0, encodeDepositProof(headers, uint16(len(headers)), 1, branch, spend))
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof block")
+
+	forged := append([]wire.BlockHeader{}, headers...)
+	forged[3].PrevBlock = checkpoint
+	_, err = VerifyDepositProof(hash, 0, encodeDepositProof(forged, 0, 1, branch, spend))
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof chain")
+	forged = mineDepositProofHeaders(checkpoint, root, depositProofConfirmations)
+	forged[2].Nonce++
+	for hashToBig(forged[2].BlockHash()).Cmp(compactToBig(forged[2].Bits)) <= 0 {
+		forged[2].Nonce++
+	}
+	forged = append(forged[:3], mineDepositProofHeaders(forged[2].BlockHash(), root, 3)...)
+	_, err = VerifyDepositProof(hash, 0, encodeDepositProof(forged, 0, 1, branch, spend))
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof work")
+
+	depositProofMaximumBits = 0x1900ffff
+	_, err = VerifyDepositProof(hash, 0, proof)
+	assert.Contains(err.Error(), "invalid bitcoin deposit proof target")
+	delete(depositProofCheckpoints, checkpoint)
+}
+
+func mineDepositProofHeaders(prev, root chainhash.Hash, count int) []wire.BlockHeader {
+	headers := make([]wire.BlockHeader, count)
+	for i := range headers {
+		h := &headers[i]
+		h.Version = 1
+		h.PrevBlock = prev
+		h.MerkleRoot = root
+		h.Timestamp = time.Unix(1296688602+int64(i)*600, 0)
+		h.Bits = 0x207fffff
+		for hashToBig(h.BlockHash()).Cmp(compactToBig(h.Bits)) > 0 {
+			h.Nonce++
+		}
+		prev = h.BlockHash()
+	}
+	return headers
+}
+
+func encodeDepositProof(headers []wire.BlockHeader, block uint16, position uint32, branch *chainhash.Hash, tx []byte) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, uint16(len(headers)))
+	for _, h := range headers {
+		h.Serialize(buf)
+	}
+	binary.Write(buf, binary.LittleEndian, block)
+	binary.Write(buf, binary.LittleEndian, position)
+	buf.WriteByte(1)
+	buf.Write(branch[:])
+	buf.Write(tx)
+	return buf.Bytes()
+}
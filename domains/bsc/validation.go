@@ -0,0 +1,50 @@
+package bsc
+
+import (
+	"encoding/hex"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/evm"
+)
+
+var (
+	BSCChainBase string
+	BSCChainId   crypto.Hash
+
+	chain *evm.Chain
+)
+
+// The BEP-20 asset ids are derived from the BSC chain base, so a token of the
+// same contract address on ethereum is never the same asset, and the zero
+// address is the native BNB, whose asset id is the chain id. The chain id,
+// asset keys and confirmations are of its entry in the evm chain table, which
+// the common package validates by evm.Lookup.
+func init() {
+	chain = evm.LookupNumber(56)
+	BSCChainBase = chain.ChainBase
+	BSCChainId = chain.ChainId
+}
+
+func VerifyAssetKey(assetKey string) error {
+	return chain.VerifyAssetKey(assetKey)
+}
+
+func VerifyAddress(address string) error {
+	return chain.VerifyAddress(address)
+}
+
+func VerifyTransactionHash(hash string) error {
+	return chain.VerifyTransactionHash(hash)
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	return chain.GenerateAssetId(assetKey)
+}
+
+func formatAddress(to string) (string, error) {
+	a, err := hex.DecodeString(to[2:])
+	if err != nil {
+		return "", err
+	}
+	return evm.ChecksumAddress(a), nil
+}
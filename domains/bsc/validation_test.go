@@ -0,0 +1,48 @@
+package bsc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	usdt := "0x55d398326f99059ff775485246999027b3197955"
+	usdc := "0x8ac76a51cc950d9822d68b83fe1ad97b32cd580d"
+	tx := "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"
+
+	usdtFormat, _ := formatAddress(usdt)
+	usdcFormat, _ := formatAddress(usdc)
+	assert.Equal("0x55d398326f99059fF775485246999027B3197955", usdtFormat)
+	assert.Equal("0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d", usdcFormat)
+
+	assert.Nil(VerifyAssetKey(usdt))
+	assert.Nil(VerifyAssetKey(usdc))
+	assert.NotNil(VerifyAssetKey(usdtFormat))
+	assert.NotNil(VerifyAssetKey(usdcFormat))
+	assert.NotNil(VerifyAssetKey(usdt[2:]))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(usdc)))
+
+	assert.Nil(VerifyAddress(usdtFormat))
+	assert.Nil(VerifyAddress(usdcFormat))
+	assert.NotNil(VerifyAddress(usdt))
+	assert.NotNil(VerifyAddress(usdc))
+	assert.NotNil(VerifyAddress(usdtFormat[2:]))
+	assert.NotNil(VerifyAddress(strings.ToUpper(usdc)))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash(usdt))
+	assert.NotNil(VerifyTransactionHash(tx[2:]))
+	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
+
+	assert.Equal(crypto.NewHash([]byte("94213408-4ee7-3150-a9c4-9c5cce421c78")), GenerateAssetId(usdt))
+	assert.Equal(crypto.NewHash([]byte("3d3d69f1-6742-34cf-95fe-3f8964e6d307")), GenerateAssetId(usdc))
+	assert.NotEqual(ethereum.GenerateAssetId(usdt), GenerateAssetId(usdt))
+	assert.Equal(crypto.NewHash([]byte("1949e683-6a08-49e2-b087-d6b72398588f")), GenerateAssetId("0x0000000000000000000000000000000000000000"))
+	assert.Equal(crypto.NewHash([]byte("1949e683-6a08-49e2-b087-d6b72398588f")), BSCChainId)
+}
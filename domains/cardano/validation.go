@@ -0,0 +1,132 @@
+package cardano
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/gofrs/uuid"
+)
+
+var (
+	CardanoChainBase string
+	CardanoChainId   crypto.Hash
+)
+
+const (
+	PrefixPayment = "addr"
+	PrefixStake   = "stake"
+
+	policyIdSize     = 28
+	assetNameMaxSize = 32
+	networkMainnet   = 1
+)
+
+func init() {
+	CardanoChainBase = "dab7c59f-c4cc-4515-9034-c6b5c04bf0ae"
+	CardanoChainId = crypto.NewHash([]byte(CardanoChainBase))
+}
+
+// VerifyAssetKey accepts the native asset unit, i.e. the hex policy id
+// followed by the hex asset name, which may be empty.
+func VerifyAssetKey(assetKey string) error {
+	if assetKey == CardanoChainBase {
+		return nil
+	}
+	if strings.ToLower(assetKey) != assetKey {
+		return fmt.Errorf("invalid cardano asset key %s", assetKey)
+	}
+	unit, err := hex.DecodeString(assetKey)
+	if err != nil {
+		return fmt.Errorf("invalid cardano asset key %s %s", assetKey, err.Error())
+	}
+	if len(unit) < policyIdSize || len(unit) > policyIdSize+assetNameMaxSize {
+		return fmt.Errorf("invalid cardano asset key %s", assetKey)
+	}
+	return nil
+}
+
+// VerifyAddress accepts the mainnet Shelley payment addresses, base and
+// enterprise, and the stake addresses. The pointer addresses are rejected.
+func VerifyAddress(address string) error {
+	if strings.TrimSpace(address) != address {
+		return fmt.Errorf("invalid cardano address %s", address)
+	}
+	prefix, data, err := bech32.DecodeNoLimit(address)
+	if err != nil {
+		return fmt.Errorf("invalid cardano address %s %s", address, err.Error())
+	}
+	payload, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return fmt.Errorf("invalid cardano address %s %s", address, err.Error())
+	}
+	if len(payload) == 0 || payload[0]&0x0f != networkMainnet {
+		return fmt.Errorf("invalid cardano address %s", address)
+	}
+	switch kind := payload[0] >> 4; {
+	case kind <= 3 && prefix == PrefixPayment:
+		if len(payload) != 57 {
+			return fmt.Errorf("invalid cardano address %s", address)
+		}
+	case (kind == 6 || kind == 7) && prefix == PrefixPayment:
+		if len(payload) != 29 {
+			return fmt.Errorf("invalid cardano address %s", address)
+		}
+	case (kind == 14 || kind == 15) && prefix == PrefixStake:
+		if len(payload) != 29 {
+			return fmt.Errorf("invalid cardano address %s", address)
+		}
+	default:
+		return fmt.Errorf("invalid cardano address %s", address)
+	}
+
+	encoded, err := bech32.Encode(prefix, data)
+	if err != nil {
+		return fmt.Errorf("invalid cardano address %s %s", address, err.Error())
+	}
+	if encoded != address {
+		return fmt.Errorf("invalid cardano address %s", address)
+	}
+	return nil
+}
+
+func VerifyTransactionHash(hash string) error {
+	if len(hash) != 64 {
+		return fmt.Errorf("invalid cardano transaction hash %s", hash)
+	}
+	if strings.ToLower(hash) != hash {
+		return fmt.Errorf("invalid cardano transaction hash %s", hash)
+	}
+	h, err := hex.DecodeString(hash)
+	if err != nil {
+		return fmt.Errorf("invalid cardano transaction hash %s %s", hash, err.Error())
+	}
+	if len(h) != 32 {
+		return fmt.Errorf("invalid cardano transaction hash %s", hash)
+	}
+	return nil
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
+		panic(assetKey)
+	}
+
+	if assetKey == CardanoChainBase {
+		return CardanoChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, CardanoChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
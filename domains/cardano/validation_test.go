@@ -0,0 +1,57 @@
+package cardano
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	ada := "dab7c59f-c4cc-4515-9034-c6b5c04bf0ae"
+	policy := "f43a62fdc3965df486de8a0d32fe800963589c41b38946602a0dc535"
+	agix := policy + "41474958"
+	tx := "a5d3a5e4d7a4d58e3fd8c3e8d1ba0bb5d4e6b8bc0a8a5a8fd1d6c3c2ed7ab9fe"
+	base := "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"
+	enterprise := "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"
+	script := "addr1w8phkx6acpnf78fuvxn0mkew3l0fd058hzquvz7w36x4gtcyjy7wx"
+	stake := "stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw"
+	testnet := "addr_test1vz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzerspjrlsz"
+
+	assert.Nil(VerifyAssetKey(ada))
+	assert.Nil(VerifyAssetKey(policy))
+	assert.Nil(VerifyAssetKey(agix))
+	assert.Nil(VerifyAssetKey(policy + strings.Repeat("ab", 32)))
+	assert.NotNil(VerifyAssetKey(policy + strings.Repeat("ab", 33)))
+	assert.NotNil(VerifyAssetKey(policy[2:]))
+	assert.NotNil(VerifyAssetKey(agix + "4"))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(agix)))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(ada)))
+	assert.NotNil(VerifyAssetKey(base))
+
+	assert.Nil(VerifyAddress(base))
+	assert.Nil(VerifyAddress(enterprise))
+	assert.Nil(VerifyAddress(script))
+	assert.Nil(VerifyAddress(stake))
+	assert.NotNil(VerifyAddress(testnet))
+	assert.NotNil(VerifyAddress(base[1:]))
+	assert.NotNil(VerifyAddress(base[:len(base)-1]))
+	assert.NotNil(VerifyAddress(strings.ToUpper(base)))
+	assert.NotNil(VerifyAddress(" " + enterprise))
+	assert.NotNil(VerifyAddress("stake" + enterprise[4:]))
+	assert.NotNil(VerifyAddress(ada))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash("0x" + tx))
+	assert.NotNil(VerifyTransactionHash(tx[2:]))
+	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
+	assert.NotNil(VerifyTransactionHash(ada))
+
+	assert.Equal(crypto.NewHash([]byte("dab7c59f-c4cc-4515-9034-c6b5c04bf0ae")), GenerateAssetId(ada))
+	assert.Equal(crypto.NewHash([]byte("873ee54b-b6e2-32be-a54f-cc6e04343336")), GenerateAssetId(agix))
+	assert.Equal(crypto.NewHash([]byte(CardanoChainBase)), CardanoChainId)
+	assert.Panics(func() { GenerateAssetId(base) })
+}
@@ -1,12 +1,15 @@
 package cosmos
 
 import (
+	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/btcsuite/btcutil/bech32"
+	"github.com/gofrs/uuid"
 )
 
 var (
@@ -25,9 +28,35 @@ func VerifyAssetKey(assetKey string) error {
 	if assetKey == CosmosAssetKey {
 		return nil
 	}
+	if VerifyContractAddress("cosmos", assetKey) == nil {
+		return nil
+	}
 	return fmt.Errorf("invalid cosmos asset key %s", assetKey)
 }
 
+// VerifyContractAddress checks a CW20 contract address of the bech32 prefix,
+// the contract addresses are 32 bytes, so never an account address.
+func VerifyContractAddress(prefix, address string) error {
+	hrp, bz, err := decodeAndConvert(address)
+	if err != nil {
+		return fmt.Errorf("invalid %s contract address %s %s", prefix, address, err.Error())
+	}
+	if hrp != prefix {
+		return fmt.Errorf("invalid %s contract address %s", prefix, address)
+	}
+	if len(bz) != 32 {
+		return fmt.Errorf("invalid %s contract address %s", prefix, address)
+	}
+	addr, err := convertAndEncode(prefix, bz)
+	if err != nil {
+		return fmt.Errorf("invalid %s contract address %s %s", prefix, address, err.Error())
+	}
+	if addr != address {
+		return fmt.Errorf("invalid %s contract address %s", prefix, address)
+	}
+	return nil
+}
+
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid cosmos address %s", address)
@@ -69,12 +98,23 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case CosmosAssetKey:
-		return CosmosChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == CosmosAssetKey {
+		return CosmosChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, CosmosChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
 }
 
 func convertAndEncode(hrp string, data []byte) (string, error) {
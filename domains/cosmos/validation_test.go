@@ -34,4 +34,18 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("7397e9f1-4e42-4dc8-8a3b-171daaadd436")), GenerateAssetId(atom))
 	assert.Equal(crypto.NewHash([]byte("7397e9f1-4e42-4dc8-8a3b-171daaadd436")), CosmosChainId)
 	assert.Equal(crypto.NewHash([]byte(CosmosChainBase)), CosmosChainId)
+
+	cw20 := "cosmos1tckpxnyvy0tulzz56yenztghjkx3gqyl28sytat22v5zwr8nffdsvhdpr7"
+	legacy := "cosmos1tckpxnyvy0tulzz56yenztghjkx3gqylm3pk0m"
+	assert.Nil(VerifyAssetKey(cw20))
+	assert.NotNil(VerifyAssetKey(legacy))
+	assert.Nil(VerifyContractAddress("cosmos", cw20))
+	assert.NotNil(VerifyContractAddress("cosmos", addrMain))
+	assert.NotNil(VerifyContractAddress("osmo", cw20))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(cw20)))
+	assert.NotNil(VerifyAssetKey(cw20[:len(cw20)-1]))
+	assert.NotNil(VerifyAssetKey(" " + cw20))
+	assert.Equal(crypto.NewHash([]byte("fd49a799-f44f-3d16-940c-7b67bd1d28bd")), GenerateAssetId(cw20))
+	assert.Equal("340131d3e0f2aff0875be90f38d7012107c1a7cc917ab7541d8aea1f82630f71", GenerateAssetId(cw20).String())
+	assert.NotNil(VerifyAssetKey("gamm/pool/1"))
 }
@@ -0,0 +1,115 @@
+package dfinity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+const (
+	principalMaxLength = 29
+	subaccountLength   = 32
+)
+
+var principalEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ParseAccount decodes the ICRC-1 textual account, which is the principal
+// alone for the default subaccount, otherwise the principal followed by a
+// checksum and the subaccount hex without leading zeros, e.g.
+// k2t6j-...-6ae-dfxgiyy.102030...1f20, only the canonical text is accepted.
+func ParseAccount(text string) ([]byte, [subaccountLength]byte, error) {
+	var subaccount [subaccountLength]byte
+	ptext, stext := text, ""
+	if i := strings.LastIndexByte(text, '.'); i >= 0 {
+		j := strings.LastIndexByte(text[:i], '-')
+		if j < 0 {
+			return nil, subaccount, fmt.Errorf("invalid internet computer account %s", text)
+		}
+		ptext, stext = text[:j], text[i+1:]
+		if len(stext) == 0 || len(stext) > subaccountLength*2 {
+			return nil, subaccount, fmt.Errorf("invalid internet computer subaccount %s", text)
+		}
+		if len(stext)%2 == 1 {
+			stext = "0" + stext
+		}
+		sub, err := hex.DecodeString(stext)
+		if err != nil {
+			return nil, subaccount, fmt.Errorf("invalid internet computer subaccount %s %s", text, err.Error())
+		}
+		copy(subaccount[subaccountLength-len(sub):], sub)
+	}
+
+	principal, err := ParsePrincipal(ptext)
+	if err != nil {
+		return nil, subaccount, err
+	}
+	if EncodeAccount(principal, subaccount) != text {
+		return nil, subaccount, fmt.Errorf("invalid internet computer account %s", text)
+	}
+	return principal, subaccount, nil
+}
+
+func EncodeAccount(principal []byte, subaccount [subaccountLength]byte) string {
+	text := EncodePrincipal(principal)
+	if subaccount == [subaccountLength]byte{} {
+		return text
+	}
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(append(append([]byte{}, principal...), subaccount[:]...)))
+	sub := strings.TrimLeft(hex.EncodeToString(subaccount[:]), "0")
+	return text + "-" + strings.ToLower(principalEncoding.EncodeToString(checksum)) + "." + sub
+}
+
+// AccountIdentifier is the ledger address of the principal and subaccount,
+// which is also accepted by VerifyAddress as the 64 hex characters.
+func AccountIdentifier(principal []byte, subaccount [subaccountLength]byte) string {
+	h := sha256.New224()
+	h.Write([]byte("\x0aaccount-id"))
+	h.Write(principal)
+	h.Write(subaccount[:])
+	hash := h.Sum(nil)
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(hash))
+	return hex.EncodeToString(append(checksum, hash...))
+}
+
+func ParsePrincipal(text string) ([]byte, error) {
+	buf, err := principalEncoding.DecodeString(strings.ToUpper(strings.ReplaceAll(text, "-", "")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid internet computer principal %s %s", text, err.Error())
+	}
+	if len(buf) < 5 || len(buf) > principalMaxLength+4 {
+		return nil, fmt.Errorf("invalid internet computer principal %s", text)
+	}
+	principal := buf[4:]
+	if binary.BigEndian.Uint32(buf[:4]) != crc32.ChecksumIEEE(principal) {
+		return nil, fmt.Errorf("invalid internet computer principal %s", text)
+	}
+	if EncodePrincipal(principal) != text {
+		return nil, fmt.Errorf("invalid internet computer principal %s", text)
+	}
+	return principal, nil
+}
+
+func EncodePrincipal(principal []byte) string {
+	buf := make([]byte, 4, 4+len(principal))
+	binary.BigEndian.PutUint32(buf, crc32.ChecksumIEEE(principal))
+	text := strings.ToLower(principalEncoding.EncodeToString(append(buf, principal...)))
+	var groups bytes.Buffer
+	for i := 0; i < len(text); i += 5 {
+		if i > 0 {
+			groups.WriteByte('-')
+		}
+		end := i + 5
+		if end > len(text) {
+			end = len(text)
+		}
+		groups.WriteString(text[i:end])
+	}
+	return groups.String()
+}
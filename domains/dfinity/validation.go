@@ -34,6 +34,10 @@ func VerifyAddress(addr string) error {
 		return fmt.Errorf("invalid internet computer address %s", addr)
 	}
 
+	if len(addr) != 64 {
+		_, _, err := ParseAccount(addr)
+		return err
+	}
 	buf, err := hex.DecodeString(addr)
 	if err != nil {
 		return err
@@ -1,6 +1,7 @@
 package dfinity
 
 import (
+	"encoding/hex"
 	"strings"
 	"testing"
 
@@ -24,6 +25,31 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAddress(icp))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 
+	principal := "k2t6j-2nvnp-4zjm3-25dtz-6xhaa-c7boj-5gayf-oj3xs-i43lp-teztq-6ae"
+	account := principal + "-dfxgiyy.102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+	assert.Nil(VerifyAddress(principal))
+	assert.Nil(VerifyAddress(account))
+	assert.Nil(VerifyAddress(principal + "-6cc627i.1"))
+	assert.NotNil(VerifyAddress(principal + "-6cc627i.01"))
+	assert.NotNil(VerifyAddress(principal + "-6cc627i.2"))
+	assert.NotNil(VerifyAddress(principal + "-aaaaaaa.0"))
+	assert.NotNil(VerifyAddress(strings.ToUpper(principal)))
+	assert.NotNil(VerifyAddress(principal[:len(principal)-1] + "a"))
+	assert.NotNil(VerifyAddress(strings.ReplaceAll(principal, "-", "")))
+
+	p, sub, err := ParseAccount(account)
+	assert.Nil(err)
+	assert.Equal("b56bf994b37ae8e79f5ce000be1727a6060ae4eef24736b7cc999c3c02", hex.EncodeToString(p))
+	assert.Equal(byte(1), sub[0])
+	assert.Equal(byte(32), sub[31])
+	assert.Equal(account, EncodeAccount(p, sub))
+	assert.Equal("5b9ac1a26d7b26369d8c6739e6560bbae57b4368073a92169dcfa726d7146939", AccountIdentifier(p, sub))
+	assert.Nil(VerifyAddress(AccountIdentifier(p, sub)))
+	p, sub, err = ParseAccount(principal)
+	assert.Nil(err)
+	assert.Equal([32]byte{}, sub)
+	assert.Equal("051b05839339f89053454a4b9865ea0452a4bffe2b1cd41f4982bad10c1e637c", AccountIdentifier(p, sub))
+
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(icp))
 	assert.NotNil(VerifyTransactionHash("0x" + tx))
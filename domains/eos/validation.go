@@ -7,6 +7,7 @@ import (
 	"io"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/gofrs/uuid"
@@ -22,6 +23,23 @@ func init() {
 	EOSChainId = crypto.NewHash([]byte(EOSChainBase))
 }
 
+const (
+	// MemoSizeLimit is the max bytes of the transfer memo, which is the only
+	// way to identify the deposits to a shared account.
+	MemoSizeLimit = 256
+
+	accountNameSizeLimit = 12
+	symbolSizeLimit      = 7
+)
+
+var (
+	accountNamePattern = regexp.MustCompile("^[a-z1-5.]{1,12}$")
+	symbolPattern      = regexp.MustCompile("^[A-Z]{1,7}$")
+)
+
+// VerifyAssetKey checks the token contract account and the symbol, e.g.
+// eosio.token:EOS or tethertether:USDT. The stricter rules of the domain
+// fork are checked by VerifyAccountName and VerifySymbol.
 func VerifyAssetKey(assetKey string) error {
 	parts := strings.Split(assetKey, ":")
 	if len(parts) != 2 {
@@ -66,6 +84,47 @@ func VerifyAddress(address string) error {
 	return nil
 }
 
+// VerifyAccountName checks the account name, at most 12 characters of a-z,
+// 1-5 and at most one dot, which can't be the first or last character.
+func VerifyAccountName(name string) error {
+	if len(name) > accountNameSizeLimit || !accountNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid eos account name %s", name)
+	}
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return fmt.Errorf("invalid eos account name %s", name)
+	}
+	if strings.Count(name, ".") > 1 {
+		return fmt.Errorf("invalid eos account name %s", name)
+	}
+	return nil
+}
+
+// VerifySymbol checks the token symbol of the asset key, at most 7 upper
+// case letters.
+func VerifySymbol(assetKey string) error {
+	parts := strings.Split(assetKey, ":")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid eos asset key %s", assetKey)
+	}
+	if len(parts[1]) > symbolSizeLimit || !symbolPattern.MatchString(parts[1]) {
+		return fmt.Errorf("invalid eos asset symbol %s", assetKey)
+	}
+	return nil
+}
+
+// VerifyMemo checks the memo of the transfer, the deposits and withdrawals
+// to the exchanges are all on the shared accounts, so the memo is required
+// to be kept as is.
+func VerifyMemo(memo string) error {
+	if len(memo) > MemoSizeLimit {
+		return fmt.Errorf("invalid eos memo size %d", len(memo))
+	}
+	if !utf8.ValidString(memo) {
+		return fmt.Errorf("invalid eos memo %x", memo)
+	}
+	return nil
+}
+
 func VerifyTransactionHash(hash string) error {
 	if len(hash) != 64 {
 		return fmt.Errorf("invalid eos transaction hash %s", hash)
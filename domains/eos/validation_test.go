@@ -23,6 +23,16 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAssetKey("eosio:EOSABCDEFG"))
 	assert.NotNil(VerifyAssetKey("eosio.token:EOS:2"))
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(eos)))
+	assert.Nil(VerifyAssetKey("eosio.token:eos"))
+	assert.Nil(VerifyAssetKey("eosio.token:EOSABCDE"))
+
+	assert.Nil(VerifySymbol(eos))
+	assert.Nil(VerifySymbol(usdt))
+	assert.NotNil(VerifySymbol("eosio.token:EOS:2"))
+	assert.NotNil(VerifySymbol("eosio.token:eos"))
+	assert.NotNil(VerifySymbol("eosio.token:"))
+	assert.NotNil(VerifySymbol("eosio.token:EOSABCDE"))
+	assert.NotNil(VerifySymbol("eosio.token:EOS1"))
 
 	assert.Nil(VerifyAddress("eosio.token"))
 	assert.Nil(VerifyAddress("tethertether"))
@@ -32,6 +42,27 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAddress("."))
 	assert.NotNil(VerifyAddress(".token"))
 	assert.NotNil(VerifyAddress("eosio."))
+	assert.Nil(VerifyAddress("eosio.tokenss"))
+
+	assert.Nil(VerifyAccountName("eosio.token"))
+	assert.Nil(VerifyAccountName("tethertether"))
+	assert.NotNil(VerifyAccountName("eosio.token6"))
+	assert.NotNil(VerifyAccountName("Eosio.token"))
+	assert.NotNil(VerifyAccountName("eos.io.token"))
+	assert.NotNil(VerifyAccountName("."))
+	assert.NotNil(VerifyAccountName(".token"))
+	assert.NotNil(VerifyAccountName("eosio."))
+	assert.NotNil(VerifyAccountName(""))
+	assert.NotNil(VerifyAccountName("tethertether1"))
+	assert.NotNil(VerifyAccountName("eosio.tokenss"))
+	assert.NotNil(VerifyAccountName("eosio0token"))
+	assert.NotNil(VerifyAccountName(" eosio.token"))
+
+	assert.Nil(VerifyMemo(""))
+	assert.Nil(VerifyMemo("c6d0c728-2624-429b-8e0d-d9d19b6592fa"))
+	assert.Nil(VerifyMemo(strings.Repeat("m", MemoSizeLimit)))
+	assert.NotNil(VerifyMemo(strings.Repeat("m", MemoSizeLimit+1)))
+	assert.NotNil(VerifyMemo(string([]byte{0xff, 0xfe})))
 
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(eos))
@@ -0,0 +1,50 @@
+package evm
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// chains are the EVM networks validated by the common package through
+// Lookup, a new network is added here with its EIP-155 chain id, the chain
+// base and the pseudo contract address of the native token, and the
+// confirmations recommended for the deposits. The table is authoritative for
+// the chain ids, asset keys and confirmations, the bsc and polygon packages
+// only expose their entries by name, and never validate them in another path.
+var chains = []*Chain{
+	{Name: "bsc", Number: 56, ChainBase: "1949e683-6a08-49e2-b087-d6b72398588f", NativeAssetKey: "0x0000000000000000000000000000000000000000", Confirmations: 15},
+	{Name: "polygon", Number: 137, ChainBase: "b7938396-3f94-4e0a-9179-d3440718156f", NativeAssetKey: "0x0000000000000000000000000000000000001010", Confirmations: 128},
+}
+
+var (
+	chainsById     map[crypto.Hash]*Chain
+	chainsByNumber map[uint64]*Chain
+)
+
+func init() {
+	chainsById = make(map[crypto.Hash]*Chain)
+	chainsByNumber = make(map[uint64]*Chain)
+	for _, c := range chains {
+		c.ChainId = crypto.NewHash([]byte(c.ChainBase))
+		if chainsById[c.ChainId] != nil || chainsByNumber[c.Number] != nil {
+			panic(fmt.Errorf("duplicated evm chain %s %d", c.Name, c.Number))
+		}
+		chainsById[c.ChainId] = c
+		chainsByNumber[c.Number] = c
+	}
+}
+
+// Lookup returns the EVM network by the Mixin chain id, or nil.
+func Lookup(chainId crypto.Hash) *Chain {
+	return chainsById[chainId]
+}
+
+// LookupNumber returns the EVM network by the EIP-155 chain id, or nil.
+func LookupNumber(number uint64) *Chain {
+	return chainsByNumber[number]
+}
+
+func Chains() []*Chain {
+	return append([]*Chain{}, chains...)
+}
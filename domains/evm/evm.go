@@ -0,0 +1,134 @@
+package evm
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/gofrs/uuid"
+	"golang.org/x/crypto/sha3"
+)
+
+const AddressLength = 20
+
+// Chain is an EVM network, the asset ids are derived from the chain base
+// and the lower case ERC-20 contract address, so the same contract address
+// on different networks is never the same asset. The native asset key is
+// the pseudo contract address of the native token, whose asset id is the
+// chain id itself.
+type Chain struct {
+	Name           string
+	Number         uint64
+	ChainBase      string
+	NativeAssetKey string
+	Confirmations  uint64
+	ChainId        crypto.Hash
+}
+
+func (c *Chain) VerifyAssetKey(assetKey string) error {
+	if len(assetKey) != 42 {
+		return fmt.Errorf("invalid %s asset key %s", c.Name, assetKey)
+	}
+	if !strings.HasPrefix(assetKey, "0x") {
+		return fmt.Errorf("invalid %s asset key %s", c.Name, assetKey)
+	}
+	if assetKey != strings.ToLower(assetKey) {
+		return fmt.Errorf("invalid %s asset key %s", c.Name, assetKey)
+	}
+	k, err := hex.DecodeString(assetKey[2:])
+	if err != nil {
+		return fmt.Errorf("invalid %s asset key %s %s", c.Name, assetKey, err.Error())
+	}
+	if len(k) != AddressLength {
+		return fmt.Errorf("invalid %s asset key %s", c.Name, assetKey)
+	}
+	return nil
+}
+
+// VerifyAddress accepts only the EIP-55 checksum address.
+func (c *Chain) VerifyAddress(address string) error {
+	if strings.TrimSpace(address) != address {
+		return fmt.Errorf("invalid %s address %s", c.Name, address)
+	}
+	if len(address) != 42 {
+		return fmt.Errorf("invalid %s address %s", c.Name, address)
+	}
+	if !strings.HasPrefix(address, "0x") {
+		return fmt.Errorf("invalid %s address %s", c.Name, address)
+	}
+	a, err := hex.DecodeString(address[2:])
+	if err != nil {
+		return fmt.Errorf("invalid %s address %s %s", c.Name, address, err.Error())
+	}
+	if len(a) != AddressLength {
+		return fmt.Errorf("invalid %s address %s", c.Name, address)
+	}
+	if ChecksumAddress(a) != address {
+		return fmt.Errorf("invalid %s address %s", c.Name, address)
+	}
+	return nil
+}
+
+func (c *Chain) VerifyTransactionHash(hash string) error {
+	if len(hash) != 66 {
+		return fmt.Errorf("invalid %s transaction hash %s", c.Name, hash)
+	}
+	if !strings.HasPrefix(hash, "0x") {
+		return fmt.Errorf("invalid %s transaction hash %s", c.Name, hash)
+	}
+	if strings.ToLower(hash) != hash {
+		return fmt.Errorf("invalid %s transaction hash %s", c.Name, hash)
+	}
+	h, err := hex.DecodeString(hash[2:])
+	if err != nil {
+		return fmt.Errorf("invalid %s transaction hash %s %s", c.Name, hash, err.Error())
+	}
+	if len(h) != 32 {
+		return fmt.Errorf("invalid %s transaction hash %s", c.Name, hash)
+	}
+	return nil
+}
+
+func (c *Chain) GenerateAssetId(assetKey string) crypto.Hash {
+	err := c.VerifyAssetKey(assetKey)
+	if err != nil {
+		panic(assetKey)
+	}
+
+	if assetKey == c.NativeAssetKey {
+		return c.ChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, c.ChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
+
+// ChecksumAddress formats the address with the EIP-55 checksum.
+func ChecksumAddress(a []byte) string {
+	buf := []byte("0x" + hex.EncodeToString(a))
+
+	sha := sha3.NewLegacyKeccak256()
+	sha.Write(buf[2:])
+	hash := sha.Sum(nil)
+	for i := 2; i < len(buf); i++ {
+		hashByte := hash[(i-2)/2]
+		if i%2 == 0 {
+			hashByte = hashByte >> 4
+		} else {
+			hashByte &= 0xf
+		}
+		if buf[i] > '9' && hashByte > 7 {
+			buf[i] -= 32
+		}
+	}
+	return string(buf)
+}
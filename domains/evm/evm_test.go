@@ -0,0 +1,64 @@
+package evm
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChains(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, addr := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		a, _ := hex.DecodeString(addr[2:])
+		assert.Equal(addr, ChecksumAddress(a))
+	}
+
+	polygon := LookupNumber(137)
+	assert.NotNil(polygon)
+	assert.Equal("polygon", polygon.Name)
+	assert.Equal(crypto.NewHash([]byte("b7938396-3f94-4e0a-9179-d3440718156f")), polygon.ChainId)
+	assert.Equal(polygon, Lookup(polygon.ChainId))
+	bsc := LookupNumber(56)
+	assert.NotNil(bsc)
+	assert.Equal("bsc", bsc.Name)
+	assert.Equal(crypto.NewHash([]byte("1949e683-6a08-49e2-b087-d6b72398588f")), bsc.ChainId)
+	assert.Equal(bsc.ChainId, bsc.GenerateAssetId(bsc.NativeAssetKey))
+	assert.Equal(uint64(15), bsc.Confirmations)
+	assert.Equal(bsc, Lookup(bsc.ChainId))
+	assert.Nil(Lookup(crypto.NewHash([]byte("43d61dcd-e413-450d-80b8-101d5e903357"))))
+	assert.Nil(LookupNumber(1))
+	assert.Len(Chains(), len(chains))
+
+	usdc := "0x2791bca1f2de4661ed88a30c99a7a9449aa84174"
+	assert.Nil(polygon.VerifyAssetKey(usdc))
+	assert.Nil(polygon.VerifyAssetKey(polygon.NativeAssetKey))
+	assert.NotNil(polygon.VerifyAssetKey(strings.ToUpper(usdc)))
+	assert.NotNil(polygon.VerifyAssetKey(usdc[2:]))
+	assert.NotNil(polygon.VerifyAssetKey(usdc + "00"))
+	err := polygon.VerifyAssetKey(usdc[:40])
+	assert.Contains(err.Error(), "invalid polygon asset key")
+
+	assert.Nil(polygon.VerifyAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"))
+	assert.NotNil(polygon.VerifyAddress(usdc))
+	assert.NotNil(polygon.VerifyAddress(" 0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"))
+
+	tx := "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"
+	assert.Nil(polygon.VerifyTransactionHash(tx))
+	assert.NotNil(polygon.VerifyTransactionHash(strings.ToUpper(tx)))
+	assert.NotNil(polygon.VerifyTransactionHash(tx[2:]))
+
+	assert.Equal(crypto.NewHash([]byte("80b65786-7c75-3523-bc03-fb25378eae41")), polygon.GenerateAssetId(usdc))
+	assert.Equal(polygon.ChainId, polygon.GenerateAssetId(polygon.NativeAssetKey))
+	other := &Chain{Name: "other", Number: 1, ChainBase: "43d61dcd-e413-450d-80b8-101d5e903357"}
+	other.ChainId = crypto.NewHash([]byte(other.ChainBase))
+	assert.NotEqual(polygon.GenerateAssetId(usdc), other.GenerateAssetId(usdc))
+}
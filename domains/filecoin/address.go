@@ -3,6 +3,8 @@ package filecoin
 import (
 	"bytes"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"golang.org/x/crypto/blake2b"
 )
@@ -39,6 +41,8 @@ const (
 	Actor
 	// BLS represents the address BLS protocol.
 	BLS
+	// Delegated represents the delegated (f4) address protocol.
+	Delegated
 
 	Unknown = Protocol(255)
 )
@@ -88,6 +92,14 @@ func NewBLSAddress(pubkey []byte) (Address, error) {
 	return newAddress(BLS, pubkey)
 }
 
+// NewDelegatedAddress returns an address using the Delegated protocol.
+func NewDelegatedAddress(namespace uint64, subaddr []byte) (Address, error) {
+	if namespace > MaxValueUvarint63 {
+		return Undef, ErrInvalidPayload
+	}
+	return newAddress(Delegated, append(ToUvarint(namespace), subaddr...))
+}
+
 // NewFromString returns the address represented by the string `addr`.
 func NewFromString(addr string) (Address, error) {
 	return decode(addr)
@@ -129,6 +141,14 @@ func newAddress(protocol Protocol, payload []byte) (Address, error) {
 		if len(payload) != BlsPublicKeyBytes {
 			return Undef, ErrInvalidPayload
 		}
+	case Delegated:
+		_, n, err := FromUvarint(payload)
+		if err != nil {
+			return Undef, ErrInvalidPayload
+		}
+		if len(payload)-n > MaxSubaddressLen {
+			return Undef, ErrInvalidPayload
+		}
 	default:
 		return Undef, ErrUnknownProtocol
 	}
@@ -158,6 +178,14 @@ func encode(network Network, addr Address) (string, error) {
 	case SECP256K1, BLS:
 		cksm := Checksum(append([]byte{addr.Protocol()}, addr.Payload()...))
 		strAddr = ntwk + fmt.Sprintf("%d", addr.Protocol()) + AddressEncoding.WithPadding(-1).EncodeToString(append(addr.Payload(), cksm[:]...))
+	case Delegated:
+		namespace, n, err := FromUvarint(addr.Payload())
+		if err != nil {
+			return UndefAddressString, err
+		}
+		cksm := Checksum(append([]byte{addr.Protocol()}, addr.Payload()...))
+		subaddr := addr.Payload()[n:]
+		strAddr = ntwk + fmt.Sprintf("%d%d", addr.Protocol(), namespace) + "f" + AddressEncoding.WithPadding(-1).EncodeToString(append(subaddr, cksm[:]...))
 	default:
 		return UndefAddressString, ErrUnknownProtocol
 	}
@@ -171,7 +199,10 @@ func decode(a string) (Address, error) {
 	if a == UndefAddressString {
 		return Undef, nil
 	}
-	if len(a) > MaxAddressStringLength || len(a) < 3 {
+	if len(a) > MaxDelegatedAddressStringLength || len(a) < 3 {
+		return Undef, ErrInvalidLength
+	}
+	if len(a) > MaxAddressStringLength && a[1] != '4' {
 		return Undef, ErrInvalidLength
 	}
 
@@ -185,11 +216,25 @@ func decode(a string) (Address, error) {
 		protocol = SECP256K1
 	case '3':
 		protocol = BLS
+	case '4':
+		protocol = Delegated
 	default:
 		return Undef, ErrUnknownProtocol
 	}
 
 	raw := a[2:]
+	var prefix []byte
+	if protocol == Delegated {
+		i := strings.IndexByte(raw, 'f')
+		if i <= 0 {
+			return Undef, ErrInvalidPayload
+		}
+		namespace, err := strconv.ParseUint(raw[:i], 10, 63)
+		if err != nil {
+			return Undef, ErrInvalidPayload
+		}
+		prefix, raw = ToUvarint(namespace), raw[i+1:]
+	}
 
 	payloadcksm, err := AddressEncoding.WithPadding(-1).DecodeString(raw)
 	if err != nil {
@@ -200,7 +245,7 @@ func decode(a string) (Address, error) {
 		return Undef, ErrInvalidChecksum
 	}
 
-	payload := payloadcksm[:len(payloadcksm)-ChecksumHashLength]
+	payload := append(prefix, payloadcksm[:len(payloadcksm)-ChecksumHashLength]...)
 	cksm := payloadcksm[len(payloadcksm)-ChecksumHashLength:]
 
 	if protocol == SECP256K1 {
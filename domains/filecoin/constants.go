@@ -32,6 +32,13 @@ const ChecksumHashLength = 4
 // it include the network prefx, protocol, and bls publickey
 const MaxAddressStringLength = 2 + 84
 
+// MaxDelegatedAddressStringLength is the max length of a delegated address encoded
+// as a string, it include the decimal namespace and the longest sub address
+const MaxDelegatedAddressStringLength = 2 + 19 + 1 + 93
+
+// MaxSubaddressLen is the max length of the sub address of a delegated address
+const MaxSubaddressLen = 54
+
 // BlsPublicKeyBytes is the length of a BLS public key
 const BlsPublicKeyBytes = 48
 
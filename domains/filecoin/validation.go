@@ -1,11 +1,23 @@
 package filecoin
 
 import (
+	"crypto/md5"
 	"encoding/base32"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// EthereumAddressManagerActorID is the namespace of the f410 addresses
+	EthereumAddressManagerActorID = 10
+
+	cidCodecDagCBOR      = 0x71
+	multihashBlake2b256  = 0xb220
+	multihashDigestBytes = 32
 )
 
 var (
@@ -20,11 +32,16 @@ func init() {
 	CurrentNetwork = Mainnet
 }
 
+// VerifyAssetKey accepts FIL, or the f410 address of a FEVM token contract.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == FilecoinChainBase {
 		return nil
 	}
-	return fmt.Errorf("invalid filecoin asset key %s", assetKey)
+	err := verifyDelegatedAddress(assetKey)
+	if err != nil {
+		return fmt.Errorf("invalid filecoin asset key %s", assetKey)
+	}
+	return nil
 }
 
 func VerifyAddress(addr string) error {
@@ -39,7 +56,11 @@ func VerifyAddress(addr string) error {
 	if err != nil {
 		return fmt.Errorf("invalid filecoin address %s %s", addr, err)
 	}
-	if a.Protocol() != SECP256K1 && a.Protocol() != BLS {
+	switch a.Protocol() {
+	case SECP256K1, BLS:
+	case Delegated:
+		return verifyDelegatedAddress(addr)
+	default:
 		return fmt.Errorf("invalid filecoin address %s", addr)
 	}
 	if a.String() != addr {
@@ -48,6 +69,27 @@ func VerifyAddress(addr string) error {
 	return nil
 }
 
+func verifyDelegatedAddress(addr string) error {
+	a, err := NewFromString(addr)
+	if err != nil {
+		return fmt.Errorf("invalid filecoin address %s %s", addr, err)
+	}
+	if a.Protocol() != Delegated {
+		return fmt.Errorf("invalid filecoin delegated address %s", addr)
+	}
+	namespace, n, err := FromUvarint(a.Payload())
+	if err != nil || namespace != EthereumAddressManagerActorID {
+		return fmt.Errorf("invalid filecoin delegated address %s %d", addr, namespace)
+	}
+	if len(a.Payload())-n != 20 {
+		return fmt.Errorf("invalid filecoin delegated address %s", addr)
+	}
+	if a.String() != addr {
+		return fmt.Errorf("invalid filecoin address %s %s", addr, a.String())
+	}
+	return nil
+}
+
 func VerifyTransactionHash(hash string) error {
 	if strings.TrimSpace(hash) != hash {
 		return fmt.Errorf("invalid filecoin transaction hash %s", hash)
@@ -92,11 +134,55 @@ func VerifyTransactionHash(hash string) error {
 	return nil
 }
 
+// VerifyMessageCid checks the transaction hash is the CID of a message, of
+// the dag-cbor codec and the blake2b-256 digest, which is a stricter rule of
+// the domain fork than VerifyTransactionHash.
+func VerifyMessageCid(hash string) error {
+	err := VerifyTransactionHash(hash)
+	if err != nil {
+		return err
+	}
+	bb, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(hash[1:]))
+	if err != nil {
+		return fmt.Errorf("invalid filecoin transaction hash %s %s", hash, err)
+	}
+	_, n, err := FromUvarint(bb)
+	if err != nil {
+		return fmt.Errorf("invalid filecoin transaction hash %s %s", hash, err)
+	}
+	codec, cn, err := FromUvarint(bb[n:])
+	if err != nil || codec != cidCodecDagCBOR {
+		return fmt.Errorf("invalid filecoin transaction hash %s %d %v", hash, codec, err)
+	}
+	n += cn
+	code, cn, err := FromUvarint(bb[n:])
+	if err != nil || code != multihashBlake2b256 {
+		return fmt.Errorf("invalid filecoin transaction hash %s %d %v", hash, code, err)
+	}
+	n += cn
+	size, cn, err := FromUvarint(bb[n:])
+	if err != nil || size != multihashDigestBytes || len(bb) != n+cn+multihashDigestBytes {
+		return fmt.Errorf("invalid filecoin transaction hash %s %d %v", hash, size, err)
+	}
+	return nil
+}
+
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case FilecoinChainBase:
-		return FilecoinChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == FilecoinChainBase {
+		return FilecoinChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, FilecoinChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
 }
@@ -14,24 +14,55 @@ func TestValidation(t *testing.T) {
 	fil := "08285081-e1d8-4be6-9edc-e203afa932da"
 	tx := "bafy2bzaceaqr65fthy3z4wn2rmo7ani75sekd5kwsg3pkrzznynopbgnovtkc"
 	addrMain := "f1egh23o5qy2ibkqwawqyjague4urpxiyf672l6zi"
+	addrBLS := "f3aebagbafaydqqcikbmga2dqpcaireeyuculbogazdinryhi6d4qccirdeqssmjzifevcwlbnfyxtabhta6wq"
+	addrEVM := "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa"
 
 	assert.Nil(VerifyAssetKey(fil))
 	assert.NotNil(VerifyAssetKey(tx))
 	assert.NotNil(VerifyAssetKey(addrMain))
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(fil)))
+	assert.Nil(VerifyAssetKey(addrEVM))
+	assert.NotNil(VerifyAssetKey(addrBLS))
 
 	assert.Nil(VerifyAddress(addrMain))
 	assert.NotNil(VerifyAddress(fil))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.Nil(VerifyAddress(addrBLS))
+	assert.NotNil(VerifyAddress(addrBLS[:len(addrBLS)-1]))
+	assert.Nil(VerifyAddress(addrEVM))
+	assert.NotNil(VerifyAddress("f4010f" + addrEVM[5:]))
+	assert.NotNil(VerifyAddress("f4300fkkld55ioe7qg24wvt7fu6pbknb56ht7pjmsmhui"))
+	assert.NotNil(VerifyAddress(addrEVM[:len(addrEVM)-1] + "b"))
+	assert.NotNil(VerifyAddress("f0" + addrEVM[2:]))
+
+	a, err := NewDelegatedAddress(EthereumAddressManagerActorID, []byte{0x52, 0x96, 0x3e, 0xf5, 0x0e, 0x27, 0xe0, 0x6d, 0x72, 0xd5, 0x9f, 0xcb, 0x4f, 0x3c, 0x2a, 0x68, 0x7b, 0xe3, 0xcf, 0xef})
+	assert.Nil(err)
+	assert.Equal(addrEVM, a.String())
+	a, err = NewFromString("f4300fkkld55ioe7qg24wvt7fu6pbknb56ht7pjmsmhui")
+	assert.Nil(err)
+	assert.Equal(Delegated, a.Protocol())
 
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(fil))
 	assert.NotNil(VerifyTransactionHash(addrMain))
 	assert.NotNil(VerifyTransactionHash("0x" + tx))
 	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
+	assert.NotNil(VerifyTransactionHash(tx[:len(tx)-2]))
+	assert.NotNil(VerifyTransactionHash("bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"))
+
+	raw := "bafk2bzaceclkp3mimhnqvpaan5dt7htenb4hl46z36hheow25h2tuavsv3bxq"
+	long := "bafy2bzaceclkp3mimhnqvpaan5dt7htenb4hl46z36hheow25h2tuavsv3bqaaa"
+	assert.Nil(VerifyMessageCid(tx))
+	assert.Nil(VerifyMessageCid("bafy2bzaceclkp3mimhnqvpaan5dt7htenb4hl46z36hheow25h2tuavsv3bxq"))
+	assert.Nil(VerifyTransactionHash(raw))
+	assert.NotNil(VerifyMessageCid(raw))
+	assert.Nil(VerifyTransactionHash(long))
+	assert.NotNil(VerifyMessageCid(long))
+	assert.NotNil(VerifyMessageCid(tx[:len(tx)-2]))
 
 	assert.Equal(crypto.NewHash([]byte("08285081-e1d8-4be6-9edc-e203afa932da")), GenerateAssetId(fil))
+	assert.Equal(crypto.NewHash([]byte("51e00699-3a9b-36e4-ae3b-f3be968f2902")), GenerateAssetId(addrEVM))
 	assert.Equal(crypto.NewHash([]byte("08285081-e1d8-4be6-9edc-e203afa932da")), FilecoinChainId)
 	assert.Equal(crypto.NewHash([]byte(FilecoinChainBase)), FilecoinChainId)
 }
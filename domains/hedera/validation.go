@@ -0,0 +1,205 @@
+package hedera
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/gofrs/uuid"
+)
+
+var (
+	HederaChainBase string
+	HederaChainId   crypto.Hash
+)
+
+func init() {
+	HederaChainBase = "80f615fc-2f2e-400f-8bec-625df1ad62e4"
+	HederaChainId = crypto.NewHash([]byte(HederaChainBase))
+}
+
+// mainnetLedgerId is the ledger id of the mainnet for the HIP-15 checksum.
+var mainnetLedgerId = []byte{0x00}
+
+// VerifyAssetKey accepts the HBAR chain base, or the shard.realm.num id of a
+// Hedera Token Service token without checksum, or the token id and the serial
+// number of an HTS NFT, e.g. 0.0.456858/42, so each NFT is a distinct asset
+// from the fungible tokens and the other serials of the same token.
+func VerifyAssetKey(assetKey string) error {
+	if assetKey == HederaChainBase {
+		return nil
+	}
+	parts := strings.Split(assetKey, "/")
+	if len(parts) > 2 {
+		return fmt.Errorf("invalid hedera asset key %s", assetKey)
+	}
+	err := verifyEntityId(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid hedera asset key %s", assetKey)
+	}
+	if len(parts) == 2 {
+		err = verifySerialNumber(parts[1])
+	}
+	if err != nil {
+		return fmt.Errorf("invalid hedera asset key %s", assetKey)
+	}
+	return nil
+}
+
+// IsNonFungibleKey reports whether the asset key is a HTS NFT token id with
+// its serial number, e.g. 0.0.1234567/42.
+func IsNonFungibleKey(assetKey string) bool {
+	if strings.Count(assetKey, "/") != 1 {
+		return false
+	}
+	return VerifyAssetKey(assetKey) == nil
+}
+
+// VerifyAddress accepts the shard.realm.num account id, and the account id
+// with the HIP-15 checksum of the mainnet, e.g. 0.0.123-vfmkw.
+func VerifyAddress(address string) error {
+	if strings.TrimSpace(address) != address {
+		return fmt.Errorf("invalid hedera address %s", address)
+	}
+	parts := strings.Split(address, "-")
+	if len(parts) > 2 {
+		return fmt.Errorf("invalid hedera address %s", address)
+	}
+	err := verifyEntityId(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid hedera address %s", address)
+	}
+	if len(parts) == 2 && parts[1] != entityIdChecksum(parts[0], mainnetLedgerId) {
+		return fmt.Errorf("invalid hedera address checksum %s", address)
+	}
+	return nil
+}
+
+// VerifyTransactionHash accepts the transaction id of the payer account and
+// the valid start time, e.g. 0.0.123@1615422161.673238162, the nanoseconds
+// are always in 9 digits.
+func VerifyTransactionHash(hash string) error {
+	parts := strings.Split(hash, "@")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid hedera transaction hash %s", hash)
+	}
+	err := verifyEntityId(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid hedera transaction hash %s", hash)
+	}
+	start := strings.Split(parts[1], ".")
+	if len(start) != 2 || len(start[1]) != 9 {
+		return fmt.Errorf("invalid hedera transaction hash %s", hash)
+	}
+	_, err = parseCanonicalUint(start[0])
+	if err != nil {
+		return fmt.Errorf("invalid hedera transaction hash %s", hash)
+	}
+	for _, c := range start[1] {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("invalid hedera transaction hash %s", hash)
+		}
+	}
+	return nil
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
+		panic(assetKey)
+	}
+
+	if assetKey == HederaChainBase {
+		return HederaChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, HederaChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
+
+// verifyEntityId accepts the shard.realm.num id of the decimal numbers
+// without leading zeros, so an entity has only one id.
+func verifyEntityId(id string) error {
+	parts := strings.Split(id, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid entity id %s", id)
+	}
+	for _, p := range parts {
+		_, err := parseCanonicalUint(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifySerialNumber accepts the serial number of an NFT, which is minted from
+// 1 and is a signed 64 bits integer on the ledger.
+func verifySerialNumber(serial string) error {
+	n, err := parseCanonicalUint(serial)
+	if err != nil {
+		return err
+	}
+	if n == 0 || n > math.MaxInt64 {
+		return fmt.Errorf("invalid serial number %s", serial)
+	}
+	return nil
+}
+
+func parseCanonicalUint(s string) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if strconv.FormatUint(n, 10) != s {
+		return 0, fmt.Errorf("invalid number %s", s)
+	}
+	return n, nil
+}
+
+// entityIdChecksum is the HIP-15 checksum of the entity id on the ledger.
+func entityIdChecksum(id string, ledgerId []byte) string {
+	const (
+		p3 = 26 * 26 * 26
+		p5 = 26 * 26 * 26 * 26 * 26
+		m  = 1000003
+		w  = 31
+	)
+
+	var sd0, sd1, sd, sh uint64
+	for i, c := range []byte(id) {
+		d := uint64(10)
+		if c != '.' {
+			d = uint64(c - '0')
+		}
+		sd = (w*sd + d) % p3
+		if i%2 == 0 {
+			sd0 = (sd0 + d) % 11
+		} else {
+			sd1 = (sd1 + d) % 11
+		}
+	}
+	h := append(append([]byte{}, ledgerId...), make([]byte, 6)...)
+	for _, b := range h {
+		sh = (w*sh + uint64(b)) % p5
+	}
+	c := ((((uint64(len(id))%5)*11+sd0)*11+sd1)*p3 + sd + sh) % p5
+	cp := (c * m) % p5
+
+	checksum := make([]byte, 5)
+	for i := 4; i >= 0; i-- {
+		checksum[i] = byte('a' + cp%26)
+		cp /= 26
+	}
+	return string(checksum)
+}
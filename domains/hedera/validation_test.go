@@ -0,0 +1,72 @@
+package hedera
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	hbar := "80f615fc-2f2e-400f-8bec-625df1ad62e4"
+	usdc := "0.0.456858"
+	addr := "0.0.1234567"
+	tx := "0.0.1234567@1615422161.073238162"
+
+	assert.Nil(VerifyAssetKey(hbar))
+	assert.Nil(VerifyAssetKey(usdc))
+	assert.Nil(VerifyAssetKey("0.0.0"))
+	assert.NotNil(VerifyAssetKey("0.0.0456858"))
+	assert.NotNil(VerifyAssetKey("0.0"))
+	assert.NotNil(VerifyAssetKey("0.0.456858.1"))
+	assert.NotNil(VerifyAssetKey("0.0.+456858"))
+	assert.NotNil(VerifyAssetKey(usdc + "-ojdqc"))
+	assert.NotNil(VerifyAssetKey(tx))
+
+	nft := "0.0.1234567/42"
+	assert.Nil(VerifyAssetKey(nft))
+	assert.Nil(VerifyAssetKey("0.0.1234567/1"))
+	assert.Nil(VerifyAssetKey("0.0.1234567/9223372036854775807"))
+	assert.NotNil(VerifyAssetKey("0.0.1234567/9223372036854775808"))
+	assert.NotNil(VerifyAssetKey("0.0.1234567/0"))
+	assert.NotNil(VerifyAssetKey("0.0.1234567/042"))
+	assert.NotNil(VerifyAssetKey("0.0.1234567/-1"))
+	assert.NotNil(VerifyAssetKey("0.0.1234567/"))
+	assert.NotNil(VerifyAssetKey("0.0.1234567/42/1"))
+	assert.NotNil(VerifyAssetKey("0.0/42"))
+	assert.NotNil(VerifyAssetKey(hbar + "/42"))
+	assert.True(IsNonFungibleKey(nft))
+	assert.False(IsNonFungibleKey(usdc))
+	assert.False(IsNonFungibleKey(hbar))
+	assert.False(IsNonFungibleKey("0.0.1234567/0"))
+	assert.False(IsNonFungibleKey("0.0.1234567/42/1"))
+
+	assert.Equal("vfmkw", entityIdChecksum("0.0.123", mainnetLedgerId))
+	assert.Equal("ojdqc", entityIdChecksum(usdc, mainnetLedgerId))
+	assert.Nil(VerifyAddress(addr))
+	assert.Nil(VerifyAddress(addr + "-ylkls"))
+	assert.Nil(VerifyAddress("0.0.123-vfmkw"))
+	assert.NotNil(VerifyAddress("0.0.123-vfmkx"))
+	assert.NotNil(VerifyAddress("0.0.123-VFMKW"))
+	assert.NotNil(VerifyAddress("0.0.123-vfmkw-vfmkw"))
+	assert.NotNil(VerifyAddress(" " + addr))
+	assert.NotNil(VerifyAddress("0.0.01234567"))
+	assert.NotNil(VerifyAddress(hbar))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash("0.0.1234567@1615422161.73238162"))
+	assert.NotNil(VerifyTransactionHash("0.0.1234567@01615422161.073238162"))
+	assert.NotNil(VerifyTransactionHash("0.0.1234567-1615422161-073238162"))
+	assert.NotNil(VerifyTransactionHash("0.0.1234567@1615422161"))
+	assert.NotNil(VerifyTransactionHash("0.0.1234567@1615422161.07323816a"))
+	assert.NotNil(VerifyTransactionHash(addr))
+
+	assert.Equal(crypto.NewHash([]byte("80f615fc-2f2e-400f-8bec-625df1ad62e4")), GenerateAssetId(hbar))
+	assert.Equal(crypto.NewHash([]byte("80f615fc-2f2e-400f-8bec-625df1ad62e4")), HederaChainId)
+	assert.Equal(crypto.NewHash([]byte("2f6174a6-aaea-3a03-aaa7-df37c59ea225")), GenerateAssetId(usdc))
+	assert.Equal(crypto.NewHash([]byte("ec07aeea-b97b-3e47-8197-dbd33c778144")), GenerateAssetId(nft))
+	assert.NotEqual(GenerateAssetId(nft), GenerateAssetId("0.0.1234567"))
+	assert.NotEqual(GenerateAssetId(nft), GenerateAssetId("0.0.1234567/43"))
+}
@@ -1,13 +1,15 @@
 package kusama
 
 import (
+	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
-	"github.com/btcsuite/btcutil/base58"
-	"golang.org/x/crypto/blake2b"
+	"github.com/MixinNetwork/mixin/domains/polkadot"
+	"github.com/gofrs/uuid"
 )
 
 const (
@@ -24,35 +26,48 @@ func init() {
 	KusamaChainId = crypto.NewHash([]byte(KusamaChainBase))
 }
 
+// VerifyAssetKey accepts the chain base for KSM, or a parachain asset of
+// the pallet assets or the foreign assets on the asset hub, e.g. 1000:1984
+// for USDT on statemine.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == KusamaChainBase {
 		return nil
 	}
-	return fmt.Errorf("invalid kusama asset key %s", assetKey)
+	err := polkadot.VerifyParachainAssetKey(assetKey)
+	if err != nil {
+		return fmt.Errorf("invalid kusama asset key %s", assetKey)
+	}
+	return nil
 }
 
+// VerifyAddress accepts the SS58 address of the kusama network prefix,
+// checked by the checksum only. The 32 bytes account id is required by
+// VerifyAccountAddress after the domain fork.
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid kusama address %s", address)
 	}
-	b := base58.Decode(address)
-	if len(b) < 8 {
-		return fmt.Errorf("invalid kusama address %s", address)
-	}
-	if b[0] != byte(addressPrefix) {
+	err := polkadot.VerifyChecksumAddress(addressPrefix, address)
+	if err != nil {
 		return fmt.Errorf("invalid kusama address %s", address)
 	}
-	addr := base58.Encode(b)
-	if addr != address {
-		return fmt.Errorf("invalid kusama address %s", address)
+	return nil
+}
+
+// VerifyAccountAddress checks the address by the stricter rules of the
+// domain fork, the address must be the 32 bytes account id of the kusama
+// network prefix.
+func VerifyAccountAddress(address string) error {
+	err := VerifyAddress(address)
+	if err != nil {
+		return err
 	}
-	public := b[1 : len(b)-2]
-	addr, err := PublicKeyToAddress(public)
+	prefix, _, err := polkadot.DecodeAddress(address)
 	if err != nil {
 		return fmt.Errorf("invalid kusama address %s", address)
 	}
-	if addr != address {
-		return fmt.Errorf("invalid kusama address %s", address)
+	if prefix != addressPrefix {
+		return fmt.Errorf("invalid kusama address network %s %d", address, prefix)
 	}
 	return nil
 }
@@ -78,26 +93,25 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case KusamaChainBase:
-		return KusamaChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
-}
 
-var ss58Prefix = []byte("SS58PRE")
+	if assetKey == KusamaChainBase {
+		return KusamaChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, KusamaChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
 
 func PublicKeyToAddress(pub []byte) (string, error) {
-	enc := append([]byte{addressPrefix}, pub...)
-	hasher, err := blake2b.New(64, nil)
-	if err != nil {
-		return "", err
-	}
-	_, err = hasher.Write(append(ss58Prefix, enc...))
-	if err != nil {
-		return "", err
-	}
-	checksum := hasher.Sum(nil)
-	return base58.Encode(append(enc, checksum[:2]...)), nil
+	return polkadot.EncodeAddress(addressPrefix, pub)
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/polkadot"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,9 +22,15 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(ksm)))
 
 	assert.Nil(VerifyAddress(addrMain))
+	assert.Nil(VerifyAccountAddress(addrMain))
+	assert.Nil(VerifyAddress("USmaZZXQHw"))
+	assert.NotNil(VerifyAccountAddress("USmaZZXQHw"))
 	assert.NotNil(VerifyAddress(ksm))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.Nil(VerifyAddress("HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F"))
+	assert.NotNil(VerifyAddress("15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5"))
+	assert.NotNil(VerifyAddress("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"))
 
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(ksm))
@@ -34,4 +41,13 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("9d29e4f6-d67c-4c4b-9525-604b04afbe9f")), GenerateAssetId(ksm))
 	assert.Equal(crypto.NewHash([]byte("9d29e4f6-d67c-4c4b-9525-604b04afbe9f")), KusamaChainId)
 	assert.Equal(crypto.NewHash([]byte(KusamaChainBase)), KusamaChainId)
+
+	usdt := "1000:1984"
+	assert.Nil(VerifyAssetKey(usdt))
+	assert.Nil(VerifyAssetKey("1000:0x01010903"))
+	assert.NotNil(VerifyAssetKey("1000:01984"))
+	assert.NotNil(VerifyAssetKey("1000:0x0101"))
+	assert.Equal(crypto.NewHash([]byte("d3a2a92d-fe33-30bf-8254-df4ee62cbfc4")), GenerateAssetId(usdt))
+	assert.NotEqual(polkadot.GenerateAssetId(usdt), GenerateAssetId(usdt))
+	assert.NotEqual(GenerateAssetId("1000:0x01010903"), polkadot.GenerateAssetId("1000:0x01010903"))
 }
@@ -25,6 +25,8 @@ func VerifyAssetKey(assetKey string) error {
 	return fmt.Errorf("invalid litecoin asset key %s", assetKey)
 }
 
+// VerifyAddress accepts the legacy P2PKH, the P2SH of both version bytes,
+// and the bech32 ltc1 witness version 0 addresses.
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid litecoin address %s", address)
@@ -42,6 +44,23 @@ func VerifyAddress(address string) error {
 	return nil
 }
 
+// VerifyObservableAddress rejects the MWEB addresses explicitly, since the
+// MWEB outputs are hidden from the deposit observers, so are the bech32m
+// addresses, e.g. ltc1p taproot, which is a stricter rule of the domain fork
+// than VerifyAddress.
+func VerifyObservableAddress(address string) error {
+	lower := strings.ToLower(address)
+	for _, hrp := range []string{mwebHRP, mwebTestHRP} {
+		if strings.HasPrefix(lower, hrp+"1") {
+			return fmt.Errorf("unsupported litecoin mweb address %s", address)
+		}
+	}
+	if strings.HasPrefix(lower, ltcParams.Bech32HRPSegwit+"1") && isBech32m(lower) {
+		return fmt.Errorf("unsupported litecoin bech32m address %s", address)
+	}
+	return VerifyAddress(address)
+}
+
 func VerifyTransactionHash(hash string) error {
 	if len(hash) != 64 {
 		return fmt.Errorf("invalid litecoin transaction hash %s", hash)
@@ -68,6 +87,55 @@ func GenerateAssetId(assetKey string) crypto.Hash {
 	}
 }
 
+const (
+	mwebHRP     = "ltcmweb"
+	mwebTestHRP = "tmweb"
+
+	bech32Charset   = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32mConstant = 0x2bc830a3
+)
+
+// isBech32m checks whether the lower case address has a valid bech32m
+// checksum, which is never a valid bech32 checksum at the same time.
+func isBech32m(address string) bool {
+	one := strings.LastIndexByte(address, '1')
+	if one < 1 || one+7 > len(address) {
+		return false
+	}
+	hrp, data := address[:one], address[one+1:]
+	values := make([]int, 0, len(hrp)*2+1+len(data))
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]>>5))
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]&31))
+	}
+	for i := 0; i < len(data); i++ {
+		v := strings.IndexByte(bech32Charset, data[i])
+		if v < 0 {
+			return false
+		}
+		values = append(values, v)
+	}
+	return bech32Polymod(values) == bech32mConstant
+}
+
+func bech32Polymod(values []int) int {
+	gen := []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := 1
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ v
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
 var (
 	ltcParams = Params{
 		Bech32HRPSegwit:  "ltc",
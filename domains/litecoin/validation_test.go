@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/btcsuite/btcutil/bech32"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,3 +40,83 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("76c802a2-7c88-447f-a93e-c29c9e5dd9c8")), LitecoinChainId)
 	assert.Equal(crypto.NewHash([]byte(LitecoinChainBase)), LitecoinChainId)
 }
+
+func TestAddressEdgeCases(t *testing.T) {
+	assert := assert.New(t)
+
+	program := crypto.NewHash([]byte("litecoin"))
+	p2wpkh, err := encodeSegWitAddress("ltc", 0, program[:20])
+	assert.Nil(err)
+	assert.Nil(VerifyAddress(p2wpkh))
+	p2wsh, err := encodeSegWitAddress("ltc", 0, program[:])
+	assert.Nil(err)
+	assert.Nil(VerifyAddress(p2wsh))
+	assert.Nil(VerifyAddress(encodeAddress(program[:20], 0x30)))
+	assert.Nil(VerifyAddress(encodeAddress(program[:20], 0x32)))
+	assert.Nil(VerifyAddress(encodeAddress(program[:20], 0x05)))
+	assert.NotNil(VerifyAddress(encodeAddress(program[:20], 0x00)))
+
+	assert.NotNil(VerifyAddress(strings.ToUpper(p2wpkh)))
+	assert.NotNil(VerifyAddress(p2wpkh[:10] + strings.ToUpper(p2wpkh[10:])))
+	last := "q"
+	if strings.HasSuffix(p2wpkh, last) {
+		last = "p"
+	}
+	assert.NotNil(VerifyAddress(p2wpkh[:len(p2wpkh)-1] + last))
+	assert.NotNil(VerifyAddress(p2wpkh + " "))
+
+	v0m := testBech32mAddress(t, "ltc", 0, program[:20])
+	assert.False(isBech32m(p2wpkh))
+	assert.True(isBech32m(v0m))
+	assert.Nil(VerifyObservableAddress(p2wpkh))
+	assert.Nil(VerifyObservableAddress(p2wsh))
+	err = VerifyObservableAddress(v0m)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "bech32m")
+	taproot := testBech32mAddress(t, "ltc", 1, program[:])
+	assert.True(strings.HasPrefix(taproot, "ltc1p"))
+	assert.NotNil(VerifyObservableAddress(taproot))
+	v1, err := bech32.Encode("ltc", append([]byte{1}, testConvertBits(t, program[:])...))
+	assert.Nil(err)
+	assert.NotNil(VerifyAddress(v1))
+	assert.NotNil(VerifyAddress(testBech32mAddress(t, "bc", 0, program[:20])))
+
+	mweb := "ltcmweb1qq0hav4lqwkfxquzmatjkp3c6lmzm7m8cz2afwvnqzyk8tnkwq5qswq6evkmsd56ph34c5f6l78z0pvdst5dpns7rnaa6ea4w2cdcdhjfryecqfy5"
+	for _, addr := range []string{mweb, strings.ToUpper(mweb), "tmweb1" + mweb[8:]} {
+		err = VerifyObservableAddress(addr)
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "mweb")
+	}
+}
+
+func testBech32mAddress(t *testing.T, hrp string, version byte, program []byte) string {
+	data := append([]byte{version}, testConvertBits(t, program)...)
+	values := make([]int, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]>>5))
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, int(hrp[i]&31))
+	}
+	for _, d := range data {
+		values = append(values, int(d))
+	}
+	mod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ bech32mConstant
+	for i := 0; i < 6; i++ {
+		data = append(data, byte(mod>>uint(5*(5-i))&31))
+	}
+	addr := hrp + "1"
+	for _, d := range data {
+		addr += string(bech32Charset[d])
+	}
+	return addr
+}
+
+func testConvertBits(t *testing.T, program []byte) []byte {
+	converted, err := bech32.ConvertBits(program, 8, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return converted
+}
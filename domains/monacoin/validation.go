@@ -0,0 +1,81 @@
+package monacoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/litecoin"
+)
+
+var (
+	MonacoinChainBase string
+	MonacoinChainId   crypto.Hash
+)
+
+func init() {
+	MonacoinChainBase = "51d0f133-9740-488e-b72e-0917a2ba312c"
+	MonacoinChainId = crypto.NewHash([]byte(MonacoinChainBase))
+}
+
+func VerifyAssetKey(assetKey string) error {
+	if assetKey == MonacoinChainBase {
+		return nil
+	}
+	return fmt.Errorf("invalid monacoin asset key %s", assetKey)
+}
+
+func VerifyAddress(address string) error {
+	if strings.TrimSpace(address) != address {
+		return fmt.Errorf("invalid monacoin address %s", address)
+	}
+	monaAddress, err := litecoin.DecodeAddress(address, &mainNetParams)
+	if err != nil {
+		monaAddress, err = litecoin.DecodeAddress(address, &legacyParams)
+		if err != nil {
+			return fmt.Errorf("invalid monacoin address %s %s", address, err.Error())
+		}
+	}
+	if monaAddress.String() != address {
+		return fmt.Errorf("invalid monacoin address %s", address)
+	}
+	return nil
+}
+
+func VerifyTransactionHash(hash string) error {
+	if len(hash) != 64 {
+		return fmt.Errorf("invalid monacoin transaction hash %s", hash)
+	}
+	if strings.ToLower(hash) != hash {
+		return fmt.Errorf("invalid monacoin transaction hash %s", hash)
+	}
+	h, err := hex.DecodeString(hash)
+	if err != nil {
+		return fmt.Errorf("invalid monacoin transaction hash %s %s", hash, err.Error())
+	}
+	if len(h) != 32 {
+		return fmt.Errorf("invalid monacoin transaction hash %s", hash)
+	}
+	return nil
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	switch assetKey {
+	case MonacoinChainBase:
+		return MonacoinChainId
+	default:
+		panic(assetKey)
+	}
+}
+
+var (
+	mainNetParams = litecoin.Params{
+		PubKeyHashAddrID: 0x32,
+		ScriptHashAddrID: 0x37,
+	}
+	legacyParams = litecoin.Params{
+		PubKeyHashAddrID: 0x32,
+		ScriptHashAddrID: 0x05,
+	}
+)
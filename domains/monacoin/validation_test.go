@@ -0,0 +1,42 @@
+package monacoin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	mona := "51d0f133-9740-488e-b72e-0917a2ba312c"
+	tx := "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"
+	addrMain := "MTVk1Jcegvnq7TBD43pFZH7uW4F45LXhka"
+	addrScript := "PEgrnujDmUJ9f43y4s9Nu64CTdLMCeuiQL"
+	addrLegacy := "3GQg5x56pJYAHbM3XyzUpdTZqn9Lq6yu56"
+
+	assert.Nil(VerifyAssetKey(mona))
+	assert.NotNil(VerifyAssetKey(tx))
+	assert.NotNil(VerifyAssetKey(addrMain))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(mona)))
+
+	assert.Nil(VerifyAddress(addrMain))
+	assert.NotNil(VerifyAddress(mona))
+	assert.NotNil(VerifyAddress(addrMain[1:]))
+	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.Nil(VerifyAddress(addrScript))
+	assert.Nil(VerifyAddress(addrLegacy))
+	assert.NotNil(VerifyAddress("NCjrV4CWpSr73mfYADbiujetMB3F3VrDWc"))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash(mona))
+	assert.NotNil(VerifyTransactionHash(addrMain))
+	assert.NotNil(VerifyTransactionHash("0x" + tx))
+	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
+
+	assert.Equal(crypto.NewHash([]byte("51d0f133-9740-488e-b72e-0917a2ba312c")), GenerateAssetId(mona))
+	assert.Equal(crypto.NewHash([]byte("51d0f133-9740-488e-b72e-0917a2ba312c")), MonacoinChainId)
+	assert.Equal(crypto.NewHash([]byte(MonacoinChainBase)), MonacoinChainId)
+}
@@ -2,13 +2,21 @@ package near
 
 import (
 	"crypto/ed25519"
+	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strings"
 
 	"filippo.io/edwards25519"
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	accountIdMinLength = 2
+	accountIdMaxLength = 64
 )
 
 var (
@@ -21,13 +29,22 @@ func init() {
 	NearChainId = crypto.NewHash([]byte(NearChainBase))
 }
 
+// VerifyAssetKey accepts the native asset, and the NEP-141 tokens by their
+// named contract account id, e.g. usdt.tether-token.near or aurora.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == NearChainBase {
 		return nil
 	}
+	implicit := len(assetKey) == ed25519.PublicKeySize*2 && isHex(assetKey)
+	if !implicit && verifyNamedAccount(assetKey) == nil {
+		return nil
+	}
 	return fmt.Errorf("invalid near asset key %s", assetKey)
 }
 
+// VerifyAddress accepts both the implicit account, which is the hex of an
+// ed25519 public key, and the named sub account like alice.near, while the
+// top level accounts are only registered for the network itself.
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid near address %s", address)
@@ -35,6 +52,45 @@ func VerifyAddress(address string) error {
 	if strings.ToLower(address) != address {
 		return fmt.Errorf("invalid near address %s", address)
 	}
+	if len(address) == ed25519.PublicKeySize*2 && isHex(address) {
+		return verifyImplicitAccount(address)
+	}
+	if !strings.Contains(address, ".") {
+		return fmt.Errorf("invalid near address %s", address)
+	}
+	return verifyNamedAccount(address)
+}
+
+// verifyNamedAccount follows the account id rules of nearcore, the parts
+// separated by dots are lowercase alphanumeric with single - or _ between.
+func verifyNamedAccount(address string) error {
+	if strings.ToLower(address) != address {
+		return fmt.Errorf("invalid near address %s", address)
+	}
+	if len(address) < accountIdMinLength || len(address) > accountIdMaxLength {
+		return fmt.Errorf("invalid near address length %s", address)
+	}
+	separator := true
+	for _, c := range address {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			separator = false
+		case c == '-', c == '_', c == '.':
+			if separator {
+				return fmt.Errorf("invalid near address %s", address)
+			}
+			separator = true
+		default:
+			return fmt.Errorf("invalid near address %s", address)
+		}
+	}
+	if separator {
+		return fmt.Errorf("invalid near address %s", address)
+	}
+	return nil
+}
+
+func verifyImplicitAccount(address string) error {
 	addr, err := hex.DecodeString(address)
 	if err != nil {
 		return fmt.Errorf("invalid near address %s", address)
@@ -68,10 +124,30 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case NearChainBase:
-		return NearChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == NearChainBase {
+		return NearChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, NearChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
 }
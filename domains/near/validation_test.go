@@ -33,6 +33,30 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyTransactionHash("0x" + tx))
 	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
 
+	named := "alice.near"
+	assert.Nil(VerifyAddress(named))
+	assert.Nil(VerifyAddress("app_1.alice-bob.testnet"))
+	assert.Nil(VerifyAddress("a." + strings.Repeat("b", 62)))
+	assert.NotNil(VerifyAddress("a." + strings.Repeat("b", 63)))
+	assert.NotNil(VerifyAddress("near"))
+	assert.NotNil(VerifyAddress("Alice.near"))
+	assert.NotNil(VerifyAddress(".alice.near"))
+	assert.NotNil(VerifyAddress("alice.near."))
+	assert.NotNil(VerifyAddress("alice..near"))
+	assert.NotNil(VerifyAddress("alice-_bob.near"))
+	assert.NotNil(VerifyAddress("alice@near"))
+	assert.NotNil(VerifyAddress(" alice.near"))
+
+	usdt := "usdt.tether-token.near"
+	assert.Nil(VerifyAssetKey(usdt))
+	assert.Nil(VerifyAssetKey("wrap.near"))
+	assert.Nil(VerifyAssetKey("aurora"))
+	assert.NotNil(VerifyAssetKey("a"))
+	assert.NotNil(VerifyAssetKey("USDT.tether-token.near"))
+	assert.NotNil(VerifyAssetKey("usdt.tether-token.near-"))
+	assert.Equal(crypto.NewHash([]byte("08789c0e-3569-32ba-995c-fdc81ba754db")), GenerateAssetId(usdt))
+	assert.Equal(crypto.NewHash([]byte("79a4218a-07c8-3b88-a480-bb31ea23e175")), GenerateAssetId("wrap.near"))
+
 	assert.Equal(crypto.NewHash([]byte("d6ac94f7-c932-4e11-97dd-617867f0669e")), GenerateAssetId(near))
 	assert.Equal(crypto.NewHash([]byte("d6ac94f7-c932-4e11-97dd-617867f0669e")), NearChainId)
 	assert.Equal(crypto.NewHash([]byte(NearChainBase)), NearChainId)
@@ -0,0 +1,149 @@
+package osmosis
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/cosmos"
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	PoolShareDenomPrefix = "gamm/pool/"
+)
+
+var (
+	OsmosisAssetKey  string
+	OsmosisChainBase string
+	OsmosisChainId   crypto.Hash
+)
+
+func init() {
+	OsmosisAssetKey = "uosmo"
+	OsmosisChainBase = "a54e4e82-8d12-4295-a3bf-dad1189a6e9f"
+	OsmosisChainId = crypto.NewHash([]byte(OsmosisChainBase))
+}
+
+// VerifyAssetKey accepts the OSMO denom, the gamm/pool/{id} denom of the
+// liquidity pool shares, or a CW20 contract address on Osmosis.
+func VerifyAssetKey(assetKey string) error {
+	if assetKey == OsmosisAssetKey {
+		return nil
+	}
+	if strings.HasPrefix(assetKey, PoolShareDenomPrefix) {
+		return VerifyPoolShareDenom(assetKey)
+	}
+	if cosmos.VerifyContractAddress("osmo", assetKey) == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid osmosis asset key %s", assetKey)
+}
+
+// VerifyPoolShareDenom checks the gamm/pool/{id} denom of the liquidity pool
+// shares, the pool id is a positive decimal without leading zeros.
+func VerifyPoolShareDenom(denom string) error {
+	id := strings.TrimPrefix(denom, PoolShareDenomPrefix)
+	if id == denom {
+		return fmt.Errorf("invalid pool share denom %s", denom)
+	}
+	pool, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pool share denom %s %s", denom, err.Error())
+	}
+	if pool == 0 || strconv.FormatUint(pool, 10) != id {
+		return fmt.Errorf("invalid pool share denom %s", denom)
+	}
+	return nil
+}
+
+func VerifyAddress(address string) error {
+	if strings.TrimSpace(address) != address {
+		return fmt.Errorf("invalid osmosis address %s", address)
+	}
+
+	bech32Prefix := "osmo"
+	hrp, bz, err := decodeAndConvert(address)
+	if err != nil {
+		return fmt.Errorf("invalid osmosis address %s %s", address, err.Error())
+	}
+	if hrp != bech32Prefix {
+		return fmt.Errorf("invalid osmosis address %s", address)
+	}
+	if len(bz) != 20 {
+		return fmt.Errorf("invalid osmosis address %s", address)
+	}
+	addr, err := convertAndEncode(bech32Prefix, bz)
+	if err != nil {
+		return fmt.Errorf("invalid osmosis address %s %s", address, err.Error())
+	}
+	if addr != address {
+		return fmt.Errorf("invalid osmosis address %s", address)
+	}
+	return nil
+}
+
+func VerifyTransactionHash(hash string) error {
+	h, err := hex.DecodeString(hash)
+	if err != nil {
+		return fmt.Errorf("invalid osmosis transaction hash %s %s", hash, err.Error())
+	}
+	if strings.ToLower(hash) != hash {
+		return fmt.Errorf("invalid osmosis transaction hash %s", hash)
+	}
+	if len(h) != 32 {
+		return fmt.Errorf("invalid osmosis transaction hash %s", hash)
+	}
+	return nil
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
+		panic(assetKey)
+	}
+
+	if assetKey == OsmosisAssetKey {
+		return OsmosisChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, OsmosisChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
+
+func convertAndEncode(hrp string, data []byte) (string, error) {
+	converted, err := bech32.ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("encoding bech32 failed: %w", err)
+	}
+
+	return bech32.Encode(hrp, converted)
+}
+
+func decodeAndConvert(bech string) (string, []byte, error) {
+	if len(bech) > 1023 {
+		return "", nil, fmt.Errorf("invalid bech32 string length %d",
+			len(bech))
+	}
+	hrp, data, err := bech32.DecodeNoLimit(bech)
+	if err != nil {
+		return "", nil, fmt.Errorf("decoding bech32 failed: %w", err)
+	}
+
+	converted, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("decoding bech32 failed: %w", err)
+	}
+	return hrp, converted, nil
+}
@@ -0,0 +1,58 @@
+package osmosis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	osmo := "uosmo"
+	tx := "c9698260bab4095df25a228a3d855918de38a9e0c57d7a137de18b4c141f26ee"
+	addrMain := "osmo1vawrtys744sfqujm28tqjx47h703h9eyw0frar"
+	cw20 := "osmo1sdsmh6kdd0uv32ken96rd5qw0kerqwqnuct23lz06amt7ymclk4q2jra69"
+
+	assert.Nil(VerifyAssetKey(osmo))
+	assert.Nil(VerifyAssetKey(cw20))
+	assert.NotNil(VerifyAssetKey(tx))
+	assert.NotNil(VerifyAssetKey(addrMain))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(osmo)))
+	assert.NotNil(VerifyAssetKey("uatom"))
+	assert.NotNil(VerifyAssetKey("cosmos1tckpxnyvy0tulzz56yenztghjkx3gqyl28sytat22v5zwr8nffdsvhdpr7"))
+
+	assert.Nil(VerifyAddress(addrMain))
+	assert.NotNil(VerifyAddress(osmo))
+	assert.NotNil(VerifyAddress(cw20))
+	assert.NotNil(VerifyAddress(addrMain[1:]))
+	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.NotNil(VerifyAddress("cosmos1vawrtys744sfqujm28tqjx47h703h9eyx56nt3"))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash(osmo))
+	assert.NotNil(VerifyTransactionHash(addrMain))
+	assert.NotNil(VerifyTransactionHash("0x" + tx))
+	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
+
+	pool := "gamm/pool/1"
+	assert.Nil(VerifyAssetKey(pool))
+	assert.Nil(VerifyAssetKey("gamm/pool/678"))
+	assert.NotNil(VerifyAssetKey("gamm/pool/0"))
+	assert.NotNil(VerifyAssetKey("gamm/pool/01"))
+	assert.NotNil(VerifyAssetKey("gamm/pool/-1"))
+	assert.NotNil(VerifyAssetKey("gamm/pool/+1"))
+	assert.NotNil(VerifyAssetKey("gamm/pool/"))
+	assert.NotNil(VerifyAssetKey("gamm/pool/1/2"))
+	assert.NotNil(VerifyAssetKey("GAMM/pool/1"))
+	assert.NotNil(VerifyPoolShareDenom("uosmo"))
+
+	assert.Equal(crypto.NewHash([]byte("a54e4e82-8d12-4295-a3bf-dad1189a6e9f")), GenerateAssetId(osmo))
+	assert.Equal(crypto.NewHash([]byte("a54e4e82-8d12-4295-a3bf-dad1189a6e9f")), OsmosisChainId)
+	assert.Equal(crypto.NewHash([]byte(OsmosisChainBase)), OsmosisChainId)
+	assert.Equal(crypto.NewHash([]byte("2b2c5755-3f14-302e-8aed-541532a91e10")), GenerateAssetId(pool))
+	assert.Equal(crypto.NewHash([]byte("3cc16710-573a-3cf2-bbdc-bd8ab36c598c")), GenerateAssetId(cw20))
+	assert.NotEqual(GenerateAssetId(pool), GenerateAssetId("gamm/pool/678"))
+}
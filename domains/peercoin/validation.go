@@ -0,0 +1,72 @@
+package peercoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/litecoin"
+)
+
+var (
+	PeercoinChainBase string
+	PeercoinChainId   crypto.Hash
+)
+
+func init() {
+	PeercoinChainBase = "42a38e35-7222-40bd-885c-7fa269189cfb"
+	PeercoinChainId = crypto.NewHash([]byte(PeercoinChainBase))
+}
+
+func VerifyAssetKey(assetKey string) error {
+	if assetKey == PeercoinChainBase {
+		return nil
+	}
+	return fmt.Errorf("invalid peercoin asset key %s", assetKey)
+}
+
+func VerifyAddress(address string) error {
+	if strings.TrimSpace(address) != address {
+		return fmt.Errorf("invalid peercoin address %s", address)
+	}
+	ppcAddress, err := litecoin.DecodeAddress(address, &mainNetParams)
+	if err != nil {
+		return err
+	}
+	if ppcAddress.String() != address {
+		return fmt.Errorf("invalid peercoin address %s", address)
+	}
+	return nil
+}
+
+func VerifyTransactionHash(hash string) error {
+	if len(hash) != 64 {
+		return fmt.Errorf("invalid peercoin transaction hash %s", hash)
+	}
+	if strings.ToLower(hash) != hash {
+		return fmt.Errorf("invalid peercoin transaction hash %s", hash)
+	}
+	h, err := hex.DecodeString(hash)
+	if err != nil {
+		return fmt.Errorf("invalid peercoin transaction hash %s %s", hash, err.Error())
+	}
+	if len(h) != 32 {
+		return fmt.Errorf("invalid peercoin transaction hash %s", hash)
+	}
+	return nil
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	switch assetKey {
+	case PeercoinChainBase:
+		return PeercoinChainId
+	default:
+		panic(assetKey)
+	}
+}
+
+var mainNetParams = litecoin.Params{
+	PubKeyHashAddrID: 0x37,
+	ScriptHashAddrID: 0x75,
+}
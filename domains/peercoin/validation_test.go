@@ -0,0 +1,41 @@
+package peercoin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	ppc := "42a38e35-7222-40bd-885c-7fa269189cfb"
+	tx := "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"
+	addrMain := "PDuFfku8SLPsz18Be95WXYVwh8Qiig2rXa"
+	addrScript := "pEm6BByZBpM3LD9aechmmQx1buX8N71j52"
+	addrMonacoin := "MNNDZdNXZ39afMwuje6Dc2i11Q3d2qVc9H"
+
+	assert.Nil(VerifyAssetKey(ppc))
+	assert.NotNil(VerifyAssetKey(tx))
+	assert.NotNil(VerifyAssetKey(addrMain))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(ppc)))
+
+	assert.Nil(VerifyAddress(addrMain))
+	assert.NotNil(VerifyAddress(ppc))
+	assert.NotNil(VerifyAddress(addrMain[1:]))
+	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.Nil(VerifyAddress(addrScript))
+	assert.NotNil(VerifyAddress(addrMonacoin))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash(ppc))
+	assert.NotNil(VerifyTransactionHash(addrMain))
+	assert.NotNil(VerifyTransactionHash("0x" + tx))
+	assert.NotNil(VerifyTransactionHash(strings.ToUpper(tx)))
+
+	assert.Equal(crypto.NewHash([]byte("42a38e35-7222-40bd-885c-7fa269189cfb")), GenerateAssetId(ppc))
+	assert.Equal(crypto.NewHash([]byte("42a38e35-7222-40bd-885c-7fa269189cfb")), PeercoinChainId)
+	assert.Equal(crypto.NewHash([]byte(PeercoinChainBase)), PeercoinChainId)
+}
@@ -0,0 +1,188 @@
+package polkadot
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const multiLocationSizeMaximum = 1024
+
+// VerifyParachainAssetKey verifies the asset of a parachain, in the form of
+// para id and asset id, e.g. 1000:1984 for USDT on the asset hub, both in
+// canonical decimal, or para id and the 0x prefixed lowercase hex of the
+// SCALE encoded XCM v3 multilocation of a foreign asset, which are used by
+// the asset hubs of both polkadot and kusama.
+func VerifyParachainAssetKey(assetKey string) error {
+	parts := strings.Split(assetKey, ":")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid parachain asset key %s", assetKey)
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || strconv.FormatUint(id, 10) != parts[0] {
+		return fmt.Errorf("invalid parachain asset key %s", assetKey)
+	}
+	if !strings.HasPrefix(parts[1], "0x") {
+		id, err = strconv.ParseUint(parts[1], 10, 32)
+		if err != nil || strconv.FormatUint(id, 10) != parts[1] {
+			return fmt.Errorf("invalid parachain asset key %s", assetKey)
+		}
+		return nil
+	}
+	location := parts[1][2:]
+	if strings.ToLower(location) != location {
+		return fmt.Errorf("invalid parachain foreign asset key %s", assetKey)
+	}
+	b, err := hex.DecodeString(location)
+	if err != nil || len(b) == 0 || len(b) > multiLocationSizeMaximum {
+		return fmt.Errorf("invalid parachain foreign asset key %s", assetKey)
+	}
+	err = verifyMultiLocation(b)
+	if err != nil {
+		return fmt.Errorf("invalid parachain foreign asset key %s %s", assetKey, err.Error())
+	}
+	return nil
+}
+
+// verifyMultiLocation decodes the SCALE encoded XCM v3 multilocation, which
+// must be canonical and consume all the bytes, so that a foreign asset has
+// only one asset key. The plurality junction is never an asset location.
+func verifyMultiLocation(b []byte) error {
+	d := &scaleDecoder{b: b}
+	d.fixed(1) // parents
+	n := d.byte()
+	if n > 8 {
+		return fmt.Errorf("invalid junctions %d", n)
+	}
+	for i := 0; i < int(n) && d.err == nil; i++ {
+		switch j := d.byte(); j {
+		case 0: // Parachain
+			d.compact(4)
+		case 1: // AccountId32
+			d.network()
+			d.fixed(32)
+		case 2: // AccountIndex64
+			d.network()
+			d.compact(8)
+		case 3: // AccountKey20
+			d.network()
+			d.fixed(20)
+		case 4: // PalletInstance
+			d.fixed(1)
+		case 5: // GeneralIndex
+			d.compact(16)
+		case 6: // GeneralKey
+			if l := d.byte(); l > 32 {
+				return fmt.Errorf("invalid general key length %d", l)
+			}
+			d.fixed(32)
+		case 7: // OnlyChild
+		case 9: // GlobalConsensus
+			d.networkId()
+		default:
+			return fmt.Errorf("invalid junction %d", j)
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return fmt.Errorf("invalid multilocation trailing %d", len(d.b))
+	}
+	return nil
+}
+
+type scaleDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *scaleDecoder) fixed(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.b) < n {
+		d.err = fmt.Errorf("invalid multilocation size %d %d", len(d.b), n)
+		return nil
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *scaleDecoder) byte() byte {
+	v := d.fixed(1)
+	if v == nil {
+		return 0
+	}
+	return v[0]
+}
+
+// compact decodes the SCALE compact integer of at most size bytes, and it
+// must be in the shortest mode.
+func (d *scaleDecoder) compact(size int) {
+	if d.err != nil || len(d.b) == 0 {
+		d.fixed(1)
+		return
+	}
+	switch d.b[0] & 3 {
+	case 0:
+		d.fixed(1)
+	case 1:
+		v := d.fixed(2)
+		if v != nil && binary.LittleEndian.Uint16(v)>>2 < 1<<6 {
+			d.err = fmt.Errorf("invalid compact %x", v)
+		}
+	case 2:
+		v := d.fixed(4)
+		if v != nil && binary.LittleEndian.Uint32(v)>>2 < 1<<14 {
+			d.err = fmt.Errorf("invalid compact %x", v)
+		}
+	case 3:
+		n := int(d.byte()>>2) + 4
+		if n > size {
+			d.err = fmt.Errorf("invalid compact size %d %d", n, size)
+			return
+		}
+		v := d.fixed(n)
+		if v == nil {
+			return
+		}
+		if v[n-1] == 0 || n == 4 && binary.LittleEndian.Uint32(v) < 1<<30 {
+			d.err = fmt.Errorf("invalid compact %x", v)
+		}
+	}
+}
+
+// network decodes the optional network id of the account junctions.
+func (d *scaleDecoder) network() {
+	switch o := d.byte(); o {
+	case 0:
+	case 1:
+		d.networkId()
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("invalid network option %d", o)
+		}
+	}
+}
+
+func (d *scaleDecoder) networkId() {
+	switch n := d.byte(); n {
+	case 0: // ByGenesis
+		d.fixed(32)
+	case 1: // ByFork
+		d.fixed(8)
+		d.fixed(32)
+	case 2, 3, 4, 5, 6: // Polkadot, Kusama, Westend, Rococo, Wococo
+	case 7: // Ethereum
+		d.compact(8)
+	case 8, 9: // BitcoinCore, BitcoinCash
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("invalid network id %d", n)
+		}
+	}
+}
@@ -1,15 +1,24 @@
 package polkadot
 
 import (
+	"bytes"
+	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/gofrs/uuid"
 	"golang.org/x/crypto/blake2b"
 )
 
+const (
+	NetworkPrefixPolkadot  = 0
+	NetworkPrefixSubstrate = 42
+)
+
 var (
 	PolkadotChainBase string
 	PolkadotChainId   crypto.Hash
@@ -20,35 +29,48 @@ func init() {
 	PolkadotChainId = crypto.NewHash([]byte(PolkadotChainBase))
 }
 
+// VerifyAssetKey accepts the chain base for DOT, or a parachain asset of
+// the pallet assets or the foreign assets on the asset hub.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == PolkadotChainBase {
 		return nil
 	}
-	return fmt.Errorf("invalid polkadot asset key %s", assetKey)
+	err := VerifyParachainAssetKey(assetKey)
+	if err != nil {
+		return fmt.Errorf("invalid polkadot asset key %s", assetKey)
+	}
+	return nil
 }
 
+// VerifyAddress accepts the SS58 address of the polkadot network prefix,
+// checked by the checksum only, so the account id of any size passes. The
+// 32 bytes account id is required by VerifyAccountAddress after the domain
+// fork.
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid polkadot address %s", address)
 	}
-	b := base58.Decode(address)
-	if len(b) < 8 {
-		return fmt.Errorf("invalid polkadot address %s", address)
-	}
-	if b[0] != byte(0) {
+	err := VerifyChecksumAddress(NetworkPrefixPolkadot, address)
+	if err != nil {
 		return fmt.Errorf("invalid polkadot address %s", address)
 	}
-	addr := base58.Encode(b)
-	if addr != address {
-		return fmt.Errorf("invalid polkadot address %s", address)
+	return nil
+}
+
+// VerifyAccountAddress checks the address by the stricter rules of the
+// domain fork, the address must be the 32 bytes account id of the polkadot
+// network prefix.
+func VerifyAccountAddress(address string) error {
+	err := VerifyAddress(address)
+	if err != nil {
+		return err
 	}
-	public := b[1 : len(b)-2]
-	addr, err := PublicKeyToAddress(public)
+	prefix, _, err := DecodeAddress(address)
 	if err != nil {
-		return fmt.Errorf("invalid polkadot address %s", address)
+		return err
 	}
-	if addr != address {
-		return fmt.Errorf("invalid polkadot address %s", address)
+	if prefix != NetworkPrefixPolkadot {
+		return fmt.Errorf("invalid polkadot address network %s %d", address, prefix)
 	}
 	return nil
 }
@@ -74,26 +96,116 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case PolkadotChainBase:
-		return PolkadotChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == PolkadotChainBase {
+		return PolkadotChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, PolkadotChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
 }
 
 var ss58Prefix = []byte("SS58PRE")
 
 func PublicKeyToAddress(pub []byte) (string, error) {
-	enc := append([]byte{0}, pub...)
-	hasher, err := blake2b.New(64, nil)
+	return EncodeAddress(NetworkPrefixPolkadot, pub)
+}
+
+// EncodeAddress encodes the 32 bytes account id to a SS58 address, with
+// the one byte network prefix below 64, or the two bytes one up to 16383.
+func EncodeAddress(prefix uint16, pub []byte) (string, error) {
+	if len(pub) != 32 {
+		return "", fmt.Errorf("invalid polkadot public key size %d", len(pub))
+	}
+	var enc []byte
+	switch {
+	case prefix < 64:
+		enc = []byte{byte(prefix)}
+	case prefix < 16384:
+		enc = []byte{
+			byte((prefix&0xfc)>>2) | 0x40,
+			byte(prefix>>8) | byte(prefix&0x03)<<6,
+		}
+	default:
+		return "", fmt.Errorf("invalid polkadot network prefix %d", prefix)
+	}
+	enc = append(enc, pub...)
+	checksum, err := ss58Checksum(enc)
 	if err != nil {
 		return "", err
 	}
-	_, err = hasher.Write(append(ss58Prefix, enc...))
+	return base58.Encode(append(enc, checksum...)), nil
+}
+
+// DecodeAddress decodes a SS58 address of any network prefix, and returns
+// the prefix and the 32 bytes account id.
+func DecodeAddress(address string) (uint16, []byte, error) {
+	b := base58.Decode(address)
+	if len(b) < 3 || base58.Encode(b) != address {
+		return 0, nil, fmt.Errorf("invalid polkadot address %s", address)
+	}
+	var prefix uint16
+	var offset int
+	switch {
+	case b[0] < 64:
+		prefix, offset = uint16(b[0]), 1
+	case b[0] < 128:
+		lower := (b[0]&0x3f)<<2 | b[1]>>6
+		upper := b[1] & 0x3f
+		prefix, offset = uint16(lower)|uint16(upper)<<8, 2
+	default:
+		return 0, nil, fmt.Errorf("invalid polkadot address prefix %s", address)
+	}
+	if len(b) != offset+32+2 {
+		return 0, nil, fmt.Errorf("invalid polkadot address size %s", address)
+	}
+	pub := b[offset : len(b)-2]
+	addr, err := EncodeAddress(prefix, pub)
+	if err != nil || addr != address {
+		return 0, nil, fmt.Errorf("invalid polkadot address %s", address)
+	}
+	return prefix, pub, nil
+}
+
+// VerifyChecksumAddress checks the one byte network prefix and the checksum
+// of the SS58 address, without the size of the account id.
+func VerifyChecksumAddress(prefix byte, address string) error {
+	b := base58.Decode(address)
+	if len(b) < 8 || b[0] != prefix || base58.Encode(b) != address {
+		return fmt.Errorf("invalid ss58 address %s", address)
+	}
+	checksum, err := ss58Checksum(b[:len(b)-2])
 	if err != nil {
-		return "", err
+		return err
+	}
+	if !bytes.Equal(checksum, b[len(b)-2:]) {
+		return fmt.Errorf("invalid ss58 address checksum %s", address)
+	}
+	return nil
+}
+
+func ss58Checksum(enc []byte) ([]byte, error) {
+	hasher, err := blake2b.New(64, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = hasher.Write(ss58Prefix)
+	if err != nil {
+		return nil, err
+	}
+	_, err = hasher.Write(enc)
+	if err != nil {
+		return nil, err
 	}
-	checksum := hasher.Sum(nil)
-	return base58.Encode(append(enc, checksum[:2]...)), nil
+	return hasher.Sum(nil)[:2], nil
 }
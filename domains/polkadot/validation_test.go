@@ -1,10 +1,12 @@
 package polkadot
 
 import (
+	"encoding/hex"
 	"strings"
 	"testing"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,9 +23,12 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(dot)))
 
 	assert.Nil(VerifyAddress(addrMain))
+	assert.Nil(VerifyAccountAddress(addrMain))
 	assert.NotNil(VerifyAddress(dot))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.NotNil(VerifyAddress("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"))
+	assert.NotNil(VerifyAddress("HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F"))
 
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(dot))
@@ -34,4 +39,76 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("54c61a72-b982-4034-a556-0d99e3c21e39")), GenerateAssetId(dot))
 	assert.Equal(crypto.NewHash([]byte("54c61a72-b982-4034-a556-0d99e3c21e39")), PolkadotChainId)
 	assert.Equal(crypto.NewHash([]byte(PolkadotChainBase)), PolkadotChainId)
+
+	usdt := "1000:1984"
+	assert.Nil(VerifyAssetKey(usdt))
+	assert.NotNil(VerifyAssetKey("1000:01984"))
+	assert.NotNil(VerifyAssetKey("1000:"))
+	assert.NotNil(VerifyAssetKey("1000:1984:1"))
+	assert.NotNil(VerifyAssetKey("1000:-1984"))
+	assert.NotNil(VerifyAssetKey("1000:4294967296"))
+	assert.NotEqual(PolkadotChainId, GenerateAssetId(usdt))
+	assert.Equal(GenerateAssetId(usdt), GenerateAssetId("1000:1984"))
+	assert.NotEqual(GenerateAssetId(usdt), GenerateAssetId("1000:1337"))
+	assert.Equal(crypto.NewHash([]byte("6489fcfa-7017-31af-958b-e68b6536a499")), GenerateAssetId(usdt))
+
+	ksm := "1000:0x02010903"
+	para := "1000:0x010300511f040a05011f"
+	eth := "1000:0x02020907040300dac17f958d2ee523a2206206994597c13d831ec7"
+	for _, k := range []string{ksm, para, eth} {
+		assert.Nil(VerifyAssetKey(k))
+		assert.NotEqual(PolkadotChainId, GenerateAssetId(k))
+	}
+	assert.NotEqual(GenerateAssetId(ksm), GenerateAssetId(para))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(eth)))
+	assert.NotNil(VerifyAssetKey("1000:0x"))
+	assert.NotNil(VerifyAssetKey("1000:0x0101"))
+	assert.NotNil(VerifyAssetKey("1000:0x0101001500"))
+	assert.NotNil(VerifyAssetKey("1000:0x0201090300"))
+	assert.NotNil(VerifyAssetKey("1000:0x0109"))
+	assert.NotNil(VerifyAssetKey("1000:0x01010800"))
+	assert.NotNil(VerifyAssetKey("1000:0x0101090a"))
+	assert.NotNil(VerifyAssetKey("1000:0x0201070203"))
+}
+
+func TestSS58(t *testing.T) {
+	assert := assert.New(t)
+
+	pub, _ := hex.DecodeString("d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+	vectors := map[uint16]string{
+		0:    "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5",
+		2:    "HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F",
+		42:   "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY",
+		1284: "VdvKmYJfD4VXA9fzz1SbmCo2eYHSzUFbaDCZSuaNKJAe8YNg6",
+		2007: "tsYiidXGXmwsjaTrJpA6Z9YuqRipRjVqMrkikExNnpsVoa9cL",
+	}
+	for prefix, address := range vectors {
+		addr, err := EncodeAddress(prefix, pub)
+		assert.Nil(err)
+		assert.Equal(address, addr)
+		p, public, err := DecodeAddress(address)
+		assert.Nil(err)
+		assert.Equal(prefix, p)
+		assert.Equal(pub, public)
+	}
+	addr, err := PublicKeyToAddress(pub)
+	assert.Nil(err)
+	assert.Equal(vectors[NetworkPrefixPolkadot], addr)
+	assert.Nil(VerifyAddress(addr))
+
+	_, err = EncodeAddress(16384, pub)
+	assert.NotNil(err)
+	_, err = EncodeAddress(0, pub[1:])
+	assert.NotNil(err)
+	_, _, err = DecodeAddress(vectors[1284][:len(vectors[1284])-1])
+	assert.NotNil(err)
+
+	enc := append([]byte{NetworkPrefixPolkadot}, pub[:5]...)
+	checksum, err := ss58Checksum(enc)
+	assert.Nil(err)
+	short := base58.Encode(append(enc, checksum...))
+	assert.Nil(VerifyAddress(short))
+	assert.NotNil(VerifyAccountAddress(short))
+	checksum[0] ^= 1
+	assert.NotNil(VerifyAddress(base58.Encode(append(enc, checksum...))))
 }
@@ -1,159 +1,47 @@
 package polygon
 
 import (
-	"crypto/md5"
 	"encoding/hex"
-	"fmt"
-	"io"
-	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
-	"github.com/gofrs/uuid"
-	"golang.org/x/crypto/sha3"
+	"github.com/MixinNetwork/mixin/domains/evm"
 )
 
 var (
 	PolygonChainBase string
 	PolygonChainId   crypto.Hash
+
+	chain *evm.Chain
 )
 
+// The polygon chain id, asset keys and confirmations are of its entry in the
+// evm chain table, which the common package validates by evm.Lookup.
 func init() {
-	PolygonChainBase = "b7938396-3f94-4e0a-9179-d3440718156f"
-	PolygonChainId = crypto.NewHash([]byte(PolygonChainBase))
+	chain = evm.LookupNumber(137)
+	PolygonChainBase = chain.ChainBase
+	PolygonChainId = chain.ChainId
 }
 
 func VerifyAssetKey(assetKey string) error {
-	if len(assetKey) != 42 {
-		return fmt.Errorf("invalid polygon asset key %s", assetKey)
-	}
-	if !strings.HasPrefix(assetKey, "0x") {
-		return fmt.Errorf("invalid polygon asset key %s", assetKey)
-	}
-	if assetKey != strings.ToLower(assetKey) {
-		return fmt.Errorf("invalid polygon asset key %s", assetKey)
-	}
-	k, err := hex.DecodeString(assetKey[2:])
-	if err != nil {
-		return fmt.Errorf("invalid polygon asset key %s %s", assetKey, err.Error())
-	}
-	if len(k) != 20 {
-		return fmt.Errorf("invalid polygon asset key %s", assetKey)
-	}
-	return nil
+	return chain.VerifyAssetKey(assetKey)
 }
 
 func VerifyAddress(address string) error {
-	if strings.TrimSpace(address) != address {
-		return fmt.Errorf("invalid polygon address %s", address)
-	}
-	if len(address) != 42 {
-		return fmt.Errorf("invalid polygon address %s", address)
-	}
-	if !strings.HasPrefix(address, "0x") {
-		return fmt.Errorf("invalid polygon address %s", address)
-	}
-	form, err := formatAddress(address)
-	if err != nil {
-		return fmt.Errorf("invalid polygon address %s", address)
-	}
-	if form != address {
-		return fmt.Errorf("invalid polygon address %s", address)
-	}
-	a, err := hex.DecodeString(address[2:])
-	if err != nil {
-		return fmt.Errorf("invalid polygon address %s %s", address, err.Error())
-	}
-	if len(a) != 20 {
-		return fmt.Errorf("invalid polygon address %s", address)
-	}
-	return nil
+	return chain.VerifyAddress(address)
 }
 
 func VerifyTransactionHash(hash string) error {
-	if len(hash) != 66 {
-		return fmt.Errorf("invalid polygon transaction hash %s", hash)
-	}
-	if !strings.HasPrefix(hash, "0x") {
-		return fmt.Errorf("invalid polygon transaction hash %s", hash)
-	}
-	if strings.ToLower(hash) != hash {
-		return fmt.Errorf("invalid polygon transaction hash %s", hash)
-	}
-	h, err := hex.DecodeString(hash[2:])
-	if err != nil {
-		return fmt.Errorf("invalid polygon transaction hash %s %s", hash, err.Error())
-	}
-	if len(h) != 32 {
-		return fmt.Errorf("invalid polygon transaction hash %s", hash)
-	}
-	return nil
+	return chain.VerifyTransactionHash(hash)
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	err := VerifyAssetKey(assetKey)
-	if err != nil {
-		panic(assetKey)
-	}
-
-	if assetKey == "0x0000000000000000000000000000000000001010" {
-		return PolygonChainId
-	}
-
-	h := md5.New()
-	io.WriteString(h, PolygonChainBase)
-	io.WriteString(h, assetKey)
-	sum := h.Sum(nil)
-	sum[6] = (sum[6] & 0x0f) | 0x30
-	sum[8] = (sum[8] & 0x3f) | 0x80
-	id := uuid.FromBytesOrNil(sum).String()
-	return crypto.NewHash([]byte(id))
+	return chain.GenerateAssetId(assetKey)
 }
 
-const (
-	AddressLength = 20
-)
-
-type Address [AddressLength]byte
-
 func formatAddress(to string) (string, error) {
-	var bytesto [20]byte
-	_bytesto, err := hex.DecodeString(to[2:])
+	a, err := hex.DecodeString(to[2:])
 	if err != nil {
 		return "", err
 	}
-	copy(bytesto[:], _bytesto)
-	address := Address(bytesto)
-	return address.Hex(), nil
-}
-
-func (a *Address) Hex() string {
-	return string(a.checksumHex())
-}
-
-func (a *Address) hex() []byte {
-	var buf [len(a)*2 + 2]byte
-	copy(buf[:2], "0x")
-	hex.Encode(buf[2:], a[:])
-	return buf[:]
-}
-
-func (a *Address) checksumHex() []byte {
-	buf := a.hex()
-
-	// compute checksum
-	sha := sha3.NewLegacyKeccak256()
-	sha.Write(buf[2:])
-	hash := sha.Sum(nil)
-	for i := 2; i < len(buf); i++ {
-		hashByte := hash[(i-2)/2]
-		if i%2 == 0 {
-			hashByte = hashByte >> 4
-		} else {
-			hashByte &= 0xf
-		}
-		if buf[i] > '9' && hashByte > 7 {
-			buf[i] -= 32
-		}
-	}
-	return buf[:]
+	return evm.ChecksumAddress(a), nil
 }
@@ -5,4 +5,6 @@ const (
 
 	RIPPLE_ACCOUNT_ID_VERSION = 0
 	RIPPLE_ACCOUNT_ID_LENGTH  = 20
+
+	currencyCodeSymbols = "?!@#$%^&*<>(){}[]|"
 )
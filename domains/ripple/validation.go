@@ -1,11 +1,15 @@
 package ripple
 
 import (
+	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/gofrs/uuid"
 )
 
 var (
@@ -18,11 +22,28 @@ func init() {
 	RippleChainId = crypto.NewHash([]byte(RippleChainBase))
 }
 
+// VerifyAssetKey accepts the XRP chain base, or an issued currency as the
+// currency:issuer pair, whose currency is the 3 characters standard code
+// other than XRP, or the 40 uppercase hex nonstandard code not starting
+// with the zero byte, and issuer is the r account address.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == RippleChainBase {
 		return nil
 	}
-	return fmt.Errorf("invalid ripple asset key %s", assetKey)
+	parts := strings.Split(assetKey, ":")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid ripple asset key %s", assetKey)
+	}
+	currency, issuer := parts[0], parts[1]
+	err := verifyCurrencyCode(currency)
+	if err != nil {
+		return fmt.Errorf("invalid ripple asset key %s %s", assetKey, err)
+	}
+	err = VerifyAddress(issuer)
+	if err != nil {
+		return fmt.Errorf("invalid ripple asset key %s", assetKey)
+	}
+	return nil
 }
 
 func VerifyAddress(address string) error {
@@ -46,6 +67,24 @@ func VerifyAddress(address string) error {
 	return nil
 }
 
+// VerifyDestinationTag accepts the canonical decimal of an unsigned 32 bits
+// integer, which the exchanges and custodians share one address with.
+func VerifyDestinationTag(tag string) error {
+	if len(tag) > 1 && tag[0] == '0' {
+		return fmt.Errorf("invalid ripple destination tag %s", tag)
+	}
+	for _, c := range tag {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("invalid ripple destination tag %s", tag)
+		}
+	}
+	_, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid ripple destination tag %s", tag)
+	}
+	return nil
+}
+
 func VerifyTransactionHash(hash string) error {
 	if strings.TrimSpace(hash) != hash {
 		return fmt.Errorf("invalid ripple transaction hash %s", hash)
@@ -67,11 +106,51 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case RippleChainBase:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
+		panic(assetKey)
+	}
+
+	if assetKey == RippleChainBase {
 		return RippleChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, RippleChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
+
+func verifyCurrencyCode(code string) error {
+	switch len(code) {
+	case 3:
+		if code == "XRP" {
+			return fmt.Errorf("native currency code %s", code)
+		}
+		for _, c := range code {
+			if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (c < '0' || c > '9') && !strings.ContainsRune(currencyCodeSymbols, c) {
+				return fmt.Errorf("invalid currency code %s", code)
+			}
+		}
+		return nil
+	case 40:
+		if strings.ToUpper(code) != code {
+			return fmt.Errorf("invalid currency code %s", code)
+		}
+		b, err := hex.DecodeString(code)
+		if err != nil {
+			return fmt.Errorf("invalid currency code %s", code)
+		}
+		if b[0] == 0 {
+			return fmt.Errorf("invalid currency code %s", code)
+		}
+		return nil
 	default:
-		panic(assetKey)
+		return fmt.Errorf("invalid currency code %s", code)
 	}
 }
 
@@ -20,11 +20,34 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAssetKey(addrMain))
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(xrp)))
 
+	usd := "USD:rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"
+	rlusd := "524C555344000000000000000000000000000000:rMxCKbEDwqr76QuheSUMdEGf4B9xJ8m5De"
+	assert.Nil(VerifyAssetKey(usd))
+	assert.Nil(VerifyAssetKey(rlusd))
+	assert.Nil(VerifyAssetKey("$$$:rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"))
+	assert.NotNil(VerifyAssetKey("XRP:rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"))
+	assert.NotNil(VerifyAssetKey("USDC:rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"))
+	assert.NotNil(VerifyAssetKey("US D:rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"))
+	assert.NotNil(VerifyAssetKey("USD:" + addrMain[1:]))
+	assert.NotNil(VerifyAssetKey("USD:rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B:1"))
+	assert.NotNil(VerifyAssetKey(strings.ToLower(rlusd[:40]) + rlusd[40:]))
+	assert.NotNil(VerifyAssetKey("00" + rlusd[2:]))
+
 	assert.Nil(VerifyAddress(addrMain))
 	assert.NotNil(VerifyAddress(xrp))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
 
+	assert.Nil(VerifyDestinationTag("0"))
+	assert.Nil(VerifyDestinationTag("123456"))
+	assert.Nil(VerifyDestinationTag("4294967295"))
+	assert.NotNil(VerifyDestinationTag(""))
+	assert.NotNil(VerifyDestinationTag("0123"))
+	assert.NotNil(VerifyDestinationTag("+123"))
+	assert.NotNil(VerifyDestinationTag("-1"))
+	assert.NotNil(VerifyDestinationTag("4294967296"))
+	assert.NotNil(VerifyDestinationTag(" 123"))
+
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(xrp))
 	assert.NotNil(VerifyTransactionHash(addrMain))
@@ -34,4 +57,6 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("23dfb5a5-5d7b-48b6-905f-3970e3176e27")), GenerateAssetId(xrp))
 	assert.Equal(crypto.NewHash([]byte("23dfb5a5-5d7b-48b6-905f-3970e3176e27")), RippleChainId)
 	assert.Equal(crypto.NewHash([]byte(RippleChainBase)), RippleChainId)
+	assert.Equal(crypto.NewHash([]byte("f7dbb47c-57ee-3567-a498-f48fce18cb55")), GenerateAssetId(usd))
+	assert.Equal(crypto.NewHash([]byte("e1b63daa-9584-38dd-a471-a97cb511b1d7")), GenerateAssetId(rlusd))
 }
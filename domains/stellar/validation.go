@@ -2,12 +2,15 @@ package stellar
 
 import (
 	"crypto/ed25519"
+	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strings"
 
 	"filippo.io/edwards25519"
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/gofrs/uuid"
 )
 
 var (
@@ -20,11 +23,31 @@ func init() {
 	StellarChainId = crypto.NewHash([]byte(StellarChainBase))
 }
 
+// VerifyAssetKey accepts the XLM chain base, or an issued asset as the
+// code:issuer pair, whose code is 1 to 12 alphanumeric characters and
+// issuer is the G account address.
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == StellarChainBase {
 		return nil
 	}
-	return fmt.Errorf("invalid stellar asset key %s", assetKey)
+	parts := strings.Split(assetKey, ":")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid stellar asset key %s", assetKey)
+	}
+	code, issuer := parts[0], parts[1]
+	if len(code) < 1 || len(code) > 12 {
+		return fmt.Errorf("invalid stellar asset key %s", assetKey)
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("invalid stellar asset key %s", assetKey)
+		}
+	}
+	err := VerifyAddress(issuer)
+	if err != nil {
+		return fmt.Errorf("invalid stellar asset key %s", assetKey)
+	}
+	return nil
 }
 
 func VerifyAddress(address string) error {
@@ -39,11 +62,11 @@ func VerifyAddress(address string) error {
 		return fmt.Errorf("invalid stellar address %s %s", address, err)
 	}
 	if len(payload) != ed25519.PublicKeySize {
-		return fmt.Errorf("invalid near address length %s", address)
+		return fmt.Errorf("invalid stellar address length %s", address)
 	}
 	_, err = edwards25519.NewIdentityPoint().SetBytes(payload)
 	if err != nil {
-		return fmt.Errorf("invalid near address %s", address)
+		return fmt.Errorf("invalid stellar address %s", address)
 	}
 	return nil
 }
@@ -69,10 +92,21 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case StellarChainBase:
-		return StellarChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == StellarChainBase {
+		return StellarChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, StellarChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
 }
@@ -20,6 +20,18 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAssetKey(addrMain))
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(xlm)))
 
+	usdc := "USDC:GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN"
+	assert.Nil(VerifyAssetKey(usdc))
+	assert.Nil(VerifyAssetKey("yXLM:" + addrMain))
+	assert.Nil(VerifyAssetKey("ABCDEFGHIJ12:" + addrMain))
+	assert.NotNil(VerifyAssetKey("ABCDEFGHIJ123:" + addrMain))
+	assert.NotNil(VerifyAssetKey(":" + addrMain))
+	assert.NotNil(VerifyAssetKey("US-DC:" + addrMain))
+	assert.NotNil(VerifyAssetKey("USDC:" + addrMain[1:]))
+	assert.NotNil(VerifyAssetKey("USDC:" + strings.ToLower(addrMain)))
+	assert.NotNil(VerifyAssetKey("USDC:" + addrMain + ":" + addrMain))
+	assert.NotNil(VerifyAssetKey("USDC"))
+
 	assert.Nil(VerifyAddress(addrMain))
 	assert.NotNil(VerifyAddress(xlm))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
@@ -34,4 +46,5 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("56e63c06-b506-4ec5-885a-4a5ac17b83c1")), GenerateAssetId(xlm))
 	assert.Equal(crypto.NewHash([]byte("56e63c06-b506-4ec5-885a-4a5ac17b83c1")), StellarChainId)
 	assert.Equal(crypto.NewHash([]byte(StellarChainBase)), StellarChainId)
+	assert.Equal(crypto.NewHash([]byte("643a36d6-1929-3777-9464-96227f9598be")), GenerateAssetId(usdc))
 }
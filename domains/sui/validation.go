@@ -0,0 +1,105 @@
+package sui
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/gofrs/uuid"
+)
+
+var (
+	SuiChainBase string
+	SuiChainId   crypto.Hash
+)
+
+const NativeCoinType = "0x2::sui::SUI"
+
+var moveIdentifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+func init() {
+	SuiChainBase = "53ee2b13-6362-4810-bf1f-579577d5e8b0"
+	SuiChainId = crypto.NewHash([]byte(SuiChainBase))
+}
+
+// VerifyAssetKey accepts either a coin type "0x<package>::<module>::<name>"
+// for fungible assets, or a bare object id "0x<id>" for the non-fungible
+// objects, e.g. a NFT, each object is an asset with supply 1.
+func VerifyAssetKey(assetKey string) error {
+	if assetKey == NativeCoinType {
+		return nil
+	}
+	if IsNonFungibleKey(assetKey) {
+		return nil
+	}
+	parts := strings.Split(assetKey, "::")
+	if len(parts) != 3 || verifyObjectId(parts[0]) != nil {
+		return fmt.Errorf("invalid sui asset key %s", assetKey)
+	}
+	if !moveIdentifier.MatchString(parts[1]) || !moveIdentifier.MatchString(parts[2]) {
+		return fmt.Errorf("invalid sui asset key %s", assetKey)
+	}
+	return nil
+}
+
+func IsNonFungibleKey(assetKey string) bool {
+	return verifyObjectId(assetKey) == nil
+}
+
+func VerifyAddress(address string) error {
+	err := verifyObjectId(address)
+	if err != nil {
+		return fmt.Errorf("invalid sui address %s", address)
+	}
+	return nil
+}
+
+func VerifyTransactionHash(hash string) error {
+	if strings.TrimSpace(hash) != hash {
+		return fmt.Errorf("invalid sui transaction hash %s", hash)
+	}
+	h := base58.Decode(hash)
+	if len(h) != 32 {
+		return fmt.Errorf("invalid sui transaction hash %s", hash)
+	}
+	if base58.Encode(h) != hash {
+		return fmt.Errorf("invalid sui transaction hash %s", hash)
+	}
+	return nil
+}
+
+func GenerateAssetId(assetKey string) crypto.Hash {
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
+		panic(assetKey)
+	}
+
+	if assetKey == NativeCoinType {
+		return SuiChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, SuiChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
+}
+
+func verifyObjectId(id string) error {
+	if !strings.HasPrefix(id, "0x") || len(id) != 66 {
+		return fmt.Errorf("invalid sui object id %s", id)
+	}
+	if strings.ToLower(id) != id {
+		return fmt.Errorf("invalid sui object id %s", id)
+	}
+	_, err := hex.DecodeString(id[2:])
+	return err
+}
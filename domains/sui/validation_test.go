@@ -0,0 +1,51 @@
+package sui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	sui := "0x2::sui::SUI"
+	coin := "0x5d4b302506645c37ff133b98c4b50a5ae14841659738d6d733d59d0d217a93bf::coin::COIN"
+	nft := "0x7d20dcdb2bca4f508ea9613994683eb4e76e9c4ed371169677c1be02aaf0b58e"
+	tx := "3SivNwfPYsaSgWj8jLNvd567sVZJCDoagsobDEMkQsHT"
+
+	assert.Nil(VerifyAssetKey(sui))
+	assert.Nil(VerifyAssetKey(coin))
+	assert.Nil(VerifyAssetKey(nft))
+	assert.NotNil(VerifyAssetKey(strings.ToUpper(nft)))
+	assert.NotNil(VerifyAssetKey(nft[2:]))
+	assert.NotNil(VerifyAssetKey(nft + "::coin"))
+	assert.NotNil(VerifyAssetKey(nft + "::coin::"))
+	assert.NotNil(VerifyAssetKey(nft + "::1coin::COIN"))
+	assert.NotNil(VerifyAssetKey("0x2::sui::sui"))
+	assert.NotNil(VerifyAssetKey(tx))
+
+	assert.False(IsNonFungibleKey(sui))
+	assert.False(IsNonFungibleKey(coin))
+	assert.True(IsNonFungibleKey(nft))
+
+	assert.Nil(VerifyAddress(nft))
+	assert.NotNil(VerifyAddress(sui))
+	assert.NotNil(VerifyAddress(nft[1:]))
+	assert.NotNil(VerifyAddress(nft[:65]))
+	assert.NotNil(VerifyAddress(strings.ToUpper(nft)))
+
+	assert.Nil(VerifyTransactionHash(tx))
+	assert.NotNil(VerifyTransactionHash(tx[:40]))
+	assert.NotNil(VerifyTransactionHash("0" + tx[1:]))
+	assert.NotNil(VerifyTransactionHash(nft))
+	assert.NotNil(VerifyTransactionHash(" " + tx))
+
+	assert.Equal(crypto.NewHash([]byte("53ee2b13-6362-4810-bf1f-579577d5e8b0")), GenerateAssetId(sui))
+	assert.Equal(crypto.NewHash([]byte("3df9d513-6a57-3ddd-9927-20fffca4fc0b")), GenerateAssetId(coin))
+	assert.Equal(crypto.NewHash([]byte("5f735518-6e40-3353-8ad8-3d69d742dc42")), GenerateAssetId(nft))
+	assert.Equal(crypto.NewHash([]byte("53ee2b13-6362-4810-bf1f-579577d5e8b0")), SuiChainId)
+	assert.Equal(crypto.NewHash([]byte(SuiChainBase)), SuiChainId)
+}
@@ -2,13 +2,17 @@ package tezos
 
 import (
 	"bytes"
+	"crypto/md5"
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"strings"
 
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/gofrs/uuid"
 	"golang.org/x/crypto/ripemd160"
 )
 
@@ -17,7 +21,12 @@ var (
 	TezosChainId   crypto.Hash
 
 	tz1Prefix = []byte{6, 161, 159}
+	tz2Prefix = []byte{6, 161, 161}
+	tz3Prefix = []byte{6, 161, 164}
+	kt1Prefix = []byte{2, 90, 121}
 	opPrefix  = []byte{5, 116}
+
+	addressPrefixes = [][]byte{tz1Prefix, tz2Prefix, tz3Prefix, kt1Prefix}
 )
 
 func init() {
@@ -25,26 +34,38 @@ func init() {
 	TezosChainId = crypto.NewHash([]byte(TezosChainBase))
 }
 
+// VerifyAssetKey accepts the FA1.2 contract address, or the FA2 contract
+// address with the decimal token id, e.g. KT1XnTn74bUtxHfDtBmm2bGZAQfhPbvKWR8o:0
 func VerifyAssetKey(assetKey string) error {
 	if assetKey == TezosChainBase {
 		return nil
 	}
-	return fmt.Errorf("invalid tezos asset key %s", assetKey)
+	parts := strings.Split(assetKey, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("invalid tezos asset key %s", assetKey)
+	}
+	prefix, err := verifyAddress(parts[0])
+	if err != nil || !bytes.Equal(prefix, kt1Prefix) {
+		return fmt.Errorf("invalid tezos asset key %s", assetKey)
+	}
+	if len(parts) == 1 {
+		return nil
+	}
+	token, ok := new(big.Int).SetString(parts[1], 10)
+	if !ok || token.Sign() < 0 || token.String() != parts[1] {
+		return fmt.Errorf("invalid tezos asset key %s", assetKey)
+	}
+	return nil
 }
 
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid tezos address %s", address)
 	}
-	address = strings.TrimSpace(address)
-	decoded, err := decodeAddress(address)
+	_, err := verifyAddress(address)
 	if err != nil {
 		return fmt.Errorf("invalid tezos address %s %s", address, err)
 	}
-	xtzAddress := CheckEncode(decoded, tz1Prefix[:])
-	if xtzAddress != address {
-		return fmt.Errorf("invalid tezos address %s", address)
-	}
 	return nil
 }
 
@@ -66,26 +87,44 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case TezosChainBase:
-		return TezosChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+
+	if assetKey == TezosChainBase {
+		return TezosChainId
+	}
+
+	h := md5.New()
+	io.WriteString(h, TezosChainBase)
+	io.WriteString(h, assetKey)
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x30
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	id := uuid.FromBytesOrNil(sum).String()
+	return crypto.NewHash([]byte(id))
 }
 
-func decodeAddress(address string) ([]byte, error) {
-	decoded, netID, err := CheckDecode(address, len(tz1Prefix))
+// verifyAddress returns the prefix of the tz1, tz2, tz3 or KT1 address.
+func verifyAddress(address string) ([]byte, error) {
+	decoded, prefix, err := CheckDecode(address, len(tz1Prefix))
 	if err != nil {
 		return nil, err
 	}
 	if len(decoded) != ripemd160.Size {
 		return nil, fmt.Errorf("decode address is of unknown size %d", len(decoded))
 	}
-	if bytes.Compare(netID, tz1Prefix) != 0 {
-		return nil, fmt.Errorf("decode address is of unknown prefix %x", netID)
+	for _, p := range addressPrefixes {
+		if !bytes.Equal(prefix, p) {
+			continue
+		}
+		if CheckEncode(decoded, p) != address {
+			return nil, fmt.Errorf("decode address is not canonical %s", address)
+		}
+		return prefix, nil
 	}
-	return decoded, nil
+	return nil, fmt.Errorf("decode address is of unknown prefix %x", prefix)
 }
 
 // ErrChecksum indicates that the checksum of a check-encoded string does not verify against
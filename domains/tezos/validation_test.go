@@ -14,16 +14,35 @@ func TestValidation(t *testing.T) {
 	xtz := "5649ca42-eb5f-4c0e-ae28-d9a4e77eded3"
 	tx := "oodYJNMcvbi1uyVVE6c14LWU64mwtTw4n444L8rwsGmg6oT5kuB"
 	addrMain := "tz1LNGzjz8H9juHNrHLKbZ1fm7un3KJpxsFY"
+	addrTz2 := "tz2BFTyPeYRzxd5aiBchbXN3WCZhx7BqbMBq"
+	addrTz3 := "tz3WXYtyDUNL91qfiCJtVUX746QpNv5i5ve5"
+	usdtz := "KT1LN4LPSqTMS7Sd2CJw4bbDGRkMv2t68Fy9"
+	usdt := "KT1XnTn74bUtxHfDtBmm2bGZAQfhPbvKWR8o"
 
 	assert.Nil(VerifyAssetKey(xtz))
 	assert.NotNil(VerifyAssetKey(tx))
 	assert.NotNil(VerifyAssetKey(addrMain))
 	assert.NotNil(VerifyAssetKey(strings.ToUpper(xtz)))
+	assert.Nil(VerifyAssetKey(usdtz))
+	assert.Nil(VerifyAssetKey(usdt + ":0"))
+	assert.Nil(VerifyAssetKey(usdt + ":18446744073709551616"))
+	assert.NotNil(VerifyAssetKey(usdt + ":"))
+	assert.NotNil(VerifyAssetKey(usdt + ":01"))
+	assert.NotNil(VerifyAssetKey(usdt + ":-1"))
+	assert.NotNil(VerifyAssetKey(usdt + ":0:0"))
+	assert.NotNil(VerifyAssetKey(addrTz2))
+	assert.NotNil(VerifyAssetKey(addrMain + ":0"))
+	assert.NotNil(VerifyAssetKey(usdt[1:]))
 
 	assert.Nil(VerifyAddress(addrMain))
 	assert.NotNil(VerifyAddress(xtz))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 	assert.NotNil(VerifyAddress(strings.ToLower(addrMain)))
+	assert.Nil(VerifyAddress(addrTz2))
+	assert.Nil(VerifyAddress(addrTz3))
+	assert.Nil(VerifyAddress(usdtz))
+	assert.NotNil(VerifyAddress(usdt + ":0"))
+	assert.NotNil(VerifyAddress(addrTz3[:len(addrTz3)-1] + "6"))
 
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(xtz))
@@ -34,4 +53,7 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("5649ca42-eb5f-4c0e-ae28-d9a4e77eded3")), GenerateAssetId(xtz))
 	assert.Equal(crypto.NewHash([]byte("5649ca42-eb5f-4c0e-ae28-d9a4e77eded3")), TezosChainId)
 	assert.Equal(crypto.NewHash([]byte(TezosChainBase)), TezosChainId)
+	assert.Equal(crypto.NewHash([]byte("18d0aeb2-1ce8-398f-a3d2-38f3e5c70f4c")), GenerateAssetId(usdtz))
+	assert.Equal(crypto.NewHash([]byte("12eb3605-5b34-3878-9373-e81b56f40fe8")), GenerateAssetId(usdt+":0"))
+	assert.Panics(func() { GenerateAssetId(addrMain) })
 }
@@ -49,6 +49,19 @@ func VerifyAssetKey(assetKey string) error {
 	return nil
 }
 
+// VerifyTokenId checks the TRC-10 asset key is an unsigned token id from
+// 1000001, which is a stricter rule of the domain fork than VerifyAssetKey.
+func VerifyTokenId(assetKey string) error {
+	if len(assetKey) != 7 {
+		return nil
+	}
+	id, err := strconv.ParseUint(assetKey, 10, 64)
+	if err != nil || id < 1000001 {
+		return fmt.Errorf("invalid tron asset key %s", assetKey)
+	}
+	return nil
+}
+
 func VerifyAddress(address string) error {
 	if strings.TrimSpace(address) != address {
 		return fmt.Errorf("invalid tron address %s", address)
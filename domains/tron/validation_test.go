@@ -23,6 +23,15 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(err)
 	err = VerifyAssetKey("10020001")
 	assert.NotNil(err)
+	for _, id := range []string{"+100200", "-100200", "0100200", "1000000"} {
+		assert.Nil(VerifyAssetKey(id))
+		assert.NotNil(VerifyTokenId(id))
+	}
+	err = VerifyAssetKey("1000001")
+	assert.Nil(err)
+	assert.Nil(VerifyTokenId("1000001"))
+	assert.Nil(VerifyTokenId("1002000"))
+	assert.Nil(VerifyTokenId("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"))
 	err = VerifyAssetKey("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
 	assert.Nil(err)
 	err = VerifyAssetKey("Tr7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
@@ -50,6 +59,10 @@ func TestValidation(t *testing.T) {
 	uid := uniqueAssetId(TronChainBase, "1002000")
 	result := crypto.NewHash([]byte(uid))
 	assert.Equal(assetId.String(), result.String())
+	assetId = GenerateAssetId("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	uid = uniqueAssetId(TronChainBase, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	assert.Equal("b91e18ff-a9ae-3dc7-8679-e935d9a4b34b", uid)
+	assert.Equal(crypto.NewHash([]byte(uid)), assetId)
 }
 
 func uniqueAssetId(chainId, assetAddress string) string {
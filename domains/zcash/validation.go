@@ -11,6 +11,10 @@ import (
 var (
 	ZcashChainBase string
 	ZcashChainId   crypto.Hash
+
+	// the shielded addresses, sprout zc, sapling zs1 and unified u1, are not
+	// supported, because the deposits to them can't be verified
+	shieldedPrefixes = []string{"zc", "zs1", "u1"}
 )
 
 func init() {
@@ -39,6 +43,17 @@ func VerifyAddress(address string) error {
 	return nil
 }
 
+// VerifyTransparentAddress rejects the shielded addresses explicitly, which
+// is a stricter rule of the domain fork than VerifyAddress.
+func VerifyTransparentAddress(address string) error {
+	for _, p := range shieldedPrefixes {
+		if strings.HasPrefix(address, p) {
+			return fmt.Errorf("shielded zcash address not supported %s", address)
+		}
+	}
+	return VerifyAddress(address)
+}
+
 func VerifyTransactionHash(hash string) error {
 	if len(hash) != 64 {
 		return fmt.Errorf("invalid zcash transaction hash %s", hash)
@@ -57,12 +72,11 @@ func VerifyTransactionHash(hash string) error {
 }
 
 func GenerateAssetId(assetKey string) crypto.Hash {
-	switch assetKey {
-	case ZcashChainBase:
-		return ZcashChainId
-	default:
+	err := VerifyAssetKey(assetKey)
+	if err != nil {
 		panic(assetKey)
 	}
+	return ZcashChainId
 }
 
 var mainNetParams = Params{
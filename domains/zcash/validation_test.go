@@ -14,6 +14,9 @@ func TestValidation(t *testing.T) {
 	zec := "c996abc9-d94e-4494-b1cf-2a3fd3ac5714"
 	tx := "30f305889eab065bb5c85e724df9ffb1c8da7f22259c583cf874fbd6ec681b8a"
 	addrMain := "t1NsuW4Xpz3GQUzt3BTZAxN6k4svKfWXgni"
+	addrScript := "t3Vz22vK5z2LcKEdg16Yv4FFneEL1zg9ojd"
+	addrSprout := "zcU1Cd6zYyZCd2VJF8yKgmzjxdiiU1rgTTjEwoN1CGUWCziPkUTXUjXmX7TMqdMNsTfuiGN1jQoVN4kGxUR4sAPN4XZ7pxb"
+	addrSapling := "zs1z7rejlpsa98s2rrrfkwmaxu53e4ue0ulcrw0h4x5g8jl04tak0d3mm47vdtahatqrlkngh9sly"
 
 	assert.Nil(VerifyAssetKey(zec))
 	assert.NotNil(VerifyAssetKey(tx))
@@ -24,6 +27,15 @@ func TestValidation(t *testing.T) {
 	assert.NotNil(VerifyAddress(zec))
 	assert.NotNil(VerifyAddress(addrMain[1:]))
 	assert.NotNil(VerifyAddress(strings.ToUpper(addrMain)))
+	assert.Nil(VerifyAddress(addrScript))
+	assert.Nil(VerifyAddress("t3PZvRc2GXwanaAaUJZDmNzTgDQD38FqXrJ"))
+	assert.NotNil(VerifyAddress(addrScript[:len(addrScript)-1] + "e"))
+	assert.Nil(VerifyTransparentAddress(addrMain))
+	assert.Nil(VerifyTransparentAddress(addrScript))
+	assert.NotNil(VerifyTransparentAddress(addrMain[1:]))
+	assert.NotNil(VerifyTransparentAddress(addrSprout))
+	assert.NotNil(VerifyTransparentAddress(addrSapling))
+	assert.NotNil(VerifyTransparentAddress("u1" + addrSapling[3:]))
 
 	assert.Nil(VerifyTransactionHash(tx))
 	assert.NotNil(VerifyTransactionHash(zec))
@@ -34,4 +46,5 @@ func TestValidation(t *testing.T) {
 	assert.Equal(crypto.NewHash([]byte("c996abc9-d94e-4494-b1cf-2a3fd3ac5714")), GenerateAssetId(zec))
 	assert.Equal(crypto.NewHash([]byte("c996abc9-d94e-4494-b1cf-2a3fd3ac5714")), ZcashChainId)
 	assert.Equal(crypto.NewHash([]byte(ZcashChainBase)), ZcashChainId)
+	assert.Panics(func() { GenerateAssetId(addrMain) })
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel"
+	"github.com/urfave/cli/v2"
+)
+
+func createGenesisCmd(c *cli.Context) error {
+	signerKeys, payeeKeys := c.StringSlice("signer"), c.StringSlice("payee")
+	if len(signerKeys) != len(payeeKeys) {
+		return fmt.Errorf("genesis signers and payees count unmatch %d %d", len(signerKeys), len(payeeKeys))
+	}
+	var signers, payees []common.Address
+	for i := range signerKeys {
+		signer, err := parseGenesisAccount(signerKeys[i])
+		if err != nil {
+			return err
+		}
+		payee, err := parseGenesisAccount(payeeKeys[i])
+		if err != nil {
+			return err
+		}
+		signers = append(signers, signer)
+		payees = append(payees, payee)
+	}
+
+	epoch := c.Int64("epoch")
+	if epoch == 0 {
+		epoch = time.Now().Unix()
+	}
+	round, err := parseGenesisRound(c)
+	if err != nil {
+		return err
+	}
+	gns := kernel.NewGenesis(epoch, signers, payees)
+	gns.Round = round
+	err = gns.Validate()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(gns, "", "  ")
+	if err != nil {
+		return err
+	}
+	if file := c.String("file"); file != "" {
+		err = os.WriteFile(file, data, 0644)
+		if err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(string(data))
+	}
+	fmt.Printf("network: %s\n", gns.NetworkId())
+	return nil
+}
+
+func validateGenesisCmd(c *cli.Context) error {
+	data, err := os.ReadFile(c.String("file"))
+	if err != nil {
+		return err
+	}
+	gns, err := kernel.ParseGenesis(data)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("network: %s\n", gns.NetworkId())
+	fmt.Printf("epoch: %s\n", time.Unix(gns.Epoch, 0).UTC().Format(time.RFC3339))
+	fmt.Printf("nodes: %d\n", len(gns.Nodes))
+	fmt.Printf("threshold: %d\n", len(gns.Nodes)*2/3+1)
+	return nil
+}
+
+// parseGenesisRound fills the missing one of the round gap and reference
+// threshold flags from the mainnet ones, and returns nil without both.
+func parseGenesisRound(c *cli.Context) (*kernel.GenesisRound, error) {
+	gap, threshold := c.Uint64("round-gap"), c.Uint64("reference-threshold")
+	if gap == 0 && threshold == 0 {
+		return nil, nil
+	}
+	if gap == 0 {
+		gap = config.SnapshotRoundGap / uint64(time.Millisecond)
+	}
+	if threshold == 0 {
+		threshold = config.SnapshotReferenceThreshold
+	}
+	round := &kernel.GenesisRound{Gap: gap, Threshold: threshold}
+	return round, round.Validate()
+}
+
+// parseGenesisAccount accepts either the address, or the private spend key
+// with the view key derived from the public spend key as all nodes do.
+func parseGenesisAccount(s string) (common.Address, error) {
+	if strings.HasPrefix(s, common.MainNetworkId) {
+		return common.NewAddressFromString(s)
+	}
+	key, err := crypto.KeyFromString(s)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid genesis account %s", s)
+	}
+	account := common.Address{
+		PrivateSpendKey: key,
+		PublicSpendKey:  key.Public(),
+	}
+	account.PrivateViewKey = account.PublicSpendKey.DeterministicHashDerive()
+	account.PublicViewKey = account.PrivateViewKey.Public()
+	return account, nil
+}
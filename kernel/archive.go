@@ -0,0 +1,341 @@
+package kernel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+	"github.com/MixinNetwork/mixin/storage"
+	"golang.org/x/crypto/sha3"
+)
+
+// The snapshot archive is a cold storage of the finalized snapshots in the
+// topological order, with their transactions.
+//
+//	header  | magic | network id | from | to
+//	record  | 1 | topology | snapshot size | snapshot | tx size | tx
+//	trailer | 0 | count | checksum | signer public key | signature
+//
+// The checksum is the SHA3-256 of all the bytes before it, and it's signed by
+// the exporting node signer key. The integers are big endian, the snapshot is
+// msgpack encoded with its cosi signature, and the transaction is compressed.
+const (
+	SnapshotArchiveMagic = "MIXINSA1"
+
+	snapshotArchiveBatch      = 500
+	snapshotArchiveRecordSize = 1024 * 1024
+	snapshotArchiveRetries    = 30
+)
+
+type snapshotArchiveWriter struct {
+	w   *bufio.Writer
+	sum hash.Hash
+	buf [8]byte
+}
+
+func (aw *snapshotArchiveWriter) write(b []byte) error {
+	aw.sum.Write(b)
+	_, err := aw.w.Write(b)
+	return err
+}
+
+func (aw *snapshotArchiveWriter) writeUint64(d uint64) error {
+	binary.BigEndian.PutUint64(aw.buf[:], d)
+	return aw.write(aw.buf[:])
+}
+
+func (aw *snapshotArchiveWriter) writeBytes(b []byte) error {
+	binary.BigEndian.PutUint32(aw.buf[:4], uint32(len(b)))
+	err := aw.write(aw.buf[:4])
+	if err != nil {
+		return err
+	}
+	return aw.write(b)
+}
+
+type snapshotArchiveReader struct {
+	r   *bufio.Reader
+	sum hash.Hash
+}
+
+func (ar *snapshotArchiveReader) read(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(ar.r, b)
+	if err != nil {
+		return nil, err
+	}
+	ar.sum.Write(b)
+	return b, nil
+}
+
+func (ar *snapshotArchiveReader) readUint64() (uint64, error) {
+	b, err := ar.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+func (ar *snapshotArchiveReader) readBytes() ([]byte, error) {
+	b, err := ar.read(4)
+	if err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(b)
+	if size == 0 || size > snapshotArchiveRecordSize {
+		return nil, fmt.Errorf("invalid archive record size %d", size)
+	}
+	return ar.read(int(size))
+}
+
+// ExportSnapshots writes the finalized snapshots in the topology range
+// [from, to) to w as a signed archive, and returns the snapshots count.
+func ExportSnapshots(store storage.Store, networkId crypto.Hash, signer crypto.Key, w io.Writer, from, to uint64) (uint64, error) {
+	if to <= from {
+		return 0, fmt.Errorf("invalid topology range %d %d", from, to)
+	}
+	aw := &snapshotArchiveWriter{w: bufio.NewWriter(w), sum: sha3.New256()}
+	err := aw.write([]byte(SnapshotArchiveMagic))
+	if err != nil {
+		return 0, err
+	}
+	err = aw.write(networkId[:])
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range []uint64{from, to} {
+		err = aw.writeUint64(d)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var count uint64
+	for offset := from; offset < to; {
+		snapshots, err := store.ReadSnapshotsSinceTopology(offset, snapshotArchiveBatch)
+		if err != nil {
+			return count, err
+		}
+		for _, s := range snapshots {
+			if s.TopologicalOrder >= to {
+				offset = to
+				break
+			}
+			tx, _, err := store.ReadTransaction(s.Transaction)
+			if err != nil {
+				return count, err
+			}
+			if tx == nil {
+				return count, fmt.Errorf("snapshot transaction not found %s %s", s.PayloadHash(), s.Transaction)
+			}
+			err = aw.write([]byte{1})
+			if err != nil {
+				return count, err
+			}
+			err = aw.writeUint64(s.TopologicalOrder)
+			if err != nil {
+				return count, err
+			}
+			err = aw.writeBytes(common.MsgpackMarshalPanic(s.Snapshot))
+			if err != nil {
+				return count, err
+			}
+			err = aw.writeBytes(tx.CompressMarshal())
+			if err != nil {
+				return count, err
+			}
+			count += 1
+		}
+		if offset >= to || len(snapshots) < snapshotArchiveBatch {
+			break
+		}
+		offset = snapshots[len(snapshots)-1].TopologicalOrder + 1
+	}
+
+	err = aw.write([]byte{0})
+	if err != nil {
+		return count, err
+	}
+	err = aw.writeUint64(count)
+	if err != nil {
+		return count, err
+	}
+	checksum := aw.sum.Sum(nil)
+	pub := signer.Public()
+	sig := signer.Sign(checksum)
+	for _, b := range [][]byte{checksum, pub[:], sig[:]} {
+		_, err = aw.w.Write(b)
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, aw.w.Flush()
+}
+
+// ImportSnapshots verifies the archive checksum and signature, then appends
+// the snapshots not in the graph yet, each one only after its cosi signature
+// verified against the consensus nodes at the snapshot timestamp.
+func (node *Node) ImportSnapshots(r io.ReadSeeker) (uint64, crypto.Key, error) {
+	signer, err := node.readSnapshotArchive(r, nil)
+	if err != nil {
+		return 0, signer, err
+	}
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, signer, err
+	}
+
+	var count uint64
+	heads := make(map[crypto.Hash]crypto.Hash)
+	_, err = node.readSnapshotArchive(r, func(s *common.SnapshotWithTopologicalOrder, tx *common.VersionedTransaction) error {
+		old, err := node.persistStore.ReadSnapshot(s.Hash)
+		if err != nil || old != nil {
+			return err
+		}
+		chain := node.GetOrCreateChain(s.NodeId)
+		err = chain.verifyArchiveSnapshot(s)
+		if err != nil {
+			return err
+		}
+		err = chain.importSnapshot(s, tx)
+		if err != nil {
+			return err
+		}
+		heads[s.NodeId] = s.Hash
+		count += 1
+		return nil
+	})
+	if err != nil {
+		return count, signer, err
+	}
+
+	// the final queue of each chain is in order, so all the snapshots are
+	// persisted once the last one of each chain
+	for id, hash := range heads {
+		for i := 0; ; i++ {
+			s, err := node.persistStore.ReadSnapshot(hash)
+			if err != nil {
+				return count, signer, err
+			}
+			if s != nil {
+				break
+			}
+			if i >= snapshotArchiveRetries {
+				return count, signer, fmt.Errorf("snapshot not finalized %s %s", id, hash)
+			}
+			time.Sleep(time.Second)
+		}
+	}
+	return count, signer, nil
+}
+
+func (chain *Chain) verifyArchiveSnapshot(s *common.SnapshotWithTopologicalOrder) error {
+	// the consensus nodes may change with the previous snapshots still in the
+	// final queue, so wait for them before rejecting the snapshot
+	for i := 0; i < snapshotArchiveRetries; i++ {
+		_, finalized := chain.verifyFinalization(&s.Snapshot)
+		if finalized {
+			return nil
+		}
+		logger.Verbosef("verifyArchiveSnapshot(%s, %d) retry %d\n", s.Hash, s.TopologicalOrder, i)
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("invalid snapshot signature %s %d", s.Hash, s.TopologicalOrder)
+}
+
+func (node *Node) readSnapshotArchive(r io.Reader, handle func(*common.SnapshotWithTopologicalOrder, *common.VersionedTransaction) error) (crypto.Key, error) {
+	var signer crypto.Key
+	ar := &snapshotArchiveReader{r: bufio.NewReader(r), sum: sha3.New256()}
+	header, err := ar.read(len(SnapshotArchiveMagic) + 32 + 16)
+	if err != nil {
+		return signer, err
+	}
+	if string(header[:len(SnapshotArchiveMagic)]) != SnapshotArchiveMagic {
+		return signer, fmt.Errorf("invalid archive magic %x", header[:len(SnapshotArchiveMagic)])
+	}
+	header = header[len(SnapshotArchiveMagic):]
+	if !bytes.Equal(header[:32], node.networkId[:]) {
+		return signer, fmt.Errorf("invalid archive network %x", header[:32])
+	}
+
+	var count uint64
+	for {
+		flag, err := ar.read(1)
+		if err != nil {
+			return signer, err
+		}
+		if flag[0] == 0 {
+			break
+		}
+		if flag[0] != 1 {
+			return signer, fmt.Errorf("invalid archive record flag %d", flag[0])
+		}
+		topo, err := ar.readUint64()
+		if err != nil {
+			return signer, err
+		}
+		sb, err := ar.readBytes()
+		if err != nil {
+			return signer, err
+		}
+		tb, err := ar.readBytes()
+		if err != nil {
+			return signer, err
+		}
+		count += 1
+		if handle == nil {
+			continue
+		}
+
+		var s common.SnapshotWithTopologicalOrder
+		err = common.MsgpackUnmarshal(sb, &s.Snapshot)
+		if err != nil {
+			return signer, err
+		}
+		s.TopologicalOrder = topo
+		s.Hash = s.PayloadHash()
+		tx, err := common.DecompressUnmarshalVersionedTransaction(tb)
+		if err != nil {
+			return signer, err
+		}
+		err = handle(&s, tx)
+		if err != nil {
+			return signer, err
+		}
+	}
+
+	total, err := ar.readUint64()
+	if err != nil {
+		return signer, err
+	}
+	if total != count {
+		return signer, fmt.Errorf("invalid archive count %d %d", total, count)
+	}
+	checksum := ar.sum.Sum(nil)
+	var sig crypto.Signature
+	trailer := make([]byte, len(checksum)+len(signer)+len(sig))
+	_, err = io.ReadFull(ar.r, trailer)
+	if err != nil {
+		return signer, err
+	}
+	if !bytes.Equal(trailer[:len(checksum)], checksum) {
+		return signer, fmt.Errorf("invalid archive checksum %x %x", trailer[:len(checksum)], checksum)
+	}
+	copy(signer[:], trailer[len(checksum):])
+	copy(sig[:], trailer[len(checksum)+len(signer):])
+	if !signer.Verify(checksum, sig) {
+		return signer, fmt.Errorf("invalid archive signature by %s", signer)
+	}
+	_, err = ar.r.ReadByte()
+	if err != io.EOF {
+		return signer, fmt.Errorf("invalid archive trailing data")
+	}
+	return signer, nil
+}
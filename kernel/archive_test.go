@@ -0,0 +1,57 @@
+package kernel
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotArchive(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := os.MkdirTemp("", "mixin-archive-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	node := setupTestNode(assert, root)
+	assert.NotNil(node)
+
+	seed := crypto.NewHash([]byte("archive"))
+	signer := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+
+	var buf bytes.Buffer
+	_, err = ExportSnapshots(node.persistStore, node.networkId, signer, &buf, 10, 10)
+	assert.NotNil(err)
+	count, err := ExportSnapshots(node.persistStore, node.networkId, signer, &buf, 10, 100)
+	assert.Nil(err)
+	assert.Equal(uint64(6), count)
+	buf.Reset()
+	count, err = ExportSnapshots(node.persistStore, node.networkId, signer, &buf, 0, 100)
+	assert.Nil(err)
+	assert.Equal(uint64(16), count)
+	data := buf.Bytes()
+	assert.Equal(SnapshotArchiveMagic, string(data[:8]))
+
+	count, pub, err := node.ImportSnapshots(bytes.NewReader(data))
+	assert.Nil(err)
+	assert.Equal(uint64(0), count)
+	assert.Equal(signer.Public(), pub)
+
+	tampered := append([]byte{}, data...)
+	tampered[100] ^= 0xff
+	_, _, err = node.ImportSnapshots(bytes.NewReader(tampered))
+	assert.NotNil(err)
+
+	tampered = append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, _, err = node.ImportSnapshots(bytes.NewReader(tampered))
+	assert.Contains(err.Error(), "invalid archive signature")
+
+	_, _, err = node.ImportSnapshots(bytes.NewReader(append(data, 0)))
+	assert.Contains(err.Error(), "trailing data")
+	_, _, err = node.ImportSnapshots(bytes.NewReader(data[:len(data)-1]))
+	assert.NotNil(err)
+}
@@ -0,0 +1,59 @@
+package kernel
+
+import (
+	"encoding/hex"
+
+	"github.com/btcsuite/btcd/btcec"
+	"golang.org/x/crypto/sha3"
+)
+
+const attestationDomain = "MIXIN:KERNEL:ATTESTATION"
+
+// SignAttestation signs the keccak256 digest of the domain, network id and
+// payload with the secp256k1 key derived from the node signer, because an EVM
+// contract could only verify it with ecrecover. The signature is r, s and v.
+func (node *Node) SignAttestation(payload []byte) ([]byte, []byte, error) {
+	priv, err := node.attestationKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	digest := attestationDigest(node.networkId[:], payload)
+	compact, err := btcec.SignCompact(btcec.S256(), priv, digest, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return digest, append(compact[1:], compact[0]), nil
+}
+
+// AttestationAddress is the EVM address of the attestation key, which the
+// bridge contracts should register for the node.
+func (node *Node) AttestationAddress() (string, error) {
+	priv, err := node.attestationKey()
+	if err != nil {
+		return "", err
+	}
+	return attestationAddress(priv.PubKey()), nil
+}
+
+func (node *Node) attestationKey() (*btcec.PrivateKey, error) {
+	seed, err := node.signerBackend.Secret(attestationDomain)
+	if err != nil {
+		return nil, err
+	}
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), seed[:])
+	return priv, nil
+}
+
+func attestationDigest(networkId, payload []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(attestationDomain))
+	h.Write(networkId)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+func attestationAddress(pub *btcec.PublicKey) string {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(pub.SerializeUncompressed()[1:])
+	return "0x" + hex.EncodeToString(h.Sum(nil)[12:])
+}
@@ -0,0 +1,45 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/signer"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttestation(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := crypto.NewHash([]byte("attestation"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	node := &Node{
+		networkId:     crypto.NewHash([]byte("network")),
+		signerBackend: signer.NewLocal(key),
+	}
+	address, err := node.AttestationAddress()
+	assert.Nil(err)
+	assert.Len(address, 42)
+
+	payload := []byte("payload")
+	digest, sig, err := node.SignAttestation(payload)
+	assert.Nil(err)
+	assert.Len(digest, 32)
+	assert.Len(sig, 65)
+	assert.Contains([]byte{27, 28}, sig[64])
+	assert.Equal(attestationDigest(node.networkId[:], payload), digest)
+
+	compact := append([]byte{sig[64]}, sig[:64]...)
+	pub, _, err := btcec.RecoverCompact(btcec.S256(), compact, digest)
+	assert.Nil(err)
+	assert.Equal(address, attestationAddress(pub))
+
+	node.networkId = crypto.NewHash([]byte("other"))
+	other, _, err := node.SignAttestation(payload)
+	assert.Nil(err)
+	assert.NotEqual(digest, other)
+	same, err := node.AttestationAddress()
+	assert.Nil(err)
+	assert.Equal(address, same)
+}
@@ -1,15 +1,18 @@
 package kernel
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"sync/atomic"
 	"time"
 
 	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/MixinNetwork/mixin/logger"
 )
 
 func (node *Node) Loop() error {
-	rand.Seed(time.Now().UnixNano())
+	rand.Seed(clock.Now().UnixNano())
 	err := node.PingNeighborsFromConfig()
 	if err != nil {
 		return err
@@ -20,24 +23,80 @@ func (node *Node) Loop() error {
 			panic(fmt.Errorf("ListenNeighbors %s", err.Error()))
 		}
 	}()
+	go node.LoopBootstrap()
 	go node.LoopCacheQueue()
+	go node.LoopRequeueSnapshots()
 	go node.MintLoop()
 	node.ElectionLoop()
 	return nil
 }
 
+// Stop stops accepting new cosi actions and waits for the queued ones to
+// drain until ctx is done, then tears down the node. The teardown happens
+// even when the drain times out, and the ctx error is returned then.
+func (node *Node) Stop(ctx context.Context) error {
+	atomic.StoreUint32(&node.stopping, 1)
+	err := node.drainChains(ctx)
+	if err != nil {
+		logger.Printf("Stop(%s) drain ERROR %s\n", node.IdForNetwork, err)
+	}
+	node.Teardown()
+	return err
+}
+
+func (node *Node) isStopping() bool {
+	return atomic.LoadUint32(&node.stopping) == 1
+}
+
+func (node *Node) drainChains(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		pending := 0
+		node.chains.RLock()
+		for _, c := range node.chains.m {
+			pending += len(c.CachePool) + len(c.finalActionsRing)
+		}
+		node.chains.RUnlock()
+		if pending == 0 {
+			return nil
+		}
+		logger.Printf("drainChains(%s) pending %d\n", node.IdForNetwork, pending)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 func (node *Node) Teardown() {
+	atomic.StoreUint32(&node.stopping, 1)
 	close(node.done)
 	<-node.cqc
+	<-node.rqc
 	<-node.mlc
 	<-node.elc
 	node.chains.RLock()
 	for _, c := range node.chains.m {
 		c.Teardown()
+		err := c.persistCosiState()
+		if err != nil {
+			logger.Printf("Teardown(%s) persistCosiState ERROR %s\n", c.ChainId, err)
+		}
 	}
 	node.chains.RUnlock()
 	node.Peer.Teardown()
-	node.persistStore.Close()
+	err := node.TopoCounter.flush(node.persistStore)
+	if err != nil {
+		logger.Printf("Teardown(%s) TopoCounter ERROR %s\n", node.IdForNetwork, err)
+	}
+	err = node.persistStore.Close()
+	if err != nil {
+		logger.Printf("Teardown(%s) persistStore ERROR %s\n", node.IdForNetwork, err)
+	}
 	node.cacheStore.Clear()
 }
 
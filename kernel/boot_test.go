@@ -0,0 +1,47 @@
+package kernel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainChains(t *testing.T) {
+	assert := assert.New(t)
+
+	id := crypto.NewHash([]byte("drain-chain"))
+	chain := &Chain{
+		ChainId:          id,
+		CachePool:        make(chan *CosiAction, 4),
+		finalActionsRing: make(chan *CosiAction, 4),
+	}
+	node := &Node{chains: &chainsMap{m: map[crypto.Hash]*Chain{id: chain}}}
+	chain.node = node
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(node.drainChains(ctx))
+
+	chain.CachePool <- &CosiAction{PeerId: id}
+	chain.finalActionsRing <- &CosiAction{PeerId: id}
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, node.drainChains(ctx))
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		chain.CachePool.Poll()
+		chain.finalActionsRing.Poll()
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(node.drainChains(ctx))
+
+	atomic.StoreUint32(&node.stopping, 1)
+	assert.Nil(chain.AppendCosiAction(&CosiAction{PeerId: id, Action: CosiActionExternalAnnouncement}))
+	assert.Len(chain.CachePool, 0)
+}
@@ -0,0 +1,157 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	bootstrapListDomain     = "MIXIN:KERNEL:BOOTSTRAP"
+	bootstrapListExpiration = 7 * 24 * time.Hour
+	bootstrapListSizeLimit  = 1024 * 1024
+	bootstrapFallbackDelay  = time.Minute
+)
+
+// BootstrapList is the peers list published at an HTTPS endpoint, signed by
+// a key configured in the nodes, for the nodes to find their first peers
+// when none of the P2P seeds are reachable.
+type BootstrapList struct {
+	Network   crypto.Hash      `json:"network"`
+	Timestamp uint64           `json:"timestamp"`
+	Peers     []string         `json:"peers"`
+	Signer    crypto.Key       `json:"signer"`
+	Signature crypto.Signature `json:"signature"`
+}
+
+func (l *BootstrapList) payload() []byte {
+	p := BootstrapList{
+		Network:   l.Network,
+		Timestamp: l.Timestamp,
+		Peers:     l.Peers,
+		Signer:    l.Signer,
+	}
+	msg := append([]byte(bootstrapListDomain), common.MsgpackMarshalPanic(p)...)
+	return msg
+}
+
+func SignBootstrapList(networkId crypto.Hash, peers []string, key crypto.Key) *BootstrapList {
+	l := &BootstrapList{
+		Network:   networkId,
+		Timestamp: uint64(clock.Now().UnixNano()),
+		Peers:     peers,
+		Signer:    key.Public(),
+	}
+	digest := crypto.NewHash(l.payload())
+	l.Signature = key.Sign(digest[:])
+	return l
+}
+
+// VerifyBootstrapList checks the list is signed by one of the keys for the
+// network, and is neither expired nor from the future.
+func VerifyBootstrapList(l *BootstrapList, networkId crypto.Hash, keys []crypto.Key) error {
+	if l.Network != networkId {
+		return fmt.Errorf("invalid bootstrap list network %s %s", l.Network, networkId)
+	}
+	var trusted bool
+	for _, k := range keys {
+		trusted = trusted || k == l.Signer
+	}
+	if !trusted {
+		return fmt.Errorf("invalid bootstrap list signer %s", l.Signer)
+	}
+	now := uint64(clock.Now().UnixNano())
+	if l.Timestamp > now+uint64(config.SnapshotRoundGap)*10 {
+		return fmt.Errorf("invalid bootstrap list timestamp %d %d", l.Timestamp, now)
+	}
+	if l.Timestamp+uint64(bootstrapListExpiration) < now {
+		return fmt.Errorf("expired bootstrap list timestamp %d %d", l.Timestamp, now)
+	}
+	digest := crypto.NewHash(l.payload())
+	if !l.Signer.Verify(digest[:], l.Signature) {
+		return fmt.Errorf("invalid bootstrap list signature %s", l.Signer)
+	}
+	return nil
+}
+
+func fetchBootstrapList(client *http.Client, uri string, networkId crypto.Hash, keys []crypto.Key) (*BootstrapList, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid bootstrap endpoint %s", uri)
+	}
+	resp, err := client.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid bootstrap endpoint status %s %d", uri, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, bootstrapListSizeLimit))
+	if err != nil {
+		return nil, err
+	}
+	var l BootstrapList
+	err = json.Unmarshal(data, &l)
+	if err != nil {
+		return nil, err
+	}
+	err = VerifyBootstrapList(&l, networkId, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// bootstrapKeys parses the configured signing keys of the bootstrap lists,
+// the invalid ones are ignored with a log.
+func (node *Node) bootstrapKeys() []crypto.Key {
+	var keys []crypto.Key
+	for _, s := range node.custom.Network.BootstrapKeys {
+		k, err := crypto.KeyFromString(s)
+		if err != nil || !k.CheckKey() {
+			logger.Printf("bootstrapKeys invalid key %s\n", s)
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// LoopBootstrap waits for the P2P seeds, and pings the peers from the
+// bootstrap endpoints once there are still no neighbors after a delay,
+// until the node gets any neighbor.
+func (node *Node) LoopBootstrap() {
+	endpoints, keys := node.custom.Network.Bootstrap, node.bootstrapKeys()
+	if len(endpoints) == 0 || len(keys) == 0 {
+		return
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	for !node.isStopping() {
+		time.Sleep(bootstrapFallbackDelay)
+		if len(node.Peer.Neighbors()) > 0 {
+			return
+		}
+		for _, uri := range endpoints {
+			l, err := fetchBootstrapList(client, uri, node.networkId, keys)
+			if err != nil {
+				logger.Printf("LoopBootstrap(%s) ERROR %s\n", uri, err)
+				continue
+			}
+			logger.Printf("LoopBootstrap(%s) %d peers\n", uri, len(l.Peers))
+			node.UpdateNeighbors(l.Peers)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package kernel
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapList(t *testing.T) {
+	assert := assert.New(t)
+
+	networkId := crypto.NewHash([]byte("network"))
+	key := crypto.NewKeyFromSeed(bytes.Repeat([]byte{1}, 64))
+	other := crypto.NewKeyFromSeed(bytes.Repeat([]byte{2}, 64))
+	keys := []crypto.Key{other.Public(), key.Public()}
+	peers := []string{"127.0.0.1:7239", "127.0.0.2:7239"}
+
+	l := SignBootstrapList(networkId, peers, key)
+	assert.Equal(key.Public(), l.Signer)
+	assert.Nil(VerifyBootstrapList(l, networkId, keys))
+	assert.Contains(VerifyBootstrapList(l, crypto.NewHash([]byte("other")), keys).Error(), "invalid bootstrap list network")
+	assert.Contains(VerifyBootstrapList(l, networkId, keys[:1]).Error(), "invalid bootstrap list signer")
+
+	data, err := json.Marshal(l)
+	assert.Nil(err)
+	var decoded BootstrapList
+	err = json.Unmarshal(data, &decoded)
+	assert.Nil(err)
+	assert.Nil(VerifyBootstrapList(&decoded, networkId, keys))
+
+	decoded.Peers = append(decoded.Peers, "127.0.0.3:7239")
+	assert.Contains(VerifyBootstrapList(&decoded, networkId, keys).Error(), "invalid bootstrap list signature")
+	decoded.Timestamp = l.Timestamp - uint64(bootstrapListExpiration) - 1
+	assert.Contains(VerifyBootstrapList(&decoded, networkId, keys).Error(), "expired bootstrap list timestamp")
+	decoded.Timestamp = l.Timestamp + uint64(time.Hour)
+	assert.Contains(VerifyBootstrapList(&decoded, networkId, keys).Error(), "invalid bootstrap list timestamp")
+
+	served := data
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/peers.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(served)
+	}))
+	defer server.Close()
+
+	fetched, err := fetchBootstrapList(server.Client(), server.URL+"/peers.json", networkId, keys)
+	assert.Nil(err)
+	assert.Equal(peers, fetched.Peers)
+	_, err = fetchBootstrapList(server.Client(), server.URL+"/peers.json", networkId, keys[:1])
+	assert.NotNil(err)
+	_, err = fetchBootstrapList(server.Client(), server.URL+"/404.json", networkId, keys)
+	assert.Contains(err.Error(), "invalid bootstrap endpoint status")
+	_, err = fetchBootstrapList(server.Client(), strings.Replace(server.URL, "https", "http", 1)+"/peers.json", networkId, keys)
+	assert.Contains(err.Error(), "invalid bootstrap endpoint")
+
+	served = []byte("{")
+	_, err = fetchBootstrapList(server.Client(), server.URL+"/peers.json", networkId, keys)
+	assert.NotNil(err)
+}
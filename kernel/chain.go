@@ -1,6 +1,7 @@
 package kernel
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"sync"
 	"time"
@@ -16,7 +17,8 @@ import (
 const (
 	FinalPoolSlotsLimit     = config.SnapshotSyncRoundThreshold * 8
 	FinalPoolRoundSizeLimit = 1024
-	CachePoolSnapshotsLimit = 256
+
+	cosiActionsBlockTimeout = time.Duration(config.SnapshotRoundGap)
 )
 
 type PeerSnapshot struct {
@@ -54,15 +56,23 @@ type Chain struct {
 	CosiAggregators map[crypto.Hash]*CosiAggregator
 	CosiVerifiers   map[crypto.Hash]*CosiVerifier
 	CachePool       ActionBuffer
+	throttle        *announcementThrottle
 	FinalPool       [FinalPoolSlotsLimit]*ChainRound
 	FinalIndex      int
 	FinalCount      int
 
 	persistStore     storage.Store
 	finalActionsRing ActionBuffer
+	cosiStates       chan *cosiStateWrite
+	cosiStateAt      time.Time
+	cosiStateSeq     uint64
+	cosiStateWritten uint64
+	cosiStateLock    sync.Mutex
+	cosiStateAEAD    cipher.AEAD
 	plc              chan struct{}
 	clc              chan struct{}
 	wlc              chan struct{}
+	slc              chan struct{}
 	running          bool
 }
 
@@ -72,12 +82,15 @@ func (node *Node) buildChain(chainId crypto.Hash) *Chain {
 		ChainId:          chainId,
 		CosiAggregators:  make(map[crypto.Hash]*CosiAggregator),
 		CosiVerifiers:    make(map[crypto.Hash]*CosiVerifier),
-		CachePool:        make(chan *CosiAction, CachePoolSnapshotsLimit),
+		CachePool:        make(chan *CosiAction, node.custom.Node.CosiActionsSize),
+		throttle:         newAnnouncementThrottle(node.custom),
 		persistStore:     node.persistStore,
 		finalActionsRing: make(chan *CosiAction, FinalPoolSlotsLimit),
+		cosiStates:       make(chan *cosiStateWrite, 1),
 		plc:              make(chan struct{}),
 		clc:              make(chan struct{}),
 		wlc:              make(chan struct{}),
+		slc:              make(chan struct{}),
 		running:          true,
 	}
 
@@ -85,10 +98,15 @@ func (node *Node) buildChain(chainId crypto.Hash) *Chain {
 	if err != nil {
 		panic(err)
 	}
+	err = chain.loadCosiState()
+	if err != nil {
+		logger.Printf("loadCosiState(%s) ERROR %s\n", chainId, err)
+	}
 
 	go chain.AggregateMintWork()
-	go chain.QueuePollSnapshots()
-	go chain.ConsumeFinalActions()
+	clock.Go(chain.QueuePollSnapshots)
+	clock.Go(chain.ConsumeFinalActions)
+	clock.Go(chain.loopPersistCosiStates)
 	return chain
 }
 
@@ -131,6 +149,7 @@ func (chain *Chain) Teardown() {
 	<-chain.clc
 	<-chain.plc
 	<-chain.wlc
+	<-chain.slc
 }
 
 func (chain *Chain) IsPledging() bool {
@@ -158,13 +177,25 @@ func (chain *Chain) loadState() error {
 	}
 	state.CacheRound = cache
 
-	final, err := loadFinalRoundForNode(chain.persistStore, chain.ChainId, cache.Number-1)
+	final, history, err := loadStateFromGraphHead(chain.node.custom, chain.persistStore, cache)
 	if err != nil {
 		return err
 	}
+	if final == nil {
+		final, err = loadFinalRoundForNode(chain.persistStore, chain.ChainId, cache.Number-1)
+		if err != nil {
+			return err
+		}
+		finals := loadFinalRoundsForNode(chain.persistStore, final)
+		err = writeGraphHead(chain.persistStore, cache, finals)
+		if err != nil {
+			return err
+		}
+		history = reduceHistory(chain.node.custom, finals)
+	}
 	state.FinalRound = final
-	state.RoundHistory = loadRoundHistoryForNode(chain.persistStore, final)
-	cache.Timestamp = final.Start + config.SnapshotRoundGap
+	state.RoundHistory = history
+	cache.Timestamp = final.Start + chain.node.custom.RoundGap()
 
 	allNodes := chain.node.NodesListWithoutState(uint64(clock.Now().UnixNano()), false)
 	for _, cn := range allNodes {
@@ -254,9 +285,9 @@ func (chain *Chain) QueuePollSnapshots() {
 		logger.Debugf("QueuePollSnapshots cache pool end %s when final %d %d\n", chain.ChainId, chain.FinalIndex, chain.FinalCount)
 
 		if stale || final == 0 && cache == 0 {
-			time.Sleep(300 * time.Millisecond)
+			clock.Sleep(300 * time.Millisecond)
 		} else {
-			time.Sleep(100 * time.Millisecond)
+			clock.Sleep(100 * time.Millisecond)
 		}
 	}
 }
@@ -274,7 +305,7 @@ func (chain *Chain) ConsumeFinalActions() {
 	for chain.running {
 		ps := chain.finalActionsRing.Poll()
 		if ps == nil {
-			time.Sleep(100 * time.Millisecond)
+			clock.Sleep(100 * time.Millisecond)
 			continue
 		}
 		logger.Debugf("ConsumeFinalActions(%s) %s\n", chain.ChainId, ps.Snapshot.Hash)
@@ -283,7 +314,7 @@ func (chain *Chain) ConsumeFinalActions() {
 			if err != nil {
 				panic(err)
 			} else if retry {
-				time.Sleep(1 * time.Second)
+				clock.Sleep(1 * time.Second)
 			} else {
 				break
 			}
@@ -356,6 +387,9 @@ func (chain *Chain) AppendFinalSnapshot(peerId crypto.Hash, s *common.Snapshot)
 	if s.NodeId != chain.ChainId {
 		panic("final queue malformed")
 	}
+	if chain.node.isStopping() {
+		return nil
+	}
 	if cs := chain.State; cs != nil && cs.CacheRound.Number > s.RoundNumber {
 		return nil
 	}
@@ -394,14 +428,47 @@ func (chain *Chain) AppendCosiAction(m *CosiAction) error {
 	default:
 		panic("should never be here")
 	}
+	if chain.node.isStopping() {
+		logger.Verbosef("AppendCosiAction(%s) %v STOPPING\n", chain.ChainId, m)
+		return nil
+	}
 
-	err := chain.CachePool.Offer(m)
+	err := chain.offerCosiAction(m)
 	if err != nil {
 		logger.Verbosef("AppendCosiAction(%s) %v FULL\n", chain.ChainId, m)
 	}
 	return nil
 }
 
+// offerCosiAction queues the action with the overflow policy of the config,
+// and the block policy waits at most a round gap for space, because the
+// cache pool consumer may append actions to its own pool.
+func (chain *Chain) offerCosiAction(m *CosiAction) error {
+	qs := chain.node.cosiSaturation
+	err := chain.CachePool.Offer(m)
+	if err != nil {
+		qs.Full()
+	}
+	for start := clock.Now(); err != nil; err = chain.CachePool.Offer(m) {
+		switch chain.node.custom.Node.CosiActionsOverflow {
+		case config.OverflowDropOldest:
+			if chain.CachePool.Poll() != nil {
+				qs.Drop()
+			}
+			continue
+		case config.OverflowBlock:
+			if chain.running && clock.Now().Sub(start) < cosiActionsBlockTimeout {
+				clock.Sleep(10 * time.Millisecond)
+				continue
+			}
+		}
+		qs.Drop()
+		return err
+	}
+	qs.Offer(len(chain.CachePool))
+	return nil
+}
+
 func (chain *Chain) AppendSelfEmpty(s *common.Snapshot) error {
 	return chain.AppendCosiAction(&CosiAction{
 		PeerId:   chain.node.IdForNetwork,
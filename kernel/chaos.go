@@ -0,0 +1,61 @@
+package kernel
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+const (
+	ChaosCrashSnapshotWrite   = "snapshot-write"
+	ChaosCrashSnapshotWritten = "snapshot-written"
+	ChaosCrashRoundStart      = "round-start"
+)
+
+func (node *Node) chaosEnabled() bool {
+	dev := node.custom.Dev
+	return dev.ChaosMessageDelay > 0 || dev.ChaosMessageDrop > 0 ||
+		dev.ChaosStorageLatency > 0 || len(dev.ChaosCrashPoints) > 0
+}
+
+// checkChaos refuses the chaos options on the mainnet, they are meant to
+// rehearse the failures and the recovery on the test networks only.
+func (node *Node) checkChaos() error {
+	if !node.chaosEnabled() {
+		return nil
+	}
+	if node.networkId.String() == config.MainnetId {
+		return fmt.Errorf("chaos options not allowed on mainnet")
+	}
+	for _, p := range node.custom.Dev.ChaosCrashPoints {
+		switch p {
+		case ChaosCrashSnapshotWrite, ChaosCrashSnapshotWritten, ChaosCrashRoundStart:
+		default:
+			return fmt.Errorf("invalid chaos crash point %s", p)
+		}
+	}
+	return nil
+}
+
+func (node *Node) chaosStorageLatency() {
+	if ms := node.custom.Dev.ChaosStorageLatency; ms > 0 {
+		clock.Sleep(time.Duration(ms) * time.Millisecond)
+	}
+}
+
+// chaosCrash panics at the configured crash point by the crash rate, the
+// node must recover from the storage after restarted by the supervisor.
+func (node *Node) chaosCrash(point string) {
+	rate := node.custom.Dev.ChaosCrashRate
+	if rate <= 0 {
+		return
+	}
+	for _, p := range node.custom.Dev.ChaosCrashPoints {
+		if p == point && rand.Intn(100) < rate {
+			panic(fmt.Errorf("chaos crash at %s", point))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaos(t *testing.T) {
+	assert := assert.New(t)
+
+	node := &Node{custom: &config.Custom{}}
+	node.networkId, _ = crypto.HashFromString(config.MainnetId)
+	assert.False(node.chaosEnabled())
+	assert.Nil(node.checkChaos())
+
+	node.custom.Dev.ChaosMessageDrop = 10
+	assert.True(node.chaosEnabled())
+	assert.Contains(node.checkChaos().Error(), "not allowed on mainnet")
+	node.networkId = crypto.NewHash([]byte("testnet"))
+	assert.Nil(node.checkChaos())
+
+	node.custom.Dev.ChaosCrashPoints = []string{ChaosCrashRoundStart, "unknown"}
+	assert.Contains(node.checkChaos().Error(), "invalid chaos crash point unknown")
+	node.custom.Dev.ChaosCrashPoints = []string{ChaosCrashRoundStart}
+	assert.Nil(node.checkChaos())
+
+	assert.NotPanics(func() { node.chaosCrash(ChaosCrashRoundStart) })
+	node.custom.Dev.ChaosCrashRate = 100
+	assert.NotPanics(func() { node.chaosCrash(ChaosCrashSnapshotWrite) })
+	assert.Panics(func() { node.chaosCrash(ChaosCrashRoundStart) })
+}
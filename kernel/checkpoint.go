@@ -0,0 +1,119 @@
+package kernel
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	CheckpointStatePending      = "pending"
+	CheckpointStateVerified     = "verified"
+	CheckpointStateContradicted = "contradicted"
+)
+
+// CheckpointState is the pinned checkpoint verified against the local graph,
+// it's pending until the round is finalized, then either verified or
+// contradicted, and a contradicted checkpoint stops the node from starting.
+type CheckpointState struct {
+	Node     crypto.Hash
+	Round    uint64
+	Snapshot crypto.Hash
+	State    string
+}
+
+type checkpointsMap struct {
+	list      []*config.Checkpoint
+	rounds    map[crypto.Hash]map[uint64]crypto.Hash
+	snapshots map[crypto.Hash]*config.Checkpoint
+}
+
+func newCheckpointsMap(list []*config.Checkpoint) *checkpointsMap {
+	m := &checkpointsMap{
+		list:      list,
+		rounds:    make(map[crypto.Hash]map[uint64]crypto.Hash),
+		snapshots: make(map[crypto.Hash]*config.Checkpoint),
+	}
+	for _, cp := range list {
+		if m.rounds[cp.Node] == nil {
+			m.rounds[cp.Node] = make(map[uint64]crypto.Hash)
+		}
+		m.rounds[cp.Node][cp.Round] = cp.Snapshot
+		m.snapshots[cp.Snapshot] = cp
+	}
+	return m
+}
+
+// checkRound refuses the final round of the node without the pinned snapshot.
+func (m *checkpointsMap) checkRound(node crypto.Hash, number uint64, snapshots []*common.Snapshot) error {
+	pin, found := m.rounds[node][number]
+	if !found {
+		return nil
+	}
+	for _, s := range snapshots {
+		if s.Hash == pin {
+			return nil
+		}
+	}
+	return fmt.Errorf("checkpoint contradicted %s:%d:%s", node, number, pin)
+}
+
+// checkSnapshot refuses the pinned snapshot in any other round.
+func (m *checkpointsMap) checkSnapshot(s *common.Snapshot) error {
+	cp := m.snapshots[s.Hash]
+	if cp == nil || (cp.Node == s.NodeId && cp.Round == s.RoundNumber) {
+		return nil
+	}
+	return fmt.Errorf("checkpoint contradicted %s:%d:%s %s:%d", cp.Node, cp.Round, cp.Snapshot, s.NodeId, s.RoundNumber)
+}
+
+func (node *Node) verifyCheckpoints() error {
+	states, err := node.ListCheckpoints()
+	if err != nil {
+		return err
+	}
+	for _, cs := range states {
+		if cs.State == CheckpointStateContradicted {
+			return fmt.Errorf("checkpoint contradicted %s:%d:%s", cs.Node, cs.Round, cs.Snapshot)
+		}
+	}
+	return nil
+}
+
+// ListCheckpoints verifies all the pinned checkpoints against the rounds in
+// the store.
+func (node *Node) ListCheckpoints() ([]*CheckpointState, error) {
+	states := make([]*CheckpointState, len(node.checkpoints.list))
+	for i, cp := range node.checkpoints.list {
+		state, err := node.checkpointState(cp)
+		if err != nil {
+			return nil, err
+		}
+		states[i] = &CheckpointState{
+			Node:     cp.Node,
+			Round:    cp.Round,
+			Snapshot: cp.Snapshot,
+			State:    state,
+		}
+	}
+	return states, nil
+}
+
+func (node *Node) checkpointState(cp *config.Checkpoint) (string, error) {
+	head, err := loadHeadRoundForNode(node.persistStore, cp.Node)
+	if err != nil || head == nil || head.Number <= cp.Round {
+		return CheckpointStatePending, err
+	}
+	topos, err := node.persistStore.ReadSnapshotsForNodeRound(cp.Node, cp.Round)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range topos {
+		if t.PayloadHash() == cp.Snapshot {
+			return CheckpointStateVerified, nil
+		}
+	}
+	return CheckpointStateContradicted, nil
+}
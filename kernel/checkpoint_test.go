@@ -0,0 +1,34 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoints(t *testing.T) {
+	assert := assert.New(t)
+
+	node := crypto.NewHash([]byte("node"))
+	other := crypto.NewHash([]byte("other"))
+	pin := &common.Snapshot{NodeId: node, RoundNumber: 7, Timestamp: 1}
+	pin.Hash = pin.PayloadHash()
+	s := &common.Snapshot{NodeId: node, RoundNumber: 7, Timestamp: 2}
+	s.Hash = s.PayloadHash()
+
+	m := newCheckpointsMap([]*config.Checkpoint{{Node: node, Round: 7, Snapshot: pin.Hash}})
+	assert.Nil(m.checkRound(node, 6, []*common.Snapshot{s}))
+	assert.Nil(m.checkRound(other, 7, []*common.Snapshot{s}))
+	assert.Nil(m.checkRound(node, 7, []*common.Snapshot{s, pin}))
+	assert.NotNil(m.checkRound(node, 7, []*common.Snapshot{s}))
+
+	assert.Nil(m.checkSnapshot(s))
+	assert.Nil(m.checkSnapshot(pin))
+	forged := &common.Snapshot{NodeId: other, RoundNumber: 7, Hash: pin.Hash}
+	assert.NotNil(m.checkSnapshot(forged))
+	forged = &common.Snapshot{NodeId: node, RoundNumber: 8, Hash: pin.Hash}
+	assert.NotNil(m.checkSnapshot(forged))
+}
@@ -0,0 +1,31 @@
+package kernel
+
+import (
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// ConsensusNodeSet is the nodes able to sign the snapshots at a historical
+// timestamp, in the order of the keys of the cosi signature mask, and the
+// threshold to finalize the snapshots.
+type ConsensusNodeSet struct {
+	Timestamp uint64
+	Threshold int
+	Nodes     []*CNode
+	Keys      []*crypto.Key
+}
+
+// ListConsensusNodes returns the consensus node set at the timestamp, for the
+// explorers and auditors to verify the signatures of the old snapshots.
+func (node *Node) ListConsensusNodes(timestamp uint64) *ConsensusNodeSet {
+	set := &ConsensusNodeSet{
+		Timestamp: timestamp,
+		Threshold: node.ConsensusThreshold(timestamp, true),
+	}
+	for _, cn := range node.NodesListWithoutState(timestamp, false) {
+		if node.ConsensusReady(cn, timestamp) {
+			set.Nodes = append(set.Nodes, cn)
+			set.Keys = append(set.Keys, &cn.Signer.PublicSpendKey)
+		}
+	}
+	return set
+}
@@ -1,12 +1,10 @@
 package kernel
 
 import (
-	"crypto/rand"
 	"fmt"
 	"time"
 
 	"github.com/MixinNetwork/mixin/common"
-	"github.com/MixinNetwork/mixin/config"
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/kernel/internal/clock"
 	"github.com/MixinNetwork/mixin/logger"
@@ -53,7 +51,8 @@ type CosiAggregator struct {
 type CosiVerifier struct {
 	Snapshot   *common.Snapshot
 	Commitment *crypto.Key
-	random     *crypto.Key
+	random     []byte
+	challenge  crypto.Hash
 }
 
 func (chain *Chain) cosiHook(m *CosiAction) (bool, error) {
@@ -66,6 +65,7 @@ func (chain *Chain) cosiHook(m *CosiAction) (bool, error) {
 		return false, err
 	}
 	if m.Action != CosiActionFinalization {
+		chain.scheduleCosiState()
 		return false, nil
 	}
 	if m.finalized || !m.WantTx || m.PeerId == chain.node.IdForNetwork {
@@ -135,7 +135,7 @@ func (chain *Chain) checkActionSanity(m *CosiAction) error {
 			return fmt.Errorf("only empty snapshot with timestamp can be announced")
 		}
 		ov := chain.CosiVerifiers[s.Transaction]
-		if ov != nil && s.RoundNumber > 0 && ov.Snapshot.RoundNumber == s.RoundNumber && s.Timestamp < ov.Snapshot.Timestamp+config.SnapshotRoundGap {
+		if ov != nil && s.RoundNumber > 0 && ov.Snapshot.RoundNumber == s.RoundNumber && s.Timestamp < ov.Snapshot.Timestamp+chain.node.custom.RoundGap() {
 			return fmt.Errorf("a transaction %s only in one round %d of one chain %s", s.Transaction, s.RoundNumber, chain.ChainId)
 		}
 	case CosiActionExternalChallenge:
@@ -183,16 +183,24 @@ func (chain *Chain) checkActionSanity(m *CosiAction) error {
 		if s.RoundNumber > cache.Number+1 {
 			return fmt.Errorf("round future %d %d", s.RoundNumber, cache.Number)
 		}
-		if s.Timestamp <= final.Start+config.SnapshotRoundGap {
-			return fmt.Errorf("round timestamp invalid %d %d", s.Timestamp, final.Start+config.SnapshotRoundGap)
+		if s.Timestamp <= final.Start+chain.node.custom.RoundGap() {
+			return fmt.Errorf("round timestamp invalid %d %d", s.Timestamp, final.Start+chain.node.custom.RoundGap())
 		}
 		if m.SnapshotHash != s.Hash {
 			return fmt.Errorf("invalid snapshot hash %s %s", m.SnapshotHash, s.Hash)
 		}
-		threshold := config.SnapshotRoundGap * config.SnapshotReferenceThreshold
-		if s.Timestamp > uint64(clock.Now().UnixNano())+threshold {
+		now := clock.Now()
+		threshold := chain.node.custom.RoundGap() * chain.node.custom.ReferenceThreshold()
+		if s.Timestamp > uint64(now.UnixNano())+threshold {
+			if m.Action == CosiActionExternalAnnouncement {
+				chain.node.skew.future(m.PeerId, s.Timestamp, now)
+				logger.Verbosef("checkActionSanity(%s) future snapshot timestamp %d %d\n", m.PeerId, s.Timestamp, now.UnixNano())
+			}
 			return fmt.Errorf("future snapshot timestamp %d", s.Timestamp)
 		}
+		if m.Action == CosiActionExternalAnnouncement {
+			chain.node.skew.sample(m.PeerId, s.Timestamp, now)
+		}
 		if s.Timestamp+threshold*2 < chain.node.GraphTimestamp {
 			return fmt.Errorf("past snapshot timestamp %d", s.Timestamp)
 		}
@@ -241,8 +249,18 @@ func (chain *Chain) cosiSendAnnouncement(m *CosiAction) error {
 		if len(cache.Snapshots) == 0 && !chain.node.CheckBroadcastedToPeers() {
 			return nil
 		}
+		if chain.node.skew.pausing() {
+			logger.Verbosef("CosiLoop cosiHandleAction cosiSendAnnouncement %s paused by clock skew\n", s.Transaction)
+			return nil
+		}
 		if s.Timestamp <= cache.Timestamp {
-			return chain.clearAndQueueSnapshotOrPanic(s)
+			wait := time.Duration(cache.Timestamp - s.Timestamp + 1)
+			if wait > chain.node.skew.threshold {
+				logger.Verbosef("CosiLoop cosiHandleAction cosiSendAnnouncement %s behind cache round %s\n", s.Transaction, wait)
+				return nil
+			}
+			chain.scheduleAnnouncement(m, wait)
+			return nil
 		}
 
 		if len(cache.Snapshots) == 0 {
@@ -251,7 +269,7 @@ func (chain *Chain) cosiSendAnnouncement(m *CosiAction) error {
 				return err
 			}
 			best := chain.determinBestRound(s.Timestamp)
-			threshold := external.Timestamp + config.SnapshotReferenceThreshold*config.SnapshotRoundGap*36
+			threshold := external.Timestamp + chain.node.custom.ReferenceThreshold()*chain.node.custom.RoundGap()*36
 			if best != nil && best.NodeId != final.NodeId && threshold < best.Start {
 				logger.Verbosef("CosiLoop cosiHandleAction cosiSendAnnouncement new best external %s:%d:%d => %s:%d:%d\n", external.NodeId, external.Number, external.Timestamp, best.NodeId, best.Number, best.Start)
 				references := &common.RoundLink{Self: final.Hash, External: best.Hash}
@@ -262,7 +280,7 @@ func (chain *Chain) cosiSendAnnouncement(m *CosiAction) error {
 				}
 				return chain.clearAndQueueSnapshotOrPanic(s)
 			}
-		} else if start, _ := cache.Gap(); s.Timestamp >= start+config.SnapshotRoundGap {
+		} else if start, _ := cache.Gap(); s.Timestamp >= start+chain.node.custom.RoundGap() {
 			best := chain.determinBestRound(s.Timestamp)
 			if best == nil {
 				logger.Verbosef("CosiLoop cosiHandleAction cosiSendAnnouncement no best available\n")
@@ -285,7 +303,7 @@ func (chain *Chain) cosiSendAnnouncement(m *CosiAction) error {
 
 		if len(cache.Snapshots) > 0 {
 			cft := cache.Snapshots[0].Timestamp
-			if s.Timestamp > cft+uint64(config.SnapshotRoundGap*4/5) {
+			if s.Timestamp > cft+uint64(chain.node.custom.RoundGap()*4/5) {
 				return chain.clearAndQueueSnapshotOrPanic(s)
 			}
 			day := uint64(time.Hour) * 24
@@ -298,7 +316,7 @@ func (chain *Chain) cosiSendAnnouncement(m *CosiAction) error {
 	}
 
 	ov := chain.CosiVerifiers[s.Transaction]
-	if ov != nil && s.RoundNumber > 0 && ov.Snapshot.RoundNumber == s.RoundNumber && s.Timestamp < ov.Snapshot.Timestamp+config.SnapshotRoundGap {
+	if ov != nil && s.RoundNumber > 0 && ov.Snapshot.RoundNumber == s.RoundNumber && s.Timestamp < ov.Snapshot.Timestamp+chain.node.custom.RoundGap() {
 		err := fmt.Errorf("a transaction %s only in one round %d of one chain %s", s.Transaction, s.RoundNumber, chain.ChainId)
 		logger.Verbosef("CosiLoop cosiHandleAction cosiSendAnnouncement ERROR %s\n", err)
 		return nil
@@ -312,8 +330,12 @@ func (chain *Chain) cosiSendAnnouncement(m *CosiAction) error {
 		Commitments: make(map[int]*crypto.Key),
 		Responses:   make(map[int]*[32]byte),
 	}
-	v := &CosiVerifier{Snapshot: s, random: crypto.CosiCommit(rand.Reader)}
-	R := v.random.Public()
+	R, random, err := chain.node.signerBackend.CosiCommit(clock.Reader())
+	if err != nil {
+		logger.Verbosef("CosiLoop cosiHandleAction cosiSendAnnouncement CosiCommit ERROR %s\n", err)
+		return nil
+	}
+	v := &CosiVerifier{Snapshot: s, random: random}
 	chain.CosiVerifiers[s.Hash] = v
 	chain.CosiVerifiers[s.Transaction] = v
 	agg.Commitments[cd.CN.ConsensusIndex] = &R
@@ -347,8 +369,8 @@ func (chain *Chain) cosiHandleAnnouncement(m *CosiAction) error {
 			logger.Verbosef("CosiLoop cosiHandleAction cosiHandleAnnouncement %s %v in future %d %d\n", m.PeerId, m.Snapshot, s.RoundNumber, cache.Number)
 			return nil
 		}
-		if s.Timestamp <= final.Start+config.SnapshotRoundGap {
-			logger.Verbosef("CosiLoop cosiHandleAction cosiHandleAnnouncement %s %v invalid timestamp %d %d\n", m.PeerId, m.Snapshot, s.Timestamp, final.Start+config.SnapshotRoundGap)
+		if s.Timestamp <= final.Start+chain.node.custom.RoundGap() {
+			logger.Verbosef("CosiLoop cosiHandleAction cosiHandleAnnouncement %s %v invalid timestamp %d %d\n", m.PeerId, m.Snapshot, s.Timestamp, final.Start+chain.node.custom.RoundGap())
 			return nil
 		}
 		if s.RoundNumber == cache.Number && !s.References.Equal(cache.References) {
@@ -370,19 +392,28 @@ func (chain *Chain) cosiHandleAnnouncement(m *CosiAction) error {
 			}
 			cache, final = nc, nf
 			chain.CosiVerifiers = make(map[crypto.Hash]*CosiVerifier)
+			chain.throttle.reset()
 		}
 
-		if err := cache.ValidateSnapshot(s); err != nil {
+		if err := cache.ValidateSnapshot(s, chain.node.custom.RoundGap()); err != nil {
 			logger.Verbosef("CosiLoop cosiHandleAction cosiHandleAnnouncement %s %v ValidateSnapshot %s\n", m.PeerId, m.Snapshot, err)
 			return nil
 		}
 	}
+	if !chain.admitAnnouncement(m) {
+		logger.Verbosef("CosiLoop cosiHandleAction cosiHandleAnnouncement %s %v throttled\n", m.PeerId, m.Snapshot)
+		return nil
+	}
 
-	r := crypto.CosiCommit(rand.Reader)
-	v := &CosiVerifier{Snapshot: s, Commitment: m.Commitment, random: r}
+	R, random, err := chain.node.signerBackend.CosiCommit(clock.Reader())
+	if err != nil {
+		logger.Verbosef("CosiLoop cosiHandleAction cosiHandleAnnouncement CosiCommit ERROR %s\n", err)
+		return nil
+	}
+	v := &CosiVerifier{Snapshot: s, Commitment: m.Commitment, random: random}
 	chain.CosiVerifiers[s.Hash] = v
 	chain.CosiVerifiers[s.Transaction] = v
-	err := chain.node.Peer.SendSnapshotCommitmentMessage(s.NodeId, s.Hash, r.Public(), cd.TX == nil)
+	err = chain.node.Peer.SendSnapshotCommitmentMessage(s.NodeId, s.Hash, R, cd.TX == nil)
 	if err != nil {
 		logger.Verbosef("CosiLoop cosiHandleAction cosiHandleAnnouncement SendSnapshotCommitmentMessage(%s, %s) ERROR %s\n", s.NodeId, s.Hash, err.Error())
 	}
@@ -405,6 +436,7 @@ func (chain *Chain) cosiHandleCommitment(m *CosiAction) error {
 	}
 	ann.Commitments[cd.PN.ConsensusIndex] = m.Commitment
 	ann.WantTxs[m.PeerId] = m.WantTx
+	chain.node.signerStats.recordCommitment(s.Timestamp, m.PeerId)
 	logger.Verbosef("CosiLoop cosiHandleAction cosiHandleCommitment %v NOW %d %d\nn", m, len(ann.Commitments), base)
 	if len(ann.Commitments) < base {
 		return nil
@@ -417,11 +449,11 @@ func (chain *Chain) cosiHandleCommitment(m *CosiAction) error {
 	}
 	s.Signature = cosi
 	v := chain.CosiVerifiers[m.SnapshotHash]
-	priv := chain.node.Signer.PrivateSpendKey
 	_, publics := chain.ConsensusKeys(s.RoundNumber, s.Timestamp)
-	response, err := cosi.Response(&priv, v.random, publics, m.SnapshotHash[:])
+	response, err := chain.cosiRespond(v, cosi, publics, m.SnapshotHash[:])
 	if err != nil {
-		return err
+		logger.Verbosef("CosiLoop cosiHandleAction cosiHandleCommitment %v Response ERROR %s\n", m, err)
+		return nil
 	}
 	ann.Responses[cd.CN.ConsensusIndex] = response
 	copy(cosi.Signature[32:], response[:])
@@ -445,8 +477,13 @@ func (chain *Chain) cosiHandleCommitment(m *CosiAction) error {
 
 func (chain *Chain) cosiHandleChallenge(m *CosiAction) error {
 	logger.Verbosef("CosiLoop cosiHandleAction cosiHandleChallenge %v\n", m)
+	defer chain.releaseAnnouncement(m.SnapshotHash)
 	v := chain.CosiVerifiers[m.SnapshotHash]
 	s, cd := v.Snapshot, m.data
+	if v.random == nil {
+		logger.Verbosef("CosiLoop cosiHandleAction cosiHandleChallenge %v restored without random\n", m)
+		return nil
+	}
 
 	var sig crypto.Signature
 	copy(sig[:], v.Commitment[:])
@@ -463,11 +500,10 @@ func (chain *Chain) cosiHandleChallenge(m *CosiAction) error {
 		return nil
 	}
 
-	priv := chain.node.Signer.PrivateSpendKey
-	response, err := m.Signature.Response(&priv, v.random, publics, m.SnapshotHash[:])
+	response, err := chain.cosiRespond(v, m.Signature, publics, m.SnapshotHash[:])
 	if err != nil {
 		logger.Verbosef("CosiLoop cosiHandleAction cosiHandleChallenge %v Response ERROR %s\n", m, err)
-		return err
+		return nil
 	}
 	err = chain.node.Peer.SendSnapshotResponseMessage(m.PeerId, m.SnapshotHash, response)
 	if err != nil {
@@ -490,6 +526,7 @@ func (chain *Chain) cosiHandleResponse(m *CosiAction) error {
 	}
 	base := chain.node.ConsensusThreshold(s.Timestamp, false)
 	agg.Responses[cd.PN.ConsensusIndex] = m.Response
+	chain.node.signerStats.recordResponse(s.Timestamp, m.PeerId)
 	logger.Verbosef("CosiLoop cosiHandleAction cosiHandleResponse %v NOW %d %d %d\n", m, len(agg.Responses), len(agg.Commitments), base)
 	if len(agg.Responses) != len(agg.Commitments) {
 		return nil
@@ -528,7 +565,7 @@ func (chain *Chain) cosiHandleResponse(m *CosiAction) error {
 			logger.Verbosef("CosiLoop cosiHandleAction cosiHandleResponse %v REFERENCES %v %v\n", m, s.References, cache.References)
 			return nil
 		}
-		if err := cache.ValidateSnapshot(s); err != nil {
+		if err := cache.ValidateSnapshot(s, chain.node.custom.RoundGap()); err != nil {
 			logger.Verbosef("CosiLoop cosiHandleAction cosiHandleResponse %v ValidateSnapshot %s\n", m, err)
 			return nil
 		}
@@ -545,11 +582,8 @@ func (chain *Chain) cosiHandleResponse(m *CosiAction) error {
 				logger.Verbosef("CosiLoop cosiHandleAction cosiHandleResponse SendTransactionToPeer(%s, %s) ERROR %s\n", id, m.SnapshotHash, err.Error())
 			}
 		}
-		err := chain.node.Peer.SendSnapshotFinalizationMessage(id, s)
-		if err != nil {
-			logger.Verbosef("CosiLoop cosiHandleAction cosiHandleResponse SendSnapshotFinalizationMessage(%s, %s) ERROR %s\n", id, m.SnapshotHash, err.Error())
-		}
 	}
+	chain.node.fanoutSnapshotFinalization(s)
 	return chain.node.reloadConsensusNodesList(s, cd.TX)
 }
 
@@ -557,7 +591,12 @@ func (chain *Chain) cosiHandleFinalization(m *CosiAction) error {
 	logger.Debugf("CosiLoop cosiHandleAction handleFinalization %s %v\n", m.PeerId, m.Snapshot)
 	s := m.Snapshot
 	m.WantTx = false
+	chain.releaseAnnouncement(s.Hash)
 
+	if err := chain.node.checkpoints.checkSnapshot(s); err != nil {
+		logger.Printf("ERROR cosiHandleFinalization %s %s %s\n", m.PeerId, s.Hash, err.Error())
+		return nil
+	}
 	if chain.IsPledging() && s.RoundNumber == 0 {
 	} else if chain.State == nil {
 		logger.Debugf("ERROR cosiHandleFinalization without consensus%s %s\n", m.PeerId, s.Hash)
@@ -620,12 +659,13 @@ func (chain *Chain) cosiHandleFinalization(m *CosiAction) error {
 		return nil
 	}
 
-	if err := cache.ValidateSnapshot(s); err != nil {
+	if err := cache.ValidateSnapshot(s, chain.node.custom.RoundGap()); err != nil {
 		logger.Verbosef("ERROR cosiHandleFinalization ValidateSnapshot %s %v %s\n", m.PeerId, s, err.Error())
 		return nil
 	}
 	chain.AddSnapshot(final, cache, s, signers)
 	m.finalized = true
+	chain.node.fanoutSnapshotFinalization(s)
 	return chain.node.reloadConsensusNodesList(s, tx)
 }
 
@@ -635,6 +675,10 @@ func (node *Node) CosiQueueExternalAnnouncement(peerId crypto.Hash, s *common.Sn
 		logger.Verbosef("CosiQueueExternalAnnouncement(%s, %v) from malicious node\n", peerId, s)
 		return nil
 	}
+	if !node.limiters.announcement.Allow(peerId.String()) {
+		logger.Verbosef("CosiQueueExternalAnnouncement(%s, %v) rate limited\n", peerId, s)
+		return nil
+	}
 	chain := node.GetOrCreateChain(s.NodeId)
 
 	s.Hash = s.PayloadHash()
@@ -718,6 +762,14 @@ func (node *Node) VerifyAndQueueAppendSnapshotFinalization(peerId crypto.Hash, s
 		return nil
 	}
 
+	// the confirmation is always sent, otherwise the peer keeps resending
+	// the limited finalization, and only the verification and queueing are
+	// limited
+	if !node.limiters.finalization.Allow(peerId.String()) {
+		logger.Verbosef("VerifyAndQueueAppendSnapshotFinalization(%s, %s) rate limited\n", peerId, s.Hash)
+		return nil
+	}
+
 	tx, err := node.checkTxInStorage(s.Transaction)
 	if err != nil {
 		logger.Verbosef("VerifyAndQueueAppendSnapshotFinalization(%s, %s) check tx error %s\n", peerId, s.Hash, err)
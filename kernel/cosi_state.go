@@ -0,0 +1,283 @@
+package kernel
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	cosiStatePersistInterval = time.Second
+	cosiStateSecretLabel     = "MIXINCOSISTATE"
+)
+
+type cosiAggregatorState struct {
+	Snapshot    *common.Snapshot
+	WantTxs     map[crypto.Hash]bool
+	Commitments map[int]*crypto.Key
+	Responses   map[int]*[32]byte
+}
+
+// cosiVerifierState has the random sealed by the signer and encrypted again
+// by a key derived from the signer, and the challenge once responded, so a
+// restored verifier responds the same challenge and no other.
+type cosiVerifierState struct {
+	Keys       []crypto.Hash
+	Snapshot   *common.Snapshot
+	Commitment *crypto.Key
+	Random     []byte
+	Challenge  crypto.Hash
+}
+
+type cosiState struct {
+	Aggregators []*cosiAggregatorState
+	Verifiers   []*cosiVerifierState
+}
+
+type cosiStateWrite struct {
+	seq uint64
+	val []byte
+}
+
+func (chain *Chain) marshalCosiState() (*cosiStateWrite, error) {
+	aead, err := chain.cosiStateCipher()
+	if err != nil {
+		return nil, err
+	}
+	state := &cosiState{}
+	for _, agg := range chain.CosiAggregators {
+		state.Aggregators = append(state.Aggregators, &cosiAggregatorState{
+			Snapshot:    agg.Snapshot,
+			WantTxs:     agg.WantTxs,
+			Commitments: agg.Commitments,
+			Responses:   agg.Responses,
+		})
+	}
+
+	verifiers := make(map[*CosiVerifier][]crypto.Hash)
+	for k, v := range chain.CosiVerifiers {
+		verifiers[v] = append(verifiers[v], k)
+	}
+	for v, keys := range verifiers {
+		vs := &cosiVerifierState{
+			Keys:       keys,
+			Snapshot:   v.Snapshot,
+			Commitment: v.Commitment,
+			Challenge:  v.challenge,
+		}
+		if v.random != nil {
+			nonce := make([]byte, aead.NonceSize())
+			_, err := io.ReadFull(clock.Reader(), nonce)
+			if err != nil {
+				return nil, err
+			}
+			vs.Random = aead.Seal(nonce, nonce, v.random, v.Snapshot.Hash[:])
+		}
+		state.Verifiers = append(state.Verifiers, vs)
+	}
+	chain.cosiStateSeq++
+	val := common.CompressMsgpackMarshalPanic(state)
+	return &cosiStateWrite{seq: chain.cosiStateSeq, val: val}, nil
+}
+
+// cosiStateCipher encrypts the sealed randoms in the persisted state, the
+// sealed random is only useful to the signer, but the host should not keep
+// it in plain.
+func (chain *Chain) cosiStateCipher() (cipher.AEAD, error) {
+	if chain.cosiStateAEAD != nil {
+		return chain.cosiStateAEAD, nil
+	}
+	secret, err := chain.node.signerBackend.Secret(cosiStateSecretLabel + chain.ChainId.String())
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	chain.cosiStateAEAD = aead
+	return aead, nil
+}
+
+// writeCosiState never writes a state older than the last one written, so a
+// state marshaled before a challenge can't replace the one persisted with it.
+func (chain *Chain) writeCosiState(w *cosiStateWrite) error {
+	chain.cosiStateLock.Lock()
+	defer chain.cosiStateLock.Unlock()
+
+	if w.seq <= chain.cosiStateWritten {
+		return nil
+	}
+	err := chain.persistStore.CacheWriteCosiState(chain.ChainId, w.val)
+	if err != nil {
+		return err
+	}
+	chain.cosiStateWritten = w.seq
+	return nil
+}
+
+func (chain *Chain) persistCosiState() error {
+	w, err := chain.marshalCosiState()
+	if err != nil {
+		return err
+	}
+	return chain.writeCosiState(w)
+}
+
+// scheduleCosiState marshals the state at most once in the interval, and the
+// write is left to loopPersistCosiStates, so the cosi loop never waits for
+// the disk. Only the latest state is written if the writes fall behind.
+func (chain *Chain) scheduleCosiState() {
+	now := clock.Now()
+	if now.Sub(chain.cosiStateAt) < cosiStatePersistInterval {
+		return
+	}
+	chain.cosiStateAt = now
+	w, err := chain.marshalCosiState()
+	if err != nil {
+		logger.Verbosef("scheduleCosiState(%s) ERROR %s\n", chain.ChainId, err)
+		return
+	}
+	select {
+	case <-chain.cosiStates:
+	default:
+	}
+	chain.cosiStates <- w
+}
+
+// cosiRespond responds the challenge by the random of the verifier. The
+// challenge is persisted before the first response and waits for the disk,
+// so a restored verifier only responds the same challenge again, because two
+// responses of a random reveal the private key.
+func (chain *Chain) cosiRespond(v *CosiVerifier, cosi *crypto.CosiSignature, publics []*crypto.Key, message []byte) (*[32]byte, error) {
+	if v.random == nil {
+		return nil, fmt.Errorf("cosi verifier %s without random", v.Snapshot.Hash)
+	}
+	challenge, err := cosi.Challenge(publics, message)
+	if err != nil {
+		return nil, err
+	}
+	ch := crypto.NewHash(challenge.Bytes())
+	if !v.challenge.HasValue() {
+		v.challenge = ch
+		err = chain.persistCosiState()
+		if err != nil {
+			v.challenge = crypto.Hash{}
+			return nil, err
+		}
+	} else if v.challenge != ch {
+		return nil, fmt.Errorf("cosi verifier %s used for challenge %s", v.Snapshot.Hash, v.challenge)
+	}
+	return chain.node.signerBackend.CosiResponse(cosi, v.random, publics, message)
+}
+
+func (chain *Chain) loopPersistCosiStates() {
+	defer close(chain.slc)
+
+	for chain.running {
+		select {
+		case val := <-chain.cosiStates:
+			err := chain.writeCosiState(val)
+			if err != nil {
+				logger.Verbosef("loopPersistCosiStates(%s) ERROR %s\n", chain.ChainId, err)
+			}
+		default:
+			clock.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// loadCosiState restores the aggregators and verifiers of the rounds not
+// final yet, a verifier with the random could respond after the restart and
+// an aggregator not challenged yet needs the random of its own verifier.
+func (chain *Chain) loadCosiState() error {
+	val, err := chain.persistStore.CacheReadCosiState(chain.ChainId)
+	if err != nil || len(val) == 0 {
+		return err
+	}
+	var state cosiState
+	err = common.DecompressMsgpackUnmarshal(val, &state)
+	if err != nil {
+		return err
+	}
+	aead, err := chain.cosiStateCipher()
+	if err != nil {
+		return err
+	}
+
+	var round uint64
+	if chain.State != nil {
+		round = chain.State.CacheRound.Number
+	}
+
+	for _, vs := range state.Verifiers {
+		s := vs.Snapshot
+		if s.RoundNumber < round {
+			continue
+		}
+		s.Hash = s.PayloadHash()
+		v := &CosiVerifier{Snapshot: s, Commitment: vs.Commitment, challenge: vs.Challenge}
+		size := aead.NonceSize()
+		if len(vs.Random) > size {
+			random, err := aead.Open(nil, vs.Random[:size], vs.Random[size:], s.Hash[:])
+			if err != nil {
+				logger.Verbosef("loadCosiState(%s) verifier %s random %v\n", chain.ChainId, s.Hash, err)
+			}
+			v.random = random
+		}
+		for _, k := range vs.Keys {
+			chain.CosiVerifiers[k] = v
+		}
+	}
+
+	for _, as := range state.Aggregators {
+		s := as.Snapshot
+		if s.RoundNumber < round {
+			continue
+		}
+		s.Hash = s.PayloadHash()
+		v := chain.CosiVerifiers[s.Hash]
+		if s.Signature == nil && (v == nil || v.random == nil) {
+			logger.Verbosef("loadCosiState(%s) aggregator %s without random\n", chain.ChainId, s.Hash)
+			continue
+		}
+		tx, err := chain.node.checkTxInStorage(s.Transaction)
+		if err != nil || tx == nil {
+			logger.Verbosef("loadCosiState(%s) aggregator %s transaction %v %v\n", chain.ChainId, s.Hash, tx, err)
+			continue
+		}
+		if s.Signature != nil {
+			cosi, err := crypto.CosiAggregateCommitment(as.Commitments)
+			if err != nil {
+				logger.Verbosef("loadCosiState(%s) aggregator %s commitments %v\n", chain.ChainId, s.Hash, err)
+				continue
+			}
+			copy(cosi.Signature[32:], s.Signature.Signature[32:])
+			s.Signature = cosi
+		}
+		if v != nil {
+			v.Snapshot = s
+		}
+		chain.CosiAggregators[s.Hash] = &CosiAggregator{
+			Snapshot:    s,
+			Transaction: tx,
+			WantTxs:     as.WantTxs,
+			Commitments: as.Commitments,
+			Responses:   as.Responses,
+		}
+	}
+
+	logger.Printf("loadCosiState(%s) %d aggregators %d verifiers\n", chain.ChainId, len(chain.CosiAggregators), len(state.Verifiers))
+	return nil
+}
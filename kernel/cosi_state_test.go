@@ -0,0 +1,125 @@
+package kernel
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/signer"
+	"github.com/MixinNetwork/mixin/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCosiState(t *testing.T) {
+	assert := assert.New(t)
+
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+	store, err := storage.NewMemoryStore(custom)
+	assert.Nil(err)
+	defer store.Close()
+
+	tx := common.NewTransaction(common.XINAssetId).AsLatestVersion()
+	tx.Extra = []byte("cosi-state")
+	assert.Nil(store.CachePutTransaction(tx))
+
+	id := crypto.NewHash([]byte("cosi-state"))
+	seed := crypto.NewHash([]byte("cosi-state-signer"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	pub := key.Public()
+	node := &Node{persistStore: store, signerBackend: signer.NewLocal(key)}
+	build := func() *Chain {
+		return &Chain{
+			node:            node,
+			ChainId:         id,
+			CosiAggregators: make(map[crypto.Hash]*CosiAggregator),
+			CosiVerifiers:   make(map[crypto.Hash]*CosiVerifier),
+			persistStore:    store,
+			cosiStates:      make(chan *cosiStateWrite, 1),
+		}
+	}
+	snapshot := func(round uint64) *common.Snapshot {
+		s := &common.Snapshot{Version: common.SnapshotVersion, NodeId: id, Transaction: tx.PayloadHash(), RoundNumber: round}
+		s.Hash = s.PayloadHash()
+		return s
+	}
+
+	R := crypto.CosiCommit(rand.Reader).Public()
+	commitments := map[int]*crypto.Key{0: &R}
+	challenged, announced := snapshot(1), snapshot(2)
+	cosi, err := crypto.CosiAggregateCommitment(commitments)
+	assert.Nil(err)
+	challenged.Signature = cosi
+	chain := build()
+	for _, s := range []*common.Snapshot{challenged, announced} {
+		chain.CosiAggregators[s.Hash] = &CosiAggregator{
+			Snapshot:    s,
+			Transaction: tx,
+			WantTxs:     make(map[crypto.Hash]bool),
+			Commitments: commitments,
+			Responses:   map[int]*[32]byte{0: {1}},
+		}
+	}
+	VR, random, err := node.signerBackend.CosiCommit(rand.Reader)
+	assert.Nil(err)
+	v := &CosiVerifier{Snapshot: announced, Commitment: &R, random: random}
+	chain.CosiVerifiers[announced.Hash] = v
+	chain.CosiVerifiers[announced.Transaction] = v
+
+	chain.scheduleCosiState()
+	assert.Len(chain.cosiStates, 1)
+	at := chain.cosiStateAt
+	chain.scheduleCosiState()
+	assert.Equal(at, chain.cosiStateAt)
+	stale := <-chain.cosiStates
+	assert.False(bytes.Contains(stale.val, random))
+	assert.Nil(chain.writeCosiState(stale))
+
+	restored := build()
+	assert.Nil(restored.loadCosiState())
+	assert.Len(restored.CosiAggregators, 2)
+	agg := restored.CosiAggregators[challenged.Hash]
+	if assert.NotNil(agg) {
+		assert.Equal(tx.PayloadHash(), agg.Transaction.PayloadHash())
+		assert.Equal(challenged.Signature.Signature, agg.Snapshot.Signature.Signature)
+	}
+	assert.NotNil(restored.CosiAggregators[announced.Hash])
+	assert.Len(restored.CosiVerifiers, 2)
+	rv := restored.CosiVerifiers[announced.Transaction]
+	if assert.NotNil(rv) {
+		assert.Equal(R, *rv.Commitment)
+		assert.Equal(random, rv.random)
+		assert.False(rv.challenge.HasValue())
+		assert.Equal(rv, restored.CosiVerifiers[announced.Hash])
+	}
+
+	publics := []*crypto.Key{&pub}
+	cosi, err = crypto.CosiAggregateCommitment(map[int]*crypto.Key{0: &VR})
+	assert.Nil(err)
+	response, err := chain.cosiRespond(v, cosi, publics, announced.Hash[:])
+	assert.Nil(err)
+	assert.Nil(cosi.VerifyResponse(publics, 0, response, announced.Hash[:]))
+	assert.Nil(chain.writeCosiState(stale))
+
+	node.signerBackend = signer.NewLocal(key)
+	restored = build()
+	assert.Nil(restored.loadCosiState())
+	rv = restored.CosiVerifiers[announced.Hash]
+	if assert.NotNil(rv) {
+		assert.Equal(v.challenge, rv.challenge)
+		again, err := restored.cosiRespond(rv, cosi, publics, announced.Hash[:])
+		assert.Nil(err)
+		assert.Equal(response, again)
+		_, err = restored.cosiRespond(rv, cosi, publics, announced.Transaction[:])
+		assert.NotNil(err)
+	}
+
+	node.signerBackend = signer.NewLocal(crypto.NewKeyFromSeed(append(id[:], id[:]...)))
+	restored = build()
+	assert.Nil(restored.loadCosiState())
+	assert.Nil(restored.CosiVerifiers[announced.Hash].random)
+	assert.Nil(restored.CosiAggregators[announced.Hash])
+}
@@ -0,0 +1,68 @@
+package kernel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+const peerRecordExpiration = 24 * time.Hour
+
+// PeerRecord is the network address of a consensus node signed by its
+// signer key, it is relayed by the discovery of all the nodes.
+type PeerRecord struct {
+	Listener  string
+	Timestamp uint64
+	Signer    crypto.Key
+	Signature crypto.Signature
+}
+
+func (r *PeerRecord) payload() []byte {
+	return common.MsgpackMarshalPanic([]interface{}{
+		r.Listener,
+		r.Timestamp,
+		r.Signer,
+	})
+}
+
+func (node *Node) BuildPeerRecord() []byte {
+	r := &PeerRecord{
+		Listener:  node.Listener,
+		Timestamp: uint64(clock.Now().UnixNano()),
+		Signer:    node.Signer.PublicSpendKey,
+	}
+	r.Signature = node.sign(r.payload())
+	return common.MsgpackMarshalPanic(r)
+}
+
+func (node *Node) VerifyPeerRecord(msg []byte) (crypto.Hash, string, error) {
+	var r PeerRecord
+	err := common.MsgpackUnmarshal(msg, &r)
+	if err != nil {
+		return crypto.Hash{}, "", err
+	}
+
+	var signer common.Address
+	signer.PublicSpendKey = r.Signer
+	signer.PublicViewKey = signer.PublicSpendKey.DeterministicHashDerive().Public()
+	peerId := signer.Hash().ForNetwork(node.networkId)
+	cn := node.GetAcceptedOrPledgingNode(peerId)
+	if cn == nil || cn.Signer.PublicSpendKey != r.Signer {
+		return crypto.Hash{}, "", fmt.Errorf("peer record invalid consensus node %s", peerId)
+	}
+	now := uint64(clock.Now().UnixNano())
+	if r.Timestamp > now+config.SnapshotRoundGap*config.SnapshotReferenceThreshold {
+		return crypto.Hash{}, "", fmt.Errorf("peer record future timestamp %s %d", peerId, r.Timestamp)
+	}
+	if r.Timestamp+uint64(peerRecordExpiration) < now {
+		return crypto.Hash{}, "", fmt.Errorf("peer record expired %s %d", peerId, r.Timestamp)
+	}
+	if !r.Signer.Verify(r.payload(), r.Signature) {
+		return crypto.Hash{}, "", fmt.Errorf("peer record signature invalid %s", peerId)
+	}
+	return peerId, r.Listener, nil
+}
@@ -144,7 +144,10 @@ func (node *Node) buildNodeRemoveTransaction(nodeId crypto.Hash, timestamp uint6
 	if old != nil && candi.Transaction == old.PayloadHash() {
 		return old, nil
 	}
+	return node.buildNodeRemoveTransactionForCandidate(candi, timestamp, nil)
+}
 
+func (node *Node) buildNodeRemoveTransactionForCandidate(candi *CNode, timestamp uint64, endorsements []*common.NodeRemovalEndorsement) (*common.VersionedTransaction, error) {
 	accept, _, err := node.persistStore.ReadTransaction(candi.Transaction)
 	if err != nil {
 		return nil, err
@@ -166,7 +169,7 @@ func (node *Node) buildNodeRemoveTransaction(nodeId crypto.Hash, timestamp uint6
 
 	tx := common.NewTransaction(common.XINAssetId)
 	tx.AddInput(candi.Transaction, 0)
-	tx.Extra = accept.Extra
+	tx.Extra = append(accept.Extra, common.EncodeNodeRemovalEndorsements(endorsements)...)
 	script := common.NewThresholdScript(1)
 	in := fmt.Sprintf("NODEREMOVE%s", candi.Signer.String())
 	si := crypto.NewHash([]byte(candi.Payee.String() + in))
@@ -212,7 +215,16 @@ func (node *Node) validateNodeRemoveSnapshot(s *common.Snapshot, tx *common.Vers
 	if s.Timestamp == 0 && s.NodeId == node.IdForNetwork {
 		timestamp = uint64(clock.Now().UnixNano())
 	}
-	cantx, err := node.buildNodeRemoveTransaction(s.NodeId, timestamp, tx)
+	endorsements, err := tx.NodeRemovalEndorsements()
+	if err != nil {
+		return err
+	}
+	var cantx *common.VersionedTransaction
+	if len(endorsements) > 0 {
+		cantx, err = node.buildNodeRemovalProposal(s.NodeId, timestamp, tx.Inputs[0].Hash, endorsements)
+	} else {
+		cantx, err = node.buildNodeRemoveTransaction(s.NodeId, timestamp, tx)
+	}
 	if err != nil {
 		return err
 	}
@@ -222,6 +234,72 @@ func (node *Node) validateNodeRemoveSnapshot(s *common.Snapshot, tx *common.Vers
 	return nil
 }
 
+// checkRemovalProposalPossibility allows to remove any accepted node out of
+// the rolling removal order, when it has not finalized any round for the
+// offline period, and the threshold of the other accepted nodes endorse it.
+func (node *Node) checkRemovalProposalPossibility(nodeId crypto.Hash, now uint64, accept crypto.Hash, endorsements []*common.NodeRemovalEndorsement) (*CNode, error) {
+	if p := node.PledgingNode(now); p != nil {
+		return nil, fmt.Errorf("still pledging now %s", p.Signer.String())
+	}
+
+	var candi *CNode
+	signers := make(map[crypto.Key]*CNode)
+	for _, cn := range node.NodesListWithoutState(now, false) {
+		if cn.State != common.NodeStateAccepted {
+			continue
+		}
+		if cn.Transaction == accept {
+			candi = cn
+			continue
+		}
+		signers[cn.Signer.PublicSpendKey] = cn
+	}
+	if candi == nil {
+		return nil, fmt.Errorf("no accepted node to remove by %s", accept)
+	}
+	if len(signers) < config.KernelMinimumNodesCount {
+		return nil, fmt.Errorf("all old nodes removed %d", len(signers)+1)
+	}
+	if candi.IdForNetwork == nodeId {
+		return nil, fmt.Errorf("never handle the node remove transaction by the node self")
+	}
+
+	active := candi.Timestamp
+	if cs := node.GetOrCreateChain(candi.IdForNetwork).State; cs != nil && cs.FinalRound.End > active {
+		active = cs.FinalRound.End
+	}
+	if active+uint64(config.KernelNodeOfflinePeriodMinimum) > now {
+		return nil, fmt.Errorf("node %s still active at %d %d", candi.IdForNetwork, active, now)
+	}
+
+	for _, e := range endorsements {
+		if signers[e.Signer] == nil {
+			return nil, fmt.Errorf("invalid node removal endorsement signer %s", e.Signer)
+		}
+	}
+	if threshold := node.ConsensusThreshold(now, false); len(endorsements) < threshold {
+		return nil, fmt.Errorf("insufficient node removal endorsements %d %d", len(endorsements), threshold)
+	}
+	return candi, nil
+}
+
+func (node *Node) buildNodeRemovalProposal(nodeId crypto.Hash, timestamp uint64, accept crypto.Hash, endorsements []*common.NodeRemovalEndorsement) (*common.VersionedTransaction, error) {
+	candi, err := node.checkRemovalProposalPossibility(nodeId, timestamp, accept, endorsements)
+	if err != nil {
+		return nil, err
+	}
+	return node.buildNodeRemoveTransactionForCandidate(candi, timestamp, endorsements)
+}
+
+// BuildNodeRemovalProposal builds the node remove transaction for the node
+// accepted by the accept transaction, to be sent by any other node.
+func (node *Node) BuildNodeRemovalProposal(accept crypto.Hash, endorsements []*common.NodeRemovalEndorsement) (*common.VersionedTransaction, error) {
+	if len(endorsements) == 0 {
+		return nil, fmt.Errorf("no node removal endorsements")
+	}
+	return node.buildNodeRemovalProposal(crypto.Hash{}, node.GraphTimestamp, accept, endorsements)
+}
+
 func (chain *Chain) checkNodeAcceptPossibility(timestamp uint64, s *common.Snapshot, finalized bool) error {
 	ci, epoch := chain.ConsensusInfo, chain.node.Epoch
 	if chain.State != nil {
@@ -246,7 +324,7 @@ func (chain *Chain) checkNodeAcceptPossibility(timestamp uint64, s *common.Snaps
 		return fmt.Errorf("invalid node accept hour %d", hours%24)
 	}
 
-	threshold := config.SnapshotRoundGap * config.SnapshotReferenceThreshold
+	threshold := chain.node.custom.RoundGap() * chain.node.custom.ReferenceThreshold()
 	if !finalized && timestamp+threshold*2 < chain.node.GraphTimestamp {
 		return fmt.Errorf("invalid snapshot timestamp %d %d", chain.node.GraphTimestamp, timestamp)
 	}
@@ -359,6 +437,7 @@ func (node *Node) reloadConsensusNodesList(s *common.Snapshot, tx *common.Versio
 		common.TransactionTypeNodeAccept,
 		common.TransactionTypeNodeRemove:
 	default:
+		node.publishTransactionEvents(s, tx)
 		return nil
 	}
 	logger.Printf("reloadConsensusNodesList(%v, %v)\n", s, tx)
@@ -366,6 +445,7 @@ func (node *Node) reloadConsensusNodesList(s *common.Snapshot, tx *common.Versio
 	if err != nil {
 		return err
 	}
+	node.publishTransactionEvents(s, tx)
 
 	chain := node.GetOrCreateChain(s.NodeId)
 	err = chain.loadState()
@@ -403,7 +483,7 @@ func (node *Node) finalizeNodeAcceptSnapshot(s *common.Snapshot, signers []crypt
 		Number:    s.RoundNumber,
 		Timestamp: s.Timestamp,
 	}
-	if err := cache.validateSnapshot(s, true); err != nil {
+	if err := cache.validateSnapshot(s, node.custom.RoundGap(), true); err != nil {
 		panic("should never be here")
 	}
 	err := node.persistStore.StartNewRound(cache.NodeId, cache.Number, cache.References, cache.Timestamp)
@@ -421,7 +501,7 @@ func (node *Node) finalizeNodeAcceptSnapshot(s *common.Snapshot, signers []crypt
 	cache = &CacheRound{
 		NodeId:    s.NodeId,
 		Number:    1,
-		Timestamp: s.Timestamp + config.SnapshotRoundGap + 1,
+		Timestamp: s.Timestamp + node.custom.RoundGap() + 1,
 		References: &common.RoundLink{
 			Self:     final.Hash,
 			External: external.Hash,
@@ -438,6 +518,12 @@ func (node *Node) finalizeNodeAcceptSnapshot(s *common.Snapshot, signers []crypt
 	}
 	chain.StepForward()
 	chain.assignNewGraphRound(final, cache)
+	node.events.publish(&RoundStarted{
+		NodeId:     cache.NodeId,
+		Number:     cache.Number,
+		Timestamp:  cache.Timestamp,
+		References: cache.References.Copy(),
+	})
 	return nil
 }
 
@@ -527,7 +613,7 @@ func (node *Node) validateNodeCancelSnapshot(s *common.Snapshot, tx *common.Vers
 		return fmt.Errorf("invalid node cancel hour %d", hours%24)
 	}
 
-	threshold := config.SnapshotRoundGap * config.SnapshotReferenceThreshold
+	threshold := node.custom.RoundGap() * node.custom.ReferenceThreshold()
 	if !finalized && timestamp+threshold*2 < node.GraphTimestamp {
 		return fmt.Errorf("invalid snapshot timestamp %d %d", node.GraphTimestamp, timestamp)
 	}
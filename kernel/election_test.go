@@ -63,6 +63,44 @@ func TestNodeRemovePossibility(t *testing.T) {
 	assert.Equal(payee.PublicSpendKey.String(), crypto.ViewGhostOutputKey(ghost, &view, &mask, 0).String())
 }
 
+func TestNodeRemovalProposalPossibility(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := os.MkdirTemp("", "mixin-election-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	node := setupTestNode(assert, root)
+	assert.NotNil(node)
+
+	now, err := time.Parse(time.RFC3339, "2020-02-09T00:00:00Z")
+	assert.Nil(err)
+	var candi *CNode
+	for _, cn := range node.NodesListWithoutState(uint64(now.UnixNano()), false) {
+		if cn.IdForNetwork != node.IdForNetwork {
+			candi = cn
+		}
+	}
+	assert.NotNil(candi)
+
+	seed := crypto.NewHash([]byte("removal"))
+	other := common.SignNodeRemoval(crypto.NewKeyFromSeed(append(seed[:], seed[:]...)), candi.Transaction, uint64(now.UnixNano()))
+
+	_, err = node.checkRemovalProposalPossibility(node.IdForNetwork, uint64(now.UnixNano()), crypto.NewHash([]byte("accept")), nil)
+	assert.Contains(err.Error(), "no accepted node to remove")
+	_, err = node.checkRemovalProposalPossibility(candi.IdForNetwork, uint64(now.UnixNano()), candi.Transaction, nil)
+	assert.Contains(err.Error(), "by the node self")
+	_, err = node.checkRemovalProposalPossibility(node.IdForNetwork, node.Epoch+uint64(time.Hour*24), candi.Transaction, nil)
+	assert.Contains(err.Error(), "still active")
+	_, err = node.checkRemovalProposalPossibility(node.IdForNetwork, uint64(now.UnixNano()), candi.Transaction, []*common.NodeRemovalEndorsement{other})
+	assert.Contains(err.Error(), "invalid node removal endorsement signer")
+	_, err = node.checkRemovalProposalPossibility(node.IdForNetwork, uint64(now.UnixNano()), candi.Transaction, nil)
+	assert.Contains(err.Error(), "insufficient node removal endorsements 0")
+
+	_, err = node.BuildNodeRemovalProposal(candi.Transaction, nil)
+	assert.NotNil(err)
+}
+
 var configData = []byte(`[node]
 signer-key = "56a7904a2dfd71c397bb48584033d8cb6ddcde9b46b7d91f07d2ede061723a0b"
 consensus-only = true
@@ -0,0 +1,214 @@
+package kernel
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+type EventType int
+
+const (
+	EventSnapshotFinalized EventType = iota + 1
+	EventRoundStarted
+	EventNodeAccepted
+	EventMintDistributed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSnapshotFinalized:
+		return "snapshot_finalized"
+	case EventRoundStarted:
+		return "round_started"
+	case EventNodeAccepted:
+		return "node_accepted"
+	case EventMintDistributed:
+		return "mint_distributed"
+	}
+	return "unknown"
+}
+
+// Event is published by the consensus core after the state is persisted,
+// the subscribers type switch on the concrete event.
+type Event interface {
+	Type() EventType
+}
+
+type SnapshotFinalized struct {
+	Snapshot *common.SnapshotWithTopologicalOrder
+	Signers  []crypto.Hash
+}
+
+type RoundStarted struct {
+	NodeId     crypto.Hash
+	Number     uint64
+	Timestamp  uint64
+	References *common.RoundLink
+}
+
+type NodeAccepted struct {
+	NodeId      crypto.Hash
+	Signer      common.Address
+	Payee       common.Address
+	Transaction crypto.Hash
+	Timestamp   uint64
+}
+
+type MintDistributed struct {
+	Group       string
+	Batch       uint64
+	Amount      common.Integer
+	Transaction crypto.Hash
+	Timestamp   uint64
+}
+
+func (*SnapshotFinalized) Type() EventType { return EventSnapshotFinalized }
+func (*RoundStarted) Type() EventType      { return EventRoundStarted }
+func (*NodeAccepted) Type() EventType      { return EventNodeAccepted }
+func (*MintDistributed) Type() EventType   { return EventMintDistributed }
+
+// EventBusStats reports the events published of each type, and the events
+// dropped for the subscribers not keeping up.
+type EventBusStats struct {
+	Subscribers int
+	Published   map[string]uint64
+	Dropped     uint64
+}
+
+// EventSubscription receives the events of the subscribed types from C,
+// which is closed on unsubscribe. An event is dropped for the subscription
+// when C is full, so a slow subscriber never blocks the consensus core, and
+// it should recover from the persisted state if it can't miss any event.
+type EventSubscription struct {
+	dropped uint64
+	C       <-chan Event
+	c       chan Event
+	types   map[EventType]bool
+}
+
+func (s *EventSubscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// eventBus decouples the observers, e.g. the RPC streams and metrics, from
+// the consensus core, which only publishes to the bus.
+type eventBus struct {
+	sync.RWMutex
+	subscriptions map[*EventSubscription]bool
+	published     map[EventType]uint64
+	dropped       uint64
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		subscriptions: make(map[*EventSubscription]bool),
+		published:     make(map[EventType]uint64),
+	}
+}
+
+func (bus *eventBus) subscribe(size int, types ...EventType) *EventSubscription {
+	bus.Lock()
+	defer bus.Unlock()
+
+	c := make(chan Event, size)
+	s := &EventSubscription{C: c, c: c, types: make(map[EventType]bool)}
+	for _, t := range types {
+		s.types[t] = true
+	}
+	bus.subscriptions[s] = true
+	return s
+}
+
+func (bus *eventBus) unsubscribe(s *EventSubscription) {
+	bus.Lock()
+	defer bus.Unlock()
+
+	if bus.subscriptions[s] {
+		delete(bus.subscriptions, s)
+		close(s.c)
+	}
+}
+
+func (bus *eventBus) publish(e Event) {
+	if bus == nil {
+		return
+	}
+	bus.Lock()
+	defer bus.Unlock()
+
+	bus.published[e.Type()] += 1
+	for s := range bus.subscriptions {
+		if len(s.types) > 0 && !s.types[e.Type()] {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			dropped := atomic.AddUint64(&s.dropped, 1)
+			bus.dropped += 1
+			logger.Debugf("eventBus.publish %s dropped %d\n", e.Type(), dropped)
+		}
+	}
+}
+
+func (bus *eventBus) stats() EventBusStats {
+	bus.RLock()
+	defer bus.RUnlock()
+
+	published := make(map[string]uint64, len(bus.published))
+	for t, n := range bus.published {
+		published[t.String()] = n
+	}
+	return EventBusStats{
+		Subscribers: len(bus.subscriptions),
+		Published:   published,
+		Dropped:     bus.dropped,
+	}
+}
+
+// SubscribeEvents subscribes the events of the types, or all events if no
+// type, with a buffer of size events.
+func (node *Node) SubscribeEvents(size int, types ...EventType) *EventSubscription {
+	return node.events.subscribe(size, types...)
+}
+
+func (node *Node) UnsubscribeEvents(s *EventSubscription) {
+	node.events.unsubscribe(s)
+}
+
+func (node *Node) EventBusStats() EventBusStats {
+	return node.events.stats()
+}
+
+// publishTransactionEvents publishes the events of the finalized snapshot
+// transaction, after the consensus nodes reloaded.
+func (node *Node) publishTransactionEvents(s *common.Snapshot, tx *common.VersionedTransaction) {
+	switch tx.TransactionType() {
+	case common.TransactionTypeMint:
+		mint := tx.Inputs[0].Mint
+		node.events.publish(&MintDistributed{
+			Group:       mint.Group,
+			Batch:       mint.Batch,
+			Amount:      mint.Amount,
+			Transaction: s.Transaction,
+			Timestamp:   s.Timestamp,
+		})
+	case common.TransactionTypeNodeAccept:
+		for _, cn := range node.allNodesSortedWithState {
+			if cn.State != common.NodeStateAccepted || cn.Transaction != s.Transaction {
+				continue
+			}
+			node.events.publish(&NodeAccepted{
+				NodeId:      cn.IdForNetwork,
+				Signer:      cn.Signer,
+				Payee:       cn.Payee,
+				Transaction: cn.Transaction,
+				Timestamp:   cn.Timestamp,
+			})
+		}
+	}
+}
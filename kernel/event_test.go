@@ -0,0 +1,53 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+	assert := assert.New(t)
+
+	bus := newEventBus()
+	all := bus.subscribe(2)
+	rounds := bus.subscribe(1, EventRoundStarted)
+
+	id := crypto.NewHash([]byte("node"))
+	bus.publish(&RoundStarted{NodeId: id, Number: 7})
+	bus.publish(&SnapshotFinalized{Snapshot: &common.SnapshotWithTopologicalOrder{TopologicalOrder: 11}})
+	bus.publish(&RoundStarted{NodeId: id, Number: 8})
+
+	e := <-all.C
+	assert.Equal(EventRoundStarted, e.Type())
+	assert.Equal(uint64(7), e.(*RoundStarted).Number)
+	e = <-all.C
+	assert.Equal(EventSnapshotFinalized, e.Type())
+	assert.Equal(uint64(11), e.(*SnapshotFinalized).Snapshot.TopologicalOrder)
+	assert.Len(all.C, 0)
+	assert.Equal(uint64(1), all.Dropped())
+
+	e = <-rounds.C
+	assert.Equal(uint64(7), e.(*RoundStarted).Number)
+	assert.Len(rounds.C, 0)
+	assert.Equal(uint64(1), rounds.Dropped())
+
+	stats := bus.stats()
+	assert.Equal(2, stats.Subscribers)
+	assert.Equal(uint64(2), stats.Published["round_started"])
+	assert.Equal(uint64(1), stats.Published["snapshot_finalized"])
+	assert.Equal(uint64(2), stats.Dropped)
+
+	bus.unsubscribe(all)
+	bus.unsubscribe(all)
+	_, open := <-all.C
+	assert.False(open)
+	bus.publish(&MintDistributed{Batch: 1})
+	assert.Len(rounds.C, 0)
+	assert.Equal(1, bus.stats().Subscribers)
+
+	var nilBus *eventBus
+	nilBus.publish(&NodeAccepted{NodeId: id})
+}
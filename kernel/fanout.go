@@ -0,0 +1,162 @@
+package kernel
+
+import (
+	"math"
+	"sync"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+// FanoutStrategy decides the nodes to send the finalized snapshot of the
+// leader to. The nodes list is sorted the same on all nodes, so each node
+// knows its own targets without any coordination.
+type FanoutStrategy interface {
+	Targets(leader, self crypto.Hash, nodes []crypto.Hash) []crypto.Hash
+	Concurrency() int
+}
+
+// serialFanout is the leader sending to all nodes one by one.
+type serialFanout struct{}
+
+// parallelFanout is the leader sending to all nodes, with at most limit
+// sends at the same time.
+type parallelFanout struct {
+	limit int
+}
+
+// treeFanout splits the nodes except the leader into about √n groups, the
+// leader sends to the first node of each group, which relays to the others
+// in its group, so no node sends to more than about √n nodes. The groups
+// start after the leader in the sorted nodes, to spread the relay load of
+// different leaders. A node missed due to a failed relay catches up by the
+// graph sync as usual.
+type treeFanout struct {
+	limit int
+}
+
+func (f serialFanout) Targets(leader, self crypto.Hash, nodes []crypto.Hash) []crypto.Hash {
+	return fanoutAll(leader, self, nodes)
+}
+
+func (f serialFanout) Concurrency() int {
+	return 1
+}
+
+func (f parallelFanout) Targets(leader, self crypto.Hash, nodes []crypto.Hash) []crypto.Hash {
+	return fanoutAll(leader, self, nodes)
+}
+
+func (f parallelFanout) Concurrency() int {
+	return f.limit
+}
+
+func (f treeFanout) Targets(leader, self crypto.Hash, nodes []crypto.Hash) []crypto.Hash {
+	others := fanoutOthers(leader, nodes)
+	size := int(math.Ceil(math.Sqrt(float64(len(others)))))
+	if self == leader {
+		var heads []crypto.Hash
+		for i := 0; i < len(others); i += size {
+			heads = append(heads, others[i])
+		}
+		return heads
+	}
+	for i := 0; i < len(others); i += size {
+		if others[i] != self {
+			continue
+		}
+		end := i + size
+		if end > len(others) {
+			end = len(others)
+		}
+		return others[i+1 : end]
+	}
+	return nil
+}
+
+func (f treeFanout) Concurrency() int {
+	return f.limit
+}
+
+func fanoutAll(leader, self crypto.Hash, nodes []crypto.Hash) []crypto.Hash {
+	if self != leader {
+		return nil
+	}
+	return fanoutOthers(leader, nodes)
+}
+
+// fanoutOthers returns the nodes except the leader, starting after it.
+func fanoutOthers(leader crypto.Hash, nodes []crypto.Hash) []crypto.Hash {
+	for i, id := range nodes {
+		if id != leader {
+			continue
+		}
+		others := make([]crypto.Hash, 0, len(nodes)-1)
+		others = append(others, nodes[i+1:]...)
+		return append(others, nodes[:i]...)
+	}
+	return append([]crypto.Hash{}, nodes...)
+}
+
+// NewFanoutStrategy returns the configured strategy for the nodes count,
+// the auto strategy uses the tree only for the large networks.
+func NewFanoutStrategy(custom *config.Custom, count int) FanoutStrategy {
+	limit := custom.Network.FanoutConcurrency
+	switch custom.Network.FinalizationFanout {
+	case config.FanoutSerial:
+		return serialFanout{}
+	case config.FanoutParallel:
+		return parallelFanout{limit: limit}
+	case config.FanoutTree:
+		return treeFanout{limit: limit}
+	}
+	if count >= custom.Network.FanoutTreeSize {
+		return treeFanout{limit: limit}
+	}
+	return parallelFanout{limit: limit}
+}
+
+// fanoutSend calls send for all the targets, with at most concurrency calls
+// at the same time, and returns when all calls are done.
+func fanoutSend(targets []crypto.Hash, concurrency int, send func(id crypto.Hash)) {
+	if concurrency <= 1 {
+		for _, id := range targets {
+			send(id)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, id := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id crypto.Hash) {
+			defer wg.Done()
+			send(id)
+			<-sem
+		}(id)
+	}
+	wg.Wait()
+}
+
+// fanoutSnapshotFinalization sends the finalized snapshot to the targets of
+// this node, the leader always sends, and the other nodes relay only when
+// the strategy is the tree.
+func (node *Node) fanoutSnapshotFinalization(s *common.Snapshot) {
+	nodes := node.NodesListWithoutState(s.Timestamp, true)
+	ids := make([]crypto.Hash, len(nodes))
+	for i, cn := range nodes {
+		ids[i] = cn.IdForNetwork
+	}
+	fanout := NewFanoutStrategy(node.custom, len(ids))
+	targets := fanout.Targets(s.NodeId, node.IdForNetwork, ids)
+	fanoutSend(targets, fanout.Concurrency(), func(id crypto.Hash) {
+		err := node.Peer.SendSnapshotFinalizationMessage(id, s)
+		if err != nil {
+			logger.Verbosef("fanoutSnapshotFinalization SendSnapshotFinalizationMessage(%s, %s) ERROR %s\n", id, s.Hash, err.Error())
+		}
+	})
+}
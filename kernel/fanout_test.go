@@ -0,0 +1,119 @@
+package kernel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanoutTargets(t *testing.T) {
+	assert := assert.New(t)
+
+	nodes := testFanoutNodes(50)
+	leader, self := nodes[10], nodes[20]
+
+	for _, f := range []FanoutStrategy{serialFanout{}, parallelFanout{limit: 8}} {
+		targets := f.Targets(leader, leader, nodes)
+		assert.Len(targets, 49)
+		assert.Equal(nodes[11], targets[0])
+		assert.Equal(nodes[9], targets[48])
+		assert.Len(f.Targets(leader, self, nodes), 0)
+	}
+
+	tree := treeFanout{limit: 8}
+	heads := tree.Targets(leader, leader, nodes)
+	assert.Len(heads, 7)
+	assert.Equal(nodes[11], heads[0])
+	assert.Equal(nodes[18], heads[1])
+	reached := map[crypto.Hash]int{}
+	for _, h := range heads {
+		reached[h] += 1
+		targets := tree.Targets(leader, h, nodes)
+		assert.LessOrEqual(len(targets), 6)
+		for _, id := range targets {
+			reached[id] += 1
+			assert.Len(tree.Targets(leader, id, nodes), 0)
+		}
+	}
+	assert.Len(reached, 49)
+	for id, n := range reached {
+		assert.NotEqual(leader, id)
+		assert.Equal(1, n)
+	}
+	assert.Len(tree.Targets(leader, leader, nodes[10:11]), 0)
+	assert.Len(tree.Targets(leader, leader, nodes[10:12]), 1)
+
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+	assert.Equal(parallelFanout{limit: 8}, NewFanoutStrategy(custom, 63))
+	assert.Equal(treeFanout{limit: 8}, NewFanoutStrategy(custom, 64))
+	custom.Network.FinalizationFanout = config.FanoutSerial
+	assert.Equal(serialFanout{}, NewFanoutStrategy(custom, 64))
+	custom.Network.FinalizationFanout = config.FanoutTree
+	assert.Equal(treeFanout{limit: 8}, NewFanoutStrategy(custom, 4))
+}
+
+func TestFanoutPropagation(t *testing.T) {
+	assert := assert.New(t)
+
+	nodes := testFanoutNodes(65)
+	delay := 2 * time.Millisecond
+	serial := testFanoutPropagation(serialFanout{}, nodes, delay)
+	parallel := testFanoutPropagation(parallelFanout{limit: 8}, nodes, delay)
+	tree := testFanoutPropagation(treeFanout{limit: 8}, nodes, delay)
+	t.Logf("fanout propagation of %d nodes serial %s parallel %s tree %s\n", len(nodes), serial, parallel, tree)
+
+	assert.True(serial >= delay*64)
+	assert.True(parallel < serial/2)
+	assert.True(tree < serial/2)
+}
+
+// testFanoutPropagation returns the time for the finalization of the first
+// node to reach all the nodes, each send takes the delay to the target.
+func testFanoutPropagation(f FanoutStrategy, nodes []crypto.Hash, delay time.Duration) time.Duration {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	leader := nodes[0]
+	reached := map[crypto.Hash]time.Duration{leader: 0}
+	start := time.Now()
+
+	var deliver func(self crypto.Hash)
+	deliver = func(self crypto.Hash) {
+		defer wg.Done()
+		fanoutSend(f.Targets(leader, self, nodes), f.Concurrency(), func(id crypto.Hash) {
+			time.Sleep(delay)
+			mutex.Lock()
+			reached[id] = time.Since(start)
+			mutex.Unlock()
+			wg.Add(1)
+			go deliver(id)
+		})
+	}
+	wg.Add(1)
+	deliver(leader)
+	wg.Wait()
+
+	if len(reached) != len(nodes) {
+		panic(len(reached))
+	}
+	var latency time.Duration
+	for _, d := range reached {
+		if d > latency {
+			latency = d
+		}
+	}
+	return latency
+}
+
+func testFanoutNodes(count int) []crypto.Hash {
+	nodes := make([]crypto.Hash, count)
+	for i := range nodes {
+		nodes[i] = crypto.NewHash([]byte(fmt.Sprintf("FANOUT#%d", i)))
+	}
+	return nodes
+}
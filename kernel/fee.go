@@ -0,0 +1,41 @@
+package kernel
+
+import (
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// FeeStats is the fees finalized by all nodes in the day of a mint batch,
+// the same day as the mint works, and the total of them.
+type FeeStats struct {
+	Batch uint64
+	Day   uint64
+	Total *common.FeeLedgerEntry
+	Nodes map[crypto.Hash]*common.FeeLedgerEntry
+}
+
+// ListFeeStats aggregates the fee ledger entries of the mint batch by the
+// nodes which finalized the transactions.
+func (node *Node) ListFeeStats(batch uint64) (*FeeStats, error) {
+	day := node.mintBatchDay(batch)
+	entries, err := node.persistStore.ReadFeeLedgerByDay(day)
+	if err != nil {
+		return nil, err
+	}
+	stats := &FeeStats{
+		Batch: batch,
+		Day:   day,
+		Total: &common.FeeLedgerEntry{},
+		Nodes: make(map[crypto.Hash]*common.FeeLedgerEntry),
+	}
+	for _, e := range entries {
+		stats.Total.Merge(e)
+		ne := stats.Nodes[e.NodeId]
+		if ne == nil {
+			ne = &common.FeeLedgerEntry{NodeId: e.NodeId}
+			stats.Nodes[e.NodeId] = ne
+		}
+		ne.Merge(e)
+	}
+	return stats, nil
+}
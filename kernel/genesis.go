@@ -3,6 +3,7 @@ package kernel
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"time"
 
@@ -16,16 +17,39 @@ const (
 )
 
 type Genesis struct {
-	Epoch int64 `json:"epoch"`
-	Nodes []*struct {
-		Signer  common.Address `json:"signer"`
-		Payee   common.Address `json:"payee"`
-		Balance common.Integer `json:"balance"`
-	} `json:"nodes"`
-	Domains []*struct {
-		Signer  common.Address `json:"signer"`
-		Balance common.Integer `json:"balance"`
-	} `json:"domains"`
+	Epoch   int64            `json:"epoch"`
+	Nodes   []*GenesisNode   `json:"nodes"`
+	Domains []*GenesisDomain `json:"domains"`
+	Round   *GenesisRound    `json:"round,omitempty"`
+}
+
+type GenesisNode struct {
+	Signer  common.Address `json:"signer"`
+	Payee   common.Address `json:"payee"`
+	Balance common.Integer `json:"balance"`
+}
+
+type GenesisDomain struct {
+	Signer  common.Address `json:"signer"`
+	Balance common.Integer `json:"balance"`
+}
+
+// GenesisRound shortens the round gap in milliseconds and the reference
+// threshold for the test networks, and the mainnet genesis never has it.
+type GenesisRound struct {
+	Gap       uint64 `json:"gap"`
+	Threshold uint64 `json:"threshold"`
+}
+
+func (r *GenesisRound) gap() uint64 {
+	if r.Gap > math.MaxUint64/uint64(time.Millisecond) {
+		return math.MaxUint64
+	}
+	return r.Gap * uint64(time.Millisecond)
+}
+
+func (r *GenesisRound) Validate() error {
+	return config.VerifySnapshotRound(r.gap(), r.Threshold)
 }
 
 func (node *Node) LoadGenesis(configDir string) error {
@@ -34,12 +58,14 @@ func (node *Node) LoadGenesis(configDir string) error {
 		return err
 	}
 
-	data, err := json.Marshal(gns)
-	if err != nil {
-		return err
-	}
 	node.Epoch = uint64(time.Unix(gns.Epoch, 0).UnixNano())
-	node.networkId = crypto.NewHash(data)
+	node.networkId = gns.NetworkId()
+	if gns.Round != nil {
+		err = node.custom.SetSnapshotRound(gns.Round.gap(), gns.Round.Threshold)
+		if err != nil {
+			return err
+		}
+	}
 	node.IdForNetwork = node.Signer.Hash().ForNetwork(node.networkId)
 	for _, in := range gns.Nodes {
 		id := in.Signer.Hash().ForNetwork(node.networkId)
@@ -183,52 +209,119 @@ func buildDomainSnapshot(networkId crypto.Hash, epoch uint64, domain common.Addr
 	}, signed
 }
 
+// NewGenesis builds the genesis of the nodes with the pledge balance, and
+// the first signer as the domain, the payees must be in the same order.
+func NewGenesis(epoch int64, signers, payees []common.Address) *Genesis {
+	gns := &Genesis{Epoch: epoch}
+	for i := range signers {
+		gns.Nodes = append(gns.Nodes, &GenesisNode{
+			Signer:  signers[i],
+			Payee:   payees[i],
+			Balance: pledgeAmount(0),
+		})
+	}
+	if len(signers) > 0 {
+		gns.Domains = []*GenesisDomain{{
+			Signer:  signers[0],
+			Balance: common.NewInteger(50000),
+		}}
+	}
+	return gns
+}
+
+// NetworkId is the hash of the JSON encoded genesis, so any change to the
+// genesis file results in a different network.
+func (gns *Genesis) NetworkId() crypto.Hash {
+	data, err := json.Marshal(gns)
+	if err != nil {
+		panic(err)
+	}
+	return crypto.NewHash(data)
+}
+
 func readGenesis(path string) (*Genesis, error) {
 	f, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	return ParseGenesis(f)
+}
 
+func ParseGenesis(data []byte) (*Genesis, error) {
 	var gns Genesis
-	err = json.Unmarshal(f, &gns)
+	err := json.Unmarshal(data, &gns)
 	if err != nil {
 		return nil, err
 	}
+	err = gns.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &gns, nil
+}
+
+// Validate checks the genesis nodes and domain, the accept transactions
+// threshold must be satisfiable by the nodes, and the epoch must be a past
+// time in seconds.
+func (gns *Genesis) Validate() error {
 	if len(gns.Nodes) < MinimumNodeCount {
-		return nil, fmt.Errorf("invalid genesis inputs number %d/%d", len(gns.Nodes), MinimumNodeCount)
+		return fmt.Errorf("invalid genesis inputs number %d/%d", len(gns.Nodes), MinimumNodeCount)
+	}
+	if threshold := len(gns.Nodes)*2/3 + 1; threshold > math.MaxUint8 {
+		return fmt.Errorf("unsatisfiable genesis threshold %d/%d", threshold, len(gns.Nodes))
+	}
+	if gns.Epoch <= 0 || gns.Epoch > math.MaxInt64/int64(time.Second) {
+		return fmt.Errorf("invalid genesis epoch %d", gns.Epoch)
+	}
+	if epoch := time.Unix(gns.Epoch, 0); epoch.After(time.Now()) {
+		return fmt.Errorf("invalid genesis epoch %s in the future", epoch)
 	}
 
-	inputsFilter := make(map[string]bool)
+	inputsFilter := make(map[crypto.Key]string)
 	for _, in := range gns.Nodes {
 		_, err := common.NewAddressFromString(in.Signer.String())
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if in.Balance.Cmp(pledgeAmount(0)) != 0 {
-			return nil, fmt.Errorf("invalid genesis node input amount %s", in.Balance.String())
+			return fmt.Errorf("invalid genesis node input amount %s", in.Balance.String())
 		}
-		if inputsFilter[in.Signer.String()] {
-			return nil, fmt.Errorf("duplicated genesis node input %s", in.Signer.String())
+		if inputsFilter[in.Signer.PublicSpendKey] != "" {
+			return fmt.Errorf("duplicated genesis node input %s", in.Signer.String())
 		}
+		inputsFilter[in.Signer.PublicSpendKey] = in.Signer.String()
 		privateView := in.Signer.PublicSpendKey.DeterministicHashDerive()
 		if privateView.Public() != in.Signer.PublicViewKey {
-			return nil, fmt.Errorf("invalid node key format %s %s", privateView.Public().String(), in.Signer.PublicViewKey.String())
+			return fmt.Errorf("invalid node key format %s %s", privateView.Public().String(), in.Signer.PublicViewKey.String())
 		}
 		privateView = in.Payee.PublicSpendKey.DeterministicHashDerive()
 		if privateView.Public() != in.Payee.PublicViewKey {
-			return nil, fmt.Errorf("invalid node key format %s %s", privateView.Public().String(), in.Payee.PublicViewKey.String())
+			return fmt.Errorf("invalid node key format %s %s", privateView.Public().String(), in.Payee.PublicViewKey.String())
+		}
+	}
+	for _, in := range gns.Nodes {
+		if signer := inputsFilter[in.Payee.PublicSpendKey]; signer != "" {
+			return fmt.Errorf("duplicated genesis node payee %s signer %s", in.Payee.String(), signer)
 		}
 	}
 
 	if len(gns.Domains) != 1 {
-		return nil, fmt.Errorf("invalid genesis domain inputs count %d", len(gns.Domains))
+		return fmt.Errorf("invalid genesis domain inputs count %d", len(gns.Domains))
 	}
 	domain := gns.Domains[0]
 	if domain.Signer.String() != gns.Nodes[0].Signer.String() {
-		return nil, fmt.Errorf("invalid genesis domain input account %s %s", domain.Signer.String(), gns.Nodes[0].Signer.String())
+		return fmt.Errorf("invalid genesis domain input account %s %s", domain.Signer.String(), gns.Nodes[0].Signer.String())
 	}
 	if domain.Balance.Cmp(common.NewInteger(50000)) != 0 {
-		return nil, fmt.Errorf("invalid genesis domain input amount %s", domain.Balance.String())
+		return fmt.Errorf("invalid genesis domain input amount %s", domain.Balance.String())
 	}
-	return &gns, nil
+	if gns.Round == nil {
+		return nil
+	}
+	mainnet := *gns
+	mainnet.Round = nil
+	if mainnet.NetworkId().String() == config.MainnetId {
+		return fmt.Errorf("invalid genesis round for the mainnet")
+	}
+	return gns.Round.Validate()
 }
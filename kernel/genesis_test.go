@@ -2,6 +2,8 @@ package kernel
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -33,6 +35,18 @@ func TestGenesis(t *testing.T) {
 		assert.Equal(common.NodeStateAccepted, n.State)
 		assert.Equal(genesisNodes[i], n.IdForNetwork.String())
 	}
+	assert.Len(node.NodesListWithoutState(uint64(now.UnixNano()), false), 0)
+
+	set := node.ListConsensusNodes(uint64(now.UnixNano()) + 1)
+	assert.Len(set.Nodes, 15)
+	assert.Len(set.Keys, 15)
+	assert.Equal(11, set.Threshold)
+	for i, n := range set.Nodes {
+		assert.Equal(genesisNodes[i], n.IdForNetwork.String())
+		assert.Equal(n.Signer.PublicSpendKey, *set.Keys[i])
+	}
+	set = node.ListConsensusNodes(uint64(now.UnixNano()))
+	assert.Len(set.Nodes, 0)
 
 	snapshots, err := node.persistStore.ReadSnapshotsSinceTopology(0, 100)
 	assert.Nil(err)
@@ -55,6 +69,75 @@ func TestGenesis(t *testing.T) {
 	}
 }
 
+func TestGenesisValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := os.ReadFile("../config/genesis.json")
+	assert.Nil(err)
+	gns, err := ParseGenesis(data)
+	assert.Nil(err)
+	assert.Equal("6430225c42bb015b4da03102fa962e4f4ef3969e03e04345db229f8377ef7997", gns.NetworkId().String())
+	gns.Round = &GenesisRound{Gap: 500, Threshold: 2}
+	assert.Contains(gns.Validate().Error(), "invalid genesis round for the mainnet")
+	gns.Round = nil
+
+	var signers, payees []common.Address
+	for i := 0; i < MinimumNodeCount; i++ {
+		signers = append(signers, testGenesisAccount(fmt.Sprintf("SIGNER#%d", i)))
+		payees = append(payees, testGenesisAccount(fmt.Sprintf("PAYEE#%d", i)))
+	}
+	gns = NewGenesis(1551312000, signers, payees)
+	assert.Nil(gns.Validate())
+	data, err = json.Marshal(gns)
+	assert.Nil(err)
+	parsed, err := ParseGenesis(data)
+	assert.Nil(err)
+	assert.Equal(gns.NetworkId(), parsed.NetworkId())
+	gns.Epoch = 1551312001
+	assert.NotEqual(gns.NetworkId(), parsed.NetworkId())
+
+	gns = NewGenesis(1551312000, signers[:MinimumNodeCount-1], payees[:MinimumNodeCount-1])
+	assert.Contains(gns.Validate().Error(), "invalid genesis inputs number")
+	gns = NewGenesis(0, signers, payees)
+	assert.Contains(gns.Validate().Error(), "invalid genesis epoch")
+	gns = NewGenesis(time.Now().Add(time.Hour).Unix(), signers, payees)
+	assert.Contains(gns.Validate().Error(), "in the future")
+
+	duplicated := append([]common.Address{}, signers...)
+	duplicated[3] = signers[1]
+	gns = NewGenesis(1551312000, duplicated, payees)
+	assert.Contains(gns.Validate().Error(), "duplicated genesis node input")
+	duplicated = append([]common.Address{}, payees...)
+	duplicated[2] = signers[5]
+	gns = NewGenesis(1551312000, signers, duplicated)
+	assert.Contains(gns.Validate().Error(), "duplicated genesis node payee")
+
+	var many []common.Address
+	for i := 0; i < 384; i++ {
+		many = append(many, testGenesisAccount(fmt.Sprintf("MANY#%d", i)))
+	}
+	gns = NewGenesis(1551312000, many, many)
+	assert.Contains(gns.Validate().Error(), "unsatisfiable genesis threshold")
+
+	gns = NewGenesis(1551312000, signers, payees)
+	gns.Round = &GenesisRound{Gap: 500, Threshold: 1}
+	assert.Contains(gns.Validate().Error(), "invalid snapshot reference threshold")
+	gns.Round = &GenesisRound{Gap: 50, Threshold: 2}
+	assert.Contains(gns.Validate().Error(), "invalid snapshot round gap")
+	gns.Round = &GenesisRound{Gap: math.MaxUint64/uint64(time.Millisecond) + 1, Threshold: 2}
+	assert.Contains(gns.Validate().Error(), "invalid snapshot round gap")
+	gns.Round = &GenesisRound{Gap: 500, Threshold: 2}
+	assert.Nil(gns.Validate())
+}
+
+func testGenesisAccount(seed string) common.Address {
+	s := crypto.NewHash([]byte(seed))
+	account := common.NewAddressFromSeed(append(s[:], s[:]...))
+	account.PrivateViewKey = account.PublicSpendKey.DeterministicHashDerive()
+	account.PublicViewKey = account.PrivateViewKey.Public()
+	return account
+}
+
 type SnapshotJSON struct {
 	Version     uint8       `json:"version"`
 	NodeId      crypto.Hash `json:"node"`
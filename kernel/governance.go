@@ -0,0 +1,53 @@
+package kernel
+
+import (
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+// GovernanceTally sums the latest signal weights of the accepted nodes on a
+// proposal, the signals from the nodes not accepted anymore are excluded. The
+// proposal passes when the weight reaches the consensus threshold of the
+// maximum weight, i.e. all accepted nodes in full support.
+type GovernanceTally struct {
+	Proposal  crypto.Hash
+	Votes     []*common.GovernanceVote
+	Nodes     int
+	Weight    uint64
+	Threshold uint64
+	Passed    bool
+}
+
+func (node *Node) TallyGovernanceSignals(proposal crypto.Hash) (*GovernanceTally, error) {
+	votes, err := node.persistStore.ReadGovernanceVotes(proposal)
+	if err != nil {
+		return nil, err
+	}
+	nodes := node.NodesListWithoutState(uint64(clock.Now().UnixNano()), true)
+	return tallyGovernanceVotes(proposal, votes, nodes), nil
+}
+
+func tallyGovernanceVotes(proposal crypto.Hash, votes []*common.GovernanceVote, nodes []*CNode) *GovernanceTally {
+	signers := make(map[crypto.Key]bool)
+	for _, cn := range nodes {
+		signers[cn.Signer.PublicSpendKey] = true
+	}
+
+	tally := &GovernanceTally{
+		Proposal: proposal,
+		Votes:    make([]*common.GovernanceVote, 0),
+		Nodes:    len(signers),
+	}
+	for _, v := range votes {
+		if v.Proposal != proposal || !signers[v.Signer] {
+			continue
+		}
+		tally.Votes = append(tally.Votes, v)
+		tally.Weight += uint64(v.Weight)
+	}
+	maximum := uint64(tally.Nodes) * common.GovernanceSignalWeightMaximum
+	tally.Threshold = maximum*2/3 + 1
+	tally.Passed = tally.Nodes > 0 && tally.Weight >= tally.Threshold
+	return tally
+}
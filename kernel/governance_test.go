@@ -0,0 +1,52 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTallyGovernanceVotes(t *testing.T) {
+	assert := assert.New(t)
+
+	proposal := crypto.NewHash([]byte("proposal"))
+	nodes := make([]*CNode, 4)
+	for i := range nodes {
+		seed := crypto.NewHash([]byte{byte(i)})
+		key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+		nodes[i] = &CNode{Signer: common.Address{PublicSpendKey: key.Public()}}
+	}
+	tally := tallyGovernanceVotes(proposal, nil, nodes)
+	assert.Equal(4, tally.Nodes)
+	assert.Equal(uint64(0), tally.Weight)
+	assert.Equal(uint64(26667), tally.Threshold)
+	assert.False(tally.Passed)
+
+	votes := []*common.GovernanceVote{
+		{Proposal: proposal, Signer: nodes[0].Signer.PublicSpendKey, Weight: 10000},
+		{Proposal: proposal, Signer: nodes[1].Signer.PublicSpendKey, Weight: 10000},
+		{Proposal: proposal, Signer: nodes[2].Signer.PublicSpendKey, Weight: 6000},
+		{Proposal: crypto.NewHash([]byte("other")), Signer: nodes[3].Signer.PublicSpendKey, Weight: 10000},
+	}
+	tally = tallyGovernanceVotes(proposal, votes, nodes)
+	assert.Len(tally.Votes, 3)
+	assert.Equal(uint64(26000), tally.Weight)
+	assert.False(tally.Passed)
+
+	votes[2].Weight = 7000
+	tally = tallyGovernanceVotes(proposal, votes, nodes)
+	assert.Equal(uint64(27000), tally.Weight)
+	assert.True(tally.Passed)
+
+	tally = tallyGovernanceVotes(proposal, votes, nodes[1:])
+	assert.Len(tally.Votes, 2)
+	assert.Equal(uint64(17000), tally.Weight)
+	assert.Equal(uint64(20001), tally.Threshold)
+	assert.False(tally.Passed)
+
+	tally = tallyGovernanceVotes(proposal, votes, nil)
+	assert.Len(tally.Votes, 0)
+	assert.False(tally.Passed)
+}
@@ -37,11 +37,19 @@ func (chain *Chain) startNewRoundAndPersist(cache *CacheRound, references *commo
 		cache.References.External = dummyExternal
 	}
 
+	chain.node.chaosStorageLatency()
+	chain.node.chaosCrash(ChaosCrashRoundStart)
 	err = chain.persistStore.StartNewRound(cache.NodeId, cache.Number, cache.References, round.Start)
 	if err != nil {
 		panic(err)
 	}
 	chain.assignNewGraphRound(round, cache)
+	chain.node.events.publish(&RoundStarted{
+		NodeId:     cache.NodeId,
+		Number:     cache.Number,
+		Timestamp:  cache.Timestamp,
+		References: cache.References.Copy(),
+	})
 	return cache, round, dummy, nil
 }
 
@@ -56,6 +64,10 @@ func (chain *Chain) validateNewRound(cache *CacheRound, references *common.Round
 	if references.Self != final.Hash {
 		return nil, false, fmt.Errorf("self cache snapshots not match yet %s %s", chain.ChainId, references.Self)
 	}
+	err := chain.node.checkpoints.checkRound(chain.ChainId, cache.Number, cache.Snapshots)
+	if err != nil {
+		return nil, false, err
+	}
 
 	external, err := chain.persistStore.ReadRound(references.External)
 	if err != nil {
@@ -122,7 +134,7 @@ func (chain *Chain) updateExternal(final *FinalRound, external *common.Round, ro
 		if err != nil {
 			return fmt.Errorf("external refernce sanity %s", err)
 		}
-		threshold := external.Timestamp + config.SnapshotSyncRoundThreshold*config.SnapshotRoundGap*64
+		threshold := external.Timestamp + config.SnapshotSyncRoundThreshold*chain.node.custom.RoundGap()*64
 		best := chain.determinBestRound(roundTime)
 		if best != nil && threshold < best.Start {
 			return fmt.Errorf("external reference %s too early %s:%d %f", external.Hash, best.NodeId, best.Number, time.Duration(best.Start-threshold).Seconds())
@@ -163,13 +175,13 @@ func (chain *Chain) assignNewGraphRound(final *FinalRound, cache *CacheRound) {
 
 	chain.StepForward()
 	rounds = append(rounds, final.Copy())
-	chain.State.RoundHistory = reduceHistory(rounds)
+	chain.State.RoundHistory = reduceHistory(chain.node.custom, rounds)
 }
 
-func reduceHistory(rounds []*FinalRound) []*FinalRound {
+func reduceHistory(custom *config.Custom, rounds []*FinalRound) []*FinalRound {
 	last := rounds[len(rounds)-1]
-	threshold := config.SnapshotReferenceThreshold * config.SnapshotRoundGap * 64
-	if rounds[0].Start+threshold > last.Start && len(rounds) <= config.SnapshotReferenceThreshold {
+	threshold := custom.ReferenceThreshold() * custom.RoundGap() * 64
+	if rounds[0].Start+threshold > last.Start && len(rounds) <= int(custom.ReferenceThreshold()) {
 		return rounds
 	}
 	newRounds := make([]*FinalRound, 0)
@@ -179,7 +191,7 @@ func reduceHistory(rounds []*FinalRound) []*FinalRound {
 		}
 		newRounds = append(newRounds, r)
 	}
-	if rc := len(newRounds) - config.SnapshotReferenceThreshold; rc > 0 {
+	if rc := len(newRounds) - int(custom.ReferenceThreshold()); rc > 0 {
 		newRounds = newRounds[rc:]
 	}
 	return newRounds
@@ -226,7 +238,7 @@ func (chain *Chain) checkRefernceSanity(ec *Chain, external *common.Round, round
 	if external.Timestamp > roundTime {
 		return fmt.Errorf("external reference later than snapshot time %f", time.Duration(external.Timestamp-roundTime).Seconds())
 	}
-	if !chain.node.genesisNodesMap[external.NodeId] && external.Number < 7+config.SnapshotReferenceThreshold {
+	if !chain.node.genesisNodesMap[external.NodeId] && external.Number < 7+chain.node.custom.ReferenceThreshold() {
 		return fmt.Errorf("external hint round too early yet not genesis %d", external.Number)
 	}
 
@@ -252,16 +264,13 @@ func historySinceRound(history []*FinalRound, link uint64) []*FinalRound {
 func (node *Node) CacheVerify(snap crypto.Hash, sig crypto.Signature, pub crypto.Key) bool {
 	key := append(snap[:], sig[:]...)
 	key = append(key, pub[:]...)
-	value, found := node.cacheStore.Get(key)
+	hash := crypto.NewHash(key)
+	entry, found := node.signatures.get(hash)
 	if found {
-		return value.(byte) == byte(1)
+		return entry.valid
 	}
 	valid := pub.Verify(snap[:], sig)
-	if valid {
-		node.cacheStore.Set(key, byte(1), 1)
-	} else {
-		node.cacheStore.Set(key, byte(0), 1)
-	}
+	node.signatures.set(hash, valid, nil)
 	return valid
 }
 
@@ -301,16 +310,16 @@ func (node *Node) CacheVerifyCosi(snap crypto.Hash, sig *crypto.CosiSignature, c
 	key = append(key, tbuf...)
 	binary.BigEndian.PutUint64(tbuf, sig.Mask)
 	key = append(key, tbuf...)
-	value, found := node.cacheStore.Get(key)
+	hash := crypto.NewHash(key)
+	entry, found := node.signatures.get(hash)
 	if found {
-		signers := convertBytesToSigners(sig, value.([]byte))
-		return signers, len(signers) == len(sig.Keys())
+		return append([]crypto.Hash{}, entry.signers...), entry.valid
 	}
 
 	err := sig.FullVerify(publics, threshold, snap[:])
 	if err != nil {
 		logger.Verbosef("CacheVerifyCosi(%s, %d, %d) ERROR %s\n", snap, len(publics), threshold, err.Error())
-		node.cacheStore.Set(key, []byte{0}, 1)
+		node.signatures.set(hash, false, nil)
 		return nil, false
 	}
 
@@ -318,32 +327,10 @@ func (node *Node) CacheVerifyCosi(snap crypto.Hash, sig *crypto.CosiSignature, c
 	for i, k := range sig.Keys() {
 		signers[i] = cids[k]
 	}
-	vb := convertSignersToBytes(signers)
-	node.cacheStore.Set(key, vb, int64(len(vb)))
+	node.signatures.set(hash, true, signers)
 	return signers, true
 }
 
-func convertBytesToSigners(sig *crypto.CosiSignature, b []byte) []crypto.Hash {
-	if len(b) != len(sig.Keys())*len(crypto.Hash{}) {
-		return nil
-	}
-	signers := make([]crypto.Hash, len(sig.Keys()))
-	for i := 0; i < len(signers); i++ {
-		var h crypto.Hash
-		copy(h[:], b[i*32:i*32+32])
-		signers[i] = h
-	}
-	return signers
-}
-
-func convertSignersToBytes(signers []crypto.Hash) []byte {
-	var b []byte
-	for _, h := range signers {
-		b = append(b, h[:]...)
-	}
-	return b
-}
-
 func (chain *Chain) ConsensusKeys(round, timestamp uint64) ([]crypto.Hash, []*crypto.Key) {
 	var signers []crypto.Hash
 	var publics []*crypto.Key
@@ -375,22 +362,30 @@ func (chain *Chain) verifyFinalization(s *common.Snapshot) ([]crypto.Hash, bool)
 		return signers, finalized
 	}
 
-	// FIXME remove this hack
+	cids, publics, removed := chain.removedConsensusKeys(s, cids, publics)
+	if !removed {
+		return nil, finalized
+	}
+	return chain.node.CacheVerifyCosi(s.Hash, s.Signature, cids, publics, base)
+}
+
+// removedConsensusKeys prepends the node removed right before the snapshot
+// to the consensus keys, because its signature may still finalize snapshots.
+// FIXME remove this hack
+func (chain *Chain) removedConsensusKeys(s *common.Snapshot, cids []crypto.Hash, publics []*crypto.Key) ([]crypto.Hash, []*crypto.Key, bool) {
 	nodes := chain.node.NodesListWithoutState(s.Timestamp, false)
 	rn := nodes[len(nodes)-1]
 	if rn.State != common.NodeStateRemoved {
-		return nil, finalized
+		return cids, publics, false
 	}
 	timestamp := s.Timestamp - uint64(config.KernelNodeAcceptPeriodMinimum)
 	if rn.Timestamp < timestamp {
-		return nil, finalized
+		return cids, publics, false
 	}
 
 	rs := []crypto.Hash{rn.IdForNetwork}
 	rk := []*crypto.Key{&rn.Signer.PublicSpendKey}
-	cids = append(rs, cids...)
-	publics = append(rk, publics...)
-	return chain.node.CacheVerifyCosi(s.Hash, s.Signature, cids, publics, base)
+	return append(rs, cids...), append(rk, publics...), true
 }
 
 func (chain *Chain) legacyVerifyFinalization(timestamp uint64, sigs []*crypto.Signature) bool {
@@ -0,0 +1,174 @@
+package kernel
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	RoundGraphFormatDOT     = "dot"
+	RoundGraphFormatGraphML = "graphml"
+
+	RoundGraphDumpLimit = 1000
+)
+
+// GraphRound is a final round in the round graph dump, the references are
+// the ones of its snapshots, i.e. the round links chosen by the best round
+// selection when the round started.
+type GraphRound struct {
+	NodeId     crypto.Hash
+	Number     uint64
+	Hash       crypto.Hash
+	Start      uint64
+	End        uint64
+	Snapshots  int
+	References *common.RoundLink
+}
+
+// DumpRoundGraph walks the persisted final rounds of all chains back from
+// their latest final rounds, at most count rounds for each chain.
+func (node *Node) DumpRoundGraph(count uint64) ([]*GraphRound, error) {
+	if count == 0 || count > RoundGraphDumpLimit {
+		return nil, fmt.Errorf("invalid round graph count %d", count)
+	}
+	_, finals := node.LoadRoundGraph()
+	var rounds []*GraphRound
+	for id, f := range finals {
+		for i := uint64(0); i < count && i <= f.Number; i++ {
+			topos, err := node.persistStore.ReadSnapshotsForNodeRound(id, f.Number-i)
+			if err != nil {
+				return nil, err
+			}
+			if len(topos) == 0 {
+				continue
+			}
+			snapshots := make([]*common.Snapshot, len(topos))
+			for j, t := range topos {
+				s := &t.Snapshot
+				s.Hash = s.PayloadHash()
+				snapshots[j] = s
+			}
+			start, end, hash := ComputeRoundHash(id, f.Number-i, snapshots)
+			rounds = append(rounds, &GraphRound{
+				NodeId:     id,
+				Number:     f.Number - i,
+				Hash:       hash,
+				Start:      start,
+				End:        end,
+				Snapshots:  len(snapshots),
+				References: snapshots[0].References,
+			})
+		}
+	}
+	sort.Slice(rounds, func(i, j int) bool {
+		a, b := rounds[i], rounds[j]
+		if a.NodeId != b.NodeId {
+			return a.NodeId.String() < b.NodeId.String()
+		}
+		return a.Number < b.Number
+	})
+	return rounds, nil
+}
+
+// EncodeRoundGraph renders the rounds grouped by nodes, with the solid self
+// reference edges and the dashed external reference edges, and the edges to
+// the rounds not dumped are omitted.
+func EncodeRoundGraph(rounds []*GraphRound, format string) (string, error) {
+	switch format {
+	case RoundGraphFormatDOT:
+		return encodeRoundGraphDOT(rounds), nil
+	case RoundGraphFormatGraphML:
+		return encodeRoundGraphML(rounds), nil
+	}
+	return "", fmt.Errorf("invalid round graph format %s", format)
+}
+
+type roundGraphEdge struct {
+	from     crypto.Hash
+	to       crypto.Hash
+	external bool
+}
+
+func roundGraphEdges(rounds []*GraphRound) []*roundGraphEdge {
+	filter := make(map[crypto.Hash]bool)
+	for _, r := range rounds {
+		filter[r.Hash] = true
+	}
+	var edges []*roundGraphEdge
+	for _, r := range rounds {
+		if r.References == nil {
+			continue
+		}
+		if filter[r.References.Self] {
+			edges = append(edges, &roundGraphEdge{r.Hash, r.References.Self, false})
+		}
+		if filter[r.References.External] {
+			edges = append(edges, &roundGraphEdge{r.Hash, r.References.External, true})
+		}
+	}
+	return edges
+}
+
+func encodeRoundGraphDOT(rounds []*GraphRound) string {
+	var b strings.Builder
+	b.WriteString("digraph rounds {\n")
+	b.WriteString("  rankdir=RL;\n")
+	b.WriteString("  node [shape=box];\n")
+	for i := 0; i < len(rounds); {
+		id := rounds[i].NodeId
+		fmt.Fprintf(&b, "  subgraph \"cluster_%s\" {\n", id)
+		fmt.Fprintf(&b, "    label=\"%s\";\n", id.String()[:8])
+		for ; i < len(rounds) && rounds[i].NodeId == id; i++ {
+			r := rounds[i]
+			fmt.Fprintf(&b, "    \"%s\" [label=\"%d\\n%d snapshots\"];\n", r.Hash, r.Number, r.Snapshots)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, e := range roundGraphEdges(rounds) {
+		style := "solid"
+		if e.external {
+			style = "dashed"
+		}
+		fmt.Fprintf(&b, "  \"%s\" -> \"%s\" [style=%s];\n", e.from, e.to, style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func encodeRoundGraphML(rounds []*GraphRound) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString("<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	keys := [][2]string{{"node", "string"}, {"number", "long"}, {"start", "long"}, {"end", "long"}, {"snapshots", "int"}}
+	for _, k := range keys {
+		fmt.Fprintf(&b, "  <key id=\"%s\" for=\"node\" attr.name=\"%s\" attr.type=\"%s\"/>\n", k[0], k[0], k[1])
+	}
+	b.WriteString("  <key id=\"reference\" for=\"edge\" attr.name=\"reference\" attr.type=\"string\"/>\n")
+	b.WriteString("  <graph id=\"rounds\" edgedefault=\"directed\">\n")
+	for _, r := range rounds {
+		fmt.Fprintf(&b, "    <node id=\"%s\">\n", r.Hash)
+		fmt.Fprintf(&b, "      <data key=\"node\">%s</data>\n", r.NodeId)
+		fmt.Fprintf(&b, "      <data key=\"number\">%d</data>\n", r.Number)
+		fmt.Fprintf(&b, "      <data key=\"start\">%d</data>\n", r.Start)
+		fmt.Fprintf(&b, "      <data key=\"end\">%d</data>\n", r.End)
+		fmt.Fprintf(&b, "      <data key=\"snapshots\">%d</data>\n", r.Snapshots)
+		b.WriteString("    </node>\n")
+	}
+	for _, e := range roundGraphEdges(rounds) {
+		reference := "self"
+		if e.external {
+			reference = "external"
+		}
+		fmt.Fprintf(&b, "    <edge source=\"%s\" target=\"%s\">\n", e.from, e.to)
+		fmt.Fprintf(&b, "      <data key=\"reference\">%s</data>\n", reference)
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n")
+	b.WriteString("</graphml>\n")
+	return b.String()
+}
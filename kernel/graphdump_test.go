@@ -0,0 +1,62 @@
+package kernel
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeRoundGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := crypto.NewHash([]byte("node-a")), crypto.NewHash([]byte("node-b"))
+	a0, a1 := crypto.NewHash([]byte("a0")), crypto.NewHash([]byte("a1"))
+	b0 := crypto.NewHash([]byte("b0"))
+	missing := crypto.NewHash([]byte("missing"))
+	rounds := []*GraphRound{
+		{NodeId: a, Number: 0, Hash: a0, Snapshots: 1, References: &common.RoundLink{Self: missing, External: missing}},
+		{NodeId: a, Number: 1, Hash: a1, Snapshots: 3, References: &common.RoundLink{Self: a0, External: b0}},
+		{NodeId: b, Number: 0, Hash: b0, Snapshots: 2, References: &common.RoundLink{Self: missing, External: a0}},
+	}
+
+	dot, err := EncodeRoundGraph(rounds, RoundGraphFormatDOT)
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(dot, "digraph rounds {\n"))
+	assert.Equal(2, strings.Count(dot, "subgraph"))
+	assert.Contains(dot, "\""+a1.String()+"\" [label=\"1\\n3 snapshots\"];")
+	assert.Contains(dot, "\""+a1.String()+"\" -> \""+a0.String()+"\" [style=solid];")
+	assert.Contains(dot, "\""+a1.String()+"\" -> \""+b0.String()+"\" [style=dashed];")
+	assert.Contains(dot, "\""+b0.String()+"\" -> \""+a0.String()+"\" [style=dashed];")
+	assert.NotContains(dot, missing.String())
+	assert.Equal(3, strings.Count(dot, "->"))
+
+	ml, err := EncodeRoundGraph(rounds, RoundGraphFormatGraphML)
+	assert.Nil(err)
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				Id string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+				Data   string `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	err = xml.Unmarshal([]byte(ml), &doc)
+	assert.Nil(err)
+	assert.Len(doc.Graph.Nodes, 3)
+	assert.Len(doc.Graph.Edges, 3)
+	assert.Equal(a1.String(), doc.Graph.Edges[0].Source)
+	assert.Equal(a0.String(), doc.Graph.Edges[0].Target)
+	assert.Equal("self", doc.Graph.Edges[0].Data)
+	assert.Equal("external", doc.Graph.Edges[1].Data)
+
+	_, err = EncodeRoundGraph(rounds, "json")
+	assert.NotNil(err)
+}
@@ -0,0 +1,72 @@
+package kernel
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+const (
+	HealthStateNone = "none"
+
+	healthSyncLagMaximum = 10 * time.Minute
+)
+
+// NodeHealth is a cheap probe of the node for load balancers and
+// orchestration, the node is ready only when it has caught up with the
+// peers and its graph timestamp lags the wall clock within some minutes.
+type NodeHealth struct {
+	Ready     bool
+	Synced    bool
+	Lag       time.Duration
+	Peers     map[string]int
+	Caches    uint64
+	Finals    uint64
+	DiskUsage int64
+	State     string
+}
+
+func (node *Node) Health() (*NodeHealth, error) {
+	usage, err := dirDiskUsage(node.configDir)
+	if err != nil {
+		return nil, err
+	}
+	h := &NodeHealth{
+		Synced:    node.CheckCatchUpWithPeers(),
+		Lag:       clock.Now().Sub(time.Unix(0, int64(node.GraphTimestamp))),
+		Peers:     node.Peer.PeerStateCounts(),
+		DiskUsage: usage,
+		State:     HealthStateNone,
+	}
+	h.Caches, h.Finals, _ = node.QueueState()
+	if cn := node.GetAcceptedOrPledgingNode(node.IdForNetwork); cn != nil {
+		h.State = cn.State
+	}
+	h.Ready = h.Synced && h.Lag < healthSyncLagMaximum
+	return h, nil
+}
+
+// dirDiskUsage sums the file sizes in the directory, the files removed by
+// the compaction or value log gc during the walk are skipped.
+func dirDiskUsage(dir string) (int64, error) {
+	var usage int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		usage += info.Size()
+		return nil
+	})
+	return usage, err
+}
@@ -0,0 +1,31 @@
+package kernel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirDiskUsage(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	usage, err := dirDiskUsage(dir)
+	assert.Nil(err)
+	assert.Equal(int64(0), usage)
+
+	err = os.MkdirAll(filepath.Join(dir, "snapshots"), 0755)
+	assert.Nil(err)
+	err = os.WriteFile(filepath.Join(dir, "config.toml"), make([]byte, 100), 0644)
+	assert.Nil(err)
+	err = os.WriteFile(filepath.Join(dir, "snapshots", "000001.vlog"), make([]byte, 1000), 0644)
+	assert.Nil(err)
+	usage, err = dirDiskUsage(dir)
+	assert.Nil(err)
+	assert.Equal(int64(1100), usage)
+
+	_, err = dirDiskUsage(filepath.Join(dir, "missing"))
+	assert.Nil(err)
+}
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/MixinNetwork/mixin/common"
-	"github.com/MixinNetwork/mixin/config"
 	"github.com/MixinNetwork/mixin/kernel/internal/clock"
 	"github.com/MixinNetwork/mixin/logger"
 	"github.com/MixinNetwork/mixin/storage"
@@ -66,7 +65,7 @@ func (node *Node) Import(configDir string, source storage.Store) error {
 func (chain *Chain) importFrom(source storage.Store) (uint64, error) {
 	var threshold, round uint64
 	filter := make(map[uint64]time.Time)
-	period := time.Duration(config.SnapshotRoundGap)
+	period := time.Duration(chain.node.custom.RoundGap())
 	for {
 		if cs := chain.State; cs != nil {
 			threshold = cs.CacheRound.Number
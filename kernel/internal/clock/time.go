@@ -1,8 +1,14 @@
 package clock
 
 import (
+	"bytes"
+	"container/heap"
+	"crypto/rand"
 	"fmt"
-	"strings"
+	"io"
+	mrand "math/rand"
+	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,10 +21,17 @@ var (
 	inTest   bool
 	mutex    *sync.RWMutex
 	mockDiff time.Duration
+
+	simulating   bool
+	virtualNow   time.Time
+	entropy      *mrand.Rand
+	timers       timerQueue
+	participants map[uint64]bool
+	running      int
 )
 
 func init() {
-	inTest = strings.Contains(config.BuildVersion, "BUILD_VERSION")
+	inTest = config.InTestBuild()
 	mutex = new(sync.RWMutex)
 	mockDiff = 0
 }
@@ -31,6 +44,9 @@ func Reset() {
 	mutex.Lock()
 	defer mutex.Unlock()
 	mockDiff = 0
+	simulating = false
+	entropy = nil
+	resetTimers()
 }
 
 func MockDiff(at time.Duration) {
@@ -43,6 +59,30 @@ func MockDiff(at time.Duration) {
 	mockDiff += at
 }
 
+// Simulate switches to a virtual clock starting at start, which only moves
+// forward by MockDiff or Sleep, and makes Reader deterministic from seed.
+// The simulated goroutines are started by Go, and the clock jumps to the
+// earliest deadline of the sleepers once all of them are sleeping.
+func Simulate(start time.Time, seed int64) {
+	if !inTest {
+		panic(fmt.Errorf("clock simulation not allowed in build version %s", config.BuildVersion))
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	mockDiff = 0
+	simulating = true
+	virtualNow = start
+	entropy = mrand.New(mrand.NewSource(seed))
+	resetTimers()
+}
+
+func Simulating() bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return simulating
+}
+
 func Now() time.Time {
 	if !inTest {
 		return time.Now()
@@ -50,5 +90,148 @@ func Now() time.Time {
 
 	mutex.RLock()
 	defer mutex.RUnlock()
+	if simulating {
+		return virtualNow.Add(mockDiff)
+	}
 	return time.Now().Add(mockDiff)
 }
+
+// Go starts f as a simulated goroutine, which holds the virtual clock unless
+// sleeping, otherwise it is the same as the go statement. A simulated
+// goroutine must only wait by Sleep, or the clock never moves.
+func Go(f func()) {
+	if !Simulating() {
+		go f()
+		return
+	}
+
+	mutex.Lock()
+	running++
+	mutex.Unlock()
+	go func() {
+		id := goroutineId()
+		mutex.Lock()
+		participants[id] = true
+		mutex.Unlock()
+		defer func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if participants[id] {
+				delete(participants, id)
+				running--
+				advance()
+			}
+		}()
+		f()
+	}()
+}
+
+// Sleep waits until the virtual clock reaches the deadline in simulation,
+// otherwise it is the same as time.Sleep. A goroutine not started by Go never
+// holds the clock, and its sleep only ends at the deadline.
+func Sleep(d time.Duration) {
+	if !inTest {
+		time.Sleep(d)
+		return
+	}
+
+	mutex.Lock()
+	if !simulating {
+		mutex.Unlock()
+		time.Sleep(d)
+		return
+	}
+	t := &timer{deadline: virtualNow.Add(d), wake: make(chan struct{})}
+	if participants[goroutineId()] {
+		t.participant = true
+		running--
+	}
+	heap.Push(&timers, t)
+	advance()
+	mutex.Unlock()
+	<-t.wake
+}
+
+type timer struct {
+	deadline    time.Time
+	wake        chan struct{}
+	participant bool
+}
+
+type timerQueue []*timer
+
+func (q timerQueue) Len() int            { return len(q) }
+func (q timerQueue) Less(i, j int) bool  { return q[i].deadline.Before(q[j].deadline) }
+func (q timerQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *timerQueue) Push(x interface{}) { *q = append(*q, x.(*timer)) }
+func (q *timerQueue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return t
+}
+
+// advance wakes the sleepers due, and jumps to the earliest deadline only
+// when no simulated goroutine is running, must be called with the lock.
+func advance() {
+	for len(timers) > 0 {
+		t := timers[0]
+		if t.deadline.After(virtualNow) {
+			if running > 0 {
+				return
+			}
+			virtualNow = t.deadline
+		}
+		heap.Pop(&timers)
+		if t.participant {
+			running++
+		}
+		close(t.wake)
+	}
+}
+
+func resetTimers() {
+	for _, t := range timers {
+		close(t.wake)
+	}
+	timers = nil
+	participants = make(map[uint64]bool)
+	running = 0
+}
+
+func goroutineId() uint64 {
+	b := make([]byte, 64)
+	b = b[:runtime.Stack(b, false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// Reader returns the entropy source for cosi randoms and other secrets,
+// the seeded deterministic one in simulation.
+func Reader() io.Reader {
+	if !inTest {
+		return rand.Reader
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if simulating {
+		return entropyReader{}
+	}
+	return rand.Reader
+}
+
+type entropyReader struct{}
+
+func (entropyReader) Read(b []byte) (int, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if entropy == nil {
+		return rand.Read(b)
+	}
+	return entropy.Read(b)
+}
@@ -0,0 +1,72 @@
+package clock
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulate(t *testing.T) {
+	assert := assert.New(t)
+	defer Reset()
+
+	start := time.Unix(1551312000, 0)
+	Simulate(start, 7)
+	assert.True(Simulating())
+	assert.Equal(start, Now())
+
+	begin := time.Now()
+	Sleep(time.Hour)
+	assert.Less(time.Since(begin), time.Second)
+	assert.Equal(start.Add(time.Hour), Now())
+	MockDiff(time.Minute)
+	assert.Equal(start.Add(time.Hour+time.Minute), Now())
+
+	a := make([]byte, 32)
+	_, err := io.ReadFull(Reader(), a)
+	assert.Nil(err)
+	Simulate(start, 7)
+	b := make([]byte, 32)
+	_, err = io.ReadFull(Reader(), b)
+	assert.Nil(err)
+	assert.Equal(a, b)
+	assert.Equal(start, Now())
+
+	Reset()
+	assert.False(Simulating())
+	assert.WithinDuration(time.Now(), Now(), time.Second)
+}
+
+func TestSimulateSleepers(t *testing.T) {
+	assert := assert.New(t)
+	defer Reset()
+
+	start := time.Unix(1551312000, 0)
+	Simulate(start, 7)
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	woken := make(map[time.Duration][]time.Time)
+	for _, d := range []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour} {
+		wg.Add(1)
+		d := d
+		Go(func() {
+			defer wg.Done()
+			for i := 0; i < 2; i++ {
+				Sleep(d)
+				lock.Lock()
+				woken[d] = append(woken[d], Now())
+				lock.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal([]time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)}, woken[time.Hour])
+	assert.Equal([]time.Time{start.Add(2 * time.Hour), start.Add(4 * time.Hour)}, woken[2*time.Hour])
+	assert.Equal([]time.Time{start.Add(3 * time.Hour), start.Add(6 * time.Hour)}, woken[3*time.Hour])
+	assert.Equal(start.Add(6*time.Hour), Now())
+}
@@ -0,0 +1,48 @@
+package kernel
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/light"
+)
+
+// BuildFinalizationBundle proves the finalization of the transaction to a
+// light wallet with the consensus keys which finalized its snapshot.
+func (node *Node) BuildFinalizationBundle(hash crypto.Hash) (*light.Bundle, error) {
+	ver, snap, err := node.persistStore.ReadTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	if ver == nil || snap == "" {
+		return nil, fmt.Errorf("transaction not finalized %s", hash)
+	}
+	sh, err := crypto.HashFromString(snap)
+	if err != nil {
+		return nil, err
+	}
+	s, err := node.persistStore.ReadSnapshot(sh)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("snapshot not found %s", sh)
+	}
+	if s.Version != common.SnapshotVersion || s.Signature == nil {
+		return nil, fmt.Errorf("legacy snapshot version %d %s", s.Version, sh)
+	}
+
+	chain := node.GetOrCreateChain(s.NodeId)
+	_, publics := chain.ConsensusKeys(s.RoundNumber, s.Timestamp)
+	threshold := node.ConsensusThreshold(s.Timestamp, true)
+	err = s.Signature.FullVerify(publics, threshold, sh[:])
+	if err != nil {
+		_, publics, _ = chain.removedConsensusKeys(&s.Snapshot, nil, publics)
+		err = s.Signature.FullVerify(publics, threshold, sh[:])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("snapshot finalization keys not found %s %s", sh, err)
+	}
+	return light.NewBundle(s.VersionedPayload(), s.Signature, threshold, publics, ver.PayloadMarshal()), nil
+}
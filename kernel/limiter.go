@@ -0,0 +1,26 @@
+package kernel
+
+import (
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/util"
+)
+
+type peerLimiters struct {
+	announcement *util.RateLimiter
+	finalization *util.RateLimiter
+}
+
+func newPeerLimiters(custom *config.Custom) *peerLimiters {
+	ar, fr := custom.Network.AnnouncementRate, custom.Network.FinalizationRate
+	return &peerLimiters{
+		announcement: util.NewRateLimiter(ar, ar*10),
+		finalization: util.NewRateLimiter(fr, fr*10),
+	}
+}
+
+func (node *Node) LimiterStats() map[string]map[string]util.RateLimiterStats {
+	return map[string]map[string]util.RateLimiterStats{
+		"announcement": node.limiters.announcement.Stats(),
+		"finalization": node.limiters.finalization.Stats(),
+	}
+}
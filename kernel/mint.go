@@ -184,6 +184,11 @@ func (node *Node) buildMintTransaction(timestamp uint64, validateOnly bool) *com
 		logger.Printf("buildMintTransaction ERROR %s\n", err.Error())
 		return nil
 	}
+	err = node.modifyMintPayees(mints, timestamp)
+	if err != nil {
+		logger.Printf("buildMintTransaction ERROR %s\n", err.Error())
+		return nil
+	}
 
 	tx := common.NewTransaction(common.XINAssetId)
 	tx.AddKernelNodeMintInput(uint64(batch), amount)
@@ -281,15 +286,7 @@ func (node *Node) checkMintPossibility(timestamp uint64, validateOnly bool) (int
 		return 0, common.Zero
 	}
 
-	pool := MintPool
-	for i := 0; i < batch/MintYearBatches; i++ {
-		pool = pool.Sub(pool.Div(MintYearShares))
-	}
-	pool = pool.Div(MintYearShares)
-	total := pool.Div(MintYearBatches)
-	light := total.Div(10)
-	full := light.Mul(9)
-
+	pool, total, light, full := mintBatchAmount(batch)
 	dist, err := node.persistStore.ReadLastMintDistribution(common.MintGroupKernelNode)
 	if err != nil {
 		logger.Verbosef("ReadLastMintDistribution ERROR %s\n", err)
@@ -312,6 +309,20 @@ func (node *Node) checkMintPossibility(timestamp uint64, validateOnly bool) (int
 	return batch, amount
 }
 
+// mintBatchAmount returns the year pool share of the batch, the total batch
+// amount, and its light and full parts, the full part goes to the kernel nodes.
+func mintBatchAmount(batch int) (common.Integer, common.Integer, common.Integer, common.Integer) {
+	pool := MintPool
+	for i := 0; i < batch/MintYearBatches; i++ {
+		pool = pool.Sub(pool.Div(MintYearShares))
+	}
+	pool = pool.Div(MintYearShares)
+	total := pool.Div(MintYearBatches)
+	light := total.Div(10)
+	full := light.Mul(9)
+	return pool, total, light, full
+}
+
 type CNodeWork struct {
 	CNode
 	Work common.Integer
@@ -324,11 +335,16 @@ func (node *Node) ListMintWorks(batch uint64) (map[crypto.Hash][2]uint64, error)
 	for i, n := range list {
 		cids[i] = n.IdForNetwork
 	}
-	day := now / (uint64(time.Hour) * 24)
-	works, err := node.persistStore.ListNodeWorks(cids, uint32(day))
+	works, err := node.persistStore.ListNodeWorks(cids, uint32(node.mintBatchDay(batch)))
 	return works, err
 }
 
+// mintBatchDay is the day since the unix epoch of the mint batch.
+func (node *Node) mintBatchDay(batch uint64) uint64 {
+	now := node.Epoch + batch*uint64(time.Hour*24)
+	return now / (uint64(time.Hour) * 24)
+}
+
 // a = average work
 // for x > 7a, y = 2a
 // for 7a > x > a, y = 1/6x + 5/6a
@@ -0,0 +1,68 @@
+package kernel
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+const mintSimulationMaximumBatches = 365 * 4
+
+type MintSimulationBatch struct {
+	Batch  uint64
+	Amount common.Integer
+	Node   common.Integer
+}
+
+// MintSimulation forecasts the kernel node mint distributions of a batch
+// range, with the accepted nodes at the time of simulation. The future works
+// are unknown, so all nodes are assumed to have the average work, which is
+// the expected distribution before the works adjustment.
+type MintSimulation struct {
+	Nodes   []*CNodeWork
+	Batches []*MintSimulationBatch
+	Amount  common.Integer
+}
+
+func (node *Node) SimulateMint(start, end uint64) (*MintSimulation, error) {
+	if start < 1 || end < start {
+		return nil, fmt.Errorf("invalid mint simulation batches %d %d", start, end)
+	}
+	if end-start >= mintSimulationMaximumBatches {
+		return nil, fmt.Errorf("invalid mint simulation batches %d %d", start, end)
+	}
+	dist, err := node.persistStore.ReadLastMintDistribution(common.MintGroupKernelNode)
+	if err != nil {
+		return nil, err
+	}
+	if start <= dist.Batch {
+		return nil, fmt.Errorf("mint simulation batch %d already distributed %d", start, dist.Batch)
+	}
+
+	accepted := node.NodesListWithoutState(uint64(clock.Now().UnixNano()), true)
+	if len(accepted) == 0 {
+		return nil, fmt.Errorf("mint simulation without accepted nodes")
+	}
+	sim := &MintSimulation{
+		Nodes:  make([]*CNodeWork, len(accepted)),
+		Amount: common.NewInteger(0),
+	}
+	for i, n := range accepted {
+		sim.Nodes[i] = &CNodeWork{CNode: *n, Work: common.NewInteger(0)}
+	}
+	for batch := start; batch <= end; batch++ {
+		_, _, _, full := mintBatchAmount(int(batch))
+		b := &MintSimulationBatch{
+			Batch:  batch,
+			Amount: full,
+			Node:   full.Div(len(accepted)),
+		}
+		for _, n := range sim.Nodes {
+			n.Work = n.Work.Add(b.Node)
+		}
+		sim.Batches = append(sim.Batches, b)
+		sim.Amount = sim.Amount.Add(full)
+	}
+	return sim, nil
+}
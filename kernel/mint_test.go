@@ -208,3 +208,39 @@ func testBuildMintSnapshots(signers []crypto.Hash, round, timestamp uint64) []*c
 	}
 	return snapshots
 }
+
+func TestMintSimulation(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, _, full := mintBatchAmount(364)
+	assert.Equal(common.NewIntegerFromString("123.28767117"), full)
+	_, _, _, full = mintBatchAmount(365)
+	assert.Equal(common.NewIntegerFromString("110.95890408"), full)
+
+	root, err := os.MkdirTemp("", "mixin-mint-simulation-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	node := setupTestNode(assert, root)
+	assert.NotNil(node)
+
+	_, err = node.SimulateMint(0, 1)
+	assert.NotNil(err)
+	_, err = node.SimulateMint(2, 1)
+	assert.NotNil(err)
+	_, err = node.SimulateMint(1, 365*4+1)
+	assert.NotNil(err)
+
+	sim, err := node.SimulateMint(364, 365)
+	assert.Nil(err)
+	assert.Len(sim.Nodes, 15)
+	assert.Len(sim.Batches, 2)
+	assert.Equal(uint64(364), sim.Batches[0].Batch)
+	assert.Equal(common.NewIntegerFromString("8.21917807"), sim.Batches[0].Node)
+	assert.Equal(uint64(365), sim.Batches[1].Batch)
+	assert.Equal(common.NewIntegerFromString("7.39726027"), sim.Batches[1].Node)
+	assert.Equal(common.NewIntegerFromString("234.24657525"), sim.Amount)
+	for _, n := range sim.Nodes {
+		assert.Equal(common.NewIntegerFromString("15.61643834"), n.Work)
+	}
+}
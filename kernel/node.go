@@ -13,7 +13,9 @@ import (
 	"github.com/MixinNetwork/mixin/kernel/internal/clock"
 	"github.com/MixinNetwork/mixin/logger"
 	"github.com/MixinNetwork/mixin/network"
+	"github.com/MixinNetwork/mixin/signer"
 	"github.com/MixinNetwork/mixin/storage"
+	"github.com/MixinNetwork/mixin/util"
 	"github.com/dgraph-io/ristretto"
 )
 
@@ -38,18 +40,31 @@ type Node struct {
 
 	genesisNodesMap map[crypto.Hash]bool
 	genesisNodes    []crypto.Hash
+	upgradeIntents  *upgradeIntentMap
+	limiters        *peerLimiters
+	cosiSaturation  *util.QueueSaturation
+	requeue         *requeueMetrics
+	skew            *clockSkew
 	startAt         time.Time
 	networkId       crypto.Hash
 	persistStore    storage.Store
 	cacheStore      *ristretto.Cache
+	signatures      *signatureCache
+	signerStats     *signerStatsMap
+	checkpoints     *checkpointsMap
 	custom          *config.Custom
+	signerBackend   signer.Signer
+	events          *eventBus
 	configDir       string
 	addr            string
+	loopback        *network.LoopbackNetwork
 
-	done chan struct{}
-	elc  chan struct{}
-	mlc  chan struct{}
-	cqc  chan struct{}
+	stopping uint32
+	done     chan struct{}
+	elc      chan struct{}
+	mlc      chan struct{}
+	cqc      chan struct{}
+	rqc      chan struct{}
 }
 
 type NodeStateSequence struct {
@@ -72,8 +87,17 @@ func SetupNode(custom *config.Custom, persistStore storage.Store, cacheStore *ri
 		SyncPoints:      &syncMap{mutex: new(sync.RWMutex), m: make(map[crypto.Hash]*network.SyncPoint)},
 		chains:          &chainsMap{m: make(map[crypto.Hash]*Chain)},
 		genesisNodesMap: make(map[crypto.Hash]bool),
+		upgradeIntents:  &upgradeIntentMap{m: make(map[crypto.Hash]*UpgradeIntent)},
+		limiters:        newPeerLimiters(custom),
+		cosiSaturation:  util.NewQueueSaturation(custom.Node.CosiActionsSize),
+		requeue:         &requeueMetrics{},
+		skew:            newClockSkew(custom.Node.ClockSkewThreshold),
 		persistStore:    persistStore,
 		cacheStore:      cacheStore,
+		signatures:      newSignatureCache(custom.Node.SignatureCacheSize),
+		signerStats:     newSignerStatsMap(custom.Node.SignerAlertThreshold, webhookSignerAlert(custom.Node.SignerAlertWebhook)),
+		checkpoints:     newCheckpointsMap(custom.Node.Checkpoints),
+		events:          newEventBus(),
 		custom:          custom,
 		configDir:       dir,
 		addr:            addr,
@@ -82,14 +106,25 @@ func SetupNode(custom *config.Custom, persistStore storage.Store, cacheStore *ri
 		elc:             make(chan struct{}),
 		mlc:             make(chan struct{}),
 		cqc:             make(chan struct{}),
+		rqc:             make(chan struct{}),
 	}
 
-	node.LoadNodeConfig()
-
-	err := node.LoadGenesis(dir)
+	err := node.LoadNodeConfig()
+	if err != nil {
+		return nil, err
+	}
+	err = node.LoadGenesis(dir)
+	if err != nil {
+		return nil, err
+	}
+	err = node.checkChaos()
 	if err != nil {
 		return nil, err
 	}
+	if custom.Dev.Simulation && !clock.Simulating() {
+		clock.Simulate(time.Unix(0, int64(node.Epoch)), custom.Dev.SimulationSeed)
+		node.startAt = clock.Now()
+	}
 	node.TopoCounter = getTopologyCounter(persistStore)
 
 	logger.Println("Validating graph entries...")
@@ -111,6 +146,10 @@ func SetupNode(custom *config.Custom, persistStore storage.Store, cacheStore *ri
 	if err != nil {
 		return nil, err
 	}
+	err = node.verifyCheckpoints()
+	if err != nil {
+		return nil, err
+	}
 
 	logger.Printf("Listen:\t%s\n", addr)
 	logger.Printf("Signer:\t%s\n", node.Signer.String())
@@ -120,14 +159,34 @@ func SetupNode(custom *config.Custom, persistStore storage.Store, cacheStore *ri
 	return node, nil
 }
 
-func (node *Node) LoadNodeConfig() {
+// LoadNodeConfig loads the signer backend, and the private spend key of the
+// signer is only available with the local backend.
+func (node *Node) LoadNodeConfig() error {
+	backend, err := signer.New(node.custom)
+	if err != nil {
+		return err
+	}
 	var addr common.Address
-	addr.PrivateSpendKey = node.custom.Node.Signer
-	addr.PublicSpendKey = addr.PrivateSpendKey.Public()
+	if node.custom.Node.SignerBackend == config.SignerBackendLocal {
+		addr.PrivateSpendKey = node.custom.Node.Signer
+	}
+	addr.PublicSpendKey = backend.PublicKey()
 	addr.PrivateViewKey = addr.PublicSpendKey.DeterministicHashDerive()
 	addr.PublicViewKey = addr.PrivateViewKey.Public()
 	node.Signer = addr
+	node.signerBackend = backend
 	node.Listener = node.custom.Network.Listener
+	return nil
+}
+
+// sign signs the message by the signer backend, and the signature is empty
+// on error, which is invalid for all the receivers.
+func (node *Node) sign(message []byte) crypto.Signature {
+	sig, err := node.signerBackend.Sign(message)
+	if err != nil {
+		logger.Printf("node.sign ERROR %s\n", err)
+	}
+	return sig
 }
 
 func (node *Node) buildNodeStateSequences(allNodesSortedWithState []*CNode, acceptedOnly bool) []*NodeStateSequence {
@@ -148,13 +207,15 @@ func (node *Node) NodesListWithoutState(threshold uint64, acceptedOnly bool) []*
 	if acceptedOnly {
 		sequences = node.acceptedNodeStateSequences
 	}
-	for i := len(sequences); i > 0; i-- {
-		seq := sequences[i-1]
-		if seq.Timestamp < threshold {
-			return seq.NodesWithoutState
-		}
+	// the sequences are sorted by the node state timestamps, so the nodes at
+	// any historical threshold is the last sequence before it
+	i := sort.Search(len(sequences), func(i int) bool {
+		return sequences[i].Timestamp >= threshold
+	})
+	if i == 0 {
+		return nil
 	}
-	return nil
+	return sequences[i-1].NodesWithoutState
 }
 
 func (node *Node) nodeSequeueWithoutState(threshold uint64, acceptedOnly bool) []*CNode {
@@ -242,7 +303,7 @@ func (node *Node) ConsensusThreshold(timestamp uint64, final bool) int {
 	consensusBase := 0
 	nodes := node.NodesListWithoutState(timestamp, false)
 	for _, cn := range nodes {
-		threshold := config.SnapshotReferenceThreshold * config.SnapshotRoundGap
+		threshold := node.custom.ReferenceThreshold() * node.custom.RoundGap()
 		if threshold > uint64(3*time.Minute) {
 			panic("should never be here")
 		}
@@ -305,6 +366,21 @@ func (node *Node) LoadConsensusNodes() error {
 
 func (node *Node) PingNeighborsFromConfig() error {
 	node.Peer = network.NewPeer(node, node.IdForNetwork, node.addr, node.custom.Network.GossipNeighbors)
+	rotation := time.Duration(node.custom.Network.TransportKeyRotation) * time.Hour
+	overlap := time.Duration(node.custom.Network.TransportKeyOverlap) * time.Second
+	node.Peer.SetTransportKeyRotation(rotation, overlap)
+	node.Peer.SetDiscovery(!node.custom.Network.StaticOnly)
+	node.Peer.SetStalePeerTimeout(time.Duration(node.custom.Network.StalePeerTimeout) * time.Second)
+	node.Peer.SetPartitionTimeout(time.Duration(node.custom.Network.PartitionTimeout) * time.Second)
+	node.Peer.SetRelay(node.custom.Network.Relay, node.custom.Network.RelayAllow, node.custom.Network.Relays, node.custom.Network.RelayBandwidth)
+	node.Peer.SetSendQueue(node.custom.Network.PeerQueueSize, node.custom.Network.PeerQueueOverflow)
+	node.Peer.SetChaos(time.Duration(node.custom.Dev.ChaosMessageDelay)*time.Millisecond, node.custom.Dev.ChaosMessageDrop)
+	if node.custom.Network.MutualTLS {
+		node.Peer.EnableMutualTLS()
+	}
+	if node.loopback != nil {
+		node.Peer.UseLoopback(node.loopback)
+	}
 
 	for _, s := range node.custom.Network.Peers {
 		if s == node.Listener {
@@ -315,6 +391,12 @@ func (node *Node) PingNeighborsFromConfig() error {
 	return nil
 }
 
+// UseLoopbackNetwork makes the node connect the other nodes in the same
+// process, for the simulations, which must be set before the loop.
+func (node *Node) UseLoopbackNetwork(n *network.LoopbackNetwork) {
+	node.loopback = n
+}
+
 func (node *Node) UpdateNeighbors(neighbors []string) error {
 	for _, in := range neighbors {
 		if in == node.Listener {
@@ -368,7 +450,7 @@ func (node *Node) BuildAuthenticationMessage() []byte {
 	data := make([]byte, 8)
 	binary.BigEndian.PutUint64(data, uint64(clock.Now().Unix()))
 	data = append(data, node.Signer.PublicSpendKey[:]...)
-	sig := node.Signer.PrivateSpendKey.Sign(data)
+	sig := node.sign(data)
 	data = append(data, sig[:]...)
 	return append(data, []byte(node.Listener)...)
 }
@@ -504,8 +586,8 @@ func (node *Node) CheckCatchUpWithPeers() bool {
 			logger.Verbosef("CheckCatchUpWithPeers local(%s) != remote(%s)\n", cf.Hash, remote.Hash)
 			return false
 		}
-		if now := uint64(clock.Now().UnixNano()); cf.Start+config.SnapshotRoundGap*100 > now {
-			logger.Verbosef("CheckCatchUpWithPeers local start(%d)+%d > now(%d)\n", cf.Start, config.SnapshotRoundGap*100, now)
+		if now := uint64(clock.Now().UnixNano()); cf.Start+node.custom.RoundGap()*100 > now {
+			logger.Verbosef("CheckCatchUpWithPeers local start(%d)+%d > now(%d)\n", cf.Start, node.custom.RoundGap()*100, now)
 			return false
 		}
 	}
@@ -0,0 +1,55 @@
+package kernel
+
+import (
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+// ListNodeModifications returns the node modifications not effective yet,
+// or all of them if pending is false.
+func (node *Node) ListNodeModifications(pending bool) ([]*common.NodeModification, error) {
+	mods, err := node.persistStore.ReadNodeModifications()
+	if err != nil || !pending {
+		return mods, err
+	}
+	now := uint64(clock.Now().UnixNano())
+	list := make([]*common.NodeModification, 0)
+	for _, m := range mods {
+		if m.Effective > now {
+			list = append(list, m)
+		}
+	}
+	return list, nil
+}
+
+// modifyMintPayees replaces the payees of the mints with the latest node
+// modifications effective at the mint timestamp, the pledge payees are
+// kept for the node removal, and all the mints before the node modify fork.
+func (node *Node) modifyMintPayees(mints []*CNodeWork, timestamp uint64) error {
+	if !common.ForkActivated(common.NodeModifyForkTimestamp, timestamp) {
+		return nil
+	}
+	mods, err := node.persistStore.ReadNodeModifications()
+	if err != nil {
+		return err
+	}
+	payees := effectivePayees(mods, timestamp)
+	for _, m := range mints {
+		if p, found := payees[m.Signer.PublicSpendKey]; found {
+			m.Payee = p
+		}
+	}
+	return nil
+}
+
+func effectivePayees(mods []*common.NodeModification, timestamp uint64) map[crypto.Key]common.Address {
+	payees := make(map[crypto.Key]common.Address)
+	for _, m := range mods {
+		if m.Effective > timestamp {
+			break
+		}
+		payees[m.Signer] = m.PayeeAddress()
+	}
+	return payees
+}
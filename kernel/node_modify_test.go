@@ -0,0 +1,36 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectivePayees(t *testing.T) {
+	assert := assert.New(t)
+
+	keys := make([]crypto.Key, 3)
+	for i := range keys {
+		seed := crypto.NewHash([]byte{byte(i)})
+		keys[i] = crypto.NewKeyFromSeed(append(seed[:], seed[:]...)).Public()
+	}
+	signer := keys[0]
+	mods := []*common.NodeModification{
+		{Signer: signer, Payee: keys[1], Effective: 100},
+		{Signer: signer, Payee: keys[2], Effective: 200},
+	}
+
+	payees := effectivePayees(mods, 99)
+	assert.Len(payees, 0)
+	payees = effectivePayees(mods, 100)
+	assert.Len(payees, 1)
+	assert.Equal(keys[1], payees[signer].PublicSpendKey)
+	assert.Equal(keys[1].DeterministicHashDerive().Public(), payees[signer].PublicViewKey)
+	payees = effectivePayees(mods, 199)
+	assert.Equal(keys[1], payees[signer].PublicSpendKey)
+	payees = effectivePayees(mods, 200)
+	assert.Equal(keys[2], payees[signer].PublicSpendKey)
+	assert.Len(effectivePayees(nil, 200), 0)
+}
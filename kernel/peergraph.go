@@ -0,0 +1,84 @@
+package kernel
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+const peerGraphDomain = "MIXIN:KERNEL:PEERGRAPH"
+
+type PeerGraphLink struct {
+	Peer      crypto.Hash `json:"peer"`
+	Address   string      `json:"address"`
+	Latency   uint64      `json:"latency"`
+	Connected uint64      `json:"connected"`
+	Received  uint64      `json:"received"`
+}
+
+// PeerGraph is the adjacency of a node signed by its signer key, so that a
+// collector could build the network topology from the reports of any nodes
+// without trusting the relays.
+type PeerGraph struct {
+	Network   crypto.Hash      `json:"network"`
+	Node      crypto.Hash      `json:"node"`
+	Signer    common.Address   `json:"signer"`
+	Timestamp uint64           `json:"timestamp"`
+	Links     []*PeerGraphLink `json:"links"`
+	Signature crypto.Signature `json:"signature"`
+}
+
+func (g *PeerGraph) payload() []byte {
+	p := PeerGraph{
+		Network:   g.Network,
+		Node:      g.Node,
+		Signer:    common.Address{PublicSpendKey: g.Signer.PublicSpendKey, PublicViewKey: g.Signer.PublicViewKey},
+		Timestamp: g.Timestamp,
+		Links:     g.Links,
+	}
+	msg := append([]byte(peerGraphDomain), common.MsgpackMarshalPanic(p)...)
+	return msg
+}
+
+func (node *Node) SignedPeerGraph() *PeerGraph {
+	g := &PeerGraph{
+		Network:   node.networkId,
+		Node:      node.IdForNetwork,
+		Signer:    common.Address{PublicSpendKey: node.Signer.PublicSpendKey, PublicViewKey: node.Signer.PublicViewKey},
+		Timestamp: uint64(clock.Now().UnixNano()),
+		Links:     make([]*PeerGraphLink, 0),
+	}
+	for _, l := range node.Peer.PeerLinks() {
+		link := &PeerGraphLink{
+			Peer:    l.Peer,
+			Address: l.Address,
+			Latency: uint64(l.Latency),
+		}
+		if !l.Connected.IsZero() {
+			link.Connected = uint64(l.Connected.UnixNano())
+		}
+		if !l.Received.IsZero() {
+			link.Received = uint64(l.Received.UnixNano())
+		}
+		g.Links = append(g.Links, link)
+	}
+	digest := crypto.NewHash(g.payload())
+	g.Signature = node.sign(digest[:])
+	return g
+}
+
+// VerifyPeerGraph checks the node id is derived from the signer in the
+// network, and the signature of the graph by the signer.
+func VerifyPeerGraph(g *PeerGraph) error {
+	id := g.Signer.Hash().ForNetwork(g.Network)
+	if id != g.Node {
+		return fmt.Errorf("invalid peer graph node %s %s", g.Node, id)
+	}
+	digest := crypto.NewHash(g.payload())
+	if !g.Signer.PublicSpendKey.Verify(digest[:], g.Signature) {
+		return fmt.Errorf("invalid peer graph signature %s", g.Node)
+	}
+	return nil
+}
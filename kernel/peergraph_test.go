@@ -0,0 +1,41 @@
+package kernel
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := os.MkdirTemp("", "mixin-peergraph-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	node := setupTestNode(assert, root)
+	assert.NotNil(node)
+	node.Peer = network.NewPeer(nil, node.IdForNetwork, "127.0.0.1:7239", false)
+
+	g := node.SignedPeerGraph()
+	assert.Equal(node.IdForNetwork, g.Node)
+	assert.Equal(node.networkId, g.Network)
+	assert.Len(g.Links, 0)
+	assert.Nil(VerifyPeerGraph(g))
+
+	data, err := json.Marshal(g)
+	assert.Nil(err)
+	var decoded PeerGraph
+	err = json.Unmarshal(data, &decoded)
+	assert.Nil(err)
+	assert.Nil(VerifyPeerGraph(&decoded))
+
+	decoded.Links = append(decoded.Links, &PeerGraphLink{Peer: crypto.NewHash([]byte("peer"))})
+	assert.Contains(VerifyPeerGraph(&decoded).Error(), "invalid peer graph signature")
+	decoded.Node = crypto.NewHash([]byte("node"))
+	assert.Contains(VerifyPeerGraph(&decoded).Error(), "invalid peer graph node")
+}
@@ -5,9 +5,10 @@ import (
 	"time"
 
 	"github.com/MixinNetwork/mixin/common"
-	"github.com/MixinNetwork/mixin/config"
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
 	"github.com/MixinNetwork/mixin/logger"
+	"github.com/MixinNetwork/mixin/util"
 )
 
 func (node *Node) QueueTransaction(tx *common.VersionedTransaction) (string, error) {
@@ -32,6 +33,15 @@ func (node *Node) QueueTransaction(tx *common.VersionedTransaction) (string, err
 	if err != nil {
 		return "", err
 	}
+	timestamp := uint64(clock.Now().UnixNano())
+	err = tx.ValidateExpiration(node.persistStore, timestamp)
+	if err != nil {
+		return "", err
+	}
+	err = tx.ValidateForks(node.persistStore, timestamp)
+	if err != nil {
+		return "", err
+	}
 	err = node.persistStore.CachePutTransaction(tx)
 	if err != nil {
 		return "", err
@@ -45,12 +55,32 @@ func (node *Node) QueueTransaction(tx *common.VersionedTransaction) (string, err
 	return tx.PayloadHash().String(), err
 }
 
+// TraceTransaction validates the transaction without queueing it, and
+// returns every check performed until the first failure.
+func (node *Node) TraceTransaction(tx *common.VersionedTransaction) (*common.ValidationTrace, error) {
+	trace, err := tx.ValidateWithTrace(node.persistStore, false)
+	if err != nil {
+		return trace, err
+	}
+	timestamp := uint64(clock.Now().UnixNano())
+	err = trace.Run("expiration", func() error {
+		return tx.ValidateExpiration(node.persistStore, timestamp)
+	}, timestamp)
+	if err != nil {
+		return trace, err
+	}
+	err = trace.Run("forks", func() error {
+		return tx.ValidateForks(node.persistStore, timestamp)
+	}, timestamp)
+	return trace, err
+}
+
 func (node *Node) LoopCacheQueue() error {
 	defer close(node.cqc)
 
 	offset, limit := crypto.Hash{}, 100
 	for {
-		period := time.Duration(config.SnapshotRoundGap)
+		period := time.Duration(node.custom.RoundGap())
 		if offset.HasValue() {
 			period = time.Millisecond * 300
 		}
@@ -60,6 +90,10 @@ func (node *Node) LoopCacheQueue() error {
 			return nil
 		case <-timer.C:
 		}
+		if node.skew.gate(clock.Now()) {
+			timer.Stop()
+			continue
+		}
 		caches, finals, _ := node.QueueState()
 		if caches > 1000 || finals > 500 {
 			timer.Stop()
@@ -136,13 +170,13 @@ func (node *Node) QueueState() (uint64, uint64, map[string][2]uint64) {
 	return caches, finals, state
 }
 
+func (node *Node) CosiQueueStats() util.QueueSaturationStats {
+	return node.cosiSaturation.Stats()
+}
+
 func (chain *Chain) clearAndQueueSnapshotOrPanic(s *common.Snapshot) error {
 	if chain.ChainId != s.NodeId {
 		panic("should never be here")
 	}
-	return chain.AppendSelfEmpty(&common.Snapshot{
-		Version:     common.SnapshotVersion,
-		NodeId:      s.NodeId,
-		Transaction: s.Transaction,
-	})
+	return chain.node.requeueSnapshot(s.Transaction)
 }
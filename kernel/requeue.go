@@ -0,0 +1,164 @@
+package kernel
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	requeuePollPeriod = 100 * time.Millisecond
+	requeueBatch      = 64
+	requeueMaxBackoff = time.Minute
+)
+
+// RequeueStats is the depth of the persistent requeue queue, and the
+// counters since the node started.
+type RequeueStats struct {
+	Depth        int
+	Capacity     int
+	Queued       uint64
+	Deduplicated uint64
+	Evicted      uint64
+	Retries      uint64
+	Removed      uint64
+}
+
+type requeueMetrics struct {
+	depth        int64
+	queued       uint64
+	deduplicated uint64
+	evicted      uint64
+	retries      uint64
+	removed      uint64
+}
+
+func (node *Node) RequeueStats() RequeueStats {
+	m := node.requeue
+	return RequeueStats{
+		Depth:        int(atomic.LoadInt64(&m.depth)),
+		Capacity:     node.custom.Node.RequeueSize,
+		Queued:       atomic.LoadUint64(&m.queued),
+		Deduplicated: atomic.LoadUint64(&m.deduplicated),
+		Evicted:      atomic.LoadUint64(&m.evicted),
+		Retries:      atomic.LoadUint64(&m.retries),
+		Removed:      atomic.LoadUint64(&m.removed),
+	}
+}
+
+// requeueSnapshot persists the transaction to announce again, so it's
+// never lost on restart, and a transaction requeued many times before
+// announced again is only queued once.
+func (node *Node) requeueSnapshot(tx crypto.Hash) error {
+	now := uint64(clock.Now().UnixNano())
+	queued, evicted, err := node.persistStore.CacheRequeueSnapshot(tx, now, node.custom.Node.RequeueSize)
+	if err != nil {
+		return err
+	}
+	m := node.requeue
+	if !queued {
+		atomic.AddUint64(&m.deduplicated, 1)
+		return nil
+	}
+	atomic.AddUint64(&m.queued, 1)
+	atomic.AddInt64(&m.depth, int64(1-evicted))
+	if evicted > 0 {
+		atomic.AddUint64(&m.evicted, uint64(evicted))
+		logger.Printf("requeueSnapshot(%s) evicted %d\n", tx, evicted)
+	}
+	return nil
+}
+
+// LoopRequeueSnapshots announces the due transactions in the requeue queue
+// again, until finalized or gone from the cache, and the backoff doubles
+// from a round gap each retry.
+func (node *Node) LoopRequeueSnapshots() {
+	defer close(node.rqc)
+
+	for {
+		timer := time.NewTimer(requeuePollPeriod)
+		select {
+		case <-node.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		err := node.pollRequeuedSnapshots()
+		if err != nil {
+			logger.Printf("LoopRequeueSnapshots ERROR %s\n", err)
+		}
+	}
+}
+
+func (node *Node) pollRequeuedSnapshots() error {
+	if node.skew.pausing() {
+		return nil
+	}
+	now := clock.Now()
+	entries, depth, err := node.persistStore.CacheListRequeuedSnapshots(uint64(now.UnixNano()), requeueBatch)
+	if err != nil {
+		return err
+	}
+	m := node.requeue
+	atomic.StoreInt64(&m.depth, int64(depth))
+	for _, e := range entries {
+		stale, err := node.requeuedSnapshotStale(e.Transaction)
+		if err != nil {
+			return err
+		}
+		if stale {
+			err = node.persistStore.CacheRemoveRequeuedSnapshot(e)
+			if err != nil {
+				return err
+			}
+			atomic.AddUint64(&m.removed, 1)
+			atomic.AddInt64(&m.depth, -1)
+			continue
+		}
+		if e.Retries > 0 {
+			atomic.AddUint64(&m.retries, 1)
+		}
+		e.Retries = e.Retries + 1
+		e.Due = uint64(now.Add(requeueBackoff(e.Retries)).UnixNano())
+		err = node.persistStore.CacheUpdateRequeuedSnapshot(e)
+		if err != nil {
+			return err
+		}
+		err = node.chain.AppendSelfEmpty(&common.Snapshot{
+			Version:     common.SnapshotVersion,
+			NodeId:      node.IdForNetwork,
+			Transaction: e.Transaction,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (node *Node) requeuedSnapshotStale(hash crypto.Hash) (bool, error) {
+	_, finalized, err := node.persistStore.ReadTransaction(hash)
+	if err != nil || len(finalized) > 0 {
+		return true, err
+	}
+	tx, err := node.persistStore.CacheGetTransaction(hash)
+	return tx == nil, err
+}
+
+// requeueBackoff is the delay after the retries, the first announcement is
+// at once, then a round gap doubled each retry and at most a minute.
+func requeueBackoff(retries uint64) time.Duration {
+	backoff := time.Duration(config.SnapshotRoundGap)
+	for i := uint64(1); i < retries && backoff < requeueMaxBackoff; i++ {
+		backoff = backoff * 2
+	}
+	if backoff > requeueMaxBackoff {
+		return requeueMaxBackoff
+	}
+	return backoff
+}
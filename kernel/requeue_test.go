@@ -0,0 +1,56 @@
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequeueSnapshots(t *testing.T) {
+	assert := assert.New(t)
+
+	gap := time.Duration(config.SnapshotRoundGap)
+	assert.Equal(gap, requeueBackoff(0))
+	assert.Equal(gap, requeueBackoff(1))
+	assert.Equal(gap*2, requeueBackoff(2))
+	assert.Equal(gap*8, requeueBackoff(4))
+	assert.Equal(requeueMaxBackoff, requeueBackoff(64))
+
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+	custom.Node.RequeueSize = 2
+	store, err := storage.NewMemoryStore(custom)
+	assert.Nil(err)
+	defer store.Close()
+
+	node := &Node{persistStore: store, custom: custom, requeue: &requeueMetrics{}, skew: newClockSkew(custom.Node.ClockSkewThreshold)}
+	a, b, c := crypto.NewHash([]byte("a")), crypto.NewHash([]byte("b")), crypto.NewHash([]byte("c"))
+	assert.Nil(node.requeueSnapshot(a))
+	assert.Nil(node.requeueSnapshot(a))
+	assert.Nil(node.requeueSnapshot(b))
+	assert.Nil(node.requeueSnapshot(c))
+	stats := node.RequeueStats()
+	assert.Equal(2, stats.Depth)
+	assert.Equal(2, stats.Capacity)
+	assert.Equal(uint64(3), stats.Queued)
+	assert.Equal(uint64(1), stats.Deduplicated)
+	assert.Equal(uint64(1), stats.Evicted)
+
+	// the requeue queue waits while the clock skew pauses the announcements
+	node.skew.paused = true
+	assert.Nil(node.pollRequeuedSnapshots())
+	assert.Equal(2, node.RequeueStats().Depth)
+	assert.Equal(uint64(0), node.RequeueStats().Removed)
+	node.skew.paused = false
+
+	// the transactions not in the cache are never announced again
+	assert.Nil(node.pollRequeuedSnapshots())
+	stats = node.RequeueStats()
+	assert.Equal(0, stats.Depth)
+	assert.Equal(uint64(2), stats.Removed)
+	assert.Equal(uint64(0), stats.Retries)
+}
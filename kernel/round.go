@@ -67,7 +67,11 @@ func (node *Node) LoadRoundGraph() (map[crypto.Hash]*CacheRound, map[crypto.Hash
 	return cacheRound, finalRound
 }
 
-func loadRoundHistoryForNode(store storage.Store, to *FinalRound) []*FinalRound {
+func loadRoundHistoryForNode(custom *config.Custom, store storage.Store, to *FinalRound) []*FinalRound {
+	return reduceHistory(custom, loadFinalRoundsForNode(store, to))
+}
+
+func loadFinalRoundsForNode(store storage.Store, to *FinalRound) []*FinalRound {
 	var history []*FinalRound
 	start := to.Number + 1 - config.SnapshotReferenceThreshold
 	if to.Number+1 < config.SnapshotReferenceThreshold {
@@ -80,7 +84,51 @@ func loadRoundHistoryForNode(store storage.Store, to *FinalRound) []*FinalRound
 		}
 		history = append(history, r)
 	}
-	return reduceHistory(history)
+	return history
+}
+
+// loadStateFromGraphHead loads the final round and history from the graph
+// head summary, or nil if the head is missing or not for the cache round.
+func loadStateFromGraphHead(custom *config.Custom, store storage.Store, cache *CacheRound) (*FinalRound, []*FinalRound, error) {
+	head, err := store.ReadGraphHead(cache.NodeId)
+	if err != nil || head == nil || head.Number != cache.Number || len(head.Finals) == 0 {
+		return nil, nil, err
+	}
+	last := head.Finals[len(head.Finals)-1]
+	if last.Number+1 != cache.Number || last.Hash != cache.References.Self {
+		return nil, nil, nil
+	}
+	history := make([]*FinalRound, len(head.Finals))
+	for i, r := range head.Finals {
+		history[i] = &FinalRound{
+			NodeId: cache.NodeId,
+			Number: r.Number,
+			Start:  r.Start,
+			End:    r.End,
+			Hash:   r.Hash,
+		}
+	}
+	return history[len(history)-1].Copy(), reduceHistory(custom, history), nil
+}
+
+// writeGraphHead rebuilds the graph head summary from the rounds history.
+func writeGraphHead(store storage.Store, cache *CacheRound, history []*FinalRound) error {
+	head := &common.GraphHead{
+		NodeId: cache.NodeId,
+		Number: cache.Number,
+	}
+	if len(cache.Snapshots) > 0 {
+		head.Start, head.End = cache.Gap()
+	}
+	for _, r := range history {
+		head.Finals = append(head.Finals, &common.GraphHeadRound{
+			Number: r.Number,
+			Start:  r.Start,
+			End:    r.End,
+			Hash:   r.Hash,
+		})
+	}
+	return store.WriteGraphHead(head)
 }
 
 func loadHeadRoundForNode(store storage.Store, nodeIdWithNetwork crypto.Hash) (*CacheRound, error) {
@@ -182,18 +230,18 @@ func (c *CacheRound) Gap() (uint64, uint64) {
 
 func (chain *Chain) AddSnapshot(final *FinalRound, cache *CacheRound, s *common.Snapshot, signers []crypto.Hash) error {
 	chain.node.TopoWrite(s, signers)
-	if err := cache.validateSnapshot(s, true); err != nil {
+	if err := cache.validateSnapshot(s, chain.node.custom.RoundGap(), true); err != nil {
 		panic("should never be here")
 	}
 	chain.assignNewGraphRound(final, cache)
 	return nil
 }
 
-func (c *CacheRound) ValidateSnapshot(s *common.Snapshot) error {
-	return c.validateSnapshot(s, false)
+func (c *CacheRound) ValidateSnapshot(s *common.Snapshot, gap uint64) error {
+	return c.validateSnapshot(s, gap, false)
 }
 
-func (c *CacheRound) validateSnapshot(s *common.Snapshot, add bool) error {
+func (c *CacheRound) validateSnapshot(s *common.Snapshot, gap uint64, add bool) error {
 	if s.RoundNumber != c.Number || !s.Hash.HasValue() {
 		panic(s)
 	}
@@ -208,10 +256,10 @@ func (c *CacheRound) validateSnapshot(s *common.Snapshot, add bool) error {
 		}
 	}
 	if start, end := c.Gap(); start <= end {
-		if s.Timestamp < start && s.Timestamp+config.SnapshotRoundGap <= end {
+		if s.Timestamp < start && s.Timestamp+gap <= end {
 			return fmt.Errorf("ValidateSnapshot error gap start %s %d %d %d", s.Hash, s.Timestamp, start, end)
 		}
-		if s.Timestamp > end && start+config.SnapshotRoundGap <= s.Timestamp {
+		if s.Timestamp > end && start+gap <= s.Timestamp {
 			return fmt.Errorf("ValidateSnapshot error gap end %s %d %d %d", s.Hash, s.Timestamp, start, end)
 		}
 	}
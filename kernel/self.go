@@ -8,6 +8,7 @@ import (
 
 	"github.com/MixinNetwork/mixin/common"
 	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
 	"github.com/MixinNetwork/mixin/logger"
 	"github.com/dgraph-io/badger/v3"
 )
@@ -47,7 +48,7 @@ func (node *Node) lockAndPersistTransaction(tx *common.VersionedTransaction, fin
 	for i := time.Duration(0); i < time.Second; i += time.Millisecond * 100 {
 		err := tx.LockInputs(node.persistStore, finalized)
 		if errors.Is(err, badger.ErrConflict) {
-			time.Sleep(i)
+			clock.Sleep(i)
 			continue
 		} else if err != nil {
 			return err
@@ -55,7 +56,7 @@ func (node *Node) lockAndPersistTransaction(tx *common.VersionedTransaction, fin
 
 		err = node.persistStore.WriteTransaction(tx)
 		if errors.Is(err, badger.ErrConflict) {
-			time.Sleep(i)
+			clock.Sleep(i)
 			continue
 		}
 		return err
@@ -64,6 +65,21 @@ func (node *Node) lockAndPersistTransaction(tx *common.VersionedTransaction, fin
 }
 
 func (node *Node) validateKernelSnapshot(s *common.Snapshot, tx *common.VersionedTransaction, finalized bool) error {
+	timestamp := s.Timestamp
+	if timestamp == 0 {
+		timestamp = uint64(clock.Now().UnixNano())
+	}
+	err := tx.ValidateExpiration(node.persistStore, timestamp)
+	if err != nil {
+		logger.Verbosef("ValidateExpiration ERROR %v %s %s\n", s, hex.EncodeToString(tx.PayloadMarshal()), err.Error())
+		return err
+	}
+	err = tx.ValidateForks(node.persistStore, timestamp)
+	if err != nil {
+		logger.Verbosef("ValidateForks ERROR %v %s %s\n", s, hex.EncodeToString(tx.PayloadMarshal()), err.Error())
+		return err
+	}
+
 	switch tx.TransactionType() {
 	case common.TransactionTypeMint:
 		err := node.validateMintSnapshot(s, tx)
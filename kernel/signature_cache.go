@@ -0,0 +1,95 @@
+package kernel
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// SignatureCacheStats reports the verified signatures cache, both the
+// legacy and cosi verifications share the same cache and counters.
+type SignatureCacheStats struct {
+	Size      int
+	Limit     int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type signatureCacheEntry struct {
+	key     crypto.Hash
+	valid   bool
+	signers []crypto.Hash
+}
+
+// signatureCache is the LRU of the signature verification results, keyed
+// by the hash of the snapshot, signature and public keys, so that the same
+// snapshot signatures from many peers are only verified once.
+type signatureCache struct {
+	sync.Mutex
+	limit     int
+	entries   map[crypto.Hash]*list.Element
+	lru       *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newSignatureCache(limit int) *signatureCache {
+	return &signatureCache{
+		limit:   limit,
+		entries: make(map[crypto.Hash]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *signatureCache) get(key crypto.Hash) (*signatureCacheEntry, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, found := c.entries[key]
+	if !found {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*signatureCacheEntry), true
+}
+
+func (c *signatureCache) set(key crypto.Hash, valid bool, signers []crypto.Hash) {
+	c.Lock()
+	defer c.Unlock()
+
+	if elem, found := c.entries[key]; found {
+		elem.Value = &signatureCacheEntry{key: key, valid: valid, signers: signers}
+		c.lru.MoveToFront(elem)
+		return
+	}
+	entry := &signatureCacheEntry{key: key, valid: valid, signers: signers}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.limit {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*signatureCacheEntry).key)
+		c.evictions++
+	}
+}
+
+func (c *signatureCache) stats() SignatureCacheStats {
+	c.Lock()
+	defer c.Unlock()
+
+	return SignatureCacheStats{
+		Size:      c.lru.Len(),
+		Limit:     c.limit,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+func (node *Node) SignatureCacheStats() SignatureCacheStats {
+	return node.signatures.stats()
+}
@@ -0,0 +1,63 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureCache(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newSignatureCache(2)
+	a := crypto.NewHash([]byte("a"))
+	b := crypto.NewHash([]byte("b"))
+	c := crypto.NewHash([]byte("c"))
+
+	_, found := cache.get(a)
+	assert.False(found)
+	cache.set(a, true, nil)
+	cache.set(b, true, []crypto.Hash{a, b})
+	entry, found := cache.get(a)
+	assert.True(found)
+	assert.True(entry.valid)
+
+	cache.set(c, false, nil)
+	_, found = cache.get(b)
+	assert.False(found)
+	entry, found = cache.get(c)
+	assert.True(found)
+	assert.False(entry.valid)
+	_, found = cache.get(a)
+	assert.True(found)
+
+	stats := cache.stats()
+	assert.Equal(2, stats.Size)
+	assert.Equal(2, stats.Limit)
+	assert.Equal(uint64(3), stats.Hits)
+	assert.Equal(uint64(2), stats.Misses)
+	assert.Equal(uint64(1), stats.Evictions)
+}
+
+func TestCacheVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	node := &Node{signatures: newSignatureCache(16)}
+	seed := crypto.NewHash([]byte("seed"))
+	priv := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	pub := priv.Public()
+	snap := crypto.NewHash([]byte("snapshot"))
+	sig := priv.Sign(snap[:])
+
+	assert.True(node.CacheVerify(snap, sig, pub))
+	assert.True(node.CacheVerify(snap, sig, pub))
+	other := crypto.NewHash([]byte("other"))
+	assert.False(node.CacheVerify(other, sig, pub))
+	assert.False(node.CacheVerify(other, sig, pub))
+
+	stats := node.SignatureCacheStats()
+	assert.Equal(2, stats.Size)
+	assert.Equal(uint64(2), stats.Hits)
+	assert.Equal(uint64(2), stats.Misses)
+}
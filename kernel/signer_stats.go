@@ -0,0 +1,208 @@
+package kernel
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	signerStatsEpoch   = uint64(time.Hour)
+	signerStatsHistory = 24
+	signerStatsMinimum = 100
+	signerAlertTimeout = 10 * time.Second
+)
+
+// SignerStats is the participation of a signer in an epoch. The eligible
+// snapshots are all the finalized ones while the signer is accepted, and
+// the commitments and responses are to the snapshots led by this node.
+type SignerStats struct {
+	Signer      crypto.Hash `json:"signer"`
+	Eligible    uint64      `json:"eligible"`
+	Signed      uint64      `json:"signed"`
+	Commitments uint64      `json:"commitments"`
+	Responses   uint64      `json:"responses"`
+}
+
+type SignerEpochStats struct {
+	Epoch   uint64         `json:"epoch"`
+	Signers []*SignerStats `json:"signers"`
+}
+
+// SignerAlert is fired when the participation of a signer in an epoch drops
+// below the threshold, the epoch is the start timestamp.
+type SignerAlert struct {
+	Signer        crypto.Hash `json:"signer"`
+	Epoch         uint64      `json:"epoch"`
+	Eligible      uint64      `json:"eligible"`
+	Signed        uint64      `json:"signed"`
+	Participation float64     `json:"participation"`
+}
+
+type signerStatsMap struct {
+	sync.Mutex
+	epochs    map[uint64]map[crypto.Hash]*SignerStats
+	latest    uint64
+	threshold float64
+	alert     func(*SignerAlert)
+}
+
+func (s *SignerStats) Participation() float64 {
+	if s.Eligible == 0 {
+		return 0
+	}
+	return float64(s.Signed) / float64(s.Eligible)
+}
+
+func newSignerStatsMap(threshold float64, alert func(*SignerAlert)) *signerStatsMap {
+	return &signerStatsMap{
+		epochs:    make(map[uint64]map[crypto.Hash]*SignerStats),
+		threshold: threshold,
+		alert:     alert,
+	}
+}
+
+func (m *signerStatsMap) get(timestamp uint64, signer crypto.Hash) *SignerStats {
+	epoch := timestamp - timestamp%signerStatsEpoch
+	if epoch > m.latest {
+		if m.latest > 0 {
+			m.checkEpoch(m.latest)
+		}
+		m.latest = epoch
+		for e := range m.epochs {
+			if e+signerStatsEpoch*signerStatsHistory <= epoch {
+				delete(m.epochs, e)
+			}
+		}
+	}
+	if epoch+signerStatsEpoch*signerStatsHistory <= m.latest {
+		return &SignerStats{}
+	}
+	signers := m.epochs[epoch]
+	if signers == nil {
+		signers = make(map[crypto.Hash]*SignerStats)
+		m.epochs[epoch] = signers
+	}
+	stats := signers[signer]
+	if stats == nil {
+		stats = &SignerStats{Signer: signer}
+		signers[signer] = stats
+	}
+	return stats
+}
+
+// checkEpoch alerts the low participation signers of the finished epoch,
+// unless it's too old, e.g. when the node is catching up the graph.
+func (m *signerStatsMap) checkEpoch(epoch uint64) {
+	if m.alert == nil || epoch+signerStatsEpoch*2 < uint64(clock.Now().UnixNano()) {
+		return
+	}
+	for _, s := range m.epochs[epoch] {
+		if s.Eligible < signerStatsMinimum || s.Participation() >= m.threshold {
+			continue
+		}
+		go m.alert(&SignerAlert{
+			Signer:        s.Signer,
+			Epoch:         epoch,
+			Eligible:      s.Eligible,
+			Signed:        s.Signed,
+			Participation: s.Participation(),
+		})
+	}
+}
+
+func (m *signerStatsMap) recordSnapshot(timestamp uint64, nodes []*CNode, signers []crypto.Hash) {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, cn := range nodes {
+		m.get(timestamp, cn.IdForNetwork).Eligible += 1
+	}
+	for _, id := range signers {
+		m.get(timestamp, id).Signed += 1
+	}
+}
+
+func (m *signerStatsMap) recordCommitment(timestamp uint64, signer crypto.Hash) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.get(timestamp, signer).Commitments += 1
+}
+
+func (m *signerStatsMap) recordResponse(timestamp uint64, signer crypto.Hash) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.get(timestamp, signer).Responses += 1
+}
+
+func (m *signerStatsMap) list() []*SignerEpochStats {
+	m.Lock()
+	defer m.Unlock()
+
+	epochs := make([]*SignerEpochStats, 0)
+	for e, signers := range m.epochs {
+		es := &SignerEpochStats{Epoch: e}
+		for _, s := range signers {
+			stats := *s
+			es.Signers = append(es.Signers, &stats)
+		}
+		sort.Slice(es.Signers, func(i, j int) bool {
+			return es.Signers[i].Signer.String() < es.Signers[j].Signer.String()
+		})
+		epochs = append(epochs, es)
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i].Epoch > epochs[j].Epoch })
+	return epochs
+}
+
+// SetSignerAlert replaces the callback fired when a signer participation
+// drops below the signer-alert-threshold, which posts the webhook if set.
+func (node *Node) SetSignerAlert(alert func(*SignerAlert)) {
+	node.signerStats.Lock()
+	defer node.signerStats.Unlock()
+
+	node.signerStats.alert = alert
+}
+
+// ListSignerStats lists the signer participation of the recent epochs, the
+// latest epoch first, and it's still in progress.
+func (node *Node) ListSignerStats() []*SignerEpochStats {
+	return node.signerStats.list()
+}
+
+func (node *Node) recordSignerParticipation(timestamp uint64, signers []crypto.Hash) {
+	if len(signers) == 0 {
+		return
+	}
+	nodes := node.NodesListWithoutState(timestamp, true)
+	node.signerStats.recordSnapshot(timestamp, nodes, signers)
+}
+
+func webhookSignerAlert(url string) func(*SignerAlert) {
+	if url == "" {
+		return nil
+	}
+	client := &http.Client{Timeout: signerAlertTimeout}
+	return func(a *SignerAlert) {
+		logger.Printf("SignerAlert(%s, %d) participation %f %d/%d\n", a.Signer, a.Epoch, a.Participation, a.Signed, a.Eligible)
+		body, err := json.Marshal(a)
+		if err != nil {
+			panic(err)
+		}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			logger.Printf("SignerAlert(%s) webhook ERROR %s\n", a.Signer, err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+}
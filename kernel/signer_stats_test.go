@@ -0,0 +1,65 @@
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignerStats(t *testing.T) {
+	assert := assert.New(t)
+
+	alerts := make(chan *SignerAlert, 10)
+	stats := newSignerStatsMap(0.5, func(a *SignerAlert) { alerts <- a })
+	a := crypto.NewHash([]byte("a"))
+	b := crypto.NewHash([]byte("b"))
+	nodes := []*CNode{{IdForNetwork: a}, {IdForNetwork: b}}
+
+	now := uint64(clock.Now().UnixNano())
+	epoch := now - now%signerStatsEpoch
+	for i := 0; i < signerStatsMinimum; i++ {
+		signers := []crypto.Hash{a}
+		if i%3 == 0 {
+			signers = append(signers, b)
+		}
+		stats.recordSnapshot(epoch+uint64(i), nodes, signers)
+	}
+	stats.recordCommitment(epoch, b)
+	stats.recordResponse(epoch, b)
+
+	epochs := stats.list()
+	assert.Len(epochs, 1)
+	assert.Equal(epoch, epochs[0].Epoch)
+	assert.Len(epochs[0].Signers, 2)
+	for _, s := range epochs[0].Signers {
+		assert.Equal(uint64(signerStatsMinimum), s.Eligible)
+		if s.Signer == a {
+			assert.Equal(float64(1), s.Participation())
+		} else {
+			assert.Equal(uint64(34), s.Signed)
+			assert.Equal(uint64(1), s.Commitments)
+			assert.Equal(uint64(1), s.Responses)
+		}
+	}
+
+	stats.recordSnapshot(epoch+signerStatsEpoch, nodes, []crypto.Hash{a, b})
+	select {
+	case alert := <-alerts:
+		assert.Equal(b, alert.Signer)
+		assert.Equal(epoch, alert.Epoch)
+		assert.Equal(0.34, alert.Participation)
+	case <-time.After(time.Second):
+		t.Fatal("signer alert timeout")
+	}
+	assert.Len(alerts, 0)
+	assert.Len(stats.list(), 2)
+	assert.Equal(epoch+signerStatsEpoch, stats.list()[0].Epoch)
+
+	stats.recordSnapshot(epoch+signerStatsEpoch*signerStatsHistory, nodes, []crypto.Hash{a})
+	assert.Len(stats.list(), 2)
+	stats.recordSnapshot(epoch, nodes, []crypto.Hash{a})
+	assert.Len(stats.list(), 2)
+}
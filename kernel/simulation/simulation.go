@@ -0,0 +1,277 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/MixinNetwork/mixin/kernel"
+	"github.com/MixinNetwork/mixin/network"
+	"github.com/MixinNetwork/mixin/storage"
+	"github.com/dgraph-io/ristretto"
+)
+
+const (
+	simulationEpoch    = 1551312000
+	simulationAssetKey = "0xa974c709cfb4566686553a20790685a47aceaa33"
+)
+
+const simulationConfig = `[node]
+signer-key = "%s"
+consensus-only = false
+memory-cache-size = 16
+kernel-operation-period = 1
+cache-ttl = 3600
+[network]
+listener = "%s"
+peers = [%s]
+`
+
+// Simulation runs the kernel nodes in the same process with the memory
+// stores and the loopback network, to drive the snapshots and assert the
+// finalization with the latency and partitions injected. The kernel clock
+// is global, so only one simulation should run at a time.
+type Simulation struct {
+	Network *network.LoopbackNetwork
+	Nodes   []*kernel.Node
+
+	domain   common.Address
+	addrs    []string
+	stores   []storage.Store
+	sequence uint64
+	started  bool
+}
+
+// New sets up count nodes with the genesis in the root directory, the
+// first node signer is also the domain to sign the deposits.
+func New(root string, count int) (*Simulation, error) {
+	if count < kernel.MinimumNodeCount {
+		return nil, fmt.Errorf("invalid simulation nodes count %d", count)
+	}
+	sim := &Simulation{Network: network.NewLoopbackNetwork()}
+
+	var signers, payees []common.Address
+	for i := 0; i < count; i++ {
+		signers = append(signers, simulationAccount(i, "SIGNER"))
+		payees = append(payees, simulationAccount(i, "PAYEE"))
+		sim.addrs = append(sim.addrs, fmt.Sprintf("127.0.0.1:%d", 17001+i))
+	}
+	sim.domain = signers[0]
+
+	genesis, err := simulationGenesis(signers, payees)
+	if err != nil {
+		return nil, err
+	}
+	peers := `"` + strings.Join(sim.addrs, `","`) + `"`
+
+	kernel.TestMockReset()
+	kernel.TestMockDiff(time.Unix(simulationEpoch, 0).Sub(time.Now()))
+	for i, a := range signers {
+		dir := fmt.Sprintf("%s/node-%04d", root, i)
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			return nil, err
+		}
+		data := []byte(fmt.Sprintf(simulationConfig, a.PrivateSpendKey.String(), sim.addrs[i], peers))
+		err = os.WriteFile(dir+"/config.toml", data, 0644)
+		if err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(dir+"/genesis.json", genesis, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		custom, err := config.Initialize(dir + "/config.toml")
+		if err != nil {
+			return nil, err
+		}
+		cache, err := ristretto.NewCache(&ristretto.Config{
+			NumCounters: 1e5,
+			MaxCost:     int64(custom.Node.MemoryCacheSize) * 1024 * 1024,
+			BufferItems: 64,
+		})
+		if err != nil {
+			return nil, err
+		}
+		store, err := storage.NewMemoryStore(custom)
+		if err != nil {
+			return nil, err
+		}
+		node, err := kernel.SetupNode(custom, store, cache, sim.addrs[i], dir)
+		if err != nil {
+			return nil, err
+		}
+		node.UseLoopbackNetwork(sim.Network)
+		sim.Nodes = append(sim.Nodes, node)
+		sim.stores = append(sim.stores, store)
+	}
+	return sim, nil
+}
+
+func (sim *Simulation) Start() {
+	sim.started = true
+	for _, n := range sim.Nodes {
+		go n.Loop()
+	}
+}
+
+// Stop tears down all the nodes, and the stores are gone with them.
+func (sim *Simulation) Stop() {
+	if !sim.started {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, n := range sim.Nodes {
+		wg.Add(1)
+		go func(n *kernel.Node) {
+			defer wg.Done()
+			n.Teardown()
+		}(n)
+	}
+	wg.Wait()
+	kernel.TestMockReset()
+}
+
+func (sim *Simulation) SetLatency(latency time.Duration) {
+	sim.Network.SetLatency(latency)
+}
+
+// Partition splits the nodes by their indexes into groups that can't
+// reach each other, the nodes not in any group reach all nodes.
+func (sim *Simulation) Partition(groups ...[]int) {
+	addrs := make([][]string, len(groups))
+	for i, g := range groups {
+		for _, n := range g {
+			addrs[i] = append(addrs[i], sim.addrs[n])
+		}
+	}
+	sim.Network.Partition(addrs...)
+}
+
+func (sim *Simulation) Heal() {
+	sim.Network.Heal()
+}
+
+// SubmitDeposit queues a new deposit transaction signed by the domain to
+// the node, the deposits are unique and never conflict with each other.
+func (sim *Simulation) SubmitDeposit(node int) (crypto.Hash, error) {
+	seq := atomic.AddUint64(&sim.sequence, 1)
+	hash := crypto.NewHash([]byte(fmt.Sprintf("SIMULATION#DEPOSIT#%d", seq)))
+	amount := common.NewInteger(seq)
+	deposit := &common.DepositData{
+		Chain:           ethereum.EthereumChainId,
+		AssetKey:        simulationAssetKey,
+		TransactionHash: "0x" + hash.String(),
+		Amount:          amount,
+	}
+
+	tx := common.NewTransaction(deposit.Asset().AssetId())
+	tx.AddDepositInput(deposit)
+	script := common.NewThresholdScript(1)
+	tx.AddOutputWithType(common.OutputTypeScript, []*common.Address{&sim.domain}, script, amount, append(hash[:], hash[:]...))
+	signed := &common.SignedTransaction{Transaction: *tx}
+	err := signed.SignRaw(sim.domain.PrivateSpendKey)
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+	ver := &common.VersionedTransaction{SignedTransaction: *signed}
+	_, err = sim.Nodes[node].QueueTransaction(ver)
+	return ver.PayloadHash(), err
+}
+
+// WaitFinalized waits until all the transactions are finalized on the nodes,
+// or all the nodes if none specified. A transaction may be snapshotted by
+// several nodes, and each node records the first finalized in its topology,
+// so the nodes must have all these snapshots, otherwise it's a fork.
+func (sim *Simulation) WaitFinalized(hashes []crypto.Hash, timeout time.Duration, nodes ...int) error {
+	if len(nodes) == 0 {
+		for i := range sim.Nodes {
+			nodes = append(nodes, i)
+		}
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		missing, err := sim.checkFinalized(hashes, nodes)
+		if err != nil || missing == "" {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("simulation finalization timeout %s", missing)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (sim *Simulation) checkFinalized(hashes []crypto.Hash, nodes []int) (string, error) {
+	for _, h := range hashes {
+		snapshots := make(map[crypto.Hash]bool)
+		for _, i := range nodes {
+			_, snap, err := sim.stores[i].ReadTransaction(h)
+			if err != nil {
+				return "", err
+			}
+			if snap == "" {
+				return fmt.Sprintf("%d:%s", i, h), nil
+			}
+			sh, err := crypto.HashFromString(snap)
+			if err != nil {
+				return "", fmt.Errorf("simulation fork %s %s", h, snap)
+			}
+			snapshots[sh] = true
+		}
+		for sh := range snapshots {
+			for _, i := range nodes {
+				s, err := sim.stores[i].ReadSnapshot(sh)
+				if err != nil {
+					return "", err
+				}
+				if s == nil {
+					return fmt.Sprintf("%d:%s:%s", i, h, sh), nil
+				}
+				if s.Transaction != h {
+					return "", fmt.Errorf("simulation fork %s %s", h, sh)
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+func simulationAccount(i int, role string) common.Address {
+	seed := make([]byte, 64)
+	copy(seed, []byte("SIMULATION#"+role+"#"))
+	seed[62] = byte(i >> 8)
+	seed[63] = byte(i)
+	account := common.NewAddressFromSeed(seed)
+	account.PrivateViewKey = account.PublicSpendKey.DeterministicHashDerive()
+	account.PublicViewKey = account.PrivateViewKey.Public()
+	return account
+}
+
+func simulationGenesis(signers, payees []common.Address) ([]byte, error) {
+	nodes := make([]map[string]string, len(signers))
+	for i := range signers {
+		nodes[i] = map[string]string{
+			"signer":  signers[i].String(),
+			"payee":   payees[i].String(),
+			"balance": "10000",
+		}
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"epoch": simulationEpoch,
+		"nodes": nodes,
+		"domains": []map[string]string{{
+			"signer":  signers[0].String(),
+			"balance": "50000",
+		}},
+	}, "", "  ")
+}
@@ -0,0 +1,53 @@
+package simulation
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulationPartition(t *testing.T) {
+	if testing.Short() {
+		t.Skip("simulation skipped in short mode")
+	}
+	assert := assert.New(t)
+
+	root, err := os.MkdirTemp("", "mixin-simulation-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	_, err = New(root, 3)
+	assert.NotNil(err)
+
+	sim, err := New(root, 7)
+	assert.Nil(err)
+	assert.Len(sim.Nodes, 7)
+	sim.Start()
+	defer sim.Stop()
+	time.Sleep(3 * time.Second)
+
+	var hashes []crypto.Hash
+	for i := 0; i < 20; i++ {
+		h, err := sim.SubmitDeposit(i % len(sim.Nodes))
+		assert.Nil(err)
+		hashes = append(hashes, h)
+	}
+	assert.Nil(sim.WaitFinalized(hashes, 30*time.Second))
+
+	sim.SetLatency(50 * time.Millisecond)
+	sim.Partition([]int{0, 1, 2, 3, 4, 5}, []int{6})
+	var partitioned []crypto.Hash
+	for i := 0; i < 20; i++ {
+		h, err := sim.SubmitDeposit(i % 6)
+		assert.Nil(err)
+		partitioned = append(partitioned, h)
+	}
+	assert.Nil(sim.WaitFinalized(partitioned, 60*time.Second, 0, 1, 2, 3, 4, 5))
+	assert.NotNil(sim.WaitFinalized(partitioned, time.Second, 6))
+
+	sim.Heal()
+	assert.Nil(sim.WaitFinalized(append(hashes, partitioned...), 120*time.Second))
+}
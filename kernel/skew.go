@@ -0,0 +1,153 @@
+package kernel
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	clockSkewSampleExpiration = 10 * time.Minute
+	clockSkewPeersMinimum     = 3
+)
+
+// clockSkew estimates the drift of the local clock by the timestamps of the
+// external announcements, which are the clocks of the peers when they sent
+// them. The drift is the median of the latest offset of each peer, so that
+// neither a few peers with bad clocks nor the network latency could move it
+// much, and it is positive when the local clock is behind the peers.
+//
+// The self announcements are paused while the drift exceeds the threshold,
+// the state only changes in gate, so each transition is logged once.
+type clockSkew struct {
+	sync.Mutex
+	threshold time.Duration
+	offsets   map[crypto.Hash]clockSkewSample
+	futures   uint64
+	paused    bool
+}
+
+type clockSkewSample struct {
+	offset time.Duration
+	at     time.Time
+}
+
+type ClockSkewStats struct {
+	Drift     time.Duration
+	Peers     int
+	Threshold time.Duration
+	Exceeded  bool
+	Paused    bool
+	Futures   uint64
+}
+
+func newClockSkew(threshold int) *clockSkew {
+	return &clockSkew{
+		threshold: time.Duration(threshold) * time.Millisecond,
+		offsets:   make(map[crypto.Hash]clockSkewSample),
+	}
+}
+
+func (s *clockSkew) sample(peer crypto.Hash, timestamp uint64, now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	offset := time.Duration(int64(timestamp) - now.UnixNano())
+	s.offsets[peer] = clockSkewSample{offset: offset, at: now}
+}
+
+// future counts the announcements rejected for the timestamps too far in
+// the future, which are also sampled because they are likely the proof of
+// the local clock behind.
+func (s *clockSkew) future(peer crypto.Hash, timestamp uint64, now time.Time) {
+	s.sample(peer, timestamp, now)
+	s.Lock()
+	s.futures += 1
+	s.Unlock()
+}
+
+// drift returns the estimated drift and the peers sampled, and the drift is
+// 0 without enough peers to estimate.
+func (s *clockSkew) drift(now time.Time) (time.Duration, int) {
+	s.Lock()
+	defer s.Unlock()
+
+	offsets := make([]time.Duration, 0, len(s.offsets))
+	for peer, sample := range s.offsets {
+		if now.Sub(sample.at) > clockSkewSampleExpiration {
+			delete(s.offsets, peer)
+			continue
+		}
+		offsets = append(offsets, sample.offset)
+	}
+	if len(offsets) < clockSkewPeersMinimum {
+		return 0, len(offsets)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets[len(offsets)/2], len(offsets)
+}
+
+// gate pauses the self announcements when the drift exceeds the threshold,
+// and resumes them when it's back, which is checked once each round of the
+// announcement loop, instead of each snapshot.
+func (s *clockSkew) gate(now time.Time) bool {
+	drift, _ := s.drift(now)
+	exceeded := drift > s.threshold || -drift > s.threshold
+	s.Lock()
+	defer s.Unlock()
+
+	if exceeded == s.paused {
+		return s.paused
+	}
+	s.paused = exceeded
+	if exceeded {
+		logger.Printf("clockSkew pause the self announcements, drift %s exceeds %s\n", drift, s.threshold)
+	} else {
+		logger.Printf("clockSkew resume the self announcements, drift %s within %s\n", drift, s.threshold)
+	}
+	return s.paused
+}
+
+func (s *clockSkew) pausing() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.paused
+}
+
+func (s *clockSkew) Stats(now time.Time) ClockSkewStats {
+	drift, peers := s.drift(now)
+	s.Lock()
+	futures, paused := s.futures, s.paused
+	s.Unlock()
+	return ClockSkewStats{
+		Drift:     drift,
+		Peers:     peers,
+		Threshold: s.threshold,
+		Exceeded:  drift > s.threshold || -drift > s.threshold,
+		Paused:    paused,
+		Futures:   futures,
+	}
+}
+
+// scheduleAnnouncement announces the self snapshot again after the wait, when
+// the local clock is slightly behind the cache round, e.g. adjusted backward,
+// instead of the requeue backoff from a round gap. The wait never exceeds the
+// skew threshold, a longer one is left to the cache queue loop.
+func (chain *Chain) scheduleAnnouncement(m *CosiAction, wait time.Duration) {
+	m.Snapshot.Timestamp = 0
+	clock.Go(func() {
+		clock.Sleep(wait)
+		err := chain.AppendCosiAction(m)
+		if err != nil {
+			logger.Verbosef("scheduleAnnouncement(%s) %s ERROR %s\n", chain.ChainId, m.Snapshot.Transaction, err)
+		}
+	})
+}
+
+func (node *Node) ClockSkewStats() ClockSkewStats {
+	return node.skew.Stats(clock.Now())
+}
@@ -0,0 +1,61 @@
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockSkew(t *testing.T) {
+	assert := assert.New(t)
+
+	peer := func(i int) crypto.Hash {
+		return crypto.NewHash([]byte{byte(i)})
+	}
+	now := time.Unix(1600000000, 0)
+	ts := func(offset time.Duration) uint64 {
+		return uint64(now.Add(offset).UnixNano())
+	}
+
+	skew := newClockSkew(3000)
+	skew.sample(peer(0), ts(5*time.Second), now)
+	skew.sample(peer(1), ts(5*time.Second), now)
+	drift, peers := skew.drift(now)
+	assert.Equal(time.Duration(0), drift)
+	assert.Equal(2, peers)
+	assert.False(skew.Stats(now).Exceeded)
+
+	skew.sample(peer(2), ts(-time.Minute), now)
+	skew.sample(peer(3), ts(100*time.Millisecond), now)
+	skew.sample(peer(4), ts(200*time.Millisecond), now)
+	drift, peers = skew.drift(now)
+	assert.Equal(200*time.Millisecond, drift)
+	assert.Equal(5, peers)
+	assert.False(skew.Stats(now).Exceeded)
+
+	skew.future(peer(2), ts(time.Minute), now)
+	skew.future(peer(3), ts(time.Minute), now)
+	assert.False(skew.pausing())
+	stats := skew.Stats(now)
+	assert.Equal(5*time.Second, stats.Drift)
+	assert.True(stats.Exceeded)
+	assert.False(stats.Paused)
+	assert.True(skew.gate(now))
+	assert.True(skew.gate(now))
+	assert.True(skew.pausing())
+	assert.True(skew.Stats(now).Paused)
+	assert.Equal(uint64(2), stats.Futures)
+	assert.Equal(3*time.Second, stats.Threshold)
+
+	later := now.Add(clockSkewSampleExpiration + time.Second)
+	skew.sample(peer(5), uint64(later.UnixNano()), later)
+	drift, peers = skew.drift(later)
+	assert.Equal(time.Duration(0), drift)
+	assert.Equal(1, peers)
+	assert.False(skew.Stats(later).Exceeded)
+	assert.True(skew.pausing())
+	assert.False(skew.gate(later))
+	assert.False(skew.pausing())
+}
@@ -0,0 +1,133 @@
+package kernel
+
+import (
+	"sync"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+// announcementThrottle limits the in-flight verifications of the external
+// announcements of a chain, so a node spamming announcements can't dominate
+// the signer while the honest nodes starve. A verification is in flight since
+// the commitment is sent, until the challenge is responded, the snapshot is
+// finalized, or it expires after a round gap.
+type announcementThrottle struct {
+	sync.Mutex
+	limit    int
+	overflow string
+	inflight map[crypto.Hash]uint64
+	queue    []*CosiAction
+	stats    AnnouncementThrottleStats
+}
+
+type AnnouncementThrottleStats struct {
+	InFlight  int
+	Queued    int
+	Admitted  uint64
+	Throttled uint64
+	Dropped   uint64
+}
+
+func newAnnouncementThrottle(custom *config.Custom) *announcementThrottle {
+	return &announcementThrottle{
+		limit:    custom.Node.AnnouncementInFlight,
+		overflow: custom.Node.AnnouncementOverflow,
+		inflight: make(map[crypto.Hash]uint64),
+	}
+}
+
+// admit returns true if the announcement could be verified now, otherwise it
+// is queued to verify after any in-flight one done, or discarded, by the
+// overflow policy, and the queue discards the oldest one when full.
+func (t *announcementThrottle) admit(m *CosiAction, now uint64) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	for snap, ts := range t.inflight {
+		if ts+config.SnapshotRoundGap < now {
+			delete(t.inflight, snap)
+		}
+	}
+	if _, found := t.inflight[m.SnapshotHash]; found {
+		return true
+	}
+	if len(t.inflight) < t.limit {
+		t.inflight[m.SnapshotHash] = now
+		t.stats.Admitted += 1
+		return true
+	}
+
+	t.stats.Throttled += 1
+	if t.overflow != config.AnnouncementOverflowQueue {
+		t.stats.Dropped += 1
+		return false
+	}
+	if len(t.queue) >= t.limit {
+		t.queue = t.queue[1:]
+		t.stats.Dropped += 1
+	}
+	t.queue = append(t.queue, m)
+	return false
+}
+
+// release ends the verification of the snapshot, and returns the oldest
+// queued announcement to verify next if any.
+func (t *announcementThrottle) release(snap crypto.Hash) *CosiAction {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, found := t.inflight[snap]; !found {
+		return nil
+	}
+	delete(t.inflight, snap)
+	if len(t.queue) == 0 {
+		return nil
+	}
+	m := t.queue[0]
+	t.queue = t.queue[1:]
+	return m
+}
+
+// reset abandons all the in-flight verifications when the chain starts a new
+// round, the queued announcements of the old round are dropped later by the
+// sanity check.
+func (t *announcementThrottle) reset() {
+	t.Lock()
+	defer t.Unlock()
+
+	t.inflight = make(map[crypto.Hash]uint64)
+}
+
+func (t *announcementThrottle) Stats() AnnouncementThrottleStats {
+	t.Lock()
+	defer t.Unlock()
+
+	stats := t.stats
+	stats.InFlight = len(t.inflight)
+	stats.Queued = len(t.queue)
+	return stats
+}
+
+func (chain *Chain) admitAnnouncement(m *CosiAction) bool {
+	return chain.throttle.admit(m, uint64(clock.Now().UnixNano()))
+}
+
+func (chain *Chain) releaseAnnouncement(snap crypto.Hash) {
+	m := chain.throttle.release(snap)
+	if m != nil {
+		chain.AppendCosiAction(m)
+	}
+}
+
+func (node *Node) AnnouncementThrottleStats() map[crypto.Hash]AnnouncementThrottleStats {
+	node.chains.RLock()
+	defer node.chains.RUnlock()
+
+	stats := make(map[crypto.Hash]AnnouncementThrottleStats)
+	for id, chain := range node.chains.m {
+		stats[id] = chain.throttle.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,59 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncementThrottle(t *testing.T) {
+	assert := assert.New(t)
+
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+	custom.Node.AnnouncementInFlight = 2
+
+	action := func(i int) *CosiAction {
+		return &CosiAction{SnapshotHash: crypto.NewHash([]byte{byte(i)})}
+	}
+	now := uint64(1000000000000)
+
+	tr := newAnnouncementThrottle(custom)
+	assert.True(tr.admit(action(0), now))
+	assert.True(tr.admit(action(1), now))
+	assert.True(tr.admit(action(1), now))
+	for i := 2; i < 5; i++ {
+		assert.False(tr.admit(action(i), now))
+	}
+	stats := tr.Stats()
+	assert.Equal(2, stats.InFlight)
+	assert.Equal(2, stats.Queued)
+	assert.Equal(uint64(2), stats.Admitted)
+	assert.Equal(uint64(3), stats.Throttled)
+	assert.Equal(uint64(1), stats.Dropped)
+
+	assert.Nil(tr.release(action(9).SnapshotHash))
+	m := tr.release(action(0).SnapshotHash)
+	assert.Equal(action(3).SnapshotHash, m.SnapshotHash)
+	assert.True(tr.admit(m, now))
+	assert.Equal(1, tr.Stats().Queued)
+
+	assert.True(tr.admit(action(5), now+config.SnapshotRoundGap+1))
+	assert.Equal(1, tr.Stats().InFlight)
+	tr.reset()
+	assert.Equal(0, tr.Stats().InFlight)
+	assert.Equal(1, tr.Stats().Queued)
+
+	custom.Node.AnnouncementOverflow = config.AnnouncementOverflowDiscard
+	tr = newAnnouncementThrottle(custom)
+	assert.True(tr.admit(action(0), now))
+	assert.True(tr.admit(action(1), now))
+	assert.False(tr.admit(action(2), now))
+	assert.Nil(tr.release(action(0).SnapshotHash))
+	stats = tr.Stats()
+	assert.Equal(1, stats.InFlight)
+	assert.Equal(0, stats.Queued)
+	assert.Equal(uint64(1), stats.Dropped)
+}
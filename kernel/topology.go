@@ -21,6 +21,9 @@ type TopologicalSequence struct {
 	check  uint64
 	count  uint64
 	tps    float64
+
+	writes sync.WaitGroup
+	done   chan struct{}
 }
 
 func (node *Node) TopologicalOrder() uint64 {
@@ -42,45 +45,68 @@ type SnapshotWitness struct {
 
 func (node *Node) WitnessSnapshot(s *common.SnapshotWithTopologicalOrder) *SnapshotWitness {
 	msg := crypto.NewHash(common.MsgpackMarshalPanic(s))
-	sig := node.Signer.PrivateSpendKey.Sign(msg[:])
+	sig := node.sign(msg[:])
 	return &SnapshotWitness{
 		Signature: &sig,
 		Timestamp: uint64(clock.Now().UnixNano()),
 	}
 }
 
+// TopoWrite assigns the next topological order to the snapshot and writes
+// it, the order is assigned with the lock held, but the write is not, so the
+// store could batch the concurrent writes of different chains, and the store
+// commits them in the topological order.
 func (node *Node) TopoWrite(s *common.Snapshot, signers []crypto.Hash) *common.SnapshotWithTopologicalOrder {
-	node.TopoCounter.Lock()
-	defer node.TopoCounter.Unlock()
+	topo := node.TopoCounter.next(s, signers)
+	defer node.TopoCounter.writes.Done()
+
+	node.chaosStorageLatency()
+	node.chaosCrash(ChaosCrashSnapshotWrite)
+	err := node.persistStore.WriteSnapshot(topo, signers)
+	if err != nil {
+		panic(err)
+	}
+	node.chaosCrash(ChaosCrashSnapshotWritten)
+	node.recordSignerParticipation(s.Timestamp, signers)
+	node.events.publish(&SnapshotFinalized{Snapshot: topo, Signers: signers})
+	return topo
+}
+
+func (topo *TopologicalSequence) next(s *common.Snapshot, signers []crypto.Hash) *common.SnapshotWithTopologicalOrder {
+	topo.Lock()
+	defer topo.Unlock()
 
 	if s.Version >= common.SnapshotVersion && len(signers) != len(s.Signature.Keys()) {
 		panic(fmt.Errorf("malformed snapshot signers %s %d %d", s.Hash, len(signers), len(s.Signature.Keys())))
 	}
 
-	if node.TopoCounter.seq%100000 == 7 {
-		node.TopoCounter.filter = make(map[crypto.Hash]bool)
+	if topo.seq%100000 == 7 {
+		topo.filter = make(map[crypto.Hash]bool)
 	}
-	if !node.TopoCounter.filter[s.Transaction] {
-		node.TopoCounter.filter[s.Transaction] = true
-		node.TopoCounter.count += 1
+	if !topo.filter[s.Transaction] {
+		topo.filter[s.Transaction] = true
+		topo.count += 1
 	}
 
-	node.TopoCounter.seq += 1
-	topo := &common.SnapshotWithTopologicalOrder{
+	topo.seq += 1
+	topo.writes.Add(1)
+	return &common.SnapshotWithTopologicalOrder{
 		Snapshot:         *s,
-		TopologicalOrder: node.TopoCounter.seq,
+		TopologicalOrder: topo.seq,
 	}
-	err := node.persistStore.WriteSnapshot(topo, signers)
-	if err != nil {
-		panic(err)
-	}
-	return topo
 }
 
 func (topo *TopologicalSequence) TopoStats() {
 	durationSeconds := 60
+	ticker := time.NewTicker(time.Duration(durationSeconds) * time.Second)
+	defer ticker.Stop()
+
 	for {
-		time.Sleep(time.Duration(durationSeconds) * time.Second)
+		select {
+		case <-topo.done:
+			return
+		case <-ticker.C:
+		}
 		topo.sps = float64(topo.seq-topo.point) / float64(durationSeconds)
 		topo.point = topo.seq
 
@@ -89,10 +115,23 @@ func (topo *TopologicalSequence) TopoStats() {
 	}
 }
 
+func (topo *TopologicalSequence) flush(store storage.Store) error {
+	topo.Lock()
+	defer topo.Unlock()
+
+	topo.writes.Wait()
+	close(topo.done)
+	if seq := store.TopologySequence(); seq != topo.seq {
+		return fmt.Errorf("topology sequence mismatch %d %d", seq, topo.seq)
+	}
+	return nil
+}
+
 func getTopologyCounter(store storage.Store) *TopologicalSequence {
 	topo := &TopologicalSequence{
 		seq:    store.TopologySequence(),
 		filter: make(map[crypto.Hash]bool),
+		done:   make(chan struct{}),
 	}
 	topo.point = topo.seq
 	go topo.TopoStats()
@@ -0,0 +1,204 @@
+package kernel
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+const (
+	transportKeyExpiration = 10 * time.Minute
+	relayFrameSecretLabel  = "MIXINRELAYFRAME"
+)
+
+// TransportKeyAnnouncement lists the transport certificate fingerprints
+// a node accepts connections with, signed by its signer key.
+type TransportKeyAnnouncement struct {
+	Keys      []crypto.Hash
+	Timestamp uint64
+	Signer    crypto.Key
+	Signature crypto.Signature
+}
+
+func (a *TransportKeyAnnouncement) payload() []byte {
+	return common.MsgpackMarshalPanic([]interface{}{
+		a.Keys,
+		a.Timestamp,
+		a.Signer,
+	})
+}
+
+func (node *Node) BuildTransportKeyMessage(keys []crypto.Hash) []byte {
+	a := &TransportKeyAnnouncement{
+		Keys:      keys,
+		Timestamp: uint64(clock.Now().UnixNano()),
+		Signer:    node.Signer.PublicSpendKey,
+	}
+	a.Signature = node.sign(a.payload())
+	return common.MsgpackMarshalPanic(a)
+}
+
+func (node *Node) VerifyTransportKey(peerId crypto.Hash, msg []byte) ([]crypto.Hash, uint64, error) {
+	var a TransportKeyAnnouncement
+	err := common.MsgpackUnmarshal(msg, &a)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(a.Keys) < 1 || len(a.Keys) > 3 {
+		return nil, 0, fmt.Errorf("transport key invalid keys count %s %d", peerId, len(a.Keys))
+	}
+
+	var signer common.Address
+	signer.PublicSpendKey = a.Signer
+	signer.PublicViewKey = signer.PublicSpendKey.DeterministicHashDerive().Public()
+	if signer.Hash().ForNetwork(node.networkId) != peerId {
+		return nil, 0, fmt.Errorf("transport key invalid signer %s %s", peerId, a.Signer)
+	}
+	now := uint64(clock.Now().UnixNano())
+	if a.Timestamp > now+config.SnapshotRoundGap*config.SnapshotReferenceThreshold {
+		return nil, 0, fmt.Errorf("transport key future timestamp %s %d", peerId, a.Timestamp)
+	}
+	if a.Timestamp+uint64(transportKeyExpiration) < now {
+		return nil, 0, fmt.Errorf("transport key expired %s %d", peerId, a.Timestamp)
+	}
+	if !a.Signer.Verify(a.payload(), a.Signature) {
+		return nil, 0, fmt.Errorf("transport key signature invalid %s", peerId)
+	}
+	return a.Keys, a.Timestamp, nil
+}
+
+// EndorseTransportKey signs the public key of the transport certificate by
+// the signer key, to bind the mutual TLS identity to the node.
+func (node *Node) EndorseTransportKey(spki []byte) []byte {
+	sig := node.sign(transportEndorsementPayload(node.networkId, spki))
+	return append(node.Signer.PublicSpendKey[:], sig[:]...)
+}
+
+func (node *Node) VerifyTransportEndorsement(endorsement, spki []byte) (crypto.Hash, error) {
+	if len(endorsement) != len(crypto.Key{})+len(crypto.Signature{}) {
+		return crypto.Hash{}, fmt.Errorf("transport endorsement malformated %d", len(endorsement))
+	}
+	var signer common.Address
+	copy(signer.PublicSpendKey[:], endorsement)
+	signer.PublicViewKey = signer.PublicSpendKey.DeterministicHashDerive().Public()
+	peerId := signer.Hash().ForNetwork(node.networkId)
+	peer := node.GetAcceptedOrPledgingNode(peerId)
+	if node.custom.Node.ConsensusOnly && peer == nil {
+		return crypto.Hash{}, fmt.Errorf("transport endorsement invalid consensus peer %s", peerId)
+	}
+	if peer != nil && peer.Signer.Hash() != signer.Hash() {
+		return crypto.Hash{}, fmt.Errorf("transport endorsement invalid consensus peer %s", peerId)
+	}
+
+	var sig crypto.Signature
+	copy(sig[:], endorsement[len(signer.PublicSpendKey):])
+	if !signer.PublicSpendKey.Verify(transportEndorsementPayload(node.networkId, spki), sig) {
+		return crypto.Hash{}, fmt.Errorf("transport endorsement signature invalid %s", peerId)
+	}
+	return peerId, nil
+}
+
+// SignRelayFrame signs the relay frame payload, the signature is the signer
+// public key followed by the signature, the same as the transport endorsement.
+func (node *Node) SignRelayFrame(payload []byte) []byte {
+	sig := node.sign(relayFramePayload(node.networkId, payload))
+	return append(node.Signer.PublicSpendKey[:], sig[:]...)
+}
+
+func (node *Node) VerifyRelayFrame(signature, payload []byte) (crypto.Hash, error) {
+	if len(signature) != len(crypto.Key{})+len(crypto.Signature{}) {
+		return crypto.Hash{}, fmt.Errorf("relay frame signature malformated %d", len(signature))
+	}
+	var signer common.Address
+	copy(signer.PublicSpendKey[:], signature)
+	signer.PublicViewKey = signer.PublicSpendKey.DeterministicHashDerive().Public()
+	peerId := signer.Hash().ForNetwork(node.networkId)
+	peer := node.GetAcceptedOrPledgingNode(peerId)
+	if node.custom.Node.ConsensusOnly && peer == nil {
+		return crypto.Hash{}, fmt.Errorf("relay frame invalid consensus peer %s", peerId)
+	}
+	if peer != nil && peer.Signer.Hash() != signer.Hash() {
+		return crypto.Hash{}, fmt.Errorf("relay frame invalid consensus peer %s", peerId)
+	}
+
+	var sig crypto.Signature
+	copy(sig[:], signature[len(signer.PublicSpendKey):])
+	if !signer.PublicSpendKey.Verify(relayFramePayload(node.networkId, payload), sig) {
+		return crypto.Hash{}, fmt.Errorf("relay frame signature invalid %s", peerId)
+	}
+	return peerId, nil
+}
+
+// SealRelayFrame encrypts the relay frame data to the target node with
+// AES-GCM, the key is the ECDH of the signer keys of the two nodes, so the
+// relay only learns the size of the data. The target must be an accepted or
+// pledging node to know its signer key.
+func (node *Node) SealRelayFrame(target crypto.Hash, data []byte) ([]byte, error) {
+	peer := node.GetAcceptedOrPledgingNode(target)
+	if peer == nil {
+		return nil, fmt.Errorf("relay frame unknown target %s", target)
+	}
+	return node.sealRelayFrame(peer.Signer.PublicSpendKey, target, data)
+}
+
+func (node *Node) sealRelayFrame(public crypto.Key, target crypto.Hash, data []byte) ([]byte, error) {
+	aead, err := node.relayFrameCipher(public)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(clock.Reader(), nonce)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, target[:]), nil
+}
+
+// OpenRelayFrame decrypts the relay frame data to this node, sealed by the
+// signer of the relay frame signature, which must be verified already.
+func (node *Node) OpenRelayFrame(signature, sealed []byte) ([]byte, error) {
+	if len(signature) != len(crypto.Key{})+len(crypto.Signature{}) {
+		return nil, fmt.Errorf("relay frame signature malformated %d", len(signature))
+	}
+	var source crypto.Key
+	copy(source[:], signature)
+	aead, err := node.relayFrameCipher(source)
+	if err != nil {
+		return nil, err
+	}
+	size := aead.NonceSize()
+	if len(sealed) < size {
+		return nil, fmt.Errorf("relay frame data malformated %d", len(sealed))
+	}
+	return aead.Open(nil, sealed[:size], sealed[size:], node.IdForNetwork[:])
+}
+
+func (node *Node) relayFrameCipher(public crypto.Key) (cipher.AEAD, error) {
+	label := relayFrameSecretLabel + node.networkId.String()
+	secret, err := node.signerBackend.SharedSecret(label, public)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func relayFramePayload(networkId crypto.Hash, payload []byte) []byte {
+	h := crypto.NewHash(append(networkId[:], payload...))
+	return h[:]
+}
+
+func transportEndorsementPayload(networkId crypto.Hash, spki []byte) []byte {
+	h := crypto.NewHash(append(networkId[:], spki...))
+	return h[:]
+}
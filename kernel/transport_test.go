@@ -0,0 +1,55 @@
+package kernel
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayFrameSeal(t *testing.T) {
+	assert := assert.New(t)
+
+	networkId := crypto.NewHash([]byte("network"))
+	nodes := make([]*Node, 3)
+	keys := make([]crypto.Key, 3)
+	for i, name := range []string{"source", "target", "relay"} {
+		seed := crypto.NewHash([]byte(name))
+		keys[i] = crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+		nodes[i] = &Node{
+			networkId:     networkId,
+			IdForNetwork:  crypto.NewHash([]byte(name)),
+			Signer:        common.Address{PublicSpendKey: keys[i].Public()},
+			signerBackend: signer.NewLocal(keys[i]),
+		}
+	}
+	source, target, relay := nodes[0], nodes[1], nodes[2]
+
+	data := []byte("relay frame message data")
+	sealed, err := source.sealRelayFrame(keys[1].Public(), target.IdForNetwork, data)
+	assert.Nil(err)
+	assert.False(bytes.Contains(sealed, data))
+	signature := source.SignRelayFrame([]byte("payload"))
+
+	opened, err := target.OpenRelayFrame(signature, sealed)
+	assert.Nil(err)
+	assert.Equal(data, opened)
+	_, err = relay.OpenRelayFrame(signature, sealed)
+	assert.NotNil(err)
+	relay.IdForNetwork = target.IdForNetwork
+	_, err = relay.OpenRelayFrame(signature, sealed)
+	assert.NotNil(err)
+
+	other := crypto.NewHash([]byte("other"))
+	sealed, err = source.sealRelayFrame(keys[1].Public(), other, data)
+	assert.Nil(err)
+	_, err = target.OpenRelayFrame(signature, sealed)
+	assert.NotNil(err)
+	_, err = target.OpenRelayFrame(signature[:32], sealed)
+	assert.NotNil(err)
+	_, err = target.OpenRelayFrame(signature, sealed[:8])
+	assert.NotNil(err)
+}
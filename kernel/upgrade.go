@@ -0,0 +1,223 @@
+package kernel
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+)
+
+const (
+	upgradeIntentExpiration = 24 * time.Hour
+	upgradeIntentRefresh    = time.Hour
+)
+
+type UpgradeIntent struct {
+	Version      string
+	Capabilities []string
+	Activation   uint64
+	Timestamp    uint64
+	Signer       crypto.Key
+	Signature    crypto.Signature
+}
+
+type UpgradeReadiness struct {
+	Capability string
+	Activation uint64
+	Nodes      []crypto.Hash
+	Threshold  int
+	Ready      bool
+}
+
+func (intent *UpgradeIntent) payload() []byte {
+	return common.MsgpackMarshalPanic([]interface{}{
+		intent.Version,
+		intent.Capabilities,
+		intent.Activation,
+		intent.Timestamp,
+		intent.Signer,
+	})
+}
+
+// BuildUpgradeIntentMessages returns the signed intent of self and the
+// unexpired intents received from the other nodes, so the intents are
+// relayed to the nodes not directly connected.
+func (node *Node) BuildUpgradeIntentMessages() [][]byte {
+	var msgs [][]byte
+	for _, intent := range node.ListUpgradeIntents() {
+		msgs = append(msgs, common.MsgpackMarshalPanic(intent))
+	}
+	return msgs
+}
+
+// signedUpgradeIntent caches the signed intent of self, and only signs it
+// again after the refresh interval, far before it's expired by the others.
+func (node *Node) signedUpgradeIntent() *UpgradeIntent {
+	up := node.custom.Upgrade
+	if len(up.Capabilities) == 0 || up.Activation <= 0 {
+		return nil
+	}
+	now := uint64(clock.Now().UnixNano())
+	return node.upgradeIntents.local(now, func() *UpgradeIntent {
+		intent := &UpgradeIntent{
+			Version:      config.BuildVersion,
+			Capabilities: up.Capabilities,
+			Activation:   uint64(time.Unix(up.Activation, 0).UnixNano()),
+			Timestamp:    now,
+			Signer:       node.Signer.PublicSpendKey,
+		}
+		intent.Signature = node.sign(intent.payload())
+		return intent
+	})
+}
+
+func (node *Node) UpdateUpgradeIntent(peerId crypto.Hash, msg []byte) error {
+	var intent UpgradeIntent
+	err := common.MsgpackUnmarshal(msg, &intent)
+	if err != nil {
+		return err
+	}
+	now := uint64(clock.Now().UnixNano())
+	cn, err := verifyUpgradeIntent(&intent, node.NodesListWithoutState(now, false), now)
+	if err != nil {
+		return fmt.Errorf("upgrade intent from %s %v", peerId, err)
+	}
+	if cn.IdForNetwork == node.IdForNetwork {
+		return nil
+	}
+	node.upgradeIntents.set(cn.IdForNetwork, &intent)
+	return nil
+}
+
+// verifyUpgradeIntent finds the accepted or pledging node of the intent
+// signer, because the intent may be relayed by any peer.
+func verifyUpgradeIntent(intent *UpgradeIntent, nodes []*CNode, now uint64) (*CNode, error) {
+	var signer *CNode
+	for _, cn := range nodes {
+		if cn.State != common.NodeStateAccepted && cn.State != common.NodeStatePledging {
+			continue
+		}
+		if cn.Signer.PublicSpendKey == intent.Signer {
+			signer = cn
+			break
+		}
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("invalid signer %s", intent.Signer)
+	}
+	if intent.Timestamp > now+config.SnapshotRoundGap*config.SnapshotReferenceThreshold {
+		return nil, fmt.Errorf("future timestamp %d", intent.Timestamp)
+	}
+	if intent.Timestamp+uint64(upgradeIntentExpiration) < now {
+		return nil, fmt.Errorf("expired %d", intent.Timestamp)
+	}
+	if !intent.Signer.Verify(intent.payload(), intent.Signature) {
+		return nil, fmt.Errorf("signature invalid %s", signer.IdForNetwork)
+	}
+	return signer, nil
+}
+
+func (node *Node) ListUpgradeIntents() map[crypto.Hash]*UpgradeIntent {
+	now := uint64(clock.Now().UnixNano())
+	intents := node.upgradeIntents.filter(func(intent *UpgradeIntent) bool {
+		return intent.Timestamp+uint64(upgradeIntentExpiration) >= now
+	})
+	if intent := node.signedUpgradeIntent(); intent != nil {
+		intents[node.IdForNetwork] = intent
+	}
+	return intents
+}
+
+func (node *Node) UpgradeReadiness() []*UpgradeReadiness {
+	now := uint64(clock.Now().UnixNano())
+	threshold := node.ConsensusThreshold(now, false)
+	accepted := make(map[crypto.Hash]bool)
+	for _, cn := range node.NodesListWithoutState(now, true) {
+		accepted[cn.IdForNetwork] = true
+	}
+	return tallyUpgradeReadiness(node.ListUpgradeIntents(), accepted, threshold)
+}
+
+func tallyUpgradeReadiness(intents map[crypto.Hash]*UpgradeIntent, accepted map[crypto.Hash]bool, threshold int) []*UpgradeReadiness {
+	readiness := make(map[string]*UpgradeReadiness)
+	for id, intent := range intents {
+		if !accepted[id] {
+			continue
+		}
+		for _, c := range intent.Capabilities {
+			key := fmt.Sprintf("%s:%d", c, intent.Activation)
+			r := readiness[key]
+			if r == nil {
+				r = &UpgradeReadiness{
+					Capability: c,
+					Activation: intent.Activation,
+					Threshold:  threshold,
+				}
+				readiness[key] = r
+			}
+			r.Nodes = append(r.Nodes, id)
+			r.Ready = len(r.Nodes) >= threshold
+		}
+	}
+
+	result := make([]*UpgradeReadiness, 0)
+	for _, r := range readiness {
+		sort.Slice(r.Nodes, func(i, j int) bool {
+			return r.Nodes[i].String() < r.Nodes[j].String()
+		})
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Capability != result[j].Capability {
+			return result[i].Capability < result[j].Capability
+		}
+		return result[i].Activation < result[j].Activation
+	})
+	return result
+}
+
+type upgradeIntentMap struct {
+	sync.RWMutex
+	m      map[crypto.Hash]*UpgradeIntent
+	signed *UpgradeIntent
+}
+
+func (m *upgradeIntentMap) local(now uint64, sign func() *UpgradeIntent) *UpgradeIntent {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.signed != nil && m.signed.Timestamp+uint64(upgradeIntentRefresh) > now {
+		return m.signed
+	}
+	m.signed = sign()
+	return m.signed
+}
+
+func (m *upgradeIntentMap) set(id crypto.Hash, intent *UpgradeIntent) {
+	m.Lock()
+	defer m.Unlock()
+
+	old := m.m[id]
+	if old != nil && old.Timestamp >= intent.Timestamp {
+		return
+	}
+	m.m[id] = intent
+}
+
+func (m *upgradeIntentMap) filter(fn func(*UpgradeIntent) bool) map[crypto.Hash]*UpgradeIntent {
+	m.RLock()
+	defer m.RUnlock()
+
+	intents := make(map[crypto.Hash]*UpgradeIntent)
+	for id, intent := range m.m {
+		if fn(intent) {
+			intents[id] = intent
+		}
+	}
+	return intents
+}
@@ -0,0 +1,101 @@
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel/internal/clock"
+	"github.com/MixinNetwork/mixin/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpgradeIntent(t *testing.T) {
+	assert := assert.New(t)
+
+	keys := make([]crypto.Key, 3)
+	nodes := make([]*CNode, 3)
+	for i := range nodes {
+		seed := crypto.NewHash([]byte{byte(i)})
+		keys[i] = crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+		nodes[i] = &CNode{
+			IdForNetwork: crypto.NewHash(seed[:]),
+			Signer:       common.Address{PublicSpendKey: keys[i].Public()},
+			State:        common.NodeStateAccepted,
+		}
+	}
+	nodes[2].State = common.NodeStateRemoved
+
+	now := uint64(clock.Now().UnixNano())
+	intent := &UpgradeIntent{
+		Version:      "v0.0.1",
+		Capabilities: []string{"snapshot-v2"},
+		Activation:   now + uint64(time.Hour),
+		Timestamp:    now,
+		Signer:       keys[1].Public(),
+	}
+	intent.Signature = keys[1].Sign(intent.payload())
+	cn, err := verifyUpgradeIntent(intent, nodes, now)
+	assert.Nil(err)
+	assert.Equal(nodes[1].IdForNetwork, cn.IdForNetwork)
+
+	_, err = verifyUpgradeIntent(intent, nodes, now+uint64(upgradeIntentExpiration)+1)
+	assert.NotNil(err)
+	_, err = verifyUpgradeIntent(intent, nodes, now-config.SnapshotRoundGap*config.SnapshotReferenceThreshold-1)
+	assert.NotNil(err)
+	forged := *intent
+	forged.Activation = now
+	_, err = verifyUpgradeIntent(&forged, nodes, now)
+	assert.NotNil(err)
+	removed := *intent
+	removed.Signer = keys[2].Public()
+	removed.Signature = keys[2].Sign(removed.payload())
+	_, err = verifyUpgradeIntent(&removed, nodes, now)
+	assert.NotNil(err)
+
+	intents := &upgradeIntentMap{m: make(map[crypto.Hash]*UpgradeIntent)}
+	intents.set(cn.IdForNetwork, intent)
+	older := *intent
+	older.Timestamp = now - 1
+	intents.set(cn.IdForNetwork, &older)
+	assert.Equal(now, intents.m[cn.IdForNetwork].Timestamp)
+
+	node := &Node{
+		IdForNetwork:   nodes[0].IdForNetwork,
+		Signer:         nodes[0].Signer,
+		custom:         &config.Custom{},
+		signerBackend:  signer.NewLocal(keys[0]),
+		upgradeIntents: intents,
+	}
+	assert.Nil(node.signedUpgradeIntent())
+	msgs := node.BuildUpgradeIntentMessages()
+	assert.Len(msgs, 1)
+	var relayed UpgradeIntent
+	assert.Nil(common.MsgpackUnmarshal(msgs[0], &relayed))
+	assert.Equal(intent.Signature, relayed.Signature)
+
+	node.custom.Upgrade.Capabilities = []string{"snapshot-v2"}
+	node.custom.Upgrade.Activation = time.Now().Add(time.Hour).Unix()
+	local := node.signedUpgradeIntent()
+	assert.NotNil(local)
+	assert.True(local.Signer.Verify(local.payload(), local.Signature))
+	assert.Equal(local, node.signedUpgradeIntent())
+	assert.Len(node.BuildUpgradeIntentMessages(), 2)
+	local.Timestamp -= uint64(upgradeIntentRefresh)
+	assert.NotEqual(local, node.signedUpgradeIntent())
+
+	accepted := map[crypto.Hash]bool{nodes[0].IdForNetwork: true, nodes[1].IdForNetwork: true}
+	readiness := tallyUpgradeReadiness(node.ListUpgradeIntents(), accepted, 2)
+	assert.Len(readiness, 2)
+	ready := readiness[0]
+	if ready.Activation != intent.Activation {
+		ready = readiness[1]
+	}
+	assert.Len(ready.Nodes, 1)
+	assert.False(ready.Ready)
+	readiness = tallyUpgradeReadiness(node.ListUpgradeIntents(), accepted, 1)
+	assert.True(readiness[0].Ready)
+	assert.True(readiness[1].Ready)
+}
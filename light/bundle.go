@@ -0,0 +1,96 @@
+// Package light verifies the finalization of a transaction without a node,
+// and depends only on the crypto package, so that mobile wallets could
+// import it with a small footprint.
+package light
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// snapshotTransactionMarker is the msgpack encoded key and the bin header
+// of the transaction hash in the snapshot payload.
+var snapshotTransactionMarker = append([]byte{0xab}, []byte("Transaction\xc4\x20")...)
+
+// Bundle is the finalization proof of a transaction, i.e. the payload of
+// the snapshot including the transaction, the cosi signature of the payload
+// hash by the consensus signers, and the payload of the transaction. Each
+// snapshot includes exactly one transaction, so no Merkle path is needed.
+type Bundle struct {
+	Snapshot    string                `json:"snapshot"`
+	Signature   *crypto.CosiSignature `json:"signature"`
+	Threshold   int                   `json:"threshold"`
+	Signers     []*crypto.Key         `json:"signers"`
+	Transaction string                `json:"transaction"`
+}
+
+// Proof is the verified result of a bundle.
+type Proof struct {
+	Snapshot    crypto.Hash
+	Transaction crypto.Hash
+	Commitment  crypto.Hash
+}
+
+func NewBundle(snapshot []byte, sig *crypto.CosiSignature, threshold int, signers []*crypto.Key, transaction []byte) *Bundle {
+	return &Bundle{
+		Snapshot:    hex.EncodeToString(snapshot),
+		Signature:   sig,
+		Threshold:   threshold,
+		Signers:     signers,
+		Transaction: hex.EncodeToString(transaction),
+	}
+}
+
+// SignerCommitment commits the consensus signers and the threshold, which
+// changes only when the consensus nodes change, so a wallet could trust a
+// commitment once and verify all bundles of the same period with it.
+func SignerCommitment(threshold int, signers []*crypto.Key) crypto.Hash {
+	buf := make([]byte, 8, 8+len(signers)*len(crypto.Key{}))
+	binary.BigEndian.PutUint64(buf, uint64(threshold))
+	for _, k := range signers {
+		buf = append(buf, k[:]...)
+	}
+	return crypto.NewHash(buf)
+}
+
+func (b *Bundle) Commitment() crypto.Hash {
+	return SignerCommitment(b.Threshold, b.Signers)
+}
+
+// Verify checks the bundle is signed by the signers of the trusted
+// commitment, and the snapshot includes the transaction.
+func (b *Bundle) Verify(commitment crypto.Hash) (*Proof, error) {
+	if c := b.Commitment(); c != commitment {
+		return nil, fmt.Errorf("invalid bundle commitment %s %s", c, commitment)
+	}
+	if b.Signature == nil || b.Threshold <= 0 {
+		return nil, fmt.Errorf("invalid bundle signature %v %d", b.Signature, b.Threshold)
+	}
+	snapshot, err := hex.DecodeString(b.Snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle snapshot %s", err)
+	}
+	transaction, err := hex.DecodeString(b.Transaction)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle transaction %s", err)
+	}
+
+	p := &Proof{
+		Snapshot:    crypto.NewHash(snapshot),
+		Transaction: crypto.NewHash(transaction),
+		Commitment:  commitment,
+	}
+	err = b.Signature.FullVerify(b.Signers, b.Threshold, p.Snapshot[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle signature %s", err)
+	}
+	marker := append(append([]byte{}, snapshotTransactionMarker...), p.Transaction[:]...)
+	if !bytes.Contains(snapshot, marker) {
+		return nil, fmt.Errorf("invalid bundle snapshot transaction %s", p.Transaction)
+	}
+	return p, nil
+}
@@ -0,0 +1,118 @@
+package light
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	assert := assert.New(t)
+
+	keys, publics := testConsensusKeys(10)
+	threshold := len(keys)*2/3 + 1
+
+	tx := common.NewTransaction(common.XINAssetId)
+	tx.AddInput(crypto.NewHash([]byte("genesis")), 0)
+	ver := tx.AsLatestVersion()
+	s := &common.Snapshot{
+		Version:     common.SnapshotVersion,
+		NodeId:      crypto.NewHash([]byte("node")),
+		Transaction: ver.PayloadHash(),
+		References:  &common.RoundLink{Self: crypto.NewHash([]byte("self")), External: crypto.NewHash([]byte("external"))},
+		RoundNumber: 7,
+		Timestamp:   1646092800000000000,
+	}
+	hash := s.PayloadHash()
+	sig := testCosiSign(assert, keys, publics, threshold, hash[:])
+
+	b := NewBundle(s.VersionedPayload(), sig, threshold, publics, ver.PayloadMarshal())
+	commitment := SignerCommitment(threshold, publics)
+	assert.Equal(commitment, b.Commitment())
+	p, err := b.Verify(commitment)
+	assert.Nil(err)
+	assert.Equal(hash, p.Snapshot)
+	assert.Equal(ver.PayloadHash(), p.Transaction)
+	assert.Equal(commitment, p.Commitment)
+
+	data, err := json.Marshal(b)
+	assert.Nil(err)
+	var decoded Bundle
+	err = json.Unmarshal(data, &decoded)
+	assert.Nil(err)
+	p, err = decoded.Verify(commitment)
+	assert.Nil(err)
+	assert.Equal(hash, p.Snapshot)
+
+	_, err = b.Verify(SignerCommitment(threshold-1, publics))
+	assert.Contains(err.Error(), "invalid bundle commitment")
+	_, err = b.Verify(SignerCommitment(threshold, publics[1:]))
+	assert.Contains(err.Error(), "invalid bundle commitment")
+
+	forged := *b
+	forged.Threshold = threshold + 1
+	_, err = forged.Verify(forged.Commitment())
+	assert.Contains(err.Error(), "invalid bundle signature")
+
+	forged = *b
+	forged.Transaction = hex.EncodeToString(append(ver.PayloadMarshal(), 0))
+	_, err = forged.Verify(commitment)
+	assert.Contains(err.Error(), "invalid bundle snapshot transaction")
+
+	other := *s
+	other.Timestamp += 1
+	forged = *b
+	forged.Snapshot = hex.EncodeToString(other.VersionedPayload())
+	_, err = forged.Verify(commitment)
+	assert.Contains(err.Error(), "invalid bundle signature")
+
+	forged = *b
+	forged.Snapshot = "invalid"
+	_, err = forged.Verify(commitment)
+	assert.Contains(err.Error(), "invalid bundle snapshot")
+	forged = *b
+	forged.Signature = nil
+	_, err = forged.Verify(commitment)
+	assert.Contains(err.Error(), "invalid bundle signature")
+}
+
+func testConsensusKeys(n int) ([]*crypto.Key, []*crypto.Key) {
+	keys := make([]*crypto.Key, n)
+	publics := make([]*crypto.Key, n)
+	for i := 0; i < n; i++ {
+		seed := crypto.NewHash([]byte(fmt.Sprintf("light%d", i)))
+		priv := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+		pub := priv.Public()
+		keys[i] = &priv
+		publics[i] = &pub
+	}
+	return keys, publics
+}
+
+func testCosiSign(assert *assert.Assertions, keys, publics []*crypto.Key, threshold int, message []byte) *crypto.CosiSignature {
+	randoms := make(map[int]*crypto.Key)
+	secrets := make(map[int]*crypto.Key)
+	for i := 0; i < threshold; i++ {
+		r := crypto.CosiCommit(rand.Reader)
+		R := r.Public()
+		secrets[i] = r
+		randoms[i] = &R
+	}
+	cosi, err := crypto.CosiAggregateCommitment(randoms)
+	assert.Nil(err)
+	responses := make(map[int]*[32]byte)
+	for i := range randoms {
+		s, err := cosi.Response(keys[i], secrets[i], publics, message)
+		assert.Nil(err)
+		responses[i] = s
+	}
+	err = cosi.AggregateResponse(publics, responses, message, true)
+	assert.Nil(err)
+	return cosi
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
+	"time"
 
+	"github.com/MixinNetwork/mixin/common"
 	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/kernel"
 	"github.com/MixinNetwork/mixin/logger"
 	"github.com/MixinNetwork/mixin/rpc"
+	"github.com/MixinNetwork/mixin/signer"
 	"github.com/MixinNetwork/mixin/storage"
 	"github.com/dgraph-io/ristretto"
 	"github.com/urfave/cli/v2"
@@ -97,10 +106,147 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "importsnapshots",
+			Usage:  "Import a signed snapshots archive to the kernel",
+			Action: importSnapshotsCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "dir",
+					Aliases: []string{"d"},
+					Usage:   "the kernel data directory",
+				},
+				&cli.StringFlag{
+					Name:    "file",
+					Aliases: []string{"f"},
+					Usage:   "the snapshots archive file",
+				},
+				&cli.IntFlag{
+					Name:    "log",
+					Aliases: []string{"l"},
+					Value:   logger.INFO,
+					Usage:   "the log level",
+				},
+				&cli.StringFlag{
+					Name:  "filter",
+					Usage: "the RE2 regex pattern to filter log",
+				},
+			},
+		},
+		{
+			Name:   "signerd",
+			Usage:  "Start the remote signer daemon holding the private spend key",
+			Action: signerdCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the private spend key of the signer",
+				},
+				&cli.StringFlag{
+					Name:  "listen",
+					Value: "unix:/run/mixin-signer.sock",
+					Usage: "the address to listen, unix:/path or tcp:host:port",
+				},
+				&cli.StringFlag{
+					Name:  "token",
+					Usage: "the hex token shared with the node signer-token",
+				},
+				&cli.IntFlag{
+					Name:    "log",
+					Aliases: []string{"l"},
+					Value:   logger.INFO,
+					Usage:   "the log level",
+				},
+				&cli.StringFlag{
+					Name:  "filter",
+					Usage: "the RE2 regex pattern to filter log",
+				},
+			},
+		},
+		{
+			Name:   "wrapsignerkey",
+			Usage:  "Wrap the private spend key by the AES key in the PKCS#11 HSM",
+			Action: wrapSignerKeyCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the private spend key of the signer",
+				},
+				&cli.StringFlag{
+					Name:  "module",
+					Usage: "the PKCS#11 module library path",
+				},
+				&cli.UintFlag{
+					Name:  "slot",
+					Usage: "the PKCS#11 slot id",
+				},
+				&cli.StringFlag{
+					Name:  "pin",
+					Usage: "the PKCS#11 user pin",
+				},
+				&cli.StringFlag{
+					Name:  "label",
+					Usage: "the label of the AES key in the HSM",
+				},
+			},
+		},
 		{
 			Name:   "setuptestnet",
 			Usage:  "Setup the test nodes and genesis",
 			Action: setupTestNetCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "round-gap",
+					Usage: "the snapshot round gap in milliseconds to shorten the rounds",
+				},
+				&cli.Uint64Flag{
+					Name:  "reference-threshold",
+					Usage: "the snapshot reference threshold to shorten the rounds",
+				},
+			},
+		},
+		{
+			Name:   "creategenesis",
+			Usage:  "Create and validate the genesis of a private network",
+			Action: createGenesisCmd,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "signer",
+					Usage: "the node signer address or private spend key",
+				},
+				&cli.StringSliceFlag{
+					Name:  "payee",
+					Usage: "the node payee address or private spend key, in the same order of signers",
+				},
+				&cli.Int64Flag{
+					Name:  "epoch",
+					Usage: "the genesis epoch in seconds, default to now",
+				},
+				&cli.Uint64Flag{
+					Name:  "round-gap",
+					Usage: "the snapshot round gap in milliseconds to shorten the rounds",
+				},
+				&cli.Uint64Flag{
+					Name:  "reference-threshold",
+					Usage: "the snapshot reference threshold to shorten the rounds",
+				},
+				&cli.StringFlag{
+					Name:  "file",
+					Usage: "the genesis.json file to write, or print it",
+				},
+			},
+		},
+		{
+			Name:   "validategenesis",
+			Usage:  "Validate the genesis file and show the network id",
+			Action: validateGenesisCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "file",
+					Value: "genesis.json",
+					Usage: "the genesis.json file to validate",
+				},
+			},
 		},
 		{
 			Name:   "createaddress",
@@ -210,6 +356,43 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "exportsnapshots",
+			Usage:  "Export the finalized snapshots as a signed archive",
+			Action: exportSnapshotsCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "from",
+					Usage: "the topology to export from",
+				},
+				&cli.Uint64Flag{
+					Name:  "to",
+					Usage: "the topology to export to, exclusive",
+				},
+				&cli.StringFlag{
+					Name:    "file",
+					Aliases: []string{"f"},
+					Usage:   "the snapshots archive file to create",
+				},
+			},
+		},
+		{
+			Name:   "bench",
+			Usage:  "Benchmark the host with the node storage settings",
+			Action: benchCmd,
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "duration",
+					Value: 3 * time.Second,
+					Usage: "the duration of each benchmark",
+				},
+				&cli.IntFlag{
+					Name:  "nodes",
+					Value: 15,
+					Usage: "the consensus nodes count to verify snapshot signatures",
+				},
+			},
+		},
 		{
 			Name:   "buildrawtransaction",
 			Usage:  "Build a script raw transaction",
@@ -258,10 +441,38 @@ func main() {
 					Name:  "key",
 					Usage: "the private key to sign the raw transaction",
 				},
+				&cli.StringFlag{
+					Name:  "view",
+					Usage: "the private view key to sign the raw transaction",
+				},
+				&cli.StringFlag{
+					Name:  "spend",
+					Usage: "the private spend key to sign the raw transaction",
+				},
+				&cli.StringFlag{
+					Name:  "mnemonic",
+					Usage: "the BIP39 mnemonic to derive the key to sign the raw transaction",
+				},
+				&cli.StringFlag{
+					Name:  "passphrase",
+					Usage: "the BIP39 passphrase of the mnemonic",
+				},
 				&cli.StringFlag{
 					Name:  "seed",
 					Usage: "the mask seed to hide the recipient public key",
 				},
+				&cli.StringSliceFlag{
+					Name:  "signed",
+					Usage: "the partially signed raw transaction to aggregate the signatures",
+				},
+				&cli.BoolFlag{
+					Name:  "partial",
+					Usage: "only sign the inputs owned by the keys for multisig",
+				},
+				&cli.BoolFlag{
+					Name:  "offline",
+					Usage: "never query the node, all the input keys must be in the raw transaction",
+				},
 			},
 		},
 		{
@@ -273,16 +484,58 @@ func main() {
 					Name:  "raw",
 					Usage: "the hex encoded signed raw transaction",
 				},
+				&cli.BoolFlag{
+					Name:  "trace",
+					Usage: "validate without broadcast and trace each check",
+				},
+			},
+		},
+		{
+			Name:   "validaterawtransaction",
+			Usage:  "Validate a hex encoded signed raw transaction without broadcast",
+			Action: validateTransactionCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "raw",
+					Usage: "the hex encoded signed raw transaction",
+				},
+			},
+		},
+		{
+			Name:   "selftestdomains",
+			Usage:  "Verify the built-in asset, address and transaction hash vectors of all domains",
+			Action: selfTestDomainsCmd,
+		},
+		{
+			Name:   "listdomainchains",
+			Usage:  "List the confirmations and instant send support of the domain chains",
+			Action: listDomainChainsCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "chain",
+					Usage: "the chain id, all chains if empty",
+				},
 			},
 		},
 		{
 			Name:   "decoderawtransaction",
-			Usage:  "Decode a raw transaction as JSON",
+			Usage:  "Decode a raw transaction as the annotated JSON",
 			Action: decodeTransactionCmd,
 			Flags: []cli.Flag{
 				&cli.StringFlag{
 					Name:  "raw",
-					Usage: "the JSON encoded raw transaction",
+					Usage: "the hex or base64 encoded raw transaction",
+				},
+			},
+		},
+		{
+			Name:   "decodesnapshot",
+			Usage:  "Decode a raw snapshot or snapshot peer message as the annotated JSON",
+			Action: decodeSnapshotCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "raw",
+					Usage: "the hex or base64 encoded snapshot",
 				},
 			},
 		},
@@ -344,6 +597,161 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "signnoderemoval",
+			Usage:  "Endorse the removal proposal of an offline node",
+			Action: signNodeRemovalCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the private signer key of the endorsing node",
+				},
+				&cli.StringFlag{
+					Name:  "accept",
+					Usage: "the accept transaction hash of the node to remove",
+				},
+			},
+		},
+		{
+			Name:   "buildnoderemovalproposal",
+			Usage:  "Build the transaction to remove an offline node",
+			Action: buildNodeRemovalProposalCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "accept",
+					Usage: "the accept transaction hash of the node to remove",
+				},
+				&cli.StringFlag{
+					Name:  "endorsements",
+					Usage: "the comma separated endorsements by signnoderemoval",
+				},
+			},
+		},
+		{
+			Name:   "signcustodianupdate",
+			Usage:  "Endorse the registration or rotation of the custodian keys",
+			Action: signCustodianUpdateCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the private signer key of the node or the current custodian",
+				},
+				&cli.UintFlag{
+					Name:  "threshold",
+					Usage: "the threshold of the new custodian keys",
+				},
+				&cli.StringFlag{
+					Name:  "keys",
+					Usage: "the comma separated new custodian public keys",
+				},
+				&cli.StringFlag{
+					Name:  "previous",
+					Usage: "the transaction of the current custodian set, empty to register",
+				},
+			},
+		},
+		{
+			Name:   "buildcustodianupdate",
+			Usage:  "Build the transaction extra to register or rotate the custodian keys",
+			Action: buildCustodianUpdateCmd,
+			Flags: []cli.Flag{
+				&cli.UintFlag{
+					Name:  "threshold",
+					Usage: "the threshold of the new custodian keys",
+				},
+				&cli.StringFlag{
+					Name:  "keys",
+					Usage: "the comma separated new custodian public keys",
+				},
+				&cli.StringFlag{
+					Name:  "previous",
+					Usage: "the transaction of the current custodian set, empty to register",
+				},
+				&cli.StringFlag{
+					Name:  "signatures",
+					Usage: "the comma separated signatures by signcustodianupdate",
+				},
+			},
+		},
+		{
+			Name:   "signgovernancesignal",
+			Usage:  "Sign the governance signal extra of a proposal vote",
+			Action: signGovernanceSignalCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the private signer key of the voting node",
+				},
+				&cli.StringFlag{
+					Name:  "proposal",
+					Usage: "the proposal hash to vote on",
+				},
+				&cli.UintFlag{
+					Name:  "weight",
+					Value: common.GovernanceSignalWeightMaximum,
+					Usage: "the vote weight in basis points, 0 to oppose",
+				},
+				&cli.StringFlag{
+					Name:  "inputs",
+					Usage: "the comma separated hash:index inputs of the voting transaction",
+				},
+			},
+		},
+		{
+			Name:   "signnodemodify",
+			Usage:  "Sign the node modify extra to rotate the payee of a node",
+			Action: signNodeModifyCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the private signer key of the accepted node",
+				},
+				&cli.StringFlag{
+					Name:  "payee",
+					Usage: "the new payee address with the view key derived from the spend key",
+				},
+				&cli.StringFlag{
+					Name:  "inputs",
+					Usage: "the comma separated hash:index inputs of the modify transaction",
+				},
+			},
+		},
+		{
+			Name:   "rotatekeys",
+			Usage:  "Rotate the payee key of a node and verify the node modify after finalization",
+			Action: rotateKeysCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "signer",
+					Usage: "the private signer key of the accepted node",
+				},
+				&cli.StringFlag{
+					Name:  "view",
+					Usage: "the private view key of the account to pay the transaction",
+				},
+				&cli.StringFlag{
+					Name:  "spend",
+					Usage: "the private spend key of the account to pay the transaction",
+				},
+				&cli.StringFlag{
+					Name:  "input",
+					Usage: "the input to spend, in the format hash:index",
+				},
+				&cli.StringFlag{
+					Name:  "amount",
+					Usage: "the input amount, which is returned to the account",
+				},
+				&cli.BoolFlag{
+					Name:  "skip-send",
+					Usage: "only build the transaction without broadcast and verification",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Value: 5 * time.Minute,
+					Usage: "the duration to wait for the transaction finalization",
+				},
+			},
+		},
 		{
 			Name:   "decodenodepledgetransaction",
 			Usage:  "Decode the extra info of a pledge transaction",
@@ -398,6 +806,23 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "graphdump",
+			Usage:  "Export the recent round graph as DOT or GraphML",
+			Action: graphDumpCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "rounds",
+					Value: 100,
+					Usage: "the recent rounds count of each node",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Value: "dot",
+					Usage: "the export format, dot or graphml",
+				},
+			},
+		},
 		{
 			Name:   "listsnapshots",
 			Usage:  "List finalized snapshots",
@@ -423,20 +848,116 @@ func main() {
 					Name:  "tx",
 					Usage: "whether including the transactions",
 				},
-			},
-		},
-		{
-			Name:   "getsnapshot",
-			Usage:  "Get the snapshot by hash",
-			Action: getSnapshotCmd,
-			Flags: []cli.Flag{
 				&cli.StringFlag{
-					Name:    "hash",
-					Aliases: []string{"x"},
-					Usage:   "the snapshot hash",
+					Name:  "node-id",
+					Usage: "only the snapshots of the node id",
 				},
-			},
-		},
+				&cli.Uint64Flag{
+					Name:  "round-start",
+					Usage: "the minimum round number",
+				},
+				&cli.Uint64Flag{
+					Name:  "round-end",
+					Usage: "the maximum round number",
+				},
+				&cli.Uint64Flag{
+					Name:  "timestamp-start",
+					Usage: "the minimum snapshot timestamp",
+				},
+				&cli.Uint64Flag{
+					Name:  "timestamp-end",
+					Usage: "the maximum snapshot timestamp",
+				},
+				&cli.BoolFlag{
+					Name:  "mint",
+					Usage: "only the snapshots of mint transactions",
+				},
+				&cli.BoolFlag{
+					Name:  "node-state",
+					Usage: "only the snapshots of node state transactions",
+				},
+			},
+		},
+		{
+			Name:   "readcursor",
+			Usage:  "List finalized snapshots from a named cursor",
+			Action: readCursorCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "the cursor name",
+				},
+				&cli.Uint64Flag{
+					Name:    "count",
+					Aliases: []string{"c"},
+					Value:   10,
+					Usage:   "the up limit of the returned snapshots",
+				},
+				&cli.BoolFlag{
+					Name:  "sig",
+					Usage: "whether including the signatures",
+				},
+				&cli.BoolFlag{
+					Name:  "tx",
+					Usage: "whether including the transactions",
+				},
+			},
+		},
+		{
+			Name:   "getcursor",
+			Usage:  "Get a named cursor",
+			Action: getCursorCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "the cursor name",
+				},
+			},
+		},
+		{
+			Name:   "setcursor",
+			Usage:  "Create or move a named cursor",
+			Action: setCursorCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "the cursor name",
+				},
+				&cli.Uint64Flag{
+					Name:  "offset",
+					Value: 0,
+					Usage: "the next topological order to read",
+				},
+			},
+		},
+		{
+			Name:   "removecursor",
+			Usage:  "Remove a named cursor",
+			Action: removeCursorCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "the cursor name",
+				},
+			},
+		},
+		{
+			Name:   "listcursors",
+			Usage:  "List all named cursors",
+			Action: listCursorsCmd,
+		},
+		{
+			Name:   "getsnapshot",
+			Usage:  "Get the snapshot by hash",
+			Action: getSnapshotCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "hash",
+					Aliases: []string{"x"},
+					Usage:   "the snapshot hash",
+				},
+			},
+		},
 		{
 			Name:   "gettransaction",
 			Usage:  "Get the finalized transaction by hash",
@@ -479,6 +1000,90 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "getfinalizationbundle",
+			Usage:  "Get the finalization proof of a transaction for light wallets",
+			Action: getFinalizationBundleCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "hash",
+					Aliases: []string{"x"},
+					Usage:   "the transaction hash",
+				},
+			},
+		},
+		{
+			Name:   "getatomicswap",
+			Usage:  "Get the atomic swap output state and the revealed secret",
+			Action: getAtomicSwapCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "hash",
+					Aliases: []string{"x"},
+					Usage:   "the transaction hash",
+				},
+				&cli.Uint64Flag{
+					Name:    "index",
+					Aliases: []string{"i"},
+					Value:   0,
+					Usage:   "the output index",
+				},
+			},
+		},
+		{
+			Name:   "signbootstraplist",
+			Usage:  "Sign the peers list to publish at an HTTPS bootstrap endpoint",
+			Action: signBootstrapListCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "network",
+					Value: config.MainnetId,
+					Usage: "the network id",
+				},
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the private key to sign the list",
+				},
+				&cli.StringFlag{
+					Name:  "peers",
+					Usage: "the comma separated peer addresses",
+				},
+			},
+		},
+		{
+			Name:   "buildatomicswapscript",
+			Usage:  "Build the hashlock script of an atomic swap output",
+			Action: buildAtomicSwapScriptCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "secret",
+					Usage: "the hex secret, a random one if both secret and hashlock empty",
+				},
+				&cli.StringFlag{
+					Name:  "hashlock",
+					Usage: "the hashlock from the counterparty",
+				},
+				&cli.Uint64Flag{
+					Name:  "expire",
+					Usage: "the expire timestamp in nanoseconds",
+				},
+				&cli.UintFlag{
+					Name:  "threshold",
+					Value: 1,
+					Usage: "the signatures threshold of the receiver keys",
+				},
+				&cli.UintFlag{
+					Name:  "refund-threshold",
+					Value: 1,
+					Usage: "the signatures threshold of the refund keys",
+				},
+				&cli.UintFlag{
+					Name:  "refund-keys",
+					Value: 1,
+					Usage: "the number of refund keys at the end of the output keys",
+				},
+			},
+		},
 		{
 			Name:   "getkey",
 			Usage:  "Get the ghost key",
@@ -491,6 +1096,87 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "listoutputsforkey",
+			Usage:  "List outputs owned by a view key and spend key",
+			Action: listOutputsForKeyCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "view",
+					Usage: "the private view key",
+				},
+				&cli.StringFlag{
+					Name:  "spend",
+					Usage: "the public spend key",
+				},
+				&cli.Uint64Flag{
+					Name:    "since",
+					Aliases: []string{"s"},
+					Value:   0,
+					Usage:   "the topological order to begin with",
+				},
+				&cli.Uint64Flag{
+					Name:    "count",
+					Aliases: []string{"c"},
+					Value:   100,
+					Usage:   "the up limit of the scanned snapshots",
+				},
+				&cli.StringFlag{
+					Name:  "filter",
+					Usage: "the filter expression, e.g. 'asset == ... && amount >= 1'",
+				},
+			},
+		},
+		{
+			Name:   "diffoutputs",
+			Usage:  "List outputs created and spent between two topological orders",
+			Action: diffOutputsCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "from",
+					Usage: "the topological order to begin with",
+				},
+				&cli.Uint64Flag{
+					Name:  "to",
+					Usage: "the topological order to end before",
+				},
+				&cli.StringFlag{
+					Name:  "asset",
+					Usage: "only the outputs of the asset",
+				},
+			},
+		},
+		{
+			Name:   "getattestation",
+			Usage:  "Get a signed attestation of an output or transaction state",
+			Action: getAttestationCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "fact",
+					Value: "output",
+					Usage: "the fact to attest, output or transaction",
+				},
+				&cli.StringFlag{
+					Name:    "hash",
+					Aliases: []string{"x"},
+					Usage:   "the transaction hash",
+				},
+				&cli.Uint64Flag{
+					Name:    "index",
+					Aliases: []string{"i"},
+					Value:   0,
+					Usage:   "the output index",
+				},
+				&cli.Uint64Flag{
+					Name:  "topology",
+					Usage: "the topological order to attest at",
+				},
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "the observer private key",
+				},
+			},
+		},
 		{
 			Name:   "listmintworks",
 			Usage:  "List mint works",
@@ -504,6 +1190,21 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "mintsimulate",
+			Usage:  "Forecast the mint distributions of future batches",
+			Action: mintSimulateCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "start",
+					Usage: "the first mint batch to simulate",
+				},
+				&cli.Uint64Flag{
+					Name:  "end",
+					Usage: "the last mint batch to simulate",
+				},
+			},
+		},
 		{
 			Name:   "listmintdistributions",
 			Usage:  "List mint distributions",
@@ -527,6 +1228,91 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "listfeestats",
+			Usage:  "List the fees burned and paid of mint batches",
+			Action: listFeeStatsCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:    "since",
+					Aliases: []string{"s"},
+					Value:   0,
+					Usage:   "the mint batch to begin with",
+				},
+				&cli.Uint64Flag{
+					Name:    "count",
+					Aliases: []string{"c"},
+					Value:   10,
+					Usage:   "the up limit of the returned batches",
+				},
+			},
+		},
+		{
+			Name:   "liststalepeers",
+			Usage:  "List the recent stale peer demotions and disconnections",
+			Action: listStalePeersCmd,
+		},
+		{
+			Name:  "peers",
+			Usage: "Export or import the signed peer address book",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "export",
+					Usage:  "Export the signed records of self and the peers learned by the discovery",
+					Action: exportPeersCmd,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "file",
+							Aliases: []string{"f"},
+							Usage:   "the file to write the hex encoded address book",
+						},
+					},
+				},
+				{
+					Name:   "import",
+					Usage:  "Import the verified records of an address book and connect the peers",
+					Action: importPeersCmd,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "file",
+							Aliases: []string{"f"},
+							Usage:   "the file of the hex encoded address book",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "getpartition",
+			Usage:  "Get the network partition state and the recent partition events",
+			Action: getPartitionCmd,
+		},
+		{
+			Name:   "listsignerstats",
+			Usage:  "List the signer participation of the recent epochs",
+			Action: listSignerStatsCmd,
+		},
+		{
+			Name:   "listcheckpoints",
+			Usage:  "List the pinned checkpoints and verify them against the local graph",
+			Action: listCheckpointsCmd,
+		},
+		{
+			Name:   "getpeergraph",
+			Usage:  "Get the signed peer connectivity graph of the node",
+			Action: getPeerGraphCmd,
+		},
+		{
+			Name:   "collectpeergraph",
+			Usage:  "Collect and verify the peer graphs of nodes into a topology view",
+			Action: collectPeerGraphCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "nodes",
+					Usage: "the comma separated RPC endpoints of the nodes sharing peer graphs",
+				},
+			},
+		},
 		{
 			Name:   "listallnodes",
 			Usage:  "List all nodes ever existed",
@@ -544,16 +1330,94 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "listconsensusnodes",
+			Usage:  "List the consensus nodes and threshold at a timestamp",
+			Action: listConsensusNodesCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "timestamp",
+					Value: 0,
+					Usage: "the timestamp in Unix nanoseconds, 0 for now",
+				},
+			},
+		},
+		{
+			Name:   "getcustodian",
+			Usage:  "Get the custodian keys active at a timestamp",
+			Action: getCustodianCmd,
+			Flags: []cli.Flag{
+				&cli.Uint64Flag{
+					Name:  "timestamp",
+					Value: 0,
+					Usage: "the timestamp in Unix nanoseconds, 0 for now",
+				},
+			},
+		},
+		{
+			Name:   "getgovernancetally",
+			Usage:  "Get the tally of the governance signals on a proposal",
+			Action: getGovernanceTallyCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "proposal",
+					Usage: "the proposal hash",
+				},
+			},
+		},
+		{
+			Name:   "listnodemodifications",
+			Usage:  "List the node payee modifications",
+			Action: listNodeModificationsCmd,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "pending",
+					Value: true,
+					Usage: "list only the modifications not effective yet",
+				},
+			},
+		},
 		{
 			Name:   "getinfo",
 			Usage:  "Get info from the node",
 			Action: getInfoCmd,
 		},
+		{
+			Name:   "gethealth",
+			Usage:  "Get the sync and readiness health of the node",
+			Action: getHealthCmd,
+		},
+		{
+			Name:   "getnodestatus",
+			Usage:  "Get the aggregated consensus, storage, network and domains status",
+			Action: getNodeStatusCmd,
+		},
+		{
+			Name:   "storageinfo",
+			Usage:  "Get the storage tuning settings and the LSM and value log sizes",
+			Action: getStorageInfoCmd,
+		},
+		{
+			Name:   "getupgradereadiness",
+			Usage:  "Get the network readiness of upgrade intents",
+			Action: getUpgradeReadinessCmd,
+		},
 		{
 			Name:   "dumpgraphhead",
 			Usage:  "Dump the graph head",
 			Action: dumpGraphHeadCmd,
 		},
+		{
+			Name:   "describeschema",
+			Usage:  "Describe the JSON schemas of the snapshot, transaction, round and RPC envelopes",
+			Action: describeSchemaCmd,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "the directory to write each schema as a file for the client code generators",
+				},
+			},
+		},
 	}
 	err := app.Run(os.Args)
 	if err != nil {
@@ -601,6 +1465,92 @@ func cloneCmd(c *cli.Context) error {
 	return node.Import(c.String("dir"), source)
 }
 
+func importSnapshotsCmd(c *cli.Context) error {
+	runtime.GOMAXPROCS(runtime.NumCPU())
+
+	logger.SetLevel(c.Int("log"))
+	err := logger.SetFilter(c.String("filter"))
+	if err != nil {
+		return err
+	}
+	custom, err := config.Initialize(c.String("dir") + "/config.toml")
+	if err != nil {
+		return err
+	}
+
+	cache, err := newCache(custom)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewBadgerStore(custom, c.String("dir"))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	file, err := os.Open(c.String("file"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	node, err := kernel.SetupNode(custom, store, cache, ":12345", c.String("dir"))
+	if err != nil {
+		return err
+	}
+
+	count, signer, err := node.ImportSnapshots(file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported snapshots: %d, signed by %s\n", count, signer)
+	return nil
+}
+
+func signerdCmd(c *cli.Context) error {
+	logger.SetLevel(c.Int("log"))
+	err := logger.SetFilter(c.String("filter"))
+	if err != nil {
+		return err
+	}
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	token, err := hex.DecodeString(c.String("token"))
+	if err != nil {
+		return err
+	}
+	server, err := signer.NewServer(key, token)
+	if err != nil {
+		return err
+	}
+	l, err := signer.Listen(c.String("listen"))
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	logger.Printf("Signer:\t%s\n", key.Public())
+	logger.Printf("Listen:\t%s\n", c.String("listen"))
+	return server.Serve(l)
+}
+
+func wrapSignerKeyCmd(c *cli.Context) error {
+	key, err := crypto.KeyFromString(c.String("key"))
+	if err != nil {
+		return err
+	}
+	wrapped, err := signer.WrapPKCS11Key(c.String("module"), c.Uint("slot"), c.String("pin"), c.String("label"), key)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signer-public:\t%s\n", key.Public())
+	fmt.Printf("signer-pkcs11-wrapped:\t%s\n", hex.EncodeToString(wrapped))
+	return nil
+}
+
 func kernelCmd(c *cli.Context) error {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
@@ -632,7 +1582,7 @@ func kernelCmd(c *cli.Context) error {
 	}
 
 	go func() {
-		server := rpc.NewServer(custom, store, node, c.Int("port")+1000)
+		server := rpc.NewServer(custom, store, node, domainSelfTestResults(), c.Int("port")+1000)
 		err := server.ListenAndServe()
 		if err != nil {
 			panic(err)
@@ -643,7 +1593,35 @@ func kernelCmd(c *cli.Context) error {
 		go http.ListenAndServe(fmt.Sprintf(":%d", c.Int("port")+2000), http.DefaultServeMux)
 	}
 
-	return node.Loop()
+	if custom.RPC.GRPC {
+		go func() {
+			lis, err := net.Listen("tcp", fmt.Sprintf(":%d", c.Int("port")+3000))
+			if err != nil {
+				panic(err)
+			}
+			err = rpc.NewGRPCServer(store, node).Serve(lis)
+			if err != nil {
+				panic(err)
+			}
+		}()
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		logger.Printf("Stopping node %s...\n", node.IdForNetwork)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		stopped <- node.Stop(ctx)
+	}()
+
+	err = node.Loop()
+	if err != nil {
+		return err
+	}
+	return <-stopped
 }
 
 func newCache(conf *config.Custom) (*ristretto.Cache, error) {
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//go:embed data/bip39-english.txt
+var bip39English string
+
+var bip39Words = make(map[string]int64)
+
+func init() {
+	for i, w := range strings.Fields(bip39English) {
+		bip39Words[w] = int64(i)
+	}
+}
+
+// mnemonicSeed validates the words and checksum of the BIP39 English
+// mnemonic, and returns the seed derived with the passphrase, so a mistyped
+// mnemonic never silently derives a different account.
+func mnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	n := len(words)
+	if n < 12 || n > 24 || n%3 != 0 {
+		return nil, fmt.Errorf("invalid mnemonic words count %d", n)
+	}
+
+	bits := new(big.Int)
+	for _, w := range words {
+		i, found := bip39Words[w]
+		if !found {
+			return nil, fmt.Errorf("invalid mnemonic word %s", w)
+		}
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(i))
+	}
+	cs := uint(n * 11 / 33)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<cs-1)).Uint64()
+	entropy := new(big.Int).Rsh(bits, cs).FillBytes(make([]byte, n*4/3))
+	sum := sha256.Sum256(entropy)
+	if uint64(sum[0]>>(8-cs)) != checksum {
+		return nil, fmt.Errorf("invalid mnemonic checksum")
+	}
+
+	mnemonic = strings.Join(words, " ")
+	salt := "mnemonic" + passphrase
+	return pbkdf2.Key([]byte(mnemonic), []byte(salt), 2048, 64, sha512.New), nil
+}
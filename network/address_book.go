@@ -0,0 +1,120 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	addressBookEntriesLimit = 1024
+	addressBookPingRate     = 1
+	addressBookPingBurst    = 16
+)
+
+// AddressBookEntry is a peer record with the time it was last seen by the
+// exporting node, the id and listener are only informative, and the record
+// signed by the peer signer key is verified again on import.
+type AddressBookEntry struct {
+	Id       crypto.Hash
+	Listener string
+	Record   []byte
+	Seen     uint64
+}
+
+func EncodeAddressBook(entries []*AddressBookEntry) []byte {
+	return common.MsgpackMarshalPanic(entries)
+}
+
+func DecodeAddressBook(data []byte) ([]*AddressBookEntry, error) {
+	var entries []*AddressBookEntry
+	err := common.MsgpackUnmarshal(data, &entries)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > addressBookEntriesLimit {
+		return nil, fmt.Errorf("address book too large %d", len(entries))
+	}
+	for _, e := range entries {
+		if e == nil || len(e.Record) == 0 {
+			return nil, fmt.Errorf("address book invalid entry")
+		}
+	}
+	return entries, nil
+}
+
+// ExportAddressBook returns the freshly signed record of self, unless self
+// is not a consensus node, and the unexpired records learned by the discovery,
+// so a new node could be seeded with the peers vetted by this node.
+func (me *Peer) ExportAddressBook() []*AddressBookEntry {
+	var entries []*AddressBookEntry
+	record := me.handle.BuildPeerRecord()
+	id, listener, err := me.handle.VerifyPeerRecord(record)
+	if err == nil && id == me.IdForNetwork {
+		entries = append(entries, &AddressBookEntry{
+			Id:       id,
+			Listener: listener,
+			Record:   record,
+			Seen:     uint64(time.Now().UnixNano()),
+		})
+	}
+	records := me.routes.closest(me.IdForNetwork, len(me.routes.buckets)*dhtBucketSize)
+	for _, r := range records {
+		entries = append(entries, &AddressBookEntry{
+			Id:       r.id,
+			Listener: r.listener,
+			Record:   r.data,
+			Seen:     uint64(r.seen.UnixNano()),
+		})
+	}
+	return entries
+}
+
+// ImportAddressBook verifies the records of the entries and adds them to the
+// routing table, then pings the peers not connected yet, at most the ping
+// rate, and the others are left to the discovery. The seen time of an entry
+// is kept, and an entry seen earlier than the known record of the peer is
+// skipped. An entry without seen time or seen in the future only adds the
+// peer not known yet as seen now, otherwise it would refresh the record on
+// every import. It returns the count of the imported.
+func (me *Peer) ImportAddressBook(entries []*AddressBookEntry) int {
+	var imported int
+	now := time.Now()
+	for _, e := range entries {
+		id, listener, err := me.handle.VerifyPeerRecord(e.Record)
+		if err != nil {
+			logger.Verbosef("ImportAddressBook VerifyPeerRecord %s ERROR %s\n", e.Id, err)
+			continue
+		}
+		if id != e.Id || listener != e.Listener {
+			logger.Verbosef("ImportAddressBook %s %s mismatch %s %s\n", e.Id, e.Listener, id, listener)
+			continue
+		}
+		if id == me.IdForNetwork {
+			continue
+		}
+		seen := time.Unix(0, int64(e.Seen))
+		r := me.routes.get(id)
+		if e.Seen == 0 || seen.After(now) {
+			if r != nil {
+				continue
+			}
+			seen = now
+		}
+		if r != nil && !seen.After(r.seen) {
+			continue
+		}
+		me.routes.update(&dhtRecord{id: id, listener: listener, data: e.Record, seen: seen})
+		if me.routes.get(id) == nil {
+			continue
+		}
+		imported += 1
+		if me.neighbors.Get(id) == nil && me.addressBookPings.Allow("ping") {
+			me.PingNeighbor(listener)
+		}
+	}
+	return imported
+}
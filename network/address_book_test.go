@@ -0,0 +1,106 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/ristretto"
+	"github.com/stretchr/testify/assert"
+)
+
+type addressBookRecord struct {
+	Id       crypto.Hash
+	Listener string
+}
+
+type addressBookHandle struct {
+	SyncHandle
+	id       crypto.Hash
+	listener string
+	revoked  map[crypto.Hash]bool
+}
+
+func (h *addressBookHandle) GetCacheStore() *ristretto.Cache {
+	return nil
+}
+
+func (h *addressBookHandle) BuildPeerRecord() []byte {
+	return common.MsgpackMarshalPanic(&addressBookRecord{h.id, h.listener})
+}
+
+func (h *addressBookHandle) VerifyPeerRecord(msg []byte) (crypto.Hash, string, error) {
+	var r addressBookRecord
+	err := common.MsgpackUnmarshal(msg, &r)
+	if err != nil {
+		return crypto.Hash{}, "", err
+	}
+	if h.revoked[r.Id] {
+		return crypto.Hash{}, "", errors.New("revoked")
+	}
+	return r.Id, r.Listener, nil
+}
+
+func TestAddressBook(t *testing.T) {
+	assert := assert.New(t)
+
+	a := &addressBookHandle{id: crypto.Hash{0x01}, listener: "127.0.0.1:7001"}
+	b := &addressBookHandle{id: crypto.Hash{0x80}, listener: "127.0.0.1:7002"}
+	c := &addressBookHandle{id: crypto.Hash{0x40}, listener: "127.0.0.1:7003"}
+	pa := NewPeer(a, a.id, a.listener, false)
+	pb := NewPeer(b, b.id, b.listener, false)
+
+	seen := time.Now().Add(-time.Hour)
+	pa.routes.update(&dhtRecord{id: c.id, listener: c.listener, data: c.BuildPeerRecord(), seen: seen})
+	entries := pa.ExportAddressBook()
+	assert.Len(entries, 2)
+	assert.Equal(a.id, entries[0].Id)
+	assert.Equal(a.listener, entries[0].Listener)
+	assert.Equal(c.id, entries[1].Id)
+	assert.Equal(uint64(seen.UnixNano()), entries[1].Seen)
+
+	book := EncodeAddressBook(append(entries, &AddressBookEntry{
+		Id:       b.id,
+		Listener: b.listener,
+		Record:   b.BuildPeerRecord(),
+	}))
+	decoded, err := DecodeAddressBook(book)
+	assert.Nil(err)
+	assert.Len(decoded, 3)
+	_, err = DecodeAddressBook(EncodeAddressBook([]*AddressBookEntry{{Id: a.id}}))
+	assert.NotNil(err)
+
+	for _, e := range decoded {
+		pb.neighbors.Set(e.Id, &Peer{})
+	}
+	assert.Equal(2, pb.ImportAddressBook(decoded))
+	assert.Equal(a.listener, pb.routes.get(a.id).listener)
+	assert.Equal(seen.UnixNano(), pb.routes.get(c.id).seen.UnixNano())
+	assert.Nil(pb.routes.get(b.id))
+	assert.Equal(0, pb.ImportAddressBook(decoded))
+
+	decoded[1].Seen = uint64(time.Now().Add(time.Hour).UnixNano())
+	assert.Equal(0, pb.ImportAddressBook(decoded))
+	assert.Equal(seen.UnixNano(), pb.routes.get(c.id).seen.UnixNano())
+	pd := NewPeer(b, crypto.Hash{0x20}, "127.0.0.1:7004", false)
+	for _, e := range decoded {
+		pd.neighbors.Set(e.Id, &Peer{})
+	}
+	assert.Equal(3, pd.ImportAddressBook(decoded))
+	assert.False(pd.routes.get(c.id).seen.After(time.Now()))
+	assert.True(pd.routes.get(c.id).seen.After(seen))
+	assert.Equal(0, pd.ImportAddressBook(decoded))
+
+	decoded[1].Seen = uint64(time.Now().Add(-time.Minute).UnixNano())
+	assert.Equal(1, pb.ImportAddressBook(decoded))
+	assert.Equal(int64(decoded[1].Seen), pb.routes.get(c.id).seen.UnixNano())
+
+	decoded[0].Listener = "127.0.0.1:7100"
+	decoded[0].Seen = 0
+	assert.Equal(0, pb.ImportAddressBook(decoded))
+	b.revoked = map[crypto.Hash]bool{c.id: true}
+	decoded[1].Seen = uint64(time.Now().UnixNano())
+	assert.Equal(0, pb.ImportAddressBook(decoded))
+}
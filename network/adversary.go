@@ -0,0 +1,114 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// Adversary is a byzantine peer for integration tests and test networks.
+// It authenticates like a kernel node, then sends the crafted messages
+// that the cosi handlers must reject or tolerate.
+type Adversary struct {
+	signer   crypto.Key
+	listener string
+	client   Client
+}
+
+func NewAdversary(signer crypto.Key, listener string) *Adversary {
+	return &Adversary{signer: signer, listener: listener}
+}
+
+func (a *Adversary) Connect(ctx context.Context, addr string) error {
+	transport, err := NewQuicClient(addr)
+	if err != nil {
+		return err
+	}
+	client, err := transport.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	a.client = client
+	return a.SendRaw(buildAuthenticationMessage(a.buildAuthentication()))
+}
+
+func (a *Adversary) Close() error {
+	if a.client == nil {
+		return nil
+	}
+	return a.client.Close()
+}
+
+func (a *Adversary) SendRaw(data []byte) error {
+	if a.client == nil {
+		return fmt.Errorf("adversary not connected")
+	}
+	return a.client.Send(data)
+}
+
+// SendMalformedCommitment sends a commitment truncated to an invalid size,
+// and a well sized one whose commitment is garbage bytes.
+func (a *Adversary) SendMalformedCommitment(snap crypto.Hash) error {
+	var R crypto.Key
+	msg := buildSnapshotCommitmentMessage(snap, R, false)
+	err := a.SendRaw(msg[:len(msg)-1])
+	if err != nil {
+		return err
+	}
+	for i := range R {
+		R[i] = 0xff
+	}
+	return a.SendRaw(buildSnapshotCommitmentMessage(snap, R, false))
+}
+
+// SendEquivocatingSnapshots announces two different snapshots for the same
+// round and references, the second one with the transaction replaced.
+func (a *Adversary) SendEquivocatingSnapshots(s *common.Snapshot, tx crypto.Hash) ([]*common.Snapshot, error) {
+	first := *s
+	second := *s
+	second.Transaction = tx
+	snapshots := []*common.Snapshot{&first, &second}
+	for _, s := range snapshots {
+		s.Hash = s.PayloadHash()
+		err := a.SendRaw(buildSnapshotAnnouncementMessage(s, a.randomCommitment()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return snapshots, nil
+}
+
+// SendStaleReference announces the snapshot with the references replaced
+// by some previous round link.
+func (a *Adversary) SendStaleReference(s *common.Snapshot, stale *common.RoundLink) (*common.Snapshot, error) {
+	ss := *s
+	ss.References = stale
+	ss.Hash = ss.PayloadHash()
+	err := a.SendRaw(buildSnapshotAnnouncementMessage(&ss, a.randomCommitment()))
+	return &ss, err
+}
+
+// ReplayResponse sends a previously captured response again, for the same
+// snapshot or for any other one.
+func (a *Adversary) ReplayResponse(snap crypto.Hash, si [32]byte) error {
+	return a.SendRaw(buildSnapshotResponseMessage(snap, &si))
+}
+
+func (a *Adversary) randomCommitment() crypto.Key {
+	return crypto.CosiCommit(rand.Reader).Public()
+}
+
+func (a *Adversary) buildAuthentication() []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(time.Now().Unix()))
+	pub := a.signer.Public()
+	data = append(data, pub[:]...)
+	sig := a.signer.Sign(data)
+	data = append(data, sig[:]...)
+	return append(data, []byte(a.listener)...)
+}
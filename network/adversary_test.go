@@ -0,0 +1,108 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdversary(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := "127.0.0.1:7001"
+	serverTrans, err := NewQuicServer(addr)
+	assert.Nil(err)
+	defer serverTrans.Close()
+	err = serverTrans.Listen()
+	assert.Nil(err)
+
+	received := make(chan *PeerMessage, 16)
+	failures := make(chan error, 16)
+	go func() {
+		server, err := serverTrans.Accept(context.Background())
+		assert.Nil(err)
+		for {
+			tm, err := server.Receive()
+			if err != nil {
+				return
+			}
+			msg, err := parseNetworkMessage(tm.Version, tm.Data)
+			if err != nil {
+				failures <- err
+				continue
+			}
+			received <- msg
+		}
+	}()
+
+	signer := crypto.NewKeyFromSeed(make([]byte, 64))
+	adv := NewAdversary(signer, "127.0.0.1:7002")
+	err = adv.Connect(context.Background(), addr)
+	assert.Nil(err)
+	defer adv.Close()
+
+	msg := <-received
+	assert.Equal(uint8(PeerMessageTypeAuthentication), msg.Type)
+	pub := signer.Public()
+	assert.Equal(pub[:], msg.Auth[8:40])
+	assert.Equal("127.0.0.1:7002", string(msg.Auth[104:]))
+
+	snap := crypto.NewHash([]byte("adversary-snapshot"))
+	err = adv.SendMalformedCommitment(snap)
+	assert.Nil(err)
+	err = <-failures
+	assert.Equal("invalid commitment message size 64", err.Error())
+	msg = <-received
+	assert.Equal(uint8(PeerMessageTypeSnapshotCommitment), msg.Type)
+	assert.Equal(snap, msg.SnapshotHash)
+	for _, b := range msg.Commitment {
+		assert.Equal(byte(0xff), b)
+	}
+
+	s := &common.Snapshot{
+		Version:     common.SnapshotVersion,
+		NodeId:      crypto.NewHash([]byte("adversary-node")),
+		Transaction: crypto.NewHash([]byte("adversary-transaction")),
+		References: &common.RoundLink{
+			Self:     crypto.NewHash([]byte("adversary-self")),
+			External: crypto.NewHash([]byte("adversary-external")),
+		},
+		RoundNumber: 7,
+		Timestamp:   1551312000000000000,
+	}
+	snapshots, err := adv.SendEquivocatingSnapshots(s, crypto.NewHash([]byte("adversary-double")))
+	assert.Nil(err)
+	assert.Len(snapshots, 2)
+	assert.NotEqual(snapshots[0].Hash, snapshots[1].Hash)
+	for _, ss := range snapshots {
+		msg = <-received
+		assert.Equal(uint8(PeerMessageTypeSnapshotAnnoucement), msg.Type)
+		assert.Equal(ss.Hash, msg.Snapshot.PayloadHash())
+		assert.Equal(s.RoundNumber, msg.Snapshot.RoundNumber)
+		assert.True(msg.Commitment.CheckKey())
+	}
+
+	stale := &common.RoundLink{Self: crypto.NewHash([]byte("stale-self")), External: s.References.External}
+	ss, err := adv.SendStaleReference(s, stale)
+	assert.Nil(err)
+	msg = <-received
+	assert.Equal(uint8(PeerMessageTypeSnapshotAnnoucement), msg.Type)
+	assert.Equal(ss.Hash, msg.Snapshot.PayloadHash())
+	assert.True(msg.Snapshot.References.Equal(stale))
+
+	var si [32]byte
+	si[0] = 1
+	err = adv.ReplayResponse(snap, si)
+	assert.Nil(err)
+	err = adv.ReplayResponse(snapshots[1].Hash, si)
+	assert.Nil(err)
+	for _, h := range []crypto.Hash{snap, snapshots[1].Hash} {
+		msg = <-received
+		assert.Equal(uint8(PeerMessageTypeSnapshotResponse), msg.Type)
+		assert.Equal(h, msg.SnapshotHash)
+		assert.Equal(si, msg.Response)
+	}
+}
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	snapshotBloomBits     = 1 << 17
+	snapshotBloomHashes   = 4
+	snapshotBloomCapacity = 8192
+)
+
+type GossipFilterStats struct {
+	Checked uint64
+	Skipped uint64
+}
+
+type bloomFilter [snapshotBloomBits / 64]uint64
+
+// the snapshot hashes are uniform already, so the bit positions are derived
+// from the hash itself with double hashing, no extra digest needed
+func (b *bloomFilter) positions(h crypto.Hash) [snapshotBloomHashes]uint64 {
+	var pos [snapshotBloomHashes]uint64
+	h1 := binary.LittleEndian.Uint64(h[:8])
+	h2 := binary.LittleEndian.Uint64(h[8:16]) | 1
+	for i := range pos {
+		pos[i] = (h1 + uint64(i)*h2) % snapshotBloomBits
+	}
+	return pos
+}
+
+func (b *bloomFilter) add(h crypto.Hash) {
+	for _, p := range b.positions(h) {
+		b[p/64] |= 1 << (p % 64)
+	}
+}
+
+func (b *bloomFilter) contains(h crypto.Hash) bool {
+	for _, p := range b.positions(h) {
+		if b[p/64]&(1<<(p%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// snapshotBloom records the snapshots confirmed by a neighbor in two bloom
+// filter generations, the current one is retired to the previous one when
+// it's full, so the memory is bounded to two filters per neighbor and the
+// recent confirmations are always remembered. With the capacity the false
+// positive rate is about half percent, and a positive is confirmed by the
+// exact confirmations cache before skipping the snapshot.
+type snapshotBloom struct {
+	sync.RWMutex
+	current  *bloomFilter
+	previous *bloomFilter
+	count    int
+}
+
+func newSnapshotBloom() *snapshotBloom {
+	return &snapshotBloom{
+		current:  new(bloomFilter),
+		previous: new(bloomFilter),
+	}
+}
+
+func (s *snapshotBloom) add(h crypto.Hash) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.count >= snapshotBloomCapacity {
+		s.previous, s.current = s.current, s.previous
+		*s.current = bloomFilter{}
+		s.count = 0
+	}
+	s.current.add(h)
+	s.count++
+}
+
+func (s *snapshotBloom) contains(h crypto.Hash) bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.current.contains(h) || s.previous.contains(h)
+}
+
+type gossipFilterCounter struct {
+	checked uint64
+	skipped uint64
+}
+
+func (c *gossipFilterCounter) check(skip bool) bool {
+	atomic.AddUint64(&c.checked, 1)
+	if skip {
+		atomic.AddUint64(&c.skipped, 1)
+	}
+	return skip
+}
+
+// GossipFilterStats reports the snapshot finalization messages checked
+// against the neighbors confirmations, and those skipped as duplicated.
+func (me *Peer) GossipFilterStats() GossipFilterStats {
+	return GossipFilterStats{
+		Checked: atomic.LoadUint64(&me.gossipFilter.checked),
+		Skipped: atomic.LoadUint64(&me.gossipFilter.skipped),
+	}
+}
@@ -0,0 +1,75 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/ristretto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotBloom(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newSnapshotBloom()
+	h := crypto.NewHash([]byte("snapshot"))
+	assert.False(b.contains(h))
+	b.add(h)
+	assert.True(b.contains(h))
+
+	for i := 0; i < snapshotBloomCapacity; i++ {
+		b.add(crypto.NewHash([]byte{byte(i), byte(i >> 8), 'A'}))
+	}
+	assert.True(b.contains(h))
+	for i := 0; i < snapshotBloomCapacity; i++ {
+		b.add(crypto.NewHash([]byte{byte(i), byte(i >> 8), 'B'}))
+	}
+	assert.False(b.contains(h))
+
+	positives := 0
+	for i := 0; i < 10000; i++ {
+		if b.contains(crypto.NewHash([]byte{byte(i), byte(i >> 8), 'C'})) {
+			positives++
+		}
+	}
+	assert.Less(positives, 100)
+}
+
+func TestGossipFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: 1e4,
+		MaxCost:     1 << 20,
+		BufferItems: 64,
+	})
+	assert.Nil(err)
+	id := crypto.NewHash([]byte("me"))
+	me := newPeer(nil, id, "127.0.0.1:7001", false, newSendQueue(16, config.OverflowDropNew))
+	me.snapshotsCaches = &confirmMap{cache: cache}
+	nid := crypto.NewHash([]byte("neighbor"))
+	peer := newPeer(nil, nid, "127.0.0.1:7002", false, me.queue)
+	me.neighbors.Set(nid, peer)
+
+	snap := crypto.NewHash([]byte("snapshot"))
+	unknown := crypto.NewHash([]byte("unknown"))
+	me.ConfirmSnapshotForPeer(unknown, snap)
+	assert.False(peer.confirmed.contains(snap))
+	me.ConfirmSnapshotForPeer(nid, snap)
+	assert.True(peer.confirmed.contains(snap))
+	cache.Wait()
+	assert.True(me.snapshotConfirmedByPeer(unknown, snap))
+	assert.True(me.snapshotConfirmedByPeer(nid, snap))
+
+	other := crypto.NewHash([]byte("other"))
+	peer.confirmed.add(other)
+	assert.False(me.snapshotConfirmedByPeer(nid, other))
+	assert.False(me.snapshotConfirmedByPeer(nid, id))
+
+	assert.True(me.gossipFilter.check(me.snapshotConfirmedByPeer(nid, snap)))
+	assert.False(me.gossipFilter.check(me.snapshotConfirmedByPeer(nid, other)))
+	stats := me.GossipFilterStats()
+	assert.Equal(uint64(2), stats.Checked)
+	assert.Equal(uint64(1), stats.Skipped)
+}
@@ -0,0 +1,33 @@
+package network
+
+import "sync"
+
+// The transport frames and the compressed payloads are only owned until the
+// stream write or the decompression returns, so their buffers are pooled.
+// The built peer messages are queued and cached by the peers, so they are
+// allocated once with the exact size instead.
+
+const bufferPoolMaxSize = 1024 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 4096)
+		return &b
+	},
+}
+
+func getBuffer(size int) *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	if cap(*b) < size {
+		*b = make([]byte, 0, size)
+	}
+	return b
+}
+
+func putBuffer(b *[]byte) {
+	if cap(*b) > bufferPoolMaxSize {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
@@ -0,0 +1,154 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	assert := assert.New(t)
+
+	b := getBuffer(16)
+	assert.Len(*b, 0)
+	assert.GreaterOrEqual(cap(*b), 16)
+	*b = append(*b, 1, 2, 3)
+	putBuffer(b)
+
+	b = getBuffer(bufferPoolMaxSize * 2)
+	assert.Len(*b, 0)
+	assert.GreaterOrEqual(cap(*b), bufferPoolMaxSize*2)
+	putBuffer(b)
+
+	snap := crypto.NewHash([]byte("buffer-snapshot"))
+	var R crypto.Key
+	msg := buildSnapshotCommitmentMessage(snap, R, true)
+	assert.Len(msg, 66)
+	assert.Equal(66, cap(msg))
+	pm, err := parseNetworkMessage(TransportMessageVersion, msg)
+	assert.Nil(err)
+	assert.Equal(snap, pm.SnapshotHash)
+	assert.True(pm.WantTx)
+}
+
+func BenchmarkBuildSnapshotAnnouncementMessage(b *testing.B) {
+	s := benchmarkSnapshot()
+	R := crypto.NewKeyFromSeed(make([]byte, 64)).Public()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildSnapshotAnnouncementMessage(s, R)
+	}
+}
+
+func BenchmarkBuildSnapshotCommitmentMessage(b *testing.B) {
+	snap := crypto.NewHash([]byte("benchmark-snapshot"))
+	R := crypto.NewKeyFromSeed(make([]byte, 64)).Public()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildSnapshotCommitmentMessage(snap, R, true)
+	}
+}
+
+func BenchmarkParseSnapshotAnnouncementMessage(b *testing.B) {
+	R := crypto.NewKeyFromSeed(make([]byte, 64)).Public()
+	msg := buildSnapshotAnnouncementMessage(benchmarkSnapshot(), R)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := parseNetworkMessage(TransportMessageVersion, msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseSnapshotFinalizationMessage parses the finalization and
+// hashes the snapshot as many times as the finalization path does.
+func BenchmarkParseSnapshotFinalizationMessage(b *testing.B) {
+	s := benchmarkSnapshot()
+	s.Hash = s.PayloadHash()
+	msg := buildSnapshotFinalizationMessage(s)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pm, err := parseNetworkMessage(TransportMessageVersion, msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for j := 0; j < 4; j++ {
+			if pm.Snapshot.PayloadHash() != s.Hash {
+				b.Fatal(pm.Snapshot.PayloadHash())
+			}
+		}
+	}
+}
+
+func BenchmarkQuicSendReceive(b *testing.B) {
+	addr := "127.0.0.1:7003"
+	serverTrans, err := NewQuicServer(addr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = serverTrans.Listen()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer serverTrans.Close()
+
+	R := crypto.NewKeyFromSeed(make([]byte, 64)).Public()
+	msg := buildSnapshotAnnouncementMessage(benchmarkSnapshot(), R)
+	done := make(chan error)
+	go func() {
+		server, err := serverTrans.Accept(context.Background())
+		if err != nil {
+			done <- err
+			return
+		}
+		for i := 0; i < b.N; i++ {
+			_, err = server.Receive()
+			if err != nil {
+				break
+			}
+		}
+		done <- err
+	}()
+
+	clientTrans, _ := NewQuicClient(addr)
+	client, err := clientTrans.Dial(context.Background())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer client.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = client.Send(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	err = <-done
+	if err != nil {
+		b.Fatal(err)
+	}
+}
+
+func benchmarkSnapshot() *common.Snapshot {
+	return &common.Snapshot{
+		Version:     common.SnapshotVersion,
+		NodeId:      crypto.NewHash([]byte("benchmark-node")),
+		Transaction: crypto.NewHash([]byte("benchmark-transaction")),
+		References: &common.RoundLink{
+			Self:     crypto.NewHash([]byte("benchmark-self")),
+			External: crypto.NewHash([]byte("benchmark-external")),
+		},
+		RoundNumber: 7,
+		Timestamp:   1551312000000000000,
+	}
+}
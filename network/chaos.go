@@ -0,0 +1,41 @@
+package network
+
+import (
+	"math/rand"
+	"time"
+)
+
+// chaos disrupts the outbound messages of the neighbor streams with random
+// delays and drops, to rehearse the kernel recovery on the test networks.
+// A dropped message is treated as sent, just like lost on the wire.
+type chaos struct {
+	delay time.Duration
+	drop  int
+}
+
+// SetChaos delays each outbound message randomly up to delay, and drops the
+// drop percentage of them, which must only be enabled on test networks.
+func (me *Peer) SetChaos(delay time.Duration, drop int) {
+	if delay <= 0 && drop <= 0 {
+		me.chaos = nil
+		return
+	}
+	me.chaos = &chaos{delay: delay, drop: drop}
+}
+
+func (c *chaos) disrupt() bool {
+	if c == nil {
+		return false
+	}
+	if c.delay > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(c.delay))))
+	}
+	return c.drop > 0 && rand.Intn(100) < c.drop
+}
+
+func (me *Peer) sendWithChaos(client Client, data []byte) error {
+	if me.chaos.disrupt() {
+		return nil
+	}
+	return client.Send(data)
+}
@@ -0,0 +1,30 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaos(t *testing.T) {
+	assert := assert.New(t)
+
+	me := &Peer{}
+	assert.False(me.chaos.disrupt())
+	me.SetChaos(0, 0)
+	assert.Nil(me.chaos)
+
+	me.SetChaos(0, 100)
+	assert.NotNil(me.chaos)
+	for i := 0; i < 10; i++ {
+		assert.True(me.chaos.disrupt())
+	}
+
+	me.SetChaos(20*time.Millisecond, 0)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		assert.False(me.chaos.disrupt())
+	}
+	assert.Less(time.Since(start), 200*time.Millisecond)
+}
@@ -0,0 +1,212 @@
+package network
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	dhtBucketSize       = 8
+	dhtAlpha            = 3
+	dhtRecordExpiration = 24 * time.Hour
+)
+
+// dhtRecord is a peer network address signed by the peer signer key, the
+// signed data is forwarded as is so that every node verifies it again.
+type dhtRecord struct {
+	id       crypto.Hash
+	listener string
+	data     []byte
+	seen     time.Time
+}
+
+// routingTable is a Kademlia routing table keyed by IdForNetwork, the
+// bucket i holds the records whose XOR distance to self has i leading
+// zero bits.
+type routingTable struct {
+	sync.RWMutex
+	self    crypto.Hash
+	buckets [len(crypto.Hash{}) * 8][]*dhtRecord
+}
+
+func newRoutingTable(self crypto.Hash) *routingTable {
+	return &routingTable{self: self}
+}
+
+func dhtDistance(a, b crypto.Hash) crypto.Hash {
+	var d crypto.Hash
+	for i := range d {
+		d[i] = a[i] ^ b[i]
+	}
+	return d
+}
+
+func dhtBucketIndex(self, id crypto.Hash) int {
+	d := dhtDistance(self, id)
+	for i, b := range d {
+		if b != 0 {
+			return i*8 + bits.LeadingZeros8(b)
+		}
+	}
+	return -1
+}
+
+// update inserts or refreshes the record, a full bucket only accepts it
+// when its least recently seen record has expired.
+func (t *routingTable) update(r *dhtRecord) {
+	i := dhtBucketIndex(t.self, r.id)
+	if i < 0 {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	bucket := t.buckets[i]
+	for j, o := range bucket {
+		if o.id == r.id {
+			bucket = append(bucket[:j], bucket[j+1:]...)
+			break
+		}
+	}
+	if len(bucket) >= dhtBucketSize {
+		if time.Since(bucket[0].seen) < dhtRecordExpiration {
+			return
+		}
+		bucket = bucket[1:]
+	}
+	t.buckets[i] = append(bucket, r)
+}
+
+func (t *routingTable) get(id crypto.Hash) *dhtRecord {
+	i := dhtBucketIndex(t.self, id)
+	if i < 0 {
+		return nil
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, r := range t.buckets[i] {
+		if r.id == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (t *routingTable) closest(target crypto.Hash, count int) []*dhtRecord {
+	t.RLock()
+	var records []*dhtRecord
+	for _, bucket := range t.buckets {
+		for _, r := range bucket {
+			if time.Since(r.seen) < dhtRecordExpiration {
+				records = append(records, r)
+			}
+		}
+	}
+	t.RUnlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		return dhtCloser(target, records[i].id, records[j].id)
+	})
+	if len(records) > count {
+		records = records[:count]
+	}
+	return records
+}
+
+func dhtCloser(target, a, b crypto.Hash) bool {
+	da, db := dhtDistance(target, a), dhtDistance(target, b)
+	return bytes.Compare(da[:], db[:]) < 0
+}
+
+func (me *Peer) SetDiscovery(enabled bool) {
+	me.discovery = enabled
+}
+
+// discoveryLoop looks up the peers closest to self and to a random target,
+// by asking the neighbors closest to the target, the verified records are
+// connected and then asked in the next round, which converges like the
+// iterative Kademlia lookup.
+func (me *Peer) discoveryLoop() {
+	if !me.discovery {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(config.SnapshotRoundGap * 20))
+	defer ticker.Stop()
+
+	for !me.closing {
+		var random crypto.Hash
+		rand.Read(random[:])
+		for _, target := range []crypto.Hash{me.IdForNetwork, random} {
+			neighbors := me.neighbors.Slice()
+			sort.Slice(neighbors, func(i, j int) bool {
+				return dhtCloser(target, neighbors[i].IdForNetwork, neighbors[j].IdForNetwork)
+			})
+			if len(neighbors) > dhtAlpha {
+				neighbors = neighbors[:dhtAlpha]
+			}
+			for _, p := range neighbors {
+				key := append(p.IdForNetwork[:], target[:]...)
+				key = append(key, 'D', 'H', 'T', PeerMessageTypeFindPeers)
+				me.sendHighToPeer(p.IdForNetwork, key, buildFindPeersMessage(target))
+			}
+		}
+		<-ticker.C
+	}
+}
+
+func (me *Peer) handleFindPeers(peer *Peer, target crypto.Hash) error {
+	if !me.discovery {
+		return nil
+	}
+	records := me.routes.closest(target, dhtBucketSize)
+	if len(records) == 0 {
+		return nil
+	}
+	data := make([][]byte, len(records))
+	for i, r := range records {
+		data[i] = r.data
+	}
+	key := append(peer.IdForNetwork[:], target[:]...)
+	key = append(key, 'D', 'H', 'T', PeerMessageTypePeerRecords)
+	return me.sendHighToPeer(peer.IdForNetwork, key, buildPeerRecordsMessage(data))
+}
+
+func (me *Peer) handlePeerRecords(records [][]byte) {
+	if !me.discovery {
+		return
+	}
+	for _, data := range records {
+		id, listener, err := me.handle.VerifyPeerRecord(data)
+		if err != nil {
+			logger.Verbosef("handlePeerRecords VerifyPeerRecord ERROR %s\n", err)
+			continue
+		}
+		if id == me.IdForNetwork {
+			continue
+		}
+		me.routes.update(&dhtRecord{id: id, listener: listener, data: data, seen: time.Now()})
+		if me.neighbors.Get(id) == nil {
+			me.PingNeighbor(listener)
+		}
+	}
+}
+
+func buildFindPeersMessage(target crypto.Hash) []byte {
+	return buildMessage(PeerMessageTypeFindPeers, target[:])
+}
+
+func buildPeerRecordsMessage(records [][]byte) []byte {
+	return buildMessage(PeerMessageTypePeerRecords, common.MsgpackMarshalPanic(records))
+}
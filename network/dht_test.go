@@ -0,0 +1,56 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingTable(t *testing.T) {
+	assert := assert.New(t)
+
+	var self crypto.Hash
+	table := newRoutingTable(self)
+	assert.Equal(-1, dhtBucketIndex(self, self))
+
+	var id crypto.Hash
+	id[0] = 0x80
+	assert.Equal(0, dhtBucketIndex(self, id))
+	id[0] = 0x01
+	assert.Equal(7, dhtBucketIndex(self, id))
+	id[0], id[31] = 0, 0x01
+	assert.Equal(255, dhtBucketIndex(self, id))
+
+	table.update(&dhtRecord{id: self, seen: time.Now()})
+	assert.Len(table.closest(self, dhtBucketSize), 0)
+
+	for i := 0; i < dhtBucketSize+2; i++ {
+		var id crypto.Hash
+		id[0], id[1] = 0x80, byte(i)
+		table.update(&dhtRecord{id: id, listener: fmt.Sprintf("127.0.0.1:%d", 7000+i), seen: time.Now()})
+	}
+	assert.Len(table.buckets[0], dhtBucketSize)
+	id = crypto.Hash{0x80, dhtBucketSize}
+	assert.Nil(table.get(id))
+
+	table.buckets[0][0].seen = time.Now().Add(-dhtRecordExpiration * 2)
+	table.update(&dhtRecord{id: id, listener: "127.0.0.1:7100", seen: time.Now()})
+	assert.Len(table.buckets[0], dhtBucketSize)
+	assert.Nil(table.get(crypto.Hash{0x80, 0}))
+	assert.Equal("127.0.0.1:7100", table.get(id).listener)
+
+	table.update(&dhtRecord{id: crypto.Hash{0x80, 1}, listener: "127.0.0.1:7200", seen: time.Now()})
+	assert.Len(table.buckets[0], dhtBucketSize)
+	assert.Equal("127.0.0.1:7200", table.buckets[0][dhtBucketSize-1].listener)
+
+	near := crypto.Hash{0x01}
+	table.update(&dhtRecord{id: near, seen: time.Now()})
+	closest := table.closest(crypto.Hash{0x01, 0xff}, 3)
+	assert.Len(closest, 3)
+	assert.Equal(near, closest[0].id)
+	assert.Equal(crypto.Hash{0x80, 8}, closest[1].id)
+	assert.Equal(crypto.Hash{0x80, 7}, closest[2].id)
+}
@@ -0,0 +1,139 @@
+package network
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+// graphDeltaFullPeriod bounds the deltas sent after a full graph, so that
+// any divergence not detected by the versions is repaired in time.
+const graphDeltaFullPeriod = 64
+
+// GraphDelta carries the sync points changed since the graph of the base
+// version sent to the same peer, and a base of 0 means a full graph. The
+// versions are local to each outbound stream, which always starts with a
+// full graph.
+type GraphDelta struct {
+	Base    uint64
+	Version uint64
+	Points  []*SyncPoint
+	Removed []crypto.Hash
+}
+
+// graphExchange keeps the graph last sent to a neighbor and the graph last
+// received from it, to exchange only the changed rounds. The receiver
+// applies a delta only on the exact base version, otherwise it drops the
+// delta and asks the sender to start over with a full graph.
+type graphExchange struct {
+	sync.Mutex
+	sent     map[crypto.Hash]SyncPoint
+	version  uint64
+	deltas   int
+	received map[crypto.Hash]*SyncPoint
+	current  uint64
+}
+
+// reset makes the next graph sent a full one.
+func (ge *graphExchange) reset() {
+	ge.Lock()
+	defer ge.Unlock()
+
+	ge.sent = nil
+	ge.deltas = 0
+}
+
+func (ge *graphExchange) delta(points []*SyncPoint) *GraphDelta {
+	ge.Lock()
+	defer ge.Unlock()
+
+	ge.version += 1
+	d := &GraphDelta{Version: ge.version}
+	if ge.sent == nil || ge.deltas >= graphDeltaFullPeriod {
+		ge.sent = make(map[crypto.Hash]SyncPoint, len(points))
+		for _, p := range points {
+			ge.sent[p.NodeId] = *p
+		}
+		ge.deltas = 0
+		d.Points = points
+		return d
+	}
+
+	d.Base = ge.version - 1
+	graph := make(map[crypto.Hash]SyncPoint, len(points))
+	for _, p := range points {
+		graph[p.NodeId] = *p
+		old, found := ge.sent[p.NodeId]
+		if !found || old.Number != p.Number || old.Hash != p.Hash {
+			d.Points = append(d.Points, p)
+		}
+	}
+	for id := range ge.sent {
+		if _, found := graph[id]; !found {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	ge.sent = graph
+	ge.deltas += 1
+	return d
+}
+
+// apply returns the full graph after the delta, or false if the delta is not
+// based on the graph received.
+func (ge *graphExchange) apply(d *GraphDelta) ([]*SyncPoint, bool) {
+	ge.Lock()
+	defer ge.Unlock()
+
+	if d.Base == 0 {
+		ge.received = make(map[crypto.Hash]*SyncPoint, len(d.Points))
+	} else if ge.received == nil || d.Base != ge.current {
+		ge.received = nil
+		return nil, false
+	}
+	for _, p := range d.Points {
+		ge.received[p.NodeId] = p
+	}
+	for _, id := range d.Removed {
+		delete(ge.received, id)
+	}
+	ge.current = d.Version
+
+	graph := make([]*SyncPoint, 0, len(ge.received))
+	for _, p := range ge.received {
+		graph = append(graph, p)
+	}
+	return graph, true
+}
+
+func (me *Peer) buildGraphExchangeMessage(p *Peer, points []*SyncPoint) []byte {
+	if !me.canSendMessage(p, PeerMessageTypeGraphDelta) {
+		return buildGraphMessage(points)
+	}
+	return buildGraphDeltaMessage(p.graphs.delta(points))
+}
+
+func (me *Peer) handleGraphDelta(peer *Peer, d *GraphDelta) []*SyncPoint {
+	graph, applied := peer.graphs.apply(d)
+	if applied {
+		return graph
+	}
+	logger.Verbosef("network.handle handleGraphDelta %s mismatch %d\n", peer.IdForNetwork, d.Base)
+	key := append(peer.IdForNetwork[:], me.IdForNetwork[:]...)
+	base := make([]byte, 8)
+	binary.BigEndian.PutUint64(base, d.Base)
+	key = append(key, base...)
+	key = append(key, 'G', 'D', 'R', PeerMessageTypeGraphResync)
+	me.sendHighToPeer(peer.IdForNetwork, key, buildGraphResyncMessage())
+	return nil
+}
+
+func buildGraphDeltaMessage(d *GraphDelta) []byte {
+	return buildMessage(PeerMessageTypeGraphDelta, common.MsgpackMarshalPanic(d))
+}
+
+func buildGraphResyncMessage() []byte {
+	return buildMessage(PeerMessageTypeGraphResync)
+}
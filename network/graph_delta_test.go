@@ -0,0 +1,94 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphDelta(t *testing.T) {
+	assert := assert.New(t)
+
+	me := NewPeer(nil, crypto.NewHash([]byte("me")), "127.0.0.1:7001", false)
+	legacy := NewPeer(nil, crypto.NewHash([]byte("legacy")), "127.0.0.1:7002", false)
+	modern := NewPeer(nil, crypto.NewHash([]byte("modern")), "127.0.0.1:7003", false)
+	modern.protocol.set(modern.LocalHello())
+
+	point := func(i, n int) *SyncPoint {
+		return &SyncPoint{
+			NodeId: crypto.NewHash([]byte{byte(i)}),
+			Number: uint64(n),
+			Hash:   crypto.NewHash([]byte{byte(i), byte(n)}),
+		}
+	}
+	graph := []*SyncPoint{point(0, 10), point(1, 20), point(2, 30)}
+
+	msg, err := parseNetworkMessage(TransportMessageVersion, me.buildGraphExchangeMessage(legacy, graph))
+	assert.Nil(err)
+	assert.Equal(uint8(PeerMessageTypeGraph), msg.Type)
+	assert.Len(msg.Graph, 3)
+
+	sender, receiver := modern.graphs, &graphExchange{}
+	exchange := func(points []*SyncPoint) (*GraphDelta, []*SyncPoint, bool) {
+		msg, err := parseNetworkMessage(TransportMessageVersion, me.buildGraphExchangeMessage(modern, points))
+		assert.Nil(err)
+		assert.Equal(uint8(PeerMessageTypeGraphDelta), msg.Type)
+		graph, applied := receiver.apply(msg.GraphDelta)
+		return msg.GraphDelta, graph, applied
+	}
+
+	d, received, applied := exchange(graph)
+	assert.True(applied)
+	assert.Equal(uint64(0), d.Base)
+	assert.Equal(uint64(1), d.Version)
+	assert.ElementsMatch(graph, received)
+
+	d, received, applied = exchange(graph)
+	assert.True(applied)
+	assert.Equal(uint64(1), d.Base)
+	assert.Len(d.Points, 0)
+	assert.Len(d.Removed, 0)
+	assert.ElementsMatch(graph, received)
+
+	graph = []*SyncPoint{point(0, 11), point(1, 20), point(3, 1)}
+	d, received, applied = exchange(graph)
+	assert.True(applied)
+	assert.Equal(uint64(2), d.Base)
+	assert.ElementsMatch([]*SyncPoint{point(0, 11), point(3, 1)}, d.Points)
+	assert.Equal([]crypto.Hash{point(2, 0).NodeId}, d.Removed)
+	assert.ElementsMatch(graph, received)
+
+	// a delta lost makes the next one mismatch, until the full graph
+	sender.delta(graph)
+	_, received, applied = exchange(graph)
+	assert.False(applied)
+	assert.Nil(received)
+	_, _, applied = exchange(graph)
+	assert.False(applied)
+	sender.reset()
+	d, received, applied = exchange(graph)
+	assert.True(applied)
+	assert.Equal(uint64(0), d.Base)
+	assert.ElementsMatch(graph, received)
+
+	for i := 0; i < graphDeltaFullPeriod; i++ {
+		d, _, applied = exchange(graph)
+		assert.True(applied)
+		assert.NotEqual(uint64(0), d.Base)
+	}
+	d, received, applied = exchange(graph)
+	assert.True(applied)
+	assert.Equal(uint64(0), d.Base)
+	assert.ElementsMatch(graph, received)
+
+	msg, err = parseNetworkMessage(TransportMessageVersion, buildGraphResyncMessage())
+	assert.Nil(err)
+	assert.Equal(uint8(PeerMessageTypeGraphResync), msg.Type)
+	assert.True(me.canAcceptMessage(PeerMessageTypeGraphDelta))
+	assert.False(me.canSendMessage(legacy, PeerMessageTypeGraphResync))
+
+	msg, err = parseNetworkMessage(TransportMessageVersion, []byte{PeerMessageTypeGraphDelta, 0xc0})
+	assert.NotNil(err)
+	assert.Nil(msg)
+}
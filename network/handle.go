@@ -27,6 +27,16 @@ const (
 	PeerMessageTypeSnapshotFinalization = 14 // leader generate A, verify si B = ri B + H(R || A || M)ai B = Ri + H(R || A || M)Ai, then finalize based on threshold
 
 	PeerMessageTypeGossipNeighbors = 101
+	PeerMessageTypeUpgradeIntent   = 102
+	PeerMessageTypeGoodbye         = 103
+	PeerMessageTypeTransportKey    = 104
+	PeerMessageTypeFindPeers       = 105
+	PeerMessageTypePeerRecords     = 106
+	PeerMessageTypeHello           = 107
+	PeerMessageTypeRelayRegister   = 108
+	PeerMessageTypeRelay           = 109
+	PeerMessageTypeGraphDelta      = 110
+	PeerMessageTypeGraphResync     = 111
 )
 
 type PeerMessage struct {
@@ -42,6 +52,13 @@ type PeerMessage struct {
 	Graph           []*SyncPoint
 	Auth            []byte
 	Neighbors       []string
+	Intent          []byte
+	TransportKey    []byte
+	Target          crypto.Hash
+	Records         [][]byte
+	Hello           *PeerHello
+	Relay           *RelayFrame
+	GraphDelta      *GraphDelta
 }
 
 type SyncHandle interface {
@@ -49,6 +66,18 @@ type SyncHandle interface {
 	BuildAuthenticationMessage() []byte
 	Authenticate(msg []byte) (crypto.Hash, string, error)
 	UpdateNeighbors(neighbors []string) error
+	BuildUpgradeIntentMessages() [][]byte
+	UpdateUpgradeIntent(peerId crypto.Hash, msg []byte) error
+	BuildTransportKeyMessage(keys []crypto.Hash) []byte
+	VerifyTransportKey(peerId crypto.Hash, msg []byte) ([]crypto.Hash, uint64, error)
+	EndorseTransportKey(spki []byte) []byte
+	VerifyTransportEndorsement(endorsement, spki []byte) (crypto.Hash, error)
+	SignRelayFrame(payload []byte) []byte
+	VerifyRelayFrame(signature, payload []byte) (crypto.Hash, error)
+	SealRelayFrame(target crypto.Hash, data []byte) ([]byte, error)
+	OpenRelayFrame(signature, sealed []byte) ([]byte, error)
+	BuildPeerRecord() []byte
+	VerifyPeerRecord(msg []byte) (crypto.Hash, string, error)
 	BuildGraph() []*SyncPoint
 	UpdateSyncPoint(peerId crypto.Hash, points []*SyncPoint)
 	ReadAllNodesWithoutState() []crypto.Hash
@@ -65,37 +94,39 @@ type SyncHandle interface {
 
 func (me *Peer) SendSnapshotAnnouncementMessage(idForNetwork crypto.Hash, s *common.Snapshot, R crypto.Key) error {
 	data := buildSnapshotAnnouncementMessage(s, R)
-	return me.sendSnapshotMessageToPeer(idForNetwork, s.PayloadHash(), PeerMessageTypeSnapshotAnnoucement, data)
+	return me.sendSnapshotMessageToPeer(idForNetwork, s.PayloadHash(), PeerMessageTypeSnapshotAnnoucement, data, sendClassConsensus)
 }
 
 func (me *Peer) SendSnapshotCommitmentMessage(idForNetwork crypto.Hash, snap crypto.Hash, R crypto.Key, wantTx bool) error {
 	data := buildSnapshotCommitmentMessage(snap, R, wantTx)
-	return me.sendSnapshotMessageToPeer(idForNetwork, snap, PeerMessageTypeSnapshotCommitment, data)
+	return me.sendSnapshotMessageToPeer(idForNetwork, snap, PeerMessageTypeSnapshotCommitment, data, sendClassConsensus)
 }
 
 func (me *Peer) SendTransactionChallengeMessage(idForNetwork crypto.Hash, snap crypto.Hash, cosi *crypto.CosiSignature, tx *common.VersionedTransaction) error {
 	data := buildTransactionChallengeMessage(snap, cosi, tx)
-	return me.sendSnapshotMessageToPeer(idForNetwork, snap, PeerMessageTypeTransactionChallenge, data)
+	return me.sendSnapshotMessageToPeer(idForNetwork, snap, PeerMessageTypeTransactionChallenge, data, sendClassConsensus)
 }
 
 func (me *Peer) SendSnapshotResponseMessage(idForNetwork crypto.Hash, snap crypto.Hash, si *[32]byte) error {
 	data := buildSnapshotResponseMessage(snap, si)
-	return me.sendSnapshotMessageToPeer(idForNetwork, snap, PeerMessageTypeSnapshotResponse, data)
+	return me.sendSnapshotMessageToPeer(idForNetwork, snap, PeerMessageTypeSnapshotResponse, data, sendClassConsensus)
 }
 
 func (me *Peer) SendSnapshotFinalizationMessage(idForNetwork crypto.Hash, s *common.Snapshot) error {
+	return me.sendSnapshotFinalizationMessage(idForNetwork, s, sendClassFinalization)
+}
+
+func (me *Peer) sendSnapshotFinalizationMessage(idForNetwork crypto.Hash, s *common.Snapshot, class sendClass) error {
 	if idForNetwork == me.IdForNetwork {
 		return nil
 	}
 
-	key := append(idForNetwork[:], s.Hash[:]...)
-	key = append(key, 'S', 'C', 'O')
-	if me.snapshotsCaches.contains(key, time.Hour) {
+	if me.gossipFilter.check(me.snapshotConfirmedByPeer(idForNetwork, s.Hash)) {
 		return nil
 	}
 
 	data := buildSnapshotFinalizationMessage(s)
-	return me.sendSnapshotMessageToPeer(idForNetwork, s.Hash, PeerMessageTypeSnapshotFinalization, data)
+	return me.sendSnapshotMessageToPeer(idForNetwork, s.Hash, PeerMessageTypeSnapshotFinalization, data, class)
 }
 
 func (me *Peer) SendSnapshotConfirmMessage(idForNetwork crypto.Hash, snap crypto.Hash) error {
@@ -117,15 +148,30 @@ func (me *Peer) SendTransactionMessage(idForNetwork crypto.Hash, ver *common.Ver
 	return me.sendHighToPeer(idForNetwork, key, buildTransactionMessage(ver))
 }
 
+// snapshotConfirmedByPeer checks the exact one hour cache of confirmations,
+// and the bloom filter of the neighbor only saves the cache lookups of the
+// snapshots not confirmed, so a false positive never skips a snapshot.
+func (me *Peer) snapshotConfirmedByPeer(idForNetwork, snap crypto.Hash) bool {
+	peer := me.neighbors.Get(idForNetwork)
+	if peer != nil && !peer.confirmed.contains(snap) {
+		return false
+	}
+	key := append(idForNetwork[:], snap[:]...)
+	key = append(key, 'S', 'C', 'O')
+	return me.snapshotsCaches.contains(key, time.Hour)
+}
+
 func (me *Peer) ConfirmSnapshotForPeer(idForNetwork, snap crypto.Hash) {
 	key := append(idForNetwork[:], snap[:]...)
 	key = append(key, 'S', 'C', 'O')
 	me.snapshotsCaches.store(key, time.Now())
+	if peer := me.neighbors.Get(idForNetwork); peer != nil {
+		peer.confirmed.add(snap)
+	}
 }
 
 func buildAuthenticationMessage(data []byte) []byte {
-	header := []byte{PeerMessageTypeAuthentication}
-	return append(header, data...)
+	return buildMessage(PeerMessageTypeAuthentication, data)
 }
 
 func buildGossipNeighborsMessage(neighbors []*Peer) []byte {
@@ -134,66 +180,77 @@ func buildGossipNeighborsMessage(neighbors []*Peer) []byte {
 		rns[i] = p.Address
 	}
 	data := common.MsgpackMarshalPanic(rns)
-	return append([]byte{PeerMessageTypeGossipNeighbors}, data...)
+	return buildMessage(PeerMessageTypeGossipNeighbors, data)
+}
+
+func buildUpgradeIntentMessage(data []byte) []byte {
+	return buildMessage(PeerMessageTypeUpgradeIntent, data)
+}
+
+func buildGoodbyeMessage() []byte {
+	return buildMessage(PeerMessageTypeGoodbye)
 }
 
 func buildSnapshotAnnouncementMessage(s *common.Snapshot, R crypto.Key) []byte {
 	data := common.MsgpackMarshalPanic(s)
-	data = append(R[:], data...)
-	return append([]byte{PeerMessageTypeSnapshotAnnoucement}, data...)
+	return buildMessage(PeerMessageTypeSnapshotAnnoucement, R[:], data)
 }
 
 func buildSnapshotCommitmentMessage(snap crypto.Hash, R crypto.Key, wantTx bool) []byte {
-	data := []byte{PeerMessageTypeSnapshotCommitment}
-	data = append(data, snap[:]...)
-	data = append(data, R[:]...)
 	if wantTx {
-		return append(data, byte(1))
+		return buildMessage(PeerMessageTypeSnapshotCommitment, snap[:], R[:], []byte{1})
 	}
-	return append(data, byte(0))
+	return buildMessage(PeerMessageTypeSnapshotCommitment, snap[:], R[:], []byte{0})
 }
 
 func buildTransactionChallengeMessage(snap crypto.Hash, cosi *crypto.CosiSignature, tx *common.VersionedTransaction) []byte {
 	mask := make([]byte, 8)
 	binary.BigEndian.PutUint64(mask, cosi.Mask)
-	data := []byte{PeerMessageTypeTransactionChallenge}
-	data = append(data, snap[:]...)
-	data = append(data, cosi.Signature[:]...)
-	data = append(data, mask...)
 	if tx != nil {
 		pl := tx.Marshal()
-		return append(data, pl...)
+		return buildMessage(PeerMessageTypeTransactionChallenge, snap[:], cosi.Signature[:], mask, pl)
 	}
-	return data
+	return buildMessage(PeerMessageTypeTransactionChallenge, snap[:], cosi.Signature[:], mask)
 }
 
 func buildSnapshotResponseMessage(snap crypto.Hash, si *[32]byte) []byte {
-	data := []byte{PeerMessageTypeSnapshotResponse}
-	data = append(data, snap[:]...)
-	return append(data, si[:]...)
+	return buildMessage(PeerMessageTypeSnapshotResponse, snap[:], si[:])
 }
 
 func buildSnapshotFinalizationMessage(s *common.Snapshot) []byte {
 	data := common.MsgpackMarshalPanic(s)
-	return append([]byte{PeerMessageTypeSnapshotFinalization}, data...)
+	return buildMessage(PeerMessageTypeSnapshotFinalization, data)
 }
 
 func buildSnapshotConfirmMessage(snap crypto.Hash) []byte {
-	return append([]byte{PeerMessageTypeSnapshotConfirm}, snap[:]...)
+	return buildMessage(PeerMessageTypeSnapshotConfirm, snap[:])
 }
 
 func buildTransactionMessage(ver *common.VersionedTransaction) []byte {
 	data := ver.Marshal()
-	return append([]byte{PeerMessageTypeTransaction}, data...)
+	return buildMessage(PeerMessageTypeTransaction, data)
 }
 
 func buildTransactionRequestMessage(tx crypto.Hash) []byte {
-	return append([]byte{PeerMessageTypeTransactionRequest}, tx[:]...)
+	return buildMessage(PeerMessageTypeTransactionRequest, tx[:])
 }
 
 func buildGraphMessage(points []*SyncPoint) []byte {
 	data := common.MsgpackMarshalPanic(points)
-	return append([]byte{PeerMessageTypeGraph}, data...)
+	return buildMessage(PeerMessageTypeGraph, data)
+}
+
+func buildMessage(typ uint8, parts ...[]byte) []byte {
+	size := 1
+	for _, p := range parts {
+		size += len(p)
+	}
+	msg := make([]byte, 1, size)
+	msg[0] = typ
+	for _, p := range parts {
+		msg = append(msg, p...)
+	}
+	return msg
 }
 
 func parseNetworkMessage(version uint8, data []byte) (*PeerMessage, error) {
@@ -207,7 +264,17 @@ func parseNetworkMessage(version uint8, data []byte) (*PeerMessage, error) {
 		if err != nil {
 			return nil, err
 		}
+	case PeerMessageTypeGraphDelta:
+		err := common.MsgpackUnmarshal(data[1:], &msg.GraphDelta)
+		if err != nil {
+			return nil, err
+		}
+		if msg.GraphDelta == nil {
+			return nil, fmt.Errorf("invalid graph delta data %d", len(data))
+		}
+	case PeerMessageTypeGraphResync:
 	case PeerMessageTypePing:
+	case PeerMessageTypeGoodbye:
 	case PeerMessageTypeGossipNeighbors:
 		err := common.MsgpackUnmarshal(data[1:], &msg.Neighbors)
 		if err != nil {
@@ -215,6 +282,37 @@ func parseNetworkMessage(version uint8, data []byte) (*PeerMessage, error) {
 		}
 	case PeerMessageTypeAuthentication:
 		msg.Auth = data[1:]
+	case PeerMessageTypeUpgradeIntent:
+		msg.Intent = data[1:]
+	case PeerMessageTypeTransportKey:
+		msg.TransportKey = data[1:]
+	case PeerMessageTypeHello:
+		h, err := parseHello(data[1:])
+		if err != nil {
+			return nil, err
+		}
+		msg.Hello = h
+	case PeerMessageTypeFindPeers:
+		if len(data[1:]) != len(msg.Target) {
+			return nil, fmt.Errorf("invalid find peers message size %d", len(data[1:]))
+		}
+		copy(msg.Target[:], data[1:])
+	case PeerMessageTypePeerRecords:
+		err := common.MsgpackUnmarshal(data[1:], &msg.Records)
+		if err != nil {
+			return nil, err
+		}
+		if len(msg.Records) > dhtBucketSize {
+			return nil, fmt.Errorf("invalid peer records count %d", len(msg.Records))
+		}
+	case PeerMessageTypeRelayRegister:
+	case PeerMessageTypeRelay:
+		var f RelayFrame
+		err := common.MsgpackUnmarshal(data[1:], &f)
+		if err != nil {
+			return nil, err
+		}
+		msg.Relay = &f
 	case PeerMessageTypeSnapshotConfirm:
 		copy(msg.SnapshotHash[:], data[1:])
 	case PeerMessageTypeTransaction:
@@ -230,13 +328,11 @@ func parseNetworkMessage(version uint8, data []byte) (*PeerMessage, error) {
 			return nil, fmt.Errorf("invalid announcement message size %d", len(data[1:]))
 		}
 		copy(msg.Commitment[:], data[1:])
-		err := common.MsgpackUnmarshal(data[33:], &msg.Snapshot)
+		s, err := common.UnmarshalSnapshot(data[33:])
 		if err != nil {
-			return nil, err
-		}
-		if msg.Snapshot == nil {
-			return nil, fmt.Errorf("invalid snapshot announcement message data")
+			return nil, fmt.Errorf("invalid snapshot announcement message data %v", err)
 		}
+		msg.Snapshot = s
 	case PeerMessageTypeSnapshotCommitment:
 		if len(data[1:]) != 65 {
 			return nil, fmt.Errorf("invalid commitment message size %d", len(data[1:]))
@@ -265,53 +361,100 @@ func parseNetworkMessage(version uint8, data []byte) (*PeerMessage, error) {
 		copy(msg.SnapshotHash[:], data[1:])
 		copy(msg.Response[:], data[33:])
 	case PeerMessageTypeSnapshotFinalization:
-		err := common.MsgpackUnmarshal(data[1:], &msg.Snapshot)
+		s, err := common.UnmarshalSnapshot(data[1:])
 		if err != nil {
-			return nil, err
-		}
-		if msg.Snapshot == nil {
-			return nil, fmt.Errorf("invalid snapshot finalization message data")
+			return nil, fmt.Errorf("invalid snapshot finalization message data %v", err)
 		}
+		msg.Snapshot = s
 	}
 	return msg, nil
 }
 
 func (me *Peer) handlePeerMessage(peer *Peer, receive chan *PeerMessage) {
 	for msg := range receive {
-		switch msg.Type {
-		case PeerMessageTypePing:
-		case PeerMessageTypeGossipNeighbors:
-			if me.gossipNeighbors {
-				me.handle.UpdateNeighbors(msg.Neighbors)
-			}
-		case PeerMessageTypeGraph:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeGraph %s\n", peer.IdForNetwork)
-			me.handle.UpdateSyncPoint(peer.IdForNetwork, msg.Graph)
-			peer.syncRing.Offer(msg.Graph)
-		case PeerMessageTypeTransactionRequest:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransactionRequest %s %s\n", peer.IdForNetwork, msg.TransactionHash)
-			me.handle.SendTransactionToPeer(peer.IdForNetwork, msg.TransactionHash)
-		case PeerMessageTypeTransaction:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransaction %s\n", peer.IdForNetwork)
-			me.handle.CachePutTransaction(peer.IdForNetwork, msg.Transaction)
-		case PeerMessageTypeSnapshotConfirm:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotConfirm %s %s\n", peer.IdForNetwork, msg.SnapshotHash)
-			me.ConfirmSnapshotForPeer(peer.IdForNetwork, msg.SnapshotHash)
-		case PeerMessageTypeSnapshotAnnoucement:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotAnnoucement %s %s\n", peer.IdForNetwork, msg.Snapshot.Transaction)
-			me.handle.CosiQueueExternalAnnouncement(peer.IdForNetwork, msg.Snapshot, &msg.Commitment)
-		case PeerMessageTypeSnapshotCommitment:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotCommitment %s %s\n", peer.IdForNetwork, msg.SnapshotHash)
-			me.handle.CosiAggregateSelfCommitments(peer.IdForNetwork, msg.SnapshotHash, &msg.Commitment, msg.WantTx)
-		case PeerMessageTypeTransactionChallenge:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransactionChallenge %s %s %t\n", peer.IdForNetwork, msg.SnapshotHash, msg.Transaction != nil)
-			me.handle.CosiQueueExternalChallenge(peer.IdForNetwork, msg.SnapshotHash, &msg.Cosi, msg.Transaction)
-		case PeerMessageTypeSnapshotResponse:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotResponse %s %s\n", peer.IdForNetwork, msg.SnapshotHash)
-			me.handle.CosiAggregateSelfResponses(peer.IdForNetwork, msg.SnapshotHash, &msg.Response)
-		case PeerMessageTypeSnapshotFinalization:
-			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotFinalization %s %s\n", peer.IdForNetwork, msg.Snapshot.Transaction)
-			me.handle.VerifyAndQueueAppendSnapshotFinalization(peer.IdForNetwork, msg.Snapshot)
+		me.handleMessage(peer, msg)
+	}
+}
+
+func (me *Peer) handleMessage(peer *Peer, msg *PeerMessage) {
+	if !me.canAcceptMessage(msg.Type) {
+		logger.Verbosef("network.handle handlePeerMessage unsupported message %d from %s\n", msg.Type, peer.IdForNetwork)
+		return
+	}
+	switch msg.Type {
+	case PeerMessageTypePing:
+	case PeerMessageTypeHello:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeHello %s %d %x\n", peer.IdForNetwork, msg.Hello.Version, msg.Hello.Capabilities)
+		me.handleHello(peer, msg.Hello)
+	case PeerMessageTypeGossipNeighbors:
+		if me.gossipNeighbors {
+			me.handle.UpdateNeighbors(msg.Neighbors)
 		}
+	case PeerMessageTypeUpgradeIntent:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeUpgradeIntent %s\n", peer.IdForNetwork)
+		me.handle.UpdateUpgradeIntent(peer.IdForNetwork, msg.Intent)
+	case PeerMessageTypeTransportKey:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransportKey %s\n", peer.IdForNetwork)
+		keys, ts, err := me.handle.VerifyTransportKey(peer.IdForNetwork, msg.TransportKey)
+		if err != nil {
+			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransportKey %s ERROR %s\n", peer.IdForNetwork, err)
+			return
+		}
+		me.transportPins.set(peer.IdForNetwork, keys, ts)
+	case PeerMessageTypeFindPeers:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeFindPeers %s %s\n", peer.IdForNetwork, msg.Target)
+		me.handleFindPeers(peer, msg.Target)
+	case PeerMessageTypePeerRecords:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypePeerRecords %s %d\n", peer.IdForNetwork, len(msg.Records))
+		me.handlePeerRecords(msg.Records)
+	case PeerMessageTypeGraph:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeGraph %s\n", peer.IdForNetwork)
+		me.handle.UpdateSyncPoint(peer.IdForNetwork, msg.Graph)
+		me.stale.update(peer, msg.Graph, time.Now())
+		me.partition.update(peer, msg.Graph)
+		peer.syncRing.Offer(msg.Graph)
+	case PeerMessageTypeGraphDelta:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeGraphDelta %s %d %d\n", peer.IdForNetwork, msg.GraphDelta.Base, len(msg.GraphDelta.Points))
+		graph := me.handleGraphDelta(peer, msg.GraphDelta)
+		if graph == nil {
+			return
+		}
+		me.handle.UpdateSyncPoint(peer.IdForNetwork, graph)
+		me.stale.update(peer, graph, time.Now())
+		me.partition.update(peer, graph)
+		peer.syncRing.Offer(graph)
+	case PeerMessageTypeGraphResync:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeGraphResync %s\n", peer.IdForNetwork)
+		peer.graphs.reset()
+	case PeerMessageTypeTransactionRequest:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransactionRequest %s %s\n", peer.IdForNetwork, msg.TransactionHash)
+		me.handle.SendTransactionToPeer(peer.IdForNetwork, msg.TransactionHash)
+	case PeerMessageTypeTransaction:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransaction %s\n", peer.IdForNetwork)
+		me.handle.CachePutTransaction(peer.IdForNetwork, msg.Transaction)
+	case PeerMessageTypeSnapshotConfirm:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotConfirm %s %s\n", peer.IdForNetwork, msg.SnapshotHash)
+		me.ConfirmSnapshotForPeer(peer.IdForNetwork, msg.SnapshotHash)
+	case PeerMessageTypeSnapshotAnnoucement:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotAnnoucement %s %s\n", peer.IdForNetwork, msg.Snapshot.Transaction)
+		me.handle.CosiQueueExternalAnnouncement(peer.IdForNetwork, msg.Snapshot, &msg.Commitment)
+	case PeerMessageTypeSnapshotCommitment:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotCommitment %s %s\n", peer.IdForNetwork, msg.SnapshotHash)
+		me.handle.CosiAggregateSelfCommitments(peer.IdForNetwork, msg.SnapshotHash, &msg.Commitment, msg.WantTx)
+	case PeerMessageTypeTransactionChallenge:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeTransactionChallenge %s %s %t\n", peer.IdForNetwork, msg.SnapshotHash, msg.Transaction != nil)
+		me.handle.CosiQueueExternalChallenge(peer.IdForNetwork, msg.SnapshotHash, &msg.Cosi, msg.Transaction)
+	case PeerMessageTypeSnapshotResponse:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotResponse %s %s\n", peer.IdForNetwork, msg.SnapshotHash)
+		me.handle.CosiAggregateSelfResponses(peer.IdForNetwork, msg.SnapshotHash, &msg.Response)
+	case PeerMessageTypeRelay:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeRelay %s %s\n", peer.IdForNetwork, msg.Relay.Target)
+		err := me.forwardRelayFrame(peer, msg.Relay)
+		if err != nil {
+			logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeRelay %s ERROR %s\n", peer.IdForNetwork, err)
+		}
+	case PeerMessageTypeSnapshotFinalization:
+		logger.Verbosef("network.handle handlePeerMessage PeerMessageTypeSnapshotFinalization %s %s\n", peer.IdForNetwork, msg.Snapshot.Transaction)
+		me.handle.VerifyAndQueueAppendSnapshotFinalization(peer.IdForNetwork, msg.Snapshot)
 	}
 }
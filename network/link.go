@@ -0,0 +1,94 @@
+package network
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	PeerStateConnected  = "connected"
+	PeerStateConnecting = "connecting"
+	PeerStateDemoted    = "demoted"
+)
+
+type PeerLink struct {
+	Peer      crypto.Hash
+	Address   string
+	Latency   time.Duration
+	Connected time.Time
+	Received  time.Time
+}
+
+// peerLink records the connectivity of a neighbor, the latency is the QUIC
+// handshake duration of the outbound stream, which takes one round trip. All
+// the fields are unix nanoseconds or durations updated atomically, because
+// the received timestamp is updated on every inbound message.
+type peerLink struct {
+	latency   int64
+	connected int64
+	received  int64
+}
+
+func (l *peerLink) dialed(latency time.Duration, now time.Time) {
+	atomic.StoreInt64(&l.latency, int64(latency))
+	atomic.StoreInt64(&l.connected, now.UnixNano())
+}
+
+func (l *peerLink) disconnected() {
+	atomic.StoreInt64(&l.connected, 0)
+}
+
+func (l *peerLink) receive(now time.Time) {
+	atomic.StoreInt64(&l.received, now.UnixNano())
+}
+
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// PeerLinks returns the current adjacency of this peer, i.e. all neighbors
+// with their latencies and when the streams were established.
+func (me *Peer) PeerLinks() []*PeerLink {
+	neighbors := me.neighbors.Slice()
+	links := make([]*PeerLink, len(neighbors))
+	for i, p := range neighbors {
+		links[i] = &PeerLink{
+			Peer:      p.IdForNetwork,
+			Address:   p.Address,
+			Latency:   time.Duration(atomic.LoadInt64(&p.link.latency)),
+			Connected: unixTime(atomic.LoadInt64(&p.link.connected)),
+			Received:  unixTime(atomic.LoadInt64(&p.link.received)),
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Peer.String() < links[j].Peer.String()
+	})
+	return links
+}
+
+// PeerStateCounts counts the neighbors by their states, a connected neighbor
+// demoted by the stale tracker is counted only as demoted.
+func (me *Peer) PeerStateCounts() map[string]int {
+	counts := map[string]int{
+		PeerStateConnected:  0,
+		PeerStateConnecting: 0,
+		PeerStateDemoted:    0,
+	}
+	for _, p := range me.neighbors.Slice() {
+		switch {
+		case me.stale.demoted(p.IdForNetwork):
+			counts[PeerStateDemoted]++
+		case atomic.LoadInt64(&p.link.connected) > 0:
+			counts[PeerStateConnected]++
+		default:
+			counts[PeerStateConnecting]++
+		}
+	}
+	return counts
+}
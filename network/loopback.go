@@ -0,0 +1,319 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const loopbackPipeSize = 1024
+
+// LoopbackNetwork connects the peers in the same process without sockets,
+// for the kernel simulations to run many nodes, and to inject the latency
+// and partitions between them. The peers still use their listener address
+// to dial each other, and the pipes are one way just like the QUIC streams.
+type LoopbackNetwork struct {
+	sync.RWMutex
+	listeners  map[string]*loopbackTransport
+	partitions map[string]int
+	latency    time.Duration
+}
+
+type loopbackAddr string
+
+type loopbackMessage struct {
+	data []byte
+	at   time.Time
+}
+
+type loopbackPipe struct {
+	messages chan *loopbackMessage
+	done     chan struct{}
+	once     sync.Once
+}
+
+type loopbackClient struct {
+	network *LoopbackNetwork
+	local   string
+	remote  string
+	pipe    *loopbackPipe
+	reverse chan *loopbackPipe
+}
+
+type loopbackTransport struct {
+	network *LoopbackNetwork
+	local   string
+	remote  string
+	accept  chan *loopbackClient
+	done    chan struct{}
+	once    sync.Once
+}
+
+func NewLoopbackNetwork() *LoopbackNetwork {
+	return &LoopbackNetwork{
+		listeners:  make(map[string]*loopbackTransport),
+		partitions: make(map[string]int),
+	}
+}
+
+// SetLatency delays all the messages between the peers.
+func (n *LoopbackNetwork) SetLatency(latency time.Duration) {
+	n.Lock()
+	defer n.Unlock()
+
+	n.latency = latency
+}
+
+// Partition splits the addresses into the groups, the peers in different
+// groups can't reach each other, and the established pipes between them are
+// broken. The addresses not in any group still reach all peers.
+func (n *LoopbackNetwork) Partition(groups ...[]string) {
+	n.Lock()
+	defer n.Unlock()
+
+	n.partitions = make(map[string]int)
+	for i, g := range groups {
+		for _, addr := range g {
+			n.partitions[addr] = i + 1
+		}
+	}
+}
+
+// Heal removes all the partitions.
+func (n *LoopbackNetwork) Heal() {
+	n.Partition()
+}
+
+func (n *LoopbackNetwork) reachable(a, b string) bool {
+	n.RLock()
+	defer n.RUnlock()
+
+	pa, pb := n.partitions[a], n.partitions[b]
+	return pa == 0 || pb == 0 || pa == pb
+}
+
+func (n *LoopbackNetwork) delay() time.Duration {
+	n.RLock()
+	defer n.RUnlock()
+
+	return n.latency
+}
+
+func (n *LoopbackNetwork) server(addr string) *loopbackTransport {
+	return &loopbackTransport{
+		network: n,
+		local:   addr,
+		accept:  make(chan *loopbackClient),
+		done:    make(chan struct{}),
+	}
+}
+
+func (n *LoopbackNetwork) client(local, remote string) *loopbackTransport {
+	return &loopbackTransport{
+		network: n,
+		local:   local,
+		remote:  remote,
+		done:    make(chan struct{}),
+	}
+}
+
+func (t *loopbackTransport) Listen() error {
+	t.network.Lock()
+	defer t.network.Unlock()
+
+	if t.network.listeners[t.local] != nil {
+		return fmt.Errorf("loopback address %s in use", t.local)
+	}
+	t.network.listeners[t.local] = t
+	return nil
+}
+
+func (t *loopbackTransport) Dial(ctx context.Context) (Client, error) {
+	if !t.network.reachable(t.local, t.remote) {
+		return nil, fmt.Errorf("loopback unreachable %s %s", t.local, t.remote)
+	}
+	t.network.RLock()
+	server := t.network.listeners[t.remote]
+	t.network.RUnlock()
+	if server == nil {
+		return nil, fmt.Errorf("loopback connection refused %s", t.remote)
+	}
+
+	pipe := &loopbackPipe{
+		messages: make(chan *loopbackMessage, loopbackPipeSize),
+		done:     make(chan struct{}),
+	}
+	reverse := make(chan *loopbackPipe, 1)
+	accepted := &loopbackClient{network: t.network, local: t.remote, remote: t.local, pipe: pipe, reverse: reverse}
+	select {
+	case server.accept <- accepted:
+	case <-server.done:
+		return nil, fmt.Errorf("loopback connection refused %s", t.remote)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(HandshakeTimeout):
+		return nil, fmt.Errorf("loopback dial timeout %s", t.remote)
+	}
+	return &loopbackClient{network: t.network, local: t.local, remote: t.remote, pipe: pipe, reverse: reverse}, nil
+}
+
+func (t *loopbackTransport) Accept(ctx context.Context) (Client, error) {
+	select {
+	case c := <-t.accept:
+		return c, nil
+	case <-t.done:
+		return nil, fmt.Errorf("loopback closed %s", t.local)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *loopbackTransport) Close() error {
+	t.once.Do(func() {
+		close(t.done)
+		t.network.Lock()
+		if t.network.listeners[t.local] == t {
+			delete(t.network.listeners, t.local)
+		}
+		t.network.Unlock()
+	})
+	return nil
+}
+
+func (c *loopbackClient) RemoteAddr() net.Addr {
+	return loopbackAddr(c.remote)
+}
+
+func (c *loopbackClient) Send(data []byte) error {
+	if !c.network.reachable(c.local, c.remote) {
+		c.Close()
+		return fmt.Errorf("loopback unreachable %s %s", c.local, c.remote)
+	}
+	msg := &loopbackMessage{
+		data: append([]byte{}, data...),
+		at:   time.Now().Add(c.network.delay()),
+	}
+	select {
+	case c.pipe.messages <- msg:
+		return nil
+	case <-c.pipe.done:
+		return io.ErrClosedPipe
+	case <-time.After(WriteDeadline):
+		return fmt.Errorf("loopback send timeout %s", c.remote)
+	}
+}
+
+func (c *loopbackClient) Receive() (*TransportMessage, error) {
+	select {
+	case msg := <-c.pipe.messages:
+		if !c.network.reachable(c.local, c.remote) {
+			c.Close()
+			return nil, fmt.Errorf("loopback unreachable %s %s", c.local, c.remote)
+		}
+		time.Sleep(time.Until(msg.at))
+		return &TransportMessage{
+			Version: TransportMessageVersion,
+			Size:    uint32(len(msg.data)),
+			Data:    msg.data,
+		}, nil
+	case <-c.pipe.done:
+		return nil, io.EOF
+	case <-time.After(ReadDeadline):
+		return nil, fmt.Errorf("loopback receive timeout %s", c.remote)
+	}
+}
+
+func (c *loopbackClient) openReverse(ctx context.Context) (Client, error) {
+	pipe := &loopbackPipe{
+		messages: make(chan *loopbackMessage, loopbackPipeSize),
+		done:     make(chan struct{}),
+	}
+	select {
+	case c.reverse <- pipe:
+	case <-c.pipe.done:
+		return nil, io.ErrClosedPipe
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &loopbackClient{network: c.network, local: c.local, remote: c.remote, pipe: pipe}, nil
+}
+
+func (c *loopbackClient) acceptReverse(ctx context.Context) (Client, error) {
+	select {
+	case pipe := <-c.reverse:
+		return &loopbackClient{network: c.network, local: c.local, remote: c.remote, pipe: pipe}, nil
+	case <-c.pipe.done:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *loopbackClient) Close() error {
+	c.pipe.once.Do(func() {
+		close(c.pipe.done)
+	})
+	return nil
+}
+
+func (a loopbackAddr) Network() string {
+	return "loopback"
+}
+
+func (a loopbackAddr) String() string {
+	return string(a)
+}
+
+// UseLoopback makes the peer listen and dial on the loopback network
+// instead of QUIC, which must be set before listening.
+func (me *Peer) UseLoopback(n *LoopbackNetwork) {
+	me.loopback = n
+}
+
+func (me *Peer) newServerTransport() Transport {
+	if me.loopback != nil {
+		return me.loopback.server(me.Address)
+	}
+	transport := newQuicServer(me.Address, me.transportKeys)
+	if me.mutualTLS {
+		transport.tls.ClientAuth = tls.RequireAnyClientCert
+		transport.tls.VerifyPeerCertificate = me.endorsementVerifier(crypto.Hash{})
+	}
+	return transport
+}
+
+// newClientTransport dials the addr, and pins the transport keys announced
+// by the peer id if present.
+func (me *Peer) newClientTransport(addr string, id crypto.Hash) (Transport, error) {
+	if me.loopback != nil {
+		return me.loopback.client(me.Address, addr), nil
+	}
+	transport, err := NewQuicClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	var verifiers []func([][]byte, [][]*x509.Certificate) error
+	if id.HasValue() {
+		verifiers = append(verifiers, me.transportPins.verifier(id))
+	}
+	if me.mutualTLS {
+		transport.tls.GetClientCertificate = me.transportKeys.getClientCertificate
+		verifiers = append(verifiers, me.endorsementVerifier(id))
+	}
+	transport.tls.VerifyPeerCertificate = func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+		for _, verify := range verifiers {
+			if err := verify(rawCerts, chains); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return transport, nil
+}
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoopback(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	n := NewLoopbackNetwork()
+	server := n.server("127.0.0.1:7001")
+	assert.Nil(server.Listen())
+	assert.NotNil(n.server("127.0.0.1:7001").Listen())
+	defer server.Close()
+
+	_, err := n.client("127.0.0.1:7002", "127.0.0.1:7003").Dial(ctx)
+	assert.NotNil(err)
+
+	accepted := make(chan Client)
+	go func() {
+		c, err := server.Accept(ctx)
+		assert.Nil(err)
+		accepted <- c
+	}()
+	client, err := n.client("127.0.0.1:7002", "127.0.0.1:7001").Dial(ctx)
+	assert.Nil(err)
+	remote := <-accepted
+	assert.Equal("127.0.0.1:7002", remote.RemoteAddr().String())
+	assert.Equal("127.0.0.1:7001", client.RemoteAddr().String())
+
+	assert.Nil(client.Send([]byte("hello")))
+	msg, err := remote.Receive()
+	assert.Nil(err)
+	assert.Equal("hello", string(msg.Data))
+	assert.Equal(uint8(TransportMessageVersion), msg.Version)
+
+	n.SetLatency(50 * time.Millisecond)
+	start := time.Now()
+	assert.Nil(client.Send([]byte("latency")))
+	msg, err = remote.Receive()
+	assert.Nil(err)
+	assert.Equal("latency", string(msg.Data))
+	assert.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+
+	n.Partition([]string{"127.0.0.1:7001"}, []string{"127.0.0.1:7002"})
+	assert.NotNil(client.Send([]byte("partition")))
+	_, err = remote.Receive()
+	assert.NotNil(err)
+	_, err = n.client("127.0.0.1:7002", "127.0.0.1:7001").Dial(ctx)
+	assert.NotNil(err)
+	assert.True(n.reachable("127.0.0.1:7002", "127.0.0.1:7009"))
+
+	n.Heal()
+	assert.True(n.reachable("127.0.0.1:7001", "127.0.0.1:7002"))
+	server.Close()
+	_, err = server.Accept(ctx)
+	assert.NotNil(err)
+	_, err = n.client("127.0.0.1:7002", "127.0.0.1:7001").Dial(ctx)
+	assert.NotNil(err)
+}
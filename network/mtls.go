@@ -0,0 +1,78 @@
+package network
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// transportEndorsementExtension is the certificate extension with the signer
+// endorsement of the certificate public key, for the mutual TLS.
+var transportEndorsementExtension = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57350, 1, 1}
+
+// EnableMutualTLS makes both ends of the peer connections present the
+// certificates endorsed by their signer keys, so the transport encryption
+// and the peer identity are validated in the TLS handshake, before any
+// consensus message is processed. It must be called before listening, and
+// all the peers must enable it, otherwise they can't connect each other.
+func (me *Peer) EnableMutualTLS() {
+	me.mutualTLS = true
+	me.transportKeys = newEndorsedTransportKeys(me.handle.EndorseTransportKey)
+}
+
+// endorsementVerifier verifies the peer certificate is endorsed by a valid
+// signer, and by the peer id if present.
+func (me *Peer) endorsementVerifier(id crypto.Hash) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		peerId, err := endorsedPeerId(rawCerts, me.handle.VerifyTransportEndorsement)
+		if err != nil {
+			return err
+		}
+		if id.HasValue() && peerId != id {
+			return fmt.Errorf("peer %s transport certificate endorsed by %s", id, peerId)
+		}
+		return nil
+	}
+}
+
+// verifyConnectionPeer ensures the authenticated peer is the one endorsed
+// the certificate of the accepted connection.
+func (me *Peer) verifyConnectionPeer(client Client, id crypto.Hash) error {
+	if !me.mutualTLS {
+		return nil
+	}
+	c, ok := client.(*QuicClient)
+	if !ok {
+		return nil
+	}
+	var rawCerts [][]byte
+	for _, cert := range c.session.ConnectionState().TLS.PeerCertificates {
+		rawCerts = append(rawCerts, cert.Raw)
+	}
+	peerId, err := endorsedPeerId(rawCerts, me.handle.VerifyTransportEndorsement)
+	if err != nil {
+		return err
+	}
+	if peerId != id {
+		return fmt.Errorf("peer %s transport certificate endorsed by %s", id, peerId)
+	}
+	return nil
+}
+
+func endorsedPeerId(rawCerts [][]byte, verify func(endorsement, spki []byte) (crypto.Hash, error)) (crypto.Hash, error) {
+	if len(rawCerts) == 0 {
+		return crypto.Hash{}, fmt.Errorf("transport certificate missing")
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(transportEndorsementExtension) {
+			return verify(ext.Value, cert.RawSubjectPublicKeyInfo)
+		}
+	}
+	return crypto.Hash{}, fmt.Errorf("transport certificate endorsement missing")
+}
@@ -0,0 +1,52 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportEndorsement(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := crypto.NewHash([]byte("transport-endorsement-signer"))
+	endorse := func(spki []byte) []byte {
+		h := crypto.NewHash(append(signer[:], spki...))
+		return h[:]
+	}
+	verify := func(endorsement, spki []byte) (crypto.Hash, error) {
+		if !bytes.Equal(endorsement, endorse(spki)) {
+			return crypto.Hash{}, fmt.Errorf("invalid endorsement")
+		}
+		return signer, nil
+	}
+
+	keys := newEndorsedTransportKeys(endorse)
+	cert, _ := keys.getClientCertificate(nil)
+	id, err := endorsedPeerId(cert.Certificate, verify)
+	assert.Nil(err)
+	assert.Equal(signer, id)
+
+	keys.rotate(time.Minute, time.Now())
+	id, err = endorsedPeerId(keys.next.Certificate, verify)
+	assert.Nil(err)
+	assert.Equal(signer, id)
+
+	plain := generateCertificate()
+	_, err = endorsedPeerId(plain.Certificate, verify)
+	assert.NotNil(err)
+	_, err = endorsedPeerId(nil, verify)
+	assert.NotNil(err)
+	_, err = endorsedPeerId([][]byte{[]byte("certificate")}, verify)
+	assert.NotNil(err)
+
+	other := generateEndorsedCertificate(func(spki []byte) []byte {
+		return endorse(append(spki, 0))
+	})
+	_, err = endorsedPeerId(other.Certificate, verify)
+	assert.NotNil(err)
+}
@@ -0,0 +1,192 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	PartitionActionDetect = "detect"
+	PartitionActionResync = "resync"
+	PartitionActionHeal   = "heal"
+
+	partitionEventsLimit = 256
+)
+
+type PartitionEvent struct {
+	Action    string
+	Height    uint64
+	Ahead     int
+	Neighbors int
+	Timestamp time.Time
+}
+
+type PartitionState struct {
+	Partitioned bool
+	Since       time.Time
+	Height      uint64
+	Advanced    time.Time
+	Ahead       int
+	Neighbors   int
+	Resyncs     int
+	Events      []*PartitionEvent
+}
+
+// partitionDetector compares the local graph height with the sync points
+// of the neighbors. If the local graph stops advancing for the timeout while
+// a majority of the neighbors are ahead, the node is likely partitioned from
+// the network, then it re-handshakes all the neighbors and advertises a lower
+// graph to them periodically, so they push the snapshots from a wider window.
+type partitionDetector struct {
+	sync.Mutex
+	timeout     time.Duration
+	height      uint64
+	advanced    time.Time
+	remote      map[crypto.Hash]uint64
+	partitioned bool
+	since       time.Time
+	resynced    time.Time
+	resyncs     int
+	ahead       int
+	neighbors   int
+	events      []*PartitionEvent
+}
+
+func newPartitionDetector() *partitionDetector {
+	return &partitionDetector{
+		remote:   make(map[crypto.Hash]uint64),
+		advanced: time.Now(),
+	}
+}
+
+func (d *partitionDetector) update(p *Peer, points []*SyncPoint) {
+	if d.timeout <= 0 {
+		return
+	}
+	d.Lock()
+	defer d.Unlock()
+
+	d.remote[p.IdForNetwork] = syncPointsHeight(points)
+}
+
+// check returns true when a resync should be triggered, the first time a
+// partition is detected and every timeout while it lasts.
+func (d *partitionDetector) check(neighbors []*Peer, local []*SyncPoint, now time.Time) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	height := syncPointsHeight(local)
+	if height > d.height {
+		d.height, d.advanced = height, now
+	}
+	ahead := 0
+	for _, p := range neighbors {
+		if d.remote[p.IdForNetwork] > d.height {
+			ahead++
+		}
+	}
+	d.ahead, d.neighbors = ahead, len(neighbors)
+
+	stalled := d.advanced.Add(d.timeout).Before(now)
+	majority := len(neighbors) > 0 && ahead*2 > len(neighbors)
+	if !d.partitioned {
+		if !stalled || !majority {
+			return false
+		}
+		d.partitioned, d.since = true, now
+		d.record(PartitionActionDetect, now)
+	} else if !stalled || !majority {
+		d.partitioned = false
+		d.record(PartitionActionHeal, now)
+		return false
+	} else if d.resynced.Add(d.timeout).After(now) {
+		return false
+	}
+	d.resynced = now
+	d.resyncs++
+	d.record(PartitionActionResync, now)
+	return true
+}
+
+func (d *partitionDetector) record(action string, now time.Time) {
+	logger.Printf("network.partition %s height %d advanced %s ahead %d/%d\n", action, d.height, d.advanced, d.ahead, d.neighbors)
+	d.events = append(d.events, &PartitionEvent{
+		Action:    action,
+		Height:    d.height,
+		Ahead:     d.ahead,
+		Neighbors: d.neighbors,
+		Timestamp: now,
+	})
+	if len(d.events) > partitionEventsLimit {
+		d.events = d.events[len(d.events)-partitionEventsLimit:]
+	}
+}
+
+// graph lowers the local sync points while partitioned, the neighbors then
+// compare their graph from earlier rounds and push more snapshots to us.
+func (d *partitionDetector) graph(points []*SyncPoint) []*SyncPoint {
+	d.Lock()
+	defer d.Unlock()
+
+	if !d.partitioned {
+		return points
+	}
+	window := config.SnapshotReferenceThreshold
+	lowered := make([]*SyncPoint, len(points))
+	for i, p := range points {
+		lowered[i] = &SyncPoint{NodeId: p.NodeId}
+		if p.Number > window {
+			lowered[i].Number = p.Number - window
+		}
+	}
+	return lowered
+}
+
+func (me *Peer) SetPartitionTimeout(timeout time.Duration) {
+	me.partition.timeout = timeout
+}
+
+func (me *Peer) PartitionState() *PartitionState {
+	me.partition.Lock()
+	defer me.partition.Unlock()
+
+	d := me.partition
+	state := &PartitionState{
+		Partitioned: d.partitioned,
+		Since:       d.since,
+		Height:      d.height,
+		Advanced:    d.advanced,
+		Ahead:       d.ahead,
+		Neighbors:   d.neighbors,
+		Resyncs:     d.resyncs,
+		Events:      make([]*PartitionEvent, len(d.events)),
+	}
+	for i, e := range d.events {
+		ce := *e
+		state.Events[i] = &ce
+	}
+	return state
+}
+
+func (me *Peer) partitionLoop() {
+	if me.partition.timeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(me.partition.timeout / 4)
+	defer ticker.Stop()
+
+	for !me.closing {
+		<-ticker.C
+		neighbors := me.neighbors.Slice()
+		if !me.partition.check(neighbors, me.handle.BuildGraph(), time.Now()) {
+			continue
+		}
+		for _, p := range neighbors {
+			p.resync = true
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionDetector(t *testing.T) {
+	assert := assert.New(t)
+
+	a := &Peer{IdForNetwork: crypto.NewHash([]byte("a"))}
+	b := &Peer{IdForNetwork: crypto.NewHash([]byte("b"))}
+	c := &Peer{IdForNetwork: crypto.NewHash([]byte("c"))}
+	neighbors := []*Peer{a, b, c}
+	graph := func(rounds ...uint64) []*SyncPoint {
+		points := make([]*SyncPoint, len(rounds))
+		for i, n := range rounds {
+			points[i] = &SyncPoint{NodeId: crypto.NewHash([]byte{byte(i)}), Number: n}
+		}
+		return points
+	}
+
+	detector := newPartitionDetector()
+	detector.update(a, graph(30, 70))
+	assert.Len(detector.remote, 0)
+
+	detector.timeout = time.Minute
+	now := time.Now()
+	local := graph(20, 30)
+	assert.False(detector.check(neighbors, local, now))
+	detector.update(a, graph(30, 70))
+	detector.update(b, graph(30, 70))
+	detector.update(c, graph(20, 30))
+	assert.False(detector.check(neighbors, local, now.Add(time.Second*30)))
+	assert.Equal(2, detector.ahead)
+	assert.Equal(local, detector.graph(local))
+
+	now = now.Add(time.Second * 90)
+	assert.True(detector.check(neighbors, local, now))
+	assert.True(detector.partitioned)
+	lowered := detector.graph(local)
+	assert.Equal(20-config.SnapshotReferenceThreshold, lowered[0].Number)
+	assert.Equal(30-config.SnapshotReferenceThreshold, lowered[1].Number)
+	assert.False(detector.check(neighbors, local, now.Add(time.Second*30)))
+	assert.True(detector.check(neighbors, local, now.Add(time.Second*90)))
+	assert.Equal(2, detector.resyncs)
+
+	now = now.Add(time.Second * 120)
+	assert.False(detector.check(neighbors, graph(30, 70), now))
+	assert.False(detector.partitioned)
+	assert.Equal(local, detector.graph(local))
+	assert.Len(detector.events, 4)
+	assert.Equal(PartitionActionDetect, detector.events[0].Action)
+	assert.Equal(PartitionActionResync, detector.events[1].Action)
+	assert.Equal(PartitionActionResync, detector.events[2].Action)
+	assert.Equal(PartitionActionHeal, detector.events[3].Action)
+}
@@ -29,12 +29,36 @@ type Peer struct {
 	handle          SyncHandle
 	transport       Transport
 	gossipNeighbors bool
-	highRing        *util.RingBuffer
-	normalRing      *util.RingBuffer
+	sendRings       [sendClassCount]*util.RingBuffer
 	syncRing        *util.RingBuffer
+	queue           *sendQueue
 	closing         bool
+	resync          bool
+	unreachable     bool
 	ops             chan struct{}
 	stn             chan struct{}
+
+	transportKeys        *transportKeys
+	transportPins        *transportPinMap
+	transportKeyRotation time.Duration
+	transportKeyOverlap  time.Duration
+	mutualTLS            bool
+
+	discovery bool
+	routes    *routingTable
+	stale     *staleTracker
+	partition *partitionDetector
+	relay     *relayService
+	link      *peerLink
+	protocol  *peerProtocol
+	chaos     *chaos
+	loopback  *LoopbackNetwork
+
+	confirmed    *snapshotBloom
+	gossipFilter *gossipFilterCounter
+	graphs       *graphExchange
+
+	addressBookPings *util.RateLimiter
 }
 
 type SyncPoint struct {
@@ -74,7 +98,7 @@ func (me *Peer) PingNeighbor(addr string) error {
 
 func (me *Peer) pingPeerStream(addr string) error {
 	logger.Verbosef("PING OPEN PEER STREAM %s\n", addr)
-	transport, err := NewQuicClient(addr)
+	transport, err := me.newClientTransport(addr, crypto.Hash{})
 	if err != nil {
 		return err
 	}
@@ -100,6 +124,10 @@ func (me *Peer) AddNeighbor(idForNetwork crypto.Hash, addr string) (*Peer, error
 	} else if a.Port < 80 || a.IP == nil {
 		return nil, fmt.Errorf("invalid address %s %d %s", addr, a.Port, a.IP)
 	}
+	err := me.checkStalePeerRefused(idForNetwork)
+	if err != nil {
+		return nil, err
+	}
 	old := me.neighbors.Get(idForNetwork)
 	if old != nil && old.Address == addr {
 		return old, nil
@@ -107,7 +135,7 @@ func (me *Peer) AddNeighbor(idForNetwork crypto.Hash, addr string) (*Peer, error
 		old.disconnect()
 	}
 
-	peer := NewPeer(nil, idForNetwork, addr, false)
+	peer := newPeer(nil, idForNetwork, addr, false, me.queue)
 	me.neighbors.Set(idForNetwork, peer)
 	go me.openPeerStreamLoop(peer)
 	go me.syncToNeighborLoop(peer)
@@ -120,14 +148,18 @@ func (me *Peer) Neighbors() []*Peer {
 
 func (p *Peer) disconnect() {
 	p.closing = true
-	p.highRing.Dispose()
-	p.normalRing.Dispose()
+	p.disposeSendRings()
 	p.syncRing.Dispose()
 	<-p.ops
 	<-p.stn
 }
 
 func NewPeer(handle SyncHandle, idForNetwork crypto.Hash, addr string, gossipNeighbors bool) *Peer {
+	queue := newSendQueue(peerQueueDefaultSize, config.OverflowDropNew)
+	return newPeer(handle, idForNetwork, addr, gossipNeighbors, queue)
+}
+
+func newPeer(handle SyncHandle, idForNetwork crypto.Hash, addr string, gossipNeighbors bool, queue *sendQueue) *Peer {
 	peer := &Peer{
 		IdForNetwork:    idForNetwork,
 		Address:         addr,
@@ -135,16 +167,29 @@ func NewPeer(handle SyncHandle, idForNetwork crypto.Hash, addr string, gossipNei
 		gossipRound:     &neighborMap{m: make(map[crypto.Hash]*Peer)},
 		pingFilter:      &neighborMap{m: make(map[crypto.Hash]*Peer)},
 		gossipNeighbors: gossipNeighbors,
-		highRing:        util.NewRingBuffer(1024),
-		normalRing:      util.NewRingBuffer(1024),
+		sendRings:       newSendRings(queue.size),
 		syncRing:        util.NewRingBuffer(1024),
+		queue:           queue,
 		handle:          handle,
 		ops:             make(chan struct{}),
 		stn:             make(chan struct{}),
+		transportPins:   &transportPinMap{m: make(map[crypto.Hash]*transportPin)},
+		routes:          newRoutingTable(idForNetwork),
+		stale:           newStaleTracker(),
+		partition:       newPartitionDetector(),
+		relay:           newRelayService(),
+		link:            &peerLink{},
+		protocol:        &peerProtocol{},
+		confirmed:       newSnapshotBloom(),
+		gossipFilter:    &gossipFilterCounter{},
+		graphs:          &graphExchange{},
+
+		addressBookPings: util.NewRateLimiter(addressBookPingRate, addressBookPingBurst),
 	}
 	peer.ctx = context.Background() // FIXME use real context
 	if handle != nil {
 		peer.snapshotsCaches = &confirmMap{cache: handle.GetCacheStore()}
+		peer.transportKeys = newTransportKeys()
 	}
 	return peer
 }
@@ -152,8 +197,7 @@ func NewPeer(handle SyncHandle, idForNetwork crypto.Hash, addr string, gossipNei
 func (me *Peer) Teardown() {
 	me.closing = true
 	me.transport.Close()
-	me.highRing.Dispose()
-	me.normalRing.Dispose()
+	me.disposeSendRings()
 	me.syncRing.Dispose()
 	neighbors := me.neighbors.Slice()
 	var wg sync.WaitGroup
@@ -169,16 +213,16 @@ func (me *Peer) Teardown() {
 }
 
 func (me *Peer) ListenNeighbors() error {
-	transport, err := NewQuicServer(me.Address)
-	if err != nil {
-		return err
-	}
-	me.transport = transport
-
-	err = me.transport.Listen()
+	me.transport = me.newServerTransport()
+	err := me.transport.Listen()
 	if err != nil {
 		return err
 	}
+	go me.rotateTransportKeysLoop()
+	go me.discoveryLoop()
+	go me.staleEvictionLoop()
+	go me.partitionLoop()
+	go me.relayPingLoop()
 
 	go func() {
 		ticker := time.NewTicker(time.Duration(config.SnapshotRoundGap))
@@ -187,7 +231,12 @@ func (me *Peer) ListenNeighbors() error {
 		for !me.closing {
 			me.gossipRound.Clear()
 			rand.Seed(time.Now().UnixNano())
-			neighbors := me.neighbors.Slice()
+			var neighbors []*Peer
+			for _, p := range me.neighbors.Slice() {
+				if !me.stale.demoted(p.IdForNetwork) {
+					neighbors = append(neighbors, p)
+				}
+			}
 			for i := range neighbors {
 				j := rand.Intn(i + 1)
 				neighbors[i], neighbors[j] = neighbors[j], neighbors[i]
@@ -231,21 +280,29 @@ func (me *Peer) openPeerStreamLoop(p *Peer) {
 			logger.Verbosef("neighbor open stream %s error %s\n", p.Address, err.Error())
 		}
 		resend = msg
+		if p.unreachable && me.relayPeerMessages(p, time.Now().Add(relayRedialInterval)) {
+			continue
+		}
 		time.Sleep(1 * time.Second)
 	}
 }
 
 func (me *Peer) openPeerStream(p *Peer, resend *ChanMsg) (*ChanMsg, error) {
 	logger.Verbosef("OPEN PEER STREAM %s\n", p.Address)
-	transport, err := NewQuicClient(p.Address)
+	transport, err := me.newClientTransport(p.Address, p.IdForNetwork)
 	if err != nil {
 		return nil, err
 	}
+	start := time.Now()
 	client, err := transport.Dial(me.ctx)
 	if err != nil {
+		p.unreachable = true
 		return nil, err
 	}
 	defer client.Close()
+	p.unreachable = false
+	p.link.dialed(time.Since(start), time.Now())
+	defer p.link.disconnected()
 	logger.Verbosef("DIAL PEER STREAM %s\n", p.Address)
 
 	err = client.Send(buildAuthenticationMessage(me.handle.BuildAuthenticationMessage()))
@@ -253,6 +310,32 @@ func (me *Peer) openPeerStream(p *Peer, resend *ChanMsg) (*ChanMsg, error) {
 		return nil, err
 	}
 	logger.Verbosef("AUTH PEER STREAM %s\n", p.Address)
+	err = client.Send(buildHelloMessage(me.LocalHello()))
+	if err != nil {
+		return nil, err
+	}
+	err = client.Send(me.buildTransportKeyMessage())
+	if err != nil {
+		return nil, err
+	}
+	if me.canSendMessage(p, PeerMessageTypePeerRecords) {
+		err = client.Send(buildPeerRecordsMessage([][]byte{me.handle.BuildPeerRecord()}))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if me.relay.isRelay(p.IdForNetwork) {
+		err = client.Send(buildRelayRegisterMessage())
+		if err != nil {
+			return nil, err
+		}
+		go me.relayReceiveLoop(p, client)
+	}
+	defer func() {
+		if me.closing {
+			client.Send(buildGoodbyeMessage())
+		}
+	}()
 
 	if resend != nil {
 		logger.Verbosef("RESEND PEER STREAM %s\n", hex.EncodeToString(resend.key))
@@ -270,12 +353,23 @@ func (me *Peer) openPeerStream(p *Peer, resend *ChanMsg) (*ChanMsg, error) {
 	gossipNeighborsTicker := time.NewTicker(time.Duration(config.SnapshotRoundGap * 100))
 	defer gossipNeighborsTicker.Stop()
 
+	upgradeIntentTicker := time.NewTicker(time.Duration(config.SnapshotRoundGap * 100))
+	defer upgradeIntentTicker.Stop()
+
+	transportKeyTicker := time.NewTicker(transportKeyAnnounceInterval)
+	defer transportKeyTicker.Stop()
+
+	p.graphs.reset()
 	for !me.closing && !p.closing {
-		gd, hd, nd := false, false, false
+		if p.resync {
+			p.resync = false
+			return nil, fmt.Errorf("PEER RESYNC")
+		}
+		gd, sd := false, false
 
 		select {
 		case <-graphTicker.C:
-			msg := buildGraphMessage(me.handle.BuildGraph())
+			msg := me.buildGraphExchangeMessage(p, me.partition.graph(me.handle.BuildGraph()))
 			err := client.Send(msg)
 			if err != nil {
 				return nil, err
@@ -288,46 +382,36 @@ func (me *Peer) openPeerStream(p *Peer, resend *ChanMsg) (*ChanMsg, error) {
 					return nil, err
 				}
 			}
-		default:
-			gd = true
-		}
-
-		item, err := p.highRing.Poll(false)
-		if err != nil {
-			return nil, err
-		} else if item == nil {
-			hd = true
-		} else {
-			msg := item.(*ChanMsg)
-			if !me.snapshotsCaches.contains(msg.key, time.Minute) {
-				err := client.Send(msg.data)
+		case <-upgradeIntentTicker.C:
+			for _, intent := range me.handle.BuildUpgradeIntentMessages() {
+				err := client.Send(buildUpgradeIntentMessage(intent))
 				if err != nil {
-					return msg, err
+					return nil, err
 				}
-				me.snapshotsCaches.store(msg.key, time.Now())
 			}
-		}
-		if !hd {
-			continue
+		case <-transportKeyTicker.C:
+			err := client.Send(me.buildTransportKeyMessage())
+			if err != nil {
+				return nil, err
+			}
+		default:
+			gd = true
 		}
 
-		item, err = p.normalRing.Poll(false)
+		msg, err := p.pollSendRings()
 		if err != nil {
 			return nil, err
-		} else if item == nil {
-			nd = true
-		} else {
-			msg := item.(*ChanMsg)
-			if !me.snapshotsCaches.contains(msg.key, time.Minute) {
-				err := client.Send(msg.data)
-				if err != nil {
-					return msg, err
-				}
-				me.snapshotsCaches.store(msg.key, time.Now())
+		} else if msg == nil {
+			sd = true
+		} else if !me.snapshotsCaches.contains(msg.key, time.Minute) {
+			err := me.sendWithChaos(client, msg.data)
+			if err != nil {
+				return msg, err
 			}
+			me.snapshotsCaches.store(msg.key, time.Now())
 		}
 
-		if gd && hd && nd {
+		if gd && sd {
 			time.Sleep(100 * time.Millisecond)
 		}
 	}
@@ -359,6 +443,18 @@ func (me *Peer) acceptNeighborConnection(client Client) error {
 		if err != nil {
 			return fmt.Errorf("parseNetworkMessage %s %s", peer.IdForNetwork, err.Error())
 		}
+		peer.link.receive(time.Now())
+		if msg.Type == PeerMessageTypeGoodbye {
+			logger.Printf("acceptNeighborConnection(%s) goodbye\n", peer.IdForNetwork)
+			return nil
+		}
+		if msg.Type == PeerMessageTypeRelayRegister {
+			err := me.registerRelayClient(peer, client)
+			if err != nil {
+				logger.Verbosef("acceptNeighborConnection(%s) relay register error %s\n", peer.IdForNetwork, err)
+			}
+			continue
+		}
 
 		select {
 		case receive <- msg:
@@ -392,6 +488,11 @@ func (me *Peer) authenticateNeighbor(client Client) (*Peer, error) {
 			auth <- err
 			return
 		}
+		err = me.verifyConnectionPeer(client, id)
+		if err != nil {
+			auth <- err
+			return
+		}
 
 		peer, err = me.AddNeighbor(id, addr)
 		if err != nil {
@@ -422,18 +523,21 @@ func (me *Peer) sendHighToPeer(idForNetwork crypto.Hash, key, data []byte) error
 	if peer == nil {
 		return nil
 	}
+	if !me.canSendMessage(peer, data[0]) {
+		return nil
+	}
 	if me.snapshotsCaches.contains(key, time.Minute) {
 		return nil
 	}
 
-	success, _ := peer.highRing.Offer(&ChanMsg{key, data})
+	success, _ := me.queue.offer(peer.sendRings[sendClassConsensus], &ChanMsg{key, data})
 	if !success {
 		return fmt.Errorf("peer send high timeout")
 	}
 	return nil
 }
 
-func (me *Peer) sendSnapshotMessageToPeer(idForNetwork crypto.Hash, snap crypto.Hash, typ byte, data []byte) error {
+func (me *Peer) sendSnapshotMessageToPeer(idForNetwork crypto.Hash, snap crypto.Hash, typ byte, data []byte, class sendClass) error {
 	if idForNetwork == me.IdForNetwork {
 		return nil
 	}
@@ -447,9 +551,9 @@ func (me *Peer) sendSnapshotMessageToPeer(idForNetwork crypto.Hash, snap crypto.
 		return nil
 	}
 
-	success, _ := peer.normalRing.Offer(&ChanMsg{key, data})
+	success, _ := me.queue.offerClass(peer.sendRings[class], &ChanMsg{key, data}, class)
 	if !success {
-		return fmt.Errorf("peer send normal timeout")
+		return fmt.Errorf("peer send %s timeout", class)
 	}
 	return nil
 }
@@ -492,6 +596,17 @@ func (m *neighborMap) Set(key crypto.Hash, v *Peer) {
 	m.m[key] = v
 }
 
+func (m *neighborMap) Delete(key crypto.Hash, v *Peer) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.m[key] != v {
+		return false
+	}
+	delete(m.m, key)
+	return true
+}
+
 func (m *neighborMap) Slice() []*Peer {
 	m.Lock()
 	defer m.Unlock()
@@ -0,0 +1,124 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// The hello message is sent right after the authentication on each outbound
+// stream, with the protocol version and the capabilities bitmap of the
+// sender. A message type in peerMessageCapabilities is only sent to a peer
+// advertised the capability, and accepted when this peer advertises it too,
+// so old peers without hello are never sent any of them. The transport key
+// and relay types are not gated, because they are sent before the hello of
+// the neighbor is known, neither are the informative upgrade intent and
+// goodbye, and old peers ignore them as unknown types, the same as the hello.
+const (
+	PeerProtocolVersion = 1
+
+	PeerCapabilityCompression = 1 << 0
+	PeerCapabilityBatching    = 1 << 1
+	PeerCapabilityQuic        = 1 << 2
+	PeerCapabilityFastSync    = 1 << 3
+	PeerCapabilityDiscovery   = 1 << 4
+	PeerCapabilityGraphDelta  = 1 << 5
+
+	peerHelloSize = 12
+)
+
+var peerMessageCapabilities = map[uint8]uint64{
+	PeerMessageTypeFindPeers:   PeerCapabilityDiscovery,
+	PeerMessageTypePeerRecords: PeerCapabilityDiscovery,
+	PeerMessageTypeGraphDelta:  PeerCapabilityGraphDelta,
+	PeerMessageTypeGraphResync: PeerCapabilityGraphDelta,
+}
+
+type PeerHello struct {
+	Version      uint32
+	Capabilities uint64
+}
+
+type peerProtocol struct {
+	sync.RWMutex
+	hello *PeerHello
+}
+
+func (pp *peerProtocol) set(h *PeerHello) (gained uint64) {
+	pp.Lock()
+	defer pp.Unlock()
+
+	var old uint64
+	if pp.hello != nil {
+		old = pp.hello.Capabilities
+	}
+	pp.hello = h
+	return h.Capabilities &^ old
+}
+
+func (pp *peerProtocol) get() *PeerHello {
+	pp.RLock()
+	defer pp.RUnlock()
+
+	return pp.hello
+}
+
+func buildHelloMessage(h *PeerHello) []byte {
+	data := make([]byte, peerHelloSize)
+	binary.BigEndian.PutUint32(data[:4], h.Version)
+	binary.BigEndian.PutUint64(data[4:], h.Capabilities)
+	return buildMessage(PeerMessageTypeHello, data)
+}
+
+// parseHello ignores the bytes after the known fields, which are reserved
+// for the newer protocol versions.
+func parseHello(data []byte) (*PeerHello, error) {
+	if len(data) < peerHelloSize {
+		return nil, fmt.Errorf("invalid hello message size %d", len(data))
+	}
+	h := &PeerHello{
+		Version:      binary.BigEndian.Uint32(data[:4]),
+		Capabilities: binary.BigEndian.Uint64(data[4:]),
+	}
+	if h.Version == 0 {
+		return nil, fmt.Errorf("invalid hello protocol version %d", h.Version)
+	}
+	return h, nil
+}
+
+func (me *Peer) LocalHello() *PeerHello {
+	caps := uint64(PeerCapabilityCompression | PeerCapabilityQuic | PeerCapabilityGraphDelta)
+	if me.discovery {
+		caps |= PeerCapabilityDiscovery
+	}
+	return &PeerHello{Version: PeerProtocolVersion, Capabilities: caps}
+}
+
+// NegotiatedCapabilities returns the capabilities advertised by both self
+// and the neighbor, which is none before the neighbor hello.
+func (me *Peer) NegotiatedCapabilities(p *Peer) uint64 {
+	h := p.protocol.get()
+	if h == nil {
+		return 0
+	}
+	return h.Capabilities & me.LocalHello().Capabilities
+}
+
+func (me *Peer) canSendMessage(p *Peer, typ uint8) bool {
+	c, gated := peerMessageCapabilities[typ]
+	return !gated || me.NegotiatedCapabilities(p)&c == c
+}
+
+func (me *Peer) canAcceptMessage(typ uint8) bool {
+	c, gated := peerMessageCapabilities[typ]
+	return !gated || me.LocalHello().Capabilities&c == c
+}
+
+func (me *Peer) handleHello(p *Peer, h *PeerHello) {
+	gained := p.protocol.set(h)
+	if gained&PeerCapabilityDiscovery != 0 && me.canSendMessage(p, PeerMessageTypePeerRecords) {
+		key := append(p.IdForNetwork[:], me.IdForNetwork[:]...)
+		key = append(key, 'D', 'H', 'T', PeerMessageTypeHello)
+		me.sendHighToPeer(p.IdForNetwork, key, buildPeerRecordsMessage([][]byte{me.handle.BuildPeerRecord()}))
+	}
+}
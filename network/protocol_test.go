@@ -0,0 +1,69 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerProtocolNegotiation(t *testing.T) {
+	assert := assert.New(t)
+
+	me := NewPeer(nil, crypto.NewHash([]byte("me")), "127.0.0.1:7001", false)
+	me.SetDiscovery(true)
+	legacy := NewPeer(nil, crypto.NewHash([]byte("legacy")), "127.0.0.1:7002", false)
+	static := NewPeer(nil, crypto.NewHash([]byte("static")), "127.0.0.1:7003", false)
+	modern := NewPeer(nil, crypto.NewHash([]byte("modern")), "127.0.0.1:7004", false)
+	modern.SetDiscovery(true)
+	future := NewPeer(nil, crypto.NewHash([]byte("future")), "127.0.0.1:7005", false)
+
+	local := me.LocalHello()
+	assert.Equal(uint32(PeerProtocolVersion), local.Version)
+	assert.Equal(uint64(PeerCapabilityCompression|PeerCapabilityQuic|PeerCapabilityDiscovery|PeerCapabilityGraphDelta), local.Capabilities)
+	assert.Equal(uint64(PeerCapabilityCompression|PeerCapabilityQuic|PeerCapabilityGraphDelta), static.LocalHello().Capabilities)
+
+	for _, p := range []*Peer{static, modern} {
+		msg, err := parseNetworkMessage(TransportMessageVersion, buildHelloMessage(p.LocalHello()))
+		assert.Nil(err)
+		assert.Equal(uint8(PeerMessageTypeHello), msg.Type)
+		assert.Equal(p.LocalHello(), msg.Hello)
+		assert.Equal(msg.Hello.Capabilities, p.protocol.set(msg.Hello))
+	}
+
+	hello := buildHelloMessage(&PeerHello{Version: 2, Capabilities: 0xffff})
+	msg, err := parseNetworkMessage(TransportMessageVersion, append(hello, 1, 2, 3))
+	assert.Nil(err)
+	assert.Equal(uint32(2), msg.Hello.Version)
+	assert.Equal(uint64(0xffff), future.protocol.set(msg.Hello))
+	assert.Equal(uint64(0), future.protocol.set(msg.Hello))
+
+	_, err = parseNetworkMessage(TransportMessageVersion, hello[:peerHelloSize])
+	assert.NotNil(err)
+	_, err = parseNetworkMessage(TransportMessageVersion, buildHelloMessage(&PeerHello{}))
+	assert.NotNil(err)
+
+	assert.Equal(uint64(0), me.NegotiatedCapabilities(legacy))
+	assert.Equal(uint64(PeerCapabilityCompression|PeerCapabilityQuic|PeerCapabilityGraphDelta), me.NegotiatedCapabilities(static))
+	assert.Equal(local.Capabilities, me.NegotiatedCapabilities(modern))
+	assert.Equal(local.Capabilities, me.NegotiatedCapabilities(future))
+
+	for _, typ := range []uint8{PeerMessageTypeGraph, PeerMessageTypeSnapshotAnnoucement, PeerMessageTypeTransportKey, PeerMessageTypeHello} {
+		for _, p := range []*Peer{legacy, static, modern, future} {
+			assert.True(me.canSendMessage(p, typ))
+		}
+	}
+	for _, typ := range []uint8{PeerMessageTypeFindPeers, PeerMessageTypePeerRecords} {
+		assert.False(me.canSendMessage(legacy, typ))
+		assert.False(me.canSendMessage(static, typ))
+		assert.True(me.canSendMessage(modern, typ))
+		assert.True(me.canSendMessage(future, typ))
+		assert.True(me.canAcceptMessage(typ))
+		assert.False(static.canAcceptMessage(typ))
+	}
+
+	// the legacy peers parse the unknown hello type without error
+	msg, err = parseNetworkMessage(TransportMessageVersion, []byte{250, 1, 2})
+	assert.Nil(err)
+	assert.Equal(uint8(250), msg.Type)
+}
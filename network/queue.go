@@ -0,0 +1,139 @@
+package network
+
+import (
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/util"
+)
+
+const (
+	peerQueueDefaultSize  = 1024
+	peerQueueBlockTimeout = time.Second
+)
+
+// sendClass is the priority of the outbound messages to a neighbor, each
+// class has its own send ring, and the send loop always drains the higher
+// classes first, so the consensus messages are never queued behind the
+// finalizations or the sync of a slow neighbor.
+type sendClass int
+
+const (
+	sendClassConsensus sendClass = iota
+	sendClassFinalization
+	sendClassSync
+	sendClassCount
+)
+
+func (c sendClass) String() string {
+	switch c {
+	case sendClassConsensus:
+		return "consensus"
+	case sendClassFinalization:
+		return "finalization"
+	case sendClassSync:
+		return "sync"
+	}
+	return "unknown"
+}
+
+// sendQueue is the size and the overflow policy of the send rings of the
+// neighbors, the saturation is counted for all of them, and the block policy
+// waits at most a second to not stall the gossip loops.
+type sendQueue struct {
+	size       int
+	policy     string
+	saturation *util.QueueSaturation
+}
+
+func newSendQueue(size int, policy string) *sendQueue {
+	return &sendQueue{
+		size:       size,
+		policy:     policy,
+		saturation: util.NewQueueSaturation(size),
+	}
+}
+
+// offerClass offers the message to the ring of the class, the sync class
+// always drops the new messages when full, because the graph sync resends
+// them anyway and should never delay the callers.
+func (q *sendQueue) offerClass(ring *util.RingBuffer, msg *ChanMsg, class sendClass) (bool, error) {
+	if class == sendClassSync {
+		return q.offerPolicy(ring, msg, config.OverflowDropNew)
+	}
+	return q.offerPolicy(ring, msg, q.policy)
+}
+
+func (q *sendQueue) offer(ring *util.RingBuffer, msg *ChanMsg) (bool, error) {
+	return q.offerPolicy(ring, msg, q.policy)
+}
+
+func (q *sendQueue) offerPolicy(ring *util.RingBuffer, msg *ChanMsg, policy string) (bool, error) {
+	success, err := ring.Offer(msg)
+	if err != nil {
+		return false, err
+	}
+	if !success {
+		q.saturation.Full()
+	}
+	for start := time.Now(); !success; {
+		switch policy {
+		case config.OverflowDropOldest:
+			if item, _ := ring.Poll(false); item != nil {
+				q.saturation.Drop()
+			}
+		case config.OverflowBlock:
+			if time.Now().Sub(start) < peerQueueBlockTimeout {
+				time.Sleep(10 * time.Millisecond)
+				break
+			}
+			fallthrough
+		default:
+			q.saturation.Drop()
+			return false, nil
+		}
+		success, err = ring.Offer(msg)
+		if err != nil {
+			return false, err
+		}
+	}
+	q.saturation.Offer(int(ring.Len()))
+	return true, nil
+}
+
+func newSendRings(size int) [sendClassCount]*util.RingBuffer {
+	var rings [sendClassCount]*util.RingBuffer
+	for i := range rings {
+		rings[i] = util.NewRingBuffer(uint64(size))
+	}
+	return rings
+}
+
+// pollSendRings returns the next message of the highest class, or nil if
+// all the send rings are empty.
+func (p *Peer) pollSendRings() (*ChanMsg, error) {
+	for _, ring := range p.sendRings {
+		item, err := ring.Poll(false)
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			return item.(*ChanMsg), nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *Peer) disposeSendRings() {
+	for _, ring := range p.sendRings {
+		ring.Dispose()
+	}
+}
+
+func (me *Peer) SetSendQueue(size int, policy string) {
+	me.queue = newSendQueue(size, policy)
+}
+
+func (me *Peer) SendQueueStats() util.QueueSaturationStats {
+	return me.queue.saturation.Stats()
+}
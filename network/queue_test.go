@@ -0,0 +1,110 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	msg := func(b byte) *ChanMsg { return &ChanMsg{key: []byte{b}, data: []byte{b}} }
+
+	q := newSendQueue(2, config.OverflowDropNew)
+	ring := util.NewRingBuffer(uint64(q.size))
+	for i := byte(0); i < 3; i++ {
+		success, err := q.offer(ring, msg(i))
+		assert.Nil(err)
+		assert.Equal(i < 2, success)
+	}
+	item, _ := ring.Poll(false)
+	assert.Equal([]byte{0}, item.(*ChanMsg).data)
+	stats := q.saturation.Stats()
+	assert.Equal(2, stats.Capacity)
+	assert.Equal(2, stats.Peak)
+	assert.Equal(uint64(2), stats.Offered)
+	assert.Equal(uint64(1), stats.Full)
+	assert.Equal(uint64(1), stats.Dropped)
+
+	q = newSendQueue(2, config.OverflowDropOldest)
+	ring = util.NewRingBuffer(uint64(q.size))
+	for i := byte(0); i < 3; i++ {
+		success, err := q.offer(ring, msg(i))
+		assert.Nil(err)
+		assert.True(success)
+	}
+	item, _ = ring.Poll(false)
+	assert.Equal([]byte{1}, item.(*ChanMsg).data)
+	stats = q.saturation.Stats()
+	assert.Equal(uint64(3), stats.Offered)
+	assert.Equal(uint64(1), stats.Dropped)
+
+	q = newSendQueue(2, config.OverflowBlock)
+	ring = util.NewRingBuffer(uint64(q.size))
+	q.offer(ring, msg(0))
+	q.offer(ring, msg(1))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ring.Poll(false)
+	}()
+	success, err := q.offer(ring, msg(2))
+	assert.Nil(err)
+	assert.True(success)
+	assert.Equal(uint64(0), q.saturation.Stats().Dropped)
+
+	start := time.Now()
+	success, err = q.offer(ring, msg(3))
+	assert.Nil(err)
+	assert.False(success)
+	assert.True(time.Now().Sub(start) >= peerQueueBlockTimeout)
+	assert.Equal(uint64(1), q.saturation.Stats().Dropped)
+
+	ring.Dispose()
+	_, err = q.offer(ring, msg(4))
+	assert.Equal(util.ErrDisposed, err)
+}
+
+func TestSendClasses(t *testing.T) {
+	assert := assert.New(t)
+
+	msg := func(b byte) *ChanMsg { return &ChanMsg{key: []byte{b}, data: []byte{b}} }
+
+	q := newSendQueue(2, config.OverflowBlock)
+	p := newPeer(nil, crypto.NewHash([]byte("neighbor")), "127.0.0.1:7001", false, q)
+	for i := byte(0); i < 3; i++ {
+		start := time.Now()
+		success, err := q.offerClass(p.sendRings[sendClassSync], msg(20+i), sendClassSync)
+		assert.Nil(err)
+		assert.Equal(i < 2, success)
+		assert.True(time.Now().Sub(start) < peerQueueBlockTimeout)
+	}
+	q.offerClass(p.sendRings[sendClassFinalization], msg(10), sendClassFinalization)
+	q.offerClass(p.sendRings[sendClassConsensus], msg(0), sendClassConsensus)
+	q.offerClass(p.sendRings[sendClassConsensus], msg(1), sendClassConsensus)
+
+	var order []byte
+	for {
+		m, err := p.pollSendRings()
+		assert.Nil(err)
+		if m == nil {
+			break
+		}
+		order = append(order, m.data[0])
+	}
+	assert.Equal([]byte{0, 1, 10, 20, 21}, order)
+
+	q.offerClass(p.sendRings[sendClassSync], msg(22), sendClassSync)
+	q.offerClass(p.sendRings[sendClassConsensus], msg(2), sendClassConsensus)
+	m, _ := p.pollSendRings()
+	assert.Equal([]byte{2}, m.data)
+	assert.Equal("finalization", sendClassFinalization.String())
+
+	p.disposeSendRings()
+	_, err := p.pollSendRings()
+	assert.Equal(util.ErrDisposed, err)
+}
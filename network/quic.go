@@ -6,9 +6,11 @@ import (
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/binary"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"math/big"
 	"net"
 	"time"
@@ -45,11 +47,17 @@ type QuicTransport struct {
 }
 
 func NewQuicServer(addr string) (*QuicTransport, error) {
-	tlsConf := generateTLSConfig()
+	return newQuicServer(addr, newTransportKeys()), nil
+}
+
+func newQuicServer(addr string, keys *transportKeys) *QuicTransport {
 	return &QuicTransport{
 		addr: addr,
-		tls:  tlsConf,
-	}, nil
+		tls: &tls.Config{
+			GetCertificate: keys.getCertificate,
+			NextProtos:     []string{"mixin-quic-peer"},
+		},
+	}
 }
 
 func NewQuicClient(addr string) (*QuicTransport, error) {
@@ -147,21 +155,17 @@ func (c *QuicClient) Receive() (*TransportMessage, error) {
 	if m.Size > TransportMessageMaxSize {
 		return nil, fmt.Errorf("quic receive invalid message size %d", m.Size)
 	}
-	for {
-		data := make([]byte, int(m.Size)-len(m.Data))
-		s, err = c.receive.Read(data)
-		if err != nil {
-			return nil, err
-		}
-		m.Data = append(m.Data, data[:s]...)
-		if len(m.Data) == int(m.Size) {
-			break
-		}
+	buf := getBuffer(int(m.Size))
+	defer putBuffer(buf)
+	data := (*buf)[:m.Size]
+	_, err = io.ReadFull(c.receive, data)
+	if err != nil {
+		return nil, err
 	}
 
 	switch m.Compression {
 	case TransportCompressionZstd:
-		m.Data, err = c.zstdUnzipper.DecodeAll(m.Data, nil)
+		m.Data, err = c.zstdUnzipper.DecodeAll(data, nil)
 	}
 
 	return &m, err
@@ -172,23 +176,52 @@ func (c *QuicClient) Send(data []byte) error {
 		return fmt.Errorf("quic send invalid message size %d", l)
 	}
 
+	buf := getBuffer(TransportMessageHeaderSize + len(data))
+	defer putBuffer(buf)
+	frame := append(*buf, TransportMessageVersion, TransportCompressionMethod, 0, 0, 0, 0)
 	switch TransportCompressionMethod {
 	case TransportCompressionZstd:
-		data = c.zstdZipper.EncodeAll(data, nil)
+		frame = c.zstdZipper.EncodeAll(data, frame)
+	default:
+		frame = append(frame, data...)
 	}
+	binary.BigEndian.PutUint32(frame[2:], uint32(len(frame)-TransportMessageHeaderSize))
+	*buf = frame
 
 	err := c.send.SetWriteDeadline(time.Now().Add(WriteDeadline))
 	if err != nil {
 		return err
 	}
-	header := []byte{TransportMessageVersion, TransportCompressionMethod, 0, 0, 0, 0}
-	binary.BigEndian.PutUint32(header[2:], uint32(len(data)))
-	_, err = c.send.Write(header)
+	_, err = c.send.Write(frame)
+	return err
+}
+
+// openReverse opens a send stream back to the dialer on the accepted
+// connection, for the relay to reach the peer behind NAT.
+func (c *QuicClient) openReverse(ctx context.Context) (Client, error) {
+	stm, err := c.session.OpenUniStreamSync(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = c.send.Write(data)
-	return err
+	return &QuicClient{
+		session:    c.session,
+		send:       stm,
+		zstdZipper: common.NewZstdEncoder(1),
+	}, nil
+}
+
+// acceptReverse accepts the stream opened by openReverse on the dialed
+// connection.
+func (c *QuicClient) acceptReverse(ctx context.Context) (Client, error) {
+	stm, err := c.session.AcceptUniStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &QuicClient{
+		session:      c.session,
+		receive:      stm,
+		zstdUnzipper: common.NewZstdDecoder(1),
+	}, nil
 }
 
 func (c *QuicClient) Close() error {
@@ -201,12 +234,26 @@ func (c *QuicClient) Close() error {
 	return c.session.CloseWithError(0, "DONE")
 }
 
-func generateTLSConfig() *tls.Config {
+func generateCertificate() *tls.Certificate {
+	return generateEndorsedCertificate(nil)
+}
+
+func generateEndorsedCertificate(endorse func(spki []byte) []byte) *tls.Certificate {
 	key, err := rsa.GenerateKey(rand.Reader, 1024)
 	if err != nil {
 		panic(err)
 	}
 	template := x509.Certificate{SerialNumber: big.NewInt(1)}
+	if endorse != nil {
+		spki, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			panic(err)
+		}
+		template.ExtraExtensions = []pkix.Extension{{
+			Id:    transportEndorsementExtension,
+			Value: endorse(spki),
+		}}
+	}
 	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
 	if err != nil {
 		panic(err)
@@ -218,8 +265,5 @@ func generateTLSConfig() *tls.Config {
 	if err != nil {
 		panic(err)
 	}
-	return &tls.Config{
-		Certificates: []tls.Certificate{tlsCert},
-		NextProtos:   []string{"mixin-quic-peer"},
-	}
+	return &tlsCert
 }
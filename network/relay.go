@@ -0,0 +1,401 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	RelayFrameMessagePrefix = "MIXINRELAY"
+
+	relayRedialInterval = 30 * time.Second
+	relayPingInterval   = 3 * time.Second
+)
+
+// RelayFrame is a peer message forwarded by a relay, the data is sealed to
+// the target and then signed by the source, so that the relay can neither
+// read nor forge or alter the messages between the two peers.
+type RelayFrame struct {
+	Source    crypto.Hash
+	Target    crypto.Hash
+	Data      []byte
+	Signature []byte
+}
+
+func (f *RelayFrame) Payload() []byte {
+	data := append([]byte(RelayFrameMessagePrefix), f.Source[:]...)
+	data = append(data, f.Target[:]...)
+	h := crypto.NewHash(append(data, f.Data...))
+	return h[:]
+}
+
+// reverseClient is a connection able to send back to the dialer, the relay
+// opens the reverse stream on the connection accepted from a peer behind
+// NAT, which can't be dialed at all.
+type reverseClient interface {
+	openReverse(ctx context.Context) (Client, error)
+	acceptReverse(ctx context.Context) (Client, error)
+}
+
+type relayBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// relayService is opt-in for both sides. A relay forwards the frames only
+// between the peers in its allow list, and caps the bandwidth of each source
+// by a token bucket, which holds at most one second of the bandwidth. A peer
+// behind NAT registers to its relays, and the messages to the unreachable
+// neighbors are sent through the relays, or through the reverse streams if
+// the neighbors registered to this peer.
+type relayService struct {
+	sync.Mutex
+	serve     bool
+	allow     map[crypto.Hash]bool
+	relays    []crypto.Hash
+	bandwidth float64
+	buckets   map[crypto.Hash]*relayBucket
+	clients   map[crypto.Hash]Client
+}
+
+func newRelayService() *relayService {
+	return &relayService{
+		allow:   make(map[crypto.Hash]bool),
+		buckets: make(map[crypto.Hash]*relayBucket),
+		clients: make(map[crypto.Hash]Client),
+	}
+}
+
+// SetRelay serves as a relay for the allowed peers if serve, and uses the
+// relays to reach the unreachable neighbors, the bandwidth is the bytes per
+// second forwarded for each source peer.
+func (me *Peer) SetRelay(serve bool, allow, relays []crypto.Hash, bandwidth int) {
+	r := me.relay
+	r.Lock()
+	defer r.Unlock()
+
+	r.serve = serve
+	r.relays = relays
+	r.bandwidth = float64(bandwidth)
+	r.allow = make(map[crypto.Hash]bool)
+	for _, id := range allow {
+		r.allow[id] = true
+	}
+}
+
+func (r *relayService) isRelay(id crypto.Hash) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, p := range r.relays {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *relayService) relayList() []crypto.Hash {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]crypto.Hash{}, r.relays...)
+}
+
+func (r *relayService) allowed(ids ...crypto.Hash) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	if !r.serve {
+		return false
+	}
+	for _, id := range ids {
+		if !r.allow[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *relayService) consume(source crypto.Hash, size int, now time.Time) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	b := r.buckets[source]
+	if b == nil {
+		b = &relayBucket{tokens: r.bandwidth, updated: now}
+		r.buckets[source] = b
+	}
+	b.tokens += now.Sub(b.updated).Seconds() * r.bandwidth
+	if b.tokens > r.bandwidth {
+		b.tokens = r.bandwidth
+	}
+	b.updated = now
+	if b.tokens < float64(size) {
+		return false
+	}
+	b.tokens -= float64(size)
+	return true
+}
+
+func (r *relayService) register(id crypto.Hash, c Client) {
+	r.Lock()
+	defer r.Unlock()
+
+	if old := r.clients[id]; old != nil {
+		old.Close()
+	}
+	r.clients[id] = c
+}
+
+func (r *relayService) unregister(id crypto.Hash, c Client) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.clients[id] == c {
+		delete(r.clients, id)
+		c.Close()
+	}
+}
+
+func (r *relayService) client(id crypto.Hash) Client {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.clients[id]
+}
+
+func (r *relayService) registered() map[crypto.Hash]Client {
+	r.Lock()
+	defer r.Unlock()
+
+	clients := make(map[crypto.Hash]Client, len(r.clients))
+	for id, c := range r.clients {
+		clients[id] = c
+	}
+	return clients
+}
+
+func buildRelayRegisterMessage() []byte {
+	return buildMessage(PeerMessageTypeRelayRegister)
+}
+
+func buildRelayMessage(f *RelayFrame) []byte {
+	return buildMessage(PeerMessageTypeRelay, common.MsgpackMarshalPanic(f))
+}
+
+func (me *Peer) buildRelayFrame(target crypto.Hash, data []byte) (*RelayFrame, error) {
+	sealed, err := me.handle.SealRelayFrame(target, data)
+	if err != nil {
+		return nil, err
+	}
+	f := &RelayFrame{Source: me.IdForNetwork, Target: target, Data: sealed}
+	f.Signature = me.handle.SignRelayFrame(f.Payload())
+	return f, nil
+}
+
+// registerRelayClient opens the reverse stream to the peer registered on
+// the accepted connection, which must be allowed by this relay.
+func (me *Peer) registerRelayClient(peer *Peer, client Client) error {
+	if !me.relay.allowed(peer.IdForNetwork) {
+		return fmt.Errorf("relay register not allowed %s", peer.IdForNetwork)
+	}
+	rc, ok := client.(reverseClient)
+	if !ok {
+		return fmt.Errorf("relay register unsupported client %s", peer.IdForNetwork)
+	}
+	c, err := rc.openReverse(me.ctx)
+	if err != nil {
+		return err
+	}
+	me.relay.register(peer.IdForNetwork, c)
+	return nil
+}
+
+// forwardRelayFrame forwards the frame from the source neighbor to the
+// target through its reverse stream, the frames over the bandwidth cap are
+// dropped as the consensus messages are all retried.
+func (me *Peer) forwardRelayFrame(peer *Peer, f *RelayFrame) error {
+	if f.Source != peer.IdForNetwork {
+		return fmt.Errorf("relay frame invalid source %s %s", f.Source, peer.IdForNetwork)
+	}
+	if f.Target == me.IdForNetwork {
+		return me.deliverRelayFrame(f)
+	}
+	if !me.relay.allowed(f.Source, f.Target) {
+		return fmt.Errorf("relay frame not allowed %s %s", f.Source, f.Target)
+	}
+	c := me.relay.client(f.Target)
+	if c == nil {
+		return fmt.Errorf("relay frame target not registered %s", f.Target)
+	}
+	if !me.relay.consume(f.Source, len(f.Data), time.Now()) {
+		return fmt.Errorf("relay frame bandwidth exceeded %s %d", f.Source, len(f.Data))
+	}
+	err := c.Send(buildRelayMessage(f))
+	if err != nil {
+		me.relay.unregister(f.Target, c)
+	}
+	return err
+}
+
+func (me *Peer) deliverRelayFrame(f *RelayFrame) error {
+	if f.Target != me.IdForNetwork {
+		return fmt.Errorf("relay frame invalid target %s", f.Target)
+	}
+	peer := me.neighbors.Get(f.Source)
+	if peer == nil {
+		return fmt.Errorf("relay frame unknown source %s", f.Source)
+	}
+	signer, err := me.handle.VerifyRelayFrame(f.Signature, f.Payload())
+	if err != nil {
+		return err
+	}
+	if signer != f.Source {
+		return fmt.Errorf("relay frame invalid signer %s %s", signer, f.Source)
+	}
+	data, err := me.handle.OpenRelayFrame(f.Signature, f.Data)
+	if err != nil {
+		return err
+	}
+	msg, err := parseNetworkMessage(TransportMessageVersion, data)
+	if err != nil {
+		return err
+	}
+	switch msg.Type {
+	case PeerMessageTypeAuthentication, PeerMessageTypeRelayRegister, PeerMessageTypeRelay:
+		return fmt.Errorf("relay frame invalid message type %d", msg.Type)
+	}
+	peer.link.receive(time.Now())
+	me.handleMessage(peer, msg)
+	return nil
+}
+
+// relayReceiveLoop receives the frames from the reverse stream of the relay,
+// until the outbound connection to the relay is closed.
+func (me *Peer) relayReceiveLoop(relay *Peer, client Client) {
+	rc, ok := client.(reverseClient)
+	if !ok {
+		return
+	}
+	c, err := rc.acceptReverse(me.ctx)
+	if err != nil {
+		logger.Verbosef("relay accept reverse %s error %s\n", relay.IdForNetwork, err)
+		return
+	}
+	defer c.Close()
+
+	for !me.closing {
+		tm, err := c.Receive()
+		if err != nil {
+			logger.Verbosef("relay receive %s error %s\n", relay.IdForNetwork, err)
+			return
+		}
+		msg, err := parseNetworkMessage(tm.Version, tm.Data)
+		if err != nil {
+			logger.Verbosef("relay receive %s error %s\n", relay.IdForNetwork, err)
+			return
+		}
+		relay.link.receive(time.Now())
+		if msg.Type != PeerMessageTypeRelay {
+			continue
+		}
+		err = me.deliverRelayFrame(msg.Relay)
+		if err != nil {
+			logger.Verbosef("relay deliver %s error %s\n", relay.IdForNetwork, err)
+		}
+	}
+}
+
+// relayPingLoop keeps the idle reverse streams within the read deadline, and
+// removes the broken ones.
+func (me *Peer) relayPingLoop() {
+	ticker := time.NewTicker(relayPingInterval)
+	defer ticker.Stop()
+
+	for !me.closing {
+		<-ticker.C
+		for id, c := range me.relay.registered() {
+			err := c.Send(buildMessage(PeerMessageTypePing))
+			if err != nil {
+				me.relay.unregister(id, c)
+			}
+		}
+	}
+}
+
+// relaySender returns the function to send the messages to the unreachable
+// neighbor, directly by its reverse stream if registered to this peer, or
+// by a connected relay, and nil if no route available.
+func (me *Peer) relaySender(p *Peer) func(data []byte) error {
+	if c := me.relay.client(p.IdForNetwork); c != nil {
+		return func(data []byte) error {
+			f, err := me.buildRelayFrame(p.IdForNetwork, data)
+			if err != nil {
+				return err
+			}
+			err = c.Send(buildRelayMessage(f))
+			if err != nil {
+				me.relay.unregister(p.IdForNetwork, c)
+			}
+			return err
+		}
+	}
+	for _, id := range me.relay.relayList() {
+		r := me.neighbors.Get(id)
+		if r == nil || r == p || r.unreachable || atomic.LoadInt64(&r.link.connected) == 0 {
+			continue
+		}
+		return func(data []byte) error {
+			f, err := me.buildRelayFrame(p.IdForNetwork, data)
+			if err != nil {
+				return err
+			}
+			h := crypto.NewHash(data)
+			key := append(f.Target[:], h[:]...)
+			success, _ := me.queue.offer(r.sendRings[sendClassConsensus], &ChanMsg{key, buildRelayMessage(f)})
+			if !success {
+				return fmt.Errorf("peer send relay timeout")
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// relayPeerMessages sends the queued messages of the unreachable neighbor
+// through the relay until the deadline, and returns false if no relay route
+// available at all.
+func (me *Peer) relayPeerMessages(p *Peer, deadline time.Time) bool {
+	send := me.relaySender(p)
+	if send == nil {
+		return false
+	}
+	for !me.closing && !p.closing && time.Now().Before(deadline) {
+		msg, err := p.pollSendRings()
+		if err != nil {
+			return true
+		}
+		if msg == nil {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		if me.snapshotsCaches.contains(msg.key, time.Minute) {
+			continue
+		}
+		err = send(msg.data)
+		if err != nil {
+			logger.Verbosef("relay send %s error %s\n", p.IdForNetwork, err)
+			return true
+		}
+		me.snapshotsCaches.store(msg.key, time.Now())
+	}
+	return true
+}
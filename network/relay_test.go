@@ -0,0 +1,91 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayService(t *testing.T) {
+	assert := assert.New(t)
+
+	a := crypto.NewHash([]byte("a"))
+	b := crypto.NewHash([]byte("b"))
+	c := crypto.NewHash([]byte("c"))
+
+	me := &Peer{IdForNetwork: c, relay: newRelayService()}
+	assert.False(me.relay.allowed(a, b))
+	me.SetRelay(false, []crypto.Hash{a, b}, []crypto.Hash{c}, 100)
+	assert.False(me.relay.allowed(a, b))
+	assert.True(me.relay.isRelay(c))
+	assert.False(me.relay.isRelay(a))
+	assert.Equal([]crypto.Hash{c}, me.relay.relayList())
+
+	me.SetRelay(true, []crypto.Hash{a, b}, nil, 100)
+	assert.True(me.relay.allowed(a, b))
+	assert.False(me.relay.allowed(a, c))
+	assert.False(me.relay.isRelay(c))
+
+	now := time.Now()
+	assert.True(me.relay.consume(a, 60, now))
+	assert.False(me.relay.consume(a, 60, now))
+	assert.True(me.relay.consume(b, 60, now))
+	assert.True(me.relay.consume(a, 60, now.Add(time.Second/2)))
+	assert.False(me.relay.consume(a, 60, now.Add(time.Second/2)))
+	assert.True(me.relay.consume(a, 100, now.Add(time.Hour)))
+	assert.False(me.relay.consume(a, 1, now.Add(time.Hour)))
+
+	f := &RelayFrame{Source: a, Target: b, Data: buildMessage(PeerMessageTypePing), Signature: []byte("signature")}
+	msg, err := parseNetworkMessage(TransportMessageVersion, buildRelayMessage(f))
+	assert.Nil(err)
+	assert.Equal(uint8(PeerMessageTypeRelay), msg.Type)
+	assert.Equal(f.Payload(), msg.Relay.Payload())
+	assert.Equal(f.Signature, msg.Relay.Signature)
+	f.Data = buildMessage(PeerMessageTypeGossipNeighbors)
+	assert.NotEqual(f.Payload(), msg.Relay.Payload())
+
+	err = me.forwardRelayFrame(&Peer{IdForNetwork: b}, msg.Relay)
+	assert.NotNil(err)
+	err = me.forwardRelayFrame(&Peer{IdForNetwork: a}, msg.Relay)
+	assert.NotNil(err)
+
+	msg, err = parseNetworkMessage(TransportMessageVersion, buildRelayRegisterMessage())
+	assert.Nil(err)
+	assert.Equal(uint8(PeerMessageTypeRelayRegister), msg.Type)
+}
+
+func TestLoopbackReverse(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	n := NewLoopbackNetwork()
+	server := n.server("127.0.0.1:7101")
+	assert.Nil(server.Listen())
+	defer server.Close()
+
+	accepted := make(chan Client)
+	go func() {
+		c, err := server.Accept(ctx)
+		assert.Nil(err)
+		accepted <- c
+	}()
+	client, err := n.client("127.0.0.1:7102", "127.0.0.1:7101").Dial(ctx)
+	assert.Nil(err)
+	remote := <-accepted
+
+	forward, err := remote.(reverseClient).openReverse(ctx)
+	assert.Nil(err)
+	backward, err := client.(reverseClient).acceptReverse(ctx)
+	assert.Nil(err)
+	assert.Nil(forward.Send([]byte("reverse")))
+	msg, err := backward.Receive()
+	assert.Nil(err)
+	assert.Equal("reverse", string(msg.Data))
+
+	client.Close()
+	_, err = client.(reverseClient).acceptReverse(ctx)
+	assert.NotNil(err)
+}
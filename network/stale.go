@@ -0,0 +1,203 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const (
+	StalePeerActionDemote     = "demote"
+	StalePeerActionRestore    = "restore"
+	StalePeerActionDisconnect = "disconnect"
+
+	stalePeerEventsLimit = 256
+)
+
+type StalePeerEvent struct {
+	Peer      crypto.Hash
+	Address   string
+	Action    string
+	Height    uint64
+	Advanced  time.Time
+	Timestamp time.Time
+}
+
+type peerProgress struct {
+	height   uint64
+	advanced time.Time
+	demoted  bool
+}
+
+// staleTracker watches the sync points advertised by each neighbor, the sum
+// of all the rounds in a graph only grows while the peer keeps finalizing.
+// A peer stuck for the timeout while some other peers advance is demoted
+// from the gossip rounds, and it is disconnected after another timeout and
+// refused for a timeout, so that its connection slot goes to healthy peers.
+type staleTracker struct {
+	sync.Mutex
+	timeout  time.Duration
+	progress map[crypto.Hash]*peerProgress
+	evicted  map[crypto.Hash]time.Time
+	events   []*StalePeerEvent
+}
+
+func newStaleTracker() *staleTracker {
+	return &staleTracker{
+		progress: make(map[crypto.Hash]*peerProgress),
+		evicted:  make(map[crypto.Hash]time.Time),
+	}
+}
+
+func syncPointsHeight(points []*SyncPoint) uint64 {
+	var height uint64
+	for _, p := range points {
+		height += p.Number
+	}
+	return height
+}
+
+func (t *staleTracker) update(p *Peer, points []*SyncPoint, now time.Time) {
+	if t.timeout <= 0 {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+
+	height := syncPointsHeight(points)
+	pp := t.progress[p.IdForNetwork]
+	if pp == nil {
+		t.progress[p.IdForNetwork] = &peerProgress{height: height, advanced: now}
+		return
+	}
+	if height <= pp.height {
+		return
+	}
+	pp.height, pp.advanced = height, now
+	if pp.demoted {
+		pp.demoted = false
+		t.record(p, StalePeerActionRestore, pp, now)
+	}
+}
+
+// check demotes and evicts the stale neighbors, and returns the neighbors
+// to disconnect. Nothing is stale unless some other neighbor advanced in
+// the timeout, otherwise it's the local node or the whole network stuck.
+func (t *staleTracker) check(neighbors []*Peer, now time.Time) []*Peer {
+	t.Lock()
+	defer t.Unlock()
+
+	for id, ts := range t.evicted {
+		if ts.Add(t.timeout).Before(now) {
+			delete(t.evicted, id)
+		}
+	}
+
+	fresh := 0
+	for _, p := range neighbors {
+		pp := t.progress[p.IdForNetwork]
+		if pp == nil {
+			pp = &peerProgress{advanced: now}
+			t.progress[p.IdForNetwork] = pp
+		}
+		if pp.advanced.Add(t.timeout).After(now) {
+			fresh++
+		}
+	}
+	if fresh == 0 {
+		return nil
+	}
+
+	var stale []*Peer
+	for _, p := range neighbors {
+		pp := t.progress[p.IdForNetwork]
+		elapsed := now.Sub(pp.advanced)
+		if elapsed >= t.timeout*2 {
+			delete(t.progress, p.IdForNetwork)
+			t.evicted[p.IdForNetwork] = now
+			t.record(p, StalePeerActionDisconnect, pp, now)
+			stale = append(stale, p)
+		} else if elapsed >= t.timeout && !pp.demoted {
+			pp.demoted = true
+			t.record(p, StalePeerActionDemote, pp, now)
+		}
+	}
+	return stale
+}
+
+func (t *staleTracker) record(p *Peer, action string, pp *peerProgress, now time.Time) {
+	logger.Printf("network.stale %s %s %s height %d advanced %s\n", action, p.IdForNetwork, p.Address, pp.height, pp.advanced)
+	t.events = append(t.events, &StalePeerEvent{
+		Peer:      p.IdForNetwork,
+		Address:   p.Address,
+		Action:    action,
+		Height:    pp.height,
+		Advanced:  pp.advanced,
+		Timestamp: now,
+	})
+	if len(t.events) > stalePeerEventsLimit {
+		t.events = t.events[len(t.events)-stalePeerEventsLimit:]
+	}
+}
+
+func (t *staleTracker) demoted(id crypto.Hash) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	pp := t.progress[id]
+	return pp != nil && pp.demoted
+}
+
+func (t *staleTracker) refused(id crypto.Hash, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	ts, found := t.evicted[id]
+	return found && ts.Add(t.timeout).After(now)
+}
+
+func (me *Peer) SetStalePeerTimeout(timeout time.Duration) {
+	me.stale.timeout = timeout
+}
+
+func (me *Peer) StalePeerEvents() []*StalePeerEvent {
+	me.stale.Lock()
+	defer me.stale.Unlock()
+
+	events := make([]*StalePeerEvent, len(me.stale.events))
+	for i, e := range me.stale.events {
+		ce := *e
+		events[i] = &ce
+	}
+	return events
+}
+
+func (me *Peer) staleEvictionLoop() {
+	if me.stale.timeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(me.stale.timeout / 4)
+	defer ticker.Stop()
+
+	for !me.closing {
+		<-ticker.C
+		stale := me.stale.check(me.neighbors.Slice(), time.Now())
+		for _, p := range stale {
+			if !me.neighbors.Delete(p.IdForNetwork, p) {
+				continue
+			}
+			me.gossipRound.Delete(p.IdForNetwork, p)
+			go p.disconnect()
+		}
+	}
+}
+
+func (me *Peer) checkStalePeerRefused(idForNetwork crypto.Hash) error {
+	if me.stale.refused(idForNetwork, time.Now()) {
+		return fmt.Errorf("stale peer %s evicted", idForNetwork)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaleTracker(t *testing.T) {
+	assert := assert.New(t)
+
+	a := &Peer{IdForNetwork: crypto.NewHash([]byte("a")), Address: "127.0.0.1:7001"}
+	b := &Peer{IdForNetwork: crypto.NewHash([]byte("b")), Address: "127.0.0.1:7002"}
+	neighbors := []*Peer{a, b}
+	graph := func(rounds ...uint64) []*SyncPoint {
+		points := make([]*SyncPoint, len(rounds))
+		for i, n := range rounds {
+			points[i] = &SyncPoint{NodeId: crypto.NewHash([]byte{byte(i)}), Number: n}
+		}
+		return points
+	}
+
+	tracker := newStaleTracker()
+	now := time.Now()
+	tracker.update(a, graph(3, 7), now)
+	assert.Len(tracker.progress, 0)
+
+	tracker.timeout = time.Minute
+	tracker.update(a, graph(3, 7), now)
+	tracker.update(b, graph(3, 7), now)
+	assert.Equal(uint64(10), tracker.progress[a.IdForNetwork].height)
+
+	now = now.Add(time.Second * 90)
+	assert.Len(tracker.check(neighbors, now), 0)
+	assert.False(tracker.demoted(a.IdForNetwork))
+	assert.False(tracker.demoted(b.IdForNetwork))
+	assert.Len(tracker.events, 0)
+
+	tracker.update(a, graph(4, 7), now)
+	tracker.update(b, graph(3, 7), now)
+	assert.Len(tracker.check(neighbors, now), 0)
+	assert.False(tracker.demoted(a.IdForNetwork))
+	assert.True(tracker.demoted(b.IdForNetwork))
+
+	tracker.update(b, graph(3, 8), now.Add(time.Second))
+	assert.False(tracker.demoted(b.IdForNetwork))
+
+	now = now.Add(time.Minute * 3)
+	tracker.update(a, graph(5, 8), now)
+	stale := tracker.check(neighbors, now)
+	assert.Len(stale, 1)
+	assert.Equal(b, stale[0])
+	assert.Nil(tracker.progress[b.IdForNetwork])
+	assert.True(tracker.refused(b.IdForNetwork, now.Add(time.Second)))
+	assert.False(tracker.refused(a.IdForNetwork, now.Add(time.Second)))
+	assert.False(tracker.refused(b.IdForNetwork, now.Add(time.Minute*2)))
+
+	assert.Len(tracker.events, 3)
+	for i, action := range []string{StalePeerActionDemote, StalePeerActionRestore, StalePeerActionDisconnect} {
+		assert.Equal(b.IdForNetwork, tracker.events[i].Peer)
+		assert.Equal(b.Address, tracker.events[i].Address)
+		assert.Equal(action, tracker.events[i].Action)
+	}
+	assert.Equal(uint64(11), tracker.events[2].Height)
+
+	tracker.check(neighbors[:1], now.Add(time.Minute*2))
+	assert.Len(tracker.evicted, 0)
+}
@@ -0,0 +1,166 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const transportKeyAnnounceInterval = 30 * time.Second
+
+// transportKeys holds the TLS certificates of the QUIC server. A rotation
+// announces the next certificate an overlap window before presenting it,
+// and keeps announcing the retired one for another window, so the peers
+// accept both identities during the rotation and no connection is dropped.
+type transportKeys struct {
+	sync.Mutex
+	current  *tls.Certificate
+	next     *tls.Certificate
+	retired  *tls.Certificate
+	overlap  time.Duration
+	switchAt time.Time
+	retireAt time.Time
+	endorse  func(spki []byte) []byte
+}
+
+type transportPin struct {
+	keys      []crypto.Hash
+	timestamp uint64
+}
+
+type transportPinMap struct {
+	sync.RWMutex
+	m map[crypto.Hash]*transportPin
+}
+
+func newTransportKeys() *transportKeys {
+	return newEndorsedTransportKeys(nil)
+}
+
+// newEndorsedTransportKeys generates all the certificates with the signer
+// endorsement for the mutual TLS.
+func newEndorsedTransportKeys(endorse func(spki []byte) []byte) *transportKeys {
+	return &transportKeys{
+		current: generateEndorsedCertificate(endorse),
+		endorse: endorse,
+	}
+}
+
+func certificateFingerprint(der []byte) crypto.Hash {
+	return crypto.NewHash(der)
+}
+
+func (k *transportKeys) rotate(overlap time.Duration, now time.Time) {
+	k.Lock()
+	defer k.Unlock()
+
+	k.promote(now)
+	if k.next != nil {
+		return
+	}
+	k.next = generateEndorsedCertificate(k.endorse)
+	k.overlap = overlap
+	k.switchAt = now.Add(overlap)
+}
+
+func (k *transportKeys) promote(now time.Time) {
+	if k.next != nil && !now.Before(k.switchAt) {
+		k.retired, k.current, k.next = k.current, k.next, nil
+		k.retireAt = k.switchAt.Add(k.overlap)
+	}
+	if k.retired != nil && !now.Before(k.retireAt) {
+		k.retired = nil
+	}
+}
+
+func (k *transportKeys) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.Lock()
+	defer k.Unlock()
+
+	k.promote(time.Now())
+	return k.current, nil
+}
+
+func (k *transportKeys) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return k.getCertificate(nil)
+}
+
+func (k *transportKeys) fingerprints(now time.Time) []crypto.Hash {
+	k.Lock()
+	defer k.Unlock()
+
+	k.promote(now)
+	keys := []crypto.Hash{certificateFingerprint(k.current.Certificate[0])}
+	for _, c := range []*tls.Certificate{k.next, k.retired} {
+		if c != nil {
+			keys = append(keys, certificateFingerprint(c.Certificate[0]))
+		}
+	}
+	return keys
+}
+
+func (m *transportPinMap) set(id crypto.Hash, keys []crypto.Hash, timestamp uint64) {
+	m.Lock()
+	defer m.Unlock()
+
+	old := m.m[id]
+	if old != nil && old.timestamp >= timestamp {
+		return
+	}
+	m.m[id] = &transportPin{keys: keys, timestamp: timestamp}
+}
+
+// verifier pins the certificate of a dialed peer to its last announced keys.
+// The first contact with a peer never announced is trust on first use, and
+// only verified by the authentication after the handshake. Once pinned, the
+// peer must present one of the pinned keys until a later signed announcement
+// replaces them, e.g. received on a connection dialed by the peer after its
+// restart, so a pin never expires to accept any certificate.
+func (m *transportPinMap) verifier(id crypto.Hash) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		m.RLock()
+		pin := m.m[id]
+		m.RUnlock()
+
+		if pin == nil {
+			return nil
+		}
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("peer %s transport certificate missing", id)
+		}
+		fp := certificateFingerprint(rawCerts[0])
+		for _, k := range pin.keys {
+			if k == fp {
+				return nil
+			}
+		}
+		return fmt.Errorf("peer %s transport certificate unknown %s", id, fp)
+	}
+}
+
+func (me *Peer) SetTransportKeyRotation(period, overlap time.Duration) {
+	me.transportKeyRotation = period
+	me.transportKeyOverlap = overlap
+}
+
+func (me *Peer) rotateTransportKeysLoop() {
+	if me.transportKeyRotation <= 0 {
+		return
+	}
+	ticker := time.NewTicker(me.transportKeyRotation)
+	defer ticker.Stop()
+
+	for !me.closing {
+		<-ticker.C
+		me.transportKeys.rotate(me.transportKeyOverlap, time.Now())
+	}
+}
+
+func (me *Peer) buildTransportKeyMessage() []byte {
+	data := me.handle.BuildTransportKeyMessage(me.transportKeys.fingerprints(time.Now()))
+	return buildMessage(PeerMessageTypeTransportKey, data)
+}
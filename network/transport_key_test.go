@@ -0,0 +1,53 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	keys := newTransportKeys()
+	first := keys.current
+	fp1 := certificateFingerprint(first.Certificate[0])
+	assert.Equal([]crypto.Hash{fp1}, keys.fingerprints(now))
+
+	keys.rotate(time.Minute, now)
+	second := keys.next
+	fp2 := certificateFingerprint(second.Certificate[0])
+	assert.Equal([]crypto.Hash{fp1, fp2}, keys.fingerprints(now))
+	keys.rotate(time.Minute, now.Add(time.Second))
+	assert.Equal(second, keys.next)
+	cert, _ := keys.getCertificate(nil)
+	assert.Equal(first, cert)
+
+	assert.Equal([]crypto.Hash{fp2, fp1}, keys.fingerprints(now.Add(time.Minute)))
+	assert.Equal(second, keys.current)
+	assert.Equal([]crypto.Hash{fp2}, keys.fingerprints(now.Add(2*time.Minute)))
+
+	id := crypto.NewHash([]byte("transport-key-peer"))
+	pins := &transportPinMap{m: make(map[crypto.Hash]*transportPin)}
+	verify := pins.verifier(id)
+	assert.Nil(verify([][]byte{first.Certificate[0]}, nil))
+
+	pins.set(id, []crypto.Hash{fp1, fp2}, 2)
+	assert.Nil(verify([][]byte{first.Certificate[0]}, nil))
+	assert.Nil(verify([][]byte{second.Certificate[0]}, nil))
+	assert.NotNil(verify([][]byte{[]byte("unknown")}, nil))
+	assert.NotNil(verify(nil, nil))
+
+	pins.set(id, []crypto.Hash{fp1}, 1)
+	assert.Nil(verify([][]byte{second.Certificate[0]}, nil))
+	pins.set(id, []crypto.Hash{fp2}, 3)
+	assert.NotNil(verify([][]byte{first.Certificate[0]}, nil))
+
+	assert.NotNil(verify(nil, nil))
+	pins.set(id, []crypto.Hash{fp1}, 4)
+	assert.Nil(verify([][]byte{first.Certificate[0]}, nil))
+	assert.NotNil(verify([][]byte{second.Certificate[0]}, nil))
+}
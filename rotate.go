@@ -0,0 +1,182 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+// rotateKeysCmd walks the operator through the node key rotation ceremony.
+// The node id is derived from the signer, so the signer key of an accepted
+// node is never rotated in place, and the rotation replaces the payee key by
+// a node modify signed with the current signer key, which is effective only
+// after the modify delay.
+func rotateKeysCmd(c *cli.Context) error {
+	signer, err := crypto.KeyFromString(c.String("signer"))
+	if err != nil {
+		return err
+	}
+	viewKey, err := crypto.KeyFromString(c.String("view"))
+	if err != nil {
+		return err
+	}
+	spendKey, err := crypto.KeyFromString(c.String("spend"))
+	if err != nil {
+		return err
+	}
+	account := common.Address{
+		PrivateViewKey:  viewKey,
+		PrivateSpendKey: spendKey,
+		PublicViewKey:   viewKey.Public(),
+		PublicSpendKey:  spendKey.Public(),
+	}
+
+	fmt.Println("step 1: generate the new payee key")
+	seed := make([]byte, 64)
+	_, err = rand.Read(seed)
+	if err != nil {
+		return err
+	}
+	payee := common.NewAddressFromSeed(seed)
+	payee.PrivateViewKey = payee.PublicSpendKey.DeterministicHashDerive()
+	payee.PublicViewKey = payee.PrivateViewKey.Public()
+	fmt.Printf("address:\t%s\n", payee.String())
+	fmt.Printf("view key:\t%s\n", payee.PrivateViewKey.String())
+	fmt.Printf("spend key:\t%s\n", payee.PrivateSpendKey.String())
+	fmt.Println("keep the new spend key safe before continuing")
+
+	fmt.Println("step 2: sign the node modify with the current signer key")
+	inputs, err := parseExtraInputs(c.String("input"))
+	if err != nil {
+		return err
+	}
+	if len(inputs) != 1 {
+		return fmt.Errorf("invalid input %s", c.String("input"))
+	}
+	m := common.SignNodeModify(signer, payee.PublicSpendKey, inputs)
+	fmt.Printf("signer:\t%s\n", m.Signer)
+	fmt.Printf("extra:\t%s\n", hex.EncodeToString(m.Encode()))
+
+	fmt.Println("step 3: build the node modify transaction")
+	raw, err := rotateKeysTransaction(c, &account, m)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("raw:\t%s\n", raw)
+	if c.Bool("skip-send") {
+		fmt.Println("broadcast the raw transaction with sendrawtransaction, then verify with listnodemodifications")
+		return nil
+	}
+
+	fmt.Println("step 4: broadcast the node modify transaction")
+	data, err := callRPC(c.String("node"), "sendrawtransaction", []interface{}{raw}, c.Bool("time"))
+	if err != nil {
+		return err
+	}
+	var sent struct {
+		Hash crypto.Hash `json:"hash"`
+	}
+	err = json.Unmarshal(data, &sent)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("transaction:\t%s\n", sent.Hash)
+
+	fmt.Println("step 5: verify the node modify after finalization")
+	mod, err := waitNodeModification(c, sent.Hash, c.Duration("timeout"))
+	if err != nil {
+		return err
+	}
+	effective, _ := strconv.ParseInt(fmt.Sprint(mod["effective"]), 10, 64)
+	fmt.Printf("payee:\t%s\n", mod["payee"])
+	fmt.Printf("effective:\t%s\n", time.Unix(0, effective).UTC().Format(time.RFC3339))
+	return nil
+}
+
+// rotateKeysTransaction spends the input back to the account with the node
+// modify as the extra, any script transaction is able to carry the modify.
+func rotateKeysTransaction(c *cli.Context, account *common.Address, m *common.NodeModify) (string, error) {
+	parts := strings.Split(c.String("input"), ":")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid input %s", c.String("input"))
+	}
+	input, err := crypto.HashFromString(parts[0])
+	if err != nil {
+		return "", err
+	}
+	index, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", err
+	}
+	amount := common.NewIntegerFromString(c.String("amount"))
+	if amount.Sign() == 0 {
+		return "", fmt.Errorf("invalid amount %s", c.String("amount"))
+	}
+
+	var raw signerInput
+	err = json.Unmarshal([]byte(fmt.Sprintf(`{"inputs":[{"hash":"%s","index":%d}]}`, input.String(), index)), &raw)
+	if err != nil {
+		return "", err
+	}
+	raw.Node = c.String("node")
+
+	seed := make([]byte, 64)
+	_, err = rand.Read(seed)
+	if err != nil {
+		return "", err
+	}
+	tx := common.NewTransaction(common.XINAssetId)
+	tx.AddInput(input, int(index))
+	tx.AddScriptOutput([]*common.Address{account}, common.NewThresholdScript(1), amount, seed)
+	tx.Extra = m.Encode()
+
+	signed := tx.AsLatestVersion()
+	err = signed.SignInput(raw, 0, []*common.Address{account})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(signed.Marshal()), nil
+}
+
+func waitNodeModification(c *cli.Context, hash crypto.Hash, timeout time.Duration) (map[string]interface{}, error) {
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(5 * time.Second) {
+		data, err := callRPC(c.String("node"), "gettransaction", []interface{}{hash.String()}, false)
+		if err != nil {
+			return nil, err
+		}
+		var tx map[string]interface{}
+		err = json.Unmarshal(data, &tx)
+		if err != nil {
+			return nil, err
+		}
+		if tx == nil || tx["snapshot"] == nil {
+			fmt.Println("waiting for finalization...")
+			continue
+		}
+
+		data, err = callRPC(c.String("node"), "listnodemodifications", []interface{}{false}, false)
+		if err != nil {
+			return nil, err
+		}
+		var mods []map[string]interface{}
+		err = json.Unmarshal(data, &mods)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range mods {
+			if m["transaction"] == hash.String() {
+				return m, nil
+			}
+		}
+		return nil, fmt.Errorf("node modify %s finalized but not accepted", hash)
+	}
+	return nil, fmt.Errorf("node modify %s not finalized in %s", hash, timeout)
+}
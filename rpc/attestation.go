@@ -0,0 +1,255 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel"
+	"github.com/MixinNetwork/mixin/storage"
+)
+
+const (
+	AttestationFactOutput      = 1
+	AttestationFactTransaction = 2
+
+	AttestationStateUnknown   = 0
+	AttestationStateUnspent   = 1
+	AttestationStateSpent     = 2
+	AttestationStateFinalized = 3
+)
+
+const attestationObserverWindow = 5 * time.Minute
+
+// The attestation payload is a sequence of 32 bytes big endian words, so that
+// an EVM contract could read it directly from the calldata:
+// fact, network, hash, index, topology, state, snapshot, timestamp
+func getAttestation(store storage.Store, node *kernel.Node, observers []string, nonces *attestationNonces, params []interface{}) (map[string]interface{}, error) {
+	if len(params) < 5 {
+		return nil, errors.New("invalid params count")
+	}
+	args := make([]string, len(params))
+	for i, p := range params {
+		args[i] = fmt.Sprint(p)
+	}
+	err := verifyAttestationObserver(observers, nonces, args, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	args = args[:len(args)-3]
+
+	var words [][32]byte
+	switch args[0] {
+	case "output":
+		words, err = attestOutput(store, node, args[1:])
+	case "transaction":
+		words, err = attestTransaction(store, node, args[1:])
+	default:
+		err = fmt.Errorf("invalid attestation fact %s", args[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	for _, w := range words {
+		payload = append(payload, w[:]...)
+	}
+	digest, sig, err := node.SignAttestation(payload)
+	if err != nil {
+		return nil, err
+	}
+	address, err := node.AttestationAddress()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"payload":   hex.EncodeToString(payload),
+		"digest":    hex.EncodeToString(digest),
+		"signer":    address,
+		"signature": hex.EncodeToString(sig),
+	}, nil
+}
+
+// verifyAttestationObserver verifies the observer signature of the fact
+// parameters and the request timestamp, which must be within the observer
+// window, and each signed request is only accepted once.
+func verifyAttestationObserver(observers []string, nonces *attestationNonces, args []string, now time.Time) error {
+	ts, err := strconv.ParseInt(args[len(args)-3], 10, 64)
+	if err != nil {
+		return err
+	}
+	if d := now.Sub(time.Unix(0, ts)); d > attestationObserverWindow || d < -attestationObserverWindow {
+		return fmt.Errorf("invalid observer timestamp %d", ts)
+	}
+	observer, err := crypto.KeyFromString(args[len(args)-2])
+	if err != nil {
+		return err
+	}
+	var sig crypto.Signature
+	b, err := hex.DecodeString(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+	if len(b) != len(sig) {
+		return fmt.Errorf("invalid signature size %d", len(b))
+	}
+	copy(sig[:], b)
+
+	for _, o := range observers {
+		if o != observer.String() {
+			continue
+		}
+		msg := crypto.NewHash([]byte(strings.Join(args[:len(args)-2], ":")))
+		if !observer.Verify(msg[:], sig) {
+			return errors.New("invalid observer signature")
+		}
+		return nonces.use(msg, now)
+	}
+	return fmt.Errorf("unauthorized observer %s", observer)
+}
+
+type attestationNonces struct {
+	sync.Mutex
+	m map[crypto.Hash]time.Time
+}
+
+func (n *attestationNonces) use(msg crypto.Hash, now time.Time) error {
+	n.Lock()
+	defer n.Unlock()
+
+	for k, t := range n.m {
+		if now.Sub(t) > attestationObserverWindow*2 {
+			delete(n.m, k)
+		}
+	}
+	if _, found := n.m[msg]; found {
+		return fmt.Errorf("observer request replayed %s", msg)
+	}
+	n.m[msg] = now
+	return nil
+}
+
+func attestOutput(store storage.Store, node *kernel.Node, args []string) ([][32]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("invalid params count")
+	}
+	hash, err := crypto.HashFromString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	index, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	topology, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	if seq := store.TopologySequence(); topology > seq {
+		return nil, fmt.Errorf("topology %d not reached %d", topology, seq)
+	}
+
+	// the output is unspent at the topology if its transaction is finalized
+	// no later than the topology, and spent only if the transaction locking
+	// it is finalized no later than the topology too, so a lock finalized
+	// after the topology, or not finalized yet, leaves it unspent
+	state, snap, err := readFinalization(store, hash, topology)
+	if err != nil {
+		return nil, err
+	}
+	if state != AttestationStateFinalized {
+		return attestationWords(node, AttestationFactOutput, hash, index, topology, AttestationStateUnknown, nil), nil
+	}
+	utxo, err := store.ReadUTXOLock(hash, int(index))
+	if err != nil {
+		return nil, err
+	}
+	state = AttestationStateUnknown
+	if utxo != nil {
+		state = AttestationStateUnspent
+	}
+	if utxo != nil && utxo.LockHash.HasValue() {
+		spent, _, err := readFinalization(store, utxo.LockHash, topology)
+		if err != nil {
+			return nil, err
+		}
+		if spent == AttestationStateFinalized {
+			state = AttestationStateSpent
+		}
+	}
+	return attestationWords(node, AttestationFactOutput, hash, index, topology, state, snap), nil
+}
+
+func attestTransaction(store storage.Store, node *kernel.Node, args []string) ([][32]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("invalid params count")
+	}
+	hash, err := crypto.HashFromString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	topology, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if seq := store.TopologySequence(); topology > seq {
+		return nil, fmt.Errorf("topology %d not reached %d", topology, seq)
+	}
+	state, snap, err := readFinalization(store, hash, topology)
+	if err != nil {
+		return nil, err
+	}
+	return attestationWords(node, AttestationFactTransaction, hash, 0, topology, state, snap), nil
+}
+
+func readFinalization(store storage.Store, hash crypto.Hash, topology uint64) (uint64, *common.SnapshotWithTopologicalOrder, error) {
+	tx, final, err := store.ReadTransaction(hash)
+	if err != nil || tx == nil || len(final) != 64 {
+		return AttestationStateUnknown, nil, err
+	}
+	sh, err := crypto.HashFromString(final)
+	if err != nil {
+		return AttestationStateUnknown, nil, err
+	}
+	snap, err := store.ReadSnapshot(sh)
+	if err != nil || snap == nil {
+		return AttestationStateUnknown, nil, err
+	}
+	if snap.TopologicalOrder > topology {
+		return AttestationStateUnknown, nil, nil
+	}
+	return AttestationStateFinalized, snap, nil
+}
+
+func attestationWords(node *kernel.Node, fact uint64, hash crypto.Hash, index, topology, state uint64, snap *common.SnapshotWithTopologicalOrder) [][32]byte {
+	var sh crypto.Hash
+	var ts uint64
+	if snap != nil {
+		sh, ts = snap.Hash, snap.Timestamp
+	}
+	return [][32]byte{
+		attestationUint(fact),
+		node.NetworkId(),
+		hash,
+		attestationUint(index),
+		attestationUint(topology),
+		attestationUint(state),
+		sh,
+		attestationUint(ts),
+	}
+}
+
+func attestationUint(n uint64) [32]byte {
+	var w [32]byte
+	binary.BigEndian.PutUint64(w[24:], n)
+	return w
+}
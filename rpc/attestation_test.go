@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttestationObserver(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := crypto.NewHash([]byte("observer"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	observers := []string{key.Public().String()}
+	nonces := &attestationNonces{m: make(map[crypto.Hash]time.Time)}
+	now := time.Now()
+
+	sign := func(ts time.Time) []string {
+		args := []string{"transaction", seed.String(), "100", fmt.Sprint(ts.UnixNano())}
+		msg := crypto.NewHash([]byte(strings.Join(args, ":")))
+		sig := key.Sign(msg[:])
+		return append(args, key.Public().String(), sig.String())
+	}
+
+	args := sign(now)
+	assert.Nil(verifyAttestationObserver(observers, nonces, args, now))
+	err := verifyAttestationObserver(observers, nonces, args, now)
+	assert.Contains(err.Error(), "replayed")
+	args = sign(now.Add(time.Second))
+	assert.Nil(verifyAttestationObserver(observers, nonces, args, now))
+
+	args = sign(now.Add(-attestationObserverWindow - time.Second))
+	err = verifyAttestationObserver(observers, nonces, args, now)
+	assert.Contains(err.Error(), "timestamp")
+	args = sign(now.Add(attestationObserverWindow + time.Second))
+	err = verifyAttestationObserver(observers, nonces, args, now)
+	assert.Contains(err.Error(), "timestamp")
+
+	args = sign(now.Add(2 * time.Second))
+	args[2] = "101"
+	err = verifyAttestationObserver(observers, nonces, args, now)
+	assert.Contains(err.Error(), "invalid observer signature")
+	args = sign(now.Add(2 * time.Second))
+	err = verifyAttestationObserver(nil, nonces, args, now)
+	assert.Contains(err.Error(), "unauthorized")
+
+	assert.Len(nonces.m, 2)
+	assert.Nil(verifyAttestationObserver(observers, nonces, args, now.Add(attestationObserverWindow)))
+	assert.Len(nonces.m, 3)
+	later := now.Add(attestationObserverWindow * 4)
+	assert.Nil(verifyAttestationObserver(observers, nonces, sign(later), later))
+	assert.Len(nonces.m, 1)
+}
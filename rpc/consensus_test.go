@@ -65,7 +65,7 @@ func TestAllTransactionsToSingleGenesisNode(t *testing.T) {
 		host := fmt.Sprintf("127.0.0.1:180%02d", i+1)
 		nodes = append(nodes, &Node{Signer: node.Signer, Host: host})
 
-		server := NewServer(custom, store, node, 18000+i+1)
+		server := NewServer(custom, store, node, nil, 18000+i+1)
 		defer server.Close()
 		go func(node *kernel.Node, store storage.Store, num int, s *http.Server) {
 			go s.ListenAndServe()
@@ -196,7 +196,7 @@ func testConsensus(t *testing.T, dup int) {
 		nodes = append(nodes, &Node{Signer: node.Signer, Host: host})
 		t.Logf("NODES#%d %s\n", i, node.IdForNetwork)
 
-		server := NewServer(custom, store, node, 18000+i+1)
+		server := NewServer(custom, store, node, nil, 18000+i+1)
 		defer server.Close()
 		go func(node *kernel.Node, store storage.Store, num int, s *http.Server) {
 			go s.ListenAndServe()
@@ -637,7 +637,7 @@ func testPledgeNewNode(assert *assert.Assertions, node string, domain common.Add
 	assert.Nil(err)
 	go pnode.Loop()
 
-	server := NewServer(custom, store, pnode, 18099)
+	server := NewServer(custom, store, pnode, nil, 18099)
 	go server.ListenAndServe()
 
 	return Node{Signer: signer, Payee: payee, Host: "127.0.0.1:18099"}, pnode, server
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/MixinNetwork/mixin/kernel"
+	"github.com/MixinNetwork/mixin/storage"
+)
+
+var cursorNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,64}$`)
+
+// setCursor and removeCursor are only allowed from the loopback, because
+// the cursors are shared by all the consumers of the node.
+func setCursor(store storage.Store, remote string, params []interface{}) (map[string]interface{}, error) {
+	if !isLoopbackAddress(remote) {
+		return nil, fmt.Errorf("setcursor not allowed from %s", remote)
+	}
+	if len(params) != 2 {
+		return nil, errors.New("invalid params count")
+	}
+	name, err := parseCursorName(params[0])
+	if err != nil {
+		return nil, err
+	}
+	offset, err := strconv.ParseUint(fmt.Sprint(params[1]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	err = store.WriteCursor(name, offset)
+	if err != nil {
+		return nil, err
+	}
+	return getCursor(store, []interface{}{name})
+}
+
+func getCursor(store storage.Store, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.New("invalid params count")
+	}
+	name, err := parseCursorName(params[0])
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := store.ReadCursor(name)
+	if err != nil {
+		return nil, err
+	}
+	if cursor == nil {
+		return nil, fmt.Errorf("cursor %s not found", name)
+	}
+	return cursorToMap(cursor), nil
+}
+
+func listCursors(store storage.Store, params []interface{}) ([]map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	cursors, err := store.ListCursors()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, len(cursors))
+	for i, c := range cursors {
+		result[i] = cursorToMap(c)
+	}
+	return result, nil
+}
+
+func removeCursor(store storage.Store, remote string, params []interface{}) (map[string]interface{}, error) {
+	if !isLoopbackAddress(remote) {
+		return nil, fmt.Errorf("removecursor not allowed from %s", remote)
+	}
+	cursor, err := getCursor(store, params)
+	if err != nil {
+		return nil, err
+	}
+	err = store.RemoveCursor(cursor["name"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// readCursor lists the snapshots from the cursor offset without moving it,
+// the consumer moves it with setcursor to the returned next offset once the
+// snapshots are processed, so nothing is skipped after a restart.
+func readCursor(node *kernel.Node, store storage.Store, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 4 {
+		return nil, errors.New("invalid params count")
+	}
+	cursor, err := getCursor(store, params[:1])
+	if err != nil {
+		return nil, err
+	}
+	offset := cursor["offset"].(uint64)
+	snapshots, err := listSnapshots(node, store, []interface{}{offset, params[1], params[2], params[3]})
+	if err != nil {
+		return nil, err
+	}
+	next := offset
+	if l := len(snapshots); l > 0 {
+		next = snapshots[l-1]["topology"].(uint64) + 1
+	}
+	cursor["snapshots"] = snapshots
+	cursor["next"] = next
+	return cursor, nil
+}
+
+func parseCursorName(param interface{}) (string, error) {
+	name := fmt.Sprint(param)
+	if !cursorNameRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid cursor name %s", name)
+	}
+	return name, nil
+}
+
+func cursorToMap(c *storage.Cursor) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      c.Name,
+		"offset":    c.Offset,
+		"timestamp": c.Timestamp,
+	}
+}
@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRemote(t *testing.T) {
+	assert := assert.New(t)
+
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+	store, err := storage.NewMemoryStore(custom)
+	assert.Nil(err)
+	defer store.Close()
+
+	_, err = setCursor(store, "10.0.0.1:8239", []interface{}{"consumer", 10})
+	assert.Contains(err.Error(), "not allowed")
+	_, err = getCursor(store, []interface{}{"consumer"})
+	assert.Contains(err.Error(), "not found")
+
+	cursor, err := setCursor(store, "127.0.0.1:8239", []interface{}{"consumer", 10})
+	assert.Nil(err)
+	assert.Equal(uint64(10), cursor["offset"])
+
+	_, err = removeCursor(store, "10.0.0.1:8239", []interface{}{"consumer"})
+	assert.Contains(err.Error(), "not allowed")
+	cursor, err = getCursor(store, []interface{}{"consumer"})
+	assert.Nil(err)
+	assert.Equal(uint64(10), cursor["offset"])
+
+	cursor, err = removeCursor(store, "[::1]:8239", []interface{}{"consumer"})
+	assert.Nil(err)
+	assert.Equal("consumer", cursor["name"])
+	_, err = getCursor(store, []interface{}{"consumer"})
+	assert.Contains(err.Error(), "not found")
+}
@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/MixinNetwork/mixin/storage"
+)
+
+func getCustodian(store storage.Store, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.New("invalid params count")
+	}
+	timestamp, err := strconv.ParseUint(fmt.Sprint(params[0]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp == 0 {
+		timestamp = uint64(time.Now().UnixNano())
+	}
+	set, err := store.ReadCustodianSet(timestamp)
+	if err != nil || set == nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"threshold":   set.Threshold,
+		"keys":        set.Keys,
+		"transaction": set.Transaction,
+		"timestamp":   set.Timestamp,
+	}, nil
+}
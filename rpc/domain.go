@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// listDomainChains lists the metadata of all domain chains, or the chain
+// given, for the services handling the deposits and withdrawals.
+func listDomainChains(params []interface{}) ([]*common.DomainChain, error) {
+	if len(params) > 1 {
+		return nil, errors.New("invalid params count")
+	}
+	if len(params) == 0 || fmt.Sprint(params[0]) == "" {
+		return common.ListDomainChains(), nil
+	}
+	id, err := crypto.HashFromString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	c := common.ReadDomainChain(id)
+	if c == nil {
+		return nil, fmt.Errorf("invalid chain id %s", id)
+	}
+	return []*common.DomainChain{c}, nil
+}
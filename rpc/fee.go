@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/kernel"
+)
+
+func listFeeStats(node *kernel.Node, params []interface{}) ([]map[string]interface{}, error) {
+	if len(params) != 2 {
+		return nil, errors.New("invalid params count")
+	}
+	since, err := strconv.ParseUint(fmt.Sprint(params[0]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.ParseUint(fmt.Sprint(params[1]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if count > 100 {
+		return nil, fmt.Errorf("invalid count %d", count)
+	}
+
+	result := make([]map[string]interface{}, 0)
+	for batch := since; batch < since+count; batch++ {
+		stats, err := node.ListFeeStats(batch)
+		if err != nil {
+			return nil, err
+		}
+		nodes := make(map[string]interface{})
+		for id, e := range stats.Nodes {
+			nodes[id.String()] = feeEntryToMap(e)
+		}
+		item := feeEntryToMap(stats.Total)
+		item["batch"] = stats.Batch
+		item["day"] = stats.Day
+		item["nodes"] = nodes
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func feeEntryToMap(e *common.FeeLedgerEntry) map[string]interface{} {
+	fuels := make([]map[string]interface{}, len(e.Fuels))
+	for i, f := range e.Fuels {
+		fuels[i] = map[string]interface{}{
+			"asset":  f.Asset,
+			"amount": f.Amount,
+		}
+	}
+	return map[string]interface{}{
+		"transactions": e.Transactions,
+		"burned":       e.Burned,
+		"fuels":        fuels,
+	}
+}
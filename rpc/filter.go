@@ -0,0 +1,368 @@
+package rpc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// A filter is a small expression evaluated against each event before it is
+// returned, e.g. `asset == 43d6... && amount >= 0.5 || key ^= 7b9e`.
+//
+//	expr    := and ("||" and)*
+//	and     := unary ("&&" unary)*
+//	unary   := "!" unary | "(" expr ")" | field op value
+//	op      := "==" | "!=" | ">=" | "<=" | ">" | "<" | "^="
+//
+// Values are bare words or double quoted strings, and ^= is prefix match.
+// The tokens and the nesting are limited, so a public filter never exhausts
+// the stack of the parser or the evaluation.
+type filter interface {
+	match(event map[string]interface{}) bool
+}
+
+const (
+	filterTokensLimit = 256
+	filterDepthLimit  = 16
+)
+
+const (
+	filterFieldString = iota
+	filterFieldUint
+	filterFieldAmount
+	filterFieldKeys
+)
+
+var filterFields = map[string]int{
+	"type":     filterFieldUint,
+	"hash":     filterFieldString,
+	"index":    filterFieldUint,
+	"asset":    filterFieldString,
+	"amount":   filterFieldAmount,
+	"key":      filterFieldKeys,
+	"script":   filterFieldString,
+	"mask":     filterFieldString,
+	"snapshot": filterFieldString,
+	"topology": filterFieldUint,
+	"lock":     filterFieldString,
+}
+
+var filterAmountRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+type filterNot struct {
+	f filter
+}
+
+type filterLogic struct {
+	and   bool
+	left  filter
+	right filter
+}
+
+type filterCompare struct {
+	field  string
+	kind   int
+	op     string
+	value  string
+	number uint64
+	amount common.Integer
+}
+
+func (f *filterNot) match(event map[string]interface{}) bool {
+	return !f.f.match(event)
+}
+
+func (f *filterLogic) match(event map[string]interface{}) bool {
+	if f.and {
+		return f.left.match(event) && f.right.match(event)
+	}
+	return f.left.match(event) || f.right.match(event)
+}
+
+func (f *filterCompare) match(event map[string]interface{}) bool {
+	switch f.kind {
+	case filterFieldUint:
+		v, err := strconv.ParseUint(fmt.Sprint(event[f.field]), 10, 64)
+		if err != nil {
+			return false
+		}
+		return compareFilterOrder(f.op, compareUint64(v, f.number))
+	case filterFieldAmount:
+		v, ok := event[f.field].(common.Integer)
+		if !ok {
+			return false
+		}
+		return compareFilterOrder(f.op, v.Cmp(f.amount))
+	case filterFieldKeys:
+		op, found := f.op, false
+		if op == "!=" {
+			op = "=="
+		}
+		keys, _ := event["keys"].([]*crypto.Key)
+		for _, k := range keys {
+			if compareFilterString(op, k.String(), f.value) {
+				found = true
+				break
+			}
+		}
+		return found != (f.op == "!=")
+	}
+	var v string
+	if val, ok := event[f.field]; ok {
+		v = fmt.Sprint(val)
+	}
+	return compareFilterString(f.op, v, f.value)
+}
+
+func compareUint64(a, b uint64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareFilterOrder(op string, c int) bool {
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	}
+	return false
+}
+
+func compareFilterString(op, a, b string) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "^=":
+		return strings.HasPrefix(a, b)
+	}
+	return false
+}
+
+func parseFilter(expr string) (filter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > filterTokensLimit {
+		return nil, fmt.Errorf("invalid filter tokens count %d", len(tokens))
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("invalid filter token %s", p.tokens[p.pos])
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+	depth  int
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("invalid filter unexpected end")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterLogic{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterLogic{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filter, error) {
+	switch p.peek() {
+	case "!", "(":
+		if p.depth >= filterDepthLimit {
+			return nil, fmt.Errorf("invalid filter depth %d", p.depth)
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+	}
+	switch p.peek() {
+	case "!":
+		p.pos++
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{f: f}, nil
+	case "(":
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t != ")" {
+			return nil, fmt.Errorf("invalid filter token %s", t)
+		}
+		return f, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *filterParser) parseCompare() (filter, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	kind, ok := filterFields[field]
+	if !ok {
+		return nil, fmt.Errorf("invalid filter field %s", field)
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if isFilterOperator(value) {
+		return nil, fmt.Errorf("invalid filter value %s", value)
+	}
+	value = strings.Trim(value, `"`)
+
+	f := &filterCompare{field: field, kind: kind, op: op, value: value}
+	switch kind {
+	case filterFieldUint, filterFieldAmount:
+		switch op {
+		case "==", "!=", ">=", "<=", ">", "<":
+		default:
+			return nil, fmt.Errorf("invalid filter operator %s for %s", op, field)
+		}
+	default:
+		switch op {
+		case "==", "!=", "^=":
+		default:
+			return nil, fmt.Errorf("invalid filter operator %s for %s", op, field)
+		}
+	}
+	switch kind {
+	case filterFieldUint:
+		f.number, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter value %s for %s", value, field)
+		}
+	case filterFieldAmount:
+		if !filterAmountRegexp.MatchString(value) {
+			return nil, fmt.Errorf("invalid filter value %s for %s", value, field)
+		}
+		f.amount = common.NewIntegerFromString(value)
+	}
+	return f, nil
+}
+
+func isFilterOperator(t string) bool {
+	switch t {
+	case "==", "!=", ">=", "<=", ">", "<", "^=", "&&", "||", "!", "(", ")":
+		return true
+	}
+	return false
+}
+
+func tokenizeFilter(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			end := strings.IndexByte(expr[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid filter unterminated string")
+			}
+			tokens = append(tokens, expr[i:i+end+2])
+			i = i + end + 2
+		case strings.ContainsRune("=!<>^&|", rune(c)):
+			op := expr[i : i+1]
+			if i+1 < len(expr) && isFilterOperator(expr[i:i+2]) {
+				op = expr[i : i+2]
+			}
+			if !isFilterOperator(op) {
+				return nil, fmt.Errorf("invalid filter operator %s", op)
+			}
+			tokens = append(tokens, op)
+			i += len(op)
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t\n()\"=!<>^&|", rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, expr[start:i])
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid filter empty")
+	}
+	return tokens, nil
+}
@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	asset := crypto.NewHash([]byte("c6d0c728-2624-429b-8e0d-d9d19b6592fa"))
+	k1 := crypto.NewKeyFromSeed(make([]byte, 64)).Public()
+	k2 := crypto.NewKeyFromSeed(append(make([]byte, 63), 1)).Public()
+	output := map[string]interface{}{
+		"type":     common.OutputTypeScript,
+		"hash":     crypto.NewHash([]byte("transaction")),
+		"index":    1,
+		"asset":    asset,
+		"amount":   common.NewIntegerFromString("1.5"),
+		"keys":     []*crypto.Key{&k1, &k2},
+		"script":   common.NewThresholdScript(1),
+		"topology": uint64(1024),
+	}
+
+	matches := map[string]bool{
+		"asset == " + asset.String():        true,
+		"asset != " + asset.String():        false,
+		"asset ^= " + asset.String()[:8]:    true,
+		`asset == "` + asset.String() + `"`: true,
+		"amount >= 1.5":                     true,
+		"amount > 1.5":                      false,
+		"amount < 2 && amount > 1":          true,
+		"index == 1 && topology >= 1000":    true,
+		"index == 0 || topology < 1000":     false,
+		"!(index == 0)":                     true,
+		"key ^= " + k2.String()[:6]:         true,
+		"key == " + k1.String():             true,
+		"key != " + k1.String():             false,
+		"lock == \"\"":                      true,
+		"asset == " + asset.String() + " && (amount > 2 || type == 0)": true,
+	}
+	for expr, match := range matches {
+		f, err := parseFilter(expr)
+		assert.Nil(err, expr)
+		assert.Equal(match, f.match(output), expr)
+	}
+	f, err := parseFilter(strings.Repeat("!", filterDepthLimit) + "index == 1")
+	assert.Nil(err)
+	assert.True(f.match(output))
+
+	invalids := []string{
+		"",
+		"asset",
+		"asset ==",
+		"unknown == 1",
+		"asset >= 1",
+		"amount ^= 1",
+		"amount >= -1",
+		"index == x",
+		"(index == 1",
+		"index == 1)",
+		"index == 1 &&",
+		"index = 1",
+		"index == )",
+		`asset == "abc`,
+		strings.Repeat("!", filterDepthLimit+1) + "index == 1",
+		strings.Repeat("(", filterDepthLimit+1) + "index == 1" + strings.Repeat(")", filterDepthLimit+1),
+		"index == 1" + strings.Repeat(" || index == 1", filterTokensLimit/4),
+	}
+	for _, expr := range invalids {
+		_, err := parseFilter(expr)
+		assert.NotNil(err, expr)
+	}
+}
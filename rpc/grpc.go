@@ -0,0 +1,208 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel"
+	"github.com/MixinNetwork/mixin/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	grpcServiceName          = "mixin.Mixin"
+	grpcListSnapshotsLimit   = 500
+	grpcFinalizationsBatch   = 100
+	grpcFinalizationsBackoff = time.Second
+)
+
+type grpcService struct {
+	store storage.Store
+	node  *kernel.Node
+}
+
+// NewGRPCServer serves the mixin.proto service, the strongly typed mirror of
+// the JSON-RPC read methods and sendrawtransaction, with the finalizations
+// stream for the clients to follow the topology without polling.
+func NewGRPCServer(store storage.Store, node *kernel.Node) *grpc.Server {
+	server := grpc.NewServer(grpc.ForceServerCodec(grpcCodec{}))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: grpcServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			grpcMethod("GetSnapshot", func() protoUnmarshaler { return &grpcHash{} }, grpcGetSnapshot),
+			grpcMethod("ListSnapshots", func() protoUnmarshaler { return &grpcListSnapshotsRequest{} }, grpcListSnapshots),
+			grpcMethod("GetTransaction", func() protoUnmarshaler { return &grpcHash{} }, grpcGetTransaction),
+			grpcMethod("GetRoundByNumber", func() protoUnmarshaler { return &grpcRoundRequest{} }, grpcGetRoundByNumber),
+			grpcMethod("GetRoundByHash", func() protoUnmarshaler { return &grpcHash{} }, grpcGetRoundByHash),
+			grpcMethod("ListSyncPoints", func() protoUnmarshaler { return &grpcEmpty{} }, grpcListSyncPoints),
+			grpcMethod("SendRawTransaction", func() protoUnmarshaler { return &grpcRawTransaction{} }, grpcSendRawTransaction),
+		},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "StreamFinalizations",
+			Handler:       grpcStreamFinalizations,
+			ServerStreams: true,
+		}},
+		Metadata: "mixin.proto",
+	}, &grpcService{store: store, node: node})
+	return server
+}
+
+func grpcMethod(name string, request func() protoUnmarshaler, call func(s *grpcService, req interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := request()
+			if err := dec(req); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			s := srv.(*grpcService)
+			if interceptor == nil {
+				return call(s, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + grpcServiceName + "/" + name}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(s, req)
+			})
+		},
+	}
+}
+
+func grpcGetSnapshot(s *grpcService, req interface{}) (interface{}, error) {
+	hash := req.(*grpcHash).Hash
+	snap, err := s.store.ReadSnapshot(hash)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, status.Errorf(codes.NotFound, "snapshot %s not found", hash)
+	}
+	tx, _, err := s.store.ReadTransaction(snap.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcSnapshot{snapshot: snap, tx: tx}, nil
+}
+
+func grpcListSnapshots(s *grpcService, req interface{}) (interface{}, error) {
+	r := req.(*grpcListSnapshotsRequest)
+	if r.Count == 0 || r.Count > grpcListSnapshotsLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid snapshots count %d", r.Count)
+	}
+	var snapshots []*common.SnapshotWithTopologicalOrder
+	var transactions []*common.VersionedTransaction
+	var err error
+	if r.Transaction {
+		snapshots, transactions, err = s.store.ReadSnapshotWithTransactionsSinceTopology(r.Offset, r.Count)
+	} else {
+		snapshots, err = s.store.ReadSnapshotsSinceTopology(r.Offset, r.Count)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return grpcSnapshotsList(snapshots, transactions), nil
+}
+
+func grpcGetTransaction(s *grpcService, req interface{}) (interface{}, error) {
+	hash := req.(*grpcHash).Hash
+	tx, snap, err := s.store.ReadTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, status.Errorf(codes.NotFound, "transaction %s not found", hash)
+	}
+	return &grpcTransaction{tx: tx, snapshot: snap}, nil
+}
+
+func grpcGetRoundByNumber(s *grpcService, req interface{}) (interface{}, error) {
+	r := req.(*grpcRoundRequest)
+	round, err := readRoundByNumber(s.store, r.Node, r.Number)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return (*grpcRound)(round), nil
+}
+
+func grpcGetRoundByHash(s *grpcService, req interface{}) (interface{}, error) {
+	round, err := readRoundByHash(s.store, req.(*grpcHash).Hash)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return (*grpcRound)(round), nil
+}
+
+func grpcListSyncPoints(s *grpcService, req interface{}) (interface{}, error) {
+	return grpcSyncPointList(s.node.BuildGraph()), nil
+}
+
+func grpcSendRawTransaction(s *grpcService, req interface{}) (interface{}, error) {
+	ver, err := common.UnmarshalVersionedTransaction(req.(*grpcRawTransaction).Raw)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := s.node.QueueTransaction(ver)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	hash, err := crypto.HashFromString(id)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcHash{Hash: hash}, nil
+}
+
+func grpcStreamFinalizations(srv interface{}, stream grpc.ServerStream) error {
+	s := srv.(*grpcService)
+	var req grpcFinalizationRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// the finalized events wake the stream up immediately, and the backoff
+	// still polls the store in case of the events dropped.
+	sub := s.node.SubscribeEvents(grpcFinalizationsBatch, kernel.EventSnapshotFinalized)
+	defer s.node.UnsubscribeEvents(sub)
+
+	offset := req.Offset
+	for {
+		snapshots, transactions, err := s.store.ReadSnapshotWithTransactionsSinceTopology(offset, grpcFinalizationsBatch)
+		if err != nil {
+			return err
+		}
+		for _, m := range grpcSnapshotsList(snapshots, transactions) {
+			if err := stream.SendMsg(m); err != nil {
+				return err
+			}
+			offset = m.snapshot.TopologicalOrder + 1
+		}
+		if len(snapshots) == grpcFinalizationsBatch {
+			continue
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-sub.C:
+			for len(sub.C) > 0 {
+				<-sub.C
+			}
+		case <-time.After(grpcFinalizationsBackoff):
+		}
+	}
+}
+
+func grpcSnapshotsList(snapshots []*common.SnapshotWithTopologicalOrder, transactions []*common.VersionedTransaction) grpcSnapshotList {
+	tx := len(transactions) == len(snapshots)
+	list := make(grpcSnapshotList, len(snapshots))
+	for i, s := range snapshots {
+		list[i] = &grpcSnapshot{snapshot: s}
+		if tx {
+			list[i].tx = transactions[i]
+		}
+	}
+	return list
+}
@@ -0,0 +1,360 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/network"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages of mixin.proto are encoded by hand with protowire, the
+// requests are decoded and the responses are encoded, so the node needs
+// no generated code while the clients generate theirs from the proto. The
+// encoding is tested against the descriptors built from mixin.proto.
+
+type protoMarshaler interface {
+	marshalProto() []byte
+}
+
+type protoUnmarshaler interface {
+	unmarshalProto(b []byte) error
+}
+
+type grpcCodec struct{}
+
+func (grpcCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(protoMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("invalid grpc message %T", v)
+	}
+	return m.marshalProto(), nil
+}
+
+func (grpcCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(protoUnmarshaler)
+	if !ok {
+		return fmt.Errorf("invalid grpc message %T", v)
+	}
+	return m.unmarshalProto(data)
+}
+
+func (grpcCodec) Name() string {
+	return "proto"
+}
+
+type grpcEmpty struct{}
+
+type grpcHash struct {
+	Hash crypto.Hash
+}
+
+type grpcRawTransaction struct {
+	Raw []byte
+}
+
+type grpcListSnapshotsRequest struct {
+	Offset      uint64
+	Count       uint64
+	Transaction bool
+}
+
+type grpcRoundRequest struct {
+	Node   crypto.Hash
+	Number uint64
+}
+
+type grpcFinalizationRequest struct {
+	Offset uint64
+}
+
+type grpcSnapshot struct {
+	snapshot *common.SnapshotWithTopologicalOrder
+	tx       *common.VersionedTransaction
+}
+
+type grpcTransaction struct {
+	tx       *common.VersionedTransaction
+	snapshot string
+}
+
+type grpcSnapshotList []*grpcSnapshot
+
+type grpcRound roundDetail
+
+type grpcSyncPointList []*network.SyncPoint
+
+func (m *grpcEmpty) marshalProto() []byte {
+	return nil
+}
+
+func (m *grpcEmpty) unmarshalProto(b []byte) error {
+	return consumeProto(b, nil)
+}
+
+func (m *grpcHash) marshalProto() []byte {
+	return appendBytesField(nil, 1, m.Hash[:])
+}
+
+func (m *grpcHash) unmarshalProto(b []byte) error {
+	return consumeProto(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 {
+			return consumeHashField(typ, b, &m.Hash)
+		}
+		return 0, nil
+	})
+}
+
+func (m *grpcRawTransaction) marshalProto() []byte {
+	return appendBytesField(nil, 1, m.Raw)
+}
+
+func (m *grpcRawTransaction) unmarshalProto(b []byte) error {
+	return consumeProto(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 {
+			return consumeBytesField(typ, b, &m.Raw)
+		}
+		return 0, nil
+	})
+}
+
+func (m *grpcListSnapshotsRequest) marshalProto() []byte {
+	b := appendVarintField(nil, 1, m.Offset)
+	b = appendVarintField(b, 2, m.Count)
+	return appendVarintField(b, 3, protowire.EncodeBool(m.Transaction))
+}
+
+func (m *grpcListSnapshotsRequest) unmarshalProto(b []byte) error {
+	return consumeProto(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeVarintField(typ, b, &m.Offset)
+		case 2:
+			return consumeVarintField(typ, b, &m.Count)
+		case 3:
+			var v uint64
+			n, err := consumeVarintField(typ, b, &v)
+			m.Transaction = protowire.DecodeBool(v)
+			return n, err
+		}
+		return 0, nil
+	})
+}
+
+func (m *grpcRoundRequest) marshalProto() []byte {
+	b := appendBytesField(nil, 1, m.Node[:])
+	return appendVarintField(b, 2, m.Number)
+}
+
+func (m *grpcRoundRequest) unmarshalProto(b []byte) error {
+	return consumeProto(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeHashField(typ, b, &m.Node)
+		case 2:
+			return consumeVarintField(typ, b, &m.Number)
+		}
+		return 0, nil
+	})
+}
+
+func (m *grpcFinalizationRequest) marshalProto() []byte {
+	return appendVarintField(nil, 1, m.Offset)
+}
+
+func (m *grpcFinalizationRequest) unmarshalProto(b []byte) error {
+	return consumeProto(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 {
+			return consumeVarintField(typ, b, &m.Offset)
+		}
+		return 0, nil
+	})
+}
+
+func marshalRoundLink(r *common.RoundLink) []byte {
+	b := appendBytesField(nil, 1, r.Self[:])
+	return appendBytesField(b, 2, r.External[:])
+}
+
+func (m *grpcTransaction) marshalProto() []byte {
+	tx := m.tx
+	hash := tx.PayloadHash()
+	b := appendBytesField(nil, 1, hash[:])
+	b = appendVarintField(b, 2, uint64(tx.Version))
+	b = appendBytesField(b, 3, tx.Asset[:])
+	for _, in := range tx.Inputs {
+		var ib []byte
+		if in.Hash.HasValue() {
+			ib = appendBytesField(ib, 1, in.Hash[:])
+			ib = appendVarintField(ib, 2, uint64(in.Index))
+		}
+		ib = appendBytesField(ib, 3, in.Genesis)
+		b = appendMessageField(b, 4, ib)
+	}
+	for _, out := range tx.Outputs {
+		ob := appendVarintField(nil, 1, uint64(out.Type))
+		ob = appendStringField(ob, 2, out.Amount.String())
+		for _, k := range out.Keys {
+			ob = appendMessageField(ob, 3, k[:])
+		}
+		ob = appendBytesField(ob, 4, out.Script)
+		if out.Mask.HasValue() {
+			ob = appendBytesField(ob, 5, out.Mask[:])
+		}
+		b = appendMessageField(b, 5, ob)
+	}
+	b = appendBytesField(b, 6, tx.Extra)
+	b = appendBytesField(b, 7, tx.Marshal())
+	if len(m.snapshot) > 0 {
+		snap, err := crypto.HashFromString(m.snapshot)
+		if err == nil {
+			b = appendBytesField(b, 8, snap[:])
+		}
+	}
+	return b
+}
+
+func (m *grpcSnapshot) marshalProto() []byte {
+	s := m.snapshot
+	b := appendBytesField(nil, 1, s.Hash[:])
+	b = appendVarintField(b, 2, uint64(s.Version))
+	b = appendBytesField(b, 3, s.NodeId[:])
+	b = appendVarintField(b, 4, s.RoundNumber)
+	b = appendVarintField(b, 5, s.Timestamp)
+	if s.References != nil {
+		b = appendMessageField(b, 6, marshalRoundLink(s.References))
+	}
+	b = appendVarintField(b, 7, s.TopologicalOrder)
+	b = appendBytesField(b, 8, s.Transaction[:])
+	if m.tx != nil {
+		tx := &grpcTransaction{tx: m.tx, snapshot: s.Hash.String()}
+		b = appendMessageField(b, 9, tx.marshalProto())
+	}
+	return b
+}
+
+func (m grpcSnapshotList) marshalProto() []byte {
+	var b []byte
+	for _, s := range m {
+		b = appendMessageField(b, 1, s.marshalProto())
+	}
+	return b
+}
+
+func (m *grpcRound) marshalProto() []byte {
+	b := appendBytesField(nil, 1, m.node[:])
+	b = appendBytesField(b, 2, m.hash[:])
+	b = appendVarintField(b, 3, m.number)
+	b = appendVarintField(b, 4, m.start)
+	b = appendVarintField(b, 5, m.end)
+	if m.references != nil {
+		b = appendMessageField(b, 6, marshalRoundLink(m.references))
+	}
+	for _, s := range m.snapshots {
+		b = appendMessageField(b, 7, (&grpcSnapshot{snapshot: s}).marshalProto())
+	}
+	return b
+}
+
+func (m grpcSyncPointList) marshalProto() []byte {
+	var b []byte
+	for _, p := range m {
+		pb := appendBytesField(nil, 1, p.NodeId[:])
+		pb = appendVarintField(pb, 2, p.Number)
+		pb = appendBytesField(pb, 3, p.Hash[:])
+		b = appendMessageField(b, 1, pb)
+	}
+	return b
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendMessageField(b, num, v)
+}
+
+func appendStringField(b []byte, num protowire.Number, v string) []byte {
+	return appendBytesField(b, num, []byte(v))
+}
+
+// appendMessageField appends the embedded message or the repeated bytes
+// even if empty, which are still meaningful as elements.
+func appendMessageField(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// consumeProto iterates the fields of the message, the field function
+// returns the bytes consumed, or zero to skip an unknown field.
+func consumeProto(b []byte, field func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n = 0
+		if field != nil {
+			var err error
+			n, err = field(num, typ, b)
+			if err != nil {
+				return err
+			}
+		}
+		if n == 0 {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func consumeVarintField(typ protowire.Type, b []byte, v *uint64) (int, error) {
+	if typ != protowire.VarintType {
+		return 0, fmt.Errorf("invalid varint field type %d", typ)
+	}
+	val, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*v = val
+	return n, nil
+}
+
+func consumeBytesField(typ protowire.Type, b []byte, v *[]byte) (int, error) {
+	if typ != protowire.BytesType {
+		return 0, fmt.Errorf("invalid bytes field type %d", typ)
+	}
+	val, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*v = append([]byte{}, val...)
+	return n, nil
+}
+
+func consumeHashField(typ protowire.Type, b []byte, h *crypto.Hash) (int, error) {
+	var val []byte
+	n, err := consumeBytesField(typ, b, &val)
+	if err != nil {
+		return 0, err
+	}
+	if len(val) != len(h) {
+		return 0, fmt.Errorf("invalid hash size %d", len(val))
+	}
+	copy(h[:], val)
+	return n, nil
+}
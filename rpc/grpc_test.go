@@ -0,0 +1,291 @@
+package rpc
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestGRPCMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	var codec grpcCodec
+	assert.Equal("proto", codec.Name())
+	_, err := codec.Marshal("snapshot")
+	assert.NotNil(err)
+	assert.NotNil(codec.Unmarshal(nil, &grpcSnapshot{}))
+
+	hash := crypto.NewHash([]byte("hash"))
+	data, err := codec.Marshal(&grpcHash{Hash: hash})
+	assert.Nil(err)
+	var h grpcHash
+	assert.Nil(codec.Unmarshal(data, &h))
+	assert.Equal(hash, h.Hash)
+	assert.NotNil(codec.Unmarshal(data[:20], &h))
+	assert.NotNil(codec.Unmarshal(appendBytesField(nil, 1, hash[:20]), &h))
+
+	list := &grpcListSnapshotsRequest{Offset: 1024, Count: 100, Transaction: true}
+	data, err = codec.Marshal(list)
+	assert.Nil(err)
+	var l grpcListSnapshotsRequest
+	assert.Nil(codec.Unmarshal(data, &l))
+	assert.Equal(*list, l)
+	data = appendStringField(data, 15, "unknown")
+	l = grpcListSnapshotsRequest{}
+	assert.Nil(codec.Unmarshal(data, &l))
+	assert.Equal(*list, l)
+	data = appendBytesField(nil, 1, []byte("offset"))
+	assert.NotNil(codec.Unmarshal(data, &l))
+
+	round := &grpcRoundRequest{Node: hash, Number: 7}
+	data, err = codec.Marshal(round)
+	assert.Nil(err)
+	var r grpcRoundRequest
+	assert.Nil(codec.Unmarshal(data, &r))
+	assert.Equal(*round, r)
+
+	var e grpcEmpty
+	assert.Nil(codec.Unmarshal(nil, &e))
+	var f grpcFinalizationRequest
+	assert.Nil(codec.Unmarshal(nil, &f))
+	assert.Equal(uint64(0), f.Offset)
+
+	snap := &common.SnapshotWithTopologicalOrder{
+		Snapshot: common.Snapshot{
+			Version:     common.SnapshotVersion,
+			NodeId:      hash,
+			RoundNumber: 12,
+			Timestamp:   1600000000000000000,
+			References:  &common.RoundLink{Self: hash, External: hash},
+			Transaction: crypto.NewHash([]byte("transaction")),
+		},
+		TopologicalOrder: 123456,
+	}
+	snap.Hash = snap.PayloadHash()
+	data, err = codec.Marshal(&grpcSnapshot{snapshot: snap})
+	assert.Nil(err)
+	fields := make(map[protowire.Number]int)
+	var topology uint64
+	err = consumeProto(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		fields[num]++
+		if num == 7 {
+			return consumeVarintField(typ, b, &topology)
+		}
+		return 0, nil
+	})
+	assert.Nil(err)
+	assert.Equal(uint64(123456), topology)
+	assert.Equal(map[protowire.Number]int{1: 1, 2: 1, 3: 1, 4: 1, 5: 1, 6: 1, 7: 1, 8: 1}, fields)
+}
+
+// TestGRPCDescriptors decodes the hand encoded messages with the descriptors
+// of mixin.proto, and encodes the requests with them, so the encoding never
+// drifts from the proto the clients generate their code from.
+func TestGRPCDescriptors(t *testing.T) {
+	assert := assert.New(t)
+
+	fd, err := parseProtoFile("mixin.proto")
+	assert.Nil(err)
+	desc := func(name string) protoreflect.MessageDescriptor {
+		return fd.Messages().ByName(protoreflect.Name(name))
+	}
+
+	hash := crypto.NewHash([]byte("hash"))
+	dm := dynamicpb.NewMessage(desc("ListSnapshotsRequest"))
+	dm.Set(dm.Descriptor().Fields().ByName("offset"), protoreflect.ValueOfUint64(1024))
+	dm.Set(dm.Descriptor().Fields().ByName("count"), protoreflect.ValueOfUint64(100))
+	dm.Set(dm.Descriptor().Fields().ByName("transaction"), protoreflect.ValueOfBool(true))
+	data, err := proto.Marshal(dm)
+	assert.Nil(err)
+	var l grpcListSnapshotsRequest
+	assert.Nil(l.unmarshalProto(data))
+	assert.Equal(grpcListSnapshotsRequest{Offset: 1024, Count: 100, Transaction: true}, l)
+
+	dm = dynamicpb.NewMessage(desc("RoundRequest"))
+	dm.Set(dm.Descriptor().Fields().ByName("node"), protoreflect.ValueOfBytes(hash[:]))
+	dm.Set(dm.Descriptor().Fields().ByName("number"), protoreflect.ValueOfUint64(7))
+	data, err = proto.Marshal(dm)
+	assert.Nil(err)
+	var r grpcRoundRequest
+	assert.Nil(r.unmarshalProto(data))
+	assert.Equal(grpcRoundRequest{Node: hash, Number: 7}, r)
+
+	dm = dynamicpb.NewMessage(desc("RawTransaction"))
+	dm.Set(dm.Descriptor().Fields().ByName("raw"), protoreflect.ValueOfBytes([]byte("raw")))
+	data, err = proto.Marshal(dm)
+	assert.Nil(err)
+	var raw grpcRawTransaction
+	assert.Nil(raw.unmarshalProto(data))
+	assert.Equal([]byte("raw"), raw.Raw)
+
+	dm = dynamicpb.NewMessage(desc("FinalizationRequest"))
+	dm.Set(dm.Descriptor().Fields().ByName("offset"), protoreflect.ValueOfUint64(99))
+	data, err = proto.Marshal(dm)
+	assert.Nil(err)
+	var f grpcFinalizationRequest
+	assert.Nil(f.unmarshalProto(data))
+	assert.Equal(uint64(99), f.Offset)
+
+	seed := make([]byte, 64)
+	account := common.NewAddressFromSeed(seed)
+	tx := common.NewTransaction(common.XINAssetId)
+	tx.AddInput(hash, 1)
+	tx.AddScriptOutput([]*common.Address{&account}, common.NewThresholdScript(1), common.NewIntegerFromString("1.5"), seed)
+	tx.Extra = []byte("extra")
+	ver := tx.AsLatestVersion()
+	snap := &common.SnapshotWithTopologicalOrder{
+		Snapshot: common.Snapshot{
+			Version:     common.SnapshotVersion,
+			NodeId:      hash,
+			RoundNumber: 12,
+			Timestamp:   1600000000000000000,
+			References:  &common.RoundLink{Self: hash, External: hash},
+			Transaction: ver.PayloadHash(),
+		},
+		TopologicalOrder: 123456,
+	}
+	snap.Hash = snap.PayloadHash()
+
+	list := grpcSnapshotList{{snapshot: snap, tx: ver}}
+	dm = unmarshalDynamic(assert, desc("SnapshotList"), list.marshalProto())
+	snapshots := dm.Get(dm.Descriptor().Fields().ByName("snapshots")).List()
+	assert.Equal(1, snapshots.Len())
+	ds := snapshots.Get(0).Message()
+	assert.Equal(snap.Hash[:], dynamicField(ds, "hash").Bytes())
+	assert.Equal(uint64(snap.Version), dynamicField(ds, "version").Uint())
+	assert.Equal(hash[:], dynamicField(ds, "node").Bytes())
+	assert.Equal(uint64(12), dynamicField(ds, "round").Uint())
+	assert.Equal(snap.Timestamp, dynamicField(ds, "timestamp").Uint())
+	assert.Equal(hash[:], dynamicField(dynamicField(ds, "references").Message(), "external").Bytes())
+	assert.Equal(uint64(123456), dynamicField(ds, "topology").Uint())
+	assert.Equal(snap.Transaction[:], dynamicField(ds, "transaction_hash").Bytes())
+
+	dt := dynamicField(ds, "transaction").Message()
+	assert.Equal(snap.Transaction[:], dynamicField(dt, "hash").Bytes())
+	assert.Equal(uint64(ver.Version), dynamicField(dt, "version").Uint())
+	assert.Equal(common.XINAssetId[:], dynamicField(dt, "asset").Bytes())
+	assert.Equal([]byte("extra"), dynamicField(dt, "extra").Bytes())
+	assert.Equal(ver.Marshal(), dynamicField(dt, "raw").Bytes())
+	assert.Equal(snap.Hash[:], dynamicField(dt, "snapshot").Bytes())
+	inputs := dynamicField(dt, "inputs").List()
+	assert.Equal(1, inputs.Len())
+	assert.Equal(hash[:], dynamicField(inputs.Get(0).Message(), "hash").Bytes())
+	assert.Equal(uint64(1), dynamicField(inputs.Get(0).Message(), "index").Uint())
+	outputs := dynamicField(dt, "outputs").List()
+	assert.Equal(1, outputs.Len())
+	out := outputs.Get(0).Message()
+	assert.Equal(uint64(common.OutputTypeScript), dynamicField(out, "type").Uint())
+	assert.Equal("1.50000000", dynamicField(out, "amount").String())
+	assert.Equal(1, dynamicField(out, "keys").List().Len())
+	assert.Equal(ver.Outputs[0].Keys[0][:], dynamicField(out, "keys").List().Get(0).Bytes())
+	assert.Equal([]byte(ver.Outputs[0].Script), dynamicField(out, "script").Bytes())
+	assert.Equal(ver.Outputs[0].Mask[:], dynamicField(out, "mask").Bytes())
+
+	round := &grpcRound{node: hash, hash: snap.Hash, number: 12, start: 1, end: 2, references: snap.References, snapshots: []*common.SnapshotWithTopologicalOrder{snap}}
+	dm = unmarshalDynamic(assert, desc("Round"), round.marshalProto())
+	assert.Equal(snap.Hash[:], dynamicField(dm, "hash").Bytes())
+	assert.Equal(uint64(2), dynamicField(dm, "end").Uint())
+	assert.Equal(1, dynamicField(dm, "snapshots").List().Len())
+
+	points := grpcSyncPointList{{NodeId: hash, Number: 3, Hash: snap.Hash}}
+	dm = unmarshalDynamic(assert, desc("SyncPointList"), points.marshalProto())
+	dp := dynamicField(dm, "points").List().Get(0).Message()
+	assert.Equal(hash[:], dynamicField(dp, "node").Bytes())
+	assert.Equal(uint64(3), dynamicField(dp, "number").Uint())
+	assert.Equal(snap.Hash[:], dynamicField(dp, "hash").Bytes())
+
+	dm = unmarshalDynamic(assert, desc("Hash"), (&grpcHash{Hash: hash}).marshalProto())
+	assert.Equal(hash[:], dynamicField(dm, "hash").Bytes())
+}
+
+func unmarshalDynamic(assert *assert.Assertions, desc protoreflect.MessageDescriptor, data []byte) *dynamicpb.Message {
+	dm := dynamicpb.NewMessage(desc)
+	assert.Nil(proto.Unmarshal(data, dm))
+	assertNoUnknownFields(assert, dm)
+	return dm
+}
+
+func assertNoUnknownFields(assert *assert.Assertions, m protoreflect.Message) {
+	assert.Len(m.GetUnknown(), 0, string(m.Descriptor().Name()))
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < v.List().Len(); i++ {
+				assertNoUnknownFields(assert, v.List().Get(i).Message())
+			}
+		} else {
+			assertNoUnknownFields(assert, v.Message())
+		}
+		return true
+	})
+}
+
+func dynamicField(m protoreflect.Message, name string) protoreflect.Value {
+	return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(name)))
+}
+
+// parseProtoFile builds the descriptor of the proto file, which only has the
+// flat messages of the scalar, bytes and message fields as mixin.proto.
+func parseProtoFile(name string) (protoreflect.FileDescriptor, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	src := regexp.MustCompile(`//[^\n]*`).ReplaceAllString(string(b), "")
+	pkg := regexp.MustCompile(`package\s+(\w+)\s*;`).FindStringSubmatch(src)
+	if pkg == nil {
+		return nil, fmt.Errorf("proto package not found")
+	}
+	scalars := map[string]descriptorpb.FieldDescriptorProto_Type{
+		"bytes":  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+		"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		"uint64": descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		"uint32": descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+		"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(name),
+		Package: proto.String(pkg[1]),
+		Syntax:  proto.String("proto3"),
+	}
+	messageRegexp := regexp.MustCompile(`message\s+(\w+)\s*\{([^}]*)\}`)
+	fieldRegexp := regexp.MustCompile(`(repeated\s+)?(\w+)\s+(\w+)\s*=\s*(\d+)\s*;`)
+	for _, m := range messageRegexp.FindAllStringSubmatch(src, -1) {
+		msg := &descriptorpb.DescriptorProto{Name: proto.String(m[1])}
+		for _, f := range fieldRegexp.FindAllStringSubmatch(m[2], -1) {
+			num, _ := strconv.Atoi(f[4])
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String(f[3]),
+				JsonName: proto.String(f[3]),
+				Number:   proto.Int32(int32(num)),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			}
+			if f[1] != "" {
+				field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			}
+			if typ, found := scalars[f[2]]; found {
+				field.Type = typ.Enum()
+			} else {
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+				field.TypeName = proto.String("." + pkg[1] + "." + f[2])
+			}
+			msg.Field = append(msg.Field, field)
+		}
+		file.MessageType = append(file.MessageType, msg)
+	}
+	return protodesc.NewFile(file, nil)
+}
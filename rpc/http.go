@@ -7,14 +7,20 @@ import (
 	"time"
 
 	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/kernel"
 	"github.com/MixinNetwork/mixin/storage"
 )
 
+// rpcBodyLimit is enough for the hex of the largest transaction in the params.
+const rpcBodyLimit = config.TransactionMaximumSize*2 + 64*1024
+
 type RPC struct {
-	Store  storage.Store
-	Node   *kernel.Node
-	custom *config.Custom
+	Store   storage.Store
+	Node    *kernel.Node
+	custom  *config.Custom
+	domains map[string]string
+	nonces  *attestationNonces
 }
 
 type Call struct {
@@ -74,7 +80,7 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var call Call
-	d := json.NewDecoder(r.Body)
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, rpcBodyLimit))
 	d.UseNumber()
 	if err := d.Decode(&call); err != nil {
 		rdr.RenderError(fmt.Errorf("bad request %s", err.Error()))
@@ -92,6 +98,34 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			renderer.RenderData(info)
 		}
+	case "gethealth":
+		health, err := getHealth(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(health)
+		}
+	case "getnodestatus":
+		status, err := getNodeStatus(impl.Store, impl.Node, impl.custom, impl.domains, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(status)
+		}
+	case "storageinfo":
+		info, err := getStorageInfo(impl.Store, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(info)
+		}
+	case "getupgradereadiness":
+		readiness, err := getUpgradeReadiness(impl.Node)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(readiness)
+		}
 	case "dumpgraphhead":
 		data, err := dumpGraphHead(impl.Node, call.Params)
 		if err != nil {
@@ -99,12 +133,33 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			renderer.RenderData(data)
 		}
+	case "describeschema":
+		schema, err := describeSchema(call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(schema)
+		}
+	case "listdomainchains":
+		chains, err := listDomainChains(call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(chains)
+		}
 	case "sendrawtransaction":
-		id, err := queueTransaction(impl.Node, call.Params)
+		data, err := queueTransaction(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(data)
+		}
+	case "validaterawtransaction":
+		data, err := validateRawTransaction(impl.Node, impl.Store, call.Params)
 		if err != nil {
 			renderer.RenderError(err)
 		} else {
-			renderer.RenderData(map[string]string{"hash": id})
+			renderer.RenderData(data)
 		}
 	case "gettransaction":
 		tx, err := getTransaction(impl.Store, call.Params)
@@ -127,6 +182,34 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			renderer.RenderData(utxo)
 		}
+	case "getfinalizationbundle":
+		bundle, err := getFinalizationBundle(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(bundle)
+		}
+	case "getatomicswap":
+		swap, err := getAtomicSwap(impl.Store, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(swap)
+		}
+	case "listoutputsforkey":
+		outputs, err := listOutputsForKey(impl.Store, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(outputs)
+		}
+	case "diffoutputs":
+		diff, err := diffOutputs(impl.Store, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(diff)
+		}
 	case "getkey":
 		utxo, err := getGhostKey(impl.Store, call.Params)
 		if err != nil {
@@ -142,11 +225,55 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			renderer.RenderData(snap)
 		}
 	case "listsnapshots":
-		snapshots, err := listSnapshots(impl.Node, impl.Store, call.Params)
+		if len(call.Params) == 5 {
+			data, err := filterSnapshots(impl.Node, impl.Store, call.Params)
+			if err != nil {
+				renderer.RenderError(err)
+			} else {
+				renderer.RenderData(data)
+			}
+		} else {
+			snapshots, err := listSnapshots(impl.Node, impl.Store, call.Params)
+			if err != nil {
+				renderer.RenderError(err)
+			} else {
+				renderer.RenderData(snapshots)
+			}
+		}
+	case "readcursor":
+		cursor, err := readCursor(impl.Node, impl.Store, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(cursor)
+		}
+	case "getcursor":
+		cursor, err := getCursor(impl.Store, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(cursor)
+		}
+	case "setcursor":
+		cursor, err := setCursor(impl.Store, r.RemoteAddr, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(cursor)
+		}
+	case "removecursor":
+		cursor, err := removeCursor(impl.Store, r.RemoteAddr, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(cursor)
+		}
+	case "listcursors":
+		cursors, err := listCursors(impl.Store, call.Params)
 		if err != nil {
 			renderer.RenderError(err)
 		} else {
-			renderer.RenderData(snapshots)
+			renderer.RenderData(cursors)
 		}
 	case "listmintworks":
 		works, err := listMintWorks(impl.Node, call.Params)
@@ -155,13 +282,27 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			renderer.RenderData(works)
 		}
+	case "mintsimulate":
+		sim, err := simulateMint(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(sim)
+		}
 	case "listmintdistributions":
-		distributions, err := listMintDistributions(impl.Store, call.Params)
+		distributions, err := listMintDistributions(impl.Store, impl.Node, call.Params)
 		if err != nil {
 			renderer.RenderError(err)
 		} else {
 			renderer.RenderData(distributions)
 		}
+	case "listfeestats":
+		stats, err := listFeeStats(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(stats)
+		}
 	case "listallnodes":
 		nodes, err := listAllNodes(impl.Store, impl.Node, call.Params)
 		if err != nil {
@@ -169,6 +310,90 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			renderer.RenderData(nodes)
 		}
+	case "listconsensusnodes":
+		set, err := listConsensusNodes(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(set)
+		}
+	case "buildnoderemovalproposal":
+		proposal, err := buildNodeRemovalProposal(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(proposal)
+		}
+	case "getcustodian":
+		custodian, err := getCustodian(impl.Store, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(custodian)
+		}
+	case "getgovernancetally":
+		tally, err := getGovernanceTally(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(tally)
+		}
+	case "listnodemodifications":
+		mods, err := listNodeModifications(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(mods)
+		}
+	case "getpeergraph":
+		graph, err := getPeerGraph(impl.Node, impl.custom.RPC.PeerGraph, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(graph)
+		}
+	case "liststalepeers":
+		events, err := listStalePeers(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(events)
+		}
+	case "exportpeers":
+		book, err := exportPeers(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(book)
+		}
+	case "importpeers":
+		result, err := importPeers(impl.Node, r.RemoteAddr, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(result)
+		}
+	case "getpartition":
+		state, err := getPartition(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(state)
+		}
+	case "listsignerstats":
+		stats, err := listSignerStats(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(stats)
+		}
+	case "listcheckpoints":
+		checkpoints, err := listCheckpoints(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(checkpoints)
+		}
 	case "getroundbynumber":
 		round, err := getRoundByNumber(impl.Node, impl.Store, call.Params)
 		if err != nil {
@@ -176,6 +401,13 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			renderer.RenderData(round)
 		}
+	case "graphdump":
+		graph, err := graphDump(impl.Node, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(graph)
+		}
 	case "getroundbyhash":
 		round, err := getRoundByHash(impl.Node, impl.Store, call.Params)
 		if err != nil {
@@ -183,6 +415,13 @@ func (impl *RPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else {
 			renderer.RenderData(round)
 		}
+	case "getattestation":
+		attestation, err := getAttestation(impl.Store, impl.Node, impl.custom.RPC.Observers, impl.nonces, call.Params)
+		if err != nil {
+			renderer.RenderError(err)
+		} else {
+			renderer.RenderData(attestation)
+		}
 	case "getroundlink":
 		link, err := getRoundLink(impl.Store, call.Params)
 		if err != nil {
@@ -215,8 +454,16 @@ func handleCORS(handler http.Handler) http.Handler {
 	})
 }
 
-func NewServer(custom *config.Custom, store storage.Store, node *kernel.Node, port int) *http.Server {
-	rpc := &RPC{Store: store, Node: node, custom: custom}
+// NewServer serves the RPCs of the node, the domains are the self-test
+// results of the built-in domain vectors reported by getnodestatus.
+func NewServer(custom *config.Custom, store storage.Store, node *kernel.Node, domains map[string]string, port int) *http.Server {
+	rpc := &RPC{
+		Store:   store,
+		Node:    node,
+		custom:  custom,
+		domains: domains,
+		nonces:  &attestationNonces{m: make(map[crypto.Hash]time.Time)},
+	}
 	handler := handleCORS(rpc)
 
 	server := &http.Server{
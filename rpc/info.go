@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/kernel"
 	"github.com/MixinNetwork/mixin/storage"
+	"github.com/MixinNetwork/mixin/util"
 )
 
 func getInfo(store storage.Store, node *kernel.Node) (map[string]interface{}, error) {
@@ -34,6 +36,15 @@ func getInfo(store storage.Store, node *kernel.Node) (map[string]interface{}, er
 		"batch":  md.Batch,
 		"pledge": node.PledgeAmount(node.GraphTimestamp),
 	}
+	skew := node.ClockSkewStats()
+	info["clock"] = map[string]interface{}{
+		"drift":     skew.Drift.String(),
+		"peers":     skew.Peers,
+		"threshold": skew.Threshold.String(),
+		"exceeded":  skew.Exceeded,
+		"paused":    skew.Paused,
+		"futures":   skew.Futures,
+	}
 	cacheMap, finalMap := node.LoadRoundGraph()
 	cacheGraph := make(map[string]interface{})
 	for n, r := range cacheMap {
@@ -110,14 +121,209 @@ func getInfo(store storage.Store, node *kernel.Node) (map[string]interface{}, er
 		"tps":       node.TPS(),
 	}
 	caches, finals, state := node.QueueState()
+	limits := make(map[string]interface{})
+	for typ, stats := range node.LimiterStats() {
+		peers := make(map[string]interface{})
+		for id, s := range stats {
+			peers[id] = map[string]interface{}{
+				"allowed": s.Allowed,
+				"shed":    s.Shed,
+			}
+		}
+		limits[typ] = peers
+	}
+	announcements := make(map[string]interface{})
+	for id, s := range node.AnnouncementThrottleStats() {
+		announcements[id.String()] = map[string]interface{}{
+			"inflight":  s.InFlight,
+			"queued":    s.Queued,
+			"admitted":  s.Admitted,
+			"throttled": s.Throttled,
+			"dropped":   s.Dropped,
+		}
+	}
+	cs := store.CacheTransactionStats()
+	var hitRate float64
+	if cs.Hits+cs.Misses > 0 {
+		hitRate = float64(cs.Hits) / float64(cs.Hits+cs.Misses)
+	}
+	ss := node.SignatureCacheStats()
+	rs := node.RequeueStats()
+	es := node.EventBusStats()
+	var signatureHitRate float64
+	if ss.Hits+ss.Misses > 0 {
+		signatureHitRate = float64(ss.Hits) / float64(ss.Hits+ss.Misses)
+	}
 	info["queue"] = map[string]interface{}{
-		"finals": finals,
-		"caches": caches,
-		"state":  state,
+		"finals":        finals,
+		"caches":        caches,
+		"state":         state,
+		"limits":        limits,
+		"announcements": announcements,
+		"transactions": map[string]interface{}{
+			"size":      cs.Size,
+			"limit":     cs.Limit,
+			"puts":      cs.Puts,
+			"hits":      cs.Hits,
+			"misses":    cs.Misses,
+			"hit_rate":  hitRate,
+			"evictions": cs.Evictions,
+		},
+		"signatures": map[string]interface{}{
+			"size":      ss.Size,
+			"limit":     ss.Limit,
+			"hits":      ss.Hits,
+			"misses":    ss.Misses,
+			"hit_rate":  signatureHitRate,
+			"evictions": ss.Evictions,
+		},
+		"cosi":  queueSaturationToMap(node.CosiQueueStats()),
+		"peers": queueSaturationToMap(node.Peer.SendQueueStats()),
+		"requeue": map[string]interface{}{
+			"depth":        rs.Depth,
+			"capacity":     rs.Capacity,
+			"queued":       rs.Queued,
+			"deduplicated": rs.Deduplicated,
+			"evicted":      rs.Evicted,
+			"retries":      rs.Retries,
+			"removed":      rs.Removed,
+		},
+		"events": map[string]interface{}{
+			"subscribers": es.Subscribers,
+			"published":   es.Published,
+			"dropped":     es.Dropped,
+		},
 	}
 	return info, nil
 }
 
+// getHealth is the cheap probe of getinfo for load balancers, which only
+// route to the ready nodes.
+func getHealth(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	h, err := node.Health()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"ready":  h.Ready,
+		"synced": h.Synced,
+		"lag":    h.Lag.String(),
+		"peers":  h.Peers,
+		"queue": map[string]interface{}{
+			"caches": h.Caches,
+			"finals": h.Finals,
+		},
+		"disk":  h.DiskUsage,
+		"state": h.State,
+	}, nil
+}
+
+// getNodeStatus aggregates the consensus, storage, network and domains
+// health in one document for the orchestration systems, instead of the
+// several RPCs of each part.
+func getNodeStatus(store storage.Store, node *kernel.Node, custom *config.Custom, domains map[string]string, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	h, err := node.Health()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, finalMap := node.LoadRoundGraph()
+	chains := make(map[string]interface{})
+	for id, r := range finalMap {
+		chains[id.String()] = map[string]interface{}{
+			"round": r.Number,
+			"lag":   now.Sub(time.Unix(0, int64(r.End))).String(),
+		}
+	}
+
+	cs := store.CacheTransactionStats()
+	accounts := make([]string, 0)
+	for _, d := range store.ReadDomains() {
+		accounts = append(accounts, d.Account.String())
+	}
+	hello := node.Peer.LocalHello()
+	gs := node.Peer.GossipFilterStats()
+	return map[string]interface{}{
+		"ready": h.Ready,
+		"consensus": map[string]interface{}{
+			"state":     h.State,
+			"synced":    h.Synced,
+			"lag":       h.Lag.String(),
+			"topology":  node.TopologicalOrder(),
+			"threshold": node.ConsensusThreshold(uint64(now.UnixNano()), false),
+			"chains":    chains,
+			"drift":     node.ClockSkewStats().Drift.String(),
+		},
+		"storage": map[string]interface{}{
+			"disk": h.DiskUsage,
+			"transactions": map[string]interface{}{
+				"size":      cs.Size,
+				"limit":     cs.Limit,
+				"evictions": cs.Evictions,
+			},
+		},
+		"network": map[string]interface{}{
+			"peers": h.Peers,
+			"queue": queueSaturationToMap(node.Peer.SendQueueStats()),
+			"gossip": map[string]interface{}{
+				"checked": gs.Checked,
+				"skipped": gs.Skipped,
+			},
+		},
+		"domains": map[string]interface{}{
+			"accounts": accounts,
+			"selftest": domains,
+		},
+		"version": map[string]interface{}{
+			"build":        config.BuildVersion,
+			"protocol":     hello.Version,
+			"capabilities": hello.Capabilities,
+			"upgrade":      custom.Upgrade.Capabilities,
+		},
+	}, nil
+}
+
+func getStorageInfo(store storage.Store, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	info := store.StorageInfo()
+	return map[string]interface{}{
+		"profile":             info.Profile,
+		"value-log-file-size": info.ValueLogFileSize,
+		"memtable-size":       info.MemTableSize,
+		"memtables":           info.NumMemTables,
+		"block-cache-size":    info.BlockCacheSize,
+		"index-cache-size":    info.IndexCacheSize,
+		"compression":         info.Compression,
+		"snapshots": map[string]interface{}{
+			"lsm":  info.Snapshots.LSM,
+			"vlog": info.Snapshots.ValueLog,
+		},
+		"cache": map[string]interface{}{
+			"lsm":  info.Cache.LSM,
+			"vlog": info.Cache.ValueLog,
+		},
+	}, nil
+}
+
+func queueSaturationToMap(qs util.QueueSaturationStats) map[string]interface{} {
+	return map[string]interface{}{
+		"capacity": qs.Capacity,
+		"peak":     qs.Peak,
+		"offered":  qs.Offered,
+		"full":     qs.Full,
+		"dropped":  qs.Dropped,
+	}
+}
+
 func dumpGraphHead(node *kernel.Node, params []interface{}) (interface{}, error) {
 	rounds := node.BuildGraph()
 	sort.Slice(rounds, func(i, j int) bool { return fmt.Sprint(rounds[i].NodeId) < fmt.Sprint(rounds[j].NodeId) })
@@ -30,7 +30,48 @@ func listMintWorks(node *kernel.Node, params []interface{}) (map[string]interfac
 	return wm, nil
 }
 
-func listMintDistributions(store storage.Store, params []interface{}) ([]map[string]interface{}, error) {
+func simulateMint(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 2 {
+		return nil, errors.New("invalid params count")
+	}
+	start, err := strconv.ParseUint(fmt.Sprint(params[0]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	end, err := strconv.ParseUint(fmt.Sprint(params[1]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	sim, err := node.SimulateMint(start, end)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]map[string]interface{}, len(sim.Nodes))
+	for i, n := range sim.Nodes {
+		nodes[i] = map[string]interface{}{
+			"id":     n.IdForNetwork,
+			"signer": n.Signer.String(),
+			"payee":  n.Payee.String(),
+			"amount": n.Work,
+		}
+	}
+	batches := make([]map[string]interface{}, len(sim.Batches))
+	for i, b := range sim.Batches {
+		batches[i] = map[string]interface{}{
+			"batch":  b.Batch,
+			"amount": b.Amount,
+			"node":   b.Node,
+		}
+	}
+	return map[string]interface{}{
+		"amount":  sim.Amount,
+		"nodes":   nodes,
+		"batches": batches,
+	}, nil
+}
+
+func listMintDistributions(store storage.Store, node *kernel.Node, params []interface{}) ([]map[string]interface{}, error) {
 	if len(params) != 3 {
 		return nil, errors.New("invalid params count")
 	}
@@ -48,7 +89,18 @@ func listMintDistributions(store storage.Store, params []interface{}) ([]map[str
 	}
 
 	mints, transactions, err := store.ReadMintDistributions(common.MintGroupKernelNode, offset, count)
-	return mintsToMap(mints, transactions, tx), err
+	if err != nil {
+		return nil, err
+	}
+	result := mintsToMap(mints, transactions, tx)
+	for i, m := range mints {
+		stats, err := node.ListFeeStats(m.Batch)
+		if err != nil {
+			return nil, err
+		}
+		result[i]["fees"] = feeEntryToMap(stats.Total)
+	}
+	return result, nil
 }
 
 func mintsToMap(mints []*common.MintDistribution, transactions []*common.VersionedTransaction, tx bool) []map[string]interface{} {
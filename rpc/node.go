@@ -1,12 +1,17 @@
 package rpc
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"time"
 
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
 	"github.com/MixinNetwork/mixin/kernel"
+	"github.com/MixinNetwork/mixin/network"
 	"github.com/MixinNetwork/mixin/storage"
 )
 
@@ -40,3 +45,274 @@ func listAllNodes(store storage.Store, node *kernel.Node, params []interface{})
 	}
 	return result, nil
 }
+
+func listConsensusNodes(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.New("invalid params count")
+	}
+	timestamp, err := strconv.ParseUint(fmt.Sprint(params[0]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp == 0 {
+		timestamp = uint64(time.Now().UnixNano())
+	}
+	set := node.ListConsensusNodes(timestamp)
+	nodes := make([]map[string]interface{}, len(set.Nodes))
+	for i, n := range set.Nodes {
+		nodes[i] = map[string]interface{}{
+			"id":          n.IdForNetwork,
+			"signer":      n.Signer,
+			"payee":       n.Payee,
+			"key":         set.Keys[i],
+			"transaction": n.Transaction,
+			"timestamp":   n.Timestamp,
+			"state":       n.State,
+		}
+	}
+	return map[string]interface{}{
+		"timestamp": set.Timestamp,
+		"threshold": set.Threshold,
+		"nodes":     nodes,
+	}, nil
+}
+
+func listStalePeers(node *kernel.Node, params []interface{}) ([]map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	events := node.Peer.StalePeerEvents()
+	result := make([]map[string]interface{}, len(events))
+	for i, e := range events {
+		result[i] = map[string]interface{}{
+			"peer":      e.Peer,
+			"address":   e.Address,
+			"action":    e.Action,
+			"height":    e.Height,
+			"advanced":  e.Advanced.UnixNano(),
+			"timestamp": e.Timestamp.UnixNano(),
+		}
+	}
+	return result, nil
+}
+
+func exportPeers(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	entries := node.Peer.ExportAddressBook()
+	peers := make([]map[string]interface{}, len(entries))
+	for i, e := range entries {
+		peers[i] = map[string]interface{}{
+			"id":       e.Id,
+			"listener": e.Listener,
+			"seen":     e.Seen,
+		}
+	}
+	return map[string]interface{}{
+		"book":  hex.EncodeToString(network.EncodeAddressBook(entries)),
+		"peers": peers,
+	}, nil
+}
+
+// importPeers is only allowed from the loopback, because the imported peers
+// not connected yet are pinged by the node.
+func importPeers(node *kernel.Node, remote string, params []interface{}) (map[string]interface{}, error) {
+	if !isLoopbackAddress(remote) {
+		return nil, fmt.Errorf("importpeers not allowed from %s", remote)
+	}
+	if len(params) != 1 {
+		return nil, errors.New("invalid params count")
+	}
+	data, err := hex.DecodeString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := network.DecodeAddressBook(data)
+	if err != nil {
+		return nil, err
+	}
+	imported := node.Peer.ImportAddressBook(entries)
+	return map[string]interface{}{
+		"imported": imported,
+		"total":    len(entries),
+	}, nil
+}
+
+func getPartition(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	state := node.Peer.PartitionState()
+	events := make([]map[string]interface{}, len(state.Events))
+	for i, e := range state.Events {
+		events[i] = map[string]interface{}{
+			"action":    e.Action,
+			"height":    e.Height,
+			"ahead":     e.Ahead,
+			"neighbors": e.Neighbors,
+			"timestamp": e.Timestamp.UnixNano(),
+		}
+	}
+	var since int64
+	if state.Partitioned {
+		since = state.Since.UnixNano()
+	}
+	return map[string]interface{}{
+		"partitioned": state.Partitioned,
+		"since":       since,
+		"height":      state.Height,
+		"advanced":    state.Advanced.UnixNano(),
+		"ahead":       state.Ahead,
+		"neighbors":   state.Neighbors,
+		"resyncs":     state.Resyncs,
+		"events":      events,
+	}, nil
+}
+
+func listSignerStats(node *kernel.Node, params []interface{}) ([]map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	epochs := node.ListSignerStats()
+	result := make([]map[string]interface{}, len(epochs))
+	for i, e := range epochs {
+		signers := make([]map[string]interface{}, len(e.Signers))
+		for j, s := range e.Signers {
+			signers[j] = map[string]interface{}{
+				"signer":        s.Signer,
+				"eligible":      s.Eligible,
+				"signed":        s.Signed,
+				"commitments":   s.Commitments,
+				"responses":     s.Responses,
+				"participation": fmt.Sprintf("%.4f", s.Participation()),
+			}
+		}
+		result[i] = map[string]interface{}{
+			"epoch":   e.Epoch,
+			"signers": signers,
+		}
+	}
+	return result, nil
+}
+
+func listCheckpoints(node *kernel.Node, params []interface{}) ([]map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	states, err := node.ListCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, len(states))
+	for i, cs := range states {
+		result[i] = map[string]interface{}{
+			"node":     cs.Node,
+			"round":    cs.Round,
+			"snapshot": cs.Snapshot,
+			"state":    cs.State,
+		}
+	}
+	return result, nil
+}
+
+func getPeerGraph(node *kernel.Node, public bool, params []interface{}) (*kernel.PeerGraph, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	if !public {
+		return nil, errors.New("peer graph not shared by this node")
+	}
+	return node.SignedPeerGraph(), nil
+}
+
+func buildNodeRemovalProposal(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 2 {
+		return nil, errors.New("invalid params count")
+	}
+	accept, err := crypto.HashFromString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(fmt.Sprint(params[1]))
+	if err != nil {
+		return nil, err
+	}
+	endorsements, err := common.DecodeNodeRemovalEndorsements(accept, data)
+	if err != nil {
+		return nil, err
+	}
+	ver, err := node.BuildNodeRemovalProposal(accept, endorsements)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"hash": ver.PayloadHash(),
+		"raw":  hex.EncodeToString(ver.Marshal()),
+	}, nil
+}
+
+func getGovernanceTally(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.New("invalid params count")
+	}
+	proposal, err := crypto.HashFromString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	tally, err := node.TallyGovernanceSignals(proposal)
+	if err != nil {
+		return nil, err
+	}
+	votes := make([]map[string]interface{}, len(tally.Votes))
+	for i, v := range tally.Votes {
+		votes[i] = map[string]interface{}{
+			"signer":      v.Signer,
+			"weight":      v.Weight,
+			"transaction": v.Transaction,
+			"timestamp":   v.Timestamp,
+		}
+	}
+	return map[string]interface{}{
+		"proposal":  tally.Proposal,
+		"votes":     votes,
+		"nodes":     tally.Nodes,
+		"weight":    tally.Weight,
+		"threshold": tally.Threshold,
+		"passed":    tally.Passed,
+	}, nil
+}
+
+func listNodeModifications(node *kernel.Node, params []interface{}) ([]map[string]interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.New("invalid params count")
+	}
+	pending, err := strconv.ParseBool(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	mods, err := node.ListNodeModifications(pending)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, len(mods))
+	for i, m := range mods {
+		result[i] = map[string]interface{}{
+			"signer":      m.Signer,
+			"payee":       m.PayeeAddress().String(),
+			"transaction": m.Transaction,
+			"timestamp":   m.Timestamp,
+			"effective":   m.Effective,
+		}
+	}
+	return result, nil
+}
+
+func isLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,22 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoopbackAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isLoopbackAddress("127.0.0.1:8239"))
+	assert.True(isLoopbackAddress("[::1]:8239"))
+	assert.False(isLoopbackAddress("10.0.0.1:8239"))
+	assert.False(isLoopbackAddress("[2001:db8::1]:8239"))
+	assert.False(isLoopbackAddress("localhost:8239"))
+	assert.False(isLoopbackAddress("127.0.0.1"))
+	assert.False(isLoopbackAddress(""))
+
+	_, err := importPeers(nil, "10.0.0.1:8239", []interface{}{"00"})
+	assert.Contains(err.Error(), "not allowed")
+}
@@ -0,0 +1,200 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/storage"
+)
+
+const (
+	listOutputsForKeyLimit = 500
+	diffOutputsLimit       = 5000
+)
+
+func listOutputsForKey(store storage.Store, params []interface{}) ([]map[string]interface{}, error) {
+	if len(params) != 4 && len(params) != 5 {
+		return nil, errors.New("invalid params count")
+	}
+	view, err := crypto.KeyFromString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	spend, err := crypto.KeyFromString(fmt.Sprint(params[1]))
+	if err != nil {
+		return nil, err
+	}
+	if !spend.CheckKey() {
+		return nil, errors.New("invalid spend key")
+	}
+	offset, err := strconv.ParseUint(fmt.Sprint(params[2]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.ParseUint(fmt.Sprint(params[3]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if count > listOutputsForKeyLimit {
+		return nil, fmt.Errorf("count too large %d/%d", count, listOutputsForKeyLimit)
+	}
+	var f filter
+	if len(params) == 5 && fmt.Sprint(params[4]) != "" {
+		f, err = parseFilter(fmt.Sprint(params[4]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	snapshots, transactions, err := store.ReadSnapshotWithTransactionsSinceTopology(offset, count)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]map[string]interface{}, 0)
+	for i, s := range snapshots {
+		tx := transactions[i]
+		for _, index := range tx.OwnedOutputs(&view, &spend) {
+			out := tx.Outputs[index]
+			output := map[string]interface{}{
+				"type":     out.Type,
+				"hash":     s.Transaction,
+				"index":    index,
+				"asset":    tx.Asset,
+				"amount":   out.Amount,
+				"keys":     out.Keys,
+				"script":   out.Script,
+				"mask":     out.Mask,
+				"snapshot": s.Hash,
+				"topology": s.TopologicalOrder,
+			}
+			utxo, err := store.ReadUTXOLock(s.Transaction, index)
+			if err != nil {
+				return nil, err
+			}
+			if utxo != nil && utxo.LockHash.HasValue() {
+				output["lock"] = utxo.LockHash
+			}
+			if f != nil && !f.match(output) {
+				continue
+			}
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs, nil
+}
+
+// diffOutputs lists the outputs created and spent by the snapshots in the
+// topology range [from, to), an output both created and spent in the range
+// is omitted, so applying the diff to the state at from results in the state
+// at to.
+func diffOutputs(store storage.Store, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 2 && len(params) != 3 {
+		return nil, errors.New("invalid params count")
+	}
+	from, err := strconv.ParseUint(fmt.Sprint(params[0]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	to, err := strconv.ParseUint(fmt.Sprint(params[1]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if to < from || to-from > diffOutputsLimit {
+		return nil, fmt.Errorf("invalid topology range %d %d/%d", from, to, diffOutputsLimit)
+	}
+	var asset crypto.Hash
+	if len(params) == 3 && fmt.Sprint(params[2]) != "" {
+		asset, err = crypto.HashFromString(fmt.Sprint(params[2]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created := make([]map[string]interface{}, 0)
+	spent := make([]map[string]interface{}, 0)
+	createdIndex := make(map[string]int)
+	for offset := from; offset < to; {
+		count := to - offset
+		if count > listOutputsForKeyLimit {
+			count = listOutputsForKeyLimit
+		}
+		snapshots, transactions, err := store.ReadSnapshotWithTransactionsSinceTopology(offset, count)
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) == 0 {
+			break
+		}
+		for i, s := range snapshots {
+			if s.TopologicalOrder >= to {
+				break
+			}
+			tx := transactions[i]
+			for _, in := range tx.Inputs {
+				if in.Genesis != nil || in.Deposit != nil || in.Mint != nil {
+					continue
+				}
+				key := fmt.Sprintf("%s:%d", in.Hash, in.Index)
+				if j, found := createdIndex[key]; found {
+					created[j] = nil
+					delete(createdIndex, key)
+					continue
+				}
+				utxo, err := store.ReadUTXOLock(in.Hash, in.Index)
+				if err != nil {
+					return nil, err
+				}
+				if utxo == nil {
+					return nil, fmt.Errorf("input not found %s", key)
+				}
+				if asset.HasValue() && utxo.Asset != asset {
+					continue
+				}
+				spent = append(spent, map[string]interface{}{
+					"type":        utxo.Type,
+					"hash":        in.Hash,
+					"index":       in.Index,
+					"asset":       utxo.Asset,
+					"amount":      utxo.Amount,
+					"transaction": s.Transaction,
+					"snapshot":    s.Hash,
+					"topology":    s.TopologicalOrder,
+				})
+			}
+			if asset.HasValue() && tx.Asset != asset {
+				continue
+			}
+			for index, out := range tx.Outputs {
+				createdIndex[fmt.Sprintf("%s:%d", s.Transaction, index)] = len(created)
+				created = append(created, map[string]interface{}{
+					"type":     out.Type,
+					"hash":     s.Transaction,
+					"index":    index,
+					"asset":    tx.Asset,
+					"amount":   out.Amount,
+					"keys":     out.Keys,
+					"script":   out.Script,
+					"mask":     out.Mask,
+					"snapshot": s.Hash,
+					"topology": s.TopologicalOrder,
+				})
+			}
+		}
+		offset = snapshots[len(snapshots)-1].TopologicalOrder + 1
+	}
+
+	outputs := make([]map[string]interface{}, 0)
+	for _, o := range created {
+		if o != nil {
+			outputs = append(outputs, o)
+		}
+	}
+	return map[string]interface{}{
+		"from":    from,
+		"to":      to,
+		"created": outputs,
+		"spent":   spent,
+	}, nil
+}
@@ -26,6 +26,16 @@ func getRoundLink(store storage.Store, params []interface{}) (uint64, error) {
 	return store.ReadLink(from, to)
 }
 
+type roundDetail struct {
+	node       crypto.Hash
+	hash       crypto.Hash
+	start      uint64
+	end        uint64
+	number     uint64
+	references *common.RoundLink
+	snapshots  []*common.SnapshotWithTopologicalOrder
+}
+
 func getRoundByNumber(kn *kernel.Node, store storage.Store, params []interface{}) (map[string]interface{}, error) {
 	if len(params) != 2 {
 		return nil, errors.New("invalid params count")
@@ -38,6 +48,14 @@ func getRoundByNumber(kn *kernel.Node, store storage.Store, params []interface{}
 	if err != nil {
 		return nil, err
 	}
+	round, err := readRoundByNumber(store, node, number)
+	if err != nil {
+		return nil, err
+	}
+	return round.toMap(kn), nil
+}
+
+func readRoundByNumber(store storage.Store, node crypto.Hash, number uint64) (*roundDetail, error) {
 	head, err := store.ReadRound(node)
 	if err != nil {
 		return nil, err
@@ -72,14 +90,14 @@ func getRoundByNumber(kn *kernel.Node, store storage.Store, params []interface{}
 	} else {
 		return nil, fmt.Errorf("round not found")
 	}
-	return map[string]interface{}{
-		"node":       node,
-		"hash":       hash,
-		"start":      start,
-		"end":        end,
-		"number":     number,
-		"references": roundLinkToMap(references),
-		"snapshots":  snapshotsToMap(kn, snapshots, nil, false),
+	return &roundDetail{
+		node:       node,
+		hash:       hash,
+		start:      start,
+		end:        end,
+		number:     number,
+		references: references,
+		snapshots:  snapshots,
 	}, nil
 }
 
@@ -91,6 +109,14 @@ func getRoundByHash(kn *kernel.Node, store storage.Store, params []interface{})
 	if err != nil {
 		return nil, err
 	}
+	round, err := readRoundByHash(store, hash)
+	if err != nil {
+		return nil, err
+	}
+	return round.toMap(kn), nil
+}
+
+func readRoundByHash(store storage.Store, hash crypto.Hash) (*roundDetail, error) {
 	round, err := store.ReadRound(hash)
 	if err != nil {
 		return nil, err
@@ -119,17 +145,29 @@ func getRoundByHash(kn *kernel.Node, store storage.Store, params []interface{})
 	} else {
 		return nil, fmt.Errorf("round malformed %s:%d", round.NodeId, round.Number)
 	}
-	return map[string]interface{}{
-		"node":       round.NodeId,
-		"hash":       hash,
-		"start":      start,
-		"end":        end,
-		"number":     round.Number,
-		"references": roundLinkToMap(round.References),
-		"snapshots":  snapshotsToMap(kn, snapshots, nil, false),
+	return &roundDetail{
+		node:       round.NodeId,
+		hash:       hash,
+		start:      start,
+		end:        end,
+		number:     round.Number,
+		references: round.References,
+		snapshots:  snapshots,
 	}, nil
 }
 
+func (r *roundDetail) toMap(kn *kernel.Node) map[string]interface{} {
+	return map[string]interface{}{
+		"node":       r.node,
+		"hash":       r.hash,
+		"start":      r.start,
+		"end":        r.end,
+		"number":     r.number,
+		"references": roundLinkToMap(r.references),
+		"snapshots":  snapshotsToMap(kn, r.snapshots, nil, false),
+	}
+}
+
 func roundLinkToMap(r *common.RoundLink) map[string]interface{} {
 	if r == nil {
 		return nil
@@ -139,3 +177,20 @@ func roundLinkToMap(r *common.RoundLink) map[string]interface{} {
 		"external": r.External.String(),
 	}
 }
+
+// graphDump exports the recent final rounds and their references for the
+// visualization tools, to debug the best round selection.
+func graphDump(kn *kernel.Node, params []interface{}) (string, error) {
+	if len(params) != 2 {
+		return "", errors.New("invalid params count")
+	}
+	count, err := strconv.ParseUint(fmt.Sprint(params[0]), 10, 64)
+	if err != nil {
+		return "", err
+	}
+	rounds, err := kn.DumpRoundGraph(count)
+	if err != nil {
+		return "", err
+	}
+	return kernel.EncodeRoundGraph(rounds, fmt.Sprint(params[1]))
+}
@@ -0,0 +1,262 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/kernel"
+)
+
+const (
+	SchemaVersion = 1
+	schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	schemaIdBase  = "https://mixin.one/schemas/v1/"
+)
+
+// schemaKeys renames the Go struct fields to the keys rendered by the RPC,
+// in the json tag form with the omitempty option for the optional keys,
+// and "-" for the fields not rendered. The structs not listed here are
+// rendered by encoding/json with the field names as is.
+var schemaKeys = map[reflect.Type]map[string]string{
+	reflect.TypeOf(common.SnapshotWithTopologicalOrder{}): {
+		"Version":          "version",
+		"NodeId":           "node",
+		"Transaction":      "transaction",
+		"References":       "references",
+		"RoundNumber":      "round",
+		"Timestamp":        "timestamp",
+		"Signatures":       "signatures,omitempty",
+		"Signature":        "signature,omitempty",
+		"Hash":             "hash",
+		"TopologicalOrder": "topology",
+	},
+	reflect.TypeOf(common.RoundLink{}): {
+		"Self":     "self",
+		"External": "external",
+	},
+	reflect.TypeOf(kernel.SnapshotWitness{}): {
+		"Signature": "signature",
+		"Timestamp": "timestamp",
+	},
+	reflect.TypeOf(common.VersionedTransaction{}): {
+		"Version":             "version",
+		"Asset":               "asset",
+		"Inputs":              "inputs",
+		"Outputs":             "outputs",
+		"Extra":               "extra",
+		"AggregatedSignature": "-",
+		"SignaturesMap":       "-",
+		"SignaturesSliceV1":   "-",
+		"BadGenesis":          "-",
+	},
+	reflect.TypeOf(common.Input{}): {
+		"Hash":    "hash,omitempty",
+		"Index":   "index,omitempty",
+		"Genesis": "genesis,omitempty",
+		"Deposit": "deposit,omitempty",
+		"Mint":    "mint,omitempty",
+	},
+	reflect.TypeOf(common.Output{}): {
+		"Type":       "type",
+		"Amount":     "amount",
+		"Keys":       "keys,omitempty",
+		"Withdrawal": "withdrawal,omitempty",
+		"Script":     "script,omitempty",
+		"Mask":       "mask,omitempty",
+	},
+	reflect.TypeOf(common.WithdrawalData{}): {
+		"Chain":    "chain",
+		"AssetKey": "asset_key",
+		"Address":  "address",
+		"Tag":      "tag",
+	},
+	reflect.TypeOf(common.Round{}): {
+		"Hash":       "hash",
+		"NodeId":     "node",
+		"Number":     "number",
+		"Timestamp":  "start",
+		"References": "references",
+	},
+}
+
+func schemaHex(size int) map[string]interface{} {
+	if size == 0 {
+		return map[string]interface{}{"type": "string", "pattern": "^[0-9a-f]*$"}
+	}
+	return map[string]interface{}{"type": "string", "pattern": fmt.Sprintf("^[0-9a-f]{%d}$", size*2)}
+}
+
+// schemaScalars are the types with the custom JSON encoding.
+var schemaScalars = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(crypto.Hash{}):          schemaHex(len(crypto.Hash{})),
+	reflect.TypeOf(crypto.Key{}):           schemaHex(len(crypto.Key{})),
+	reflect.TypeOf(crypto.Signature{}):     schemaHex(len(crypto.Signature{})),
+	reflect.TypeOf(crypto.CosiSignature{}): schemaHex(len(crypto.Signature{}) + 8),
+	reflect.TypeOf(common.Script{}):        schemaHex(0),
+	reflect.TypeOf(common.Integer{}):       {"type": "string", "pattern": `^\d+(\.\d{1,8})?$`},
+}
+
+// schemaOf generates the JSON schema of the Go type as rendered by the RPC.
+func schemaOf(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if s := schemaScalars[t]; s != nil {
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return schemaHex(0)
+		}
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		return schemaObject(t)
+	}
+	return map[string]interface{}{}
+}
+
+func schemaObject(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	schemaFields(t, schemaKeys[t], properties, &required)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func schemaFields(t reflect.Type, keys map[string]string, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if keys != nil {
+			tag = keys[f.Name]
+		}
+		if f.Anonymous && tag == "" {
+			schemaFields(f.Type, keys, properties, required)
+			continue
+		}
+		if tag == "-" || (keys != nil && tag == "") {
+			continue
+		}
+		name := f.Name
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			name = parts[0]
+		}
+		properties[name] = schemaOf(f.Type)
+		if len(parts) < 2 || parts[1] != "omitempty" {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func schemaDocument(name string, s map[string]interface{}) map[string]interface{} {
+	s["$schema"] = schemaDialect
+	s["$id"] = schemaIdBase + name + ".json"
+	s["title"] = name
+	return s
+}
+
+// schemaTransaction also has the hash computed from the payload.
+func schemaTransaction() map[string]interface{} {
+	s := schemaOf(reflect.TypeOf(common.VersionedTransaction{}))
+	s["properties"].(map[string]interface{})["hash"] = schemaOf(reflect.TypeOf(crypto.Hash{}))
+	s["required"] = append(s["required"].([]string), "hash")
+	return s
+}
+
+// schemaSnapshot renders the transaction as the hash, or the transaction
+// object if requested, and always has the witness of the node.
+func schemaSnapshot() map[string]interface{} {
+	s := schemaOf(reflect.TypeOf(common.SnapshotWithTopologicalOrder{}))
+	properties := s["properties"].(map[string]interface{})
+	properties["transaction"] = map[string]interface{}{
+		"oneOf": []interface{}{
+			schemaOf(reflect.TypeOf(crypto.Hash{})),
+			map[string]interface{}{"$ref": schemaIdBase + "transaction.json"},
+		},
+	}
+	properties["witness"] = schemaOf(reflect.TypeOf(kernel.SnapshotWitness{}))
+	s["required"] = append(s["required"].([]string), "witness")
+	return s
+}
+
+func schemaRound() map[string]interface{} {
+	s := schemaOf(reflect.TypeOf(common.Round{}))
+	properties := s["properties"].(map[string]interface{})
+	properties["end"] = schemaOf(reflect.TypeOf(uint64(0)))
+	properties["snapshots"] = map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"$ref": schemaIdBase + "snapshot.json"},
+	}
+	s["required"] = append(s["required"].([]string), "end", "snapshots")
+	return s
+}
+
+// schemaEnvelopes are the request and response objects of all the calls,
+// the response has either the data or the error.
+func schemaEnvelopes() (map[string]interface{}, map[string]interface{}) {
+	request := schemaOf(reflect.TypeOf(Call{}))
+	response := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":      map[string]interface{}{"type": "string"},
+			"data":    map[string]interface{}{},
+			"error":   map[string]interface{}{"type": "string"},
+			"runtime": map[string]interface{}{"type": "string"},
+		},
+		"oneOf": []interface{}{
+			map[string]interface{}{"required": []string{"data"}},
+			map[string]interface{}{"required": []string{"error"}},
+		},
+	}
+	return request, response
+}
+
+// describeSchema returns the versioned JSON schemas of the RPC objects, and
+// the hash of them for the clients to detect any field changes.
+func describeSchema(params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 0 {
+		return nil, errors.New("invalid params count")
+	}
+	request, response := schemaEnvelopes()
+	schemas := map[string]interface{}{
+		"snapshot":    schemaDocument("snapshot", schemaSnapshot()),
+		"transaction": schemaDocument("transaction", schemaTransaction()),
+		"round":       schemaDocument("round", schemaRound()),
+		"request":     schemaDocument("request", request),
+		"response":    schemaDocument("response", response),
+	}
+	data, err := json.Marshal(schemas)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"version": SchemaVersion,
+		"hash":    crypto.NewHash(data),
+		"schemas": schemas,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	// all the exported fields must be either renamed or omitted explicitly,
+	// so the new fields are never missed by the schemas.
+	for typ, keys := range schemaKeys {
+		var check func(t reflect.Type)
+		check = func(t reflect.Type) {
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				if f.PkgPath != "" {
+					continue
+				}
+				if f.Anonymous && keys[f.Name] == "" {
+					check(f.Type)
+					continue
+				}
+				assert.NotEmpty(keys[f.Name], typ.String()+"."+f.Name)
+			}
+		}
+		check(typ)
+	}
+
+	_, err := describeSchema([]interface{}{"snapshot"})
+	assert.NotNil(err)
+	result, err := describeSchema(nil)
+	assert.Nil(err)
+	assert.Equal(SchemaVersion, result["version"])
+	again, _ := describeSchema(nil)
+	assert.Equal(result["hash"], again["hash"])
+	schemas := result["schemas"].(map[string]interface{})
+	assert.Len(schemas, 5)
+
+	snapshot := schemas["snapshot"].(map[string]interface{})
+	assert.Equal("https://mixin.one/schemas/v1/snapshot.json", snapshot["$id"])
+	properties := snapshot["properties"].(map[string]interface{})
+	for _, k := range []string{"version", "node", "references", "round", "timestamp", "hash", "topology", "witness", "transaction", "signatures", "signature"} {
+		assert.Contains(properties, k)
+	}
+	assert.Len(properties, 11)
+	assert.NotContains(snapshot["required"], "signature")
+	assert.Contains(snapshot["required"], "witness")
+	assert.Equal("^[0-9a-f]{64}$", properties["node"].(map[string]interface{})["pattern"])
+
+	round := schemas["round"].(map[string]interface{})
+	properties = round["properties"].(map[string]interface{})
+	assert.ElementsMatch([]string{"node", "hash", "start", "end", "number", "references", "snapshots"}, round["required"])
+	assert.Len(properties, 7)
+
+	k := crypto.NewKeyFromSeed(make([]byte, 64)).Public()
+	tx := common.NewTransaction(crypto.NewHash([]byte("asset")))
+	tx.AddInput(crypto.NewHash([]byte("input")), 1)
+	tx.AddScriptOutput([]*common.Address{}, common.NewThresholdScript(1), common.NewInteger(1), make([]byte, 64))
+	tx.Outputs[0].Keys = []*crypto.Key{&k}
+	tx.Extra = []byte("extra")
+	ver := tx.AsLatestVersion()
+
+	schema := schemas["transaction"].(map[string]interface{})
+	properties = schema["properties"].(map[string]interface{})
+	data, err := json.Marshal(transactionToMap(ver))
+	assert.Nil(err)
+	var rendered map[string]interface{}
+	assert.Nil(json.Unmarshal(data, &rendered))
+	assert.Len(properties, len(rendered))
+	for k := range rendered {
+		assert.Contains(properties, k)
+	}
+	for _, k := range schema["required"].([]string) {
+		assert.Contains(rendered, k)
+	}
+	outputs := properties["outputs"].(map[string]interface{})["items"].(map[string]interface{})
+	output := rendered["outputs"].([]interface{})[0].(map[string]interface{})
+	for k := range output {
+		assert.Contains(outputs["properties"], k)
+	}
+	inputs := properties["inputs"].(map[string]interface{})["items"].(map[string]interface{})
+	input := rendered["inputs"].([]interface{})[0].(map[string]interface{})
+	for k := range input {
+		assert.Contains(inputs["properties"], k)
+	}
+	assert.Len(inputs["required"], 0)
+
+	request := schemas["request"].(map[string]interface{})
+	assert.ElementsMatch([]string{"id", "method", "params"}, request["required"])
+}
@@ -29,19 +29,84 @@ func getCacheTransaction(store storage.Store, params []interface{}) (map[string]
 	return data, nil
 }
 
-func queueTransaction(node *kernel.Node, params []interface{}) (string, error) {
+func queueTransaction(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 1 && len(params) != 2 {
+		return nil, errors.New("invalid params count")
+	}
+	raw, err := hex.DecodeString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	ver, err := common.UnmarshalVersionedTransaction(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) == 2 {
+		trace, err := strconv.ParseBool(fmt.Sprint(params[1]))
+		if err != nil {
+			return nil, err
+		}
+		if trace {
+			return traceTransaction(node, ver)
+		}
+	}
+	id, err := node.QueueTransaction(ver)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"hash": id}, nil
+}
+
+func traceTransaction(node *kernel.Node, ver *common.VersionedTransaction) (map[string]interface{}, error) {
+	trace, err := node.TraceTransaction(ver)
+	data := map[string]interface{}{
+		"hash":  ver.PayloadHash(),
+		"valid": err == nil,
+		"steps": trace.Steps,
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	return data, nil
+}
+
+// validateRawTransaction runs the same validation as sendrawtransaction
+// against the current UTXO set without queueing the transaction, and the
+// code tells the reason of the first failed check.
+func validateRawTransaction(node *kernel.Node, store storage.Store, params []interface{}) (map[string]interface{}, error) {
 	if len(params) != 1 {
-		return "", errors.New("invalid params count")
+		return nil, errors.New("invalid params count")
 	}
 	raw, err := hex.DecodeString(fmt.Sprint(params[0]))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	ver, err := common.UnmarshalVersionedTransaction(raw)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return node.QueueTransaction(ver)
+	_, snap, err := store.ReadTransaction(ver.PayloadHash())
+	if err != nil {
+		return nil, err
+	}
+
+	trace, err := node.TraceTransaction(ver)
+	data := map[string]interface{}{
+		"hash":      ver.PayloadHash(),
+		"valid":     err == nil,
+		"finalized": len(snap) > 0,
+		"steps":     trace.Steps,
+	}
+	if err != nil {
+		var check string
+		if len(trace.Steps) > 0 {
+			check = trace.Steps[len(trace.Steps)-1].Check
+		}
+		data["check"] = check
+		data["code"] = common.ValidationErrorCode(check, err)
+		data["error"] = err.Error()
+	}
+	return data, nil
 }
 
 func getTransaction(store storage.Store, params []interface{}) (map[string]interface{}, error) {
@@ -102,6 +167,91 @@ func getUTXO(store storage.Store, params []interface{}) (map[string]interface{},
 	return output, nil
 }
 
+// getFinalizationBundle proves the transaction finalized to light wallets,
+// which verify the bundle with the light package and a trusted commitment.
+func getFinalizationBundle(node *kernel.Node, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.New("invalid params count")
+	}
+	hash, err := crypto.HashFromString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	b, err := node.BuildFinalizationBundle(hash)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"snapshot":    b.Snapshot,
+		"signature":   b.Signature,
+		"threshold":   b.Threshold,
+		"signers":     b.Signers,
+		"transaction": b.Transaction,
+		"commitment":  b.Commitment(),
+	}, nil
+}
+
+// getAtomicSwap shows the hashlock output state, and the secret once it's
+// revealed by the claim transaction, to claim the other side of the swap.
+func getAtomicSwap(store storage.Store, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 2 {
+		return nil, errors.New("invalid params count")
+	}
+	hash, err := crypto.HashFromString(fmt.Sprint(params[0]))
+	if err != nil {
+		return nil, err
+	}
+	index, err := strconv.ParseUint(fmt.Sprint(params[1]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	utxo, err := store.ReadUTXOLock(hash, int(index))
+	if err != nil || utxo == nil {
+		return nil, err
+	}
+	hashlock := utxo.Script.Hashlock()
+	if utxo.Type != common.OutputTypeScript || !hashlock.HasValue() {
+		return nil, fmt.Errorf("invalid atomic swap output %s:%d", hash, index)
+	}
+
+	swap := map[string]interface{}{
+		"hash":      hash,
+		"index":     index,
+		"asset":     utxo.Asset,
+		"amount":    utxo.Amount,
+		"hashlock":  hashlock,
+		"expire":    utxo.Script.Expiration(),
+		"threshold": utxo.Script[2],
+		"refunds":   utxo.Script[13],
+		"state":     "unspent",
+	}
+	if !utxo.LockHash.HasValue() {
+		return swap, nil
+	}
+	swap["lock"] = utxo.LockHash
+	swap["state"] = "locked"
+	tx, snap, err := store.ReadTransaction(utxo.LockHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		tx, err = store.CacheGetTransaction(utxo.LockHash)
+		if err != nil || tx == nil {
+			return swap, err
+		}
+	}
+	claimed := crypto.NewHash(tx.Extra) == hashlock
+	if claimed {
+		swap["secret"] = hex.EncodeToString(tx.Extra)
+	}
+	if len(snap) > 0 && claimed {
+		swap["state"] = "claimed"
+	} else if len(snap) > 0 {
+		swap["state"] = "refunded"
+	}
+	return swap, nil
+}
+
 func getGhostKey(store storage.Store, params []interface{}) (map[string]interface{}, error) {
 	if len(params) != 1 {
 		return nil, errors.New("invalid params count")
@@ -169,6 +319,79 @@ func listSnapshots(node *kernel.Node, store storage.Store, params []interface{})
 	return snapshotsToMap(node, snapshots, nil, sig), err
 }
 
+// filterSnapshots pages the snapshots by the topology offset, the returned
+// next offset should be used to continue even if no snapshots matched.
+func filterSnapshots(node *kernel.Node, store storage.Store, params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 5 {
+		return nil, errors.New("invalid params count")
+	}
+	offset, err := strconv.ParseUint(fmt.Sprint(params[0]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.ParseUint(fmt.Sprint(params[1]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := strconv.ParseBool(fmt.Sprint(params[2]))
+	if err != nil {
+		return nil, err
+	}
+	tx, err := strconv.ParseBool(fmt.Sprint(params[3]))
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseSnapshotFilter(params[4])
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, transactions, next, err := store.ReadSnapshotsSinceTopologyWithFilter(offset, count, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !tx {
+		transactions = nil
+	}
+	return map[string]interface{}{
+		"snapshots": snapshotsToMap(node, snapshots, transactions, sig),
+		"next":      next,
+	}, nil
+}
+
+func parseSnapshotFilter(param interface{}) (*storage.SnapshotFilter, error) {
+	m, ok := param.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid snapshot filter %v", param)
+	}
+	filter := &storage.SnapshotFilter{}
+	for k, v := range m {
+		var err error
+		switch k {
+		case "node":
+			filter.NodeId, err = crypto.HashFromString(fmt.Sprint(v))
+		case "round_start":
+			filter.RoundStart, err = strconv.ParseUint(fmt.Sprint(v), 10, 64)
+		case "round_end":
+			filter.RoundEnd, err = strconv.ParseUint(fmt.Sprint(v), 10, 64)
+		case "timestamp_start":
+			filter.TimestampStart, err = strconv.ParseUint(fmt.Sprint(v), 10, 64)
+		case "timestamp_end":
+			filter.TimestampEnd, err = strconv.ParseUint(fmt.Sprint(v), 10, 64)
+		case "mint":
+			filter.Mint, err = strconv.ParseBool(fmt.Sprint(v))
+		case "node_state":
+			filter.NodeState, err = strconv.ParseBool(fmt.Sprint(v))
+		default:
+			err = fmt.Errorf("invalid snapshot filter field %s", k)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
 func snapshotsToMap(node *kernel.Node, snapshots []*common.SnapshotWithTopologicalOrder, transactions []*common.VersionedTransaction, sig bool) []map[string]interface{} {
 	tx := len(transactions) == len(snapshots)
 	result := make([]map[string]interface{}, len(snapshots))
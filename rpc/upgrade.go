@@ -0,0 +1,35 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/MixinNetwork/mixin/kernel"
+)
+
+func getUpgradeReadiness(node *kernel.Node) (map[string]interface{}, error) {
+	intents := make(map[string]interface{})
+	for id, intent := range node.ListUpgradeIntents() {
+		intents[id.String()] = map[string]interface{}{
+			"version":      intent.Version,
+			"capabilities": intent.Capabilities,
+			"activation":   time.Unix(0, int64(intent.Activation)),
+			"timestamp":    time.Unix(0, int64(intent.Timestamp)),
+		}
+	}
+
+	capabilities := make([]map[string]interface{}, 0)
+	for _, r := range node.UpgradeReadiness() {
+		capabilities = append(capabilities, map[string]interface{}{
+			"capability": r.Capability,
+			"activation": time.Unix(0, int64(r.Activation)),
+			"nodes":      r.Nodes,
+			"threshold":  r.Threshold,
+			"ready":      r.Ready,
+		})
+	}
+
+	return map[string]interface{}{
+		"intents":      intents,
+		"capabilities": capabilities,
+	}, nil
+}
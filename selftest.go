@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/domains/akash"
+	"github.com/MixinNetwork/mixin/domains/algorand"
+	"github.com/MixinNetwork/mixin/domains/aptos"
+	"github.com/MixinNetwork/mixin/domains/arweave"
+	"github.com/MixinNetwork/mixin/domains/avalanche"
+	"github.com/MixinNetwork/mixin/domains/bch"
+	"github.com/MixinNetwork/mixin/domains/binance"
+	"github.com/MixinNetwork/mixin/domains/bitcoin"
+	"github.com/MixinNetwork/mixin/domains/bsc"
+	"github.com/MixinNetwork/mixin/domains/bsv"
+	"github.com/MixinNetwork/mixin/domains/cardano"
+	"github.com/MixinNetwork/mixin/domains/cosmos"
+	"github.com/MixinNetwork/mixin/domains/dash"
+	"github.com/MixinNetwork/mixin/domains/decred"
+	"github.com/MixinNetwork/mixin/domains/dfinity"
+	"github.com/MixinNetwork/mixin/domains/dogecoin"
+	"github.com/MixinNetwork/mixin/domains/eos"
+	"github.com/MixinNetwork/mixin/domains/etc"
+	"github.com/MixinNetwork/mixin/domains/ethereum"
+	"github.com/MixinNetwork/mixin/domains/filecoin"
+	"github.com/MixinNetwork/mixin/domains/handshake"
+	"github.com/MixinNetwork/mixin/domains/hedera"
+	"github.com/MixinNetwork/mixin/domains/horizen"
+	"github.com/MixinNetwork/mixin/domains/kusama"
+	"github.com/MixinNetwork/mixin/domains/litecoin"
+	"github.com/MixinNetwork/mixin/domains/mobilecoin"
+	"github.com/MixinNetwork/mixin/domains/monacoin"
+	"github.com/MixinNetwork/mixin/domains/monero"
+	"github.com/MixinNetwork/mixin/domains/namecoin"
+	"github.com/MixinNetwork/mixin/domains/near"
+	"github.com/MixinNetwork/mixin/domains/nervos"
+	"github.com/MixinNetwork/mixin/domains/osmosis"
+	"github.com/MixinNetwork/mixin/domains/peercoin"
+	"github.com/MixinNetwork/mixin/domains/polkadot"
+	"github.com/MixinNetwork/mixin/domains/polygon"
+	"github.com/MixinNetwork/mixin/domains/ravencoin"
+	"github.com/MixinNetwork/mixin/domains/ripple"
+	"github.com/MixinNetwork/mixin/domains/siacoin"
+	"github.com/MixinNetwork/mixin/domains/solana"
+	"github.com/MixinNetwork/mixin/domains/stellar"
+	"github.com/MixinNetwork/mixin/domains/sui"
+	"github.com/MixinNetwork/mixin/domains/tezos"
+	"github.com/MixinNetwork/mixin/domains/tron"
+	"github.com/MixinNetwork/mixin/domains/zcash"
+	"github.com/urfave/cli/v2"
+)
+
+// domainVector is a known good asset, address and deposit transaction
+// hash of a domain, the same values asserted by the domain package tests.
+type domainVector struct {
+	Domain   string
+	ChainId  crypto.Hash
+	AssetKey string
+	AssetId  string
+	Address  string
+	Hash     string
+}
+
+var domainVectors = []*domainVector{
+	{"akash", akash.AkashChainId, "uakt", "9c612618-ca59-4583-af34-be9482f5002d", "akash1f9su26yet620lndeyzmun5x5sk6wfslv4xxtgt", "e2adef1954f5eee1bd9f4defa7080b6b61a8b9de650120ba9722ab8674e6f38a"},
+	{"algorand", algorand.AlgorandChainId, algorand.AlgorandChainBase, algorand.AlgorandChainBase, "KZRF5B5JGH2NGSEG3DSKYM4KBB2OCDZY3BGXYCAZTMJBADDISJ436DNDTM", "OLY6AWDB7QCUQZWMVTPUIVTI65SNXSVU7OKLGXLGZSIWOSJMIWFQ"},
+	{"algorand", algorand.AlgorandChainId, "31566704", "a9afeec5-5c79-3a2a-b1af-38717995efd9", "KZRF5B5JGH2NGSEG3DSKYM4KBB2OCDZY3BGXYCAZTMJBADDISJ436DNDTM", "OLY6AWDB7QCUQZWMVTPUIVTI65SNXSVU7OKLGXLGZSIWOSJMIWFQ"},
+	{"aptos", aptos.AptosChainId, aptos.NativeCoinType, aptos.AptosChainBase, "0x8f396e4246b2ba87b51c0739ef5ea4f26515a98375308c31ac2ec1e42142a57f", "0x6b4bad4a0b32e5e1a9d4d6d7a0ab7cf5a1a0fa2ebd0c5a6b3f0e0ad4b8c2f9e1"},
+	{"aptos", aptos.AptosChainId, "0xf22bede237a07e121b56d91a491eb7bcdfd1f5907926a9e58338f964a01b17fa::asset::USDC", "962f624f-42b2-3dbd-b9ed-27329c0756c5", "0x8f396e4246b2ba87b51c0739ef5ea4f26515a98375308c31ac2ec1e42142a57f", "0x6b4bad4a0b32e5e1a9d4d6d7a0ab7cf5a1a0fa2ebd0c5a6b3f0e0ad4b8c2f9e1"},
+	{"arweave", arweave.ArweaveChainId, arweave.ArweaveChainBase, arweave.ArweaveChainBase, "9dE4RwCxwElyc0YDfzgYmeMZhyDuhfnMmq8N95J8pIg", "5_-HdBC72aXmM0b9NmHbDBZdcvwdhcNfj7Rqts9YtQE"},
+	{"avalanche", avalanche.AvalancheChainId, "FvwEAhmxKfeiG8SnEvq42hc6whRyY3EFYAvebMqDNDGCgxN5Z", "cbc77539-0a20-4666-8c8a-4ded62b36f0a", "X-avax1emj30lmw3mcdgnmzl2plrmmvahln9mnmfzw2d5", "Sv3wdQnUfh7A9zGzppHxn7ehjzkFR79MMnQdx2CUWdRc3eSNN"},
+	{"avalanche", avalanche.AvalancheChainId, "0xb97ef9ef8734c71904d8002f8b6bc66dd9c48a6e", "e332c8c1-a4e2-3db9-90f9-850118163afa", "0xA974c709cFb4566686553a20790685A47acEAA33", "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"},
+	{"bch", bch.BitcoinCashChainId, bch.BitcoinCashChainBase, bch.BitcoinCashChainBase, "19q6XbBBYLhxnQGxWeS3fiehV5huV8bAZd", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"binance", binance.BinanceChainId, "BNB", "17f78d7c-ed96-40ff-980c-5dc62fecbc85", "bnb1rmc2xnpgx48hfq5jr8hqzh02ewl26dz5k0vfu7", "752b23fa8585f2516022a481c6c57f42f355cbb79560e7f26520ddb027ecc48f"},
+	{"bitcoin", bitcoin.BitcoinChainId, bitcoin.BitcoinChainAssetKey, bitcoin.BitcoinChainAssetKey, "1zgmvYi5x1wy3hUh7AjKgpcVgpA8Lj9FA", "c5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"},
+	{"bsc", bsc.BSCChainId, "0x55d398326f99059ff775485246999027b3197955", "94213408-4ee7-3150-a9c4-9c5cce421c78", "0x55d398326f99059fF775485246999027B3197955", "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"},
+	{"bsv", bsv.BitcoinSVChainId, bsv.BitcoinSVChainBase, bsv.BitcoinSVChainBase, "19q6XbBBYLhxnQGxWeS3fiehV5huV8bAZd", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"cardano", cardano.CardanoChainId, cardano.CardanoChainBase, cardano.CardanoChainBase, "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x", "a5d3a5e4d7a4d58e3fd8c3e8d1ba0bb5d4e6b8bc0a8a5a8fd1d6c3c2ed7ab9fe"},
+	{"cardano", cardano.CardanoChainId, "f43a62fdc3965df486de8a0d32fe800963589c41b38946602a0dc53541474958", "873ee54b-b6e2-32be-a54f-cc6e04343336", "stake1uyehkck0lajq8gr28t9uxnuvgcqrc6070x3k9r8048z8y5gh6ffgw", "a5d3a5e4d7a4d58e3fd8c3e8d1ba0bb5d4e6b8bc0a8a5a8fd1d6c3c2ed7ab9fe"},
+	{"cosmos", cosmos.CosmosChainId, "uatom", "7397e9f1-4e42-4dc8-8a3b-171daaadd436", "cosmos14xwf5zcf0qk2t8vuqtr0zv9yt9g85dust0u68d", "c9698260bab4095df25a228a3d855918de38a9e0c57d7a137de18b4c141f26ee"},
+	{"dash", dash.DashChainId, dash.DashChainBase, dash.DashChainBase, "XksUwk1GETexCpP6Wbrdswd3TfWRSckUAn", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"decred", decred.DecredChainId, decred.DecredChainBase, decred.DecredChainBase, "DsoBw7Xa2dh1pRYcmFC3npi4Mh4ZydbMzUH", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"dfinity", dfinity.DfinityChainId, dfinity.DfinityChainBase, dfinity.DfinityChainBase, "449ce7ad1298e2ed2781ed379aba25efc2748d14c60ede190ad7621724b9e8b2", "8614fec5bc43d40fbc252ac3b042b7a01d622338e073d790d2da501cab845a8c"},
+	{"dogecoin", dogecoin.DogecoinChainId, dogecoin.DogecoinChainBase, dogecoin.DogecoinChainBase, "DANHz6EQVoWyZ9rER56DwTXHWUxfkv9k2o", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"eos", eos.EOSChainId, "eosio.token:EOS", "6cfe566e-4aad-470b-8c9a-2fd35b49c68d", "eosio.token", "197be13b8d572ae4c83fe2bc60e87ac8993896242bb486790fd4378f88d8d961"},
+	{"etc", etc.EthereumClassicChainId, "0x0000000000000000000000000000000000000000", "2204c1ee-0ea2-4add-bb9a-b3719cfff93a", "0xA974c709cFb4566686553a20790685A47acEAA33", "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"},
+	{"ethereum", ethereum.EthereumChainId, "0xa974c709cfb4566686553a20790685a47aceaa33", "c94ac88f-4671-3976-b60a-09064f1811e8", "0xA974c709cFb4566686553a20790685A47acEAA33", "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"},
+	{"filecoin", filecoin.FilecoinChainId, filecoin.FilecoinChainBase, filecoin.FilecoinChainBase, "f1egh23o5qy2ibkqwawqyjague4urpxiyf672l6zi", "bafy2bzaceaqr65fthy3z4wn2rmo7ani75sekd5kwsg3pkrzznynopbgnovtkc"},
+	{"handshake", handshake.HandshakenChainId, handshake.HandshakenChainBase, handshake.HandshakenChainBase, "hs1qsh9v47p3k75lk9js8dptdd4qcy3n0scd33lm4j", "8c30eece44c9b4f4314f06ec5eedc7486e83ae76159ea81a0ee7aac2f16bbf0b"},
+	{"hedera", hedera.HederaChainId, hedera.HederaChainBase, hedera.HederaChainBase, "0.0.1234567-ylkls", "0.0.1234567@1615422161.073238162"},
+	{"hedera", hedera.HederaChainId, "0.0.456858", "2f6174a6-aaea-3a03-aaa7-df37c59ea225", "0.0.1234567", "0.0.1234567@1615422161.073238162"},
+	{"hedera", hedera.HederaChainId, "0.0.1234567/42", "ec07aeea-b97b-3e47-8197-dbd33c778144", "0.0.1234567", "0.0.1234567@1615422161.073238162"},
+	{"horizen", horizen.HorizenChainId, horizen.HorizenChainBase, horizen.HorizenChainBase, "zszpcLB6C5B8QvfDbF2dYWXsrpac5DL9WRk", "8c30eece44c9b4f4314f06ec5eedc7486e83ae76159ea81a0ee7aac2f16bbf0b"},
+	{"kusama", kusama.KusamaChainId, kusama.KusamaChainBase, kusama.KusamaChainBase, "F4xQKRUagnSGjFqafyhajLs94e7Vvzvr8ebwYJceKpr8R7T", "0x961c4418df4afdbc2dcca2a146e01eadc8a56f76515c523ee1bda55d46e4b3e0"},
+	{"kusama", kusama.KusamaChainId, "1000:1984", "d3a2a92d-fe33-30bf-8254-df4ee62cbfc4", "F4xQKRUagnSGjFqafyhajLs94e7Vvzvr8ebwYJceKpr8R7T", "0x961c4418df4afdbc2dcca2a146e01eadc8a56f76515c523ee1bda55d46e4b3e0"},
+	{"litecoin", litecoin.LitecoinChainId, litecoin.LitecoinChainBase, litecoin.LitecoinChainBase, "LcDrhX7NCmoRj58abHjAzfNCvk7jHxARsm", "b17c33501a8f52918f9c80723420a5f4fd39be2de117ec8343239d3a98b467c1"},
+	{"mobilecoin", mobilecoin.MobileCoinChainId, mobilecoin.MobileCoinChainBase, mobilecoin.MobileCoinChainBase, "G57w8Br44AYd6aEKfagTyLFvt4tTLhDdzGsX6PbYwfumwpjc1htSpWfoey2FLYNKMJA28q8YyqYb83dh66A7BTVA4XNZzXsNNUDv1nTmaw", "40c7e63c8cd2ddb1e65ffd3531e47739ed78cdcfef9cfd5cb6916f3c50d19c16"},
+	{"monacoin", monacoin.MonacoinChainId, monacoin.MonacoinChainBase, monacoin.MonacoinChainBase, "MTVk1Jcegvnq7TBD43pFZH7uW4F45LXhka", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"monero", monero.MoneroChainId, monero.MoneroChainBase, monero.MoneroChainBase, "447XRzap95djHJ1eQPXH6a1atfkZ1LLeVbr36BEH5HJCZgESVsCwpZfLX413y7gECRPaKS3Wz3izkQcQzzfRre6ER4oKK1P", "b140a0c02836f56a3a0638d1bb9118b660701879b7307f26373e51756a3fb1f5"},
+	{"namecoin", namecoin.NamecoinChainId, namecoin.NamecoinChainBase, namecoin.NamecoinChainBase, "NCjrV4CWpSr73mfYADbiujetMB3F3VrDWc", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"near", near.NearChainId, near.NearChainBase, near.NearChainBase, "d6b52637bf0e03a253a634a64705580ed0d2d58479613a0aa13c4342db172323", "8Z87eXBbFQN1b91UVVHsASeFPvucCZmmG9oae6wZV6uN"},
+	{"near", near.NearChainId, "usdt.tether-token.near", "08789c0e-3569-32ba-995c-fdc81ba754db", "usdt.tether-token.near", "8Z87eXBbFQN1b91UVVHsASeFPvucCZmmG9oae6wZV6uN"},
+	{"nervos", nervos.NervosChainId, nervos.NervosChainBase, nervos.NervosChainBase, "ckb1qyqt8csrd4yg4el5etgkvt8rmdg923t8yagswneqnr", "0x92d028bf29a20769347b0e1ac5c27cbf087b22f97a85c695da758df204442f2b"},
+	{"osmosis", osmosis.OsmosisChainId, "uosmo", "a54e4e82-8d12-4295-a3bf-dad1189a6e9f", "osmo1vawrtys744sfqujm28tqjx47h703h9eyw0frar", "c9698260bab4095df25a228a3d855918de38a9e0c57d7a137de18b4c141f26ee"},
+	{"osmosis", osmosis.OsmosisChainId, "gamm/pool/1", "2b2c5755-3f14-302e-8aed-541532a91e10", "osmo1vawrtys744sfqujm28tqjx47h703h9eyw0frar", "c9698260bab4095df25a228a3d855918de38a9e0c57d7a137de18b4c141f26ee"},
+	{"peercoin", peercoin.PeercoinChainId, peercoin.PeercoinChainBase, peercoin.PeercoinChainBase, "PDuFfku8SLPsz18Be95WXYVwh8Qiig2rXa", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"polkadot", polkadot.PolkadotChainId, polkadot.PolkadotChainBase, polkadot.PolkadotChainBase, "13eM4Bgw55j93P7tiozfSjCkr55imbbiyso9MTG6YiQLaZSt", "0x69cb313180b82f8d98314fc57c09905acc82282df3d068091e2344ea35a85c5a"},
+	{"polkadot", polkadot.PolkadotChainId, "1000:1984", "6489fcfa-7017-31af-958b-e68b6536a499", "13eM4Bgw55j93P7tiozfSjCkr55imbbiyso9MTG6YiQLaZSt", "0x69cb313180b82f8d98314fc57c09905acc82282df3d068091e2344ea35a85c5a"},
+	{"polygon", polygon.PolygonChainId, "0x2e1ad108ff1d8c782fcbbb89aad783ac49586756", "9189a528-c3a5-36cb-8e08-feb81e7cb9cb", "0x2e1AD108fF1D8C782fcBbB89AAd783aC49586756", "0xc5945a8571fc84cd6850b26b5771d76311ed56957a04e993927de07b83f07c91"},
+	{"ravencoin", ravencoin.RavencoinChainId, ravencoin.RavencoinChainBase, ravencoin.RavencoinChainBase, "RE9x1e1u6nXiaMq1eFstcK8whQ4NhGz1mP", "00a1630c8d0af5ef875d1f13330cc64cee0f91bc5f5aee8e401bf13d2a1beb04"},
+	{"ripple", ripple.RippleChainId, ripple.RippleChainBase, ripple.RippleChainBase, "rK6Vezau2D1FDUhFs1me35H3xod8UKc1Go", "564D15A614B47A01D9F3AD08EC298ED8D7A7ECC98F4D64627D4D6A559668DBC8"},
+	{"ripple", ripple.RippleChainId, "USD:rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "f7dbb47c-57ee-3567-a498-f48fce18cb55", "rK6Vezau2D1FDUhFs1me35H3xod8UKc1Go", "564D15A614B47A01D9F3AD08EC298ED8D7A7ECC98F4D64627D4D6A559668DBC8"},
+	{"siacoin", siacoin.SiacoinChainId, siacoin.SiacoinChainBase, siacoin.SiacoinChainBase, "7a029a98f4be2d5f0364b0c5bc27fa1a0c45a9ca670fab2109e6b8328969e0899b774cf91478", "a78040a7b25278a96dfcbf56f9e0945072188a3638db549481f52db8dfcaa647"},
+	{"solana", solana.SolanaChainId, "11111111111111111111111111111111", "64692c23-8971-4cf4-84a7-4dd1271dd887", "GuscxHWgjxoMTokbW5bmt54WnHAVEtyE3RCVXgxdZjnG", "rhz84aQJvQaYquFuDuyHVUHq8kZBjHrsmFDHRM2r87rjygCNBk6F9GtCfiLL31juDM4YptXHMyVXbcnupELcu1N"},
+	{"stellar", stellar.StellarChainId, stellar.StellarChainBase, stellar.StellarChainBase, "GD77JOIFC622O5HXU446VIKGR5A5HMSTAUKO2FSN5CIVWPHXDBGIAG7Y", "fa01f7b2391eac01662316f1611be34611c28bd4746026f69b89ad86e9b9f581"},
+	{"stellar", stellar.StellarChainId, "USDC:GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN", "643a36d6-1929-3777-9464-96227f9598be", "GD77JOIFC622O5HXU446VIKGR5A5HMSTAUKO2FSN5CIVWPHXDBGIAG7Y", "fa01f7b2391eac01662316f1611be34611c28bd4746026f69b89ad86e9b9f581"},
+	{"sui", sui.SuiChainId, "0x2::sui::SUI", "53ee2b13-6362-4810-bf1f-579577d5e8b0", "0x7d20dcdb2bca4f508ea9613994683eb4e76e9c4ed371169677c1be02aaf0b58e", "3SivNwfPYsaSgWj8jLNvd567sVZJCDoagsobDEMkQsHT"},
+	{"tezos", tezos.TezosChainId, tezos.TezosChainBase, tezos.TezosChainBase, "tz1LNGzjz8H9juHNrHLKbZ1fm7un3KJpxsFY", "oodYJNMcvbi1uyVVE6c14LWU64mwtTw4n444L8rwsGmg6oT5kuB"},
+	{"tezos", tezos.TezosChainId, "KT1XnTn74bUtxHfDtBmm2bGZAQfhPbvKWR8o:0", "12eb3605-5b34-3878-9373-e81b56f40fe8", "KT1LN4LPSqTMS7Sd2CJw4bbDGRkMv2t68Fy9", "oodYJNMcvbi1uyVVE6c14LWU64mwtTw4n444L8rwsGmg6oT5kuB"},
+	{"tron", tron.TronChainId, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "b91e18ff-a9ae-3dc7-8679-e935d9a4b34b", "TBJSVkP9zNDmHwnZtZHqG1GZXtWuJL71Mv", "f5eade17b339ae39e8d6b61cb1d935c942fae4e7da312e16fac2f1573d152dfe"},
+	{"zcash", zcash.ZcashChainId, zcash.ZcashChainBase, zcash.ZcashChainBase, "t1NsuW4Xpz3GQUzt3BTZAxN6k4svKfWXgni", "30f305889eab065bb5c85e724df9ffb1c8da7f22259c583cf874fbd6ec681b8a"},
+}
+
+func (v *domainVector) verify() error {
+	asset := &common.Asset{ChainId: v.ChainId, AssetKey: v.AssetKey}
+	if err := asset.Verify(); err != nil {
+		return fmt.Errorf("asset key %s %v", v.AssetKey, err)
+	}
+	if id := asset.AssetId(); id != crypto.NewHash([]byte(v.AssetId)) {
+		return fmt.Errorf("asset id %s %s", v.AssetKey, id)
+	}
+	if err := common.VerifyWithdrawalAddress(v.ChainId, v.Address); err != nil {
+		return fmt.Errorf("address %s %v", v.Address, err)
+	}
+	if err := common.VerifyDepositTransactionHash(v.ChainId, v.Hash); err != nil {
+		return fmt.Errorf("transaction hash %s %v", v.Hash, err)
+	}
+	return nil
+}
+
+// domainSelfTestResults verifies the vectors once for the node status, and
+// reports each domain as pass or its first failure.
+func domainSelfTestResults() map[string]string {
+	results := make(map[string]string)
+	for _, v := range domainVectors {
+		if r, found := results[v.Domain]; found && r != "pass" {
+			continue
+		}
+		err := v.verify()
+		if err != nil {
+			results[v.Domain] = err.Error()
+		} else {
+			results[v.Domain] = "pass"
+		}
+	}
+	return results
+}
+
+func selfTestDomainsCmd(c *cli.Context) error {
+	var failed int
+	for _, v := range domainVectors {
+		err := v.verify()
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL %-12s %s\n", v.Domain, err)
+		} else {
+			fmt.Printf("PASS %-12s %s\n", v.Domain, v.AssetKey)
+		}
+	}
+	fmt.Printf("%d/%d domain vectors passed\n", len(domainVectors)-failed, len(domainVectors))
+	if failed > 0 {
+		return fmt.Errorf("%d domain vectors failed", failed)
+	}
+	return nil
+}
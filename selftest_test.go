@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainSelfTest(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, v := range domainVectors {
+		assert.Nil(v.verify(), v.Domain)
+		c := common.ReadDomainChain(v.ChainId)
+		if assert.NotNil(c, v.Domain) {
+			assert.Equal(c.Name, v.Domain)
+		}
+	}
+
+	results := domainSelfTestResults()
+	chains := common.ListDomainChains()
+	assert.Len(results, len(chains))
+	for _, c := range chains {
+		assert.Equal("pass", results[c.Name], c.Name)
+	}
+
+	v := *domainVectors[0]
+	v.Address = "invalid"
+	assert.NotNil(v.verify())
+	v = *domainVectors[0]
+	v.AssetId = "invalid"
+	assert.NotNil(v.verify())
+	assert.Nil(selfTestDomainsCmd(nil))
+}
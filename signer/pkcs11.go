@@ -0,0 +1,191 @@
+package signer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"filippo.io/edwards25519"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// hsmToken seals and opens by the AES key in the HSM, which never leaves
+// the HSM.
+type hsmToken interface {
+	seal(plain []byte) ([]byte, error)
+	open(sealed []byte) ([]byte, error)
+}
+
+// PKCS11 is the signer with the key wrapped by the AES key of a PKCS#11 HSM.
+// The standard mechanisms can't do the ed25519 scalar operations of the cosi
+// response, so the HSM unwraps the key for each operation and the key is
+// erased right after, the host never stores the key and can't sign without
+// the HSM. Like the Server, the cosi random is sealed by the HSM key with the
+// commit time, and a sealed random only responds one challenge.
+type PKCS11 struct {
+	sync.Mutex
+	token   hsmToken
+	wrapped []byte
+	public  crypto.Key
+	randoms map[crypto.Hash]*sealedRandomUse
+}
+
+// NewPKCS11 logins the slot of the PKCS#11 module by the pin, and finds the
+// AES key of the label to unwrap the key, which must be of the public key.
+func NewPKCS11(module string, slot uint, pin, label string, wrapped []byte, public crypto.Key) (*PKCS11, error) {
+	token, err := openPKCS11Token(module, slot, pin, label)
+	if err != nil {
+		return nil, err
+	}
+	return newPKCS11(token, wrapped, public)
+}
+
+// WrapPKCS11Key wraps the key by the AES key of the label in the HSM, as the
+// signer-pkcs11-wrapped of the node config.
+func WrapPKCS11Key(module string, slot uint, pin, label string, key crypto.Key) ([]byte, error) {
+	token, err := openPKCS11Token(module, slot, pin, label)
+	if err != nil {
+		return nil, err
+	}
+	return token.seal(key[:])
+}
+
+func newPKCS11(token hsmToken, wrapped []byte, public crypto.Key) (*PKCS11, error) {
+	p := &PKCS11{
+		token:   token,
+		wrapped: wrapped,
+		public:  public,
+		randoms: make(map[crypto.Hash]*sealedRandomUse),
+	}
+	err := p.withKey(func(key *crypto.Key) error {
+		if key.Public() != public {
+			return fmt.Errorf("invalid pkcs11 wrapped key for %s", public)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PKCS11) PublicKey() crypto.Key {
+	return p.public
+}
+
+func (p *PKCS11) Sign(message []byte) (crypto.Signature, error) {
+	var sig crypto.Signature
+	err := p.withKey(func(key *crypto.Key) error {
+		sig = key.Sign(message)
+		return nil
+	})
+	return sig, err
+}
+
+func (p *PKCS11) CosiCommit(rand io.Reader) (crypto.Key, []byte, error) {
+	r := crypto.CosiCommit(rand)
+	plain := make([]byte, 40)
+	copy(plain, r[:])
+	binary.BigEndian.PutUint64(plain[32:], uint64(time.Now().UnixNano()))
+	sealed, err := p.token.seal(plain)
+	if err != nil {
+		return crypto.Key{}, nil, err
+	}
+	return r.Public(), sealed, nil
+}
+
+func (p *PKCS11) CosiResponse(cosi *crypto.CosiSignature, sealed []byte, publics []*crypto.Key, message []byte) (*[32]byte, error) {
+	plain, err := p.token.open(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(plain) != 40 {
+		return nil, fmt.Errorf("invalid cosi random size %d", len(plain))
+	}
+	var random crypto.Key
+	copy(random[:], plain)
+	ts := time.Unix(0, int64(binary.BigEndian.Uint64(plain[32:])))
+	if time.Since(ts) > sealedRandomLifetime {
+		return nil, fmt.Errorf("expired cosi random %s", ts)
+	}
+	challenge, err := cosi.Challenge(publics, message)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	for k, u := range p.randoms {
+		if time.Since(u.timestamp) > sealedRandomLifetime {
+			delete(p.randoms, k)
+		}
+	}
+	id := crypto.NewHash(sealed)
+	ch := crypto.NewHash(challenge.Bytes())
+	if u := p.randoms[id]; u != nil {
+		if u.challenge != ch {
+			return nil, fmt.Errorf("cosi random used for challenge %s", u.challenge)
+		}
+		var response [32]byte
+		copy(response[:], u.response)
+		return &response, nil
+	}
+	var response *[32]byte
+	err = p.withKey(func(key *crypto.Key) error {
+		response, err = cosi.Response(key, &random, publics, message)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.randoms[id] = &sealedRandomUse{challenge: ch, response: response[:], timestamp: ts}
+	return response, nil
+}
+
+func (p *PKCS11) Secret(label string) (crypto.Hash, error) {
+	var secret crypto.Hash
+	err := p.withKey(func(key *crypto.Key) error {
+		secret, _ = NewLocal(*key).Secret(label)
+		return nil
+	})
+	return secret, err
+}
+
+func (p *PKCS11) SharedSecret(label string, public crypto.Key) (crypto.Hash, error) {
+	var secret crypto.Hash
+	err := p.withKey(func(key *crypto.Key) error {
+		s, err := NewLocal(*key).SharedSecret(label, public)
+		secret = s
+		return err
+	})
+	return secret, err
+}
+
+// withKey unwraps the key by the HSM for the function, and erases it after.
+func (p *PKCS11) withKey(fn func(key *crypto.Key) error) error {
+	plain, err := p.token.open(p.wrapped)
+	if err != nil {
+		return err
+	}
+	var key crypto.Key
+	defer func() {
+		for i := range plain {
+			plain[i] = 0
+		}
+		for i := range key {
+			key[i] = 0
+		}
+	}()
+	if len(plain) != len(key) {
+		return fmt.Errorf("invalid pkcs11 wrapped key size %d", len(plain))
+	}
+	_, err = edwards25519.NewScalar().SetCanonicalBytes(plain)
+	if err != nil {
+		return fmt.Errorf("invalid pkcs11 wrapped key %s", err)
+	}
+	copy(key[:], plain)
+	return fn(&key)
+}
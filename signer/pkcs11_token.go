@@ -0,0 +1,117 @@
+//go:build cgo
+
+package signer
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// pkcs11Token is the AES key in the HSM, each seal has a random 12 bytes IV
+// prepended to the AES-GCM cipher text. The operations are serialized on
+// the only session.
+type pkcs11Token struct {
+	sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+}
+
+func openPKCS11Token(module string, slot uint, pin, label string) (hsmToken, error) {
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("invalid pkcs11 module %s", module)
+	}
+	err := ctx.Initialize()
+	if err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+	t := &pkcs11Token{ctx: ctx}
+	err = t.login(slot, pin, label)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *pkcs11Token) login(slot uint, pin, label string) error {
+	session, err := t.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return err
+	}
+	t.session = session
+	err = t.ctx.Login(session, pkcs11.CKU_USER, pin)
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return err
+	}
+	err = t.ctx.FindObjectsInit(session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	})
+	if err != nil {
+		return err
+	}
+	objects, _, err := t.ctx.FindObjects(session, 2)
+	if err != nil {
+		return err
+	}
+	err = t.ctx.FindObjectsFinal(session)
+	if err != nil {
+		return err
+	}
+	if len(objects) != 1 {
+		return fmt.Errorf("invalid pkcs11 key %s count %d", label, len(objects))
+	}
+	t.key = objects[0]
+	return nil
+}
+
+func (t *pkcs11Token) seal(plain []byte) ([]byte, error) {
+	iv := make([]byte, 12)
+	_, err := io.ReadFull(rand.Reader, iv)
+	if err != nil {
+		return nil, err
+	}
+	t.Lock()
+	defer t.Unlock()
+
+	params := pkcs11.NewGCMParams(iv, nil, 128)
+	defer params.Free()
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}
+	err = t.ctx.EncryptInit(t.session, mechanism, t.key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := t.ctx.Encrypt(t.session, plain)
+	if err != nil {
+		return nil, err
+	}
+	if actual := params.IV(); len(actual) == len(iv) {
+		iv = actual
+	}
+	return append(iv, sealed...), nil
+}
+
+func (t *pkcs11Token) open(sealed []byte) ([]byte, error) {
+	if len(sealed) < 12 {
+		return nil, fmt.Errorf("invalid pkcs11 sealed size %d", len(sealed))
+	}
+	t.Lock()
+	defer t.Unlock()
+
+	params := pkcs11.NewGCMParams(sealed[:12], nil, 128)
+	defer params.Free()
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}
+	err := t.ctx.DecryptInit(t.session, mechanism, t.key)
+	if err != nil {
+		return nil, err
+	}
+	return t.ctx.Decrypt(t.session, sealed[12:])
+}
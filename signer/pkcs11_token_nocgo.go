@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package signer
+
+import "fmt"
+
+func openPKCS11Token(module string, slot uint, pin, label string) (hsmToken, error) {
+	return nil, fmt.Errorf("pkcs11 signer %s unsupported without cgo", module)
+}
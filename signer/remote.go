@@ -0,0 +1,266 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+const (
+	remoteMethodPublic   = "public"
+	remoteMethodSign     = "sign"
+	remoteMethodCommit   = "commit"
+	remoteMethodResponse = "response"
+	remoteMethodSecret   = "secret"
+	remoteMethodShared   = "shared"
+
+	remoteFrameSizeLimit = 1024 * 1024
+	remoteTimeout        = 5 * time.Second
+	remoteRequestWindow  = 30 * time.Second
+)
+
+// The remote signer daemon holds the key on another host, and the node
+// connects to it by a unix or tcp socket. Each frame is the HMAC-SHA256 of
+// the body by the shared token followed by the body, and the response MAC
+// covers the request MAC too, so neither a request nor a response could be
+// forged or replayed without the token.
+type remoteRequest struct {
+	Method    string
+	Timestamp uint64
+	Message   []byte
+	Sealed    []byte
+	Signature []byte
+	Mask      uint64
+	Publics   []crypto.Key
+	Label     string
+	Public    crypto.Key
+}
+
+type remoteResponse struct {
+	Error      string
+	Key        crypto.Key
+	Signature  crypto.Signature
+	Commitment crypto.Key
+	Sealed     []byte
+	Response   []byte
+	Secret     crypto.Hash
+}
+
+// Remote is the client of the signer daemon, and the public key is from the
+// config, which must be the key of the daemon.
+type Remote struct {
+	sync.Mutex
+	network string
+	address string
+	token   []byte
+	public  crypto.Key
+	conn    net.Conn
+}
+
+// NewRemote parses the address of the daemon, e.g. unix:/run/mixin.sock or
+// tcp:10.0.0.2:7240, and the hex encoded token.
+func NewRemote(address, token string, public crypto.Key) (*Remote, error) {
+	network, addr, err := parseRemoteAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(token)
+	if err != nil || len(key) < 32 {
+		return nil, fmt.Errorf("invalid signer token")
+	}
+	return &Remote{network: network, address: addr, token: key, public: public}, nil
+}
+
+func parseRemoteAddress(address string) (string, string, error) {
+	parts := strings.SplitN(address, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid signer address %s", address)
+	}
+	switch parts[0] {
+	case "unix", "tcp":
+		return parts[0], parts[1], nil
+	}
+	return "", "", fmt.Errorf("invalid signer address %s", address)
+}
+
+func (r *Remote) PublicKey() crypto.Key {
+	return r.public
+}
+
+func (r *Remote) Sign(message []byte) (crypto.Signature, error) {
+	res, err := r.call(&remoteRequest{Method: remoteMethodSign, Message: message})
+	if err != nil {
+		return crypto.Signature{}, err
+	}
+	if !r.public.Verify(message, res.Signature) {
+		return crypto.Signature{}, fmt.Errorf("invalid remote signer signature")
+	}
+	return res.Signature, nil
+}
+
+// CosiCommit ignores the rand, the random is always generated and sealed by
+// the daemon.
+func (r *Remote) CosiCommit(_ io.Reader) (crypto.Key, []byte, error) {
+	res, err := r.call(&remoteRequest{Method: remoteMethodCommit})
+	if err != nil {
+		return crypto.Key{}, nil, err
+	}
+	return res.Commitment, res.Sealed, nil
+}
+
+func (r *Remote) CosiResponse(cosi *crypto.CosiSignature, sealed []byte, publics []*crypto.Key, message []byte) (*[32]byte, error) {
+	req := &remoteRequest{
+		Method:    remoteMethodResponse,
+		Message:   message,
+		Sealed:    sealed,
+		Signature: cosi.Signature[:],
+		Mask:      cosi.Mask,
+	}
+	for _, k := range publics {
+		req.Publics = append(req.Publics, *k)
+	}
+	res, err := r.call(req)
+	if err != nil {
+		return nil, err
+	}
+	var s [32]byte
+	if len(res.Response) != len(s) {
+		return nil, fmt.Errorf("invalid remote signer response size %d", len(res.Response))
+	}
+	copy(s[:], res.Response)
+	return &s, nil
+}
+
+func (r *Remote) Secret(label string) (crypto.Hash, error) {
+	res, err := r.call(&remoteRequest{Method: remoteMethodSecret, Label: label})
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+	return res.Secret, nil
+}
+
+func (r *Remote) SharedSecret(label string, public crypto.Key) (crypto.Hash, error) {
+	res, err := r.call(&remoteRequest{Method: remoteMethodShared, Label: label, Public: public})
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+	return res.Secret, nil
+}
+
+// call sends the request on the connection, and redials once if the
+// connection is broken, e.g. the daemon restarted.
+func (r *Remote) call(req *remoteRequest) (*remoteResponse, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	req.Timestamp = uint64(time.Now().UnixNano())
+	for i := 0; ; i++ {
+		err := r.connect()
+		if err != nil {
+			return nil, err
+		}
+		res, err := r.roundTrip(req)
+		if err == nil && res.Error != "" {
+			return nil, fmt.Errorf("remote signer %s", res.Error)
+		} else if err == nil {
+			return res, nil
+		}
+		r.conn.Close()
+		r.conn = nil
+		if i > 0 {
+			return nil, err
+		}
+	}
+}
+
+// connect dials the daemon and checks its key is the configured one.
+func (r *Remote) connect() error {
+	if r.conn != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout(r.network, r.address, remoteTimeout)
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+	req := &remoteRequest{Method: remoteMethodPublic, Timestamp: uint64(time.Now().UnixNano())}
+	res, err := r.roundTrip(req)
+	if err == nil && res.Key != r.public {
+		err = fmt.Errorf("remote signer key mismatch %s %s", res.Key, r.public)
+	}
+	if err != nil {
+		r.conn.Close()
+		r.conn = nil
+	}
+	return err
+}
+
+func (r *Remote) roundTrip(req *remoteRequest) (*remoteResponse, error) {
+	err := r.conn.SetDeadline(time.Now().Add(remoteTimeout))
+	if err != nil {
+		return nil, err
+	}
+	body := common.MsgpackMarshalPanic(req)
+	mac := remoteMAC(r.token, nil, body)
+	err = writeRemoteFrame(r.conn, mac, body)
+	if err != nil {
+		return nil, err
+	}
+	body, err = readRemoteFrame(r.conn, r.token, mac)
+	if err != nil {
+		return nil, err
+	}
+	var res remoteResponse
+	err = common.MsgpackUnmarshal(body, &res)
+	return &res, err
+}
+
+func remoteMAC(token, request, body []byte) []byte {
+	h := hmac.New(sha256.New, token)
+	h.Write(request)
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+func writeRemoteFrame(w io.Writer, mac, body []byte) error {
+	frame := make([]byte, 4, 4+len(mac)+len(body))
+	binary.BigEndian.PutUint32(frame, uint32(len(mac)+len(body)))
+	frame = append(frame, mac...)
+	frame = append(frame, body...)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readRemoteFrame returns the body if its MAC is valid, the request is the
+// MAC of the request for a response frame, or nil for a request frame.
+func readRemoteFrame(r io.Reader, token, request []byte) ([]byte, error) {
+	header := make([]byte, 4)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header)
+	if size <= sha256.Size || size > remoteFrameSizeLimit {
+		return nil, fmt.Errorf("invalid signer frame size %d", size)
+	}
+	frame := make([]byte, size)
+	_, err = io.ReadFull(r, frame)
+	if err != nil {
+		return nil, err
+	}
+	mac, body := frame[:sha256.Size], frame[sha256.Size:]
+	if !hmac.Equal(mac, remoteMAC(token, request, body)) {
+		return nil, fmt.Errorf("invalid signer frame mac")
+	}
+	return body, nil
+}
@@ -0,0 +1,253 @@
+package signer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+// sealedRandomLifetime bounds the time between a cosi commitment and its
+// response, a sealed random older than this is refused.
+const sealedRandomLifetime = time.Hour
+
+type sealedRandomUse struct {
+	challenge crypto.Hash
+	response  []byte
+	timestamp time.Time
+}
+
+// Server is the remote signer daemon. The cosi random is sealed by a key
+// generated on start, so all the sealed randoms are invalid after a restart,
+// and a sealed random is only used to respond one challenge. A response to
+// the same challenge again returns the same response, any other challenge
+// is refused, because two responses of a random reveal the private key.
+type Server struct {
+	sync.Mutex
+	key      crypto.Key
+	token    []byte
+	aead     cipher.AEAD
+	requests map[string]time.Time
+	randoms  map[crypto.Hash]*sealedRandomUse
+}
+
+func NewServer(key crypto.Key, token []byte) (*Server, error) {
+	if len(token) < 32 {
+		return nil, fmt.Errorf("invalid signer token size %d", len(token))
+	}
+	secret := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, secret)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		key:      key,
+		token:    token,
+		aead:     aead,
+		requests: make(map[string]time.Time),
+		randoms:  make(map[crypto.Hash]*sealedRandomUse),
+	}, nil
+}
+
+// Listen listens on the address of the same format as the signer-remote of
+// the node config.
+func Listen(address string) (net.Listener, error) {
+	network, addr, err := parseRemoteAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen(network, addr)
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		body, err := readRemoteFrame(conn, s.token, nil)
+		if err != nil {
+			if err != io.EOF {
+				logger.Printf("signer.serveConn %s ERROR %s\n", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		mac := remoteMAC(s.token, nil, body)
+		res := s.handle(mac, body)
+		body = common.MsgpackMarshalPanic(res)
+		err = writeRemoteFrame(conn, remoteMAC(s.token, mac, body), body)
+		if err != nil {
+			logger.Printf("signer.serveConn %s ERROR %s\n", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+func (s *Server) handle(mac, body []byte) *remoteResponse {
+	var req remoteRequest
+	err := common.MsgpackUnmarshal(body, &req)
+	if err != nil {
+		return &remoteResponse{Error: err.Error()}
+	}
+	err = s.checkRequest(mac, &req)
+	if err != nil {
+		return &remoteResponse{Error: err.Error()}
+	}
+	logger.Verbosef("signer.handle %s %d\n", req.Method, req.Timestamp)
+
+	switch req.Method {
+	case remoteMethodPublic:
+		return &remoteResponse{Key: s.key.Public()}
+	case remoteMethodSign:
+		return &remoteResponse{Signature: s.key.Sign(req.Message)}
+	case remoteMethodCommit:
+		r := crypto.CosiCommit(rand.Reader)
+		sealed, err := s.seal(r)
+		if err != nil {
+			return &remoteResponse{Error: err.Error()}
+		}
+		return &remoteResponse{Commitment: r.Public(), Sealed: sealed}
+	case remoteMethodResponse:
+		response, err := s.respond(&req)
+		if err != nil {
+			return &remoteResponse{Error: err.Error()}
+		}
+		return &remoteResponse{Response: response}
+	case remoteMethodSecret:
+		secret, _ := NewLocal(s.key).Secret(req.Label)
+		return &remoteResponse{Secret: secret}
+	case remoteMethodShared:
+		secret, err := NewLocal(s.key).SharedSecret(req.Label, req.Public)
+		if err != nil {
+			return &remoteResponse{Error: err.Error()}
+		}
+		return &remoteResponse{Secret: secret}
+	}
+	return &remoteResponse{Error: fmt.Sprintf("invalid method %s", req.Method)}
+}
+
+// checkRequest refuses the requests out of the time window, and the requests
+// seen in the window, so a captured request could never be replayed.
+func (s *Server) checkRequest(mac []byte, req *remoteRequest) error {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	ts := time.Unix(0, int64(req.Timestamp))
+	if ts.Before(now.Add(-remoteRequestWindow)) || ts.After(now.Add(remoteRequestWindow)) {
+		return fmt.Errorf("invalid timestamp %d", req.Timestamp)
+	}
+	for k, t := range s.requests {
+		if t.Before(now.Add(-remoteRequestWindow * 2)) {
+			delete(s.requests, k)
+		}
+	}
+	if _, found := s.requests[string(mac)]; found {
+		return fmt.Errorf("replayed request %x", mac)
+	}
+	s.requests[string(mac)] = ts
+	return nil
+}
+
+func (s *Server) respond(req *remoteRequest) ([]byte, error) {
+	var cosi crypto.CosiSignature
+	if len(req.Signature) != len(cosi.Signature) {
+		return nil, fmt.Errorf("invalid cosi signature size %d", len(req.Signature))
+	}
+	copy(cosi.Signature[:], req.Signature)
+	cosi.Mask = req.Mask
+	publics := make([]*crypto.Key, len(req.Publics))
+	for i := range req.Publics {
+		publics[i] = &req.Publics[i]
+	}
+
+	random, ts, err := s.unseal(req.Sealed)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(ts) > sealedRandomLifetime {
+		return nil, fmt.Errorf("expired cosi random %s", ts)
+	}
+	challenge, err := cosi.Challenge(publics, req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	for k, u := range s.randoms {
+		if time.Since(u.timestamp) > sealedRandomLifetime {
+			delete(s.randoms, k)
+		}
+	}
+	id := crypto.NewHash(req.Sealed)
+	ch := crypto.NewHash(challenge.Bytes())
+	if u := s.randoms[id]; u != nil {
+		if u.challenge != ch {
+			return nil, fmt.Errorf("cosi random used for challenge %s", u.challenge)
+		}
+		return u.response, nil
+	}
+	response, err := cosi.Response(&s.key, random, publics, req.Message)
+	if err != nil {
+		return nil, err
+	}
+	s.randoms[id] = &sealedRandomUse{challenge: ch, response: response[:], timestamp: ts}
+	return response[:], nil
+}
+
+func (s *Server) seal(random *crypto.Key) ([]byte, error) {
+	plain := make([]byte, 40)
+	copy(plain, random[:])
+	binary.BigEndian.PutUint64(plain[32:], uint64(time.Now().UnixNano()))
+	nonce := make([]byte, s.aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (s *Server) unseal(sealed []byte) (*crypto.Key, time.Time, error) {
+	size := s.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, time.Time{}, fmt.Errorf("invalid cosi random size %d", len(sealed))
+	}
+	plain, err := s.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if len(plain) != 40 {
+		return nil, time.Time{}, fmt.Errorf("invalid cosi random size %d", len(plain))
+	}
+	var random crypto.Key
+	copy(random[:], plain)
+	ts := time.Unix(0, int64(binary.BigEndian.Uint64(plain[32:])))
+	return &random, ts, nil
+}
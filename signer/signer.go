@@ -0,0 +1,159 @@
+package signer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+)
+
+// localRandomSecretLabel derives the key to seal the cosi randoms of the
+// local signer, which the node never asks as a Secret.
+const localRandomSecretLabel = "MIXINLOCALCOSIRANDOM"
+
+// Signer holds the private spend key of the node, and signs for the node
+// without exposing the key. The cosi random is sealed by the signer, so that
+// the host never learns the random of a response, which would reveal the
+// key with the response.
+type Signer interface {
+	PublicKey() crypto.Key
+	Sign(message []byte) (crypto.Signature, error)
+	CosiCommit(rand io.Reader) (crypto.Key, []byte, error)
+	CosiResponse(cosi *crypto.CosiSignature, sealed []byte, publics []*crypto.Key, message []byte) (*[32]byte, error)
+	Secret(label string) (crypto.Hash, error)
+	SharedSecret(label string, public crypto.Key) (crypto.Hash, error)
+}
+
+// New returns the signer of the node config, the remote signer connects to
+// the daemon until the first use, and the PKCS#11 signer logins the HSM at
+// once to check the wrapped key.
+func New(custom *config.Custom) (Signer, error) {
+	switch custom.Node.SignerBackend {
+	case config.SignerBackendLocal:
+		return NewLocal(custom.Node.Signer), nil
+	case config.SignerBackendRemote:
+		return NewRemote(custom.Node.SignerRemote, custom.Node.SignerToken, custom.Node.SignerPublic)
+	case config.SignerBackendPKCS11:
+		n := custom.Node
+		return NewPKCS11(n.SignerPKCS11Module, n.SignerPKCS11Slot, n.SignerPKCS11Pin, n.SignerPKCS11Label, n.SignerPKCS11Wrapped, n.SignerPublic)
+	}
+	return nil, fmt.Errorf("invalid signer backend %s", custom.Node.SignerBackend)
+}
+
+// Local is the signer with the key in memory. The cosi random is sealed by
+// a key derived from the private key with the commit time, so the sealed
+// randoms persisted by the node are still valid after a restart, each at
+// most for the sealed random lifetime. Like the Server, a random only
+// responds one challenge since the start, and the node must persist the
+// challenge before the response to keep it across restarts.
+type Local struct {
+	sync.Mutex
+	key     crypto.Key
+	aead    cipher.AEAD
+	randoms map[crypto.Hash]*sealedRandomUse
+}
+
+func NewLocal(key crypto.Key) *Local {
+	secret := crypto.NewHash(append([]byte(localRandomSecretLabel), key[:]...))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &Local{key: key, aead: aead, randoms: make(map[crypto.Hash]*sealedRandomUse)}
+}
+
+func (l *Local) PublicKey() crypto.Key {
+	return l.key.Public()
+}
+
+func (l *Local) Sign(message []byte) (crypto.Signature, error) {
+	return l.key.Sign(message), nil
+}
+
+func (l *Local) CosiCommit(rand io.Reader) (crypto.Key, []byte, error) {
+	r := crypto.CosiCommit(rand)
+	plain := make([]byte, 40)
+	copy(plain, r[:])
+	binary.BigEndian.PutUint64(plain[32:], uint64(time.Now().UnixNano()))
+	nonce := make([]byte, l.aead.NonceSize())
+	_, err := io.ReadFull(rand, nonce)
+	if err != nil {
+		return crypto.Key{}, nil, err
+	}
+	return r.Public(), l.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (l *Local) CosiResponse(cosi *crypto.CosiSignature, sealed []byte, publics []*crypto.Key, message []byte) (*[32]byte, error) {
+	size := l.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, fmt.Errorf("invalid cosi random size %d", len(sealed))
+	}
+	plain, err := l.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(plain) != 40 {
+		return nil, fmt.Errorf("invalid cosi random size %d", len(plain))
+	}
+	var random crypto.Key
+	copy(random[:], plain)
+	ts := time.Unix(0, int64(binary.BigEndian.Uint64(plain[32:])))
+	if time.Since(ts) > sealedRandomLifetime {
+		return nil, fmt.Errorf("expired cosi random %s", ts)
+	}
+	challenge, err := cosi.Challenge(publics, message)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	for k, u := range l.randoms {
+		if time.Since(u.timestamp) > sealedRandomLifetime {
+			delete(l.randoms, k)
+		}
+	}
+	id := crypto.NewHash(sealed)
+	ch := crypto.NewHash(challenge.Bytes())
+	if u := l.randoms[id]; u != nil {
+		if u.challenge != ch {
+			return nil, fmt.Errorf("cosi random used for challenge %s", u.challenge)
+		}
+		var response [32]byte
+		copy(response[:], u.response)
+		return &response, nil
+	}
+	response, err := cosi.Response(&l.key, &random, publics, message)
+	if err != nil {
+		return nil, err
+	}
+	l.randoms[id] = &sealedRandomUse{challenge: ch, response: response[:], timestamp: ts}
+	return response, nil
+}
+
+// Secret derives the secret of the label from the key, which is never used
+// to sign anything.
+func (l *Local) Secret(label string) (crypto.Hash, error) {
+	return crypto.NewHash(append([]byte(label), l.key[:]...)), nil
+}
+
+// SharedSecret derives the secret of the label from the ECDH of the key and
+// the public key of another node, which is the same for both nodes.
+func (l *Local) SharedSecret(label string, public crypto.Key) (crypto.Hash, error) {
+	if !public.CheckKey() {
+		return crypto.Hash{}, fmt.Errorf("invalid shared secret public key %s", public)
+	}
+	p := crypto.KeyMultPubPriv(&public, &l.key)
+	return crypto.NewHash(append([]byte(label), p.Bytes()...)), nil
+}
@@ -0,0 +1,175 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := crypto.NewHash([]byte("signer"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	token := make([]byte, 32)
+	rand.Read(token)
+
+	server, err := NewServer(key, token)
+	assert.Nil(err)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(err)
+	defer l.Close()
+	go server.Serve(l)
+
+	address := "tcp:" + l.Addr().String()
+	local := NewLocal(key)
+	remote, err := NewRemote(address, hex.EncodeToString(token), key.Public())
+	assert.Nil(err)
+	assert.Equal(local.PublicKey(), remote.PublicKey())
+
+	msg := []byte("mixin")
+	sig, err := remote.Sign(msg)
+	assert.Nil(err)
+	pub := key.Public()
+	assert.True(pub.Verify(msg, sig))
+
+	secret, err := remote.Secret("COSISTATE")
+	assert.Nil(err)
+	expected, _ := local.Secret("COSISTATE")
+	assert.Equal(expected, secret)
+
+	otherSeed := crypto.NewHash([]byte("other"))
+	otherKey := crypto.NewKeyFromSeed(append(otherSeed[:], otherSeed[:]...))
+	shared, err := remote.SharedSecret("RELAY", otherKey.Public())
+	assert.Nil(err)
+	expected, _ = NewLocal(otherKey).SharedSecret("RELAY", key.Public())
+	assert.Equal(expected, shared)
+	expected, _ = local.SharedSecret("OTHER", otherKey.Public())
+	assert.NotEqual(expected, shared)
+
+	R, sealed, err := remote.CosiCommit(nil)
+	assert.Nil(err)
+	cosi, err := crypto.CosiAggregateCommitment(map[int]*crypto.Key{0: &R})
+	assert.Nil(err)
+	publics := []*crypto.Key{&pub}
+	response, err := remote.CosiResponse(cosi, sealed, publics, msg)
+	assert.Nil(err)
+	assert.Nil(cosi.VerifyResponse(publics, 0, response, msg))
+	again, err := remote.CosiResponse(cosi, sealed, publics, msg)
+	assert.Nil(err)
+	assert.Equal(response, again)
+	_, err = remote.CosiResponse(cosi, sealed, publics, []byte("other"))
+	assert.NotNil(err)
+
+	R, random, err := local.CosiCommit(rand.Reader)
+	assert.Nil(err)
+	cosi, err = crypto.CosiAggregateCommitment(map[int]*crypto.Key{0: &R})
+	assert.Nil(err)
+	response, err = local.CosiResponse(cosi, random, publics, msg)
+	assert.Nil(err)
+	assert.Nil(cosi.VerifyResponse(publics, 0, response, msg))
+	again, err = local.CosiResponse(cosi, random, publics, msg)
+	assert.Nil(err)
+	assert.Equal(response, again)
+	_, err = local.CosiResponse(cosi, random, publics, []byte("other"))
+	assert.NotNil(err)
+	again, err = NewLocal(key).CosiResponse(cosi, random, publics, msg)
+	assert.Nil(err)
+	assert.Equal(response, again)
+	_, err = NewLocal(otherKey).CosiResponse(cosi, random, publics, msg)
+	assert.NotNil(err)
+	_, err = local.CosiResponse(cosi, random[1:], publics, msg)
+	assert.NotNil(err)
+
+	other, err := NewRemote(address, hex.EncodeToString(make([]byte, 32)), key.Public())
+	assert.Nil(err)
+	_, err = other.Sign(msg)
+	assert.NotNil(err)
+	other, err = NewRemote(address, hex.EncodeToString(token), R)
+	assert.Nil(err)
+	_, err = other.Sign(msg)
+	assert.NotNil(err)
+	_, err = NewRemote("udp:127.0.0.1:7240", hex.EncodeToString(token), key.Public())
+	assert.NotNil(err)
+}
+
+type testToken struct {
+	aead cipher.AEAD
+}
+
+func (t *testToken) seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	rand.Read(nonce)
+	return t.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (t *testToken) open(sealed []byte) ([]byte, error) {
+	size := t.aead.NonceSize()
+	return t.aead.Open(nil, sealed[:size], sealed[size:], nil)
+}
+
+func TestPKCS11Signer(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := crypto.NewHash([]byte("signer"))
+	key := crypto.NewKeyFromSeed(append(seed[:], seed[:]...))
+	pub := key.Public()
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	block, err := aes.NewCipher(secret)
+	assert.Nil(err)
+	aead, err := cipher.NewGCM(block)
+	assert.Nil(err)
+	token := &testToken{aead: aead}
+	wrapped, err := token.seal(key[:])
+	assert.Nil(err)
+	assert.False(bytes.Contains(wrapped, key[:]))
+
+	_, err = newPKCS11(token, wrapped, crypto.Key(seed))
+	assert.NotNil(err)
+	_, err = newPKCS11(token, append([]byte{}, wrapped[1:]...), pub)
+	assert.NotNil(err)
+	hsm, err := newPKCS11(token, wrapped, pub)
+	assert.Nil(err)
+	assert.Equal(pub, hsm.PublicKey())
+	local := NewLocal(key)
+
+	msg := []byte("mixin")
+	sig, err := hsm.Sign(msg)
+	assert.Nil(err)
+	assert.True(pub.Verify(msg, sig))
+	secretHash, err := hsm.Secret("COSISTATE")
+	assert.Nil(err)
+	expected, _ := local.Secret("COSISTATE")
+	assert.Equal(expected, secretHash)
+	secretHash, err = hsm.SharedSecret("RELAY", crypto.Key(seed).DeterministicHashDerive().Public())
+	assert.Nil(err)
+	expected, _ = local.SharedSecret("RELAY", crypto.Key(seed).DeterministicHashDerive().Public())
+	assert.Equal(expected, secretHash)
+
+	R, sealed, err := hsm.CosiCommit(rand.Reader)
+	assert.Nil(err)
+	assert.Len(sealed, 12+40+16)
+	cosi, err := crypto.CosiAggregateCommitment(map[int]*crypto.Key{0: &R})
+	assert.Nil(err)
+	publics := []*crypto.Key{&pub}
+	response, err := hsm.CosiResponse(cosi, sealed, publics, msg)
+	assert.Nil(err)
+	assert.Nil(cosi.VerifyResponse(publics, 0, response, msg))
+	again, err := hsm.CosiResponse(cosi, sealed, publics, msg)
+	assert.Nil(err)
+	assert.Equal(response, again)
+	_, err = hsm.CosiResponse(cosi, sealed, publics, []byte("other"))
+	assert.NotNil(err)
+	_, err = hsm.CosiResponse(cosi, sealed[1:], publics, msg)
+	assert.NotNil(err)
+	_, err = local.CosiResponse(cosi, sealed, publics, msg)
+	assert.NotNil(err)
+}
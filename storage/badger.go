@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/MixinNetwork/mixin/config"
@@ -13,28 +14,60 @@ type BadgerStore struct {
 	custom      *config.Custom
 	snapshotsDB *badger.DB
 	cacheDB     *badger.DB
-	closing     bool
+	throttle    *ioThrottle
+	txCache     *transactionCacheMetrics
+	batcher     *snapshotBatcher
+	manifests   chan struct{}
+	closing     uint32
 }
 
 func NewBadgerStore(custom *config.Custom, dir string) (*BadgerStore, error) {
-	snapshotsDB, err := openDB(dir+"/snapshots", true, custom)
+	throttle := newIOThrottle(custom.Storage.DiskBandwidth, custom.Storage.BackgroundShare)
+	snapshotsDB, err := openDB(dir+"/snapshots", true, custom, throttle)
 	if err != nil {
 		return nil, err
 	}
-	cacheDB, err := openDB(dir+"/cache", false, custom)
+	cacheDB, err := openDB(dir+"/cache", false, custom, throttle)
 	if err != nil {
 		return nil, err
 	}
-	return &BadgerStore{
+	return newBadgerStore(custom, snapshotsDB, cacheDB, throttle)
+}
+
+func newBadgerStore(custom *config.Custom, snapshotsDB, cacheDB *badger.DB, throttle *ioThrottle) (*BadgerStore, error) {
+	store := &BadgerStore{
 		custom:      custom,
 		snapshotsDB: snapshotsDB,
 		cacheDB:     cacheDB,
-		closing:     false,
-	}, nil
+		throttle:    throttle,
+		txCache:     &transactionCacheMetrics{},
+		manifests:   make(chan struct{}),
+	}
+	entries, err := store.listCacheTransactionExpirations()
+	if err != nil {
+		return nil, err
+	}
+	store.txCache.size = int64(len(entries))
+	window := time.Duration(custom.Storage.SnapshotBatchWindow) * time.Millisecond
+	store.batcher = newSnapshotBatcher(window, custom.Storage.SnapshotBatchSize)
+	go store.loopSnapshotBatches()
+	go store.loopTopologyManifests()
+	return store, nil
+}
+
+// isClosing is read by the loops and the writers without lock, while the
+// Close sets it from another goroutine.
+func (store *BadgerStore) isClosing() bool {
+	return atomic.LoadUint32(&store.closing) == 1
 }
 
 func (store *BadgerStore) Close() error {
-	store.closing = true
+	if !atomic.CompareAndSwapUint32(&store.closing, 0, 1) {
+		return nil
+	}
+	store.batcher.wake()
+	<-store.batcher.done
+	<-store.manifests
 	err := store.snapshotsDB.Close()
 	if err != nil {
 		return err
@@ -42,12 +75,15 @@ func (store *BadgerStore) Close() error {
 	return store.cacheDB.Close()
 }
 
-func openDB(dir string, sync bool, custom *config.Custom) (*badger.DB, error) {
+func openDB(dir string, sync bool, custom *config.Custom, throttle *ioThrottle) (*badger.DB, error) {
 	opts := badger.DefaultOptions(dir)
 	opts = opts.WithSyncWrites(sync)
-	opts = opts.WithCompression(options.None)
-	opts = opts.WithBlockCacheSize(0)
-	opts = opts.WithIndexCacheSize(0)
+	opts = opts.WithValueLogFileSize(int64(custom.Storage.ValueLogFileSize) << 20)
+	opts = opts.WithMemTableSize(int64(custom.Storage.MemTableSize) << 20)
+	opts = opts.WithNumMemtables(custom.Storage.NumMemTables)
+	opts = opts.WithCompression(storageCompression(custom.Storage.Compression))
+	opts = opts.WithBlockCacheSize(int64(custom.Storage.BlockCacheSize) << 20)
+	opts = opts.WithIndexCacheSize(int64(custom.Storage.IndexCacheSize) << 20)
 	opts = opts.WithMetricsEnabled(false)
 	opts = opts.WithLoggingLevel(badger.ERROR)
 	db, err := badger.Open(opts)
@@ -61,6 +97,7 @@ func openDB(dir string, sync bool, custom *config.Custom) (*badger.DB, error) {
 				lsm, vlog := db.Size()
 				logger.Printf("Badger LSM %d VLOG %d\n", lsm, vlog)
 				if lsm > 1024*1024*8 || vlog > 1024*1024*32 {
+					throttle.wait(int(opts.ValueLogFileSize))
 					err := db.RunValueLogGC(0.5)
 					logger.Printf("Badger RunValueLogGC %v\n", err)
 				}
@@ -71,3 +108,47 @@ func openDB(dir string, sync bool, custom *config.Custom) (*badger.DB, error) {
 
 	return db, nil
 }
+
+// StorageInfo is the badger tuning applied when the store opened, and the
+// live LSM and value log sizes in bytes of the snapshots and cache databases.
+type StorageInfo struct {
+	Profile          string
+	ValueLogFileSize int
+	MemTableSize     int
+	NumMemTables     int
+	BlockCacheSize   int
+	IndexCacheSize   int
+	Compression      string
+	Snapshots        StorageDBSize
+	Cache            StorageDBSize
+}
+
+type StorageDBSize struct {
+	LSM      int64
+	ValueLog int64
+}
+
+func (s *BadgerStore) StorageInfo() StorageInfo {
+	info := StorageInfo{
+		Profile:          s.custom.Storage.Profile,
+		ValueLogFileSize: s.custom.Storage.ValueLogFileSize,
+		MemTableSize:     s.custom.Storage.MemTableSize,
+		NumMemTables:     s.custom.Storage.NumMemTables,
+		BlockCacheSize:   s.custom.Storage.BlockCacheSize,
+		IndexCacheSize:   s.custom.Storage.IndexCacheSize,
+		Compression:      s.custom.Storage.Compression,
+	}
+	info.Snapshots.LSM, info.Snapshots.ValueLog = s.snapshotsDB.Size()
+	info.Cache.LSM, info.Cache.ValueLog = s.cacheDB.Size()
+	return info
+}
+
+func storageCompression(c string) options.CompressionType {
+	switch c {
+	case config.StorageCompressionSnappy:
+		return options.Snappy
+	case config.StorageCompressionZSTD:
+		return options.ZSTD
+	}
+	return options.None
+}
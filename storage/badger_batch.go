@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
+)
+
+const snapshotBatchGapTimeout = 30 * time.Second
+
+// snapshotBatcher coalesces the snapshot writes arriving within the window
+// into one transaction, so the sync writes of many snapshots share a single
+// commit. The writes are committed strictly in the topological order, no
+// matter the order they arrive, so the topology never has any hole.
+type snapshotBatcher struct {
+	sync.Mutex
+	window  time.Duration
+	size    int
+	since   time.Time
+	pending map[uint64]*snapshotWrite
+	closed  bool
+	signal  chan struct{}
+	done    chan struct{}
+}
+
+type snapshotWrite struct {
+	snap    *common.SnapshotWithTopologicalOrder
+	signers []crypto.Hash
+	result  chan error
+}
+
+func newSnapshotBatcher(window time.Duration, size int) *snapshotBatcher {
+	if size < 1 {
+		size = 1
+	}
+	return &snapshotBatcher{
+		window:  window,
+		size:    size,
+		pending: make(map[uint64]*snapshotWrite),
+		signal:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (b *snapshotBatcher) queue(w *snapshotWrite) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.closed {
+		return fmt.Errorf("snapshot write %d store closing", w.snap.TopologicalOrder)
+	}
+	if b.pending[w.snap.TopologicalOrder] != nil {
+		panic(fmt.Errorf("snapshot topology duplication %d", w.snap.TopologicalOrder))
+	}
+	if len(b.pending) == 0 {
+		b.since = time.Now()
+	}
+	b.pending[w.snap.TopologicalOrder] = w
+	b.wake()
+	return nil
+}
+
+func (b *snapshotBatcher) hasPending() bool {
+	b.Lock()
+	defer b.Unlock()
+	return len(b.pending) > 0
+}
+
+func (b *snapshotBatcher) wake() {
+	select {
+	case b.signal <- struct{}{}:
+	default:
+	}
+}
+
+// take returns at most limit writes in the order from the next one, and stops
+// at the first missing order.
+func (b *snapshotBatcher) take(next uint64, limit int) []*snapshotWrite {
+	b.Lock()
+	defer b.Unlock()
+
+	var batch []*snapshotWrite
+	for ; len(batch) < limit; next++ {
+		w := b.pending[next]
+		if w == nil {
+			break
+		}
+		delete(b.pending, next)
+		batch = append(batch, w)
+	}
+	if len(batch) > 0 {
+		b.since = time.Now()
+	}
+	if len(b.pending) > 0 && time.Since(b.since) > snapshotBatchGapTimeout {
+		panic(fmt.Errorf("snapshot topology gap %d %d", next, len(b.pending)))
+	}
+	return batch
+}
+
+func (s *BadgerStore) WriteSnapshot(snap *common.SnapshotWithTopologicalOrder, signers []crypto.Hash) error {
+	w := &snapshotWrite{snap: snap, signers: signers, result: make(chan error, 1)}
+	if s.isClosing() {
+		return fmt.Errorf("snapshot write %d store closing", snap.TopologicalOrder)
+	}
+	err := s.batcher.queue(w)
+	if err != nil {
+		return err
+	}
+	return <-w.result
+}
+
+func (s *BadgerStore) loopSnapshotBatches() {
+	b := s.batcher
+	defer close(b.done)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for !s.isClosing() {
+		// this loop is the only writer of the snapshots, so the next order
+		// is always the one after all the committed snapshots
+		next := s.TopologySequence() + 1
+		batch := b.take(next, b.size)
+		if len(batch) == 0 {
+			select {
+			case <-b.signal:
+			case <-ticker.C:
+			}
+			continue
+		}
+		// wait for the missing orders only when some later ones are already
+		// queued, otherwise the batch is committed without any delay
+		if len(batch) < b.size && b.hasPending() {
+			timer := time.NewTimer(b.window)
+			select {
+			case <-b.signal:
+			case <-timer.C:
+			}
+			timer.Stop()
+			batch = append(batch, b.take(next+uint64(len(batch)), b.size-len(batch))...)
+		}
+
+		err := s.writeSnapshotBatch(batch)
+		if err == nil {
+			for _, w := range batch {
+				w.result <- nil
+			}
+			continue
+		}
+		logger.Verbosef("writeSnapshotBatch %d %d ERROR %s\n", batch[0].snap.TopologicalOrder, len(batch), err)
+		s.retrySnapshotBatch(batch)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	b.closed = true
+	for _, w := range b.pending {
+		w.result <- fmt.Errorf("snapshot write %d store closing", w.snap.TopologicalOrder)
+	}
+}
+
+// retrySnapshotBatch writes the snapshots one by one, and stops at the first
+// failed order, because a later order committed after it would make a hole
+// in the topology.
+func (s *BadgerStore) retrySnapshotBatch(batch []*snapshotWrite) {
+	for i, w := range batch {
+		err := s.writeSnapshotBatch([]*snapshotWrite{w})
+		w.result <- err
+		if err == nil {
+			continue
+		}
+		for _, r := range batch[i+1:] {
+			r.result <- fmt.Errorf("snapshot write %d after %d failed", r.snap.TopologicalOrder, w.snap.TopologicalOrder)
+		}
+		return
+	}
+}
+
+// writeSnapshotBatch writes all the snapshots in one transaction, and nothing
+// is written if any of them fails, e.g. the transaction is too big or in
+// conflict, then the caller retries them one by one.
+func (s *BadgerStore) writeSnapshotBatch(batch []*snapshotWrite) error {
+	txn := s.snapshotsDB.NewTransaction(true)
+	defer txn.Discard()
+
+	for _, w := range batch {
+		err := s.assertSnapshotWrite(txn, w.snap)
+		if err != nil {
+			return err
+		}
+		ver, err := readTransaction(txn, w.snap.Transaction)
+		if err != nil {
+			return err
+		}
+		err = writeSnapshot(txn, w.snap, ver)
+		if err != nil {
+			return err
+		}
+		err = writeSnapshotWork(txn, w.snap, w.signers)
+		if err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
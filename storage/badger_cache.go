@@ -1,10 +1,13 @@
 package storage
 
 import (
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/MixinNetwork/mixin/common"
 	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/MixinNetwork/mixin/logger"
 	"github.com/dgraph-io/badger/v3"
 )
 
@@ -12,8 +15,41 @@ const (
 	cachePrefixTransactionCache  = "TRANSACTIONCACHE"
 	cachePrefixSnapshotNodeQueue = "SNAPSHOTNODEQUEUE"
 	cachePrefixSnapshotNodeMeta  = "SNAPSHOTNODEMETA"
+	cachePrefixCosiState         = "COSISTATE"
 )
 
+// TransactionCacheStats is the approximate size of the unconfirmed
+// transactions cache, and the counters since the store opened.
+type TransactionCacheStats struct {
+	Size      int64
+	Limit     int64
+	Puts      uint64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type transactionCacheMetrics struct {
+	size      int64
+	puts      uint64
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	evicting  int32
+}
+
+func (s *BadgerStore) CacheTransactionStats() TransactionCacheStats {
+	m := s.txCache
+	return TransactionCacheStats{
+		Size:      atomic.LoadInt64(&m.size),
+		Limit:     int64(s.custom.Node.TransactionCacheSize),
+		Puts:      atomic.LoadUint64(&m.puts),
+		Hits:      atomic.LoadUint64(&m.hits),
+		Misses:    atomic.LoadUint64(&m.misses),
+		Evictions: atomic.LoadUint64(&m.evictions),
+	}
+}
+
 func (s *BadgerStore) CacheListTransactions(offset crypto.Hash, limit int) ([]*common.VersionedTransaction, error) {
 	txn := s.cacheDB.NewTransaction(false)
 	defer txn.Discard()
@@ -59,9 +95,14 @@ func (s *BadgerStore) CacheRemoveTransactions(hashes []crypto.Hash) error {
 			}
 			return nil
 		})
-		if err != nil || len(hashes) <= batch {
+		if err != nil {
 			return err
 		}
+		if len(hashes) <= batch {
+			atomic.AddInt64(&s.txCache.size, -int64(len(hashes)))
+			return nil
+		}
+		atomic.AddInt64(&s.txCache.size, -int64(batch))
 		hashes = hashes[batch:]
 	}
 }
@@ -77,7 +118,18 @@ func (s *BadgerStore) CachePutTransaction(tx *common.VersionedTransaction) error
 	if err != nil {
 		return err
 	}
-	return txn.Commit()
+	err = txn.Commit()
+	if err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&s.txCache.puts, 1)
+	size := atomic.AddInt64(&s.txCache.size, 1)
+	limit := s.custom.Node.TransactionCacheSize
+	if limit > 0 && size > int64(limit) && atomic.CompareAndSwapInt32(&s.txCache.evicting, 0, 1) {
+		go s.evictCacheTransactions(limit)
+	}
+	return nil
 }
 
 func (s *BadgerStore) CacheGetTransaction(hash crypto.Hash) (*common.VersionedTransaction, error) {
@@ -87,10 +139,12 @@ func (s *BadgerStore) CacheGetTransaction(hash crypto.Hash) (*common.VersionedTr
 	key := cacheTransactionCacheKey(hash)
 	item, err := txn.Get(key)
 	if err == badger.ErrKeyNotFound {
+		atomic.AddUint64(&s.txCache.misses, 1)
 		return nil, nil
 	} else if err != nil {
 		return nil, err
 	}
+	atomic.AddUint64(&s.txCache.hits, 1)
 	val, err := item.ValueCopy(nil)
 	if err != nil {
 		return nil, err
@@ -98,6 +152,94 @@ func (s *BadgerStore) CacheGetTransaction(hash crypto.Hash) (*common.VersionedTr
 	return common.DecompressUnmarshalVersionedTransaction(val)
 }
 
+// evictCacheTransactions recounts the cached transactions, because the
+// size counter drifts with the TTL expirations and overwrites, and removes
+// the ones closest to expire until a tenth below the limit.
+func (s *BadgerStore) evictCacheTransactions(limit int) {
+	defer atomic.StoreInt32(&s.txCache.evicting, 0)
+
+	entries, err := s.listCacheTransactionExpirations()
+	if err != nil {
+		logger.Printf("evictCacheTransactions ERROR %s\n", err)
+		return
+	}
+	atomic.StoreInt64(&s.txCache.size, int64(len(entries)))
+	if len(entries) <= limit {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].expiresAt < entries[j].expiresAt
+	})
+	hashes := make([]crypto.Hash, len(entries)-limit*9/10)
+	for i := range hashes {
+		hashes[i] = entries[i].hash
+	}
+	err = s.CacheRemoveTransactions(hashes)
+	if err != nil {
+		logger.Printf("evictCacheTransactions ERROR %s\n", err)
+		return
+	}
+	atomic.AddUint64(&s.txCache.evictions, uint64(len(hashes)))
+	logger.Printf("evictCacheTransactions %d %d\n", len(entries), len(hashes))
+}
+
+type cacheTransactionExpiration struct {
+	hash      crypto.Hash
+	expiresAt uint64
+}
+
+func (s *BadgerStore) listCacheTransactionExpirations() ([]*cacheTransactionExpiration, error) {
+	txn := s.cacheDB.NewTransaction(false)
+	defer txn.Discard()
+
+	prefix := []byte(cachePrefixTransactionCache)
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Prefix = prefix
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	var entries []*cacheTransactionExpiration
+	for it.Seek(prefix); it.Valid(); it.Next() {
+		item := it.Item()
+		e := &cacheTransactionExpiration{expiresAt: item.ExpiresAt()}
+		copy(e.hash[:], item.Key()[len(prefix):])
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (s *BadgerStore) CacheWriteCosiState(chainId crypto.Hash, state []byte) error {
+	txn := s.cacheDB.NewTransaction(true)
+	defer txn.Discard()
+
+	key := cacheCosiStateKey(chainId)
+	etr := badger.NewEntry(key, state).WithTTL(time.Duration(s.custom.Node.CacheTTL) * time.Second)
+	err := txn.SetEntry(etr)
+	if err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+func (s *BadgerStore) CacheReadCosiState(chainId crypto.Hash) ([]byte, error) {
+	txn := s.cacheDB.NewTransaction(false)
+	defer txn.Discard()
+
+	item, err := txn.Get(cacheCosiStateKey(chainId))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+func cacheCosiStateKey(chainId crypto.Hash) []byte {
+	return append([]byte(cachePrefixCosiState), chainId[:]...)
+}
+
 func cacheTransactionCacheKey(hash crypto.Hash) []byte {
 	return append([]byte(cachePrefixTransactionCache), hash[:]...)
 }
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+const (
+	graphPrefixCursor = "CURSOR"
+	cursorsLimit      = 256
+)
+
+// Cursor is a named consumer position in the topology stream, the offset
+// is the next topological order to read.
+type Cursor struct {
+	Name      string
+	Offset    uint64
+	Timestamp uint64
+}
+
+func (s *BadgerStore) ReadCursor(name string) (*Cursor, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	item, err := txn.Get(graphCursorKey(name))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	val, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseCursor(name, val)
+}
+
+func (s *BadgerStore) WriteCursor(name string, offset uint64) error {
+	val := make([]byte, 16)
+	binary.BigEndian.PutUint64(val[:8], offset)
+	binary.BigEndian.PutUint64(val[8:], uint64(time.Now().UnixNano()))
+	return s.snapshotsDB.Update(func(txn *badger.Txn) error {
+		key := graphCursorKey(name)
+		_, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			if count := countCursors(txn); count >= cursorsLimit {
+				return fmt.Errorf("cursors limit %d reached", count)
+			}
+		} else if err != nil {
+			return err
+		}
+		return txn.Set(key, val)
+	})
+}
+
+func (s *BadgerStore) RemoveCursor(name string) error {
+	return s.snapshotsDB.Update(func(txn *badger.Txn) error {
+		return txn.Delete(graphCursorKey(name))
+	})
+}
+
+func (s *BadgerStore) ListCursors() ([]*Cursor, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(graphPrefixCursor)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	cursors := make([]*Cursor, 0)
+	for it.Seek(opts.Prefix); it.Valid(); it.Next() {
+		item := it.Item()
+		name := string(item.Key()[len(graphPrefixCursor):])
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return nil, err
+		}
+		c, err := parseCursor(name, val)
+		if err != nil {
+			return nil, err
+		}
+		cursors = append(cursors, c)
+	}
+	return cursors, nil
+}
+
+func countCursors(txn *badger.Txn) int {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Prefix = []byte(graphPrefixCursor)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	var count int
+	for it.Seek(opts.Prefix); it.Valid(); it.Next() {
+		count++
+	}
+	return count
+}
+
+func parseCursor(name string, val []byte) (*Cursor, error) {
+	if len(val) != 16 {
+		return nil, fmt.Errorf("invalid cursor %s value size %d", name, len(val))
+	}
+	return &Cursor{
+		Name:      name,
+		Offset:    binary.BigEndian.Uint64(val[:8]),
+		Timestamp: binary.BigEndian.Uint64(val[8:]),
+	}, nil
+}
+
+func graphCursorKey(name string) []byte {
+	return append([]byte(graphPrefixCursor), name...)
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"encoding/binary"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/badger/v3"
+)
+
+const graphPrefixCustodianSet = "CUSTODIANSET"
+
+// ReadCustodianSet returns the custodian set active at the timestamp, i.e.
+// the latest finalized update no later than it, or nil if none registered.
+// The sets finalized at the same timestamp are ordered by the transaction
+// hash, so all the nodes agree on the active one.
+func (s *BadgerStore) ReadCustodianSet(timestamp uint64) (*common.CustodianSet, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	opts.Prefix = []byte(graphPrefixCustodianSet)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	last := crypto.Hash{}
+	for i := range last {
+		last[i] = 0xff
+	}
+	it.Seek(graphCustodianSetKey(timestamp, last))
+	if !it.Valid() {
+		return nil, nil
+	}
+	val, err := it.Item().ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	var set common.CustodianSet
+	err = common.MsgpackUnmarshal(val, &set)
+	return &set, err
+}
+
+func writeCustodianSet(txn *badger.Txn, update *common.CustodianUpdate, tx crypto.Hash, timestamp uint64) error {
+	set := &common.CustodianSet{
+		Threshold:   update.Threshold,
+		Keys:        update.Keys,
+		Transaction: tx,
+		Timestamp:   timestamp,
+	}
+	key := graphCustodianSetKey(timestamp, tx)
+	return txn.Set(key, common.MsgpackMarshalPanic(set))
+}
+
+func graphCustodianSetKey(timestamp uint64, tx crypto.Hash) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, timestamp)
+	key := append([]byte(graphPrefixCustodianSet), buf...)
+	return append(key, tx[:]...)
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/badger/v3"
+)
+
+const (
+	graphPrefixFeeLedger = "FEELEDGER" // node|round => fees of the round
+	graphPrefixFeeDay    = "FEEDAY"    // day|node|round => empty
+)
+
+// ReadFeeLedger returns at most count fee entries of the node since the
+// round, the rounds without any fees have no entries.
+func (s *BadgerStore) ReadFeeLedger(nodeId crypto.Hash, round, count uint64) ([]*common.FeeLedgerEntry, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = append([]byte(graphPrefixFeeLedger), nodeId[:]...)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	entries := make([]*common.FeeLedgerEntry, 0)
+	for it.Seek(graphFeeLedgerKey(nodeId, round)); it.Valid() && uint64(len(entries)) < count; it.Next() {
+		v, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			return nil, err
+		}
+		var e common.FeeLedgerEntry
+		err = common.MsgpackUnmarshal(v, &e)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, nil
+}
+
+// ReadFeeLedgerByDay returns the fee entries of all nodes in the day, by
+// the timestamp of the first transaction with fees in the round.
+func (s *BadgerStore) ReadFeeLedgerByDay(day uint64) ([]*common.FeeLedgerEntry, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Prefix = graphFeeDayKey(day, crypto.Hash{}, 0)[:len(graphPrefixFeeDay)+8]
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	entries := make([]*common.FeeLedgerEntry, 0)
+	for it.Seek(opts.Prefix); it.Valid(); it.Next() {
+		key := it.Item().Key()[len(opts.Prefix):]
+		var nodeId crypto.Hash
+		copy(nodeId[:], key)
+		round := binary.BigEndian.Uint64(key[len(nodeId):])
+		e, err := readFeeLedgerEntry(txn, nodeId, round)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func writeFeeLedger(txn *badger.Txn, ver *common.VersionedTransaction, snap *common.SnapshotWithTopologicalOrder) error {
+	burned, fuel := ver.Fees()
+	if burned.Sign() <= 0 && fuel.Sign() <= 0 {
+		return nil
+	}
+	e, err := readFeeLedgerEntry(txn, snap.NodeId, snap.RoundNumber)
+	if err == badger.ErrKeyNotFound {
+		e = &common.FeeLedgerEntry{
+			NodeId:    snap.NodeId,
+			Round:     snap.RoundNumber,
+			Timestamp: snap.Timestamp,
+		}
+		day := snap.Timestamp / uint64(time.Hour*24)
+		err = txn.Set(graphFeeDayKey(day, snap.NodeId, snap.RoundNumber), []byte{})
+	}
+	if err != nil {
+		return err
+	}
+	e.AddFees(&ver.Transaction)
+	key := graphFeeLedgerKey(snap.NodeId, snap.RoundNumber)
+	return txn.Set(key, common.MsgpackMarshalPanic(e))
+}
+
+func readFeeLedgerEntry(txn *badger.Txn, nodeId crypto.Hash, round uint64) (*common.FeeLedgerEntry, error) {
+	item, err := txn.Get(graphFeeLedgerKey(nodeId, round))
+	if err != nil {
+		return nil, err
+	}
+	v, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	var e common.FeeLedgerEntry
+	err = common.MsgpackUnmarshal(v, &e)
+	return &e, err
+}
+
+func graphFeeLedgerKey(nodeId crypto.Hash, round uint64) []byte {
+	key := append([]byte(graphPrefixFeeLedger), nodeId[:]...)
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, round)
+	return append(key, buf...)
+}
+
+func graphFeeDayKey(day uint64, nodeId crypto.Hash, round uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, day)
+	key := append([]byte(graphPrefixFeeDay), buf...)
+	key = append(key, nodeId[:]...)
+	binary.BigEndian.PutUint64(buf, round)
+	return append(key, buf...)
+}
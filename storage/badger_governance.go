@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/badger/v3"
+)
+
+const graphPrefixGovernanceVote = "GOVERNANCEVOTE"
+
+func (s *BadgerStore) ReadGovernanceVotes(proposal crypto.Hash) ([]*common.GovernanceVote, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	votes := make([]*common.GovernanceVote, 0)
+	prefix := append([]byte(graphPrefixGovernanceVote), proposal[:]...)
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		key := it.Item().KeyCopy(nil)
+		val, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			return nil, err
+		}
+		vote, err := governanceVoteFromEntry(key, val)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+	return votes, nil
+}
+
+func writeGovernanceVote(txn *badger.Txn, signal *common.GovernanceSignal, tx crypto.Hash, timestamp uint64) error {
+	key := graphGovernanceVoteKey(signal.Proposal, signal.Signer)
+	item, err := txn.Get(key)
+	if err == nil {
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		old, err := governanceVoteFromEntry(key, val)
+		if err != nil {
+			return err
+		}
+		if old.Timestamp > timestamp {
+			return nil
+		}
+	} else if err != badger.ErrKeyNotFound {
+		return err
+	}
+
+	val := make([]byte, 10)
+	binary.BigEndian.PutUint16(val, signal.Weight)
+	binary.BigEndian.PutUint64(val[2:], timestamp)
+	val = append(val, tx[:]...)
+	return txn.Set(key, val)
+}
+
+func governanceVoteFromEntry(key, val []byte) (*common.GovernanceVote, error) {
+	if len(val) != 10+len(crypto.Hash{}) {
+		return nil, fmt.Errorf("invalid governance vote entry %x", val)
+	}
+	var vote common.GovernanceVote
+	key = key[len(graphPrefixGovernanceVote):]
+	copy(vote.Proposal[:], key)
+	copy(vote.Signer[:], key[len(vote.Proposal):])
+	vote.Weight = binary.BigEndian.Uint16(val)
+	vote.Timestamp = binary.BigEndian.Uint64(val[2:])
+	copy(vote.Transaction[:], val[10:])
+	return &vote, nil
+}
+
+func graphGovernanceVoteKey(proposal crypto.Hash, signer crypto.Key) []byte {
+	key := append([]byte(graphPrefixGovernanceVote), proposal[:]...)
+	return append(key, signer[:]...)
+}
@@ -20,6 +20,7 @@ const (
 	graphPrefixFinalization = "FINALIZATION" // transaction finalization hack
 	graphPrefixUnique       = "UNIQUE"       // unique transaction in one node
 	graphPrefixRound        = "ROUND"        // hash|node-if-cache {node:hash,number:734,references:{self-parent-round-hash,external-round-hash}}
+	graphPrefixHead         = "GRAPHHEAD"    // node {number:734,start,end,finals:[{number:733,start,end,hash}]}
 	graphPrefixSnapshot     = "SNAPSHOT"     //
 	graphPrefixLink         = "LINK"         // self-external number
 	graphPrefixTopology     = "TOPOLOGY"
@@ -43,6 +44,7 @@ func (s *BadgerStore) RemoveGraphEntries(prefix string) (int, error) {
 	var removed int
 	it.Seek([]byte(prefix))
 	for ; it.Valid(); it.Next() {
+		s.throttle.wait(int(it.Item().EstimatedSize()))
 		key := it.Item().KeyCopy(nil)
 		err := txn.Delete(key)
 		if err != nil {
@@ -92,59 +94,45 @@ func readSnapshotsForNodeRound(txn *badger.Txn, nodeId crypto.Hash, round uint64
 	return snapshots, nil
 }
 
-func (s *BadgerStore) WriteSnapshot(snap *common.SnapshotWithTopologicalOrder, signers []crypto.Hash) error {
-	txn := s.snapshotsDB.NewTransaction(true)
-	defer txn.Discard()
-
-	// FIXME assert only, remove in future
-	if config.Debug {
-		cache, err := readRound(txn, snap.NodeId)
-		if err != nil {
-			return err
-		}
-		if cache == nil || snap.RoundNumber != cache.Number {
-			panic(fmt.Errorf("snapshot round number assert error %d %d", cache.Number, snap.RoundNumber))
-		}
-		if snap.RoundNumber > 0 && !snap.References.Equal(cache.References) {
-			panic("snapshot references assert error")
-		}
-		ver, err := readTransaction(txn, snap.Transaction)
-		if err != nil {
-			return err
-		}
-		if ver == nil {
-			panic("snapshot transaction not exist")
-		}
-		key := graphSnapshotKey(snap.NodeId, snap.RoundNumber, snap.Transaction)
-		_, err = txn.Get(key)
-		if err == nil {
-			panic("snapshot duplication")
-		} else if err != badger.ErrKeyNotFound {
-			return err
-		}
-		key = graphUniqueKey(snap.NodeId, snap.Transaction)
-		_, err = txn.Get(key)
-		if err == nil {
-			panic("snapshot duplication")
-		} else if err != badger.ErrKeyNotFound {
-			return err
-		}
+// assertSnapshotWrite checks the round, references and duplication of the
+// snapshot in debug mode only.
+// FIXME assert only, remove in future
+func (s *BadgerStore) assertSnapshotWrite(txn *badger.Txn, snap *common.SnapshotWithTopologicalOrder) error {
+	if !config.Debug {
+		return nil
 	}
-	// end assert
-
-	ver, err := readTransaction(txn, snap.Transaction)
+	cache, err := readRound(txn, snap.NodeId)
 	if err != nil {
 		return err
 	}
-	err = writeSnapshot(txn, snap, ver)
+	if cache == nil || snap.RoundNumber != cache.Number {
+		panic(fmt.Errorf("snapshot round number assert error %d %d", cache.Number, snap.RoundNumber))
+	}
+	if snap.RoundNumber > 0 && !snap.References.Equal(cache.References) {
+		panic("snapshot references assert error")
+	}
+	ver, err := readTransaction(txn, snap.Transaction)
 	if err != nil {
 		return err
 	}
-	err = writeSnapshotWork(txn, snap, signers)
-	if err != nil {
+	if ver == nil {
+		panic("snapshot transaction not exist")
+	}
+	key := graphSnapshotKey(snap.NodeId, snap.RoundNumber, snap.Transaction)
+	_, err = txn.Get(key)
+	if err == nil {
+		panic("snapshot duplication")
+	} else if err != badger.ErrKeyNotFound {
 		return err
 	}
-	return txn.Commit()
+	key = graphUniqueKey(snap.NodeId, snap.Transaction)
+	_, err = txn.Get(key)
+	if err == nil {
+		panic("snapshot duplication")
+	} else if err != badger.ErrKeyNotFound {
+		return err
+	}
+	return nil
 }
 
 func writeSnapshot(txn *badger.Txn, snap *common.SnapshotWithTopologicalOrder, ver *common.VersionedTransaction) error {
@@ -166,6 +154,11 @@ func writeSnapshot(txn *badger.Txn, snap *common.SnapshotWithTopologicalOrder, v
 		return err
 	}
 
+	err = updateGraphHeadSnapshot(txn, snap)
+	if err != nil {
+		return err
+	}
+
 	return writeTopology(txn, snap)
 }
 
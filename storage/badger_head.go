@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/badger/v3"
+)
+
+func (s *BadgerStore) ReadGraphHead(node crypto.Hash) (*common.GraphHead, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+	return readGraphHead(txn, node)
+}
+
+// WriteGraphHead writes the head rebuilt from the rounds history, for the
+// nodes without the head yet, and it's skipped if the head round changed.
+func (s *BadgerStore) WriteGraphHead(head *common.GraphHead) error {
+	txn := s.snapshotsDB.NewTransaction(true)
+	defer txn.Discard()
+
+	round, err := readRound(txn, head.NodeId)
+	if err != nil || round == nil || round.Number != head.Number {
+		return err
+	}
+	err = writeGraphHead(txn, head)
+	if err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+func readGraphHead(txn *badger.Txn, node crypto.Hash) (*common.GraphHead, error) {
+	item, err := txn.Get(graphHeadKey(node))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	val, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	var head common.GraphHead
+	err = common.MsgpackUnmarshal(val, &head)
+	return &head, err
+}
+
+func writeGraphHead(txn *badger.Txn, head *common.GraphHead) error {
+	val := common.MsgpackMarshalPanic(head)
+	return txn.Set(graphHeadKey(head.NodeId), val)
+}
+
+// updateGraphHeadSnapshot extends the cache round timestamps of the head,
+// and drops the head if inconsistent, to be rebuilt on the next boot.
+func updateGraphHeadSnapshot(txn *badger.Txn, snap *common.SnapshotWithTopologicalOrder) error {
+	head, err := readGraphHead(txn, snap.NodeId)
+	if err != nil || head == nil {
+		return err
+	}
+	if head.Number != snap.RoundNumber {
+		return txn.Delete(graphHeadKey(snap.NodeId))
+	}
+	if head.Start == 0 || snap.Timestamp < head.Start {
+		head.Start = snap.Timestamp
+	}
+	if snap.Timestamp > head.End {
+		head.End = snap.Timestamp
+	}
+	return writeGraphHead(txn, head)
+}
+
+func updateGraphHeadRound(txn *badger.Txn, node crypto.Hash, number uint64, references *common.RoundLink) error {
+	if number == 0 {
+		return writeGraphHead(txn, &common.GraphHead{NodeId: node})
+	}
+	head, err := readGraphHead(txn, node)
+	if err != nil || head == nil {
+		return err
+	}
+	if head.Number+1 != number || head.Start == 0 {
+		return txn.Delete(graphHeadKey(node))
+	}
+	head.Finals = append(head.Finals, &common.GraphHeadRound{
+		Number: head.Number,
+		Start:  head.Start,
+		End:    head.End,
+		Hash:   references.Self,
+	})
+	if rc := len(head.Finals) - int(config.SnapshotReferenceThreshold); rc > 0 {
+		head.Finals = head.Finals[rc:]
+	}
+	head.Number, head.Start, head.End = number, 0, 0
+	return writeGraphHead(txn, head)
+}
+
+func graphHeadKey(node crypto.Hash) []byte {
+	return append([]byte(graphPrefixHead), node[:]...)
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/MixinNetwork/mixin/common"
+	"github.com/MixinNetwork/mixin/logger"
+	"github.com/dgraph-io/badger/v3"
+)
+
+const (
+	graphPrefixTopologyManifest = "TOPOMANIFEST"
+
+	topologyManifestSize     = 1000
+	topologyManifestInterval = 10 * time.Second
+)
+
+// topologyManifest bundles the snapshots of a block of topologyManifestSize
+// orders, so a range read is a single sequential read of the block, instead
+// of a topology key seek and a snapshot key read for each snapshot. The
+// snapshots are the raw values of the snapshot keys, and a manifest is only
+// built for a complete block, which never changes after.
+type topologyManifest struct {
+	Snapshots [][]byte
+}
+
+// loopTopologyManifests builds the manifests of all the complete blocks not
+// built yet, which also migrates the databases before the manifests, and the
+// reads fall back to the topology keys for the blocks without manifest.
+func (s *BadgerStore) loopTopologyManifests() {
+	defer close(s.manifests)
+
+	for !s.isClosing() {
+		block, err := s.buildNextTopologyManifest()
+		if err != nil {
+			logger.Printf("buildNextTopologyManifest ERROR %s\n", err)
+		}
+		if err == nil && block > 0 {
+			if block%100 == 0 {
+				logger.Printf("buildNextTopologyManifest %d\n", block)
+			}
+			continue
+		}
+		for start := time.Now(); !s.isClosing() && time.Since(start) < topologyManifestInterval; {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// buildNextTopologyManifest returns the block number after the manifest
+// built, or 0 if no complete block to build.
+func (s *BadgerStore) buildNextTopologyManifest() (uint64, error) {
+	block := s.topologyManifestSequence()
+	if s.TopologySequence() < (block+1)*topologyManifestSize-1 {
+		return 0, nil
+	}
+
+	txn := s.snapshotsDB.NewTransaction(true)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(graphPrefixTopology)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	var size int
+	m := &topologyManifest{}
+	offset := block * topologyManifestSize
+	it.Seek(graphTopologyKey(offset))
+	for ; it.Valid() && len(m.Snapshots) < topologyManifestSize; it.Next() {
+		item := it.Item()
+		order := graphTopologyOrder(item.KeyCopy(nil))
+		if order != offset+uint64(len(m.Snapshots)) {
+			return 0, fmt.Errorf("topology manifest %d hole %d", block, order)
+		}
+		key, err := item.ValueCopy(nil)
+		if err != nil {
+			return 0, err
+		}
+		item, err = txn.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			return 0, err
+		}
+		size += len(v)
+		m.Snapshots = append(m.Snapshots, v)
+	}
+	it.Close()
+	if len(m.Snapshots) != topologyManifestSize {
+		return 0, fmt.Errorf("topology manifest %d incomplete %d", block, len(m.Snapshots))
+	}
+
+	s.throttle.wait(size * 2)
+	err := txn.Set(graphTopologyManifestKey(block), common.MsgpackMarshalPanic(m))
+	if err != nil {
+		return 0, err
+	}
+	return block + 1, txn.Commit()
+}
+
+// topologyManifestSequence returns the first block without manifest.
+func (s *BadgerStore) topologyManifestSequence() uint64 {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Reverse = true
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	it.Seek(graphTopologyManifestKey(^uint64(0)))
+	if it.ValidForPrefix([]byte(graphPrefixTopologyManifest)) {
+		key := it.Item().KeyCopy(nil)
+		return binary.BigEndian.Uint64(key[len(graphPrefixTopologyManifest):]) + 1
+	}
+	return 0
+}
+
+// readTopologyManifest returns at most count snapshots since the offset in
+// the manifest of the block, or nothing if the block has no manifest.
+func readTopologyManifest(txn *badger.Txn, offset, count uint64) ([]*common.SnapshotWithTopologicalOrder, error) {
+	block := offset / topologyManifestSize
+	item, err := txn.Get(graphTopologyManifestKey(block))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	v, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	var m topologyManifest
+	err = common.MsgpackUnmarshal(v, &m)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Snapshots) != topologyManifestSize {
+		return nil, fmt.Errorf("malformed topology manifest %d %d", block, len(m.Snapshots))
+	}
+
+	var snapshots []*common.SnapshotWithTopologicalOrder
+	for i := offset % topologyManifestSize; i < topologyManifestSize && uint64(len(snapshots)) < count; i++ {
+		var snap common.SnapshotWithTopologicalOrder
+		err = common.DecompressMsgpackUnmarshal(m.Snapshots[i], &snap)
+		if err != nil {
+			return nil, err
+		}
+		snap.Hash = snap.PayloadHash()
+		snap.TopologicalOrder = block*topologyManifestSize + i
+		snapshots = append(snapshots, &snap)
+	}
+	return snapshots, nil
+}
+
+func graphTopologyManifestKey(block uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, block)
+	return append([]byte(graphPrefixTopologyManifest), buf...)
+}
@@ -15,6 +15,7 @@ import (
 const (
 	graphPrefixNodeStateQueue = "NODESTATEQUEUE"
 	graphPrefixNodeOperation  = "NODEOPERATION"
+	graphPrefixNodeModify     = "NODEMODIFY"
 )
 
 func readAllNodes(txn *badger.Txn, threshold uint64, withState bool) []*common.Node {
@@ -238,6 +239,63 @@ func writeNodePledge(txn *badger.Txn, signer, payee crypto.Key, tx crypto.Hash,
 	return txn.Set(key, val)
 }
 
+// ReadNodeModifications returns all the finalized node modifications in the
+// order of their effective timestamps.
+func (s *BadgerStore) ReadNodeModifications() ([]*common.NodeModification, error) {
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(graphPrefixNodeModify)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	mods := make([]*common.NodeModification, 0)
+	for it.Seek(opts.Prefix); it.Valid(); it.Next() {
+		val, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			return nil, err
+		}
+		var m common.NodeModification
+		err = common.MsgpackUnmarshal(val, &m)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, &m)
+	}
+	return mods, nil
+}
+
+func writeNodeModify(txn *badger.Txn, modify *common.NodeModify, tx crypto.Hash, timestamp uint64) error {
+	var node *common.Node
+	for _, n := range readAllNodes(txn, timestamp, false) {
+		if n.Signer.PublicSpendKey == modify.Signer {
+			node = n
+		}
+	}
+	if node == nil || node.State != common.NodeStateAccepted {
+		logger.Printf("writeNodeModify %s not accepted while tx %s\n", modify.Signer, tx)
+		return nil
+	}
+
+	m := &common.NodeModification{
+		Signer:      modify.Signer,
+		Payee:       modify.Payee,
+		Transaction: tx,
+		Timestamp:   timestamp,
+		Effective:   timestamp + uint64(config.KernelNodeModifyDelay),
+	}
+	key := nodeModifyKey(m.Signer, m.Effective)
+	return txn.Set(key, common.MsgpackMarshalPanic(m))
+}
+
+func nodeModifyKey(signer crypto.Key, effective uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, effective)
+	key := append([]byte(graphPrefixNodeModify), buf...)
+	return append(key, signer[:]...)
+}
+
 func nodeStateQueueKey(signer crypto.Key, timestamp uint64) []byte {
 	buf := make([]byte, 8)
 	binary.BigEndian.PutUint64(buf, timestamp)
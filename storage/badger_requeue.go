@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/badger/v3"
+)
+
+const (
+	cachePrefixRequeueEntry = "REQUEUEENTRY"
+	cachePrefixRequeueOrder = "REQUEUEORDER"
+)
+
+// RequeueEntry is a self snapshot of the transaction to announce again,
+// after the due timestamp, and the retries is how many times announced.
+type RequeueEntry struct {
+	Transaction crypto.Hash
+	Created     uint64
+	Due         uint64
+	Retries     uint64
+}
+
+// CacheRequeueSnapshot queues the transaction if not queued yet, and
+// evicts the oldest entries to keep the queue within the limit. It returns
+// whether the transaction is newly queued, and how many entries evicted.
+func (s *BadgerStore) CacheRequeueSnapshot(tx crypto.Hash, now uint64, limit int) (bool, int, error) {
+	if limit <= 0 {
+		return false, 0, fmt.Errorf("invalid requeue limit %d", limit)
+	}
+	txn := s.cacheDB.NewTransaction(true)
+	defer txn.Discard()
+
+	_, err := txn.Get(cacheRequeueEntryKey(tx))
+	if err == nil {
+		return false, 0, nil
+	} else if err != badger.ErrKeyNotFound {
+		return false, 0, err
+	}
+
+	order, err := listRequeueOrder(txn)
+	if err != nil {
+		return false, 0, err
+	}
+	evicted := 0
+	for ; len(order)-evicted >= limit; evicted++ {
+		o := order[evicted]
+		err = txn.Delete(o)
+		if err != nil {
+			return false, 0, err
+		}
+		var h crypto.Hash
+		copy(h[:], o[len(cachePrefixRequeueOrder)+8:])
+		err = txn.Delete(cacheRequeueEntryKey(h))
+		if err != nil {
+			return false, 0, err
+		}
+	}
+
+	e := &RequeueEntry{Transaction: tx, Created: now, Due: now}
+	err = txn.Set(cacheRequeueEntryKey(tx), e.value())
+	if err != nil {
+		return false, 0, err
+	}
+	err = txn.Set(cacheRequeueOrderKey(e.Created, tx), []byte{})
+	if err != nil {
+		return false, 0, err
+	}
+	return true, evicted, txn.Commit()
+}
+
+// CacheListRequeuedSnapshots lists at most limit entries due before now,
+// in the order of queued.
+func (s *BadgerStore) CacheListRequeuedSnapshots(now uint64, limit int) ([]*RequeueEntry, int, error) {
+	txn := s.cacheDB.NewTransaction(false)
+	defer txn.Discard()
+
+	order, err := listRequeueOrder(txn)
+	if err != nil {
+		return nil, 0, err
+	}
+	var entries []*RequeueEntry
+	for _, o := range order {
+		if len(entries) >= limit {
+			break
+		}
+		var h crypto.Hash
+		copy(h[:], o[len(cachePrefixRequeueOrder)+8:])
+		item, err := txn.Get(cacheRequeueEntryKey(h))
+		if err != nil {
+			return nil, 0, err
+		}
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		e, err := parseRequeueEntry(h, val)
+		if err != nil {
+			return nil, 0, err
+		}
+		if e.Due <= now {
+			entries = append(entries, e)
+		}
+	}
+	return entries, len(order), nil
+}
+
+func (s *BadgerStore) CacheUpdateRequeuedSnapshot(e *RequeueEntry) error {
+	return s.cacheDB.Update(func(txn *badger.Txn) error {
+		key := cacheRequeueEntryKey(e.Transaction)
+		_, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return txn.Set(key, e.value())
+	})
+}
+
+func (s *BadgerStore) CacheRemoveRequeuedSnapshot(e *RequeueEntry) error {
+	return s.cacheDB.Update(func(txn *badger.Txn) error {
+		err := txn.Delete(cacheRequeueEntryKey(e.Transaction))
+		if err != nil {
+			return err
+		}
+		return txn.Delete(cacheRequeueOrderKey(e.Created, e.Transaction))
+	})
+}
+
+func listRequeueOrder(txn *badger.Txn) ([][]byte, error) {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Prefix = []byte(cachePrefixRequeueOrder)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	var keys [][]byte
+	for it.Seek(opts.Prefix); it.Valid(); it.Next() {
+		key := it.Item().KeyCopy(nil)
+		if len(key) != len(cachePrefixRequeueOrder)+8+32 {
+			return nil, fmt.Errorf("invalid requeue order key %x", key)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (e *RequeueEntry) value() []byte {
+	val := make([]byte, 24)
+	binary.BigEndian.PutUint64(val[:8], e.Created)
+	binary.BigEndian.PutUint64(val[8:16], e.Due)
+	binary.BigEndian.PutUint64(val[16:], e.Retries)
+	return val
+}
+
+func parseRequeueEntry(tx crypto.Hash, val []byte) (*RequeueEntry, error) {
+	if len(val) != 24 {
+		return nil, fmt.Errorf("invalid requeue entry %s value size %d", tx, len(val))
+	}
+	return &RequeueEntry{
+		Transaction: tx,
+		Created:     binary.BigEndian.Uint64(val[:8]),
+		Due:         binary.BigEndian.Uint64(val[8:16]),
+		Retries:     binary.BigEndian.Uint64(val[16:]),
+	}, nil
+}
+
+func cacheRequeueEntryKey(tx crypto.Hash) []byte {
+	return append([]byte(cachePrefixRequeueEntry), tx[:]...)
+}
+
+func cacheRequeueOrderKey(created uint64, tx crypto.Hash) []byte {
+	key := make([]byte, len(cachePrefixRequeueOrder)+8)
+	copy(key, cachePrefixRequeueOrder)
+	binary.BigEndian.PutUint64(key[len(cachePrefixRequeueOrder):], created)
+	return append(key, tx[:]...)
+}
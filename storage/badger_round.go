@@ -141,6 +141,11 @@ func startNewRound(txn *badger.Txn, node crypto.Hash, number uint64, references
 		}
 	}
 
+	err := updateGraphHeadRound(txn, node, number, references)
+	if err != nil {
+		return err
+	}
+
 	return writeRound(txn, node, &common.Round{
 		NodeId:     node,
 		Number:     number,
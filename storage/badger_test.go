@@ -1,10 +1,16 @@
 package storage
 
 import (
+	"fmt"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/MixinNetwork/mixin/common"
 	"github.com/MixinNetwork/mixin/config"
+	"github.com/MixinNetwork/mixin/crypto"
+	"github.com/dgraph-io/badger/v3"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,6 +30,555 @@ func TestBadger(t *testing.T) {
 	seq := store.TopologySequence()
 	assert.Equal(uint64(0), seq)
 
+	cursor, err := store.ReadCursor("indexer")
+	assert.Nil(err)
+	assert.Nil(cursor)
+	err = store.WriteCursor("indexer", 1024)
+	assert.Nil(err)
+	err = store.WriteCursor("exporter", 7)
+	assert.Nil(err)
+	cursor, err = store.ReadCursor("indexer")
+	assert.Nil(err)
+	assert.Equal("indexer", cursor.Name)
+	assert.Equal(uint64(1024), cursor.Offset)
+	assert.True(cursor.Timestamp > 0)
+	cursors, err := store.ListCursors()
+	assert.Nil(err)
+	assert.Len(cursors, 2)
+	assert.Equal("exporter", cursors[0].Name)
+	assert.Equal(uint64(7), cursors[0].Offset)
+	err = store.RemoveCursor("exporter")
+	assert.Nil(err)
+	cursors, err = store.ListCursors()
+	assert.Nil(err)
+	assert.Len(cursors, 1)
+	assert.Equal("indexer", cursors[0].Name)
+
+	for i := 1; i < cursorsLimit; i++ {
+		err = store.WriteCursor(fmt.Sprintf("cursor-%d", i), uint64(i))
+		assert.Nil(err)
+	}
+	err = store.WriteCursor("overflow", 1)
+	assert.NotNil(err)
+	err = store.WriteCursor("indexer", 2048)
+	assert.Nil(err)
+	err = store.RemoveCursor("cursor-1")
+	assert.Nil(err)
+	err = store.WriteCursor("overflow", 1)
+	assert.Nil(err)
+
+	err = store.Close()
+	assert.Nil(err)
+}
+
+func TestTransactionCache(t *testing.T) {
+	assert := assert.New(t)
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+	custom.Node.TransactionCacheSize = 10
+
+	root, err := os.MkdirTemp("", "mixin-badger-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	store, err := NewBadgerStore(custom, root)
+	assert.Nil(err)
+	defer store.Close()
+
+	var hashes []crypto.Hash
+	for i := 0; i < 25; i++ {
+		tx := common.NewTransaction(common.XINAssetId)
+		tx.AddInput(crypto.NewHash([]byte{byte(i)}), 0)
+		ver := tx.AsLatestVersion()
+		err = store.CachePutTransaction(ver)
+		assert.Nil(err)
+		hashes = append(hashes, ver.PayloadHash())
+		for atomic.LoadInt32(&store.txCache.evicting) != 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	entries, err := store.listCacheTransactionExpirations()
+	assert.Nil(err)
+	assert.LessOrEqual(len(entries), 10)
+	stats := store.CacheTransactionStats()
+	assert.Equal(int64(len(entries)), stats.Size)
+	assert.Equal(int64(10), stats.Limit)
+	assert.Equal(uint64(25), stats.Puts)
+	assert.Equal(uint64(25-len(entries)), stats.Evictions)
+
+	ver, err := store.CacheGetTransaction(entries[0].hash)
+	assert.Nil(err)
+	assert.Equal(entries[0].hash, ver.PayloadHash())
+	ver, err = store.CacheGetTransaction(crypto.NewHash([]byte("missing")))
+	assert.Nil(err)
+	assert.Nil(ver)
+	stats = store.CacheTransactionStats()
+	assert.Equal(uint64(1), stats.Hits)
+	assert.Equal(uint64(1), stats.Misses)
+
+	assert.Contains(hashes, entries[0].hash)
+	err = store.CacheRemoveTransactions([]crypto.Hash{entries[0].hash})
+	assert.Nil(err)
+	assert.Equal(int64(len(entries)-1), store.CacheTransactionStats().Size)
+}
+
+func TestIOThrottle(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(newIOThrottle(0, 20))
+	assert.Nil(newIOThrottle(100, 0))
+	assert.Nil(newIOThrottle(100, 100))
+	var none *ioThrottle
+	none.wait(1024)
+
+	now := time.Now()
+	var slept time.Duration
+	throttle := newIOThrottle(10, 10)
+	throttle.updated = now
+	throttle.now = func() time.Time { return now }
+	throttle.sleep = func(d time.Duration) { slept += d }
+	mb := 1024 * 1024
+
+	throttle.wait(mb)
+	assert.Equal(time.Duration(0), slept)
+	throttle.wait(mb)
+	assert.Equal(time.Second, slept)
+	throttle.wait(mb / 2)
+	assert.Equal(time.Second*2+time.Second/2, slept)
+
+	now = now.Add(time.Second * 3)
+	slept = 0
+	throttle.wait(mb / 2)
+	assert.Equal(time.Duration(0), slept)
+	throttle.wait(mb / 2)
+	assert.Equal(time.Duration(0), slept)
+	throttle.wait(mb)
+	assert.Equal(time.Second, slept)
+}
+
+func TestSnapshotFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	nodeId := crypto.NewHash([]byte("node"))
+	other := crypto.NewHash([]byte("other"))
+	key := graphSnapshotKey(nodeId, 7, crypto.NewHash([]byte("tx")))
+
+	filter := &SnapshotFilter{}
+	assert.True(filter.matchKey(key))
+	assert.False(filter.matchKey(key[1:]))
+	filter.NodeId = other
+	assert.False(filter.matchKey(key))
+	filter.NodeId = nodeId
+	assert.True(filter.matchKey(key))
+	filter.RoundStart, filter.RoundEnd = 7, 7
+	assert.True(filter.matchKey(key))
+	filter.RoundStart, filter.RoundEnd = 8, 0
+	assert.False(filter.matchKey(key))
+	filter.RoundStart, filter.RoundEnd = 0, 6
+	assert.False(filter.matchKey(key))
+
+	snap := &common.SnapshotWithTopologicalOrder{}
+	snap.Timestamp = 1000
+	assert.True(filter.matchSnapshot(snap))
+	filter.TimestampStart, filter.TimestampEnd = 1000, 1000
+	assert.True(filter.matchSnapshot(snap))
+	filter.TimestampStart, filter.TimestampEnd = 1001, 0
+	assert.False(filter.matchSnapshot(snap))
+	filter.TimestampStart, filter.TimestampEnd = 0, 999
+	assert.False(filter.matchSnapshot(snap))
+
+	tx := common.NewTransaction(common.XINAssetId)
+	tx.AddKernelNodeMintInput(1, common.NewInteger(1))
+	mint := tx.AsLatestVersion()
+	tx = common.NewTransaction(common.XINAssetId)
+	tx.AddInput(crypto.NewHash([]byte("input")), 0)
+	script := tx.AsLatestVersion()
+	assert.True(filter.matchTransaction(mint))
+	assert.True(filter.matchTransaction(script))
+	filter.NodeState = true
+	assert.False(filter.matchTransaction(mint))
+	assert.False(filter.matchTransaction(script))
+	filter.Mint = true
+	assert.True(filter.matchTransaction(mint))
+	assert.False(filter.matchTransaction(script))
+}
+
+func TestCustodianSet(t *testing.T) {
+	assert := assert.New(t)
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+
+	root, err := os.MkdirTemp("", "mixin-badger-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	store, err := NewBadgerStore(custom, root)
+	assert.Nil(err)
+	defer store.Close()
+
+	set, err := store.ReadCustodianSet(uint64(time.Now().UnixNano()))
+	assert.Nil(err)
+	assert.Nil(set)
+
+	keys := make([]crypto.Key, 3)
+	for i := range keys {
+		seed := crypto.NewHash([]byte{byte(i)})
+		keys[i] = crypto.NewKeyFromSeed(append(seed[:], seed[:]...)).Public()
+	}
+	for i, ts := range []uint64{100, 200} {
+		update := &common.CustodianUpdate{Threshold: uint8(i + 1), Keys: keys[i:]}
+		txn := store.snapshotsDB.NewTransaction(true)
+		err = writeCustodianSet(txn, update, crypto.NewHash([]byte{byte(i)}), ts)
+		assert.Nil(err)
+		assert.Nil(txn.Commit())
+	}
+
+	set, err = store.ReadCustodianSet(99)
+	assert.Nil(err)
+	assert.Nil(set)
+	set, err = store.ReadCustodianSet(100)
+	assert.Nil(err)
+	assert.Equal(uint8(1), set.Threshold)
+	assert.Len(set.Keys, 3)
+	assert.Equal(uint64(100), set.Timestamp)
+	set, err = store.ReadCustodianSet(199)
+	assert.Nil(err)
+	assert.Equal(uint64(100), set.Timestamp)
+	set, err = store.ReadCustodianSet(uint64(time.Now().UnixNano()))
+	assert.Nil(err)
+	assert.Equal(uint8(2), set.Threshold)
+	assert.Equal(keys[1:], set.Keys)
+	assert.Equal(crypto.NewHash([]byte{1}), set.Transaction)
+
+	update := &common.CustodianUpdate{Threshold: 3, Keys: keys}
+	txn := store.snapshotsDB.NewTransaction(true)
+	err = writeCustodianSet(txn, update, crypto.Hash{}, 200)
+	assert.Nil(err)
+	assert.Nil(txn.Commit())
+	set, err = store.ReadCustodianSet(200)
+	assert.Nil(err)
+	assert.Equal(uint8(2), set.Threshold)
+	assert.Equal(crypto.NewHash([]byte{1}), set.Transaction)
+	set, err = store.ReadCustodianSet(199)
+	assert.Nil(err)
+	assert.Equal(uint64(100), set.Timestamp)
+}
+
+func TestMemoryStore(t *testing.T) {
+	assert := assert.New(t)
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+
+	stores := make([]*BadgerStore, 16)
+	for i := range stores {
+		store, err := NewMemoryStore(custom)
+		assert.Nil(err)
+		assert.Equal(uint64(0), store.TopologySequence())
+		stores[i] = store
+	}
+
+	for i, store := range stores {
+		for _, name := range []string{"indexer", "exporter", "archiver"} {
+			err = store.WriteCursor(name, uint64(i))
+			assert.Nil(err)
+		}
+	}
+	for i, store := range stores {
+		cursors, err := store.ListCursors()
+		assert.Nil(err)
+		assert.Len(cursors, 3)
+		assert.Equal("archiver", cursors[0].Name)
+		assert.Equal("exporter", cursors[1].Name)
+		assert.Equal("indexer", cursors[2].Name)
+		assert.Equal(uint64(i), cursors[2].Offset)
+		assert.Nil(store.Close())
+	}
+}
+
+func TestGraphHead(t *testing.T) {
+	assert := assert.New(t)
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+
+	store, err := NewMemoryStore(custom)
+	assert.Nil(err)
+	defer store.Close()
+
+	node := crypto.NewHash([]byte("node"))
+	head, err := store.ReadGraphHead(node)
+	assert.Nil(err)
+	assert.Nil(head)
+
+	update := func(fn func(txn *badger.Txn) error) {
+		txn := store.snapshotsDB.NewTransaction(true)
+		defer txn.Discard()
+		assert.Nil(fn(txn))
+		assert.Nil(txn.Commit())
+	}
+	snapshot := func(round, timestamp uint64) *common.SnapshotWithTopologicalOrder {
+		return &common.SnapshotWithTopologicalOrder{
+			Snapshot: common.Snapshot{NodeId: node, RoundNumber: round, Timestamp: timestamp},
+		}
+	}
+
+	update(func(txn *badger.Txn) error { return updateGraphHeadRound(txn, node, 0, nil) })
+	for r := uint64(0); r < 15; r++ {
+		for _, ts := range []uint64{r*10 + 12, r*10 + 11, r*10 + 13} {
+			update(func(txn *badger.Txn) error { return updateGraphHeadSnapshot(txn, snapshot(r, ts)) })
+		}
+		links := &common.RoundLink{Self: crypto.NewHash([]byte{byte(r)})}
+		update(func(txn *badger.Txn) error { return updateGraphHeadRound(txn, node, r+1, links) })
+	}
+	head, err = store.ReadGraphHead(node)
+	assert.Nil(err)
+	assert.Equal(uint64(15), head.Number)
+	assert.Equal(uint64(0), head.Start)
+	assert.Len(head.Finals, int(config.SnapshotReferenceThreshold))
+	assert.Equal(uint64(5), head.Finals[0].Number)
+	last := head.Finals[len(head.Finals)-1]
+	assert.Equal(uint64(14), last.Number)
+	assert.Equal(uint64(151), last.Start)
+	assert.Equal(uint64(153), last.End)
+	assert.Equal(crypto.NewHash([]byte{14}), last.Hash)
+
+	update(func(txn *badger.Txn) error { return updateGraphHeadSnapshot(txn, snapshot(14, 160)) })
+	head, err = store.ReadGraphHead(node)
+	assert.Nil(err)
+	assert.Nil(head)
+	links := &common.RoundLink{Self: crypto.NewHash([]byte{15})}
+	update(func(txn *badger.Txn) error { return updateGraphHeadRound(txn, node, 16, links) })
+	head, err = store.ReadGraphHead(node)
+	assert.Nil(err)
+	assert.Nil(head)
+
+	assert.Nil(store.WriteGraphHead(&common.GraphHead{NodeId: node, Number: 16}))
+	head, err = store.ReadGraphHead(node)
+	assert.Nil(err)
+	assert.Nil(head)
+}
+
+func TestRequeueQueue(t *testing.T) {
+	assert := assert.New(t)
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+
+	store, err := NewMemoryStore(custom)
+	assert.Nil(err)
+	defer store.Close()
+
+	hashes := make([]crypto.Hash, 5)
+	for i := range hashes {
+		hashes[i] = crypto.NewHash([]byte{byte(i)})
+	}
+	_, _, err = store.CacheRequeueSnapshot(hashes[0], 100, 0)
+	assert.NotNil(err)
+
+	for i, h := range hashes[:3] {
+		queued, evicted, err := store.CacheRequeueSnapshot(h, uint64(100+i), 3)
+		assert.Nil(err)
+		assert.True(queued)
+		assert.Equal(0, evicted)
+	}
+	queued, evicted, err := store.CacheRequeueSnapshot(hashes[1], 200, 3)
+	assert.Nil(err)
+	assert.False(queued)
+	assert.Equal(0, evicted)
+
+	entries, depth, err := store.CacheListRequeuedSnapshots(101, 10)
+	assert.Nil(err)
+	assert.Equal(3, depth)
+	assert.Len(entries, 2)
+	assert.Equal(hashes[0], entries[0].Transaction)
+	assert.Equal(uint64(101), entries[1].Due)
+
+	entries[0].Due, entries[0].Retries = 500, 1
+	assert.Nil(store.CacheUpdateRequeuedSnapshot(entries[0]))
+	entries, _, err = store.CacheListRequeuedSnapshots(400, 10)
+	assert.Nil(err)
+	assert.Len(entries, 2)
+	assert.Equal(hashes[1], entries[0].Transaction)
+	entries, _, err = store.CacheListRequeuedSnapshots(500, 1)
+	assert.Nil(err)
+	assert.Len(entries, 1)
+	assert.Equal(hashes[0], entries[0].Transaction)
+	assert.Equal(uint64(1), entries[0].Retries)
+	assert.Equal(uint64(100), entries[0].Created)
+
+	queued, evicted, err = store.CacheRequeueSnapshot(hashes[3], 300, 3)
+	assert.Nil(err)
+	assert.True(queued)
+	assert.Equal(1, evicted)
+	queued, evicted, err = store.CacheRequeueSnapshot(hashes[4], 301, 2)
+	assert.Nil(err)
+	assert.True(queued)
+	assert.Equal(2, evicted)
+	entries, depth, err = store.CacheListRequeuedSnapshots(1000, 10)
+	assert.Nil(err)
+	assert.Equal(2, depth)
+	assert.Equal(hashes[3], entries[0].Transaction)
+	assert.Equal(hashes[4], entries[1].Transaction)
+
+	assert.Nil(store.CacheRemoveRequeuedSnapshot(entries[0]))
+	assert.Nil(store.CacheUpdateRequeuedSnapshot(entries[0]))
+	entries, depth, err = store.CacheListRequeuedSnapshots(1000, 10)
+	assert.Nil(err)
+	assert.Equal(1, depth)
+	assert.Equal(hashes[4], entries[0].Transaction)
+}
+
+func TestFeeLedger(t *testing.T) {
+	assert := assert.New(t)
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+
+	store, err := NewMemoryStore(custom)
+	assert.Nil(err)
+	defer store.Close()
+
+	day := uint64(time.Hour * 24)
+	node := crypto.NewHash([]byte("node"))
+	asset := crypto.NewHash([]byte("asset"))
+	claim := common.NewTransaction(common.XINAssetId)
+	claim.Outputs = append(claim.Outputs, &common.Output{Type: common.OutputTypeWithdrawalClaim, Amount: common.NewIntegerFromString("0.0001")})
+	fuel := common.NewTransaction(asset)
+	fuel.Outputs = append(fuel.Outputs, &common.Output{Type: common.OutputTypeWithdrawalFuel, Amount: common.NewInteger(2)})
+	plain := common.NewTransaction(asset)
+	plain.Outputs = append(plain.Outputs, &common.Output{Type: common.OutputTypeScript, Amount: common.NewInteger(3)})
+
+	for i, tx := range []*common.Transaction{claim, fuel, plain, claim, fuel} {
+		ver := tx.AsLatestVersion()
+		snap := &common.SnapshotWithTopologicalOrder{Snapshot: common.Snapshot{NodeId: node, RoundNumber: uint64(i / 3), Timestamp: day*10 + uint64(i)}}
+		txn := store.snapshotsDB.NewTransaction(true)
+		assert.Nil(writeFeeLedger(txn, ver, snap))
+		assert.Nil(txn.Commit())
+	}
+
+	entries, err := store.ReadFeeLedger(node, 0, 10)
+	assert.Nil(err)
+	assert.Len(entries, 2)
+	assert.Equal(uint64(0), entries[0].Round)
+	assert.Equal(uint64(2), entries[0].Transactions)
+	assert.Equal("0.00010000", entries[0].Burned.String())
+	assert.Len(entries[0].Fuels, 1)
+	assert.Equal(asset, entries[0].Fuels[0].Asset)
+	assert.Equal("2.00000000", entries[0].Fuels[0].Amount.String())
+	assert.Equal(day*10, entries[0].Timestamp)
+	entries, err = store.ReadFeeLedger(node, 1, 10)
+	assert.Nil(err)
+	assert.Len(entries, 1)
+	assert.Equal(uint64(2), entries[0].Transactions)
+	entries, err = store.ReadFeeLedger(crypto.NewHash([]byte("other")), 0, 10)
+	assert.Nil(err)
+	assert.Len(entries, 0)
+
+	entries, err = store.ReadFeeLedgerByDay(10)
+	assert.Nil(err)
+	assert.Len(entries, 2)
+	total := &common.FeeLedgerEntry{}
+	for _, e := range entries {
+		total.Merge(e)
+	}
+	assert.Equal(uint64(4), total.Transactions)
+	assert.Equal("0.00020000", total.Burned.String())
+	assert.Equal("4.00000000", total.Fuels[0].Amount.String())
+	entries, err = store.ReadFeeLedgerByDay(11)
+	assert.Nil(err)
+	assert.Len(entries, 0)
+}
+
+func TestSnapshotBatcher(t *testing.T) {
+	assert := assert.New(t)
+
+	write := func(order uint64) *snapshotWrite {
+		return &snapshotWrite{
+			snap:   &common.SnapshotWithTopologicalOrder{TopologicalOrder: order},
+			result: make(chan error, 1),
+		}
+	}
+	orders := func(batch []*snapshotWrite) []uint64 {
+		var orders []uint64
+		for _, w := range batch {
+			orders = append(orders, w.snap.TopologicalOrder)
+		}
+		return orders
+	}
+
+	b := newSnapshotBatcher(time.Millisecond, 2)
+	assert.False(b.hasPending())
+	for _, o := range []uint64{13, 11, 12, 15} {
+		assert.Nil(b.queue(write(o)))
+	}
+	assert.True(b.hasPending())
+	assert.Len(b.take(10, 2), 0)
+	assert.Equal([]uint64{11, 12}, orders(b.take(11, 2)))
+	assert.Equal([]uint64{13}, orders(b.take(13, 2)))
+	assert.Len(b.take(14, 2), 0)
+	assert.Nil(b.queue(write(14)))
+	assert.Equal([]uint64{14, 15}, orders(b.take(14, 2)))
+	assert.Len(b.pending, 0)
+	assert.False(b.hasPending())
+	assert.Panics(func() {
+		b.queue(write(16))
+		b.queue(write(16))
+	})
+	b.closed = true
+	assert.NotNil(b.queue(write(17)))
+
+	b = newSnapshotBatcher(time.Millisecond, 0)
+	assert.Equal(1, b.size)
+}
+
+func TestTopologyManifest(t *testing.T) {
+	assert := assert.New(t)
+	custom, err := config.Initialize("../config/config.example.toml")
+	assert.Nil(err)
+
+	root, err := os.MkdirTemp("", "mixin-badger-test")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	store, err := NewBadgerStore(custom, root)
+	assert.Nil(err)
+
+	nodeId := crypto.NewHash([]byte("node"))
+	txn := store.snapshotsDB.NewTransaction(true)
+	for i := uint64(0); i < topologyManifestSize*2+500; i++ {
+		snap := &common.SnapshotWithTopologicalOrder{TopologicalOrder: i}
+		snap.Version = common.SnapshotVersion
+		snap.NodeId = nodeId
+		snap.RoundNumber = i
+		snap.Transaction = crypto.NewHash([]byte(fmt.Sprint(i)))
+		snap.Timestamp = 1000 + i
+		key := graphSnapshotKey(snap.NodeId, snap.RoundNumber, snap.Transaction)
+		assert.Nil(txn.Set(key, common.CompressMsgpackMarshalPanic(snap)))
+		assert.Nil(writeTopology(txn, snap))
+	}
+	assert.Nil(txn.Commit())
+
+	linear, err := store.ReadSnapshotsSinceTopology(0, topologyManifestSize*3)
+	assert.Nil(err)
+	assert.Len(linear, topologyManifestSize*2+500)
+
+	for {
+		block, err := store.buildNextTopologyManifest()
+		assert.Nil(err)
+		if block == 0 {
+			break
+		}
+	}
+	assert.Equal(uint64(2), store.topologyManifestSequence())
+
+	for _, r := range [][2]uint64{{0, 1}, {0, 500}, {999, 2}, {500, 1200}, {1999, 600}, {2400, 500}, {2500, 10}} {
+		snapshots, err := store.ReadSnapshotsSinceTopology(r[0], r[1])
+		assert.Nil(err)
+		end := r[0] + r[1]
+		if end > uint64(len(linear)) {
+			end = uint64(len(linear))
+		}
+		assert.Equal(linear[r[0]:end], snapshots)
+	}
+
 	err = store.Close()
 	assert.Nil(err)
 }
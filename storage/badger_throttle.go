@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// ioThrottle is a token bucket of disk bytes shared by all the background
+// jobs, e.g. graph validation, entries removal and value log gc, so they
+// never take more than the configured share of the disk bandwidth from
+// the snapshot writes. A job borrows the bytes it is about to read, and
+// sleeps until the bucket refills when it goes into debt.
+type ioThrottle struct {
+	sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	updated time.Time
+	now     func() time.Time
+	sleep   func(time.Duration)
+}
+
+func newIOThrottle(bandwidth, share int) *ioThrottle {
+	if bandwidth <= 0 || share <= 0 || share >= 100 {
+		return nil
+	}
+	rate := float64(bandwidth) * 1024 * 1024 * float64(share) / 100
+	return &ioThrottle{
+		rate:    rate,
+		burst:   rate,
+		tokens:  rate,
+		updated: time.Now(),
+		now:     time.Now,
+		sleep:   time.Sleep,
+	}
+}
+
+func (t *ioThrottle) reserve(n int) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+
+	now := t.now()
+	if elapsed := now.Sub(t.updated).Seconds(); elapsed > 0 {
+		t.tokens = t.tokens + elapsed*t.rate
+		if t.tokens > t.burst {
+			t.tokens = t.burst
+		}
+		t.updated = now
+	}
+	t.tokens = t.tokens - float64(n)
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+func (t *ioThrottle) wait(n int) {
+	if t == nil || n <= 0 {
+		return
+	}
+	if d := t.reserve(n); d > 0 {
+		t.sleep(d)
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/dgraph-io/badger/v3"
 )
 
+const snapshotFilterScanLimit = 10000
+
 func (s *BadgerStore) ReadSnapshot(hash crypto.Hash) (*common.SnapshotWithTopologicalOrder, error) {
 	txn := s.snapshotsDB.NewTransaction(false)
 	defer txn.Discard()
@@ -79,11 +82,29 @@ func (s *BadgerStore) ReadSnapshotWithTransactionsSinceTopology(topologyOffset,
 	return snapshots, transactions, nil
 }
 
+// ReadSnapshotsSinceTopology reads the blocks with manifest first, and only
+// scans the topology keys since the first block without manifest, which is
+// usually the latest incomplete block.
 func (s *BadgerStore) ReadSnapshotsSinceTopology(topologyOffset, count uint64) ([]*common.SnapshotWithTopologicalOrder, error) {
 	snapshots := make([]*common.SnapshotWithTopologicalOrder, 0)
 	txn := s.snapshotsDB.NewTransaction(false)
 	defer txn.Discard()
 
+	for uint64(len(snapshots)) < count {
+		bundle, err := readTopologyManifest(txn, topologyOffset, count-uint64(len(snapshots)))
+		if err != nil {
+			return snapshots, err
+		}
+		if len(bundle) == 0 {
+			break
+		}
+		snapshots = append(snapshots, bundle...)
+		topologyOffset += uint64(len(bundle))
+	}
+	if uint64(len(snapshots)) >= count {
+		return snapshots, nil
+	}
+
 	opts := badger.DefaultIteratorOptions
 	opts.Prefix = []byte(graphPrefixTopology)
 	it := txn.NewIterator(opts)
@@ -118,6 +139,126 @@ func (s *BadgerStore) ReadSnapshotsSinceTopology(topologyOffset, count uint64) (
 	return snapshots, nil
 }
 
+// SnapshotFilter selects the snapshots by their topology index value
+// before decoding them, zero fields are not filtered, and the ranges
+// are inclusive.
+type SnapshotFilter struct {
+	NodeId         crypto.Hash
+	RoundStart     uint64
+	RoundEnd       uint64
+	TimestampStart uint64
+	TimestampEnd   uint64
+	Mint           bool
+	NodeState      bool
+}
+
+func (f *SnapshotFilter) matchKey(key []byte) bool {
+	if len(key) != len(graphPrefixSnapshot)+len(crypto.Hash{})*2+8 {
+		return false
+	}
+	key = key[len(graphPrefixSnapshot):]
+	if f.NodeId.HasValue() && !bytes.Equal(f.NodeId[:], key[:len(f.NodeId)]) {
+		return false
+	}
+	round := binary.BigEndian.Uint64(key[len(f.NodeId):])
+	if round < f.RoundStart {
+		return false
+	}
+	return f.RoundEnd == 0 || round <= f.RoundEnd
+}
+
+func (f *SnapshotFilter) matchSnapshot(snap *common.SnapshotWithTopologicalOrder) bool {
+	if snap.Timestamp < f.TimestampStart {
+		return false
+	}
+	return f.TimestampEnd == 0 || snap.Timestamp <= f.TimestampEnd
+}
+
+func (f *SnapshotFilter) matchTransaction(tx *common.VersionedTransaction) bool {
+	if !f.Mint && !f.NodeState {
+		return true
+	}
+	switch tx.TransactionType() {
+	case common.TransactionTypeMint:
+		return f.Mint
+	case common.TransactionTypeNodePledge,
+		common.TransactionTypeNodeCancel,
+		common.TransactionTypeNodeAccept,
+		common.TransactionTypeNodeRemove:
+		return f.NodeState
+	}
+	return false
+}
+
+// ReadSnapshotsSinceTopologyWithFilter scans at most snapshotFilterScanLimit
+// topology entries, and returns the matched snapshots and the topology to
+// continue with, which may be beyond the last matched snapshot.
+func (s *BadgerStore) ReadSnapshotsSinceTopologyWithFilter(offset, count uint64, filter *SnapshotFilter) ([]*common.SnapshotWithTopologicalOrder, []*common.VersionedTransaction, uint64, error) {
+	if count > 500 {
+		return nil, nil, 0, fmt.Errorf("count %d too large, the maximum is 500", count)
+	}
+	snapshots := make([]*common.SnapshotWithTopologicalOrder, 0)
+	transactions := make([]*common.VersionedTransaction, 0)
+	txn := s.snapshotsDB.NewTransaction(false)
+	defer txn.Discard()
+
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(graphPrefixTopology)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	next, scanned := offset, 0
+	it.Seek(graphTopologyKey(offset))
+	for ; it.Valid() && uint64(len(snapshots)) < count; it.Next() {
+		if scanned >= snapshotFilterScanLimit {
+			break
+		}
+		scanned += 1
+		item := it.Item()
+		topology := graphTopologyOrder(item.KeyCopy(nil))
+		next = topology + 1
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			return snapshots, transactions, next, err
+		}
+		if !filter.matchKey(v) {
+			continue
+		}
+		item, err = txn.Get(v)
+		if err != nil {
+			return snapshots, transactions, next, err
+		}
+		v, err = item.ValueCopy(nil)
+		if err != nil {
+			return snapshots, transactions, next, err
+		}
+		var snap common.SnapshotWithTopologicalOrder
+		err = common.DecompressMsgpackUnmarshal(v, &snap)
+		if err != nil {
+			return snapshots, transactions, next, err
+		}
+		if !filter.matchSnapshot(&snap) {
+			continue
+		}
+		tx, err := readTransaction(txn, snap.Transaction)
+		if err != nil {
+			return snapshots, transactions, next, err
+		}
+		if tx == nil {
+			return snapshots, transactions, next, fmt.Errorf("transaction %s not found", snap.Transaction)
+		}
+		if !filter.matchTransaction(tx) {
+			continue
+		}
+		snap.Hash = snap.PayloadHash()
+		snap.TopologicalOrder = topology
+		snapshots = append(snapshots, &snap)
+		transactions = append(transactions, tx)
+	}
+
+	return snapshots, transactions, next, nil
+}
+
 func (s *BadgerStore) TopologySequence() uint64 {
 	var sequence uint64
 
@@ -172,7 +172,36 @@ func finalizeTransaction(txn *badger.Txn, ver *common.VersionedTransaction, snap
 			return err
 		}
 	}
-	return nil
+
+	err = writeFeeLedger(txn, ver, snap)
+	if err != nil {
+		return err
+	}
+
+	update, err := ver.CustodianUpdate()
+	if err == nil && update != nil && common.ForkActivated(common.CustodianForkTimestamp, snap.Timestamp) {
+		err = writeCustodianSet(txn, update, ver.PayloadHash(), snap.Timestamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	modify, err := ver.NodeModify()
+	if err == nil && modify != nil && common.ForkActivated(common.NodeModifyForkTimestamp, snap.Timestamp) {
+		err = writeNodeModify(txn, modify, ver.PayloadHash(), snap.Timestamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !common.ForkActivated(common.GovernanceForkTimestamp, snap.Timestamp) {
+		return nil
+	}
+	signal, err := ver.GovernanceSignal()
+	if err != nil || signal == nil {
+		return nil
+	}
+	return writeGovernanceVote(txn, signal, ver.PayloadHash(), snap.Timestamp)
 }
 
 func writeUTXO(txn *badger.Txn, utxo *common.UTXO, extra []byte, timestamp uint64, genesis bool) error {
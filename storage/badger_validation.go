@@ -63,7 +63,7 @@ func (s *BadgerStore) validateSnapshotEntriesForNode(nodeId crypto.Hash, depth u
 	if head.Number < depth {
 		start = 0
 	}
-	invalid, total := 0, 0
+	invalid, total, throttle := 0, 0, s.throttle
 	for i := start; i < head.Number; i++ {
 		snapshots, err := readSnapshotsForNodeRound(txn, nodeId, i)
 		if err != nil {
@@ -79,6 +79,7 @@ func (s *BadgerStore) validateSnapshotEntriesForNode(nodeId crypto.Hash, depth u
 			if err != nil {
 				return total, invalid, err
 			}
+			throttle.wait(len(val))
 			ver, err := common.DecompressUnmarshalVersionedTransaction(val)
 			if err != nil {
 				return total, invalid, err
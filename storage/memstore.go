@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"github.com/MixinNetwork/mixin/config"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/badger/v3/options"
+)
+
+// badger limits a batch to 15 percent of the memtable size, which must
+// not be less than the value threshold, so the default 1MB threshold is
+// lowered for the small memtables.
+const (
+	memoryStoreTableSize      = 4 << 20
+	memoryStoreValueThreshold = 256 << 10
+)
+
+// NewMemoryStore opens the store with both databases entirely in memory,
+// for the unit tests and simulations to run many nodes without the disk.
+// It shares all the logic of the badger store, and the iterations are in
+// the same deterministic key order. Nothing is persisted after closed, and
+// the IO throttle and value log GC are meaningless for it.
+func NewMemoryStore(custom *config.Custom) (*BadgerStore, error) {
+	snapshotsDB, err := openMemoryDB()
+	if err != nil {
+		return nil, err
+	}
+	cacheDB, err := openMemoryDB()
+	if err != nil {
+		snapshotsDB.Close()
+		return nil, err
+	}
+	return newBadgerStore(custom, snapshotsDB, cacheDB, nil)
+}
+
+func openMemoryDB() (*badger.DB, error) {
+	opts := badger.DefaultOptions("").WithInMemory(true)
+	opts = opts.WithCompression(options.None)
+	opts = opts.WithMemTableSize(memoryStoreTableSize)
+	opts = opts.WithBaseTableSize(memoryStoreTableSize / 2)
+	opts = opts.WithNumMemtables(2)
+	opts = opts.WithValueThreshold(memoryStoreValueThreshold)
+	opts = opts.WithBlockCacheSize(0)
+	opts = opts.WithIndexCacheSize(0)
+	opts = opts.WithMetricsEnabled(false)
+	opts = opts.WithLoggingLevel(badger.ERROR)
+	return badger.Open(opts)
+}
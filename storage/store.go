@@ -27,16 +27,36 @@ type Store interface {
 	ReadSnapshot(hash crypto.Hash) (*common.SnapshotWithTopologicalOrder, error)
 	ReadSnapshotsSinceTopology(offset, count uint64) ([]*common.SnapshotWithTopologicalOrder, error)
 	ReadSnapshotWithTransactionsSinceTopology(topologyOffset, count uint64) ([]*common.SnapshotWithTopologicalOrder, []*common.VersionedTransaction, error)
+	ReadSnapshotsSinceTopologyWithFilter(offset, count uint64, filter *SnapshotFilter) ([]*common.SnapshotWithTopologicalOrder, []*common.VersionedTransaction, uint64, error)
 	ReadSnapshotsForNodeRound(nodeIdWithNetwork crypto.Hash, round uint64) ([]*common.SnapshotWithTopologicalOrder, error)
 	ReadRound(hash crypto.Hash) (*common.Round, error)
+	ReadGraphHead(node crypto.Hash) (*common.GraphHead, error)
+	WriteGraphHead(head *common.GraphHead) error
 	ReadLink(from, to crypto.Hash) (uint64, error)
 	WriteSnapshot(*common.SnapshotWithTopologicalOrder, []crypto.Hash) error
 	ReadDomains() []common.Domain
+	ReadGovernanceVotes(proposal crypto.Hash) ([]*common.GovernanceVote, error)
+	ReadCustodianSet(timestamp uint64) (*common.CustodianSet, error)
+	ReadNodeModifications() ([]*common.NodeModification, error)
+	ReadFeeLedger(nodeId crypto.Hash, round, count uint64) ([]*common.FeeLedgerEntry, error)
+	ReadFeeLedgerByDay(day uint64) ([]*common.FeeLedgerEntry, error)
+
+	ReadCursor(name string) (*Cursor, error)
+	WriteCursor(name string, offset uint64) error
+	RemoveCursor(name string) error
+	ListCursors() ([]*Cursor, error)
 
 	CachePutTransaction(tx *common.VersionedTransaction) error
 	CacheGetTransaction(hash crypto.Hash) (*common.VersionedTransaction, error)
 	CacheListTransactions(offset crypto.Hash, limit int) ([]*common.VersionedTransaction, error)
 	CacheRemoveTransactions([]crypto.Hash) error
+	CacheTransactionStats() TransactionCacheStats
+	CacheWriteCosiState(chainId crypto.Hash, state []byte) error
+	CacheReadCosiState(chainId crypto.Hash) ([]byte, error)
+	CacheRequeueSnapshot(tx crypto.Hash, now uint64, limit int) (bool, int, error)
+	CacheListRequeuedSnapshots(now uint64, limit int) ([]*RequeueEntry, int, error)
+	CacheUpdateRequeuedSnapshot(e *RequeueEntry) error
+	CacheRemoveRequeuedSnapshot(e *RequeueEntry) error
 
 	ReadLastMintDistribution(group string) (*common.MintDistribution, error)
 	LockMintInput(mint *common.MintData, tx crypto.Hash, fork bool) error
@@ -47,6 +67,7 @@ type Store interface {
 	ReadWorkOffset(nodeId crypto.Hash) (uint64, error)
 	WriteRoundWork(nodeId crypto.Hash, round uint64, snapshots []*common.SnapshotWork) error
 
+	StorageInfo() StorageInfo
 	RemoveGraphEntries(prefix string) (int, error)
 	ValidateGraphEntries(networkId crypto.Hash, depth uint64) (int, int, error)
 }
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter keeps a token bucket for each key, refilled at rate tokens
+// per second up to burst. Requests without a token are shed and counted.
+type RateLimiter struct {
+	sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type RateLimiterStats struct {
+	Allowed uint64
+	Shed    uint64
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	stats   RateLimiterStats
+}
+
+func NewRateLimiter(rate, burst int) *RateLimiter {
+	if burst < rate {
+		burst = rate
+	}
+	return &RateLimiter{
+		rate:    float64(rate),
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *RateLimiter) Allow(key string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = b.tokens + elapsed*l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.updated = now
+	}
+	if b.tokens < 1 {
+		b.stats.Shed++
+		return false
+	}
+	b.tokens--
+	b.stats.Allowed++
+	return true
+}
+
+func (l *RateLimiter) Stats() map[string]RateLimiterStats {
+	l.Lock()
+	defer l.Unlock()
+
+	stats := make(map[string]RateLimiterStats)
+	for k, b := range l.buckets {
+		stats[k] = b.stats
+	}
+	return stats
+}
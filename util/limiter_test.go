@@ -0,0 +1,41 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1551312000, 0)
+	limiter := NewRateLimiter(10, 20)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 20; i++ {
+		assert.True(limiter.Allow("a"))
+	}
+	assert.False(limiter.Allow("a"))
+	assert.True(limiter.Allow("b"))
+
+	now = now.Add(500 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		assert.True(limiter.Allow("a"))
+	}
+	assert.False(limiter.Allow("a"))
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 20; i++ {
+		assert.True(limiter.Allow("a"))
+	}
+	assert.False(limiter.Allow("a"))
+
+	stats := limiter.Stats()
+	assert.Len(stats, 2)
+	assert.Equal(uint64(45), stats["a"].Allowed)
+	assert.Equal(uint64(3), stats["a"].Shed)
+	assert.Equal(uint64(1), stats["b"].Allowed)
+	assert.Equal(uint64(0), stats["b"].Shed)
+}
@@ -0,0 +1,54 @@
+package util
+
+import "sync"
+
+// QueueSaturation counts the offers to some bounded queues of the same
+// capacity, how often they are found full, how many items are dropped by
+// the overflow policy, and the peak length ever reached.
+type QueueSaturation struct {
+	sync.Mutex
+	stats QueueSaturationStats
+}
+
+type QueueSaturationStats struct {
+	Capacity int
+	Peak     int
+	Offered  uint64
+	Full     uint64
+	Dropped  uint64
+}
+
+func NewQueueSaturation(capacity int) *QueueSaturation {
+	return &QueueSaturation{stats: QueueSaturationStats{Capacity: capacity}}
+}
+
+func (qs *QueueSaturation) Offer(length int) {
+	qs.Lock()
+	defer qs.Unlock()
+
+	qs.stats.Offered++
+	if length > qs.stats.Peak {
+		qs.stats.Peak = length
+	}
+}
+
+func (qs *QueueSaturation) Full() {
+	qs.Lock()
+	defer qs.Unlock()
+
+	qs.stats.Full++
+}
+
+func (qs *QueueSaturation) Drop() {
+	qs.Lock()
+	defer qs.Unlock()
+
+	qs.stats.Dropped++
+}
+
+func (qs *QueueSaturation) Stats() QueueSaturationStats {
+	qs.Lock()
+	defer qs.Unlock()
+
+	return qs.stats
+}
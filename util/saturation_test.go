@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueSaturation(t *testing.T) {
+	assert := assert.New(t)
+
+	qs := NewQueueSaturation(4)
+	qs.Offer(1)
+	qs.Offer(3)
+	qs.Offer(2)
+	qs.Full()
+	qs.Full()
+	qs.Drop()
+
+	stats := qs.Stats()
+	assert.Equal(4, stats.Capacity)
+	assert.Equal(3, stats.Peak)
+	assert.Equal(uint64(3), stats.Offered)
+	assert.Equal(uint64(2), stats.Full)
+	assert.Equal(uint64(1), stats.Dropped)
+}